@@ -637,6 +637,27 @@ func sortedMethodNames(typ *types.Interface) []string {
 	return list
 }
 
+// sortedEmbeddeds returns the type set elements of typ other than
+// embedded interfaces, whose methods are listed by sortedMethodNames.
+func (w *Walker) sortedEmbeddeds(typ *types.Interface) []string {
+	var list []string
+	for i, n := 0, typ.NumEmbeddeds(); i < n; i++ {
+		switch emb := typ.EmbeddedType(i).(type) {
+		case *types.Interface:
+			list = append(list, w.sortedEmbeddeds(emb)...)
+		case *types.Named:
+			// Embedded interface or comparable.
+			if _, ok := emb.Underlying().(*types.Interface); !ok || emb == types.Universe.Lookup("comparable").Type() {
+				list = append(list, w.typeString(emb))
+			}
+		default:
+			list = append(list, w.typeString(emb))
+		}
+	}
+	sort.Strings(list)
+	return list
+}
+
 func (w *Walker) writeType(buf *bytes.Buffer, typ types.Type) {
 	switch typ := typ.(type) {
 	case *types.Basic:
@@ -694,13 +715,30 @@ func (w *Walker) writeType(buf *bytes.Buffer, typ types.Type) {
 
 	case *types.Interface:
 		buf.WriteString("interface{")
-		if typ.NumMethods() > 0 {
+		if elems := append(sortedMethodNames(typ), w.sortedEmbeddeds(typ)...); len(elems) > 0 {
 			buf.WriteByte(' ')
-			buf.WriteString(strings.Join(sortedMethodNames(typ), ", "))
+			buf.WriteString(strings.Join(elems, ", "))
 			buf.WriteByte(' ')
 		}
 		buf.WriteString("}")
 
+	case *types.Union:
+		for i := 0; i < typ.Len(); i++ {
+			if i > 0 {
+				buf.WriteString(" | ")
+			}
+			term := typ.Term(i)
+			if term.Tilde() {
+				buf.WriteByte('~')
+			}
+			w.writeType(buf, term.Type())
+		}
+
+	case *types.TypeParam:
+		// Type parameters are identified by their index so that
+		// renaming them does not change the API.
+		fmt.Fprintf(buf, "$%d", typ.Index())
+
 	case *types.Map:
 		buf.WriteString("map[")
 		w.writeType(buf, typ.Key())
@@ -730,13 +768,41 @@ func (w *Walker) writeType(buf *bytes.Buffer, typ types.Type) {
 			buf.WriteByte('.')
 		}
 		buf.WriteString(typ.Obj().Name())
+		if targs := typ.TypeArgs(); targs.Len() > 0 {
+			buf.WriteByte('[')
+			for i := 0; i < targs.Len(); i++ {
+				if i > 0 {
+					buf.WriteString(", ")
+				}
+				w.writeType(buf, targs.At(i))
+			}
+			buf.WriteByte(']')
+		}
 
 	default:
 		panic(fmt.Sprintf("unknown type %T", typ))
 	}
 }
 
+func (w *Walker) writeTypeParams(buf *bytes.Buffer, tparams *types.TypeParamList) {
+	if tparams.Len() == 0 {
+		return
+	}
+	buf.WriteByte('[')
+	for i := 0; i < tparams.Len(); i++ {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		tparam := tparams.At(i)
+		w.writeType(buf, tparam)
+		buf.WriteByte(' ')
+		w.writeType(buf, tparam.Constraint())
+	}
+	buf.WriteByte(']')
+}
+
 func (w *Walker) writeSignature(buf *bytes.Buffer, sig *types.Signature) {
+	w.writeTypeParams(buf, sig.TypeParams())
 	w.writeParams(buf, sig.Params(), sig.Variadic())
 	switch res := sig.Results(); res.Len() {
 	case 0:
@@ -804,6 +870,12 @@ func (w *Walker) emitObj(obj types.Object) {
 func (w *Walker) emitType(obj *types.TypeName) {
 	name := obj.Name()
 	typ := obj.Type()
+	if named, ok := typ.(*types.Named); ok && named.TypeParams().Len() > 0 {
+		var buf bytes.Buffer
+		buf.WriteString(name)
+		w.writeTypeParams(&buf, named.TypeParams())
+		name = buf.String()
+	}
 	switch typ := typ.Underlying().(type) {
 	case *types.Struct:
 		w.emitStructType(name, typ)
@@ -864,6 +936,7 @@ func (w *Walker) emitIfaceType(name string, typ *types.Interface) {
 
 	var methodNames []string
 	complete := true
+	embeddeds := w.sortedEmbeddeds(typ)
 	mset := types.NewMethodSet(typ)
 	for i, n := 0, mset.Len(); i < n; i++ {
 		m := mset.At(i).Obj().(*types.Func)
@@ -892,13 +965,13 @@ func (w *Walker) emitIfaceType(name string, typ *types.Interface) {
 		return
 	}
 
-	if len(methodNames) == 0 {
+	if len(methodNames) == 0 && len(embeddeds) == 0 {
 		w.emitf("type %s interface {}", name)
 		return
 	}
 
 	sort.Strings(methodNames)
-	w.emitf("type %s interface { %s }", name, strings.Join(methodNames, ", "))
+	w.emitf("type %s interface { %s }", name, strings.Join(append(methodNames, embeddeds...), ", "))
 }
 
 func (w *Walker) emitFunc(f *types.Func) {
@@ -912,17 +985,31 @@ func (w *Walker) emitFunc(f *types.Func) {
 func (w *Walker) emitMethod(m *types.Selection) {
 	sig := m.Type().(*types.Signature)
 	recv := sig.Recv().Type()
+	base := recv
+	if p, _ := recv.(*types.Pointer); p != nil {
+		base = p.Elem()
+	}
 	// report exported methods with unexported receiver base type
-	if true {
-		base := recv
-		if p, _ := recv.(*types.Pointer); p != nil {
-			base = p.Elem()
-		}
-		if obj := base.(*types.Named).Obj(); !obj.Exported() {
-			log.Fatalf("exported method with unexported receiver base type: %s", m)
+	named := base.(*types.Named)
+	if obj := named.Obj(); !obj.Exported() {
+		log.Fatalf("exported method with unexported receiver base type: %s", m)
+	}
+	recvString := w.typeString(recv)
+	if tparams := named.TypeParams(); tparams.Len() > 0 && named.TypeArgs().Len() == 0 {
+		// The receiver of a method of a generic type is the type
+		// instantiated with its own type parameters.
+		var buf bytes.Buffer
+		buf.WriteByte('[')
+		for i := 0; i < tparams.Len(); i++ {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			w.writeType(&buf, tparams.At(i))
 		}
+		buf.WriteByte(']')
+		recvString += buf.String()
 	}
-	w.emitf("method (%s) %s%s", w.typeString(recv), m.Obj().Name(), w.signatureString(sig))
+	w.emitf("method (%s) %s%s", recvString, m.Obj().Name(), w.signatureString(sig))
 }
 
 func (w *Walker) emitf(format string, args ...interface{}) {
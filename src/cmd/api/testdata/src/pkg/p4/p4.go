@@ -0,0 +1,26 @@
+package p4
+
+type Number interface {
+	~int | ~float64
+}
+
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+func NewPair[K comparable, V any](key K, value V) *Pair[K, V] {
+	return &Pair[K, V]{key, value}
+}
+
+func (p *Pair[K, V]) Get() (K, V) {
+	return p.Key, p.Value
+}
+
+func Sum[S ~[]E, E Number](s S) E {
+	var sum E
+	for _, v := range s {
+		sum += v
+	}
+	return sum
+}
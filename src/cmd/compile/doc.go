@@ -103,6 +103,14 @@ Flags:
 		and diagnose imports that would cause a circular dependency.
 	-pack
 		Write a package (archive) file rather than an object file
+	-pgoprofile file
+		Read a CPU profile of the program in pprof format from file
+		and use it for profile-guided optimization: functions called
+		from hot call sites get a larger inlining budget there, and hot
+		interface method calls whose callee is usually the method of
+		one concrete type are devirtualized for that type. The
+		optimizations can be turned off with -d=pgoinline=0 and
+		-d=pgodevirtualize=0.
 	-race
 		Compile with race detector enabled.
 	-s
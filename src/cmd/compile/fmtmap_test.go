@@ -70,6 +70,7 @@ var knownFormats = map[string]string{
 	"[][]string %q":                                   "",
 	"[]byte %s":                                       "",
 	"[]byte %x":                                       "",
+	"[]cmd/compile/internal/pgo.CallEdge %v":          "",
 	"[]cmd/compile/internal/ssa.Edge %v":              "",
 	"[]cmd/compile/internal/ssa.ID %v":                "",
 	"[]cmd/compile/internal/ssa.posetNode %v":         "",
@@ -171,6 +172,8 @@ var knownFormats = map[string]string{
 	"interface{} %v":                                  "",
 	"map[*cmd/compile/internal/gc.Node]*cmd/compile/internal/ssa.Value %v": "",
 	"map[*cmd/compile/internal/gc.Node][]*cmd/compile/internal/gc.Node %v": "",
+	"map[cmd/compile/internal/pgo.CallEdge]bool %v":                        "",
+	"map[cmd/compile/internal/pgo.CallEdge]int64 %v":                       "",
 	"map[cmd/compile/internal/ssa.ID]uint32 %v":                            "",
 	"map[int64]uint32 %v":  "",
 	"math/big.Accuracy %s": "",
@@ -107,6 +107,11 @@ func algtype1(t *types.Type) (AlgKind, *types.Type) {
 		TCHAN, TUNSAFEPTR:
 		return AMEM, nil
 
+	case TTYPEPARAM:
+		// Type parameters only appear in generic signatures,
+		// which are never compiled; see typeParams.
+		return AMEM, nil
+
 	case TFUNC, TMAP:
 		return ANOEQ, t
 
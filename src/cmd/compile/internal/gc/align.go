@@ -51,12 +51,25 @@ func expandiface(t *types.Type) {
 		addMethod(m, true)
 	}
 
+	// The type set of t is the intersection of the type sets of
+	// its embedded elements.
+	var terms []*types.Term
+	restricted := false
+	comparable := t == types.Comparabletype
+	restrict := func(ts []*types.Term) {
+		if restricted {
+			ts = intersectTerms(terms, ts)
+		}
+		terms, restricted = ts, true
+	}
+
 	for _, m := range t.Methods().Slice() {
 		if m.Sym != nil {
 			continue
 		}
 
-		if !m.Type.IsInterface() {
+		switch {
+		case m.Type.Etype == TFORW:
 			yyerrorl(m.Pos, "interface contains embedded non-interface %v", m.Type)
 			m.SetBroke(true)
 			t.SetBroke(true)
@@ -66,6 +79,16 @@ func expandiface(t *types.Type) {
 			// TODO(mdempsky): Revisit this.
 			methods = append(methods, m)
 			continue
+
+		case m.Type.Etype == TUNION:
+			restrict(unionTerms(m.Type))
+			continue
+
+		case !m.Type.IsInterface():
+			// Embedded non-interface type T: the type set
+			// only contains T.
+			restrict([]*types.Term{{Type: m.Type}})
+			continue
 		}
 
 		// Embedded interface: duplicate all methods
@@ -79,6 +102,12 @@ func expandiface(t *types.Type) {
 			f.SetBroke(t1.Broke())
 			addMethod(f, false)
 		}
+		if ts, ok := m.Type.TypeTerms(); ok {
+			restrict(ts)
+		}
+		if m.Type.IsComparableConstraint() {
+			comparable = true
+		}
 	}
 
 	sort.Sort(methcmp(methods))
@@ -93,6 +122,68 @@ func expandiface(t *types.Type) {
 	// Access fields directly to avoid recursively calling dowidth
 	// within Type.Fields().
 	t.Extra.(*types.Interface).Fields.Set(methods)
+	t.SetTypeTerms(terms, restricted)
+	t.SetComparableConstraint(comparable)
+}
+
+// unionTerms returns the type terms of union type u. Terms naming
+// constraint interfaces contribute their own type terms; terms naming
+// other interfaces leave the type set unrestricted, which is
+// represented by a single term for the empty interface.
+func unionTerms(u *types.Type) []*types.Term {
+	var terms []*types.Term
+	for _, x := range u.Terms() {
+		if x.Type.IsInterface() {
+			if ts, ok := x.Type.TypeTerms(); ok {
+				terms = append(terms, ts...)
+				continue
+			}
+		}
+		terms = append(terms, x)
+	}
+	return terms
+}
+
+// intersectTerms returns the type terms matching both a term of x and
+// a term of y.
+func intersectTerms(x, y []*types.Term) []*types.Term {
+	var terms []*types.Term
+	for _, a := range x {
+		for _, b := range y {
+			if c := intersectTerm(a, b); c != nil {
+				terms = append(terms, c)
+			}
+		}
+	}
+	return terms
+}
+
+// intersectTerm returns the type term matching exactly the types
+// matched by both a and b, or nil if there are none.
+func intersectTerm(a, b *types.Term) *types.Term {
+	switch {
+	case a.Type.IsEmptyInterface():
+		return b
+	case b.Type.IsEmptyInterface():
+		return a
+	case a.Tilde && b.Tilde:
+		if types.Identical(a.Type, b.Type) {
+			return a
+		}
+	case a.Tilde:
+		if types.Identical(a.Type, b.Type.Orig) {
+			return b
+		}
+	case b.Tilde:
+		if types.Identical(a.Type.Orig, b.Type) {
+			return a
+		}
+	default:
+		if types.Identical(a.Type, b.Type) {
+			return a
+		}
+	}
+	return nil
 }
 
 func widstruct(errtype *types.Type, t *types.Type, o int64, flag int) int64 {
@@ -284,6 +375,15 @@ func dowidth(t *types.Type) {
 		t.Align = uint8(Widthptr)
 		expandiface(t)
 
+	case TTYPEPARAM:
+		// Type parameters only appear in the signatures of
+		// generic functions, which are never compiled.
+		w = int64(Widthptr)
+
+	case TUNION:
+		// Unions only appear embedded in constraint interfaces.
+		w = 1
+
 	case TCHAN: // implemented as pointer
 		w = int64(Widthptr)
 
@@ -200,6 +200,9 @@ func predeclared() []*types.Type {
 
 			// any type, for builtin export data
 			types.Types[TANY],
+
+			// comparable
+			types.Comparabletype,
 		}
 	}
 	return predecl
@@ -18,6 +18,7 @@ func newobject(typ *byte) *any
 func panicdivide()
 func panicshift()
 func panicmakeslicelen()
+func panicrangeexit()
 func throwinit()
 func panicwrap()
 
@@ -25,6 +26,9 @@ func gopanic(interface{})
 func gorecover(*int32) interface{}
 func goschedguarded()
 
+func deferrangefunc(tok *unsafe.Pointer)
+func deferprocat(fn func(), tok unsafe.Pointer)
+
 // Note: these declarations are just for wasm port.
 // Other ports call assembly stubs instead.
 func goPanicIndex(x int, y int)
@@ -374,7 +374,10 @@ func closureType(clo *Node) *types.Type {
 		if !v.Name.Byval() {
 			typ = types.NewPtr(typ)
 		}
-		fields = append(fields, symfield(v.Sym, typ))
+		// Captured variables of instances of imported generic
+		// functions belong to the generic function's package,
+		// but the fields must belong to the local package.
+		fields = append(fields, namedfield(v.Sym.Name, typ))
 	}
 	typ := tostruct(fields)
 	typ.SetNoalg(true)
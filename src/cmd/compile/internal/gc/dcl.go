@@ -662,7 +662,12 @@ func interfacefield(n *Node) *types.Field {
 	// Otherwise, Left is InterfaceTypeName.
 
 	if n.Left != nil {
-		n.Left = typecheck(n.Left, ctxType)
+		top := ctxType
+		if n.Sym == nil {
+			// Embedded elements may be type constraints.
+			top |= ctxConstraint
+		}
+		n.Left = typecheck(n.Left, top)
 		n.Type = n.Left.Type
 		n.Left = nil
 	}
@@ -901,7 +906,7 @@ func addmethod(msym *types.Sym, t *types.Type, local, nointerface bool) *types.F
 		return nil
 	}
 
-	if local && mt.Sym.Pkg != localpkg {
+	if local && mt.Sym.Pkg != localpkg && !isInstance(mt) {
 		yyerror("cannot define new methods on non-local type %v", mt)
 		return nil
 	}
@@ -0,0 +1,388 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gc
+
+import (
+	"cmd/compile/internal/syntax"
+	"cmd/compile/internal/types"
+	"cmd/internal/obj"
+	"cmd/internal/src"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// embedCfg holds the parsed -embedcfg file, written by the go command.
+// Patterns maps each //go:embed pattern to the list of files it matched
+// (as slash-separated paths relative to the package directory),
+// and Files maps each of those paths to the file's location on disk.
+var embedCfg struct {
+	Patterns map[string][]string
+	Files    map[string]string
+}
+
+func readEmbedCfg(file string) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		log.Fatalf("-embedcfg: %v", err)
+	}
+	if err := json.Unmarshal(data, &embedCfg); err != nil {
+		log.Fatalf("%s: %v", file, err)
+	}
+	if embedCfg.Patterns == nil {
+		log.Fatalf("%s: invalid embedcfg: missing Patterns", file)
+	}
+	if embedCfg.Files == nil {
+		log.Fatalf("%s: invalid embedcfg: missing Files", file)
+	}
+}
+
+// An embedPragma records a //go:embed directive seen by the parser.
+type embedPragma struct {
+	pos      syntax.Pos
+	patterns []string
+}
+
+// An embedVar records a package-level variable initialized
+// by one or more //go:embed directives.
+type embedVar struct {
+	n        *Node
+	pos      src.XPos
+	patterns []string
+}
+
+// embedlist is the list of variables to be filled in with embedded
+// file contents once type checking is complete.
+var embedlist []embedVar
+
+// parseEmbedPragma parses the arguments of a //go:embed directive.
+func (p *noder) parseEmbedPragma(pos syntax.Pos, text string) {
+	args := strings.TrimPrefix(text, "go:embed")
+	if args == "" || (args[0] != ' ' && args[0] != '\t') {
+		p.error(syntax.Error{Pos: pos, Msg: "usage: //go:embed pattern..."})
+		return
+	}
+	patterns, err := parseGoEmbed(args)
+	if err != nil {
+		p.error(syntax.Error{Pos: pos, Msg: err.Error()})
+		return
+	}
+	if len(patterns) == 0 {
+		p.error(syntax.Error{Pos: pos, Msg: "usage: //go:embed pattern..."})
+		return
+	}
+	p.embeds = append(p.embeds, embedPragma{pos, patterns})
+}
+
+// parseGoEmbed parses the text following "//go:embed" to extract the glob patterns.
+// It accepts unquoted space-separated patterns as well as double-quoted and back-quoted Go strings.
+// This is a copy of parseGoEmbed in go/build/read.go.
+func parseGoEmbed(args string) ([]string, error) {
+	var list []string
+	for args = strings.TrimSpace(args); args != ""; args = strings.TrimSpace(args) {
+		var path string
+	Switch:
+		switch args[0] {
+		default:
+			i := len(args)
+			for j, c := range args {
+				if c == ' ' || c == '\t' {
+					i = j
+					break
+				}
+			}
+			path = args[:i]
+			args = args[i:]
+
+		case '`':
+			i := strings.Index(args[1:], "`")
+			if i < 0 {
+				return nil, fmt.Errorf("invalid quoted string in //go:embed: %s", args)
+			}
+			path = args[1 : 1+i]
+			args = args[1+i+1:]
+
+		case '"':
+			i := 1
+			for ; i < len(args); i++ {
+				if args[i] == '\\' {
+					i++
+					continue
+				}
+				if args[i] == '"' {
+					q, err := strconv.Unquote(args[:i+1])
+					if err != nil {
+						return nil, fmt.Errorf("invalid quoted string in //go:embed: %s", args[:i+1])
+					}
+					path = q
+					args = args[i+1:]
+					break Switch
+				}
+			}
+			if i >= len(args) {
+				return nil, fmt.Errorf("invalid quoted string in //go:embed: %s", args)
+			}
+		}
+
+		if args != "" {
+			r := args[0]
+			if r != ' ' && r != '\t' {
+				return nil, fmt.Errorf("invalid quoted string in //go:embed: %s", args)
+			}
+		}
+
+		list = append(list, path)
+	}
+	return list, nil
+}
+
+// posBefore reports whether syntax position a precedes b in the same file.
+func posBefore(a, b syntax.Pos) bool {
+	return a.Line() < b.Line() || a.Line() == b.Line() && a.Col() < b.Col()
+}
+
+// assignEmbeds matches the //go:embed directives in the file
+// to the package-level variable declarations they precede.
+// Directives that do not immediately precede a var declaration
+// are reported as misplaced.
+func (p *noder) assignEmbeds() map[*syntax.VarDecl][]string {
+	if len(p.embeds) == 0 {
+		return nil
+	}
+	m := make(map[*syntax.VarDecl][]string)
+	decls := p.file.DeclList
+	i := 0
+	for _, e := range p.embeds {
+		for i < len(decls) && posBefore(decls[i].Pos(), e.pos) {
+			i++
+		}
+		misplaced := i >= len(decls)
+		if i > 0 {
+			if fn, ok := decls[i-1].(*syntax.FuncDecl); ok && fn.Body != nil && posBefore(e.pos, fn.Body.Rbrace) {
+				misplaced = true
+			}
+		}
+		if !misplaced {
+			d, ok := decls[i].(*syntax.VarDecl)
+			if !ok {
+				misplaced = true
+			} else {
+				m[d] = append(m[d], e.patterns...)
+			}
+		}
+		if misplaced {
+			p.yyerrorpos(e.pos, "misplaced //go:embed directive")
+		}
+	}
+	return m
+}
+
+// embedVarDecl checks and records the //go:embed patterns for
+// the variables declared by decl.
+func (p *noder) embedVarDecl(decl *syntax.VarDecl, names []*Node, typ *Node, exprs []*Node, patterns []string) {
+	pos := p.pos(decl)
+	switch {
+	case !p.importedEmbed:
+		yyerrorl(pos, "go:embed only allowed in Go files that import \"embed\"")
+	case len(names) > 1:
+		yyerrorl(pos, "go:embed cannot apply to multiple vars")
+	case len(exprs) > 0:
+		yyerrorl(pos, "go:embed cannot apply to var with initializer")
+	case typ == nil:
+		// Should not happen, since len(exprs) == 0 now.
+		yyerrorl(pos, "go:embed cannot apply to var without type")
+	case embedCfg.Patterns == nil:
+		yyerrorl(pos, "invalid go:embed: build system did not supply embed configuration")
+	default:
+		embedlist = append(embedlist, embedVar{names[0], pos, patterns})
+	}
+}
+
+// Embed kinds, by the type of the variable being initialized.
+const (
+	embedUnknown = iota
+	embedBytes
+	embedString
+	embedFiles
+)
+
+func embedKind(typ *types.Type) int {
+	if typ.Sym != nil && typ.Sym.Name == "FS" && typ.Sym.Pkg.Path == "embed" {
+		return embedFiles
+	}
+	if typ.Etype == types.TSTRING {
+		return embedString
+	}
+	if typ.IsSlice() && typ.Elem().Etype == types.TUINT8 {
+		return embedBytes
+	}
+	return embedUnknown
+}
+
+// embedFileList returns the sorted list of files (and, for embed.FS,
+// the directories leading to them) matched by the patterns of v.
+func embedFileList(v embedVar, kind int) []string {
+	have := make(map[string]bool)
+	var list []string
+	for _, pattern := range v.patterns {
+		files, ok := embedCfg.Patterns[pattern]
+		if !ok {
+			yyerrorl(v.pos, "invalid go:embed: build system did not map pattern: %s", pattern)
+		}
+		for _, file := range files {
+			if embedCfg.Files[file] == "" {
+				yyerrorl(v.pos, "invalid go:embed: build system did not map file: %s", file)
+				continue
+			}
+			if !have[file] {
+				have[file] = true
+				list = append(list, file)
+			}
+			if kind == embedFiles {
+				for dir := path.Dir(file); dir != "." && !have[dir+"/"]; dir = path.Dir(dir) {
+					have[dir+"/"] = true
+					list = append(list, dir+"/")
+				}
+			}
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return embedFileLess(list[i], list[j])
+	})
+
+	if kind == embedString || kind == embedBytes {
+		if len(list) > 1 {
+			yyerrorl(v.pos, "invalid go:embed: multiple files for type %v", v.n.Type)
+			return nil
+		}
+	}
+	return list
+}
+
+// embedFileNameSplit splits name into its directory and element,
+// ignoring a trailing slash. The ordering of embed.FS depends on it.
+func embedFileNameSplit(name string) (dir, elem string, isDir bool) {
+	if name[len(name)-1] == '/' {
+		isDir = true
+		name = name[:len(name)-1]
+	}
+	i := len(name) - 1
+	for i >= 0 && name[i] != '/' {
+		i--
+	}
+	if i < 0 {
+		return ".", name, isDir
+	}
+	return name[:i], name[i+1:], isDir
+}
+
+// embedFileLess implements the sort order for a list of embedded files.
+// See the comment inside ../../../../embed/embed.go's Files struct for rationale.
+func embedFileLess(x, y string) bool {
+	xdir, xelem, _ := embedFileNameSplit(x)
+	ydir, yelem, _ := embedFileNameSplit(y)
+	return xdir < ydir || xdir == ydir && xelem < yelem
+}
+
+// fileStringSym returns a symbol holding the content of the named file,
+// along with its length. If readonly is set, the symbol is a
+// content-addressed read-only string; otherwise it is a fresh,
+// writable symbol suitable as the backing array of a []byte.
+// If hash is non-nil, the file's SHA-256 hash is copied into it.
+func fileStringSym(pos src.XPos, file string, readonly bool, hash []byte) (*obj.LSym, int, bool) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		yyerrorl(pos, "reading embedded file: %v", err)
+		return nil, 0, false
+	}
+	if hash != nil {
+		sum := sha256.Sum256(data)
+		copy(hash, sum[:])
+	}
+	if readonly {
+		return stringsym(pos, string(data)), len(data), true
+	}
+
+	slicebytes_gen++
+	sym := localpkg.Lookup(fmt.Sprintf(".gobytes.%d", slicebytes_gen))
+	lsym := sym.Linksym()
+	off := dsname(lsym, 0, string(data), pos, "slice")
+	ggloblsym(lsym, int32(off), obj.NOPTR|obj.LOCAL)
+	return lsym, len(data), true
+}
+
+// initEmbeds fills in the data for the variables in embedlist.
+// It must be called after type checking, before the globals are dumped.
+func initEmbeds() {
+	for _, v := range embedlist {
+		initEmbed(v)
+	}
+	embedlist = nil
+}
+
+func initEmbed(v embedVar) {
+	kind := embedKind(v.n.Type)
+	if kind == embedUnknown {
+		yyerrorl(v.pos, "go:embed cannot apply to var of type %v", v.n.Type)
+		return
+	}
+
+	files := embedFileList(v, kind)
+	sym := v.n.Sym.Linksym()
+	switch kind {
+	case embedString, embedBytes:
+		if len(files) == 0 {
+			return
+		}
+		fsym, size, ok := fileStringSym(v.pos, embedCfg.Files[files[0]], kind == embedString, nil)
+		if !ok {
+			return
+		}
+		off := dsymptr(sym, 0, fsym, 0)
+		off = duintptr(sym, off, uint64(size))
+		if kind == embedBytes {
+			duintptr(sym, off, uint64(size))
+		}
+
+	case embedFiles:
+		// The data is a slice header followed by the slice contents:
+		// a list of (name string, data string, hash [16]byte) entries.
+		// The embed.FS variable itself holds a pointer to the header.
+		slicedata := localpkg.Lookup(v.n.Sym.Name + ".files").Linksym()
+		off := 0
+		off = dsymptr(slicedata, off, slicedata, 3*Widthptr) // []file, pointing just past slice
+		off = duintptr(slicedata, off, uint64(len(files)))
+		off = duintptr(slicedata, off, uint64(len(files)))
+
+		const hashSize = 16
+		hash := make([]byte, hashSize)
+		for _, file := range files {
+			off = dsymptr(slicedata, off, stringsym(v.pos, file), 0) // file string
+			off = duintptr(slicedata, off, uint64(len(file)))
+			if strings.HasSuffix(file, "/") {
+				// entry for directory - no data
+				off = duintptr(slicedata, off, 0)
+				off = duintptr(slicedata, off, 0)
+				off += hashSize
+			} else {
+				fsym, size, ok := fileStringSym(v.pos, embedCfg.Files[file], true, hash)
+				if !ok {
+					return
+				}
+				off = dsymptr(slicedata, off, fsym, 0) // data string
+				off = duintptr(slicedata, off, uint64(size))
+				off = int(slicedata.WriteBytes(Ctxt, int64(off), hash))
+			}
+		}
+		ggloblsym(slicedata, int32(off), obj.RODATA|obj.LOCAL)
+		dsymptr(sym, 0, slicedata, 0)
+	}
+}
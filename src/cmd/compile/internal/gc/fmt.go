@@ -774,12 +774,31 @@ func typefmt(t *types.Type, flag FmtFlag, mode fmtMode, depth int) string {
 			}
 			buf = append(buf, tconv(f.Type, FmtShort, mode, depth)...)
 		}
-		if t.NumFields() != 0 {
+		n := t.NumFields()
+		if t.IsComparableConstraint() {
+			if n != 0 {
+				buf = append(buf, ';')
+			}
+			buf = append(buf, " comparable"...)
+			n++
+		}
+		if terms, ok := t.TypeTerms(); ok {
+			if n != 0 {
+				buf = append(buf, ';')
+			}
+			buf = append(buf, ' ')
+			buf = append(buf, termsString(terms, mode, depth)...)
+			n++
+		}
+		if n != 0 {
 			buf = append(buf, ' ')
 		}
 		buf = append(buf, '}')
 		return string(buf)
 
+	case TUNION:
+		return termsString(t.Terms(), mode, depth)
+
 	case TFUNC:
 		buf := make([]byte, 0, 64)
 		if flag&FmtShort != 0 {
@@ -876,6 +895,24 @@ func typefmt(t *types.Type, flag FmtFlag, mode fmtMode, depth int) string {
 	return mode.Sprintf("%v <%v>", t.Etype, t.Sym)
 }
 
+// termsString formats the type terms of a union.
+func termsString(terms []*types.Term, mode fmtMode, depth int) string {
+	if len(terms) == 0 {
+		return "<empty type set>"
+	}
+	buf := make([]byte, 0, 64)
+	for i, x := range terms {
+		if i != 0 {
+			buf = append(buf, " | "...)
+		}
+		if x.Tilde {
+			buf = append(buf, '~')
+		}
+		buf = append(buf, tmodeString(x.Type, mode, depth)...)
+	}
+	return string(buf)
+}
+
 // Statements which may be rendered with a simplestmt as init.
 func stmtwithinit(op Op) bool {
 	switch op {
@@ -0,0 +1,1368 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file implements generic functions and types.
+//
+// Generic declarations are compiled by stenciling: the syntax of a
+// generic declaration is kept as a template, and each instantiation
+// with a distinct list of type arguments is noded again from the
+// template, with the type parameter names bound to the type arguments.
+// The resulting functions and types are ordinary (non-generic) Nodes.
+// Instances are named like Name[targs] and are emitted as DUPOK
+// symbols by every package that uses them.
+//
+// The signature and constraints of a generic declaration are noded
+// with the type parameter names bound to TTYPEPARAM types. They are
+// used to infer type arguments from the arguments of calls.
+
+package gc
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"cmd/compile/internal/syntax"
+	"cmd/compile/internal/types"
+	"cmd/internal/objabi"
+	"cmd/internal/src"
+)
+
+// generics maps the symbols of generic functions and types, declared
+// locally or imported, to their descriptions. The symbols' Defs are
+// OGENERIC Nodes.
+var generics = map[*types.Sym]*generic{}
+
+// A generic describes a generic function or type.
+type generic struct {
+	sym     *types.Sym
+	tmpl    *template
+	methods []*template // methods of a generic type
+
+	// tparams are the type parameters and sig is the signature of
+	// a generic function, in terms of tparams; see typeParams.
+	tparams []*types.Type
+	sig     *types.Type
+
+	// params caches the instances of a generic type whose type
+	// arguments contain type parameters, by type arguments.
+	params map[string]*Node
+}
+
+// A template is the syntax of a generic declaration or of a method of
+// a generic type.
+type template struct {
+	pkg     *types.Pkg       // package of the declaration
+	imports []templateImport // imports of the declaring file
+	decl    syntax.Decl      // *syntax.FuncDecl or *syntax.TypeDecl; see syntax
+	text    string           // source text of decl, for export
+
+	// imported reports whether the template was read from export
+	// data, in which case decl is parsed from text on first use.
+	imported bool
+	basemap  map[*syntax.PosBase]*src.PosBase
+}
+
+// A templateImport is an import of the file declaring a template.
+type templateImport struct {
+	name string // local package name, or "." for a dot import
+	pkg  *types.Pkg
+}
+
+// typeInsts maps the instances of generic types to their generic
+// types and type arguments.
+var typeInsts = map[*types.Type]*typeInst{}
+
+type typeInst struct {
+	gen   *generic
+	targs []*types.Type
+}
+
+// instDepth is the current nesting depth of instantiations.
+var instDepth int
+
+// maxInstDepth limits the nesting depth of instantiations, which is
+// unbounded for generic functions instantiating themselves with ever
+// larger type arguments.
+const maxInstDepth = 100
+
+// instantiationsClosed is set once all function bodies have been type
+// checked. Later instantiations of types, such as those caused by
+// importing declarations for inlining, only declare their methods,
+// which are compiled by the packages instantiating them from source.
+var instantiationsClosed bool
+
+// templatePkgs maps the functions, closures and type literals of
+// instances of generic declarations imported from other packages to
+// the packages of the declarations, whose unexported names they use;
+// see curpkg and setTypeLitPkg.
+var templatePkgs = map[*Node]*types.Pkg{}
+
+// setTypeLitPkg sets the package of the type of the type checked
+// struct, interface or function type literal n, if it is part of an
+// instance of an imported generic declaration.
+func setTypeLitPkg(n *Node) {
+	if pkg := templatePkgs[n]; pkg != nil && n.Type != types.Types[TINTER] {
+		n.Type.SetPkg(pkg)
+	}
+}
+
+// isInstance reports whether t is an instance of a generic type.
+func isInstance(t *types.Type) bool {
+	return typeInsts[t] != nil
+}
+
+// refersToInstance reports whether n refers to an instance of a
+// generic function or type. Importing packages cannot resolve such
+// references in inlined bodies.
+func refersToInstance(n *Node) bool {
+	if n.Op == ONAME && n.Class() == PFUNC && n.Name.Defn != nil && n.Name.Defn.Func.Instance() {
+		return true
+	}
+	return containsInstance(n.Type)
+}
+
+// containsInstance reports whether type t is or is composed of
+// instances of generic types.
+func containsInstance(t *types.Type) bool {
+	if t == nil {
+		return false
+	}
+	if isInstance(t) {
+		return true
+	}
+	if t.Sym != nil {
+		return false
+	}
+	switch t.Etype {
+	case TPTR, TSLICE, TARRAY, TCHAN:
+		return containsInstance(t.Elem())
+	case TMAP:
+		return containsInstance(t.Key()) || containsInstance(t.Elem())
+	case TFUNC:
+		return containsInstanceField(t.Recvs()) || containsInstanceField(t.Params()) || containsInstanceField(t.Results())
+	case TSTRUCT:
+		return containsInstanceField(t)
+	}
+	return false
+}
+
+func containsInstanceField(t *types.Type) bool {
+	for _, f := range t.FieldSlice() {
+		if containsInstance(f.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// genericDecl records the generic function or type declaration decl
+// named name.
+func (p *noder) genericDecl(decl syntax.Decl, name *syntax.Name) {
+	if !langSupported(1, 14, localpkg) {
+		p.yyerrorpos(decl.Pos(), "type parameters require go1.14 or later (-lang was set to %s; check go.mod)", flag_lang)
+	}
+	if Curfn != nil {
+		p.yyerrorpos(decl.Pos(), "generic type cannot be declared inside a function")
+		return
+	}
+	switch decl := decl.(type) {
+	case *syntax.FuncDecl:
+		switch {
+		case name.Value == "init":
+			p.yyerrorpos(decl.Pos(), "func init must have no type parameters")
+		case localpkg.Name == "main" && name.Value == "main":
+			p.yyerrorpos(decl.Pos(), "func main must have no type parameters")
+		case decl.Body == nil:
+			p.yyerrorpos(decl.Pos(), "missing function body")
+		}
+	case *syntax.TypeDecl:
+		if decl.Alias {
+			p.yyerrorpos(decl.Pos(), "generic type cannot be alias")
+		}
+	}
+
+	n := p.declName(name)
+	n.Op = OGENERIC
+	declare(n, dclcontext)
+	if n.isBlank() {
+		return
+	}
+	generics[n.Sym] = &generic{sym: n.Sym, tmpl: p.template(decl)}
+}
+
+// pendingMethods are the methods of generic types declared in the
+// package, attached to their types once all files are noded.
+var pendingMethods []pendingMethod
+
+type pendingMethod struct {
+	pos  src.XPos
+	base *types.Sym // generic receiver base type
+	tmpl *template
+}
+
+// genericMethod records the method decl of the generic type base.
+func (p *noder) genericMethod(decl *syntax.FuncDecl, base *syntax.Name) {
+	if decl.Body == nil {
+		p.yyerrorpos(decl.Pos(), "missing function body")
+	}
+	pendingMethods = append(pendingMethods, pendingMethod{
+		pos:  p.pos(decl),
+		base: p.name(base),
+		tmpl: p.template(decl),
+	})
+}
+
+// attachGenericMethods attaches the recorded methods of generic types
+// to their types.
+func attachGenericMethods() {
+	for _, m := range pendingMethods {
+		g := generics[m.base]
+		if g == nil || !g.isType() {
+			if n := asNode(m.base.Def); n == nil {
+				yyerrorl(m.pos, "undefined: %v", m.base)
+			} else {
+				yyerrorl(m.pos, "%v is not a generic type", m.base)
+			}
+			continue
+		}
+		g.addMethod(m.pos, m.tmpl)
+	}
+	pendingMethods = nil
+}
+
+// addMethod adds the method template m to generic type g.
+func (g *generic) addMethod(pos src.XPos, m *template) {
+	fun := m.syntax().(*syntax.FuncDecl)
+	_, tparams := recvTypeParams(fun.Recv)
+	if len(tparams) != len(g.tparamFields()) {
+		yyerrorl(pos, "got %d type parameters, but receiver base type declares %d", len(tparams), len(g.tparamFields()))
+		return
+	}
+	for _, m1 := range g.methods {
+		if m1.syntax().(*syntax.FuncDecl).Name.Value == fun.Name.Value {
+			yyerrorl(pos, "method redeclared: %v.%s", g.sym, fun.Name.Value)
+			return
+		}
+	}
+	g.methods = append(g.methods, m)
+}
+
+// recvTypeParams returns the base type name and the type parameter
+// names of a method receiver such as (l *List[T]). It returns nil if
+// the receiver base type is not instantiated.
+func recvTypeParams(recv *syntax.Field) (*syntax.Name, []*syntax.Name) {
+	typ := recv.Type
+	for {
+		if x, ok := typ.(*syntax.ParenExpr); ok {
+			typ = x.X
+			continue
+		}
+		if x, ok := typ.(*syntax.Operation); ok && x.Op == syntax.Mul && x.Y == nil {
+			typ = x.X
+			continue
+		}
+		break
+	}
+	index, ok := typ.(*syntax.IndexExpr)
+	if !ok {
+		return nil, nil
+	}
+	base, ok := index.X.(*syntax.Name)
+	if !ok {
+		return nil, nil
+	}
+	list := []syntax.Expr{index.Index}
+	if x, ok := index.Index.(*syntax.ListExpr); ok {
+		list = x.ElemList
+	}
+	var tparams []*syntax.Name
+	for _, x := range list {
+		name, ok := x.(*syntax.Name)
+		if !ok {
+			// Report the error when instantiating.
+			name = &syntax.Name{Value: "_"}
+		}
+		tparams = append(tparams, name)
+	}
+	return base, tparams
+}
+
+// recvTypeParamSyms returns the symbols in pkg of the receiver type
+// parameters of fun, a method of a generic type, and fun itself.
+// Blank type parameters cannot be bound to their type arguments by
+// name, so they are given hidden names, and fun is replaced by a copy
+// whose receiver type refers to them by those names.
+func recvTypeParamSyms(pkg *types.Pkg, fun *syntax.FuncDecl) ([]*types.Sym, *syntax.FuncDecl) {
+	_, names := recvTypeParams(fun.Recv)
+	syms := make([]*types.Sym, len(names))
+	hidden := false
+	for i, name := range names {
+		if name.Value == "_" {
+			syms[i] = pkg.Lookup(fmt.Sprintf(".blank%d", i))
+			hidden = true
+		} else {
+			syms[i] = pkg.Lookup(name.Value)
+		}
+	}
+	if !hidden {
+		return syms, fun
+	}
+
+	rename := func(x syntax.Expr, s *types.Sym) syntax.Expr {
+		if name, ok := x.(*syntax.Name); ok && name.Value == "_" {
+			c := *name
+			c.Value = s.Name
+			return &c
+		}
+		return x
+	}
+	var rewrite func(x syntax.Expr) syntax.Expr
+	rewrite = func(x syntax.Expr) syntax.Expr {
+		switch x := x.(type) {
+		case *syntax.ParenExpr:
+			c := *x
+			c.X = rewrite(x.X)
+			return &c
+		case *syntax.Operation:
+			c := *x
+			c.X = rewrite(x.X)
+			return &c
+		case *syntax.IndexExpr:
+			c := *x
+			if list, ok := x.Index.(*syntax.ListExpr); ok {
+				l := *list
+				l.ElemList = make([]syntax.Expr, len(list.ElemList))
+				for i, e := range list.ElemList {
+					l.ElemList[i] = rename(e, syms[i])
+				}
+				c.Index = &l
+			} else {
+				c.Index = rename(x.Index, syms[0])
+			}
+			return &c
+		}
+		return x
+	}
+
+	recv := *fun.Recv
+	recv.Type = rewrite(recv.Type)
+	c := *fun
+	c.Recv = &recv
+	return syms, &c
+}
+
+// template returns the template for the generic declaration decl.
+func (p *noder) template(decl syntax.Decl) *template {
+	p.markUsedImports(decl)
+	return &template{
+		pkg:     localpkg,
+		imports: p.imports,
+		decl:    decl,
+		text:    p.declText(decl),
+		basemap: p.basemap,
+	}
+}
+
+// markUsedImports marks the imports decl refers to as used: the
+// names in generic declarations are only resolved when instantiated.
+func (p *noder) markUsedImports(decl syntax.Decl) {
+	syntax.Walk(decl, func(x syntax.Node) bool {
+		if name, ok := x.(*syntax.Name); ok {
+			n := asNode(lookup(name.Value).Def)
+			switch {
+			case n == nil:
+			case n.Op == OPACK:
+				n.Name.SetUsed(true)
+			case n.Name != nil && n.Name.Pack != nil:
+				n.Name.Pack.Name.SetUsed(true)
+			}
+		}
+		return false
+	})
+}
+
+// declText returns the source text of the generic declaration decl,
+// starting with a line directive for its position.
+func (p *noder) declText(decl syntax.Decl) string {
+	pos := decl.Pos()
+	line := fmt.Sprintf("/*line %s:%d:%d*/", p.makeSrcPosBase(pos.Base()).AbsFilename(), pos.RelLine(), pos.RelCol())
+	switch decl := decl.(type) {
+	case *syntax.FuncDecl:
+		if decl.Body == nil || p.src == nil {
+			return ""
+		}
+		// Copy functions verbatim to preserve the positions
+		// within their bodies. decl.Pos is the position of the
+		// token following "func".
+		start := p.offset(pos)
+		end := p.offset(decl.Body.Rbrace) + 1
+		return "func " + line + string(p.src[start:end])
+
+	case *syntax.TypeDecl:
+		d := *decl
+		d.Group = nil
+		var buf bytes.Buffer
+		buf.WriteString(line)
+		syntax.Fprint(&buf, &d, false)
+		return buf.String()
+	}
+	panic("unhandled Decl")
+}
+
+// offset returns the offset of pos in the file being noded.
+func (p *noder) offset(pos syntax.Pos) int {
+	if p.lines == nil {
+		p.lines = append(p.lines, 0)
+		for i, b := range p.src {
+			if b == '\n' {
+				p.lines = append(p.lines, i+1)
+			}
+		}
+	}
+	return p.lines[pos.Line()-1] + int(pos.Col()) - 1
+}
+
+// syntax returns the syntax of template t.
+func (t *template) syntax() syntax.Decl {
+	if t.decl == nil {
+		base := syntax.NewFileBase(t.pkg.Path)
+		errh := func(err error) {
+			Fatalf("cannot parse generic declaration of package %q: %v", t.pkg.Path, err)
+		}
+		file, _ := syntax.Parse(base, strings.NewReader("package p; "+t.text), errh, nil, 0)
+		t.decl = file.DeclList[0]
+	}
+	return t.decl
+}
+
+// renode calls f with a noder for the syntax of template t, in a scope
+// where the imports of t are visible and the type parameter names
+// tparams denote the types targs.
+func (t *template) renode(tparams []*types.Sym, targs []*types.Type, f func(p *noder)) {
+	saveCurfn, saveDclcontext, saveLineno := Curfn, dclcontext, lineno
+	Curfn = nil
+	dclcontext = PEXTERN
+
+	types.Markdcl()
+	bind := func(s *types.Sym, def *Node) {
+		types.Pushdcl(s)
+		s.Def = asTypesNode(def)
+	}
+	if t.pkg != localpkg {
+		// Universe names are visible in localpkg only;
+		// see finishUniverse.
+		for _, s := range builtinpkg.Syms {
+			if s.Def != nil && t.pkg.Lookup(s.Name).Def == nil {
+				bind(t.pkg.Lookup(s.Name), asNode(s.Def))
+			}
+		}
+	}
+	for _, imp := range t.imports {
+		if imp.name == "." {
+			for _, s := range imp.pkg.Syms {
+				if s.Def != nil && types.IsExported(s.Name) && t.pkg.Lookup(s.Name).Def == nil {
+					bind(t.pkg.Lookup(s.Name), asNode(s.Def))
+				}
+			}
+			continue
+		}
+		s := t.pkg.Lookup(imp.name)
+		pack := nod(OPACK, nil, nil)
+		pack.Sym = s
+		pack.Name.Pkg = imp.pkg
+		pack.Name.SetUsed(true)
+		bind(s, pack)
+	}
+	for i, s := range tparams {
+		if !s.IsBlank() {
+			bind(s, typenod(targs[i]))
+		}
+	}
+
+	p := &noder{
+		basemap:  t.basemap,
+		pkg:      t.pkg,
+		imported: t.imported,
+	}
+	f(p)
+
+	types.Popdcl()
+	Curfn, dclcontext, lineno = saveCurfn, saveDclcontext, saveLineno
+}
+
+// atTopLevel calls f with the type checker set up as for package-level
+// declarations.
+func atTopLevel(f func()) {
+	saveCurfn, saveDecldepth, saveLineno := Curfn, decldepth, lineno
+	Curfn, decldepth = nil, 0
+	f()
+	Curfn, decldepth, lineno = saveCurfn, saveDecldepth, saveLineno
+}
+
+// isType reports whether g is a generic type.
+func (g *generic) isType() bool {
+	_, ok := g.tmpl.syntax().(*syntax.TypeDecl)
+	return ok
+}
+
+// tparamFields returns the type parameter list of g.
+func (g *generic) tparamFields() []*syntax.Field {
+	switch decl := g.tmpl.syntax().(type) {
+	case *syntax.FuncDecl:
+		return decl.TParamList
+	case *syntax.TypeDecl:
+		return decl.TParamList
+	}
+	panic("unhandled Decl")
+}
+
+// tparamSyms returns the symbols of the type parameter names of g.
+func (g *generic) tparamSyms() []*types.Sym {
+	var syms []*types.Sym
+	for _, f := range g.tparamFields() {
+		syms = append(syms, g.tmpl.pkg.Lookup(f.Name.Value))
+	}
+	return syms
+}
+
+// typeParams returns the type parameters of g. On first use, it
+// computes them and the signature of a generic function.
+func (g *generic) typeParams() []*types.Type {
+	if g.tparams != nil {
+		return g.tparams
+	}
+
+	fields := g.tparamFields()
+	syms := g.tparamSyms()
+	tparams := make([]*types.Type, len(fields))
+	for i, s := range syms {
+		tparams[i] = types.NewTypeParam(s, g.sym, i)
+	}
+	g.tparams = tparams
+
+	bounds := make([]*Node, len(fields))
+	var sig *Node
+	g.tmpl.renode(syms, tparams, func(p *noder) {
+		for i, f := range fields {
+			typenod(tparams[i]).Pos = p.pos(f)
+			bounds[i] = p.constraint(f.Type)
+		}
+		if fun, ok := g.tmpl.syntax().(*syntax.FuncDecl); ok {
+			sig = p.signature(nil, fun.Type)
+		}
+	})
+
+	atTopLevel(func() {
+		for i, b := range bounds {
+			b = typecheck(b, ctxType|ctxConstraint)
+			bound := b.Type
+			if bound == nil {
+				bound = types.Types[TINTER]
+			} else if !bound.IsInterface() {
+				bound = implicitInterface(bound)
+			}
+			tparams[i].TypeParam().Bound = bound
+		}
+		if sig != nil {
+			sig = typecheck(sig, ctxType)
+			g.sig = sig.Type
+		}
+	})
+	return tparams
+}
+
+// underlying returns the underlying type of generic type g, in terms
+// of its type parameters.
+func (g *generic) underlying() *types.Type {
+	n := g.instance(g.typeParams())
+	if n == nil || n.Type == nil {
+		return nil
+	}
+	return n.Type.Orig
+}
+
+// methodTypes returns the methods of generic type g, with signatures
+// in terms of the type parameters of g.
+func (g *generic) methodTypes() []*types.Field {
+	tparams := g.typeParams()
+	var ms []*types.Field
+	for _, m := range g.methods {
+		syms, fun := recvTypeParamSyms(m.pkg, m.syntax().(*syntax.FuncDecl))
+
+		f := types.NewField()
+		var sig *Node
+		m.renode(syms, tparams, func(p *noder) {
+			f.Pos = p.pos(fun)
+			f.Sym = p.fieldSym(fun.Name.Value)
+			sig = p.signature(fun.Recv, fun.Type)
+		})
+		atTopLevel(func() {
+			sig = typecheck(sig, ctxType)
+		})
+		if sig.Type == nil {
+			continue
+		}
+		f.Type = sig.Type
+		ms = append(ms, f)
+	}
+	return ms
+}
+
+// implicitInterface returns the constraint interface for the
+// non-interface constraint t, whose type set only contains t.
+func implicitInterface(t *types.Type) *types.Type {
+	f := types.NewField()
+	f.Type = t
+	it := types.New(TINTER)
+	it.SetInterface([]*types.Field{f})
+	return it
+}
+
+// coreTerm returns the single type term of the constraint of type
+// parameter tparam, if any.
+func coreTerm(tparam *types.Type) *types.Term {
+	terms, restricted := tparam.TypeParam().Bound.TypeTerms()
+	if !restricted || len(terms) != 1 || terms[0].Type.IsInterface() {
+		return nil
+	}
+	return terms[0]
+}
+
+// instName returns the name of the instance of generic name with type
+// arguments targs.
+func instName(name string, targs []*types.Type) string {
+	var buf bytes.Buffer
+	buf.WriteString(name)
+	buf.WriteByte('[')
+	for i, targ := range targs {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		s := targ.ShortString()
+		if myimportpath != "" {
+			s = strings.Replace(s, `"".`, objabi.PathToPrefix(myimportpath)+".", -1)
+		}
+		buf.WriteString(s)
+	}
+	buf.WriteByte(']')
+	return buf.String()
+}
+
+// instance returns the instance of g with type arguments targs: the
+// ONAME of a function or the OTYPE of a type. It reports an error and
+// returns nil if targs do not satisfy the constraints of g.
+func (g *generic) instance(targs []*types.Type) *Node {
+	tparams := g.typeParams()
+	if len(targs) != len(tparams) {
+		yyerror("got %d type arguments but %v has %d type parameters", len(targs), g.sym, len(tparams))
+		return nil
+	}
+	for _, targ := range targs {
+		if targ == nil || targ.Broke() {
+			return nil
+		}
+	}
+
+	if parameterized(targs...) {
+		if !g.isType() {
+			Fatalf("parameterized instance of generic function %v", g.sym)
+		}
+		return g.paramInstance(targs)
+	}
+
+	s := g.tmpl.pkg.Lookup(instName(g.sym.Name, targs))
+	if n := asNode(s.Def); n != nil {
+		return n
+	}
+	if !g.satisfied(targs) {
+		return nil
+	}
+	if instDepth >= maxInstDepth {
+		yyerror("instantiation cycle in %v", s)
+		return nil
+	}
+	instDepth++
+	defer func() { instDepth-- }()
+
+	if !g.isType() {
+		return g.instantiateFunc(s, targs)
+	}
+	n := g.instantiateType(s, targs, func(n *Node) {
+		s.Def = asTypesNode(n)
+		s.Lastlineno = n.Pos
+	})
+	if n.Type != nil {
+		for _, m := range g.methods {
+			instantiateMethod(m, targs)
+		}
+	}
+	return n
+}
+
+// paramInstance returns the instance of generic type g with the type
+// arguments targs, which contain type parameters. Such instances only
+// appear in generic signatures and constraints, and have no methods.
+func (g *generic) paramInstance(targs []*types.Type) *Node {
+	var key bytes.Buffer
+	for _, targ := range targs {
+		fmt.Fprintf(&key, "%p,", targ)
+	}
+	if n := g.params[key.String()]; n != nil {
+		return n
+	}
+	if g.params == nil {
+		g.params = make(map[string]*Node)
+	}
+
+	// Use a symbol of its own, which is not entered in
+	// the package.
+	s := &types.Sym{Name: instName(g.sym.Name, targs), Pkg: g.tmpl.pkg}
+	return g.instantiateType(s, targs, func(n *Node) {
+		g.params[key.String()] = n
+	})
+}
+
+// instantiateFunc returns the instance of generic function g with type
+// arguments targs, named by s.
+func (g *generic) instantiateFunc(s *types.Sym, targs []*types.Type) *Node {
+	if instantiationsClosed {
+		Fatalf("late instantiation of %v", s)
+	}
+
+	fun := g.tmpl.syntax().(*syntax.FuncDecl)
+	var fn *Node
+	g.tmpl.renode(g.tparamSyms(), targs, func(p *noder) {
+		t := p.signature(nil, fun.Type)
+		fn = p.nod(fun, ODCLFUNC, nil, nil)
+		fn.Func.Nname = newfuncnamel(p.pos(fun.Name), s)
+		fn.Func.Nname.Name.Defn = fn
+		fn.Func.Nname.Name.Param.Ntype = t
+		fn.Func.Pragma = fun.Pragma
+
+		// Declare the instance by hand: its symbol may belong
+		// to another package, and it is not exported.
+		fn.Func.Nname.SetClass(PFUNC)
+		s.SetFunc(true)
+		s.Def = asTypesNode(fn.Func.Nname)
+
+		p.funcBody(fn, fun.Body)
+	})
+	fn.Func.SetDupok(true)
+	fn.Func.SetInstance(true)
+
+	atTopLevel(func() {
+		fn = typecheck(fn, ctxStmt)
+	})
+	xtop = append(xtop, fn)
+	return fn.Func.Nname
+}
+
+// instantiateType returns the instance of generic type g with type
+// arguments targs, named by s, without its methods. It calls declare
+// with the instance before type checking it, so that recursive
+// references to the instance resolve.
+func (g *generic) instantiateType(s *types.Sym, targs []*types.Type, declare func(n *Node)) *Node {
+	decl := g.tmpl.syntax().(*syntax.TypeDecl)
+	var n *Node
+	g.tmpl.renode(g.tparamSyms(), targs, func(p *noder) {
+		n = dclname(s)
+		n.Pos = p.pos(decl.Name)
+		n.Op = OTYPE
+		n.Name.Param.Ntype = p.typeExprOrNil(decl.Type)
+		n.Name.Param.Pragma = decl.Pragma
+	})
+
+	// Declare the instance by hand; see instantiateFunc.
+	declare(n)
+
+	atTopLevel(func() {
+		n = typecheck(n, ctxType)
+	})
+	if n.Type != nil {
+		typeInsts[n.Type] = &typeInst{gen: g, targs: targs}
+	}
+	return n
+}
+
+// instantiateMethod instantiates the method template m of a generic
+// type with type arguments targs.
+func instantiateMethod(m *template, targs []*types.Type) {
+	tparams, fun := recvTypeParamSyms(m.pkg, m.syntax().(*syntax.FuncDecl))
+
+	var fn *Node
+	m.renode(tparams, targs, func(p *noder) {
+		fn = p.funcDecl(fun)
+	})
+	fn.Func.SetDupok(true)
+	fn.Func.SetInstance(true)
+
+	atTopLevel(func() {
+		fn = typecheck(fn, ctxStmt)
+	})
+	if instantiationsClosed {
+		// Only declare the method, like imported methods;
+		// see the comment on instantiationsClosed.
+		fn.Func.Nname.Name.Defn = nil
+		return
+	}
+	xtop = append(xtop, fn)
+}
+
+// satisfied reports whether the type arguments targs satisfy the
+// constraints of the type parameters of g, reporting an error if not.
+func (g *generic) satisfied(targs []*types.Type) bool {
+	tparams := g.typeParams()
+	bounds := make([]*types.Type, len(tparams))
+	var renode []*Node
+	for i, tparam := range tparams {
+		bounds[i] = tparam.TypeParam().Bound
+		if parameterized(bounds[i]) {
+			renode = make([]*Node, len(tparams))
+		}
+	}
+	if renode != nil {
+		// Constraints referring to type parameters are noded
+		// again, with the type arguments.
+		fields := g.tparamFields()
+		g.tmpl.renode(g.tparamSyms(), targs, func(p *noder) {
+			for i, f := range fields {
+				if parameterized(bounds[i]) {
+					renode[i] = p.constraint(f.Type)
+				}
+			}
+		})
+		for i, n := range renode {
+			if n == nil {
+				continue
+			}
+			atTopLevel(func() {
+				n = typecheck(n, ctxType|ctxConstraint)
+			})
+			if n.Type == nil {
+				return false
+			}
+			bounds[i] = n.Type
+			if !bounds[i].IsInterface() {
+				bounds[i] = implicitInterface(bounds[i])
+			}
+		}
+	}
+
+	ok := true
+	for i, targ := range targs {
+		if !satisfies(targ, bounds[i]) {
+			ok = false
+		}
+	}
+	return ok
+}
+
+// satisfies reports whether type t satisfies the constraint bound,
+// reporting an error if not.
+func satisfies(t, bound *types.Type) bool {
+	dowidth(bound)
+	if m := missingMethod(t, bound); m != nil {
+		yyerror("%v does not satisfy %v (missing method %v)", t, bound, m.Sym)
+		return false
+	}
+	if bound.IsComparableConstraint() && !IsComparable(t) {
+		yyerror("%v does not satisfy comparable", t)
+		return false
+	}
+	terms, restricted := bound.TypeTerms()
+	if !restricted {
+		return true
+	}
+	for _, term := range terms {
+		if matchTerm(term, t) {
+			return true
+		}
+	}
+	yyerror("%v does not satisfy %v (%v missing in %s)", t, bound, t, termsString(terms, FErr, 0))
+	return false
+}
+
+// matchTerm reports whether type t is in the type set of term.
+func matchTerm(term *types.Term, t *types.Type) bool {
+	switch {
+	case term.Type.IsEmptyInterface():
+		return true
+	case t.IsInterface():
+		return false
+	case term.Tilde:
+		return types.Identical(term.Type, t.Orig)
+	}
+	return types.Identical(term.Type, t)
+}
+
+// missingMethod returns a method of interface iface that type t does
+// not have, if any.
+func missingMethod(t, iface *types.Type) *types.Field {
+	if mt := methtype(t); mt != nil {
+		expandmeth(mt)
+	}
+	for _, im := range iface.Fields().Slice() {
+		if t.IsInterface() {
+			found := false
+			for _, tm := range t.Fields().Slice() {
+				if tm.Sym == im.Sym && types.Identical(tm.Type, im.Type) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return im
+			}
+			continue
+		}
+		tm, followptr := ifacelookdot(im.Sym, t, false)
+		if tm == nil || tm.Nointerface() || !types.Identical(tm.Type, im.Type) {
+			return im
+		}
+		if tm.Type.Recv().Type.IsPtr() && !t.IsPtr() && !followptr {
+			return im
+		}
+	}
+	return nil
+}
+
+// parameterized reports whether any of the types ts contains type
+// parameters.
+func parameterized(ts ...*types.Type) bool {
+	for _, t := range ts {
+		if isParameterized(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func isParameterized(t *types.Type) bool {
+	if t == nil {
+		return false
+	}
+	if inst := typeInsts[t]; inst != nil {
+		return parameterized(inst.targs...)
+	}
+	if t.Sym != nil {
+		return t.IsTypeParam()
+	}
+	switch t.Etype {
+	case TPTR, TSLICE, TARRAY, TCHAN:
+		return isParameterized(t.Elem())
+	case TMAP:
+		return isParameterized(t.Key()) || isParameterized(t.Elem())
+	case TFUNC:
+		return parameterizedFields(t.Recvs()) || parameterizedFields(t.Params()) || parameterizedFields(t.Results())
+	case TSTRUCT:
+		return parameterizedFields(t)
+	case TINTER:
+		terms, _ := t.TypeTerms()
+		for _, term := range terms {
+			if isParameterized(term.Type) {
+				return true
+			}
+		}
+		return parameterizedFields(t)
+	}
+	return false
+}
+
+func parameterizedFields(t *types.Type) bool {
+	for _, f := range t.FieldSlice() {
+		if isParameterized(f.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// genericOperand returns the generic function or type denoted by
+// operand n, if any.
+func genericOperand(n *Node) *generic {
+	for n.Op == OPAREN {
+		n = n.Left
+	}
+	n = resolve(n)
+	if n.Op != OGENERIC {
+		return nil
+	}
+	return generics[n.Sym]
+}
+
+// typeArgs type checks the type arguments of the instantiation n.
+// It returns nil after reporting an error.
+func typeArgs(n *Node) []*types.Type {
+	list := n.List.Slice()
+	if n.Right != nil {
+		list = []*Node{n.Right}
+	}
+	targs := make([]*types.Type, len(list))
+	for i, x := range list {
+		x = typecheck(x, ctxType)
+		if x.Type == nil {
+			return nil
+		}
+		if x.Op != OTYPE {
+			yyerror("%v is not a type", x)
+			return nil
+		}
+		targs[i] = x.Type
+	}
+	return targs
+}
+
+// instantiateIndex returns the instance denoted by the instantiation n
+// of generic g, or n with a nil Type after reporting an error.
+func instantiateIndex(n *Node, g *generic) *Node {
+	targs := typeArgs(n)
+	if targs == nil {
+		n.Type = nil
+		return n
+	}
+	if !g.isType() {
+		// Infer the missing type arguments from the
+		// constraints.
+		if targs = g.infer(targs, nil, false); targs == nil {
+			n.Type = nil
+			return n
+		}
+	}
+	inst := g.instance(targs)
+	if inst == nil {
+		n.Type = nil
+		return n
+	}
+	return inst
+}
+
+// instantiateCall instantiates the generic function called by n, if
+// any, inferring the type arguments missing from the instantiation
+// from the call arguments. It reports whether to go on checking n.
+func instantiateCall(n *Node) bool {
+	var g *generic
+	var targs []*types.Type
+	if x := unparen(n.Left); x.Op == OINDEX {
+		g = genericOperand(x.Left)
+		if g == nil || g.isType() {
+			return true
+		}
+		if targs = typeArgs(x); targs == nil {
+			return false
+		}
+	} else {
+		g = genericOperand(n.Left)
+		if g == nil || g.isType() {
+			return true
+		}
+	}
+
+	typecheckargs(n)
+	targs = g.infer(targs, n.List.Slice(), n.IsDDD())
+	if targs == nil {
+		return false
+	}
+	inst := g.instance(targs)
+	if inst == nil {
+		return false
+	}
+	n.Left = inst
+	return true
+}
+
+// infer returns the type arguments of generic function g called with
+// arguments args, given the leading type arguments targs. It returns
+// nil after reporting an error.
+func (g *generic) infer(targs []*types.Type, args []*Node, isddd bool) []*types.Type {
+	tparams := g.typeParams()
+	if len(targs) > len(tparams) {
+		yyerror("got %d type arguments but %v has %d type parameters", len(targs), g.sym, len(tparams))
+		return nil
+	}
+	if len(targs) == len(tparams) {
+		return targs
+	}
+	for _, arg := range args {
+		if arg.Type == nil {
+			return nil
+		}
+	}
+
+	u := &unifier{tparams: tparams, targs: make([]*types.Type, len(tparams))}
+	copy(u.targs, targs)
+
+	// Unify the parameter types with the types of the typed
+	// arguments, and collect the untyped arguments for
+	// parameters of type parameter type.
+	params := g.sig.Params().FieldSlice()
+	untyped := make([][]*Node, len(tparams))
+	for i, arg := range args {
+		var pt *types.Type
+		switch {
+		case g.sig.IsVariadic() && i >= len(params)-1:
+			pt = params[len(params)-1].Type
+			if !isddd {
+				pt = pt.Elem()
+			}
+		case i < len(params):
+			pt = params[i].Type
+		default:
+			continue // reported when checking the call
+		}
+		if arg.Type.IsUntyped() {
+			if k := u.index(pt); k >= 0 {
+				untyped[k] = append(untyped[k], arg)
+			}
+			continue
+		}
+		if !u.unify(pt, arg.Type) {
+			yyerror("in call to %v, type %v of %v does not match %v", g.sym, arg.Type, arg, pt)
+			return nil
+		}
+	}
+	u.inferCore()
+
+	// Use the default types of untyped arguments, for type
+	// parameters still unknown.
+	for k, list := range untyped {
+		if u.targs[k] != nil || len(list) == 0 {
+			continue
+		}
+		kind := CTxxx
+		for _, arg := range list {
+			k1 := idealkind(arg)
+			if k1 == CTNIL || k1 == CTxxx {
+				kind = CTxxx
+				break
+			}
+			if kind != CTxxx && (kind >= CTSTR || k1 >= CTSTR) && k1 != kind {
+				yyerror("in call to %v, mismatched types %v and %v (cannot infer %v)", g.sym, list[0].Type, arg.Type, tparams[k])
+				return nil
+			}
+			if k1 > kind {
+				kind = k1
+			}
+		}
+		if kind != CTxxx {
+			u.targs[k] = defaultType(kind)
+		}
+	}
+	u.inferCore()
+
+	for k, targ := range u.targs {
+		if targ == nil {
+			yyerror("in call to %v, cannot infer %v", g.sym, tparams[k])
+			return nil
+		}
+	}
+	return u.targs
+}
+
+// A unifier infers the type arguments targs of the type parameters
+// tparams by unifying types containing them with other types.
+type unifier struct {
+	tparams []*types.Type
+	targs   []*types.Type
+}
+
+// index returns the index of type parameter t in u.tparams, or -1.
+func (u *unifier) index(t *types.Type) int {
+	if !t.IsTypeParam() {
+		return -1
+	}
+	i := t.TypeParam().Index
+	if i < len(u.tparams) && u.tparams[i] == t {
+		return i
+	}
+	return -1
+}
+
+// inferCore infers type arguments from the core types of the
+// constraints of the type parameters.
+func (u *unifier) inferCore() {
+	for changed := true; changed; {
+		changed = false
+		for i, tparam := range u.tparams {
+			term := coreTerm(tparam)
+			if term == nil {
+				continue
+			}
+			if u.targs[i] == nil {
+				if t := u.subst(term.Type); t != nil {
+					u.targs[i] = t
+					changed = true
+				}
+				continue
+			}
+			known := u.known()
+			t := u.targs[i]
+			if term.Tilde {
+				t = t.Orig
+			}
+			u.unify(term.Type, t) // mismatches are reported when checking constraints
+			if u.known() > known {
+				changed = true
+			}
+		}
+	}
+}
+
+// known returns the number of type arguments inferred so far.
+func (u *unifier) known() int {
+	n := 0
+	for _, t := range u.targs {
+		if t != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// unify unifies type x, which may contain type parameters, with type y,
+// recording the type arguments this infers. It reports whether x and y
+// match. Unification is inexact: a defined type matches a type literal
+// with the same underlying type.
+func (u *unifier) unify(x, y *types.Type) bool {
+	if i := u.index(x); i >= 0 {
+		t := u.targs[i]
+		switch {
+		case t == nil:
+			u.targs[i] = y
+		case types.Identical(t, y):
+		case t.Sym != nil && y.Sym == nil && types.Identical(t.Orig, y):
+		case t.Sym == nil && y.Sym != nil && types.Identical(t, y.Orig):
+			u.targs[i] = y
+		default:
+			return false
+		}
+		return true
+	}
+	if !isParameterized(x) {
+		// Mismatches are reported when checking the call.
+		return true
+	}
+
+	if xi := typeInsts[x]; xi != nil {
+		if yi := typeInsts[y]; yi != nil && yi.gen == xi.gen {
+			for i, targ := range xi.targs {
+				if !u.unify(targ, yi.targs[i]) {
+					return false
+				}
+			}
+			return true
+		}
+		x = x.Orig
+	}
+	if y.Sym != nil {
+		y = y.Orig
+	}
+	if x.Etype != y.Etype {
+		return false
+	}
+
+	switch x.Etype {
+	case TPTR, TSLICE, TCHAN:
+		return u.unify(x.Elem(), y.Elem())
+	case TARRAY:
+		return x.NumElem() == y.NumElem() && u.unify(x.Elem(), y.Elem())
+	case TMAP:
+		return u.unify(x.Key(), y.Key()) && u.unify(x.Elem(), y.Elem())
+	case TFUNC:
+		if x.NumParams() != y.NumParams() || x.NumResults() != y.NumResults() || x.IsVariadic() != y.IsVariadic() {
+			return false
+		}
+		return u.unifyFields(x.Params(), y.Params()) && u.unifyFields(x.Results(), y.Results())
+	case TSTRUCT:
+		if x.NumFields() != y.NumFields() {
+			return false
+		}
+		for i, f := range x.FieldSlice() {
+			if f.Sym != y.Field(i).Sym || f.Embedded != y.Field(i).Embedded {
+				return false
+			}
+		}
+		return u.unifyFields(x, y)
+	}
+	return true
+}
+
+func (u *unifier) unifyFields(x, y *types.Type) bool {
+	for i, f := range x.FieldSlice() {
+		if !u.unify(f.Type, y.Field(i).Type) {
+			return false
+		}
+	}
+	return true
+}
+
+// subst returns type t with the type parameters of u replaced by their
+// inferred type arguments, or nil if some are not known.
+func (u *unifier) subst(t *types.Type) *types.Type {
+	if i := u.index(t); i >= 0 {
+		return u.targs[i]
+	}
+	if !isParameterized(t) {
+		return t
+	}
+	if inst := typeInsts[t]; inst != nil {
+		targs := make([]*types.Type, len(inst.targs))
+		for i, targ := range inst.targs {
+			if targs[i] = u.subst(targ); targs[i] == nil {
+				return nil
+			}
+		}
+		if n := inst.gen.instance(targs); n != nil {
+			return n.Type
+		}
+		return nil
+	}
+
+	switch t.Etype {
+	case TPTR, TSLICE, TARRAY, TCHAN:
+		elem := u.subst(t.Elem())
+		if elem == nil {
+			return nil
+		}
+		switch t.Etype {
+		case TPTR:
+			return types.NewPtr(elem)
+		case TSLICE:
+			return types.NewSlice(elem)
+		case TARRAY:
+			return types.NewArray(elem, t.NumElem())
+		}
+		return types.NewChan(elem, t.ChanDir())
+	case TMAP:
+		key, elem := u.subst(t.Key()), u.subst(t.Elem())
+		if key == nil || elem == nil {
+			return nil
+		}
+		return types.NewMap(key, elem)
+	case TFUNC:
+		params, results := u.substFields(t.Params()), u.substFields(t.Results())
+		if params == nil || results == nil {
+			return nil
+		}
+		return functypefield(nil, params, results)
+	}
+	return nil
+}
+
+func (u *unifier) substFields(t *types.Type) []*types.Field {
+	fields := []*types.Field{}
+	for _, f := range t.FieldSlice() {
+		typ := u.subst(f.Type)
+		if typ == nil {
+			return nil
+		}
+		f1 := types.NewField()
+		f1.Pos = f.Pos
+		f1.Sym = f.Sym
+		f1.Type = typ
+		f1.SetIsDDD(f.IsDDD())
+		fields = append(fields, f1)
+	}
+	return fields
+}
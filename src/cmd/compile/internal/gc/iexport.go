@@ -51,7 +51,7 @@
 // section where the associated declaration can be found.
 //
 //
-// There are five kinds of declarations, distinguished by their first
+// There are seven kinds of declarations, distinguished by their first
 // byte:
 //
 //     type Var struct {
@@ -91,6 +91,38 @@
 //         Type typeOff
 //     }
 //
+//     type GenericFunc struct {
+//         Tag        byte // 'G'
+//         Pos        Pos
+//         TypeParams []TypeParam
+//         Signature  Signature
+//     }
+//
+//     type GenericType struct {
+//         Tag        byte // 'U'
+//         Pos        Pos
+//         TypeParams []TypeParam
+//         Underlying typeOff
+//
+//         Methods []struct{
+//             Pos       Pos
+//             Name      stringOff
+//             Recv      Param
+//             Signature Signature
+//         }
+//     }
+//
+//     type TypeParam struct {
+//         Pos        Pos
+//         Name       stringOff
+//         Constraint typeOff
+//     }
+//
+// The signatures, underlying types and methods of generic
+// declarations are in terms of their type parameters. The receiver
+// type of a method of a generic type is the type instantiated with
+// its own type parameters.
+//
 //
 // typeOff means a uvarint that either indicates a predeclared type,
 // or an offset into the Data section. If the uvarint is less than
@@ -102,7 +134,7 @@
 // (*exportWriter).value for details.
 //
 //
-// There are nine kinds of type descriptors, distinguished by an itag:
+// There are twelve kinds of type descriptors, distinguished by an itag:
 //
 //     type DefinedType struct {
 //         Tag     itag // definedType
@@ -170,6 +202,30 @@
 //         }
 //     }
 //
+//     type TypeParamType struct {
+//         Tag     itag // typeParamType
+//         Name    stringOff // generic declaration
+//         PkgPath stringOff
+//         Index   uint64
+//     }
+//
+//     type InstanceType struct {
+//         Tag      itag // instanceType
+//         Name     stringOff // generic type
+//         PkgPath  stringOff
+//         TypeArgs []typeOff
+//     }
+//
+//     type UnionType struct {
+//         Tag   itag // unionType
+//         Terms []struct {
+//             Tilde bool
+//             Type  typeOff
+//         }
+//     }
+//
+// Union types only appear as embedded elements of interface types.
+//
 //
 //     type Signature struct {
 //         Params   []Param
@@ -202,6 +258,7 @@ package gc
 import (
 	"bufio"
 	"bytes"
+	"cmd/compile/internal/syntax"
 	"cmd/compile/internal/types"
 	"cmd/internal/goobj2"
 	"cmd/internal/src"
@@ -214,9 +271,10 @@ import (
 )
 
 // Current indexed export format version. Increase with each format change.
+// 2: added generic declarations and type parameter, instance and union types
 // 1: added column details to Pos
 // 0: Go1.11 encoding
-const iexportVersion = 1
+const iexportVersion = 2
 
 // predeclReserved is the number of type offsets reserved for types
 // implicitly declared in the universe block.
@@ -237,6 +295,9 @@ const (
 	signatureType
 	structType
 	interfaceType
+	typeParamType
+	instanceType
+	unionType
 )
 
 func iexport(out *bufio.Writer) {
@@ -247,7 +308,9 @@ func iexport(out *bufio.Writer) {
 		p := &exporter{marked: make(map[*types.Type]bool)}
 		for _, n := range exportlist {
 			sym := n.Sym
-			p.markType(asNode(sym.Def).Type)
+			if n := asNode(sym.Def); n.Op != OGENERIC {
+				p.markType(n.Type)
+			}
 		}
 	}
 
@@ -447,6 +510,34 @@ func (p *iexporter) doDecl(n *Node) {
 		w.pos(n.Pos)
 		w.value(n.Type, n.Val())
 
+	case OGENERIC:
+		g := generics[n.Sym]
+		if !g.isType() {
+			// Generic function.
+			w.tag('G')
+			w.pos(n.Pos)
+			w.typeParams(g)
+			w.signature(g.sig)
+			w.genericExt(g)
+			break
+		}
+
+		// Generic type.
+		w.tag('U')
+		w.pos(n.Pos)
+		w.typeParams(g)
+		w.typ(g.underlying())
+
+		ms := g.methodTypes()
+		w.uint64(uint64(len(ms)))
+		for _, m := range ms {
+			w.pos(m.Pos)
+			w.selector(m.Sym)
+			w.param(m.Type.Recv())
+			w.signature(m.Type)
+		}
+		w.genericExt(g)
+
 	case OTYPE:
 		if IsAlias(n.Sym) {
 			// Alias.
@@ -619,6 +710,23 @@ func (w *exportWriter) startType(k itag) {
 }
 
 func (w *exportWriter) doTyp(t *types.Type) {
+	if inst := typeInsts[t]; inst != nil {
+		w.startType(instanceType)
+		w.qualifiedIdent(asNode(inst.gen.sym.Def))
+		w.uint64(uint64(len(inst.targs)))
+		for _, targ := range inst.targs {
+			w.typ(targ)
+		}
+		return
+	}
+
+	if t.IsTypeParam() {
+		w.startType(typeParamType)
+		w.qualifiedIdent(asNode(t.TypeParam().Owner.Def))
+		w.uint64(uint64(t.TypeParam().Index))
+		return
+	}
+
 	if t.Sym != nil {
 		if t.Sym.Pkg == builtinpkg || t.Sym.Pkg == unsafepkg {
 			Fatalf("builtin type missing from typIndex: %v", t)
@@ -697,6 +805,15 @@ func (w *exportWriter) doTyp(t *types.Type) {
 			w.signature(f.Type)
 		}
 
+	case TUNION:
+		w.startType(unionType)
+		terms := t.Terms()
+		w.uint64(uint64(len(terms)))
+		for _, term := range terms {
+			w.bool(term.Tilde)
+			w.typ(term.Type)
+		}
+
 	default:
 		Fatalf("unexpected type: %v", t)
 	}
@@ -724,6 +841,16 @@ func (w *exportWriter) signature(t *types.Type) {
 	}
 }
 
+func (w *exportWriter) typeParams(g *generic) {
+	tparams := g.typeParams()
+	w.uint64(uint64(len(tparams)))
+	for _, tparam := range tparams {
+		w.pos(asNode(tparam.Nod).Pos)
+		w.string(tparam.Sym.Name)
+		w.typ(tparam.TypeParam().Bound)
+	}
+}
+
 func (w *exportWriter) paramList(fs []*types.Field) {
 	w.uint64(uint64(len(fs)))
 	for _, f := range fs {
@@ -981,6 +1108,85 @@ func (w *exportWriter) funcExt(n *Node) {
 	}
 }
 
+// genericExt writes the templates of a generic declaration, which
+// importers instantiate from source.
+func (w *exportWriter) genericExt(g *generic) {
+	w.template(g.tmpl)
+	w.uint64(uint64(len(g.methods)))
+	for _, m := range g.methods {
+		w.template(m)
+	}
+}
+
+func (w *exportWriter) template(t *template) {
+	w.uint64(uint64(len(t.imports)))
+	for _, imp := range t.imports {
+		w.string(imp.name)
+		w.pkg(imp.pkg)
+	}
+	w.string(t.text)
+
+	w.p.pushTemplateDeps(t)
+}
+
+// pushTemplateDeps adds the package-level declarations that template t
+// may refer to to the declaration work queue, so that importers can
+// resolve the names in t.
+func (p *iexporter) pushTemplateDeps(t *template) {
+	pkgs := make(map[string]*types.Pkg)
+	var dots []*types.Pkg
+	for _, imp := range t.imports {
+		if imp.name == "." {
+			dots = append(dots, imp.pkg)
+		} else {
+			pkgs[imp.name] = imp.pkg
+		}
+	}
+
+	push := func(s *types.Sym) {
+		n := asNode(s.Def)
+		if n == nil {
+			return
+		}
+		if n.Op == ONONAME {
+			expandDecl(n)
+		}
+		switch n.Op {
+		case ONAME:
+			if n.Class() != PEXTERN && n.Class() != PFUNC {
+				return
+			}
+		case OLITERAL, OTYPE, OGENERIC:
+		default:
+			return
+		}
+		if n.Sym != s || isInstance(n.Type) {
+			return
+		}
+		p.pushDecl(n)
+	}
+
+	// This over-approximates the names t refers to: local names
+	// and field and method names may match package-level names
+	// too, which is harmless.
+	syntax.Walk(t.syntax(), func(x syntax.Node) bool {
+		switch x := x.(type) {
+		case *syntax.SelectorExpr:
+			if name, ok := x.X.(*syntax.Name); ok {
+				if pkg := pkgs[name.Value]; pkg != nil {
+					push(pkg.Lookup(x.Sel.Value))
+				}
+			}
+		case *syntax.Name:
+			push(t.pkg.Lookup(x.Value))
+			for _, pkg := range dots {
+				push(pkg.Lookup(x.Value))
+			}
+		}
+		return false
+	})
+}
+
 func (w *exportWriter) methExt(m *types.Field) {
 	w.bool(m.Nointerface())
 	w.funcExt(asNode(m.Type.Nname()))
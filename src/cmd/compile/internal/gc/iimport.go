@@ -8,6 +8,7 @@
 package gc
 
 import (
+	"cmd/compile/internal/syntax"
 	"cmd/compile/internal/types"
 	"cmd/internal/bio"
 	"cmd/internal/obj"
@@ -346,6 +347,31 @@ func (r *importReader) doDecl(n *Node) {
 		importvar(r.p.ipkg, pos, n.Sym, typ)
 		r.varExt(n)
 
+	case 'G', 'U':
+		// Generic declarations are instantiated from their
+		// templates, so skip their typed description.
+		r.skipTypeParams()
+		if tag == 'G' {
+			r.skipSignature()
+		} else {
+			r.uint64() // underlying type
+			for i := r.uint64(); i > 0; i-- {
+				r.pos()
+				r.string()
+				r.skipParam()
+				r.skipSignature()
+			}
+		}
+
+		n.Op = OGENERIC
+		n.Pos = pos
+		g := &generic{sym: n.Sym, tmpl: r.template()}
+		g.methods = make([]*template, r.uint64())
+		for i := range g.methods {
+			g.methods[i] = r.template()
+		}
+		generics[n.Sym] = g
+
 	default:
 		Fatalf("unexpected tag: %v", tag)
 	}
@@ -586,6 +612,45 @@ func (r *importReader) typ1() *types.Type {
 		checkwidth(t)
 
 		return t
+
+	case typeParamType:
+		// Type parameters only appear in the typed description
+		// of generic declarations, which the compiler skips.
+		Fatalf("unexpected type parameter in %q", r.p.ipkg.Path)
+		return nil
+
+	case instanceType:
+		n := asNode(r.qualifiedIdent().PkgDef())
+		if n.Op == ONONAME {
+			expandDecl(n)
+		}
+		g := generics[n.Sym]
+		if g == nil {
+			Fatalf("expected generic type, got %v: %v, %v", n.Op, n.Sym, n)
+		}
+		targs := make([]*types.Type, r.uint64())
+		for i := range targs {
+			targs[i] = r.typ()
+		}
+
+		// Instantiating type checks the instance, which may
+		// import further declarations.
+		saveInimport := inimport
+		inimport = false
+		inst := g.instance(targs)
+		inimport = saveInimport
+		if inst == nil || inst.Type == nil {
+			Fatalf("cannot instantiate %v in %q", n.Sym, r.p.ipkg.Path)
+		}
+		return inst.Type
+
+	case unionType:
+		terms := make([]*types.Term, r.uint64())
+		for i := range terms {
+			terms[i] = &types.Term{Tilde: r.bool()}
+			terms[i].Type = r.typ()
+		}
+		return types.NewUnion(terms)
 	}
 }
 
@@ -620,6 +685,33 @@ func (r *importReader) param() *types.Field {
 	return f
 }
 
+func (r *importReader) skipTypeParams() {
+	for i := r.uint64(); i > 0; i-- {
+		r.pos()
+		r.string()
+		r.uint64() // constraint
+	}
+}
+
+func (r *importReader) skipSignature() {
+	n := r.uint64()
+	for i := n; i > 0; i-- {
+		r.skipParam()
+	}
+	for i := r.uint64(); i > 0; i-- {
+		r.skipParam()
+	}
+	if n > 0 {
+		r.bool()
+	}
+}
+
+func (r *importReader) skipParam() {
+	r.pos()
+	r.string()
+	r.uint64() // type
+}
+
 func (r *importReader) bool() bool {
 	return r.uint64() != 0
 }
@@ -668,13 +760,32 @@ func (r *importReader) funcExt(n *Node) {
 
 	// Inline body.
 	if u := r.uint64(); u > 0 {
-		n.Func.Inl = &Inline{
-			Cost: int32(u - 1),
+		// Functions only referenced by the templates of
+		// generic declarations may lack exported bodies.
+		if _, ok := inlineImporter[n.Sym]; ok {
+			n.Func.Inl = &Inline{
+				Cost: int32(u - 1),
+			}
 		}
 		n.Func.Endlineno = r.pos()
 	}
 }
 
+func (r *importReader) template() *template {
+	imports := make([]templateImport, r.uint64())
+	for i := range imports {
+		imports[i].name = r.string()
+		imports[i].pkg = r.pkg()
+	}
+	return &template{
+		pkg:      r.currPkg,
+		imports:  imports,
+		text:     r.string(),
+		imported: true,
+		basemap:  make(map[*syntax.PosBase]*src.PosBase),
+	}
+}
+
 func (r *importReader) methExt(m *types.Field) {
 	if r.bool() {
 		m.SetNointerface(true)
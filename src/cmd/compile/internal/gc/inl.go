@@ -46,6 +46,13 @@ const (
 
 	inlineBigFunctionNodes   = 5000 // Functions with this many nodes are considered "big".
 	inlineBigFunctionMaxCost = 20   // Max cost of inlinee when inlining into a "big" function.
+
+	// With -pgoprofile, the call edges that together account for this
+	// percentage of the profile's weight are hot. Functions called by a
+	// hot edge may cost up to inlineHotMaxBudget, and are inlined at
+	// the call sites of hot edges only.
+	inlineCDFHotCallSiteThresholdPercent = 99
+	inlineHotMaxBudget                   = 2000
 )
 
 // Get the function's package. For ordinary functions it's on the ->sym, but for imported methods
@@ -170,6 +177,13 @@ func caninl(fn *Node) {
 		return
 	}
 
+	// Instances of generic declarations are compiled by each
+	// package using them, and their bodies cannot be exported.
+	if fn.Func.Instance() {
+		reason = "instance of generic declaration"
+		return
+	}
+
 	// If fn has no body (is defined outside of Go), cannot inline it.
 	if fn.Nbody.Len() == 0 {
 		reason = "no function body"
@@ -200,8 +214,13 @@ func caninl(fn *Node) {
 	// locals, and we use this map to produce a pruned Inline.Dcl
 	// list. See issue 25249 for more context.
 
+	budget := int32(inlineMaxBudget)
+	if pgohotfunc(fn) {
+		budget = inlineHotMaxBudget
+	}
+
 	visitor := hairyVisitor{
-		budget:        inlineMaxBudget,
+		budget:        budget,
 		extraCallCost: cc,
 		usedLocals:    make(map[*Node]bool),
 	}
@@ -210,12 +229,12 @@ func caninl(fn *Node) {
 		return
 	}
 	if visitor.budget < 0 {
-		reason = fmt.Sprintf("function too complex: cost %d exceeds budget %d", inlineMaxBudget-visitor.budget, inlineMaxBudget)
+		reason = fmt.Sprintf("function too complex: cost %d exceeds budget %d", budget-visitor.budget, budget)
 		return
 	}
 
 	n.Func.Inl = &Inline{
-		Cost: inlineMaxBudget - visitor.budget,
+		Cost: budget - visitor.budget,
 		Dcl:  inlcopylist(pruneUnusedAutos(n.Name.Defn.Func.Dcl, &visitor)),
 		Body: inlcopylist(fn.Nbody.Slice()),
 	}
@@ -230,7 +249,7 @@ func caninl(fn *Node) {
 		fmt.Printf("%v: can inline %v\n", fn.Line(), n)
 	}
 	if logopt.Enabled() {
-		logopt.LogOpt(fn.Pos, "canInlineFunction", "inline", fn.funcname(), fmt.Sprintf("cost: %d", inlineMaxBudget-visitor.budget))
+		logopt.LogOpt(fn.Pos, "canInlineFunction", "inline", fn.funcname(), fmt.Sprintf("cost: %d", budget-visitor.budget))
 	}
 }
 
@@ -301,6 +320,11 @@ func (v *hairyVisitor) visit(n *Node) bool {
 		return false
 	}
 
+	if refersToInstance(n) {
+		v.reason = "reference to instance of generic declaration"
+		return true
+	}
+
 	switch n.Op {
 	// Call is okay if inlinable and we have the budget for the body.
 	case OCALLFUNC:
@@ -325,12 +349,12 @@ func (v *hairyVisitor) visit(n *Node) bool {
 			break
 		}
 
-		if fn := n.Left.Func; fn != nil && fn.Inl != nil {
+		if fn := n.Left.Func; fn != nil && fn.Inl != nil && fn.Inl.Cost <= inlineMaxBudget {
 			v.budget -= fn.Inl.Cost
 			break
 		}
 		if n.Left.isMethodExpression() {
-			if d := asNode(n.Left.Sym.Def); d != nil && d.Func.Inl != nil {
+			if d := asNode(n.Left.Sym.Def); d != nil && d.Func.Inl != nil && d.Func.Inl.Cost <= inlineMaxBudget {
 				v.budget -= d.Func.Inl.Cost
 				break
 			}
@@ -361,7 +385,7 @@ func (v *hairyVisitor) visit(n *Node) bool {
 				break
 			}
 		}
-		if inlfn := asNode(t.FuncType().Nname).Func; inlfn.Inl != nil {
+		if inlfn := asNode(t.FuncType().Nname).Func; inlfn.Inl != nil && inlfn.Inl.Cost <= inlineMaxBudget {
 			v.budget -= inlfn.Inl.Cost
 			break
 		}
@@ -832,9 +856,11 @@ func mkinlcall(n, fn *Node, maxCost int32) *Node {
 		// No inlinable body.
 		return n
 	}
-	if fn.Func.Inl.Cost > maxCost {
+	if fn.Func.Inl.Cost > maxCost && !(maxCost == inlineMaxBudget && pgohotcall(n, fn)) {
 		// The inlined function body is too big. Typically we use this check to restrict
 		// inlining into very big functions.  See issue 26546 and 17566.
+		// Functions inlinable only thanks to a PGO profile are also too big,
+		// except at hot call sites in functions that are not big.
 		if logopt.Enabled() {
 			logopt.LogOpt(n.Pos, "cannotInlineCall", "inline", Curfn.funcname(),
 				fmt.Sprintf("cost %d of %s exceeds max large caller cost %d", fn.Func.Inl.Cost, fn.pkgFuncName(), maxCost))
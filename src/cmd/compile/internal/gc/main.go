@@ -46,6 +46,7 @@ var (
 	Debug_compilelater int
 	debug_dclstack     int
 	Debug_libfuzzer    int
+	Debug_fuzzcounters int
 	Debug_panic        int
 	Debug_slice        int
 	Debug_vlog         bool
@@ -56,6 +57,8 @@ var (
 	Debug_gendwarfinl  int
 	Debug_softfloat    int
 	Debug_defer        int
+	Debug_pgoinline    int
+	Debug_pgodevirt    int
 )
 
 // Debug arguments.
@@ -76,6 +79,7 @@ var debugtab = []struct {
 	{"dclstack", "run internal dclstack check", &debug_dclstack},
 	{"gcprog", "print dump of GC programs", &Debug_gcprog},
 	{"libfuzzer", "coverage instrumentation for libfuzzer", &Debug_libfuzzer},
+	{"fuzzcounters", "coverage counters for go test -fuzz", &Debug_fuzzcounters},
 	{"nil", "print information about nil checks", &Debug_checknil},
 	{"panic", "do not hide any compiler panic", &Debug_panic},
 	{"slice", "print information about slice compilation", &Debug_slice},
@@ -88,6 +92,8 @@ var debugtab = []struct {
 	{"dwarfinl", "print information about DWARF inlined function creation", &Debug_gendwarfinl},
 	{"softfloat", "force compiler to emit soft-float code", &Debug_softfloat},
 	{"defer", "print information about defer compilation", &Debug_defer},
+	{"pgoinline", "enable profile-guided inlining", &Debug_pgoinline},
+	{"pgodevirtualize", "enable profile-guided devirtualization", &Debug_pgodevirt},
 }
 
 const debugHelpHeader = `usage: -d arg[,arg]* and arg is <key>[=<value>]
@@ -232,6 +238,7 @@ func Main(archInit func(*Arch)) {
 	objabi.Flagcount("h", "halt on error", &Debug['h'])
 	objabi.Flagfn1("importmap", "add `definition` of the form source=actual to import map", addImportMap)
 	objabi.Flagfn1("importcfg", "read import configuration from `file`", readImportCfg)
+	objabi.Flagfn1("embedcfg", "read go:embed configuration from `file`", readEmbedCfg)
 	flag.StringVar(&flag_installsuffix, "installsuffix", "", "set pkg directory `suffix`")
 	objabi.Flagcount("j", "debug runtime-initialized variables", &Debug['j'])
 	objabi.Flagcount("l", "disable inlining", &Debug['l'])
@@ -244,6 +251,7 @@ func Main(archInit func(*Arch)) {
 	}
 	flag.BoolVar(&nolocalimports, "nolocalimports", false, "reject local (relative) imports")
 	flag.StringVar(&outfile, "o", "", "write output to `file`")
+	flag.StringVar(&pgoprofile, "pgoprofile", "", "read profile from `file` for profile-guided optimization")
 	flag.StringVar(&myimportpath, "p", "", "set expected package import `path`")
 	flag.BoolVar(&writearchive, "pack", false, "write to file.a instead of file.o")
 	objabi.Flagcount("r", "debug generated wrappers", &Debug['r'])
@@ -382,6 +390,11 @@ func Main(archInit func(*Arch)) {
 		log.Fatalf("location lists requested but register mapping not available on %v", Ctxt.Arch.Name)
 	}
 
+	// Profile-guided optimizations are on by default when
+	// a profile is given; -d can turn them off below.
+	Debug_pgoinline = 1
+	Debug_pgodevirt = 1
+
 	// parse -d argument
 	if debugstr != "" {
 	Split:
@@ -461,6 +474,7 @@ func Main(archInit func(*Arch)) {
 
 		// Fuzzing the runtime isn't interesting either.
 		Debug_libfuzzer = 0
+		Debug_fuzzcounters = 0
 	}
 
 	// set via a -d flag
@@ -485,6 +499,10 @@ func Main(archInit func(*Arch)) {
 		logopt.LogJsonOption(jsonLogOpt)
 	}
 
+	if pgoprofile != "" {
+		readpgoprofile(pgoprofile)
+	}
+
 	ssaDump = os.Getenv("GOSSAFUNC")
 	if ssaDump != "" {
 		if strings.HasSuffix(ssaDump, "+") {
@@ -599,13 +617,18 @@ func Main(archInit func(*Arch)) {
 			// Now that we've checked whether n terminates,
 			// we can eliminate some obviously dead code.
 			deadcode(Curfn)
+			if nerrors == 0 {
+				rewriteRangeFuncs(Curfn)
+			}
 			fcount++
 		}
 	}
+	instantiationsClosed = true
 	// With all types checked, it's now safe to verify map keys. One single
 	// check past phase 9 isn't sufficient, as we may exit with other errors
 	// before then, thus skipping map key errors.
 	checkMapKeys()
+	checkConstraintUses()
 	timings.AddEvent(fcount, "funcs")
 
 	if nsavederrors+nerrors != 0 {
@@ -647,6 +670,16 @@ func Main(archInit func(*Arch)) {
 		}
 	}
 
+	if pgoHotEdges != nil && Debug_pgodevirt != 0 {
+		// Devirtualize hot interface calls before inlining,
+		// so that the concrete calls can be inlined.
+		for _, n := range xtop {
+			if n.Op == ODCLFUNC {
+				pgodevirtualize(n)
+			}
+		}
+	}
+
 	if Debug['l'] != 0 {
 		// Find functions that can be inlined and clone them before walk expands them.
 		visitBottomUp(xtop, func(list []*Node, recursive bool) {
@@ -746,9 +779,10 @@ func Main(archInit func(*Arch)) {
 			externdcl[i] = typecheck(externdcl[i], ctxExpr)
 		}
 	}
-	// Check the map keys again, since we typechecked the external
-	// declarations.
+	// Check the map keys and constraint uses again, since we
+	// typechecked the external declarations.
 	checkMapKeys()
+	checkConstraintUses()
 
 	if nerrors+nsavederrors != 0 {
 		errorexit()
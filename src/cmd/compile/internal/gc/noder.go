@@ -5,8 +5,9 @@
 package gc
 
 import (
+	"bytes"
 	"fmt"
-	"os"
+	"io/ioutil"
 	"path/filepath"
 	"runtime"
 	"strconv"
@@ -33,6 +34,7 @@ func parseFiles(filenames []string) uint {
 		p := &noder{
 			basemap: make(map[*syntax.PosBase]*src.PosBase),
 			err:     make(chan syntax.Error),
+			pkg:     localpkg,
 		}
 		noders = append(noders, p)
 
@@ -42,14 +44,16 @@ func parseFiles(filenames []string) uint {
 			defer close(p.err)
 			base := syntax.NewFileBase(filename)
 
-			f, err := os.Open(filename)
+			// Read the whole file: the source text of generic
+			// declarations is recorded for export.
+			text, err := ioutil.ReadFile(filename)
 			if err != nil {
 				p.error(syntax.Error{Pos: syntax.MakePos(base, 0, 0), Msg: err.Error()})
 				return
 			}
-			defer f.Close()
+			p.src = text
 
-			p.file, _ = syntax.Parse(base, f, p.error, p.pragma, syntax.CheckBranches) // errors are tracked via p.error
+			p.file, _ = syntax.Parse(base, bytes.NewReader(text), p.error, p.pragma, syntax.CheckBranches) // errors are tracked via p.error
 		}(filename)
 	}
 
@@ -62,6 +66,7 @@ func parseFiles(filenames []string) uint {
 		p.node()
 		lines += p.file.Lines
 		p.file = nil // release memory
+		p.src = nil
 
 		if nsyntaxerrors != 0 {
 			errorexit()
@@ -71,6 +76,7 @@ func parseFiles(filenames []string) uint {
 	}
 
 	localpkg.Height = myheight
+	attachGenericMethods()
 
 	return lines
 }
@@ -91,7 +97,11 @@ func (p *noder) makeSrcPosBase(b0 *syntax.PosBase) *src.PosBase {
 			// line directive base
 			p0 := b0.Pos()
 			p1 := src.MakePos(p.makeSrcPosBase(p0.Base()), p0.Line(), p0.Col())
-			b1 = src.NewLinePragmaBase(p1, fn, fileh(fn), b0.Line(), b0.Col())
+			absfn := fn
+			if !p.imported {
+				absfn = fileh(fn)
+			}
+			b1 = src.NewLinePragmaBase(p1, fn, absfn, b0.Line(), b0.Col())
 		}
 		p.basemap[b0] = b1
 	}
@@ -136,16 +146,44 @@ type noder struct {
 	err        chan syntax.Error
 	scope      ScopeID
 
+	// embeds records the //go:embed directives in the file,
+	// and embedVars maps each package-level var declaration
+	// to the patterns of the directives preceding it.
+	embeds        []embedPragma
+	embedVars     map[*syntax.VarDecl][]string
+	importedEmbed bool
+
 	// scopeVars is a stack tracking the number of variables declared in the
 	// current function at the moment each open scope was opened.
 	scopeVars []int
 
 	lastCloseScopePos syntax.Pos
+
+	// pkg is the package whose scope names are looked up in.
+	// It is localpkg, except when instantiating a generic
+	// declaration imported from another package.
+	pkg *types.Pkg
+
+	// imported reports whether the syntax was read from export data,
+	// where line directives already name absolute file names.
+	imported bool
+
+	// src is the text of file, and lines holds the offsets
+	// at which its lines start (computed lazily).
+	src   []byte
+	lines []int
+
+	// imports records the file's imports, which generic
+	// declarations need to be instantiated.
+	imports []templateImport
 }
 
 func (p *noder) funcBody(fn *Node, block *syntax.BlockStmt) {
 	oldScope := p.scope
 	p.scope = 0
+	if p.pkg != localpkg {
+		templatePkgs[fn] = p.pkg
+	}
 	funchdr(fn)
 
 	if block != nil {
@@ -241,6 +279,7 @@ func (p *noder) node() {
 	p.setlineno(p.file.PkgName)
 	mkpackage(p.file.PkgName.Value)
 
+	p.embedVars = p.assignEmbeds()
 	xtop = append(xtop, p.decls(p.file.DeclList)...)
 
 	for _, n := range p.linknames {
@@ -295,9 +334,23 @@ func (p *noder) decls(decls []syntax.Decl) (l []*Node) {
 			l = append(l, p.constDecl(decl, &cs)...)
 
 		case *syntax.TypeDecl:
+			if decl.TParamList != nil {
+				p.genericDecl(decl, decl.Name)
+				break
+			}
 			l = append(l, p.typeDecl(decl))
 
 		case *syntax.FuncDecl:
+			if decl.TParamList != nil {
+				p.genericDecl(decl, decl.Name)
+				break
+			}
+			if decl.Recv != nil {
+				if base, _ := recvTypeParams(decl.Recv); base != nil {
+					p.genericMethod(decl, base)
+					break
+				}
+			}
 			l = append(l, p.funcDecl(decl))
 
 		default:
@@ -324,6 +377,9 @@ func (p *noder) importDecl(imp *syntax.ImportDecl) {
 	}
 
 	ipkg.Direct = true
+	if ipkg.Path == "embed" {
+		p.importedEmbed = true
+	}
 
 	var my *types.Sym
 	if imp.LocalPkgName != nil {
@@ -336,6 +392,10 @@ func (p *noder) importDecl(imp *syntax.ImportDecl) {
 	pack.Sym = my
 	pack.Name.Pkg = ipkg
 
+	if my.Name != "_" && my.Name != "init" {
+		p.imports = append(p.imports, templateImport{my.Name, ipkg})
+	}
+
 	switch my.Name {
 	case ".":
 		importdot(ipkg, pack)
@@ -363,6 +423,10 @@ func (p *noder) varDecl(decl *syntax.VarDecl) []*Node {
 		exprs = p.exprList(decl.Values)
 	}
 
+	if patterns, ok := p.embedVars[decl]; ok {
+		p.embedVarDecl(decl, names, typ, exprs, patterns)
+	}
+
 	p.setlineno(decl)
 	return variter(names, typ, exprs)
 }
@@ -485,7 +549,7 @@ func (p *noder) funcDecl(fun *syntax.FuncDecl) *Node {
 			}
 		}
 	} else {
-		f.Func.Shortname = name
+		f.Func.Shortname = p.fieldSym(fun.Name.Value)
 		name = nblank.Sym // filled in by typecheckfunc
 	}
 
@@ -530,7 +594,7 @@ func (p *noder) funcDecl(fun *syntax.FuncDecl) *Node {
 }
 
 func (p *noder) signature(recv *syntax.Field, typ *syntax.FuncType) *Node {
-	n := p.nod(typ, OTFUNC, nil, nil)
+	n := p.typeLit(p.nod(typ, OTFUNC, nil, nil))
 	if recv != nil {
 		n.Left = p.param(recv, false, false)
 	}
@@ -634,10 +698,16 @@ func (p *noder) expr(expr syntax.Expr) *Node {
 			obj.Name.SetUsed(true)
 			return oldname(restrictlookup(expr.Sel.Value, obj.Name.Pkg))
 		}
-		n := nodSym(OXDOT, obj, p.name(expr.Sel))
+		n := nodSym(OXDOT, obj, p.fieldSym(expr.Sel.Value))
 		n.Pos = p.pos(expr) // lineno may have been changed by p.expr(expr.X)
 		return n
 	case *syntax.IndexExpr:
+		if list, ok := expr.Index.(*syntax.ListExpr); ok {
+			// instantiation with several type arguments
+			n := p.nod(expr, OINDEX, p.expr(expr.X), nil)
+			n.List.Set(p.exprs(list.ElemList))
+			return n
+		}
 		return p.nod(expr, OINDEX, p.expr(expr.X), p.expr(expr.Index))
 	case *syntax.SliceExpr:
 		op := OSLICE
@@ -659,6 +729,10 @@ func (p *noder) expr(expr syntax.Expr) *Node {
 		if expr.Op == syntax.Add && expr.Y != nil {
 			return p.sum(expr)
 		}
+		if expr.Op == syntax.Tilde {
+			p.yyerrorpos(expr.Pos(), "cannot use ~ outside of interface or type constraint")
+			return p.expr(expr.X)
+		}
 		x := p.expr(expr.X)
 		if expr.Y == nil {
 			return p.nod(expr, p.unOp(expr.Op), x, nil)
@@ -816,7 +890,7 @@ func (p *noder) structType(expr *syntax.StructType) *Node {
 		if field.Name == nil {
 			n = p.embedded(field.Type)
 		} else {
-			n = p.nodSym(field, ODCLFIELD, p.typeExpr(field.Type), p.name(field.Name))
+			n = p.nodSym(field, ODCLFIELD, p.typeExpr(field.Type), p.fieldSym(field.Name.Value))
 		}
 		if i < len(expr.TagList) && expr.TagList[i] != nil {
 			n.SetVal(p.basicLit(expr.TagList[i]))
@@ -825,7 +899,7 @@ func (p *noder) structType(expr *syntax.StructType) *Node {
 	}
 
 	p.setlineno(expr)
-	n := p.nod(expr, OTSTRUCT, nil, nil)
+	n := p.typeLit(p.nod(expr, OTSTRUCT, nil, nil))
 	n.List.Set(l)
 	return n
 }
@@ -836,9 +910,14 @@ func (p *noder) interfaceType(expr *syntax.InterfaceType) *Node {
 		p.setlineno(method)
 		var n *Node
 		if method.Name == nil {
-			n = p.nodSym(method, ODCLFIELD, oldname(p.packname(method.Type)), nil)
+			switch method.Type.(type) {
+			case *syntax.Name, *syntax.SelectorExpr:
+				n = p.nodSym(method, ODCLFIELD, oldname(p.packname(method.Type)), nil)
+			default:
+				n = p.nodSym(method, ODCLFIELD, p.typeElem(method.Type), nil)
+			}
 		} else {
-			mname := p.name(method.Name)
+			mname := p.fieldSym(method.Name.Value)
 			sig := p.typeExpr(method.Type)
 			sig.Left = fakeRecv()
 			n = p.nodSym(method, ODCLFIELD, sig, mname)
@@ -847,11 +926,57 @@ func (p *noder) interfaceType(expr *syntax.InterfaceType) *Node {
 		l = append(l, n)
 	}
 
-	n := p.nod(expr, OTINTER, nil, nil)
+	n := p.typeLit(p.nod(expr, OTINTER, nil, nil))
 	n.List.Set(l)
 	return n
 }
 
+// typeElem returns the Node for an element embedded in an interface,
+// which is a type, a ~T term, or a union of such terms.
+func (p *noder) typeElem(expr syntax.Expr) *Node {
+	if op, ok := expr.(*syntax.Operation); ok && (op.Op == syntax.Tilde || op.Op == syntax.Or && op.Y != nil) {
+		n := p.nod(op, OTUNION, nil, nil)
+		n.List.Set(p.unionTerms(op, nil))
+		return n
+	}
+	return p.typeExpr(expr)
+}
+
+// unionTerms appends the terms of union expr to terms.
+func (p *noder) unionTerms(expr syntax.Expr, terms []*Node) []*Node {
+	if op, ok := expr.(*syntax.Operation); ok {
+		switch {
+		case op.Op == syntax.Or && op.Y != nil:
+			terms = p.unionTerms(op.X, terms)
+			return p.unionTerms(op.Y, terms)
+		case op.Op == syntax.Tilde:
+			return append(terms, p.nod(op, OTILDE, p.typeExpr(op.X), nil))
+		}
+	}
+	return append(terms, p.typeExpr(expr))
+}
+
+// constraint returns the Node for the constraint of a type parameter.
+// A constraint that is a union or a ~T term stands for the interface
+// embedding it.
+func (p *noder) constraint(expr syntax.Expr) *Node {
+	if op, ok := expr.(*syntax.Operation); ok && (op.Op == syntax.Tilde || op.Op == syntax.Or && op.Y != nil) {
+		n := p.typeLit(p.nod(expr, OTINTER, nil, nil))
+		n.List.Set1(p.nodSym(expr, ODCLFIELD, p.typeElem(expr), nil))
+		return n
+	}
+	return p.typeExpr(expr)
+}
+
+// typeLit records the package of the struct, interface or function
+// type literal n; see templatePkgs.
+func (p *noder) typeLit(n *Node) *Node {
+	if p.pkg != localpkg {
+		templatePkgs[n] = p.pkg
+	}
+	return n
+}
+
 func (p *noder) packname(expr syntax.Expr) *types.Sym {
 	switch expr := expr.(type) {
 	case *syntax.Name:
@@ -890,7 +1015,7 @@ func (p *noder) embedded(typ syntax.Expr) *Node {
 	}
 
 	sym := p.packname(typ)
-	n := p.nodSym(typ, ODCLFIELD, oldname(sym), lookup(sym.Name))
+	n := p.nodSym(typ, ODCLFIELD, oldname(sym), p.fieldSym(sym.Name))
 	n.SetEmbedded(true)
 
 	if isStar {
@@ -1408,7 +1533,17 @@ func (p *noder) basicLit(lit *syntax.BasicLit) Val {
 }
 
 func (p *noder) name(name *syntax.Name) *types.Sym {
-	return lookup(name.Value)
+	return p.pkg.Lookup(name.Value)
+}
+
+// fieldSym returns the symbol for the field, method, or selector name
+// name. Exported names belong to localpkg, whichever package declares
+// them; see also importReader.ident.
+func (p *noder) fieldSym(name string) *types.Sym {
+	if types.IsExported(name) {
+		return lookup(name)
+	}
+	return p.pkg.Lookup(name)
 }
 
 func (p *noder) mkname(name *syntax.Name) *Node {
@@ -1503,6 +1638,9 @@ func (p *noder) pragma(pos syntax.Pos, text string) syntax.Pragma {
 		}
 		p.linknames = append(p.linknames, linkname{pos, f[1], target})
 
+	case text == "go:embed", strings.HasPrefix(text, "go:embed "), strings.HasPrefix(text, "go:embed\t"):
+		p.parseEmbedPragma(pos, text)
+
 	case strings.HasPrefix(text, "go:cgo_import_dynamic "):
 		// This is permitted for general use because Solaris
 		// code relies on it in golang.org/x/sys/unix and others.
@@ -114,6 +114,7 @@ func dumpCompilerObj(bout *bio.Writer) {
 func dumpdata() {
 	externs := len(externdcl)
 
+	initEmbeds()
 	dumpglobls()
 	addptabs()
 	addsignats(externdcl)
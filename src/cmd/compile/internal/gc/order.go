@@ -324,9 +324,10 @@ func (o *Order) stmtList(l Nodes) {
 	}
 }
 
-// edge inserts coverage instrumentation for libfuzzer.
+// edge inserts coverage instrumentation for libfuzzer
+// and for the fuzzing engine of go test.
 func (o *Order) edge() {
-	if Debug_libfuzzer == 0 {
+	if Debug_libfuzzer == 0 && Debug_fuzzcounters == 0 {
 		return
 	}
 
@@ -937,7 +938,7 @@ func (o *Order) stmt(n *Node) {
 	// For now just clean all the temporaries at the end.
 	// In practice that's fine.
 	case OSWITCH:
-		if Debug_libfuzzer != 0 && !hasDefaultCase(n) {
+		if (Debug_libfuzzer != 0 || Debug_fuzzcounters != 0) && !hasDefaultCase(n) {
 			// Add empty "default:" case for instrumentation.
 			n.List.Append(nod(OCASE, nil, nil))
 		}
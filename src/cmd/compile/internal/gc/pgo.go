@@ -0,0 +1,333 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Profile-guided optimization.
+//
+// When compiling with -pgoprofile, the compiler reads a CPU profile of
+// the program and uses the weights of its call edges to
+//
+//	- inline hot calls to functions that are too big for the
+//	  default inlining budget (see inl.go), and
+//	- devirtualize hot interface calls whose callee is usually
+//	  a method of the same concrete type.
+//
+// Call sites are matched with the profile by the linker name of the
+// calling function and the line of the call.
+
+package gc
+
+import (
+	"cmd/compile/internal/pgo"
+	"cmd/compile/internal/types"
+	"cmd/internal/objabi"
+	"fmt"
+	"log"
+	"strings"
+)
+
+var (
+	pgoprofile string // -pgoprofile flag
+
+	// pgoProfile is the call graph read from the -pgoprofile file.
+	pgoProfile *pgo.Profile
+
+	// pgoHotEdges holds the hot call edges of pgoProfile.
+	pgoHotEdges map[pgo.CallEdge]bool
+
+	// pgoHotCallees holds the linker names of the functions
+	// called by hot call edges.
+	pgoHotCallees map[string]bool
+)
+
+// readpgoprofile reads the profile in file and computes its hot edges.
+func readpgoprofile(file string) {
+	p, err := pgo.New(file)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	pgoProfile = p
+	pgoHotEdges = p.HotEdges(inlineCDFHotCallSiteThresholdPercent)
+	pgoHotCallees = make(map[string]bool)
+	for e := range pgoHotEdges {
+		pgoHotCallees[e.Callee] = true
+	}
+	if Debug['m'] > 1 {
+		fmt.Printf("pgo: %d hot call edges out of %d\n", len(pgoHotEdges), len(p.Weights))
+	}
+}
+
+// pgolinkname returns the linker symbol name that name, the name of
+// a symbol of the compiled package or another one, has in the profile.
+func pgolinkname(name string) string {
+	if strings.HasPrefix(name, `"".`) {
+		return objabi.PathToPrefix(myimportpath) + name[2:]
+	}
+	return name
+}
+
+// pgosymname returns the name of the function s in the profile.
+func pgosymname(s *types.Sym) string {
+	return pgolinkname(s.LinksymName())
+}
+
+// pgocallsite returns the profile call site of the call n in Curfn.
+// If n is part of an inlined body, the call site is in the innermost
+// inlined function, as it is in the profile.
+func pgocallsite(n *Node) pgo.CallSite {
+	pos := Ctxt.InnermostPos(n.Pos)
+	caller := pgosymname(Curfn.Func.Nname.Sym)
+	if inl := pos.Base().InliningIndex(); inl >= 0 {
+		caller = pgolinkname(Ctxt.InlTree.InlinedFunction(inl).Name)
+	}
+	return pgo.CallSite{Caller: caller, Line: int(pos.RelLine())}
+}
+
+// pgohotfunc reports whether fn is called by a hot call edge.
+func pgohotfunc(fn *Node) bool {
+	if pgoHotCallees == nil || Debug_pgoinline == 0 {
+		return false
+	}
+	return pgoHotCallees[pgosymname(fn.Func.Nname.Sym)]
+}
+
+// pgohotcall reports whether the call n to fn is a hot call edge.
+func pgohotcall(n, fn *Node) bool {
+	if pgoHotEdges == nil || Debug_pgoinline == 0 {
+		return false
+	}
+	return pgoHotEdges[pgo.CallEdge{CallSite: pgocallsite(n), Callee: pgosymname(fn.Sym)}]
+}
+
+// pgodevirtualize rewrites the interface method calls in fn whose
+// hottest callee in the profile is the method of a concrete type to
+// call that method directly when the receiver has that type, so that
+// the direct call can be inlined. That is,
+//
+//	x.M(args)
+//
+// becomes
+//
+//	tmp, atmp := x, args
+//	if c, ok := tmp.(T); ok {
+//		c.M(atmp)
+//	} else {
+//		tmp.M(atmp)
+//	}
+//
+// Only calls that are statements, the right-hand side of an assignment
+// or the value of a return statement are rewritten. In an assignment,
+// the new statements are attached to the result, as for an inlined
+// call, so that they run after the operands of the left-hand side
+// are evaluated.
+func pgodevirtualize(fn *Node) {
+	savefn := Curfn
+	Curfn = fn
+	lno := lineno
+	pgodevirtlist(fn.Nbody)
+	lineno = lno
+	Curfn = savefn
+}
+
+func pgodevirtlist(l Nodes) {
+	s := l.Slice()
+	for i, n := range s {
+		s[i] = pgodevirtstmt(n)
+	}
+}
+
+func pgodevirtstmt(n *Node) *Node {
+	if n == nil {
+		return nil
+	}
+
+	pgodevirtlist(n.Ninit)
+	pgodevirtlist(n.Nbody)
+	switch n.Op {
+	case OIF:
+		pgodevirtlist(n.Rlist)
+	case OBLOCK, OSWITCH, OSELECT:
+		pgodevirtlist(n.List)
+	}
+
+	var call *Node
+	switch n.Op {
+	case OCALLINTER:
+		call = n
+	case OAS, OASOP, OAS2FUNC:
+		call = n.Right
+	case ORETURN:
+		if n.List.Len() == 1 {
+			call = n.List.First()
+		}
+	}
+	if call == nil || call.Op != OCALLINTER {
+		return n
+	}
+	t := pgodevirtcallee(call)
+	if t == nil {
+		return n
+	}
+	if Debug['m'] != 0 {
+		fmt.Printf("%v: PGO devirtualizing %v to %v\n", call.Line(), call.Left, t)
+	}
+	return pgodevirtcall(n, call, t)
+}
+
+// pgodevirtcallee returns the concrete type whose method is the hottest
+// callee of the interface method call, or nil if there is none.
+func pgodevirtcallee(call *Node) *types.Type {
+	if call.IsDDD() || call.Ninit.Len() != 0 {
+		return nil
+	}
+	for _, a := range call.List.Slice() {
+		if a.Type == nil || a.Type.IsFuncArgStruct() {
+			return nil
+		}
+	}
+
+	sel := call.Left
+	for _, e := range pgoProfile.Calls(pgocallsite(call)) {
+		if !pgoHotEdges[e] {
+			continue
+		}
+		t := pgomethodtype(e.Callee, sel.Sym.Name)
+		if t == nil {
+			continue
+		}
+		var missing, have *types.Field
+		var ptr int
+		if !implements(t, sel.Left.Type, &missing, &have, &ptr) {
+			continue
+		}
+		return t
+	}
+	return nil
+}
+
+// pgomethodtype returns the receiver type of the method name,
+// a profile name of the form pkg.T.method or pkg.(*T).method,
+// or nil if it is not a method of a type the compiler knows.
+// The type must be declared in the compiled package or a package
+// it imports directly.
+func pgomethodtype(name, method string) *types.Type {
+	if !strings.HasSuffix(name, "."+method) {
+		return nil
+	}
+	name = name[:len(name)-len(method)-1]
+
+	// The package path is escaped, so the first dot
+	// after the last slash ends it.
+	slash := strings.LastIndex(name, "/")
+	dot := strings.Index(name[slash+1:], ".")
+	if dot < 0 {
+		return nil
+	}
+	dot += slash + 1
+	prefix, typ := name[:dot], name[dot+1:]
+	ptr := strings.HasPrefix(typ, "(*") && strings.HasSuffix(typ, ")")
+	if ptr {
+		typ = typ[2 : len(typ)-1]
+	}
+
+	var pkg *types.Pkg
+	if prefix == objabi.PathToPrefix(myimportpath) {
+		pkg = localpkg
+	} else {
+		for _, p := range types.ImportedPkgList() {
+			if p.Prefix == prefix {
+				pkg = p
+				break
+			}
+		}
+	}
+	if pkg == nil {
+		return nil
+	}
+	s := pkg.Syms[typ]
+	if s == nil {
+		return nil
+	}
+	d := resolve(asNode(s.Def))
+	if d == nil || d.Op != OTYPE || d.Type == nil || d.Type.IsInterface() {
+		return nil
+	}
+	if ptr {
+		return types.NewPtr(d.Type)
+	}
+	return d.Type
+}
+
+// pgodevirtcall rewrites the statement n containing the interface
+// method call to devirtualize the call for the concrete type t.
+func pgodevirtcall(n, call *Node, t *types.Type) *Node {
+	lineno = call.Pos
+	sel := call.Left
+
+	var init []*Node
+	recv := temp(sel.Left.Type)
+	init = append(init, typecheck(nod(OAS, recv, sel.Left), ctxStmt))
+	var args []*Node
+	for _, a := range call.List.Slice() {
+		tmp := temp(a.Type)
+		init = append(init, typecheck(nod(OAS, tmp, a), ctxStmt))
+		args = append(args, tmp)
+	}
+
+	var results []*Node
+	if n != call && call.Type != nil {
+		if call.Type.IsFuncArgStruct() {
+			for _, f := range call.Type.FieldSlice() {
+				results = append(results, temp(f.Type))
+			}
+		} else {
+			results = append(results, temp(call.Type))
+		}
+	}
+
+	c := temp(t)
+	ok := temp(types.Types[TBOOL])
+	as := nod(OAS2, nil, nil)
+	as.List.Set2(c, ok)
+	as.Rlist.Set1(nod(ODOTTYPE, recv, typenod(t)))
+	nif := nod(OIF, ok, nil)
+	nif.Ninit.Set1(as)
+	nif.Nbody.Set1(pgodevirtbranch(nodSym(OXDOT, c, sel.Sym), args, results))
+	nif.Rlist.Set1(pgodevirtbranch(nodSym(OXDOT, recv, sel.Sym), args, results))
+	init = append(init, typecheck(nif, ctxStmt))
+
+	switch n.Op {
+	case OCALLINTER:
+		b := nod(OBLOCK, nil, nil)
+		b.List.Set(init)
+		return typecheck(b, ctxStmt)
+	case OAS, OASOP:
+		n.Right = addinit(results[0], init)
+		return n
+	case OAS2FUNC:
+		results[0] = addinit(results[0], init)
+		n.Rlist.Set(results)
+		n.Right = nil
+		n.Op = OAS2
+		n.SetTypecheck(0)
+		return typecheck(n, ctxStmt)
+	case ORETURN:
+		n.List.Set(results)
+	}
+	n.Ninit.Append(init...)
+	return n
+}
+
+// pgodevirtbranch returns a call of fn with args, assigning the
+// results, if any, to results.
+func pgodevirtbranch(fn *Node, args, results []*Node) *Node {
+	call := nod(OCALL, fn, nil)
+	call.List.Set(append([]*Node(nil), args...))
+	if len(results) == 0 {
+		return call
+	}
+	as := nod(OAS2, nil, nil)
+	as.List.Set(append([]*Node(nil), results...))
+	as.Rlist.Set1(call)
+	return as
+}
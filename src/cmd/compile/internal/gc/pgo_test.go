@@ -0,0 +1,269 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gc
+
+import (
+	"bytes"
+	"fmt"
+	"internal/profile"
+	"internal/testenv"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const pgoSrc = `
+package main
+
+type Adder interface {
+	Add(a, b int) int
+}
+
+type Plus struct{}
+
+func (*Plus) Add(a, b int) int { return a + b }
+
+type Minus struct{}
+
+func (Minus) Add(a, b int) int { return a - b }
+
+func big(x int) int {
+	%s
+	return x
+}
+
+func run(a Adder, n int) int {
+	s := 0
+	for i := 0; i < n; i++ {
+		s = a.Add(s, big(i)) // hot
+	}
+	return s + big(n) // cold
+}
+
+func main() {
+	println(run(&Plus{}, 100))
+}
+`
+
+// pgoLine returns the line of the first line of src containing s.
+func pgoLine(t *testing.T, src, s string) int64 {
+	for i, line := range strings.Split(src, "\n") {
+		if strings.Contains(line, s) {
+			return int64(i + 1)
+		}
+	}
+	t.Fatalf("no line containing %q", s)
+	return 0
+}
+
+// pgoSample is a profile sample of weight w in which caller calls
+// callee on the given line.
+type pgoSample struct {
+	callee, caller string
+	line           int64
+	w              int64
+}
+
+// pgoWriteProfile returns a CPU profile holding samples.
+func pgoWriteProfile(t *testing.T, samples []pgoSample) []byte {
+	p := &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "samples", Unit: "count"}},
+		PeriodType: &profile.ValueType{Type: "cpu", Unit: "nanoseconds"},
+		Period:     1,
+	}
+	loc := func(name string, line int64) *profile.Location {
+		f := &profile.Function{ID: uint64(len(p.Function) + 1), Name: name, SystemName: name, Filename: "x.go"}
+		p.Function = append(p.Function, f)
+		l := &profile.Location{ID: uint64(len(p.Location) + 1), Line: []profile.Line{{Function: f, Line: line}}}
+		p.Location = append(p.Location, l)
+		return l
+	}
+	for _, s := range samples {
+		locs := []*profile.Location{loc(s.callee, 1), loc(s.caller, s.line)}
+		p.Sample = append(p.Sample, &profile.Sample{Location: locs, Value: []int64{s.w}})
+	}
+
+	var buf bytes.Buffer
+	if err := p.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// pgoProfileFor returns a CPU profile in which run spends its time
+// calling big and (*Plus).Add on the line marked hot.
+func pgoProfileFor(t *testing.T, src string) []byte {
+	hot := pgoLine(t, src, "// hot")
+	cold := pgoLine(t, src, "// cold")
+	return pgoWriteProfile(t, []pgoSample{
+		{"main.big", "main.run", hot, 600},
+		{"main.(*Plus).Add", "main.run", hot, 400},
+		{"main.big", "main.run", cold, 1},
+	})
+}
+
+func TestPGO(t *testing.T) {
+	testenv.MustHaveGoBuild(t)
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "TestPGO")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Make big too expensive to inline without a profile.
+	src := fmt.Sprintf(pgoSrc, strings.Repeat("x = x*3 + x>>2\n\t", 30))
+	srcfile := filepath.Join(dir, "x.go")
+	if err := ioutil.WriteFile(srcfile, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	proffile := filepath.Join(dir, "x.pprof")
+	if err := ioutil.WriteFile(proffile, pgoProfileFor(t, src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	compile := func(args ...string) string {
+		args = append([]string{"tool", "compile", "-p", "main", "-m", "-o", filepath.Join(dir, "x.o")}, args...)
+		cmd := exec.Command(testenv.GoToolPath(t), append(args, srcfile)...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("%v: %v\n%s", cmd.Args, err, out)
+		}
+		return string(out)
+	}
+
+	hot, cold := pgoLine(t, src, "// hot"), pgoLine(t, src, "// cold")
+	inlineHot := fmt.Sprintf("x.go:%d:19: inlining call to big", hot)
+	inlineCold := fmt.Sprintf("x.go:%d:16: inlining call to big", cold)
+	devirt := fmt.Sprintf("x.go:%d:12: PGO devirtualizing a.Add to *Plus", hot)
+
+	out := compile()
+	for _, s := range []string{inlineHot, inlineCold, devirt} {
+		if strings.Contains(out, s) {
+			t.Errorf("without profile: output contains %q:\n%s", s, out)
+		}
+	}
+
+	out = compile("-pgoprofile", proffile)
+	for _, s := range []string{inlineHot, devirt} {
+		if !strings.Contains(out, s) {
+			t.Errorf("with profile: output does not contain %q:\n%s", s, out)
+		}
+	}
+	if strings.Contains(out, inlineCold) {
+		t.Errorf("with profile: output contains %q:\n%s", inlineCold, out)
+	}
+
+	out = compile("-pgoprofile", proffile, "-d", "pgoinline=0,pgodevirtualize=0")
+	for _, s := range []string{inlineHot, inlineCold, devirt} {
+		if strings.Contains(out, s) {
+			t.Errorf("with profile and PGO disabled: output contains %q:\n%s", s, out)
+		}
+	}
+}
+
+const pgoAssignSrc = `
+package main
+
+import "fmt"
+
+type Adder interface {
+	Add(a, b int) int
+	AddCarry(a, b uint) (uint, bool)
+}
+
+type Plus struct{}
+
+func (*Plus) Add(a, b int) int {
+	trace("Add")
+	return a + b
+}
+
+func (*Plus) AddCarry(a, b uint) (uint, bool) {
+	trace("AddCarry")
+	return a + b, a+b < a
+}
+
+var calls []string
+
+func trace(s string) { calls = append(calls, s) }
+
+func key(k int) int {
+	trace("key")
+	return k
+}
+
+func run(a Adder) {
+	m := make(map[int]int)
+	m[key(1)] = a.Add(1, 2) // hot Add
+	m[key(2)] += a.Add(3, 4) // hot Add
+	s := make([]uint, 1)
+	var c bool
+	s[key(0)], c = a.AddCarry(^uint(0), 2) // hot AddCarry
+	fmt.Println(calls, m, s, c)
+}
+
+func main() {
+	run(&Plus{})
+}
+`
+
+// TestPGODevirtualizeAssign checks that devirtualized calls on the
+// right-hand side of assignments run after the operands of the
+// left-hand side, as they do without a profile.
+func TestPGODevirtualizeAssign(t *testing.T) {
+	testenv.MustHaveGoRun(t)
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "TestPGODevirtualizeAssign")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := pgoAssignSrc
+	srcfile := filepath.Join(dir, "x.go")
+	if err := ioutil.WriteFile(srcfile, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var samples []pgoSample
+	for i, line := range strings.Split(src, "\n") {
+		if j := strings.Index(line, "// hot "); j >= 0 {
+			samples = append(samples, pgoSample{"main.(*Plus)." + line[j+len("// hot "):], "main.run", int64(i + 1), 100})
+		}
+	}
+	proffile := filepath.Join(dir, "x.pprof")
+	if err := ioutil.WriteFile(proffile, pgoWriteProfile(t, samples), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(gcflags string) string {
+		cmd := exec.Command(testenv.GoToolPath(t), "run", "-gcflags="+gcflags, srcfile)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("%v: %v\n%s", cmd.Args, err, out)
+		}
+		return string(out)
+	}
+
+	want := run("")
+	out := run("-m -pgoprofile=" + proffile)
+	for _, s := range []string{
+		fmt.Sprintf("x.go:%d:19: PGO devirtualizing a.Add to *Plus", pgoLine(t, src, "m[key(1)]")),
+		fmt.Sprintf("x.go:%d:20: PGO devirtualizing a.Add to *Plus", pgoLine(t, src, "m[key(2)]")),
+		fmt.Sprintf("x.go:%d:27: PGO devirtualizing a.AddCarry to *Plus", pgoLine(t, src, "s[key(0)]")),
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("with profile: output does not contain %q:\n%s", s, out)
+		}
+	}
+	if !strings.HasSuffix(out, want) {
+		t.Errorf("with profile: program printed\n%s\nwant\n%s", out, want)
+	}
+}
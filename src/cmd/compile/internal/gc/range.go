@@ -87,6 +87,35 @@ func typecheckrangeExpr(n *Node) {
 	case TSTRING:
 		t1 = types.Types[TINT]
 		t2 = types.Runetype
+
+	case TFUNC:
+		if !langSupported(1, 14, curpkg()) {
+			yyerrorv("go1.14", "cannot range over %L", n.Right)
+			return
+		}
+		yield, why := rangeFuncYield(t)
+		if yield == nil {
+			yyerrorl(n.Pos, "cannot range over %L: func must be func(yield func(...) bool): %s", n.Right, why)
+			return
+		}
+		params := yield.Params().FieldSlice()
+		if len(params) > 0 {
+			t1 = params[0].Type
+		}
+		if len(params) > 1 {
+			t2 = params[1].Type
+		}
+		if n.List.Len() > len(params) {
+			switch len(params) {
+			case 0:
+				yyerrorl(n.Pos, "range over %L permits no iteration variables", n.Right)
+			case 1:
+				yyerrorl(n.Pos, "range over %L permits only one iteration variable", n.Right)
+			default:
+				yyerrorl(n.Pos, "too many variables in range")
+			}
+			return
+		}
 	}
 
 	if n.List.Len() > 2 || toomany {
@@ -0,0 +1,671 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gc
+
+import (
+	"cmd/compile/internal/types"
+	"cmd/internal/src"
+	"fmt"
+	"strings"
+)
+
+// Range-over-func loops.
+//
+// A loop ranging over an iterator function
+//
+//	L: for k, v := range f {
+//		body
+//	}
+//
+// is rewritten, once the enclosing function has been type checked, into a
+// call of f whose yield function is a closure holding the loop body:
+//
+//	{
+//		var #next int
+//		f(func(#p0 K, #p1 V) bool {
+//			if #next != 0 {
+//				runtime.panicrangeexit()
+//			}
+//			k, v := #p0, #p1
+//			body'
+//			return true
+//		})
+//		if #next == 1 {
+//			return
+//		}
+//		if #next == 2 {
+//			break L2
+//		}
+//		...
+//		#next = -1
+//	}
+//
+// In body', a continue statement for the loop becomes "return true" and a
+// break statement becomes "#next = -1; return false". A return statement
+// assigns the results of the enclosing function and then sets #next to 1
+// before returning false. Branches to labels outside the loop likewise set
+// #next to a code of their own, and the branch itself is made after f
+// returns. Since #next is non-zero once the loop body has asked to stop,
+// an iterator that keeps calling yield after that panics.
+//
+// A defer statement in the body must defer its call to the end of the
+// function containing the loop, not of the yield function. Such loops are
+// preceded by
+//
+//	var #defers unsafe.Pointer
+//	runtime.deferrangefunc(&#defers)
+//
+// which adds a defer record to the frame of the function, and the defer
+// statement "defer g(x)" becomes
+//
+//	#d0, #d1 := g, x
+//	runtime.deferprocat(func() { #d0(#d1) }, #defers)
+//
+// which adds the call to that record. The runtime runs the calls added to
+// the record when it would have run the record itself. A loop nested in
+// the body of another one shares the record of the outer loop.
+//
+// Loops are rewritten innermost first, so that the statements generated
+// after an inner loop are in turn rewritten as part of the body of the loop
+// enclosing it. Variables of the enclosing function used by the body are
+// captured by the closure exactly as if it had been written in the source.
+
+// rangeFuncYield returns the type of the yield function of t, the type of
+// a range-over-func iterator. If t is not a valid iterator type,
+// rangeFuncYield returns nil and the reason why.
+func rangeFuncYield(t *types.Type) (*types.Type, string) {
+	switch {
+	case t.NumParams() != 1:
+		return nil, "wrong argument count"
+	case t.NumResults() != 0:
+		return nil, "wrong result count"
+	}
+	yield := t.Params().Field(0).Type
+	switch {
+	case yield.Etype != TFUNC:
+		return nil, "argument is not func"
+	case yield.NumParams() > 2:
+		return nil, "yield func has too many parameters"
+	case yield.NumResults() != 1 || !types.Identical(yield.Results().Field(0).Type, types.Types[TBOOL]):
+		return nil, "yield func does not return bool"
+	}
+	return yield, ""
+}
+
+// isRangeFunc reports whether n is a range-over-func loop.
+func isRangeFunc(n *Node) bool {
+	return n.Op == ORANGE && n.Type != nil && n.Type.Etype == TFUNC
+}
+
+// rewriteRangeFuncs rewrites the range-over-func loops in the body of fn,
+// which must have been type checked without errors.
+func rewriteRangeFuncs(fn *Node) {
+	labels := make(map[*Node]*types.Sym)
+	found := false
+	inspectList(fn.Nbody, func(n *Node) bool {
+		switch {
+		case n.Op == OLABEL && n.Name.Defn != nil:
+			labels[n.Name.Defn] = n.Sym
+		case isRangeFunc(n):
+			found = true
+		}
+		return true
+	})
+	if !found {
+		return
+	}
+
+	// Typechecking the closures below must not reset the Assigned flag
+	// of the variables they capture, as typecheckclosure does for
+	// assignments in straight-line code preceding a closure: the whole
+	// function has already been type checked.
+	olddd := decldepth
+	decldepth = 0
+	oldfn := Curfn
+	Curfn = fn
+	oldctxt := dclcontext
+
+	var edit func(n *Node) *Node
+	edit = func(n *Node) *Node {
+		if n == nil {
+			return nil
+		}
+		switch n.Op {
+		case ONAME, OLITERAL, OTYPE, ONONAME, OPACK, OCLOSURE:
+			return n
+		}
+		editList(n.Ninit, edit)
+		n.Left = edit(n.Left)
+		n.Right = edit(n.Right)
+		editList(n.List, edit)
+		editList(n.Nbody, edit)
+		editList(n.Rlist, edit)
+		if isRangeFunc(n) {
+			n = rewriteRangeFunc(fn, n, labels[n])
+		}
+		return n
+	}
+	editList(fn.Nbody, edit)
+
+	dclcontext = oldctxt
+	Curfn = oldfn
+	decldepth = olddd
+}
+
+// editList replaces each node n in l by edit(n).
+func editList(l Nodes, edit func(*Node) *Node) {
+	s := l.Slice()
+	for i, n := range s {
+		s[i] = edit(n)
+	}
+}
+
+// A rangeFuncLoop holds the state for rewriting a single range-over-func
+// loop.
+type rangeFuncLoop struct {
+	fn    *Node               // function containing the loop
+	pos   src.XPos            // position of the loop
+	label *types.Sym          // label of the loop, or nil
+	inner map[*types.Sym]bool // labels defined in the loop body
+	next  *Node               // #next variable
+
+	defers    *Node // #defers variable, if the body executes defer statements
+	ndefers   int   // number of temporaries for deferred calls
+	hasReturn bool
+	results   []*Node         // variables holding the results of fn
+	exits     []rangeFuncExit // branches to statements outside the loop
+	blocks    []*Node         // statements replacing branches in the body
+}
+
+// A rangeFuncExit is a break, continue or goto statement in a loop body
+// that leaves the loop for a statement enclosing it.
+type rangeFuncExit struct {
+	op    Op
+	label *types.Sym
+}
+
+// #next codes other than those of the loop exits.
+const (
+	rangeFuncDone   = -1 // the loop is over
+	rangeFuncReturn = 1  // the loop body executed a return statement
+	rangeFuncExits  = 2  // code of the first entry of rangeFuncLoop.exits
+)
+
+// rewriteRangeFunc rewrites the range-over-func loop n in fn, whose label
+// is label, and returns the statement replacing it.
+func rewriteRangeFunc(fn, n *Node, label *types.Sym) *Node {
+	lno := setlineno(n)
+
+	r := &rangeFuncLoop{
+		fn:    fn,
+		pos:   n.Pos,
+		label: label,
+		inner: make(map[*types.Sym]bool),
+		next:  rangeFuncVar(fn, n.Pos, "#next", types.Types[TINT]),
+	}
+	inspectList(n.Nbody, func(n *Node) bool {
+		if n.Op == OLABEL {
+			r.inner[n.Sym] = true
+		}
+		return true
+	})
+	editList(n.Nbody, func(n *Node) *Node {
+		return r.branches(n, false, false)
+	})
+
+	yield, _ := rangeFuncYield(n.Type)
+	var ptypes []*types.Type
+	for _, f := range yield.Params().FieldSlice() {
+		ptypes = append(ptypes, f.Type)
+	}
+	clo, params := rangeFuncClosure(n.Pos, ptypes, types.Types[TBOOL])
+	xfunc := clo.Func.Closure
+
+	guard := nod(OIF, nod(ONE, r.next, nodintconst(0)), nil)
+	guard.Nbody.Set1(nod(OCALL, syslook("panicrangeexit"), nil))
+	body := []*Node{guard}
+
+	var init []*Node
+	for _, s := range n.Ninit.Slice() {
+		if s.Op == ODCL && s.Left.Name.Defn == n {
+			// Iteration variables declared by the loop are
+			// declared anew on each call of the yield function.
+			body = append(body, s)
+		} else {
+			init = append(init, s)
+		}
+	}
+	for i, v := range n.List.Slice() {
+		if !v.isBlank() {
+			body = append(body, nod(OAS, v, params[i]))
+		}
+	}
+	body = append(body, n.Nbody.Slice()...)
+	body = append(body, yieldReturn(true))
+	xfunc.Nbody.Set(body)
+	xfunc.Func.Endlineno = n.Pos
+	if n.Nbody.Len() > 0 {
+		xfunc.Func.Endlineno = n.Nbody.Slice()[n.Nbody.Len()-1].Pos
+	}
+
+	r.capture(xfunc)
+
+	call := nod(OCALL, n.Right, nil)
+	call.List.Set1(clo)
+
+	stmts := append(init, nod(ODCL, r.next, nil), nod(OAS, r.next, nil))
+	if r.defers != nil {
+		anchor := nod(OCALL, syslook("deferrangefunc"), nil)
+		anchor.List.Set1(nod(OADDR, r.defers, nil))
+		stmts = append(stmts, nod(ODCL, r.defers, nil), anchor)
+		fn.Func.SetHasDefer(true)
+		fn.Func.SetOpenCodedDeferDisallowed(true)
+	}
+	if !r.namedResults() {
+		for _, v := range r.results {
+			stmts = append(stmts, nod(ODCL, v, nil))
+		}
+	}
+	stmts = append(stmts, call)
+	if r.hasReturn {
+		// Named results have already been assigned.
+		ret := nod(ORETURN, nil, nil)
+		if r.results != nil && !r.namedResults() {
+			ret.List.Set(append([]*Node(nil), r.results...))
+		} else {
+			ret.SetTypecheck(1)
+		}
+		stmts = append(stmts, r.ifNext(rangeFuncReturn, ret))
+	}
+	for i, e := range r.exits {
+		stmts = append(stmts, r.ifNext(rangeFuncExits+i, nodSym(e.op, nil, e.label)))
+	}
+	stmts = append(stmts, r.setNext(rangeFuncDone))
+
+	block := nod(OBLOCK, nil, nil)
+	block.List.Set(stmts)
+	block = typecheck(block, ctxStmt)
+
+	// The blocks replacing branch statements may be nested in statements
+	// of the body that had already been type checked, so check them now.
+	Curfn = xfunc
+	for _, b := range r.blocks {
+		typecheck(b, ctxStmt)
+	}
+	Curfn = fn
+
+	lineno = lno
+	return block
+}
+
+// rangeFuncVar declares a new variable of type t in fn, for use by the
+// rewrite of a loop at pos.
+func rangeFuncVar(fn *Node, pos src.XPos, name string, t *types.Type) *Node {
+	v := newnamel(pos, lookup(name))
+	v.Type = t
+	v.SetClass(PAUTO)
+	v.Name.Curfn = fn
+	v.Name.Decldepth = 1
+	v.Name.SetUsed(true)
+	v.SetTypecheck(1)
+	fn.Func.Dcl = append(fn.Func.Dcl, v)
+	return v
+}
+
+// rangeFuncClosure returns a new closure at pos with parameters of types
+// ptypes and a result of type result, if not nil, and the closure
+// parameters. The closure has no body yet.
+func rangeFuncClosure(pos src.XPos, ptypes []*types.Type, result *types.Type) (clo *Node, params []*Node) {
+	ftype := func() *Node {
+		t := nod(OTFUNC, nil, nil)
+		for i, pt := range ptypes {
+			t.List.Append(symfield(lookupN("#p", i), pt))
+		}
+		if result != nil {
+			t.Rlist.Append(anonfield(result))
+		}
+		return t
+	}
+
+	xtype := ftype()
+	xfunc := nodl(pos, ODCLFUNC, nil, nil)
+	xfunc.Func.SetIsHiddenClosure(true)
+	xfunc.Func.Nname = newfuncnamel(pos, nblank.Sym) // filled in by typecheckclosure
+	xfunc.Func.Nname.Name.Param.Ntype = xtype
+	xfunc.Func.Nname.Name.Defn = xfunc
+
+	clo = nodl(pos, OCLOSURE, nil, nil)
+	clo.Func.Ntype = ftype()
+
+	xfunc.Func.Closure = clo
+	clo.Func.Closure = xfunc
+
+	funchdr(xfunc)
+	funcbody()
+
+	for _, f := range xtype.List.Slice() {
+		params = append(params, f.Right)
+	}
+	return clo, params
+}
+
+// branches rewrites the statements in n that leave the loop body.
+// inLoop and inSwitch report whether n is within a loop, or a switch or
+// select statement, nested in the body.
+func (r *rangeFuncLoop) branches(n *Node, inLoop, inSwitch bool) *Node {
+	if n == nil {
+		return nil
+	}
+	switch n.Op {
+	case ONAME, OLITERAL, OTYPE, ONONAME, OPACK, OCLOSURE:
+		return n
+
+	case OBREAK, OCONTINUE:
+		switch {
+		case n.Sym == nil:
+			if inLoop || n.Op == OBREAK && inSwitch {
+				return n
+			}
+			return r.exit(n, nil)
+		case n.Sym == r.label:
+			return r.exit(n, nil)
+		case r.inner[n.Sym]:
+			return n
+		}
+		return r.exit(n, n.Sym)
+
+	case OGOTO:
+		if r.inner[n.Sym] {
+			return n
+		}
+		return r.exit(n, n.Sym)
+
+	case ORETURN:
+		r.hasReturn = true
+		var stmts []*Node
+		if n.List.Len() > 0 {
+			results := r.resultVars()
+			if len(results) == 1 {
+				stmts = append(stmts, nod(OAS, results[0], n.List.First()))
+			} else {
+				as := nod(OAS2, nil, nil)
+				as.List.Set(append([]*Node(nil), results...))
+				as.Rlist.Set(n.List.Slice())
+				stmts = append(stmts, as)
+			}
+		}
+		stmts = append(stmts, r.setNext(rangeFuncReturn), yieldReturn(false))
+		return r.block(n, stmts)
+
+	case ODEFER:
+		return r.deferCall(n)
+
+	case OCALLFUNC:
+		if n.Left.Op == ONAME && n.Left.Sym == Runtimepkg.Lookup("deferrangefunc") {
+			// The deferred calls of a loop nested in the
+			// body are added to the defer record of r.
+			tok := n.List.First().Left
+			return r.block(n, []*Node{nod(OAS, tok, r.deferToken())})
+		}
+
+	case OFOR, OFORUNTIL, ORANGE:
+		inLoop = true
+
+	case OSWITCH, OSELECT:
+		inSwitch = true
+	}
+
+	edit := func(n *Node) *Node {
+		return r.branches(n, inLoop, inSwitch)
+	}
+	editList(n.Ninit, edit)
+	n.Left = edit(n.Left)
+	n.Right = edit(n.Right)
+	editList(n.List, edit)
+	editList(n.Nbody, edit)
+	editList(n.Rlist, edit)
+	return n
+}
+
+// deferCall rewrites the defer statement n in the loop body into a call of
+// runtime.deferprocat. The function value and arguments of the deferred
+// call are evaluated right away, as for any defer statement, and the
+// function deferred is a closure making the call with them.
+func (r *rangeFuncLoop) deferCall(n *Node) *Node {
+	call := n.Left
+	clo, _ := rangeFuncClosure(n.Pos, nil, nil)
+	xfunc := clo.Func.Closure
+
+	// The initialization statements of the call, such as those
+	// assigning the results of g in f(g()), are part of the evaluation
+	// of the arguments.
+	stmts := append(n.Ninit.Slice(), call.Ninit.Slice()...)
+	call.Ninit.Set(nil)
+	temp := func(x *Node) *Node {
+		if x == nil || x.Op == OLITERAL || x.Op == OTYPE || x.Op == ONAME && x.Class() == PFUNC {
+			return x
+		}
+		if x.Op == OCLOSURE {
+			// The closure is no longer called directly.
+			x.Func.Top &^= ctxCallee
+		}
+		v := rangeFuncVar(r.fn, x.Pos, fmt.Sprintf("#d%d", r.ndefers), x.Type)
+		r.ndefers++
+		stmts = append(stmts, nod(ODCL, v, nil), nod(OAS, v, x))
+		return closureVar(xfunc, v)
+	}
+
+	switch call.Op {
+	case OCALLMETH, OCALLINTER:
+		call.Left.Left = temp(call.Left.Left)
+	default:
+		call.Left = temp(call.Left)
+		call.Right = temp(call.Right)
+	}
+	editList(call.List, temp)
+	xfunc.Nbody.Set1(call)
+	xfunc.Func.Endlineno = n.Pos
+	// Calls of recover in the deferred function must see the
+	// closure as the deferred function.
+	xfunc.Func.SetWrapper(true)
+
+	d := nod(OCALL, syslook("deferprocat"), nil)
+	d.List.Set2(clo, r.deferToken())
+	stmts = append(stmts, d)
+	return r.block(n, stmts)
+}
+
+// deferToken returns the #defers variable of r.
+func (r *rangeFuncLoop) deferToken() *Node {
+	if r.defers == nil {
+		r.defers = rangeFuncVar(r.fn, r.pos, "#defers", types.Types[TUNSAFEPTR])
+	}
+	return r.defers
+}
+
+// exit rewrites the branch statement n, which leaves the loop body.
+// label is the label of the statement outside the loop that n branches to,
+// or nil if n breaks or continues the loop itself.
+func (r *rangeFuncLoop) exit(n *Node, label *types.Sym) *Node {
+	if label == nil {
+		if n.Op == OCONTINUE {
+			return r.block(n, []*Node{yieldReturn(true)})
+		}
+		return r.block(n, []*Node{r.setNext(rangeFuncDone), yieldReturn(false)})
+	}
+
+	code := -1
+	for i, e := range r.exits {
+		if e.op == n.Op && e.label == label {
+			code = rangeFuncExits + i
+			break
+		}
+	}
+	if code < 0 {
+		code = rangeFuncExits + len(r.exits)
+		r.exits = append(r.exits, rangeFuncExit{op: n.Op, label: label})
+	}
+	return r.block(n, []*Node{r.setNext(code), yieldReturn(false)})
+}
+
+// block returns a block holding stmts, in place of statement n.
+func (r *rangeFuncLoop) block(n *Node, stmts []*Node) *Node {
+	for _, s := range stmts {
+		s.Pos = n.Pos
+	}
+	b := nodl(n.Pos, OBLOCK, nil, nil)
+	b.List.Set(stmts)
+	r.blocks = append(r.blocks, b)
+	return b
+}
+
+// namedResults reports whether the results of r.fn are named.
+func (r *rangeFuncLoop) namedResults() bool {
+	results := r.fn.Type.Results()
+	if results.NumFields() == 0 {
+		return true
+	}
+	v := asNode(results.Field(0).Nname)
+	return v != nil && !strings.HasPrefix(v.Sym.Name, "~r")
+}
+
+// resultVars returns the variables to which a return statement in the loop
+// body assigns its results. These are the named results of r.fn, or else
+// temporaries returned once the iterator function has returned, as unnamed
+// results cannot be captured by the closure.
+func (r *rangeFuncLoop) resultVars() []*Node {
+	if r.results != nil {
+		return r.results
+	}
+	for i, f := range r.fn.Type.Results().FieldSlice() {
+		if r.namedResults() {
+			r.results = append(r.results, asNode(f.Nname))
+		} else {
+			r.results = append(r.results, rangeFuncVar(r.fn, r.pos, fmt.Sprintf("#r%d", i), f.Type))
+		}
+	}
+	return r.results
+}
+
+func (r *rangeFuncLoop) setNext(code int) *Node {
+	return nod(OAS, r.next, nodintconst(int64(code)))
+}
+
+func (r *rangeFuncLoop) ifNext(code int, stmt *Node) *Node {
+	n := nod(OIF, nod(OEQ, r.next, nodintconst(int64(code))), nil)
+	n.Nbody.Set1(stmt)
+	return n
+}
+
+// yieldReturn returns a statement returning b from the yield function.
+func yieldReturn(b bool) *Node {
+	n := nod(ORETURN, nil, nil)
+	n.List.Set1(nodbool(b))
+	return n
+}
+
+// capture turns the variables of r.fn used in the body of xfunc into
+// closure variables, and moves those declared in the body to xfunc.
+func (r *rangeFuncLoop) capture(xfunc *Node) {
+	fn := r.fn
+
+	inside := make(map[*Node]bool)
+	declared := make(map[*Node]bool)
+	inspectList(xfunc.Nbody, func(n *Node) bool {
+		inside[n] = true
+		if n.Op == ODCL {
+			declared[n.Left] = true
+		}
+		return true
+	})
+	isLocal := func(v *Node) bool {
+		return declared[v] || v.Name.Defn != nil && inside[v.Name.Defn]
+	}
+
+	cvars := make(map[*Node]*Node)
+	capture := func(v *Node) *Node {
+		if v == nil || v.Op != ONAME || v.Name == nil || v.Name.Curfn != fn || isLocal(v) {
+			return v
+		}
+		switch v.Class() {
+		case PAUTO, PAUTOHEAP, PPARAM, PPARAMOUT:
+		default:
+			return v
+		}
+		c := cvars[v]
+		if c == nil {
+			c = closureVar(xfunc, v)
+			cvars[v] = c
+		}
+		return c
+	}
+
+	var edit func(n *Node) *Node
+	edit = func(n *Node) *Node {
+		if n == nil {
+			return nil
+		}
+		switch n.Op {
+		case ONAME:
+			return capture(n)
+		case OLITERAL, OTYPE, ONONAME, OPACK:
+			return n
+		case OCLOSURE:
+			// Closures in the body now capture the variables
+			// of the enclosing function through xfunc.
+			for _, v := range n.Func.Closure.Func.Cvars.Slice() {
+				v.Name.Param.Outer = capture(v.Name.Param.Outer)
+			}
+			return n
+		}
+		editList(n.Ninit, edit)
+		n.Left = edit(n.Left)
+		n.Right = edit(n.Right)
+		editList(n.List, edit)
+		editList(n.Nbody, edit)
+		editList(n.Rlist, edit)
+		return n
+	}
+	editList(xfunc.Nbody, edit)
+
+	dcl := fn.Func.Dcl[:0]
+	for _, v := range fn.Func.Dcl {
+		if v.Op == ONAME && v.Class() == PAUTO && v.Name.Curfn == fn && isLocal(v) {
+			v.Name.Curfn = xfunc
+			xfunc.Func.Dcl = append(xfunc.Func.Dcl, v)
+			continue
+		}
+		dcl = append(dcl, v)
+	}
+	fn.Func.Dcl = dcl
+}
+
+// closureVar returns a new closure variable of xfunc for v, a variable of
+// the function enclosing xfunc.
+func closureVar(xfunc, v *Node) *Node {
+	if v.Type == nil {
+		// v is a closure variable only used by closures nested
+		// in the loop body, which now capture it through xfunc.
+		// Type check it here, as capturevars drops untyped ones.
+		v = typecheck(v, ctxExpr)
+	}
+
+	// See oldname.
+	c := newnamel(v.Pos, v.Sym)
+	c.Name.Curfn = xfunc
+	c.SetClass(PAUTOHEAP)
+	c.Name.SetIsClosureVar(true)
+	c.SetIsDDD(v.IsDDD())
+	c.Name.Defn = v
+	if v.Name.IsClosureVar() {
+		c.Name.Defn = v.Name.Defn
+	}
+	c.Name.Param.Outer = v
+	c.Type = v.Type
+	c.Name.SetUsed(true)
+	c.SetTypecheck(1)
+	xfunc.Func.Cvars.Append(c)
+	return c
+}
@@ -318,6 +318,7 @@ func deferstruct(stksize int64) *types.Type {
 		makefield("started", types.Types[TBOOL]),
 		makefield("heap", types.Types[TBOOL]),
 		makefield("openDefer", types.Types[TBOOL]),
+		makefield("rangefunc", types.Types[TBOOL]),
 		makefield("sp", types.Types[TUINTPTR]),
 		makefield("pc", types.Types[TUINTPTR]),
 		// Note: the types here don't really matter. Defer structures
@@ -329,6 +330,7 @@ func deferstruct(stksize int64) *types.Type {
 		makefield("framepc", types.Types[TUINTPTR]),
 		makefield("varp", types.Types[TUINTPTR]),
 		makefield("fd", types.Types[TUINTPTR]),
+		makefield("head", types.Types[TUINTPTR]),
 		makefield("args", argtype),
 	}
 
@@ -1161,13 +1163,14 @@ func dtypesym(t *types.Type) *obj.LSym {
 		tbase = t.Elem()
 	}
 	dupok := 0
-	if tbase.Sym == nil {
+	if tbase.Sym == nil || isInstance(tbase) {
 		dupok = obj.DUPOK
 	}
 
 	if myimportpath != "runtime" || (tbase != types.Types[tbase.Etype] && tbase != types.Bytetype && tbase != types.Runetype && tbase != types.Errortype) { // int, float, etc
-		// named types from other files are defined only by those files
-		if tbase.Sym != nil && tbase.Sym.Pkg != localpkg {
+		// named types from other files are defined only by those files,
+		// except for instances of generic types
+		if tbase.Sym != nil && tbase.Sym.Pkg != localpkg && !isInstance(tbase) {
 			return lsym
 		}
 		// TODO(mdempsky): Investigate whether this can happen.
@@ -4387,21 +4387,23 @@ func (s *state) call(n *Node, k callKind) *ssa.Value {
 		// 1: started, set in deferprocStack
 		// 2: heap, set in deferprocStack
 		// 3: openDefer
-		// 4: sp, set in deferprocStack
-		// 5: pc, set in deferprocStack
-		// 6: fn
+		// 4: rangefunc, set in deferprocStack
+		// 5: sp, set in deferprocStack
+		// 6: pc, set in deferprocStack
+		// 7: fn
 		s.store(closure.Type,
-			s.newValue1I(ssa.OpOffPtr, closure.Type.PtrTo(), t.FieldOff(6), addr),
+			s.newValue1I(ssa.OpOffPtr, closure.Type.PtrTo(), t.FieldOff(7), addr),
 			closure)
-		// 7: panic, set in deferprocStack
-		// 8: link, set in deferprocStack
-		// 9: framepc
-		// 10: varp
-		// 11: fd
+		// 8: panic, set in deferprocStack
+		// 9: link, set in deferprocStack
+		// 10: framepc
+		// 11: varp
+		// 12: fd
+		// 13: head, set in deferprocStack
 
 		// Then, store all the arguments of the defer call.
 		ft := fn.Type
-		off := t.FieldOff(12)
+		off := t.FieldOff(14)
 		args := n.Rlist.Slice()
 
 		// Set receiver (for interface calls). Always a pointer.
@@ -4491,8 +4493,9 @@ func (s *state) call(n *Node, k callKind) *ssa.Value {
 	}
 	s.vars[&memVar] = call
 
-	// Finish block for defers
-	if k == callDefer || k == callDeferStack {
+	// Finish block for defers. Like deferproc, runtime.deferrangefunc
+	// returns 1 if one of the deferred calls it is used for stops a panic.
+	if k == callDefer || k == callDeferStack || sym != nil && sym.Pkg == Runtimepkg && sym.Name == "deferrangefunc" {
 		b := s.endBlock()
 		b.Kind = ssa.BlockDefer
 		b.SetControl(call)
@@ -207,7 +207,7 @@ func Fatalf(fmt_ string, args ...interface{}) {
 // their usage position.
 func hasUniquePos(n *Node) bool {
 	switch n.Op {
-	case ONAME, OPACK:
+	case ONAME, OPACK, OGENERIC:
 		return false
 	case OLITERAL, OTYPE:
 		if n.Sym != nil {
@@ -1495,15 +1495,16 @@ func genwrapper(rcvr *types.Type, method *types.Field, newnam *types.Sym) {
 		fmt.Printf("genwrapper rcvrtype=%v method=%v newnam=%v\n", rcvr, method, newnam)
 	}
 
-	// Only generate (*T).M wrappers for T.M in T's own package.
+	// Only generate (*T).M wrappers for T.M in T's own package,
+	// or in every package using T if T is an instance of a generic type.
 	if rcvr.IsPtr() && rcvr.Elem() == method.Type.Recv().Type &&
-		rcvr.Elem().Sym != nil && rcvr.Elem().Sym.Pkg != localpkg {
+		rcvr.Elem().Sym != nil && rcvr.Elem().Sym.Pkg != localpkg && !isInstance(rcvr.Elem()) {
 		return
 	}
 
 	// Only generate I.M wrappers for I in I's own package
 	// but keep doing it for error.Error (was issue #29304).
-	if rcvr.IsInterface() && rcvr.Sym != nil && rcvr.Sym.Pkg != localpkg && rcvr != types.Errortype {
+	if rcvr.IsInterface() && rcvr.Sym != nil && rcvr.Sym.Pkg != localpkg && rcvr != types.Errortype && !isInstance(rcvr) {
 		return
 	}
 
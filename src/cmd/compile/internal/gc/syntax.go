@@ -570,6 +570,7 @@ const (
 	funcExportInline             // include inline body in export data
 	funcInstrumentBody           // add race/msan instrumentation during SSA construction
 	funcOpenCodedDeferDisallowed // can't do open-coded defers
+	funcInstance                 // is an instance of a generic function or method
 )
 
 func (f *Func) Dupok() bool                    { return f.flags&funcDupok != 0 }
@@ -583,6 +584,7 @@ func (f *Func) InlinabilityChecked() bool      { return f.flags&funcInlinability
 func (f *Func) ExportInline() bool             { return f.flags&funcExportInline != 0 }
 func (f *Func) InstrumentBody() bool           { return f.flags&funcInstrumentBody != 0 }
 func (f *Func) OpenCodedDeferDisallowed() bool { return f.flags&funcOpenCodedDeferDisallowed != 0 }
+func (f *Func) Instance() bool                 { return f.flags&funcInstance != 0 }
 
 func (f *Func) SetDupok(b bool)                    { f.flags.set(funcDupok, b) }
 func (f *Func) SetWrapper(b bool)                  { f.flags.set(funcWrapper, b) }
@@ -595,6 +597,7 @@ func (f *Func) SetInlinabilityChecked(b bool)      { f.flags.set(funcInlinabilit
 func (f *Func) SetExportInline(b bool)             { f.flags.set(funcExportInline, b) }
 func (f *Func) SetInstrumentBody(b bool)           { f.flags.set(funcInstrumentBody, b) }
 func (f *Func) SetOpenCodedDeferDisallowed(b bool) { f.flags.set(funcOpenCodedDeferDisallowed, b) }
+func (f *Func) SetInstance(b bool)                 { f.flags.set(funcInstance, b) }
 
 func (f *Func) setWBPos(pos src.XPos) {
 	if Debug_wb != 0 {
@@ -774,6 +777,8 @@ const (
 	OTINTER  // interface{}
 	OTFUNC   // func()
 	OTARRAY  // []int, [8]int, [N]int or [...]int
+	OTUNION  // ~int | string (List is the list of terms)
+	OTILDE   // ~Left (term of an OTUNION)
 
 	// misc
 	ODDD        // func f(args ...int) or f(l...) or var a = [...]int{0, 1, 2}.
@@ -791,6 +796,7 @@ const (
 	OVARLIVE    // variable is alive
 	ORESULT     // result of a function call; Xoffset is stack offset
 	OINLMARK    // start of an inlined body, with file/line of caller. Xoffset is an index into the inline tree.
+	OGENERIC    // generic function or type Sym, usable only once instantiated
 
 	// arch-specific opcodes
 	ORETJMP // return to other function
@@ -7,6 +7,7 @@ package gc
 import (
 	"cmd/compile/internal/types"
 	"cmd/internal/objabi"
+	"cmd/internal/src"
 	"fmt"
 	"strings"
 )
@@ -61,12 +62,13 @@ func tracePrint(title string, n *Node) func(np **Node) {
 }
 
 const (
-	ctxStmt    = 1 << iota // evaluated at statement level
-	ctxExpr                // evaluated in value context
-	ctxType                // evaluated in type context
-	ctxCallee              // call-only expressions are ok
-	ctxMultiOK             // multivalue function returns are ok
-	ctxAssign              // assigning to expression
+	ctxStmt       = 1 << iota // evaluated at statement level
+	ctxExpr                   // evaluated in value context
+	ctxType                   // evaluated in type context
+	ctxCallee                 // call-only expressions are ok
+	ctxMultiOK                // multivalue function returns are ok
+	ctxAssign                 // assigning to expression
+	ctxConstraint             // type constraints are ok
 )
 
 // type checks the whole tree of an expression.
@@ -226,10 +228,10 @@ func typecheck(n *Node, top int) (res *Node) {
 	n = resolve(n)
 
 	// Skip typecheck if already done.
-	// But re-typecheck ONAME/OTYPE/OLITERAL/OPACK node in case context has changed.
+	// But re-typecheck ONAME/OTYPE/OLITERAL/OPACK/OGENERIC node in case context has changed.
 	if n.Typecheck() == 1 {
 		switch n.Op {
-		case ONAME, OTYPE, OLITERAL, OPACK:
+		case ONAME, OTYPE, OLITERAL, OPACK, OGENERIC:
 			break
 
 		default:
@@ -501,15 +503,65 @@ func typecheck1(n *Node, top int) (res *Node) {
 	case OTSTRUCT:
 		ok |= ctxType
 		setTypeNode(n, tostruct(n.List.Slice()))
+		setTypeLitPkg(n)
 		n.List.Set(nil)
 
 	case OTINTER:
 		ok |= ctxType
 		setTypeNode(n, tointerface(n.List.Slice()))
+		setTypeLitPkg(n)
+
+	case OTUNION:
+		ok |= ctxType
+		if top&ctxConstraint == 0 {
+			yyerror("cannot use type union outside of interface or type constraint")
+			n.Type = nil
+			return n
+		}
+		var terms []*types.Term
+		for _, x := range n.List.Slice() {
+			term := &types.Term{}
+			if x.Op == OTILDE {
+				term.Tilde = true
+				x = x.Left
+			}
+			x = typecheck(x, ctxType|ctxConstraint)
+			t := x.Type
+			if t == nil {
+				n.Type = nil
+				return n
+			}
+			if term.Tilde {
+				if t.IsInterface() {
+					yyerrorl(x.Pos, "invalid use of ~ (%v is an interface)", t)
+					n.Type = nil
+					return n
+				}
+				if t.Sym != nil && t.Orig != t {
+					yyerrorl(x.Pos, "invalid use of ~ (underlying type of %v is %v)", t, t.Orig)
+					n.Type = nil
+					return n
+				}
+			}
+			term.Type = t
+			terms = append(terms, term)
+		}
+		setTypeNode(n, types.NewUnion(terms))
+		n.List.Set(nil)
+
+	case OGENERIC:
+		if g := generics[n.Sym]; g != nil && g.isType() {
+			yyerror("cannot use generic type %v without instantiation", n.Sym)
+		} else {
+			yyerror("cannot use generic function %v without instantiation", n.Sym)
+		}
+		n.Type = nil
+		return n
 
 	case OTFUNC:
 		ok |= ctxType
 		setTypeNode(n, functype(n.Left, n.List.Slice(), n.Rlist.Slice()))
+		setTypeLitPkg(n)
 		n.Left = nil
 		n.List.Set(nil)
 		n.Rlist.Set(nil)
@@ -994,6 +1046,19 @@ func typecheck1(n *Node, top int) (res *Node) {
 		}
 
 	case OINDEX:
+		if g := genericOperand(n.Left); g != nil {
+			n = instantiateIndex(n, g)
+			if n.Type == nil {
+				return n
+			}
+			if n.Op == OTYPE {
+				ok |= ctxType
+			} else {
+				ok |= ctxExpr
+			}
+			break
+		}
+
 		ok |= ctxExpr
 		n.Left = typecheck(n.Left, ctxExpr)
 		n.Left = defaultlit(n.Left, nil)
@@ -1226,6 +1291,10 @@ func typecheck1(n *Node, top int) (res *Node) {
 	// call and call like
 	case OCALL:
 		typecheckslice(n.Ninit.Slice(), ctxStmt) // imported rewritten f(g()) calls (#30907)
+		if !instantiateCall(n) {
+			n.Type = nil
+			return n
+		}
 		n.Left = typecheck(n.Left, ctxExpr|ctxType|ctxCallee)
 		if n.Left.Diag() {
 			n.SetDiag(true)
@@ -1918,6 +1987,11 @@ func typecheck1(n *Node, top int) (res *Node) {
 		OVARLIVE:
 		ok |= ctxStmt
 
+	case OBLOCK:
+		// Only created by the compiler, see rangefunc.go.
+		ok |= ctxStmt
+		typecheckslice(n.List.Slice(), ctxStmt)
+
 	case OLABEL:
 		ok |= ctxStmt
 		decldepth++
@@ -2023,7 +2097,7 @@ func typecheck1(n *Node, top int) (res *Node) {
 
 	case ODCLTYPE:
 		ok |= ctxStmt
-		n.Left = typecheck(n.Left, ctxType)
+		n.Left = typecheck(n.Left, ctxType|ctxConstraint)
 		checkwidth(n.Left.Type)
 		if n.Left.Type != nil && n.Left.Type.NotInHeap() && n.Left.Name.Param.Pragma&NotInHeap == 0 {
 			// The type contains go:notinheap types, so it
@@ -2060,6 +2134,17 @@ func typecheck1(n *Node, top int) (res *Node) {
 		return n
 	}
 
+	if top&(ctxType|ctxConstraint) == ctxType && n.Op == OTYPE && n.Type != nil && n.Type.IsInterface() {
+		if !n.Type.WidthCalculated() {
+			// check when all types are settled
+			constraintqueue = append(constraintqueue, constraintUse{lineno, n.Type})
+		} else if n.Type.IsConstraint() {
+			// n is the shared declaration of the interface;
+			// leave its type intact.
+			yyerror("interface contains type constraints")
+		}
+	}
+
 	// TODO(rsc): simplify
 	if (top&(ctxCallee|ctxExpr|ctxType) != 0) && top&ctxStmt == 0 && ok&(ctxExpr|ctxType|ctxCallee) == 0 {
 		yyerror("%v used as value", n)
@@ -2860,7 +2945,7 @@ func typecheckcomplit(n *Node) (res *Node) {
 
 				f := t.Field(i)
 				s := f.Sym
-				if s != nil && !types.IsExported(s.Name) && s.Pkg != localpkg {
+				if s != nil && !types.IsExported(s.Name) && s.Pkg != localpkg && s.Pkg != curpkg() {
 					yyerror("implicit assignment of unexported field '%s' in %v literal", s.Name, t)
 				}
 				// No pushtype allowed here. Must name fields for that.
@@ -2898,12 +2983,13 @@ func typecheckcomplit(n *Node) (res *Node) {
 					}
 
 					// Sym might have resolved to name in other top-level
-					// package, because of import dot. Redirect to correct sym
-					// before we do the lookup.
+					// package, because of import dot or because the literal
+					// is in an instance of an imported generic declaration.
+					// Redirect to correct sym before we do the lookup.
 					s := key.Sym
 					if s.Pkg != localpkg && types.IsExported(s.Name) {
 						s1 := lookup(s.Name)
-						if s1.Origpkg == s.Pkg {
+						if s1.Origpkg == s.Pkg || s.Pkg == curpkg() {
 							s = s1
 						}
 					}
@@ -3409,6 +3495,24 @@ func checkMapKeys() {
 	mapqueue = nil
 }
 
+// A constraintUse records the use of interface type t at pos in a
+// context where constraint interfaces are not permitted.
+type constraintUse struct {
+	pos src.XPos
+	t   *types.Type
+}
+
+var constraintqueue []constraintUse
+
+func checkConstraintUses() {
+	for _, u := range constraintqueue {
+		if !u.t.Broke() && u.t.IsConstraint() {
+			yyerrorl(u.pos, "interface contains type constraints")
+		}
+	}
+	constraintqueue = nil
+}
+
 func setUnderlying(t, underlying *types.Type) {
 	if underlying.Etype == TFORW {
 		// This type isn't computed yet; when it is, update n.
@@ -3464,7 +3568,7 @@ func typecheckdeftype(n *Node) {
 	}
 
 	n.SetTypecheck(1)
-	n.Name.Param.Ntype = typecheck(n.Name.Param.Ntype, ctxType)
+	n.Name.Param.Ntype = typecheck(n.Name.Param.Ntype, ctxType|ctxConstraint)
 	t := n.Name.Param.Ntype.Type
 	if t == nil {
 		n.SetDiag(true)
@@ -3959,6 +4063,11 @@ func curpkg() *types.Pkg {
 		// Initialization expressions for package-scope variables.
 		return localpkg
 	}
+	if pkg := templatePkgs[fn]; pkg != nil {
+		// Functions and closures of instances of imported
+		// generic declarations.
+		return pkg
+	}
 
 	// TODO(mdempsky): Standardize on either ODCLFUNC or ONAME for
 	// Curfn, rather than mixing them.
@@ -45,6 +45,10 @@ const (
 	TSTRING    = types.TSTRING
 	TUNSAFEPTR = types.TUNSAFEPTR
 
+	// pseudo-types for type parameters and their constraints
+	TTYPEPARAM = types.TTYPEPARAM
+	TUNION     = types.TUNION
+
 	// pseudo-types for literals
 	TIDEAL = types.TIDEAL
 	TNIL   = types.TNIL
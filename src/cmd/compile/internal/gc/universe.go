@@ -377,6 +377,19 @@ func lexinit1() {
 	s.Def = asTypesNode(typenod(types.Errortype))
 	dowidth(types.Errortype)
 
+	// any is an alias for the empty interface.
+	s = builtinpkg.Lookup("any")
+	s.Def = asTypesNode(typenod(types.Types[TINTER]))
+
+	// comparable is the interface implemented by all comparable
+	// types. It may only be used as a type constraint.
+	s = builtinpkg.Lookup("comparable")
+	types.Comparabletype = types.New(TINTER)
+	types.Comparabletype.Sym = s
+	types.Comparabletype.SetComparableConstraint(true)
+	s.Def = asTypesNode(typenod(types.Comparabletype))
+	dowidth(types.Comparabletype)
+
 	// We create separate byte and rune types for better error messages
 	// rather than just creating type alias *types.Sym's for the uint8 and
 	// int32 types. Hence, (bytetype|runtype).Sym.isAlias() is false.
@@ -0,0 +1,178 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package pgo reads the CPU profiles used for profile-guided optimization
+// and summarizes them as a weighted call graph.
+//
+// The profile is a pprof CPU profile, as written by runtime/pprof or
+// net/http/pprof. Each sample contributes its weight to the call edge at
+// the top of its stack, from the function containing the sampled
+// instruction's caller to that function. Inlined frames are expanded, so
+// the edges are those of the source program rather than of the profiled
+// binary. Call sites are identified by the name of the calling function
+// and the source line of the call, so a profile stays useful as long as
+// the hot code does not move.
+package pgo
+
+import (
+	"fmt"
+	"internal/profile"
+	"os"
+	"sort"
+)
+
+// A CallSite is a call in a calling function.
+type CallSite struct {
+	Caller string // linker symbol name of the calling function
+	Line   int    // line of the call
+}
+
+// A CallEdge is a call from a call site to a function.
+type CallEdge struct {
+	CallSite
+	Callee string // linker symbol name of the called function
+}
+
+// A Profile is a weighted call graph read from a profile.
+type Profile struct {
+	// TotalWeight is the sum of the weights of all edges.
+	TotalWeight int64
+
+	// Weights holds the weight of each edge in the graph.
+	Weights map[CallEdge]int64
+
+	// calls holds the edges out of each call site,
+	// sorted by decreasing weight.
+	calls map[CallSite][]CallEdge
+}
+
+// New reads the profile in file.
+func New(file string) (*Profile, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	prof, err := profile.Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing profile %s: %v", file, err)
+	}
+	p, err := fromProfile(prof)
+	if err != nil {
+		return nil, fmt.Errorf("profile %s: %v", file, err)
+	}
+	return p, nil
+}
+
+// fromProfile builds the call graph of prof.
+func fromProfile(prof *profile.Profile) (*Profile, error) {
+	index := -1
+	for i, st := range prof.SampleType {
+		if st.Type == "samples" && st.Unit == "count" || st.Type == "cpu" && st.Unit == "nanoseconds" {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("no sample value of type samples/count or cpu/nanoseconds")
+	}
+
+	p := &Profile{
+		Weights: make(map[CallEdge]int64),
+		calls:   make(map[CallSite][]CallEdge),
+	}
+	for _, s := range prof.Sample {
+		w := s.Value[index]
+		if w <= 0 {
+			continue
+		}
+		// The first two frames of the sample, innermost first.
+		var frames [2]profile.Line
+		n := 0
+	Locations:
+		for _, loc := range s.Location {
+			for _, line := range loc.Line {
+				if line.Function == nil {
+					continue
+				}
+				frames[n] = line
+				if n++; n == len(frames) {
+					break Locations
+				}
+			}
+		}
+		if n < len(frames) {
+			continue
+		}
+		e := CallEdge{
+			CallSite: CallSite{Caller: frames[1].Function.Name, Line: int(frames[1].Line)},
+			Callee:   frames[0].Function.Name,
+		}
+		if _, ok := p.Weights[e]; !ok {
+			p.calls[e.CallSite] = append(p.calls[e.CallSite], e)
+		}
+		p.Weights[e] += w
+		p.TotalWeight += w
+	}
+
+	for _, edges := range p.calls {
+		p.sortEdges(edges)
+	}
+	return p, nil
+}
+
+// sortEdges sorts edges by decreasing weight, and then by name so that
+// the order does not depend on the order of the samples.
+func (p *Profile) sortEdges(edges []CallEdge) {
+	sort.Sort(&byWeight{p, edges})
+}
+
+type byWeight struct {
+	p     *Profile
+	edges []CallEdge
+}
+
+func (x *byWeight) Len() int      { return len(x.edges) }
+func (x *byWeight) Swap(i, j int) { x.edges[i], x.edges[j] = x.edges[j], x.edges[i] }
+func (x *byWeight) Less(i, j int) bool {
+	ei, ej := x.edges[i], x.edges[j]
+	if wi, wj := x.p.Weights[ei], x.p.Weights[ej]; wi != wj {
+		return wi > wj
+	}
+	if ei.Caller != ej.Caller {
+		return ei.Caller < ej.Caller
+	}
+	if ei.Line != ej.Line {
+		return ei.Line < ej.Line
+	}
+	return ei.Callee < ej.Callee
+}
+
+// Calls returns the edges out of the call site cs, sorted by
+// decreasing weight.
+func (p *Profile) Calls(cs CallSite) []CallEdge {
+	return p.calls[cs]
+}
+
+// HotEdges returns the hottest edges of the graph, which together
+// account for at least percent percent of the total weight.
+func (p *Profile) HotEdges(percent float64) map[CallEdge]bool {
+	edges := make([]CallEdge, 0, len(p.Weights))
+	for e := range p.Weights {
+		edges = append(edges, e)
+	}
+	p.sortEdges(edges)
+
+	hot := make(map[CallEdge]bool)
+	var cum int64
+	for _, e := range edges {
+		if float64(cum) >= float64(p.TotalWeight)*percent/100 {
+			break
+		}
+		hot[e] = true
+		cum += p.Weights[e]
+	}
+	return hot
+}
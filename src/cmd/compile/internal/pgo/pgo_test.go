@@ -0,0 +1,91 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pgo
+
+import (
+	"internal/profile"
+	"reflect"
+	"testing"
+)
+
+// testProfile returns a profile with the given stacks, each listed
+// innermost frame first as function name and line, with weights.
+func testProfile(sampleType string, stacks map[int64][][]profile.Line) *profile.Profile {
+	p := &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "alloc", Unit: "bytes"}, {Type: sampleType, Unit: "count"}},
+	}
+	funcs := make(map[string]*profile.Function)
+	for w, stack := range stacks {
+		var locs []*profile.Location
+		for _, lines := range stack {
+			for i := range lines {
+				name := lines[i].Function.Name
+				f := funcs[name]
+				if f == nil {
+					f = &profile.Function{ID: uint64(len(funcs) + 1), Name: name}
+					funcs[name] = f
+					p.Function = append(p.Function, f)
+				}
+				lines[i].Function = f
+			}
+			loc := &profile.Location{ID: uint64(len(p.Location) + 1), Line: lines}
+			p.Location = append(p.Location, loc)
+			locs = append(locs, loc)
+		}
+		p.Sample = append(p.Sample, &profile.Sample{Location: locs, Value: []int64{0, w}})
+	}
+	return p
+}
+
+func line(fn string, n int64) profile.Line {
+	return profile.Line{Function: &profile.Function{Name: fn}, Line: n}
+}
+
+func TestFromProfile(t *testing.T) {
+	prof := testProfile("samples", map[int64][][]profile.Line{
+		// main.a:10 calls main.b.
+		70: {{line("main.b", 3)}, {line("main.a", 10)}, {line("main.main", 5)}},
+		// main.a:10 calls main.c, which is inlined into main.a at line 11.
+		20: {{line("main.c", 7)}, {line("main.a", 10)}},
+		5:  {{line("main.c", 8), line("main.a", 11)}, {line("main.main", 5)}},
+		// A sample with no caller does not contribute to any edge.
+		4: {{line("main.main", 6)}},
+	})
+	p, err := fromProfile(prof)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ab := CallEdge{CallSite{"main.a", 10}, "main.b"}
+	ac := CallEdge{CallSite{"main.a", 10}, "main.c"}
+	ac11 := CallEdge{CallSite{"main.a", 11}, "main.c"}
+	wantWeights := map[CallEdge]int64{ab: 70, ac: 20, ac11: 5}
+	if !reflect.DeepEqual(p.Weights, wantWeights) {
+		t.Errorf("Weights = %v, want %v", p.Weights, wantWeights)
+	}
+	if p.TotalWeight != 95 {
+		t.Errorf("TotalWeight = %d, want 95", p.TotalWeight)
+	}
+	if got, want := p.Calls(CallSite{"main.a", 10}), []CallEdge{ab, ac}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Calls(main.a:10) = %v, want %v", got, want)
+	}
+
+	if got, want := p.HotEdges(70), map[CallEdge]bool{ab: true}; !reflect.DeepEqual(got, want) {
+		t.Errorf("HotEdges(70) = %v, want %v", got, want)
+	}
+	if got, want := p.HotEdges(80), map[CallEdge]bool{ab: true, ac: true}; !reflect.DeepEqual(got, want) {
+		t.Errorf("HotEdges(80) = %v, want %v", got, want)
+	}
+	if got := p.HotEdges(100); len(got) != 3 {
+		t.Errorf("HotEdges(100) = %v, want all edges", got)
+	}
+}
+
+func TestFromProfileSampleType(t *testing.T) {
+	prof := testProfile("contentions", nil)
+	if _, err := fromProfile(prof); err == nil {
+		t.Errorf("fromProfile succeeded on a profile without CPU samples")
+	}
+}
@@ -70,12 +70,14 @@ type (
 	}
 
 	// Name Type
+	// Name TParamList Type
 	TypeDecl struct {
-		Name   *Name
-		Alias  bool
-		Type   Expr
-		Group  *Group // nil means not part of a group
-		Pragma Pragma
+		Name       *Name
+		TParamList []*Field // nil means no type parameters
+		Alias      bool
+		Type       Expr
+		Group      *Group // nil means not part of a group
+		Pragma     Pragma
 		decl
 	}
 
@@ -90,17 +92,18 @@ type (
 		decl
 	}
 
-	// func          Name Type { Body }
-	// func          Name Type
-	// func Receiver Name Type { Body }
-	// func Receiver Name Type
+	// func          Name TParamList Type { Body }
+	// func          Name TParamList Type
+	// func Receiver Name            Type { Body }
+	// func Receiver Name            Type
 	FuncDecl struct {
-		Attr   map[string]bool // go:attr map
-		Recv   *Field          // nil means regular function
-		Name   *Name
-		Type   *FuncType
-		Body   *BlockStmt // nil means no body (forward declaration)
-		Pragma Pragma     // TODO(mdempsky): Cleaner solution.
+		Attr       map[string]bool // go:attr map
+		Recv       *Field          // nil means regular function
+		Name       *Name
+		TParamList []*Field // nil means no type parameters
+		Type       *FuncType
+		Body       *BlockStmt // nil means no body (forward declaration)
+		Pragma     Pragma     // TODO(mdempsky): Cleaner solution.
 		decl
 	}
 )
@@ -179,6 +182,7 @@ type (
 	}
 
 	// X[Index]
+	// X[T1, T2, ...] (with Ti = Index.(*ListExpr).ElemList[i])
 	IndexExpr struct {
 		X     Expr
 		Index Expr
@@ -212,6 +216,8 @@ type (
 		expr
 	}
 
+	// X Op Y
+	// Op X (Y == nil); ~X is an approximation element in a type constraint
 	Operation struct {
 		Op   Operator
 		X, Y Expr // Y == nil means unary expression
@@ -262,7 +268,7 @@ type (
 	// Name Type
 	//      Type
 	Field struct {
-		Name *Name // nil means anonymous field/parameter (structs/parameters), or embedded interface (interfaces)
+		Name *Name // nil means anonymous field/parameter (structs/parameters), or embedded element (interfaces)
 		Type Expr  // field names declared in a list share the same Type (identical pointers)
 		node
 	}
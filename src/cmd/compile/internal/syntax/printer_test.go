@@ -34,6 +34,12 @@ func TestPrintString(t *testing.T) {
 	for _, want := range []string{
 		"package p",
 		"package p; type _ = int; type T1 = struct{}; type ( _ = *struct{}; T2 = float32 )",
+		"package p; type T[P any] struct{}; type A [N]int; type B [P * Q]int",
+		"package p; type T[P interface{ ~int | ~string }, Q any] []P",
+		"package p; func f[P any, Q ~[]P](x P) Q",
+		"package p; func (r *T[P]) m() T[P, Q]",
+		"package p; var _ = f[int]; var _ = f[int, string](x); var _ = T[int]{}",
+		"package p; type I interface{ M(); ~int | float64; T[int] }; type S struct{ T[int]; a [n]int }",
 		// TODO(gri) expand
 	} {
 		ast, err := Parse(nil, strings.NewReader(want), nil, nil, 0)
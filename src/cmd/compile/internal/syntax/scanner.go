@@ -316,6 +316,10 @@ redo:
 		s.op, s.prec = Not, 0
 		s.tok = _Operator
 
+	case '~':
+		s.op, s.prec = Tilde, 0
+		s.tok = _Operator
+
 	default:
 		s.tok = 0
 		s.errorf("invalid character %#U", c)
@@ -0,0 +1,64 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package syntax
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	const src = `package p
+
+type List[T any] struct {
+	next *List[T]
+	val  T
+}
+
+func Map[F, T any](s []F, f func(F) T) []T {
+	r := make([]T, 0, len(s))
+	for _, v := range s {
+		r = append(r, f(v))
+	}
+	return r
+}
+`
+	file, err := Parse(nil, strings.NewReader(src), nil, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	Walk(file, func(n Node) bool {
+		if n, ok := n.(*Name); ok {
+			names = append(names, n.Value)
+		}
+		return false
+	})
+	got := strings.Join(names, " ")
+	// The shared constraint of F and T is walked twice.
+	const want = "p List T any next List T val T Map F any T any s F f F T T r make T len s _ v s r append r f v r"
+	if got != want {
+		t.Errorf("got names %q\nwant %q", got, want)
+	}
+
+	// Stopping at a declaration skips its children.
+	var decls int
+	Walk(file, func(n Node) bool {
+		switch n := n.(type) {
+		case *Name:
+			if n.Value != "p" {
+				t.Errorf("unexpected name %s", n.Value)
+			}
+		case Decl:
+			decls++
+			return true
+		}
+		return false
+	})
+	if decls != 2 {
+		t.Errorf("got %d declarations, want 2", decls)
+	}
+}
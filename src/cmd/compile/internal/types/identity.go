@@ -70,7 +70,15 @@ func identical(t1, t2 *Type, cmpTags bool, assumedEqual map[typePair]struct{}) b
 				return false
 			}
 		}
-		return true
+		if t1.IsComparableConstraint() != t2.IsComparableConstraint() {
+			return false
+		}
+		terms1, restricted1 := t1.TypeTerms()
+		terms2, restricted2 := t2.TypeTerms()
+		return restricted1 == restricted2 && identicalTerms(terms1, terms2, cmpTags, assumedEqual)
+
+	case TUNION:
+		return identicalTerms(t1.Terms(), t2.Terms(), cmpTags, assumedEqual)
 
 	case TSTRUCT:
 		if t1.NumFields() != t2.NumFields() {
@@ -124,3 +132,18 @@ func identical(t1, t2 *Type, cmpTags bool, assumedEqual map[typePair]struct{}) b
 
 	return identical(t1.Elem(), t2.Elem(), cmpTags, assumedEqual)
 }
+
+// identicalTerms reports whether the type term lists terms1 and terms2
+// are identical. The order of terms matters.
+func identicalTerms(terms1, terms2 []*Term, cmpTags bool, assumedEqual map[typePair]struct{}) bool {
+	if len(terms1) != len(terms2) {
+		return false
+	}
+	for i, x := range terms1 {
+		y := terms2[i]
+		if x.Tilde != y.Tilde || !identical(x.Type, y.Type, cmpTags, assumedEqual) {
+			return false
+		}
+	}
+	return true
+}
@@ -56,6 +56,10 @@ const (
 	TSTRING
 	TUNSAFEPTR
 
+	// pseudo-types for type parameters and their constraints
+	TTYPEPARAM
+	TUNION
+
 	// pseudo-types for literals
 	TIDEAL // untyped numeric constants
 	TNIL
@@ -103,6 +107,9 @@ var (
 	// Predeclared error interface type.
 	Errortype *Type
 
+	// Predeclared comparable constraint interface type.
+	Comparabletype *Type
+
 	// Types to represent untyped string and boolean constants.
 	Idealstring *Type
 	Idealbool   *Type
@@ -131,6 +138,8 @@ type Type struct {
 	// TPTR: Ptr
 	// TARRAY: *Array
 	// TSLICE: Slice
+	// TTYPEPARAM: *TypeParam
+	// TUNION: *Union
 	Extra interface{}
 
 	// Width is the width of this Type in bytes.
@@ -294,6 +303,33 @@ func (t *Type) StructType() *Struct {
 type Interface struct {
 	Fields Fields
 	pkg    *Pkg
+
+	// terms, restricted and comparable describe the type set of a
+	// constraint interface beyond its methods. They are computed
+	// when the interface is expanded. If restricted is set, the
+	// type set only contains the types matching one of terms.
+	terms      []*Term
+	restricted bool
+	comparable bool
+}
+
+// TypeParam contains Type fields specific to type parameters.
+type TypeParam struct {
+	Owner *Sym  // generic function or type declaring the type parameter
+	Index int   // index in the owner's type parameter list
+	Bound *Type // constraint interface
+}
+
+// Union contains Type fields specific to union types. Unions only
+// appear as elements embedded in constraint interfaces.
+type Union struct {
+	Terms []*Term
+}
+
+// A Term is a type term of a union: T, or ~T if Tilde is set.
+type Term struct {
+	Tilde bool
+	Type  *Type
 }
 
 // Ptr contains Type fields specific to pointer types.
@@ -465,6 +501,10 @@ func New(et EType) *Type {
 		t.Extra = new(Chan)
 	case TTUPLE:
 		t.Extra = new(Tuple)
+	case TTYPEPARAM:
+		t.Extra = new(TypeParam)
+	case TUNION:
+		t.Extra = new(Union)
 	}
 	return t
 }
@@ -511,6 +551,24 @@ func NewTuple(t1, t2 *Type) *Type {
 	return t
 }
 
+// NewTypeParam returns a new type parameter named by sym. It is the
+// index'th type parameter of the generic function or type owner.
+func NewTypeParam(sym, owner *Sym, index int) *Type {
+	t := New(TTYPEPARAM)
+	t.Sym = sym
+	tp := t.Extra.(*TypeParam)
+	tp.Owner = owner
+	tp.Index = index
+	return t
+}
+
+// NewUnion returns a new union Type with the given terms.
+func NewUnion(terms []*Term) *Type {
+	t := New(TUNION)
+	t.Extra.(*Union).Terms = terms
+	return t
+}
+
 func newSSA(name string) *Type {
 	t := New(TSSA)
 	t.Extra = name
@@ -687,6 +745,12 @@ func (t *Type) copy() *Type {
 	case TARRAY:
 		x := *t.Extra.(*Array)
 		nt.Extra = &x
+	case TTYPEPARAM:
+		x := *t.Extra.(*TypeParam)
+		nt.Extra = &x
+	case TUNION:
+		x := *t.Extra.(*Union)
+		nt.Extra = &x
 	case TTUPLE, TSSA:
 		Fatalf("ssa types cannot be copied")
 	}
@@ -874,6 +938,63 @@ func (t *Type) SetInterface(methods []*Field) {
 	t.Methods().Set(methods)
 }
 
+// TypeParam returns t's extra type-parameter-specific fields.
+func (t *Type) TypeParam() *TypeParam {
+	t.wantEtype(TTYPEPARAM)
+	return t.Extra.(*TypeParam)
+}
+
+// Terms returns the terms of union type t.
+func (t *Type) Terms() []*Term {
+	t.wantEtype(TUNION)
+	return t.Extra.(*Union).Terms
+}
+
+// TypeTerms returns the type terms of interface t's type set and
+// whether they restrict the type set at all. A restricted type set
+// without terms is empty.
+func (t *Type) TypeTerms() ([]*Term, bool) {
+	t.wantEtype(TINTER)
+	Dowidth(t)
+	i := t.Extra.(*Interface)
+	return i.terms, i.restricted
+}
+
+// SetTypeTerms sets the type terms of interface t's type set.
+func (t *Type) SetTypeTerms(terms []*Term, restricted bool) {
+	t.wantEtype(TINTER)
+	i := t.Extra.(*Interface)
+	i.terms, i.restricted = terms, restricted
+}
+
+// IsComparableConstraint reports whether interface t's type set
+// only contains comparable types, as with the predeclared
+// interface comparable.
+func (t *Type) IsComparableConstraint() bool {
+	t.wantEtype(TINTER)
+	Dowidth(t)
+	return t.Extra.(*Interface).comparable
+}
+
+// SetComparableConstraint sets whether interface t's type set only
+// contains comparable types.
+func (t *Type) SetComparableConstraint(b bool) {
+	t.wantEtype(TINTER)
+	t.Extra.(*Interface).comparable = b
+}
+
+// IsConstraint reports whether t is an interface whose type set is
+// restricted beyond its methods. Such interfaces may only be used as
+// type constraints.
+func (t *Type) IsConstraint() bool {
+	if !t.IsInterface() {
+		return false
+	}
+	Dowidth(t)
+	i := t.Extra.(*Interface)
+	return i.restricted || i.comparable
+}
+
 func (t *Type) WidthCalculated() bool {
 	return t.Align > 0
 }
@@ -1278,7 +1399,17 @@ func (t *Type) IsInterface() bool {
 
 // IsEmptyInterface reports whether t is an empty interface type.
 func (t *Type) IsEmptyInterface() bool {
-	return t.IsInterface() && t.NumFields() == 0
+	if !t.IsInterface() || t.NumFields() != 0 {
+		return false
+	}
+	// Before expansion, embedded elements are fields too.
+	i := t.Extra.(*Interface)
+	return !i.restricted && !i.comparable
+}
+
+// IsTypeParam reports whether t is a type parameter.
+func (t *Type) IsTypeParam() bool {
+	return t.Etype == TTYPEPARAM
 }
 
 func (t *Type) PtrTo() *Type {
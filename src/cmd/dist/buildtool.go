@@ -44,6 +44,7 @@ var bootstrapDirs = []string{
 	"cmd/compile/internal/logopt",
 	"cmd/compile/internal/mips",
 	"cmd/compile/internal/mips64",
+	"cmd/compile/internal/pgo",
 	"cmd/compile/internal/ppc64",
 	"cmd/compile/internal/types",
 	"cmd/compile/internal/s390x",
@@ -95,6 +96,7 @@ var bootstrapDirs = []string{
 	"debug/macho",
 	"debug/pe",
 	"internal/goversion",
+	"internal/profile",
 	"internal/race",
 	"internal/xcoff",
 	"math/big",
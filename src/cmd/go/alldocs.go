@@ -30,6 +30,7 @@
 // 	tool        run specified go tool
 // 	version     print Go version
 // 	vet         report likely mistakes in packages
+// 	work        workspace maintenance
 //
 // Use "go help <command>" for more information about a command.
 //
@@ -160,6 +161,14 @@
 // 		directory, but it is not accessed. When -modfile is specified, an
 // 		alternate go.sum file is also used: its path is derived from the
 // 		-modfile flag by trimming the ".mod" extension and appending ".sum".
+// 	-pgo file
+// 		specify the file path of a profile for profile-guided optimization (PGO).
+// 		The special name "auto" lets the go command select a file named
+// 		"default.pgo" in the main package's directory if that file exists.
+// 		The special name "off" turns off PGO. The default is "auto".
+// 		With "auto", a profile is only used when building a single main
+// 		package. An explicit profile is used for all packages in the build.
+// 		See 'go doc cmd/compile' for the optimizations it enables.
 // 	-pkgdir dir
 // 		install and load all packages from dir instead of the usual locations.
 // 		For example, when building with a non-standard configuration,
@@ -712,6 +721,31 @@
 // directory $GOPATH/pkg/$GOOS_$GOARCH. When module-aware mode is enabled,
 // other packages are built and cached but not installed.
 //
+// If the arguments have version suffixes (like @latest or @v1.0.0), "go install"
+// builds packages in module-aware mode, ignoring the go.mod file in the current
+// directory or any parent directory, if there is one. This is useful for
+// installing executables without affecting the dependencies of the main module.
+// To eliminate ambiguity about which module versions are used in the build, the
+// arguments must satisfy the following constraints:
+//
+// - Arguments must be package paths or package patterns (with "..." wildcards).
+// They must not be standard packages (like fmt), meta-patterns (std, cmd,
+// all), or relative or absolute file paths.
+//
+// - All arguments must have the same version suffix. Different queries are not
+// allowed, even if they refer to the same version.
+//
+// - All arguments must refer to packages in the same module at the same version.
+//
+// - No module is considered the "main" module. If the module containing
+// packages named on the command line has a go.mod file, it must not contain
+// directives (replace and exclude) that would cause it to be interpreted
+// differently than if it were the main module. The go.mod file is never
+// updated, and no go.sum file is written.
+//
+// - Packages named on the command line must be main packages. Packages matched
+// only by a pattern are skipped if they are not main packages.
+//
 // The -i flag installs the dependencies of the named packages as well.
 //
 // For more about the build flags, see 'go help build'.
@@ -780,6 +814,14 @@
 //         TestGoFiles     []string // _test.go files in package
 //         XTestGoFiles    []string // _test.go files outside package
 //
+//         // Embedded files
+//         EmbedPatterns      []string // //go:embed patterns
+//         EmbedFiles         []string // files matched by EmbedPatterns
+//         TestEmbedPatterns  []string // //go:embed patterns in TestGoFiles
+//         TestEmbedFiles     []string // files matched by TestEmbedPatterns
+//         XTestEmbedPatterns []string // //go:embed patterns in XTestGoFiles
+//         XTestEmbedFiles    []string // files matched by XTestEmbedPatterns
+//
 //         // Cgo directives
 //         CgoCFLAGS    []string // cgo: flags for C compiler
 //         CgoCPPFLAGS  []string // cgo: flags for C preprocessor
@@ -1249,6 +1291,12 @@
 // but it may also be an import path, file system path, or pattern
 // matching a single known package, as in 'go run .' or 'go run my/cmd'.
 //
+// If the package argument has a version suffix (like @latest or @v1.0.0),
+// 'go run' builds the program in module-aware mode, ignoring the go.mod file in
+// the current directory or any parent directory, if there is one. This is useful
+// for running programs without affecting the dependencies of the main module.
+// The same constraints apply as for 'go install pkg@version'; see 'go help install'.
+//
 // By default, 'go run' runs the compiled binary directly: 'a.out arguments...'.
 // If the -exec flag is given, 'go run' invokes the binary using xprog:
 // 	'xprog a.out arguments...'.
@@ -1344,7 +1392,7 @@
 // The rule for a match in the cache is that the run involves the same
 // test binary and the flags on the command line come entirely from a
 // restricted set of 'cacheable' test flags, defined as -cpu, -list,
-// -parallel, -run, -short, and -v. If a run of go test has any test
+// -parallel, -run, -shard, -short, and -v. If a run of go test has any test
 // or non-test flags outside this set, the result is not cached. To
 // disable test caching, use any test flag or argument other than the
 // cacheable flags. The idiomatic way to disable test caching explicitly
@@ -1465,6 +1513,125 @@
 // See also: go fmt, go fix.
 //
 //
+// Workspace maintenance
+//
+// Go work provides access to operations on workspaces.
+//
+// A workspace is a set of modules, each in its own directory, that are
+// developed together. The modules in a workspace are listed by the use
+// directives of a go.work file:
+//
+// 	go 1.14
+//
+// 	use (
+// 		./hello
+// 		./example
+// 	)
+//
+// When the go command finds a go.work file in the current directory or
+// one of its parents, it runs in workspace mode: every module listed in
+// go.work is a main module, so that build, test, list, vet and the other
+// build commands resolve imports of any of those modules to the listed
+// directories rather than to a version in the module cache. This makes it
+// possible to work on several interdependent modules at once without
+// adding temporary replace directives to their go.mod files.
+//
+// A go.work file may also contain replace directives, using the same
+// syntax as go.mod. They apply to every module in the workspace and take
+// precedence over replacements of the same module in the go.mod files of
+// the workspace modules.
+//
+// In workspace mode the go.mod files of the workspace modules are never
+// updated, and the -mod flag may only be set to readonly. Checksums found
+// in the go.sum files of the workspace modules are trusted, and any new
+// checksums are recorded in a go.work.sum file next to go.work.
+//
+// The GOWORK environment variable overrides the search for go.work:
+// it may name the go.work file to use, or be set to "off" to disable
+// workspace mode.
+//
+// Note that support for workspaces is built into all the go commands,
+// not just 'go work'. See 'go help modules' for an overview of module
+// functionality.
+//
+// Usage:
+//
+// 	go work <command> [arguments]
+//
+// The commands are:
+//
+// 	init        initialize workspace file
+// 	sync        sync workspace build list to modules
+// 	use         add modules to workspace file
+//
+// Use "go help work <command>" for more information about a command.
+//
+// Initialize workspace file
+//
+// Usage:
+//
+// 	go work init [moddirs]
+//
+// Init initializes and writes a new go.work file in the current
+// directory, in effect creating a new workspace there.
+// The file go.work must not already exist.
+//
+// Init optionally accepts paths to the workspace modules as arguments.
+// Each directory must contain a go.mod file, and is added to the
+// go.work file with a use directive.
+//
+// See 'go help work' for more information about workspaces.
+//
+//
+// Sync workspace build list to modules
+//
+// Usage:
+//
+// 	go work sync
+//
+// Sync syncs the workspace's build list back to the workspace's modules.
+//
+// The workspace's build list is the set of versions of all the
+// (transitive) dependency modules used to do builds in the workspace.
+// It is computed by the minimal version selection algorithm from the
+// requirements of all the workspace modules together.
+//
+// Sync then updates the go.mod file of each workspace module: for every
+// module that the workspace module depends on, directly or indirectly,
+// the requirement is raised to the version in the workspace's build list
+// if that version is higher than the one the module would select on
+// its own. Dependencies that the module does not yet require directly
+// are added as indirect requirements. Requirements are only ever
+// upgraded, never downgraded, and requirements on other workspace
+// modules are left unchanged.
+//
+// See 'go help work' for more information about workspaces.
+//
+//
+// Add modules to workspace file
+//
+// Usage:
+//
+// 	go work use [-r] moddirs
+//
+// Use provides a command-line interface for adding directories,
+// optionally recursively, to a go.work file.
+//
+// A use directive is added to the go.work file for each argument
+// directory that contains a go.mod file. Use directives for argument
+// directories that do not contain a go.mod file are removed from the
+// go.work file, so that 'go work use' can also be used to drop a module
+// that has been deleted from the workspace.
+//
+// The -r flag searches recursively for modules in the argument
+// directories, and the use command operates as if each of the
+// directories were specified as arguments: directories containing a
+// go.mod file are added, and existing use directives for directories
+// below the arguments that no longer contain a go.mod file are removed.
+//
+// See 'go help work' for more information about workspaces.
+//
+//
 // Build modes
 //
 // The 'go build' and 'go install' commands take a -buildmode argument which
@@ -1635,6 +1802,11 @@
 // 	GOTMPDIR
 // 		The directory where the go command will write
 // 		temporary source files, packages, and binaries.
+// 	GOWORK
+// 		The path to the go.work file that enables workspace mode,
+// 		or "off" to disable workspace mode. If empty, the go command
+// 		looks for a go.work file in the current directory and its parents.
+// 		Cannot be set using 'go env -w'. See 'go help work'.
 //
 // Environment variables for use with cgo:
 //
@@ -3009,6 +3181,24 @@
 // 	-failfast
 // 	    Do not start new tests after the first test failure.
 //
+// 	-fuzz regexp
+// 	    Run the fuzz target matching the regular expression. When specified,
+// 	    the command line argument must match exactly one package, and regexp
+// 	    must match exactly one fuzz target within that package. After tests,
+// 	    benchmarks, seed corpora of other fuzz targets, and examples have
+// 	    completed, the matching target will be fuzzed. See the Fuzzing
+// 	    section of the testing package documentation for details.
+//
+// 	-fuzzminimizetime t
+// 	    Run enough iterations of the fuzz function to take t when minimizing
+// 	    a failing input, specified as a time.Duration (for example,
+// 	    -fuzzminimizetime 30s). The default is 60s.
+//
+// 	-fuzztime t
+// 	    Run enough iterations of the fuzz target to take t, specified as a
+// 	    time.Duration (for example, -fuzztime 1h30s). The default is to run
+// 	    forever.
+//
 // 	-list regexp
 // 	    List tests, benchmarks, or examples matching the regular expression.
 // 	    No tests, benchmarks or examples will be run. This will only
@@ -3033,12 +3223,27 @@
 // 	    of all tests matching X, even those without sub-tests matching Y,
 // 	    because it must run them to look for those sub-tests.
 //
+// 	-shard i/n
+// 	    Run only the i'th of n shards of the top-level tests, examples and
+// 	    fuzz targets, where 0 <= i < n. A test is assigned to a shard based
+// 	    only on its name, so running each shard from 0 to n-1, for example
+// 	    on separate machines, runs every test exactly once. Benchmarks are
+// 	    not sharded. The selected shard is reported at the beginning of
+// 	    the test output.
+//
 // 	-short
 // 	    Tell long-running tests to shorten their run time.
 // 	    It is off by default but set during all.bash so that installing
 // 	    the Go tree can run a sanity check but not spend time running
 // 	    exhaustive tests.
 //
+// 	-shuffle off,on,N
+// 	    Randomize the execution order of tests and benchmarks.
+// 	    It is off by default. If -shuffle is set to on, then it will seed
+// 	    the randomizer using the system clock. If -shuffle is set to an
+// 	    integer N, then N will be used as the seed value. In both cases,
+// 	    the seed will be reported for reproducibility.
+//
 // 	-timeout d
 // 	    If a test binary runs longer than duration d, panic.
 // 	    If d is 0, the timeout is disabled.
@@ -3175,8 +3380,8 @@
 //
 // Testing functions
 //
-// The 'go test' command expects to find test, benchmark, and example functions
-// in the "*_test.go" files corresponding to the package under test.
+// The 'go test' command expects to find test, benchmark, fuzz, and example
+// functions in the "*_test.go" files corresponding to the package under test.
 //
 // A test function is one named TestXxx (where Xxx does not start with a
 // lower case letter) and should have the signature,
@@ -3187,6 +3392,10 @@
 //
 // 	func BenchmarkXxx(b *testing.B) { ... }
 //
+// A fuzz target is one named FuzzXxx and should have the signature,
+//
+// 	func FuzzXxx(f *testing.F) { ... }
+//
 // An example function is similar to a test function but, instead of using
 // *testing.T to report success or failure, prints output to os.Stdout.
 // If the last comment in the function starts with "Output:" then the output
@@ -3226,7 +3435,7 @@
 //
 // The entire test file is presented as the example when it contains a single
 // example function, at least one other function, type, variable, or constant
-// declaration, and no test or benchmark functions.
+// declaration, and no test, benchmark, or fuzz functions.
 //
 // See the documentation of the testing package for more information.
 //
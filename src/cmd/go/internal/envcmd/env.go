@@ -148,6 +148,7 @@ func ExtraEnvVars() []cfg.EnvVar {
 	}
 	return []cfg.EnvVar{
 		{Name: "GOMOD", Value: gomod},
+		{Name: "GOWORK", Value: modload.WorkFilePath()},
 	}
 }
 
@@ -378,7 +379,7 @@ func checkEnvWrite(key, val string) error {
 	switch key {
 	case "GOEXE", "GOGCCFLAGS", "GOHOSTARCH", "GOHOSTOS", "GOMOD", "GOTOOLDIR":
 		return fmt.Errorf("%s cannot be modified", key)
-	case "GOENV":
+	case "GOENV", "GOWORK":
 		return fmt.Errorf("%s can only be set using the OS environment", key)
 	}
 
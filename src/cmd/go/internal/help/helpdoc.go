@@ -530,6 +530,11 @@ General-purpose environment variables:
 	GOTMPDIR
 		The directory where the go command will write
 		temporary source files, packages, and binaries.
+	GOWORK
+		The path to the go.work file that enables workspace mode,
+		or "off" to disable workspace mode. If empty, the go command
+		looks for a go.work file in the current directory and its parents.
+		Cannot be set using 'go env -w'. See 'go help work'.
 
 Environment variables for use with cgo:
 
@@ -13,6 +13,7 @@ import (
 	"go/build"
 	"go/scanner"
 	"go/token"
+	"io/fs"
 	"io/ioutil"
 	"os"
 	pathpkg "path"
@@ -30,12 +31,18 @@ import (
 	"cmd/go/internal/par"
 	"cmd/go/internal/search"
 	"cmd/go/internal/str"
+
+	"golang.org/x/mod/module"
 )
 
 var (
 	// module initialization hook; never nil, no-op if module use is disabled
 	ModInit func()
 
+	// module initialization hook for building packages from a single module
+	// at an exact version, ignoring the current module; never nil
+	ModInitOutsideModule func(pattern, version string) (module.Version, error)
+
 	// module hooks; nil if module use is disabled
 	ModBinDir            func() string                                                                            // return effective bin directory
 	ModLookup            func(parentPath string, parentIsStd bool, path string) (dir, realPath string, err error) // lookup effective meaning of import
@@ -101,6 +108,10 @@ type PackagePublic struct {
 	SwigCXXFiles    []string `json:",omitempty"` // .swigcxx files
 	SysoFiles       []string `json:",omitempty"` // .syso system object files added to package
 
+	// Embedded files
+	EmbedPatterns []string `json:",omitempty"` // //go:embed patterns
+	EmbedFiles    []string `json:",omitempty"` // files matched by EmbedPatterns
+
 	// Cgo directives
 	CgoCFLAGS    []string `json:",omitempty"` // cgo: flags for C compiler
 	CgoCPPFLAGS  []string `json:",omitempty"` // cgo: flags for C preprocessor
@@ -126,6 +137,11 @@ type PackagePublic struct {
 	TestImports  []string `json:",omitempty"` // imports from TestGoFiles
 	XTestGoFiles []string `json:",omitempty"` // _test.go files outside package
 	XTestImports []string `json:",omitempty"` // imports from XTestGoFiles
+
+	TestEmbedPatterns  []string `json:",omitempty"` // //go:embed patterns in TestGoFiles
+	TestEmbedFiles     []string `json:",omitempty"` // files matched by TestEmbedPatterns
+	XTestEmbedPatterns []string `json:",omitempty"` // //go:embed patterns in XTestGoFiles
+	XTestEmbedFiles    []string `json:",omitempty"` // files matched by XTestEmbedPatterns
 }
 
 // AllFiles returns the names of all the files considered for the package.
@@ -150,9 +166,34 @@ func (p *Package) AllFiles() []string {
 		p.SysoFiles,
 		p.TestGoFiles,
 		p.XTestGoFiles,
+		p.embedFilesNotListed(),
 	)
 }
 
+// embedFilesNotListed returns the embedded files of p and its tests
+// that do not already appear in one of the other file lists.
+// An embedded file may well be, say, one of the package's .go files.
+func (p *Package) embedFilesNotListed() []string {
+	embed := str.StringList(p.EmbedFiles, p.TestEmbedFiles, p.XTestEmbedFiles)
+	if len(embed) == 0 {
+		return nil
+	}
+	have := make(map[string]bool)
+	for _, list := range [][]string{p.GoFiles, p.CgoFiles, p.IgnoredGoFiles, p.CFiles, p.CXXFiles, p.MFiles, p.HFiles, p.FFiles, p.SFiles, p.SwigFiles, p.SwigCXXFiles, p.SysoFiles, p.TestGoFiles, p.XTestGoFiles} {
+		for _, file := range list {
+			have[file] = true
+		}
+	}
+	var files []string
+	for _, file := range embed {
+		if !have[file] {
+			have[file] = true
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
 // Desc returns the package "description", for use in b.showOutput.
 func (p *Package) Desc() string {
 	if p.ForTest != "" {
@@ -176,10 +217,13 @@ type PackageInternal struct {
 	ExeName           string               // desired name for temporary executable
 	CoverMode         string               // preprocess Go source files with the coverage tool in this mode
 	CoverVars         map[string]*CoverVar // variables created by coverage analysis
+	FuzzInstrument    bool                 // package should be instrumented for fuzzing
 	OmitDebug         bool                 // tell linker not to write debug information
 	GobinSubdir       bool                 // install target would be subdir of GOBIN
 	BuildInfo         string               // add this info to package main
+	PGOProfile        string               // path to the profile for profile-guided optimization
 	TestmainGo        *[]byte              // content for _testmain.go
+	Embed             map[string][]string  // //go:embed comment mapping
 
 	Asmflags   []string // -asmflags for this package
 	Gcflags    []string // -gcflags for this package
@@ -294,6 +338,9 @@ func (p *Package) copyBuild(pp *build.Package) {
 	p.TestImports = pp.TestImports
 	p.XTestGoFiles = pp.XTestGoFiles
 	p.XTestImports = pp.XTestImports
+	p.EmbedPatterns = pp.EmbedPatterns
+	p.TestEmbedPatterns = pp.TestEmbedPatterns
+	p.XTestEmbedPatterns = pp.XTestEmbedPatterns
 	if IgnoreImports {
 		p.Imports = nil
 		p.Internal.RawImports = nil
@@ -1665,6 +1712,14 @@ func (p *Package) load(stk *ImportStack, bp *build.Package, err error) {
 		}
 	}
 
+	// Resolve the //go:embed patterns to the files they match.
+	p.EmbedFiles, p.Internal.Embed, err = resolveEmbed(p.Dir, p.EmbedPatterns)
+	if err != nil {
+		p.Incomplete = true
+		setError(err)
+		setEmbedErrorPos(p.Error, p.Internal.Build.EmbedPatternPos)
+	}
+
 	// Check for case-insensitive collision of input files.
 	// To avoid problems on case-insensitive files, we reject any package
 	// where two different input files have equal names under a case-insensitive
@@ -1781,6 +1836,197 @@ func (p *Package) load(stk *ImportStack, bp *build.Package, err error) {
 	}
 }
 
+// An EmbedError indicates a problem with a //go:embed pattern.
+type EmbedError struct {
+	Pattern string
+	Err     error
+}
+
+func (e *EmbedError) Error() string {
+	return fmt.Sprintf("pattern %s: %v", e.Pattern, e.Err)
+}
+
+func (e *EmbedError) Unwrap() error {
+	return e.Err
+}
+
+// setEmbedErrorPos sets the position of perr, if it reports an *EmbedError,
+// to the first position of the offending pattern in posMap.
+func setEmbedErrorPos(perr *PackageError, posMap map[string][]token.Position) {
+	e, ok := perr.Err.(*EmbedError)
+	if !ok || perr.Pos != "" {
+		return
+	}
+	if pos := posMap[e.Pattern]; len(pos) > 0 {
+		p := pos[0]
+		p.Filename = base.ShortPath(p.Filename)
+		perr.Pos = p.String()
+	}
+}
+
+// resolveEmbed resolves //go:embed patterns and returns only the file list it matches.
+// For each pattern, the returned map lists the files the pattern matches.
+//
+// Patterns may not contain . or .. path elements, and they may not match
+// files in a different module (any directory containing a go.mod file),
+// nor files with names that could not be packaged into a module zip.
+// A pattern naming a directory matches all the files in that directory tree,
+// except those beginning with . or _.
+func resolveEmbed(pkgdir string, patterns []string) (files []string, pmap map[string][]string, err error) {
+	if len(patterns) == 0 {
+		return nil, nil, nil
+	}
+	var pattern string
+	defer func() {
+		if err != nil {
+			err = &EmbedError{Pattern: pattern, Err: err}
+		}
+	}()
+
+	pmap = make(map[string][]string)
+	have := make(map[string]int)
+	dirOK := make(map[string]bool)
+	pid := 0 // pattern ID, to allow reuse of have map
+	for _, pattern = range patterns {
+		pid++
+
+		// Check pattern is valid for //go:embed.
+		if _, err := pathpkg.Match(pattern, ""); err != nil || !validEmbedPattern(pattern) {
+			return nil, nil, fmt.Errorf("invalid pattern syntax")
+		}
+
+		// Glob to find matches.
+		match, err := filepath.Glob(pkgdir + string(filepath.Separator) + filepath.FromSlash(pattern))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// Filter list of matches down to the ones that will still exist when
+		// the directory is packaged up as a module. (If pkgdir is in the module cache,
+		// only those files exist already, but if pkgdir is in the current module,
+		// then there may be other things lying around, like symbolic links or .git directories.)
+		var list []string
+		for _, file := range match {
+			rel := filepath.ToSlash(file[len(pkgdir)+1:]) // file, relative to pkgdir
+
+			what := "file"
+			info, err := os.Lstat(file)
+			if err != nil {
+				return nil, nil, err
+			}
+			if info.IsDir() {
+				what = "directory"
+			}
+
+			// Check that directories along path do not begin a new module
+			// (do not contain a go.mod).
+			for dir := file; len(dir) > len(pkgdir)+1 && !dirOK[dir]; dir = filepath.Dir(dir) {
+				if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+					return nil, nil, fmt.Errorf("cannot embed %s %s: in different module", what, rel)
+				}
+				if dir != file {
+					if info, err := os.Lstat(dir); err == nil && !info.IsDir() {
+						return nil, nil, fmt.Errorf("cannot embed %s %s: in non-directory %s", what, rel, dir[len(pkgdir)+1:])
+					}
+				}
+				dirOK[dir] = true
+				if elem := filepath.Base(dir); isBadEmbedName(elem) {
+					if dir == file {
+						return nil, nil, fmt.Errorf("cannot embed %s %s: invalid name %s", what, rel, elem)
+					}
+					return nil, nil, fmt.Errorf("cannot embed %s %s: in invalid directory %s", what, rel, elem)
+				}
+			}
+
+			switch {
+			default:
+				return nil, nil, fmt.Errorf("cannot embed irregular file %s", rel)
+
+			case info.Mode().IsRegular():
+				if have[rel] != pid {
+					have[rel] = pid
+					list = append(list, rel)
+				}
+
+			case info.IsDir():
+				// Gather all files in the named directory, stopping at module boundaries
+				// and ignoring files that wouldn't be packaged into a module.
+				count := 0
+				err := filepath.Walk(file, func(path string, info os.FileInfo, err error) error {
+					if err != nil {
+						return err
+					}
+					rel := filepath.ToSlash(path[len(pkgdir)+1:])
+					name := info.Name()
+					if path != file && (isBadEmbedName(name) || name[0] == '.' || name[0] == '_') {
+						// Ignore bad names, assuming they won't go into modules.
+						// Also avoid hidden files that user may not know about.
+						if info.IsDir() {
+							return filepath.SkipDir
+						}
+						return nil
+					}
+					if info.IsDir() {
+						if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+							return filepath.SkipDir
+						}
+						return nil
+					}
+					if !info.Mode().IsRegular() {
+						return nil
+					}
+					count++
+					if have[rel] != pid {
+						have[rel] = pid
+						list = append(list, rel)
+					}
+					return nil
+				})
+				if err != nil {
+					return nil, nil, err
+				}
+				if count == 0 {
+					return nil, nil, fmt.Errorf("cannot embed directory %s: contains no embeddable files", rel)
+				}
+			}
+		}
+
+		if len(list) == 0 {
+			return nil, nil, fmt.Errorf("no matching files found")
+		}
+		sort.Strings(list)
+		pmap[pattern] = list
+	}
+
+	for file := range have {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files, pmap, nil
+}
+
+func validEmbedPattern(pattern string) bool {
+	return pattern != "." && fs.ValidPath(pattern)
+}
+
+// isBadEmbedName reports whether name is the base name of a file that
+// can't or won't be included in modules and therefore shouldn't be treated
+// as existing for embedding.
+func isBadEmbedName(name string) bool {
+	if err := module.CheckFilePath(name); err != nil {
+		return true
+	}
+	switch name {
+	// Empty string should be impossible but make it bad.
+	case "":
+		return true
+	// Version control directories won't be present in module.
+	case ".bzr", ".hg", ".git", ".svn":
+		return true
+	}
+	return false
+}
+
 // collectDeps populates p.Deps and p.DepsErrors by iterating over
 // p.Internal.Imports.
 //
@@ -2096,6 +2342,7 @@ func PackagesAndErrors(patterns []string) []*Package {
 	// (not just the ones matching the patterns but also
 	// their dependencies).
 	setToolFlags(pkgs...)
+	setPGOProfilePath(pkgs)
 
 	return pkgs
 }
@@ -2109,6 +2356,58 @@ func setToolFlags(pkgs ...*Package) {
 	}
 }
 
+// setPGOProfilePath sets the profile used for profile-guided optimization
+// of pkgs and their dependencies, as selected by the -pgo flag, and
+// records it in the build info of the main packages.
+func setPGOProfilePath(pkgs []*Package) {
+	var file string
+	switch cfg.BuildPGO {
+	case "", "off":
+		return
+	case "auto":
+		// Use the default.pgo file of the main package,
+		// but only if there is only one main package.
+		var main *Package
+		for _, p := range pkgs {
+			if p.Name == "main" {
+				if main != nil {
+					return
+				}
+				main = p
+			}
+		}
+		if main == nil || main.Dir == "" {
+			return
+		}
+		file = filepath.Join(main.Dir, "default.pgo")
+		if fi, err := os.Stat(file); err != nil || !fi.Mode().IsRegular() {
+			return
+		}
+	default:
+		abs, err := filepath.Abs(cfg.BuildPGO)
+		if err != nil {
+			base.Fatalf("go: -pgo: %v", err)
+		}
+		if _, err := os.Stat(abs); err != nil {
+			base.Fatalf("go: -pgo: %v", err)
+		}
+		file = abs
+	}
+
+	for _, p := range PackageList(pkgs) {
+		p.Internal.PGOProfile = file
+	}
+	recorded := file
+	if cfg.BuildTrimpath {
+		recorded = filepath.Base(file)
+	}
+	for _, p := range pkgs {
+		if p.Internal.BuildInfo != "" {
+			p.Internal.BuildInfo += "build\t-pgo=" + recorded + "\n"
+		}
+	}
+}
+
 func ImportPaths(args []string) []*search.Match {
 	if ModInit(); cfg.ModulesEnabled {
 		return ModImportPaths(args)
@@ -2245,6 +2544,7 @@ func GoFilesPackage(gofiles []string) *Package {
 	}
 
 	setToolFlags(pkg)
+	setPGOProfilePath([]*Package{pkg})
 
 	return pkg
 }
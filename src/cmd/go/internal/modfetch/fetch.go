@@ -307,6 +307,11 @@ func RemoveAll(dir string) error {
 
 var GoSumFile string // path to go.sum; set by package modload
 
+// WorkspaceGoSumFiles lists the go.sum files of the main modules in workspace
+// mode; set by package modload. Their checksums are trusted, but they are
+// never written: new checksums go to GoSumFile, which is go.work.sum.
+var WorkspaceGoSumFiles []string
+
 type modSum struct {
 	mod module.Version
 	sum string
@@ -320,6 +325,7 @@ var goSum struct {
 	overwrite bool                        // if true, overwrite go.sum without incorporating its contents
 	enabled   bool                        // whether to use go.sum at all
 	modverify string                      // path to go.modverify, to be deleted
+	workspace map[modSum]bool             // sums listed in WorkspaceGoSumFiles
 }
 
 // initGoSum initializes the go.sum data.
@@ -343,6 +349,24 @@ func initGoSum() (bool, error) {
 	goSum.enabled = true
 	readGoSum(goSum.m, GoSumFile, data)
 
+	goSum.workspace = make(map[modSum]bool)
+	for _, file := range WorkspaceGoSumFiles {
+		data, err := lockedfile.Read(file)
+		if err != nil && !os.IsNotExist(err) {
+			return false, err
+		}
+		sums := make(map[module.Version][]string)
+		readGoSum(sums, file, data)
+		for mod, list := range sums {
+			for _, h := range list {
+				if !goSum.workspace[modSum{mod, h}] {
+					goSum.workspace[modSum{mod, h}] = true
+					goSum.m[mod] = append(goSum.m[mod], h)
+				}
+			}
+		}
+	}
+
 	// Add old go.modverify file.
 	// We'll delete go.modverify in WriteGoSum.
 	alt := strings.TrimSuffix(GoSumFile, ".sum") + ".modverify"
@@ -565,7 +589,9 @@ func WriteGoSum() {
 		// Don't bother opening the go.sum file if we don't have anything to add.
 		return
 	}
-	if cfg.BuildMod == "readonly" {
+	if cfg.BuildMod == "readonly" && WorkspaceGoSumFiles == nil {
+		// In workspace mode, -mod=readonly protects the go.mod files of the
+		// main modules, but go.work.sum may still be updated.
 		base.Fatalf("go: updates to go.sum needed, disabled by -mod=readonly")
 	}
 
@@ -600,6 +626,10 @@ func WriteGoSum() {
 			list := goSum.m[m]
 			sort.Strings(list)
 			for _, h := range list {
+				if goSum.workspace[modSum{m, h}] {
+					// Already recorded in a main module's go.sum.
+					continue
+				}
 				fmt.Fprintf(&buf, "%s %s %s\n", m.Path, m.Version, h)
 			}
 		}
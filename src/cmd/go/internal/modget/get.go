@@ -989,7 +989,7 @@ func (u *upgrader) Upgrade(m module.Version) (module.Version, error) {
 // requested at a specific version. This helps us understand the requirements
 // implied by each downgrade.
 func buildListForLostUpgrade(lost module.Version, reqs mvs.Reqs) ([]module.Version, error) {
-	return mvs.BuildList(lostUpgradeRoot, &lostUpgradeReqs{Reqs: reqs, lost: lost})
+	return mvs.BuildList([]module.Version{lostUpgradeRoot}, &lostUpgradeReqs{Reqs: reqs, lost: lost})
 }
 
 var lostUpgradeRoot = module.Version{Path: "lost-upgrade-root", Version: ""}
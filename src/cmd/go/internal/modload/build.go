@@ -104,17 +104,17 @@ func addVersions(m *modinfo.ModulePublic) {
 }
 
 func moduleInfo(m module.Version, fromBuildList bool) *modinfo.ModulePublic {
-	if m == Target {
+	if isMainModule(m) {
 		info := &modinfo.ModulePublic{
 			Path:    m.Path,
 			Version: m.Version,
 			Main:    true,
 		}
 		if HasModRoot() {
-			info.Dir = ModRoot()
+			info.Dir = mainModuleRoot(m)
 			info.GoMod = filepath.Join(info.Dir, "go.mod")
-			if modFile.Go != nil {
-				info.GoVersion = modFile.Go.Version
+			if f := mainModFile(m); f.Go != nil {
+				info.GoVersion = f.Go.Version
 			}
 		}
 		return info
@@ -235,7 +235,7 @@ func PackageBuildInfo(path string, deps []string) string {
 		}
 		r := Replacement(mod)
 		h := ""
-		if r.Path == "" {
+		if r.Path == "" && !isMainModule(mod) {
 			h = "\t" + modfetch.Sum(mod)
 		}
 		fmt.Fprintf(&buf, "dep\t%s\t%s%s\n", mod.Path, mv, h)
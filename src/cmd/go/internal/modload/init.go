@@ -56,11 +56,56 @@ var (
 	CmdModInit   bool   // running 'go mod init'
 	CmdModModule string // module argument for 'go mod init'
 
+	// ForceUseModules may be set to force modules to be enabled when
+	// GO111MODULE=auto or to report an error when GO111MODULE=off.
+	ForceUseModules bool
+
+	// RootMode determines whether a module root is needed.
+	RootMode Root
+
 	allowMissingModuleImports bool
+
+	// workFilePath is the path of the go.work file in use, or the empty
+	// string if the go command is not running in workspace mode.
+	workFilePath string
+	workFile     *WorkFile
+	workModRoots []string // root directories of the modules listed in workFile
+)
+
+// A Root describes how Init locates the main module.
+type Root int
+
+const (
+	// AutoRoot is the default for most commands. modload.Init will look for
+	// a go.mod file in the current directory or any parent. If none is found,
+	// modules may be disabled (GO111MODULE=auto) or commands may run in a
+	// limited module mode.
+	AutoRoot Root = iota
+
+	// NoRoot is used for commands that run in module mode and ignore any go.mod
+	// or go.work file in the current directory or in parent directories.
+	NoRoot
 )
 
 var modFile *modfile.File
 
+// mainModules lists the main modules. In workspace mode, these are the
+// modules listed in the go.work file, in order. Otherwise, mainModules holds
+// only Target.
+var mainModules []module.Version
+
+// In workspace mode, mainModuleRoots and mainModFiles record the root
+// directory and the parsed go.mod file of each main module, and workReplace
+// and workExclude hold the replacements and exclusions gathered from the
+// go.work file and the go.mod files of all main modules. Relative replacement
+// directories in workReplace are made absolute.
+var (
+	mainModuleRoots map[module.Version]string
+	mainModFiles    map[module.Version]*modfile.File
+	workReplace     map[module.Version]module.Version
+	workExclude     map[module.Version]bool
+)
+
 // A modFileIndex is an index of data corresponding to a modFile
 // at a specific point in time.
 type modFileIndex struct {
@@ -119,10 +164,13 @@ func Init() {
 	default:
 		base.Fatalf("go: unknown environment setting GO111MODULE=%s", env)
 	case "auto", "":
-		mustUseModules = false
+		mustUseModules = ForceUseModules
 	case "on":
 		mustUseModules = true
 	case "off":
+		if ForceUseModules {
+			base.Fatalf("go: modules disabled by GO111MODULE=off; see 'go help modules'")
+		}
 		mustUseModules = false
 		return
 	}
@@ -154,7 +202,23 @@ func Init() {
 		os.Setenv("GIT_SSH_COMMAND", "ssh -o ControlMaster=no")
 	}
 
-	if CmdModInit {
+	if !CmdModInit && workspaceAllowed() {
+		workFilePath = findWorkFile()
+	}
+	if RootMode == NoRoot {
+		// The caller sets the build list explicitly,
+		// so any go.mod file in the current directory is ignored.
+		if cfg.ModFile != "" {
+			base.Fatalf("go: -modfile cannot be used with commands that ignore the current module")
+		}
+		modRoot = ""
+	} else if workFilePath != "" {
+		if cfg.ModFile != "" {
+			base.Fatalf("go: -modfile cannot be used in workspace mode")
+		}
+		mustUseModules = true
+		loadWorkFile()
+	} else if CmdModInit {
 		// Running 'go mod init': go.mod will be created in current directory.
 		modRoot = base.Cwd
 	} else {
@@ -238,14 +302,103 @@ func Init() {
 		// For example, 'go get' does this, since it is expected to resolve paths.
 		//
 		// See golang.org/issue/32027.
+	} else if workFilePath != "" {
+		// The go.sum files of the main modules are read but not written.
+		// Any other checksums are recorded in go.work.sum.
+		modfetch.GoSumFile = workFilePath + ".sum"
+		modfetch.WorkspaceGoSumFiles = []string{}
+		for _, dir := range workModRoots {
+			modfetch.WorkspaceGoSumFiles = append(modfetch.WorkspaceGoSumFiles, filepath.Join(dir, "go.sum"))
+		}
+		search.SetWorkspaceModRoots(workModRoots)
 	} else {
 		modfetch.GoSumFile = strings.TrimSuffix(ModFilePath(), ".mod") + ".sum"
 		search.SetModRoot(modRoot)
 	}
 }
 
+// workspaceAllowed reports whether the current command may run in workspace
+// mode. 'go get' and the 'go mod' subcommands read and write the go.mod file
+// of a single module, so they ignore go.work files.
+func workspaceAllowed() bool {
+	return RootMode != NoRoot && cfg.CmdName != "get" && !strings.HasPrefix(cfg.CmdName, "mod ")
+}
+
+// findWorkFile returns the path of the go.work file to use, or the empty
+// string if the go command should not run in workspace mode.
+//
+// GOWORK=off disables workspace mode, and any other non-empty value of
+// GOWORK names the go.work file to use. Otherwise, findWorkFile looks for a
+// go.work file in the current directory and its parents.
+func findWorkFile() string {
+	switch gowork := cfg.Getenv("GOWORK"); gowork {
+	case "off":
+		return ""
+	case "":
+		// Search for go.work below.
+	default:
+		if !filepath.IsAbs(gowork) {
+			base.Fatalf("go: invalid GOWORK: not an absolute path")
+		}
+		if !strings.HasSuffix(gowork, ".work") {
+			base.Fatalf("go: invalid GOWORK: file does not have .work extension")
+		}
+		return gowork
+	}
+
+	dir := filepath.Clean(base.Cwd)
+	for {
+		if fi, err := os.Stat(filepath.Join(dir, "go.work")); err == nil && !fi.IsDir() {
+			return filepath.Join(dir, "go.work")
+		}
+		d := filepath.Dir(dir)
+		if d == dir {
+			break
+		}
+		dir = d
+	}
+	return ""
+}
+
+// WorkFilePath returns the path of the go.work file in use,
+// or the empty string if the go command is not in workspace mode.
+func WorkFilePath() string {
+	Init()
+	return workFilePath
+}
+
+// loadWorkFile reads the go.work file and sets workModRoots.
+// modRoot is set to the root of the listed module containing the
+// current directory or, if there is none, the first listed module.
+func loadWorkFile() {
+	wf, err := ReadWorkFile(workFilePath)
+	if err != nil {
+		base.Fatalf("go: %v", err)
+	}
+	workFile = wf
+
+	workDir := filepath.Dir(workFilePath)
+	for _, dir := range wf.Use {
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(workDir, dir)
+		}
+		workModRoots = append(workModRoots, filepath.Clean(dir))
+	}
+
+	modRoot = ""
+	for _, root := range workModRoots {
+		if search.InDir(base.Cwd, root) != "" && len(root) > len(modRoot) {
+			modRoot = root
+		}
+	}
+	if modRoot == "" && len(workModRoots) > 0 {
+		modRoot = workModRoots[0]
+	}
+}
+
 func init() {
 	load.ModInit = Init
+	load.ModInitOutsideModule = InitOutsideModule
 
 	// Set modfetch.PkgMod and codehost.WorkRoot unconditionally,
 	// so that go clean -modcache and go mod download can run even without modules enabled.
@@ -265,7 +418,7 @@ func init() {
 // be called until the command is installed and flags are parsed. Instead of
 // calling Init and Enabled, the main package can call this function.
 func WillBeEnabled() bool {
-	if modRoot != "" || mustUseModules {
+	if modRoot != "" || mustUseModules || ForceUseModules {
 		return true
 	}
 	if initialized {
@@ -288,6 +441,9 @@ func WillBeEnabled() bool {
 		// Running 'go mod init': go.mod will be created in current directory.
 		return true
 	}
+	if workspaceAllowed() && findWorkFile() != "" {
+		return true
+	}
 	if modRoot := findModuleRoot(base.Cwd); modRoot == "" {
 		// GO111MODULE is 'auto', and we can't find a module root.
 		// Stay in GOPATH mode.
@@ -381,9 +537,14 @@ func InitMod() {
 	}
 
 	Init()
+	if workFilePath != "" {
+		initWorkspace()
+		return
+	}
 	if modRoot == "" {
 		Target = module.Version{Path: "command-line-arguments"}
 		targetPrefix = "command-line-arguments"
+		mainModules = []module.Version{Target}
 		buildList = []module.Version{Target}
 		return
 	}
@@ -492,6 +653,63 @@ func AllowMissingModuleImports() {
 	allowMissingModuleImports = true
 }
 
+// InitOutsideModule enables module mode without a main module, ignoring any
+// go.mod file in the current directory or its parents, and sets the build list
+// to require only the module providing pattern at the given version.
+// It returns that module.
+//
+// The module's go.mod file must not contain replace or exclude directives,
+// since those would only take effect if it were the main module.
+func InitOutsideModule(pattern, version string) (module.Version, error) {
+	ForceUseModules = true
+	RootMode = NoRoot
+	AllowMissingModuleImports()
+	InitMod()
+
+	// If multiple modules provide pattern, accept the longest match.
+	results, err := QueryPattern(pattern, version, Allowed)
+	if err != nil {
+		return module.Version{}, err
+	}
+	m := results[0].Mod
+
+	data, err := modfetch.GoMod(m.Path, m.Version)
+	if err != nil {
+		return module.Version{}, err
+	}
+	// Parse laxly and look for the directives in the syntax, so that
+	// they are refused even if they would not pass a strict parse.
+	f, err := modfile.ParseLax("go.mod", data, nil)
+	if err != nil {
+		return module.Version{}, fmt.Errorf("%s: %v", m, err)
+	}
+	used := make(map[string]bool)
+	for _, stmt := range f.Syntax.Stmt {
+		switch stmt := stmt.(type) {
+		case *modfile.Line:
+			used[stmt.Token[0]] = true
+		case *modfile.LineBlock:
+			used[stmt.Token[0]] = true
+		}
+	}
+	directive := ""
+	if used["replace"] {
+		directive = "replace"
+	} else if used["exclude"] {
+		directive = "exclude"
+	}
+	if directive != "" {
+		return module.Version{}, fmt.Errorf("%s\n"+
+			"\tThe go.mod file for the module providing named packages contains one or\n"+
+			"\tmore %s directives. It must not contain directives that would cause\n"+
+			"\tit to be interpreted differently than if it were the main module.", m, directive)
+	}
+
+	// The requirements of m are read from its go.mod file as usual.
+	SetBuildList([]module.Version{Target, m})
+	return m, nil
+}
+
 // modFileToBuildList initializes buildList from the modFile.
 func modFileToBuildList() {
 	Target = modFile.Module.Mod
@@ -503,6 +721,7 @@ func modFileToBuildList() {
 		}
 	}
 
+	mainModules = []module.Version{Target}
 	list := []module.Version{Target}
 	for _, r := range modFile.Require {
 		list = append(list, r.Mod)
@@ -510,9 +729,171 @@ func modFileToBuildList() {
 	buildList = list
 }
 
+// initWorkspace sets mainModules and Target from the go.work file and the
+// go.mod files of the modules it lists. Target is the main module whose
+// root is modRoot.
+func initWorkspace() {
+	if len(workModRoots) == 0 {
+		base.Fatalf("go: no modules were found in the current workspace; see 'go help work'")
+	}
+
+	mainModules = nil
+	mainModuleRoots = make(map[module.Version]string)
+	mainModFiles = make(map[module.Version]*modfile.File)
+	workReplace = make(map[module.Version]module.Version)
+	workExclude = make(map[module.Version]bool)
+
+	replacedBy := make(map[module.Version]string)
+	addReplace := func(r *modfile.Replace, dir, file string) {
+		repl := r.New
+		if repl.Version == "" && !filepath.IsAbs(repl.Path) {
+			repl.Path = filepath.Join(dir, repl.Path)
+		}
+		if prev, ok := workReplace[r.Old]; ok && prev != repl {
+			base.Fatalf("go: conflicting replacements for %v:\n\t%v (in %s)\n\t%v (in %s)\nadd a replace directive to %s to resolve the conflict",
+				r.Old, prev, base.ShortPath(replacedBy[r.Old]), repl, base.ShortPath(file), base.ShortPath(workFilePath))
+		}
+		workReplace[r.Old] = repl
+		replacedBy[r.Old] = file
+	}
+
+	// Replacements in go.work take precedence over those in go.mod files.
+	for _, r := range workFile.Replace {
+		addReplace(r, filepath.Dir(workFilePath), workFilePath)
+	}
+	inWorkFile := make(map[module.Version]bool)
+	for old := range workReplace {
+		inWorkFile[old] = true
+	}
+
+	for _, root := range workModRoots {
+		gomod := filepath.Join(root, "go.mod")
+		data, err := lockedfile.Read(gomod)
+		if err != nil {
+			base.Fatalf("go: %v", err)
+		}
+		f, err := modfile.Parse(gomod, data, nil)
+		if err != nil {
+			// Errors returned by modfile.Parse begin with file:line.
+			base.Fatalf("go: errors parsing go.mod:\n%s\n", err)
+		}
+		if f.Module == nil {
+			base.Fatalf("go: %s has no module directive", base.ShortPath(gomod))
+		}
+		m := f.Module.Mod
+		if prev, ok := mainModuleRoots[m]; ok {
+			base.Fatalf("go: module %s appears multiple times in workspace:\n\t%s\n\t%s", m.Path, base.ShortPath(prev), base.ShortPath(root))
+		}
+		mainModules = append(mainModules, m)
+		mainModuleRoots[m] = root
+		mainModFiles[m] = f
+
+		for _, r := range f.Replace {
+			if !inWorkFile[r.Old] {
+				addReplace(r, root, gomod)
+			}
+		}
+		for _, x := range f.Exclude {
+			workExclude[x.Mod] = true
+		}
+		if root == modRoot {
+			Target = m
+			modFile = f
+			index = indexModFile(data, f, false)
+		}
+	}
+
+	targetPrefix = Target.Path
+	buildList = append([]module.Version(nil), mainModules...)
+	setDefaultBuildMod()
+}
+
+// MainModules returns the main modules: the modules listed in the go.work
+// file in workspace mode, or else only the module containing the current
+// directory.
+func MainModules() []module.Version {
+	InitMod()
+	return mainModules
+}
+
+// MainModuleDir returns the root directory of the main module m.
+func MainModuleDir(m module.Version) string {
+	return mainModuleRoot(m)
+}
+
+// isMainModule reports whether m is one of the main modules.
+func isMainModule(m module.Version) bool {
+	if mainModuleRoots != nil {
+		_, ok := mainModuleRoots[m]
+		return ok
+	}
+	return m == Target
+}
+
+// mainModuleForPath returns the main module with the given module path.
+func mainModuleForPath(path string) (module.Version, bool) {
+	for _, m := range mainModules {
+		if m.Path == path {
+			return m, true
+		}
+	}
+	return module.Version{}, false
+}
+
+// mainModuleRoot returns the root directory of the main module m.
+func mainModuleRoot(m module.Version) string {
+	if root, ok := mainModuleRoots[m]; ok {
+		return root
+	}
+	return ModRoot()
+}
+
+// mainModulePrefix returns the path prefix for packages in the main module m.
+func mainModulePrefix(m module.Version) string {
+	if m == Target {
+		return targetPrefix
+	}
+	return m.Path
+}
+
+// mainModFile returns the parsed go.mod file of the main module m, or nil
+// if it has none.
+func mainModFile(m module.Version) *modfile.File {
+	if f, ok := mainModFiles[m]; ok {
+		return f
+	}
+	return modFile
+}
+
+// mainModuleForDir returns the main module whose root directory contains
+// dir, and that root. If no main module contains dir, ok is false.
+func mainModuleForDir(dir string) (m module.Version, root string, ok bool) {
+	if modRoot == "" {
+		return module.Version{}, "", false
+	}
+	for _, mm := range mainModules {
+		r := mainModuleRoot(mm)
+		if (dir == r || strings.HasPrefix(dir, r+string(filepath.Separator))) && len(r) > len(root) {
+			m, root, ok = mm, r, true
+		}
+	}
+	return m, root, ok
+}
+
 // setDefaultBuildMod sets a default value for cfg.BuildMod
-// if it is currently empty.
+// if it is currently empty. In workspace mode, the only allowed
+// value is "readonly".
 func setDefaultBuildMod() {
+	if workFilePath != "" {
+		// The go.mod files of the main modules are never updated in
+		// workspace mode, and there is no single vendor directory to use.
+		if cfg.BuildMod != "" && cfg.BuildMod != "readonly" {
+			base.Fatalf("go: -mod may only be set to readonly when in workspace mode")
+		}
+		cfg.BuildMod = "readonly"
+		cfg.BuildModReason = fmt.Sprintf("workspace mode is enabled by %s.", base.ShortPath(workFilePath))
+		return
+	}
 	if cfg.BuildMod != "" {
 		// Don't override an explicit '-mod=' argument.
 		return
@@ -646,7 +1027,11 @@ func checkVendorConsistency() {
 }
 
 // Allowed reports whether module m is allowed (not excluded) by the main module's go.mod.
+// In workspace mode, m is allowed unless it is excluded by any main module.
 func Allowed(m module.Version) bool {
+	if workExclude != nil {
+		return !workExclude[m]
+	}
 	return index == nil || !index.exclude[m]
 }
 
@@ -900,6 +1285,13 @@ func WriteGoMod() {
 		return
 	}
 
+	// In workspace mode, the go.mod files of the main modules are left alone,
+	// but go.work.sum may need new checksums.
+	if workFilePath != "" {
+		modfetch.WriteGoSum()
+		return
+	}
+
 	if cfg.BuildMod != "readonly" {
 		addGoStmt()
 	}
@@ -45,7 +45,11 @@ func ListModules(args []string, listU, listVersions bool) []*modinfo.ModulePubli
 func listModules(args []string, listVersions bool) []*modinfo.ModulePublic {
 	LoadBuildList()
 	if len(args) == 0 {
-		return []*modinfo.ModulePublic{moduleInfo(buildList[0], true)}
+		var mods []*modinfo.ModulePublic
+		for _, m := range mainModules {
+			mods = append(mods, moduleInfo(m, true))
+		}
+		return mods
 	}
 
 	var mods []*modinfo.ModulePublic
@@ -124,10 +124,10 @@ func ImportPathsQuiet(patterns []string, tags map[string]bool) []*search.Match {
 					// Note: The checks for @ here are just to avoid misinterpreting
 					// the module cache directories (formerly GOPATH/src/mod/foo@v1.5.2/bar).
 					// It's not strictly necessary but helpful to keep the checks.
-					if modRoot != "" && dir == modRoot {
-						pkg = targetPrefix
-					} else if modRoot != "" && strings.HasPrefix(dir, modRoot+string(filepath.Separator)) && !strings.Contains(dir[len(modRoot):], "@") {
-						suffix := filepath.ToSlash(dir[len(modRoot):])
+					if mainMod, root, ok := mainModuleForDir(dir); ok && dir == root {
+						pkg = mainModulePrefix(mainMod)
+					} else if ok && !strings.Contains(dir[len(root):], "@") {
+						suffix := filepath.ToSlash(dir[len(root):])
 						if strings.HasPrefix(suffix, "/vendor/") {
 							// TODO getmode vendor check
 							pkg = strings.TrimPrefix(suffix, "/vendor/")
@@ -142,8 +142,9 @@ func ImportPathsQuiet(patterns []string, tags map[string]bool) []*search.Match {
 								continue
 							}
 						} else {
-							modPkg := targetPrefix + suffix
-							if _, ok := dirInModule(modPkg, targetPrefix, modRoot, true); ok {
+							prefix := mainModulePrefix(mainMod)
+							modPkg := prefix + suffix
+							if _, ok := dirInModule(modPkg, prefix, root, true); ok {
 								pkg = modPkg
 							} else if !iterating {
 								ModRoot()
@@ -172,7 +173,7 @@ func ImportPathsQuiet(patterns []string, tags map[string]bool) []*search.Match {
 				if iterating {
 					// Enumerate the packages in the main module.
 					// We'll load the dependencies as we find them.
-					m.Pkgs = matchPackages("...", loaded.tags, false, []module.Version{Target})
+					m.Pkgs = matchPackages("...", loaded.tags, false, mainModules)
 				} else {
 					// Starting with the packages in the main module,
 					// enumerate the full list of "all".
@@ -241,7 +242,10 @@ func checkMultiplePaths() {
 // pathInModuleCache returns the import path of the directory dir,
 // if dir is in the module cache copy of a module in our build list.
 func pathInModuleCache(dir string) string {
-	for _, m := range buildList[1:] {
+	for _, m := range buildList {
+		if isMainModule(m) {
+			continue
+		}
 		var root string
 		var err error
 		if repl := Replacement(m); repl.Path != "" && repl.Version == "" {
@@ -327,17 +331,18 @@ func DirImportPath(dir string) string {
 		dir = filepath.Clean(dir)
 	}
 
-	if dir == modRoot {
-		return targetPrefix
+	m, root, ok := mainModuleForDir(dir)
+	if !ok {
+		return "."
 	}
-	if strings.HasPrefix(dir, modRoot+string(filepath.Separator)) {
-		suffix := filepath.ToSlash(dir[len(modRoot):])
-		if strings.HasPrefix(suffix, "/vendor/") {
-			return strings.TrimPrefix(suffix, "/vendor/")
-		}
-		return targetPrefix + suffix
+	if dir == root {
+		return mainModulePrefix(m)
+	}
+	suffix := filepath.ToSlash(dir[len(root):])
+	if strings.HasPrefix(suffix, "/vendor/") {
+		return strings.TrimPrefix(suffix, "/vendor/")
 	}
-	return "."
+	return mainModulePrefix(m) + suffix
 }
 
 // LoadBuildList loads and returns the build list from go.mod.
@@ -402,7 +407,7 @@ func loadAll(testAll bool) []string {
 	return paths
 }
 
-// TargetPackages returns the list of packages in the target (top-level) module
+// TargetPackages returns the list of packages in the target (top-level) modules
 // matching pattern, which may be relative to the working directory, under all
 // build tag settings.
 func TargetPackages(pattern string) []string {
@@ -410,7 +415,7 @@ func TargetPackages(pattern string) []string {
 	// module is a thing that can contain packages.
 	ModRoot()
 
-	return matchPackages(pattern, imports.AnyTags(), false, []module.Version{Target})
+	return matchPackages(pattern, imports.AnyTags(), false, mainModules)
 }
 
 // BuildList returns the module build list,
@@ -626,7 +631,7 @@ var errMissing = errors.New("cannot find package")
 func (ld *loader) load(roots func() []string) {
 	var err error
 	reqs := Reqs()
-	buildList, err = mvs.BuildList(Target, reqs)
+	buildList, err = mvs.BuildList(mainModules, reqs)
 	if err != nil {
 		base.Fatalf("go: %v", err)
 	}
@@ -677,7 +682,7 @@ func (ld *loader) load(roots func() []string) {
 
 		// Recompute buildList with all our additions.
 		reqs = Reqs()
-		buildList, err = mvs.BuildList(Target, reqs)
+		buildList, err = mvs.BuildList(mainModules, reqs)
 		if err != nil {
 			// If an error was found in a newly added module, report the package
 			// import stack instead of the module requirement stack. Packages
@@ -695,7 +700,7 @@ func (ld *loader) load(roots func() []string) {
 	// Compute directly referenced dependency modules.
 	ld.direct = make(map[string]bool)
 	for _, pkg := range ld.pkgs {
-		if pkg.mod == Target {
+		if isMainModule(pkg.mod) {
 			for _, dep := range pkg.imports {
 				if dep.mod.Path != "" {
 					ld.direct[dep.mod.Path] = true
@@ -714,10 +719,16 @@ func (ld *loader) load(roots func() []string) {
 	// Mix in direct markings (really, lack of indirect markings)
 	// from go.mod, unless we scanned the whole module
 	// and can therefore be sure we know better than go.mod.
-	if !ld.isALL && modFile != nil {
-		for _, r := range modFile.Require {
-			if !r.Indirect {
-				ld.direct[r.Mod.Path] = true
+	if !ld.isALL {
+		for _, m := range mainModules {
+			f := mainModFile(m)
+			if f == nil {
+				continue
+			}
+			for _, r := range f.Require {
+				if !r.Indirect {
+					ld.direct[r.Mod.Path] = true
+				}
 			}
 		}
 	}
@@ -993,10 +1004,20 @@ func WhyDepth(path string) int {
 	return n
 }
 
-// Replacement returns the replacement for mod, if any, from go.mod.
+// Replacement returns the replacement for mod, if any, from go.mod
+// (or, in workspace mode, from go.work and the go.mod files of the main modules).
 // If there is no replacement for mod, Replacement returns
 // a module.Version with Path == "".
 func Replacement(mod module.Version) module.Version {
+	if workReplace != nil {
+		if r, ok := workReplace[mod]; ok {
+			return r
+		}
+		if r, ok := workReplace[module.Version{Path: mod.Path}]; ok {
+			return r
+		}
+		return module.Version{}
+	}
 	if index != nil {
 		if r, ok := index.replace[mod]; ok {
 			return r
@@ -1038,6 +1059,14 @@ func (r *mvsReqs) Required(mod module.Version) ([]module.Version, error) {
 			return cached{nil, err}
 		}
 		for i, mv := range list {
+			if workFilePath != "" {
+				// Any requirement on a module in the workspace
+				// is satisfied by that module itself.
+				if m, ok := mainModuleForPath(mv.Path); ok {
+					list[i] = m
+					continue
+				}
+			}
 			if index != nil {
 				for index.exclude[mv] {
 					mv1, err := r.next(mv)
@@ -1177,6 +1206,14 @@ func (r *mvsReqs) modFileToList(f *modfile.File) []module.Version {
 
 // required returns a unique copy of the requirements of mod.
 func (r *mvsReqs) required(mod module.Version) ([]module.Version, error) {
+	if f, ok := mainModFiles[mod]; ok {
+		// In workspace mode, the requirements of each main module
+		// come directly from its go.mod file.
+		if f.Go != nil {
+			r.versions.LoadOrStore(mod, f.Go.Version)
+		}
+		return r.modFileToList(f), nil
+	}
 	if mod == Target {
 		if modFile != nil && modFile.Go != nil {
 			r.versions.LoadOrStore(mod, modFile.Go.Version)
@@ -1311,8 +1348,8 @@ func (*mvsReqs) next(m module.Version) (module.Version, error) {
 // The isLocal return value reports whether the replacement,
 // if any, is local to the filesystem.
 func fetch(mod module.Version) (dir string, isLocal bool, err error) {
-	if mod == Target {
-		return ModRoot(), true, nil
+	if isMainModule(mod) {
+		return mainModuleRoot(mod), true, nil
 	}
 	if r := Replacement(mod); r.Path != "" {
 		if r.Version == "" {
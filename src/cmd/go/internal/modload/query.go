@@ -79,7 +79,10 @@ func queryProxy(proxy, path, query, current string, allowed func(module.Version)
 	if current != "" && !semver.IsValid(current) {
 		return nil, fmt.Errorf("invalid previous version %q", current)
 	}
-	if cfg.BuildMod != "" && cfg.BuildMod != "mod" {
+	if cfg.BuildMod != "" && cfg.BuildMod != "mod" && workFilePath == "" {
+		// In workspace mode, -mod=readonly only keeps the go.mod files of the
+		// workspace modules from being updated. Queries that look up modules
+		// without adding them to the build list are still allowed.
 		return nil, errQueryDisabled
 	}
 	if allowed == nil {
@@ -220,14 +223,14 @@ func queryProxy(proxy, path, query, current string, allowed func(module.Version)
 		return info, nil
 	}
 
-	if path == Target.Path {
+	if m, ok := mainModuleForPath(path); ok {
 		if query != "latest" {
 			return nil, fmt.Errorf("can't query specific version (%q) for the main module (%s)", query, path)
 		}
-		if !allowed(Target) {
+		if !allowed(m) {
 			return nil, fmt.Errorf("internal error: main module version is not allowed")
 		}
-		return &modfetch.RevInfo{Version: Target.Version}, nil
+		return &modfetch.RevInfo{Version: m.Version}, nil
 	}
 
 	if str.HasPathPrefix(path, "std") || str.HasPathPrefix(path, "cmd") {
@@ -412,8 +415,8 @@ func QueryPattern(pattern, query string, allowed func(module.Version) bool) ([]Q
 	} else {
 		match = func(m module.Version, root string, isLocal bool) []string {
 			prefix := m.Path
-			if m == Target {
-				prefix = targetPrefix
+			if isMainModule(m) {
+				prefix = mainModulePrefix(m)
 			}
 			if _, ok := dirInModule(pattern, prefix, root, isLocal); ok {
 				return []string{pattern}
@@ -424,17 +427,20 @@ func QueryPattern(pattern, query string, allowed func(module.Version) bool) ([]Q
 	}
 
 	if HasModRoot() {
-		pkgs := match(Target, modRoot, true)
-		if len(pkgs) > 0 {
+		for _, m := range mainModules {
+			pkgs := match(m, mainModuleRoot(m), true)
+			if len(pkgs) == 0 {
+				continue
+			}
 			if query != "latest" {
-				return nil, fmt.Errorf("can't query specific version for package %s in the main module (%s)", pattern, Target.Path)
+				return nil, fmt.Errorf("can't query specific version for package %s in the main module (%s)", pattern, m.Path)
 			}
-			if !allowed(Target) {
-				return nil, fmt.Errorf("internal error: package %s is in the main module (%s), but version is not allowed", pattern, Target.Path)
+			if !allowed(m) {
+				return nil, fmt.Errorf("internal error: package %s is in the main module (%s), but version is not allowed", pattern, m.Path)
 			}
 			return []QueryResult{{
-				Mod:      Target,
-				Rev:      &modfetch.RevInfo{Version: Target.Version},
+				Mod:      m,
+				Rev:      &modfetch.RevInfo{Version: m.Version},
 				Packages: pkgs,
 			}}, nil
 		}
@@ -481,13 +487,13 @@ func QueryPattern(pattern, query string, allowed func(module.Version) bool) ([]Q
 }
 
 // modulePrefixesExcludingTarget returns all prefixes of path that may plausibly
-// exist as a module, excluding targetPrefix but otherwise including path
-// itself, sorted by descending length.
+// exist as a module, excluding targetPrefix and the paths of any other main
+// modules but otherwise including path itself, sorted by descending length.
 func modulePrefixesExcludingTarget(path string) []string {
 	prefixes := make([]string, 0, strings.Count(path, "/")+1)
 
 	for {
-		if path != targetPrefix {
+		if _, isMain := mainModuleForPath(path); path != targetPrefix && !isMain {
 			if _, _, ok := module.SplitPathVersion(path); ok {
 				prefixes = append(prefixes, path)
 			}
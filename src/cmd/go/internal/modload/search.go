@@ -132,12 +132,12 @@ func matchPackages(pattern string, tags map[string]bool, useStd bool, modules []
 			root, modPrefix string
 			isLocal         bool
 		)
-		if mod == Target {
+		if isMainModule(mod) {
 			if !HasModRoot() {
 				continue // If there is no main module, we can't search in it.
 			}
-			root = ModRoot()
-			modPrefix = targetPrefix
+			root = mainModuleRoot(mod)
+			modPrefix = mainModulePrefix(mod)
 			isLocal = true
 		} else {
 			var err error
@@ -0,0 +1,219 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package modload
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"cmd/go/internal/lockedfile"
+
+	"golang.org/x/mod/modfile"
+)
+
+// A WorkFile is the parsed, interpreted form of a go.work file.
+//
+// A go.work file uses the same syntax as a go.mod file. It may contain a
+// go directive, use directives naming the directories of the modules in
+// the workspace, and replace directives, which apply to every module in
+// the workspace:
+//
+//	go 1.14
+//
+//	use (
+//		./hello
+//		./example
+//	)
+//
+//	replace golang.org/x/net => ./net
+type WorkFile struct {
+	Go      string              // version from the go directive, if any
+	Use     []string            // module directories, as written in the file
+	Replace []*modfile.Replace  // replace directives
+	Syntax  *modfile.FileSyntax // syntax tree, for editing and formatting
+}
+
+// ReadWorkFile reads and parses the go.work file at path.
+func ReadWorkFile(path string) (*WorkFile, error) {
+	data, err := lockedfile.Read(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseWorkFile(path, data)
+}
+
+// WriteWorkFile formats f and writes it to the go.work file at path.
+func WriteWorkFile(path string, f *WorkFile) error {
+	return lockedfile.Write(path, bytes.NewReader(f.Format()), 0666)
+}
+
+// ParseWorkFile parses the contents of the go.work file named file.
+func ParseWorkFile(file string, data []byte) (*WorkFile, error) {
+	lax, err := modfile.ParseLax(file, data, nil)
+	if err != nil {
+		return nil, err
+	}
+	wf := &WorkFile{Syntax: lax.Syntax}
+
+	// The go.mod parser does not know about use directives. Collect them
+	// here, then blank them out so that the remaining directives can be
+	// checked strictly while keeping their original positions.
+	var errs []string
+	masked := append([]byte(nil), data...)
+	mask := func(start, end int) {
+		for i := start; i < end && i < len(masked); i++ {
+			if masked[i] != '\n' {
+				masked[i] = ' '
+			}
+		}
+	}
+	addUse := func(line *modfile.Line, args []string) {
+		if len(args) != 1 {
+			errs = append(errs, fmt.Sprintf("%s:%d: usage: use local/dir", file, line.Start.Line))
+			return
+		}
+		dir, err := parseWorkString(args[0])
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s:%d: invalid quoted string: %v", file, line.Start.Line, err))
+			return
+		}
+		wf.Use = append(wf.Use, dir)
+	}
+	for _, stmt := range lax.Syntax.Stmt {
+		switch x := stmt.(type) {
+		case *modfile.Line:
+			if x.Token[0] == "use" {
+				addUse(x, x.Token[1:])
+				mask(x.Start.Byte, x.End.Byte)
+			}
+		case *modfile.LineBlock:
+			if len(x.Token) == 1 && x.Token[0] == "use" {
+				for _, l := range x.Line {
+					addUse(l, l.Token)
+				}
+				mask(x.Start.Byte, x.RParen.Pos.Byte+1)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.New(strings.Join(errs, "\n"))
+	}
+
+	f, err := modfile.Parse(file, masked, nil)
+	if err != nil {
+		return nil, err
+	}
+	if f.Module != nil {
+		return nil, fmt.Errorf("%s:%d: unknown directive: module", file, f.Module.Syntax.Start.Line)
+	}
+	if len(f.Require) > 0 {
+		return nil, fmt.Errorf("%s:%d: unknown directive: require", file, f.Require[0].Syntax.Start.Line)
+	}
+	if len(f.Exclude) > 0 {
+		return nil, fmt.Errorf("%s:%d: unknown directive: exclude", file, f.Exclude[0].Syntax.Start.Line)
+	}
+	if f.Go != nil {
+		wf.Go = f.Go.Version
+	}
+	wf.Replace = f.Replace
+	return wf, nil
+}
+
+func parseWorkString(s string) (string, error) {
+	if strings.HasPrefix(s, `"`) {
+		return strconv.Unquote(s)
+	}
+	return s, nil
+}
+
+// AddGoStmt sets the go directive of f to version.
+func (f *WorkFile) AddGoStmt(version string) {
+	f.Go = version
+	for _, stmt := range f.Syntax.Stmt {
+		if line, ok := stmt.(*modfile.Line); ok && line.Token[0] == "go" {
+			line.Token = []string{"go", version}
+			return
+		}
+	}
+	line := &modfile.Line{Token: []string{"go", version}}
+	f.Syntax.Stmt = append([]modfile.Expr{line}, f.Syntax.Stmt...)
+}
+
+// AddUse adds a use directive for the module directory dir,
+// unless f already has one.
+func (f *WorkFile) AddUse(dir string) {
+	for _, u := range f.Use {
+		if u == dir {
+			return
+		}
+	}
+	f.Use = append(f.Use, dir)
+
+	tok := modfile.AutoQuote(dir)
+	for _, stmt := range f.Syntax.Stmt {
+		if block, ok := stmt.(*modfile.LineBlock); ok && len(block.Token) == 1 && block.Token[0] == "use" {
+			block.Line = append(block.Line, &modfile.Line{Token: []string{tok}, InBlock: true})
+			return
+		}
+	}
+	for i, stmt := range f.Syntax.Stmt {
+		if line, ok := stmt.(*modfile.Line); ok && line.Token[0] == "use" {
+			// Turn the existing directive into a block holding both.
+			f.Syntax.Stmt[i] = &modfile.LineBlock{
+				Comments: line.Comments,
+				Token:    []string{"use"},
+				Line: []*modfile.Line{
+					{Token: line.Token[1:], InBlock: true},
+					{Token: []string{tok}, InBlock: true},
+				},
+			}
+			return
+		}
+	}
+	f.Syntax.Stmt = append(f.Syntax.Stmt, &modfile.Line{Token: []string{"use", tok}})
+}
+
+// DropUse removes any use directive for the module directory dir.
+func (f *WorkFile) DropUse(dir string) {
+	w := 0
+	for _, u := range f.Use {
+		if u != dir {
+			f.Use[w] = u
+			w++
+		}
+	}
+	f.Use = f.Use[:w]
+
+	matches := func(tok string) bool {
+		d, err := parseWorkString(tok)
+		return err == nil && d == dir
+	}
+	for _, stmt := range f.Syntax.Stmt {
+		switch x := stmt.(type) {
+		case *modfile.Line:
+			if len(x.Token) == 2 && x.Token[0] == "use" && matches(x.Token[1]) {
+				x.Token = nil
+			}
+		case *modfile.LineBlock:
+			if len(x.Token) == 1 && x.Token[0] == "use" {
+				for _, l := range x.Line {
+					if len(l.Token) == 1 && matches(l.Token[0]) {
+						l.Token = nil
+					}
+				}
+			}
+		}
+	}
+	f.Syntax.Cleanup()
+}
+
+// Format returns the formatted contents of f.
+func (f *WorkFile) Format() []byte {
+	f.Syntax.Cleanup()
+	return modfile.Format(f.Syntax)
+}
@@ -0,0 +1,100 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package modload
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var parseWorkFileTests = []struct {
+	in      string
+	use     []string
+	replace int
+	err     string
+}{
+	{
+		in:  "go 1.14\n\nuse ./a\n",
+		use: []string{"./a"},
+	},
+	{
+		in:  "go 1.14\n\nuse (\n\t./a\n\t\"./b c\"\n\t/abs/d\n)\n",
+		use: []string{"./a", "./b c", "/abs/d"},
+	},
+	{
+		in:      "use ./a\nreplace example.com/x => ./x\n",
+		use:     []string{"./a"},
+		replace: 1,
+	},
+	{
+		in:  "use ./a ./b\n",
+		err: "go.work:1: usage: use local/dir",
+	},
+	{
+		in:  "module example.com/m\n",
+		err: "go.work:1: unknown directive: module",
+	},
+	{
+		in:  "use ./a\nrequire example.com/x v1.0.0\n",
+		err: "go.work:2: unknown directive: require",
+	},
+	{
+		in:  "use ./a\nbogus x\n",
+		err: "go.work:2: unknown directive: bogus",
+	},
+}
+
+func TestParseWorkFile(t *testing.T) {
+	for _, tt := range parseWorkFileTests {
+		wf, err := ParseWorkFile("go.work", []byte(tt.in))
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("ParseWorkFile(%q): error %v, want %q", tt.in, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseWorkFile(%q): %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(wf.Use, tt.use) {
+			t.Errorf("ParseWorkFile(%q).Use = %q, want %q", tt.in, wf.Use, tt.use)
+		}
+		if len(wf.Replace) != tt.replace {
+			t.Errorf("ParseWorkFile(%q) has %d replacements, want %d", tt.in, len(wf.Replace), tt.replace)
+		}
+	}
+}
+
+func TestWorkFileEdit(t *testing.T) {
+	wf, err := ParseWorkFile("go.work", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wf.AddGoStmt("1.14")
+	wf.AddUse("./a")
+	if got, want := string(wf.Format()), "go 1.14\n\nuse ./a\n"; got != want {
+		t.Fatalf("after AddUse(./a):\n%s\nwant:\n%s", got, want)
+	}
+	wf.AddUse("./b")
+	wf.AddUse("./a")
+	if got, want := string(wf.Format()), "go 1.14\n\nuse (\n\t./a\n\t./b\n)\n"; got != want {
+		t.Fatalf("after AddUse(./b):\n%s\nwant:\n%s", got, want)
+	}
+	wf.DropUse("./a")
+	if got, want := string(wf.Format()), "go 1.14\n\nuse ./b\n"; got != want {
+		t.Fatalf("after DropUse(./a):\n%s\nwant:\n%s", got, want)
+	}
+
+	// The edited file must parse back to the same directories.
+	wf2, err := ParseWorkFile("go.work", wf.Format())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(wf2.Use, []string{"./b"}) {
+		t.Errorf("reparsed Use = %q, want [./b]", wf2.Use)
+	}
+}
@@ -114,13 +114,17 @@ func (e *BuildListError) Error() string {
 	return b.String()
 }
 
-// BuildList returns the build list for the target module.
-// The first element is the target itself, with the remainder of the list sorted by path.
-func BuildList(target module.Version, reqs Reqs) ([]module.Version, error) {
-	return buildList(target, reqs, nil)
+// BuildList returns the build list for the target modules.
+//
+// The first elements of the list are the targets themselves, in the order
+// given, with the remainder of the list sorted by path. Each target is
+// selected at its own version, regardless of the versions of its path
+// required by other modules in the graph.
+func BuildList(targets []module.Version, reqs Reqs) ([]module.Version, error) {
+	return buildList(targets, reqs, nil)
 }
 
-func buildList(target module.Version, reqs Reqs, upgrade func(module.Version) (module.Version, error)) ([]module.Version, error) {
+func buildList(targets []module.Version, reqs Reqs, upgrade func(module.Version) (module.Version, error)) ([]module.Version, error) {
 	// Explore work graph in parallel in case reqs.Required
 	// does high-latency network operations.
 	type modGraphNode struct {
@@ -140,8 +144,12 @@ func buildList(target module.Version, reqs Reqs, upgrade func(module.Version) (m
 		atomic.StoreInt32(&haveErr, 1)
 	}
 
+	isTarget := make(map[string]bool, len(targets))
 	var work par.Work
-	work.Add(target)
+	for _, target := range targets {
+		isTarget[target.Path] = true
+		work.Add(target)
+	}
 	work.Do(10, func(item interface{}) {
 		m := item.(module.Version)
 
@@ -182,7 +190,9 @@ func buildList(target module.Version, reqs Reqs, upgrade func(module.Version) (m
 		// neededBy[a] = b means a was added to the module graph by b.
 		neededBy := make(map[*modGraphNode]*modGraphNode)
 		q := make([]*modGraphNode, 0, len(modGraph))
-		q = append(q, modGraph[target])
+		for _, target := range targets {
+			q = append(q, modGraph[target])
+		}
 		for len(q) > 0 {
 			node := q[0]
 			q = q[1:]
@@ -219,18 +229,25 @@ func buildList(target module.Version, reqs Reqs, upgrade func(module.Version) (m
 
 	// The final list is the minimum version of each module found in the graph.
 
-	if v := min[target.Path]; v != target.Version {
-		// TODO(jayconrod): there is a special case in modload.mvsReqs.Max
-		// that prevents us from selecting a newer version of a module
-		// when the module has no version. This may only be the case for target.
-		// Should we always panic when target has a version?
-		// See golang.org/issue/31491, golang.org/issue/29773.
-		panic(fmt.Sprintf("mistake: chose version %q instead of target %+v", v, target)) // TODO: Don't panic.
+	for _, target := range targets {
+		if v := min[target.Path]; v != target.Version {
+			if len(targets) == 1 {
+				// TODO(jayconrod): there is a special case in modload.mvsReqs.Max
+				// that prevents us from selecting a newer version of a module
+				// when the module has no version. This may only be the case for target.
+				// Should we always panic when target has a version?
+				// See golang.org/issue/31491, golang.org/issue/29773.
+				panic(fmt.Sprintf("mistake: chose version %q instead of target %+v", v, target)) // TODO: Don't panic.
+			}
+			// Some other module in the graph requires a newer version of
+			// this target's path, but the target itself takes precedence.
+			min[target.Path] = target.Version
+		}
 	}
 
-	list := []module.Version{target}
+	list := append([]module.Version(nil), targets...)
 	for path, vers := range min {
-		if path != target.Path {
+		if !isTarget[path] {
 			list = append(list, module.Version{Path: path, Version: vers})
 		}
 
@@ -238,13 +255,13 @@ func buildList(target module.Version, reqs Reqs, upgrade func(module.Version) (m
 		required := n.required
 		for _, r := range required {
 			v := min[r.Path]
-			if r.Path != target.Path && reqs.Max(v, r.Version) != v {
+			if !isTarget[r.Path] && reqs.Max(v, r.Version) != v {
 				panic(fmt.Sprintf("mistake: version %q does not satisfy requirement %+v", v, r)) // TODO: Don't panic.
 			}
 		}
 	}
 
-	tail := list[1:]
+	tail := list[len(targets):]
 	sort.Slice(tail, func(i, j int) bool {
 		return tail[i].Path < tail[j].Path
 	})
@@ -255,7 +272,7 @@ func buildList(target module.Version, reqs Reqs, upgrade func(module.Version) (m
 // with the constraint that all module paths listed in base must
 // appear in the returned list.
 func Req(target module.Version, base []string, reqs Reqs) ([]module.Version, error) {
-	list, err := BuildList(target, reqs)
+	list, err := BuildList([]module.Version{target}, reqs)
 	if err != nil {
 		return nil, err
 	}
@@ -341,7 +358,7 @@ func Req(target module.Version, base []string, reqs Reqs) ([]module.Version, err
 // UpgradeAll returns a build list for the target module
 // in which every module is upgraded to its latest version.
 func UpgradeAll(target module.Version, reqs Reqs) ([]module.Version, error) {
-	return buildList(target, reqs, func(m module.Version) (module.Version, error) {
+	return buildList([]module.Version{target}, reqs, func(m module.Version) (module.Version, error) {
 		if m.Path == target.Path {
 			return target, nil
 		}
@@ -362,7 +379,7 @@ func Upgrade(target module.Version, reqs Reqs, upgrade ...module.Version) ([]mod
 	// to find which ones are the buggy ones.
 	list = append([]module.Version(nil), list...)
 	list = append(list, upgrade...)
-	return BuildList(target, &override{target, list, reqs})
+	return BuildList([]module.Version{target}, &override{target, list, reqs})
 }
 
 // Downgrade returns a build list for the target module
@@ -33,6 +33,27 @@ upgrade* A: A B1 C4 D5 E2 F1 G1
 upgrade A C4: A B1 C4 D4 E2 F1 G1
 downgrade A2 D2: A2 C4 D2
 
+# Several targets, as in a workspace. Each target is selected at its own
+# version, even when another module requires a different version of it.
+name: multi1
+A: B1 C1
+B: C2
+B1: D1
+C1:
+C2:
+D1:
+build A B: A B C2 D1
+build B A: B A C2 D1
+
+name: multi2
+A: B2
+B: C1
+B2: C2
+C1:
+C2:
+build A: A B2 C2
+build A B: A B C2
+
 name: trim
 A: B1 C2
 B1: D3
@@ -380,11 +401,11 @@ func Test(t *testing.T) {
 			name = val
 			continue
 		case "build":
-			if len(kf) != 2 {
-				t.Fatalf("build takes one argument: %q", line)
+			if len(kf) < 2 {
+				t.Fatalf("build takes at least one argument: %q", line)
 			}
 			fns = append(fns, func(t *testing.T) {
-				list, err := BuildList(m(kf[1]), reqs)
+				list, err := BuildList(ms(kf[1:]), reqs)
 				checkList(t, key, list, err, val)
 			})
 			continue
@@ -27,6 +27,12 @@ Typically the package is specified as a list of .go source files from a single d
 but it may also be an import path, file system path, or pattern
 matching a single known package, as in 'go run .' or 'go run my/cmd'.
 
+If the package argument has a version suffix (like @latest or @v1.0.0),
+'go run' builds the program in module-aware mode, ignoring the go.mod file in
+the current directory or any parent directory, if there is one. This is useful
+for running programs without affecting the dependencies of the main module.
+The same constraints apply as for 'go install pkg@version'; see 'go help install'.
+
 By default, 'go run' runs the compiled binary directly: 'a.out arguments...'.
 If the -exec flag is given, 'go run' invokes the binary using xprog:
 	'xprog a.out arguments...'.
@@ -58,16 +64,35 @@ func printStderr(args ...interface{}) (int, error) {
 }
 
 func runRun(cmd *base.Command, args []string) {
-	work.BuildInit()
-	var b work.Builder
-	b.Init()
-	b.Print = printStderr
 	i := 0
 	for i < len(args) && strings.HasSuffix(args[i], ".go") {
 		i++
 	}
+	// A package named as path@version is loaded outside the current module,
+	// and PackagesOutsideModule initializes the build itself.
+	outsideModule := i == 0 && len(args) > 0 && work.HasVersionSuffix(args[:1])
+	if !outsideModule {
+		work.BuildInit()
+	}
+	var b work.Builder
+	b.Init()
+	b.Print = printStderr
 	var p *load.Package
-	if i > 0 {
+	if outsideModule {
+		pkgs := work.PackagesOutsideModule(args[:1])
+		if len(pkgs) == 0 {
+			base.Fatalf("go run: no main packages loaded from %s", args[0])
+		}
+		if len(pkgs) > 1 {
+			var names []string
+			for _, p := range pkgs {
+				names = append(names, p.ImportPath)
+			}
+			base.Fatalf("go run: pattern %s matches multiple packages:\n\t%s", args[0], strings.Join(names, "\n\t"))
+		}
+		p = pkgs[0]
+		i++
+	} else if i > 0 {
 		files := args[:i]
 		for _, file := range files {
 			if strings.HasSuffix(file, "_test.go") {
@@ -125,6 +125,35 @@ func SetModRoot(dir string) {
 	modRoot = dir
 }
 
+// modRoots holds the root directories of the modules in the workspace,
+// in workspace mode.
+var modRoots []string
+
+// SetWorkspaceModRoots records the root directories of the modules in
+// the workspace. MatchPackagesInFS descends into those directories even
+// though they contain go.mod files.
+func SetWorkspaceModRoots(dirs []string) {
+	modRoots = dirs
+}
+
+// isWorkspaceModRoot reports whether dir is the root directory
+// of one of the modules in the workspace.
+func isWorkspaceModRoot(dir string) bool {
+	if len(modRoots) == 0 {
+		return false
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	for _, root := range modRoots {
+		if abs == root {
+			return true
+		}
+	}
+	return false
+}
+
 // MatchPackagesInFS is like MatchPackages but is passed a pattern that
 // begins with an absolute path or "./" or "../". On Windows, the pattern may
 // use slash or backslash separators or a mix of both.
@@ -201,7 +230,7 @@ func MatchPackagesInFS(pattern string) *Match {
 
 		if !top && cfg.ModulesEnabled {
 			// Ignore other modules found in subdirectories.
-			if fi, err := os.Stat(filepath.Join(path, "go.mod")); err == nil && !fi.IsDir() {
+			if fi, err := os.Stat(filepath.Join(path, "go.mod")); err == nil && !fi.IsDir() && !isWorkspaceModRoot(path) {
 				return filepath.SkipDir
 			}
 		}
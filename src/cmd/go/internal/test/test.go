@@ -117,7 +117,7 @@ elapsed time in the summary line.
 The rule for a match in the cache is that the run involves the same
 test binary and the flags on the command line come entirely from a
 restricted set of 'cacheable' test flags, defined as -cpu, -list,
--parallel, -run, -short, and -v. If a run of go test has any test
+-parallel, -run, -shard, -short, and -v. If a run of go test has any test
 or non-test flags outside this set, the result is not cached. To
 disable test caching, use any test flag or argument other than the
 cacheable flags. The idiomatic way to disable test caching explicitly
@@ -239,6 +239,24 @@ control the execution of any test:
 	-failfast
 	    Do not start new tests after the first test failure.
 
+	-fuzz regexp
+	    Run the fuzz target matching the regular expression. When specified,
+	    the command line argument must match exactly one package, and regexp
+	    must match exactly one fuzz target within that package. After tests,
+	    benchmarks, seed corpora of other fuzz targets, and examples have
+	    completed, the matching target will be fuzzed. See the Fuzzing
+	    section of the testing package documentation for details.
+
+	-fuzzminimizetime t
+	    Run enough iterations of the fuzz function to take t when minimizing
+	    a failing input, specified as a time.Duration (for example,
+	    -fuzzminimizetime 30s). The default is 60s.
+
+	-fuzztime t
+	    Run enough iterations of the fuzz target to take t, specified as a
+	    time.Duration (for example, -fuzztime 1h30s). The default is to run
+	    forever.
+
 	-list regexp
 	    List tests, benchmarks, or examples matching the regular expression.
 	    No tests, benchmarks or examples will be run. This will only
@@ -263,12 +281,27 @@ control the execution of any test:
 	    of all tests matching X, even those without sub-tests matching Y,
 	    because it must run them to look for those sub-tests.
 
+	-shard i/n
+	    Run only the i'th of n shards of the top-level tests, examples and
+	    fuzz targets, where 0 <= i < n. A test is assigned to a shard based
+	    only on its name, so running each shard from 0 to n-1, for example
+	    on separate machines, runs every test exactly once. Benchmarks are
+	    not sharded. The selected shard is reported at the beginning of
+	    the test output.
+
 	-short
 	    Tell long-running tests to shorten their run time.
 	    It is off by default but set during all.bash so that installing
 	    the Go tree can run a sanity check but not spend time running
 	    exhaustive tests.
 
+	-shuffle off,on,N
+	    Randomize the execution order of tests and benchmarks.
+	    It is off by default. If -shuffle is set to on, then it will seed
+	    the randomizer using the system clock. If -shuffle is set to an
+	    integer N, then N will be used as the seed value. In both cases,
+	    the seed will be reported for reproducibility.
+
 	-timeout d
 	    If a test binary runs longer than duration d, panic.
 	    If d is 0, the timeout is disabled.
@@ -408,8 +441,8 @@ var HelpTestfunc = &base.Command{
 	UsageLine: "testfunc",
 	Short:     "testing functions",
 	Long: `
-The 'go test' command expects to find test, benchmark, and example functions
-in the "*_test.go" files corresponding to the package under test.
+The 'go test' command expects to find test, benchmark, fuzz, and example
+functions in the "*_test.go" files corresponding to the package under test.
 
 A test function is one named TestXxx (where Xxx does not start with a
 lower case letter) and should have the signature,
@@ -420,6 +453,10 @@ A benchmark function is one named BenchmarkXxx and should have the signature,
 
 	func BenchmarkXxx(b *testing.B) { ... }
 
+A fuzz target is one named FuzzXxx and should have the signature,
+
+	func FuzzXxx(f *testing.F) { ... }
+
 An example function is similar to a test function but, instead of using
 *testing.T to report success or failure, prints output to os.Stdout.
 If the last comment in the function starts with "Output:" then the output
@@ -459,7 +496,7 @@ Here is another example where the ordering of the output is ignored:
 
 The entire test file is presented as the example when it contains a single
 example function, at least one other function, type, variable, or constant
-declaration, and no test or benchmark functions.
+declaration, and no test, benchmark, or fuzz functions.
 
 See the documentation of the testing package for more information.
 `,
@@ -481,6 +518,7 @@ var (
 	testTimeout      string          // -timeout flag
 	testArgs         []string
 	testBench        bool
+	testFuzz         string // -fuzz flag
 	testList         bool
 	testShowPass     bool   // show passing output
 	testVetList      string // -vet flag
@@ -553,6 +591,9 @@ func runTest(cmd *base.Command, args []string) {
 	if testProfile != "" && len(pkgs) != 1 {
 		base.Fatalf("cannot use %s flag with multiple packages", testProfile)
 	}
+	if testFuzz != "" && len(pkgs) != 1 {
+		base.Fatalf("cannot use -fuzz flag with multiple packages")
+	}
 	initCoverProfile()
 	defer closeCoverProfile()
 
@@ -563,8 +604,10 @@ func runTest(cmd *base.Command, args []string) {
 	if dt, err := time.ParseDuration(testTimeout); err == nil && dt > 0 {
 		testActualTimeout = dt
 		testKillTimeout = testActualTimeout + 1*time.Minute
-	} else if err == nil && dt == 0 {
+	} else if err == nil && dt == 0 || testTimeout == "" && testFuzz != "" {
 		// An explicit zero disables the test timeout.
+		// Fuzzing runs until it finds a failure or -fuzztime expires,
+		// so it has no default timeout either.
 		// No timeout is passed to tests.
 		// Let it have one century (almost) before we kill it.
 		testActualTimeout = -1
@@ -719,6 +762,29 @@ func runTest(cmd *base.Command, args []string) {
 		}
 	}
 
+	if testFuzz != "" {
+		// Instrument the packages linked into the test binary so that the
+		// fuzzing engine can tell which inputs reach new code. Skip packages
+		// that the testing and internal/fuzz packages use concurrently with
+		// fuzzing: their coverage says nothing about the code under test.
+		var skipInstrumentation = map[string]bool{
+			"context":       true,
+			"internal/fuzz": true,
+			"reflect":       true,
+			"runtime":       true,
+			"sync":          true,
+			"sync/atomic":   true,
+			"syscall":       true,
+			"testing":       true,
+			"time":          true,
+		}
+		for _, p := range load.TestPackageList(pkgs) {
+			if !skipInstrumentation[p.ImportPath] {
+				p.Internal.FuzzInstrument = true
+			}
+		}
+	}
+
 	// Prepare build + run + print actions for all packages being tested.
 	for _, p := range pkgs {
 		// sync/atomic import is inserted by the cover tool. See #18486
@@ -1067,10 +1133,10 @@ func (c *runCache) builderRunTest(b *work.Builder, a *work.Action) error {
 	}
 
 	var buf bytes.Buffer
-	if len(pkgArgs) == 0 || testBench {
+	if len(pkgArgs) == 0 || testBench || testFuzz != "" {
 		// Stream test output (no buffering) when no package has
 		// been given on the command line (implicit current directory)
-		// or when benchmarking.
+		// or when benchmarking or fuzzing.
 		// No change to stdout.
 	} else {
 		// If we're only running a single package under test or if parallelism is
@@ -1122,7 +1188,13 @@ func (c *runCache) builderRunTest(b *work.Builder, a *work.Action) error {
 	if !c.disableCache && len(execCmd) == 0 {
 		testlogArg = []string{"-test.testlogfile=" + a.Objdir + "testlog.txt"}
 	}
-	args := str.StringList(execCmd, a.Deps[0].BuiltTarget(), testlogArg, testArgs)
+	fuzzArg := []string{}
+	if testFuzz != "" {
+		if dir := cache.DefaultDir(); dir != "off" {
+			fuzzArg = []string{"-test.fuzzcachedir=" + filepath.Join(dir, "fuzz", a.Package.ImportPath)}
+		}
+	}
+	args := str.StringList(execCmd, a.Deps[0].BuiltTarget(), testlogArg, fuzzArg, testArgs)
 
 	if testCoverProfile != "" {
 		// Write coverage to temporary profile, for merging later.
@@ -1275,6 +1347,7 @@ func (c *runCache) tryCacheWithID(b *work.Builder, a *work.Action, id string) bo
 			"-test.list",
 			"-test.parallel",
 			"-test.run",
+			"-test.shard",
 			"-test.short",
 			"-test.v":
 			// These are cacheable.
@@ -47,6 +47,9 @@ var testFlagDefn = []*cmdflag.Defn{
 	{Name: "cpu", PassToTest: true},
 	{Name: "cpuprofile", PassToTest: true},
 	{Name: "failfast", BoolVar: new(bool), PassToTest: true},
+	{Name: "fuzz", PassToTest: true},
+	{Name: "fuzzminimizetime", PassToTest: true},
+	{Name: "fuzztime", PassToTest: true},
 	{Name: "list", PassToTest: true},
 	{Name: "memprofile", PassToTest: true},
 	{Name: "memprofilerate", PassToTest: true},
@@ -55,7 +58,9 @@ var testFlagDefn = []*cmdflag.Defn{
 	{Name: "outputdir", PassToTest: true},
 	{Name: "parallel", PassToTest: true},
 	{Name: "run", PassToTest: true},
+	{Name: "shard", PassToTest: true},
 	{Name: "short", BoolVar: new(bool), PassToTest: true},
+	{Name: "shuffle", PassToTest: true},
 	{Name: "timeout", PassToTest: true},
 	{Name: "trace", PassToTest: true},
 	{Name: "v", BoolVar: &testV, PassToTest: true},
@@ -157,6 +162,8 @@ func testFlags(usage func(), args []string) (packageNames, passToTest []string)
 			case "bench":
 				// record that we saw the flag; don't care about the value
 				testBench = true
+			case "fuzz":
+				testFuzz = value
 			case "list":
 				testList = true
 			case "timeout":
@@ -10,6 +10,7 @@ import (
 	"go/build"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -112,6 +113,14 @@ and test commands:
 		directory, but it is not accessed. When -modfile is specified, an
 		alternate go.sum file is also used: its path is derived from the
 		-modfile flag by trimming the ".mod" extension and appending ".sum".
+	-pgo file
+		specify the file path of a profile for profile-guided optimization (PGO).
+		The special name "auto" lets the go command select a file named
+		"default.pgo" in the main package's directory if that file exists.
+		The special name "off" turns off PGO. The default is "auto".
+		With "auto", a profile is only used when building a single main
+		package. An explicit profile is used for all packages in the build.
+		See 'go doc cmd/compile' for the optimizations it enables.
 	-pkgdir dir
 		install and load all packages from dir instead of the usual locations.
 		For example, when building with a non-standard configuration,
@@ -257,6 +266,7 @@ func AddBuildFlags(cmd *base.Command, mask BuildFlagMask) {
 	cmd.Flag.StringVar(&cfg.BuildContext.InstallSuffix, "installsuffix", "", "")
 	cmd.Flag.Var(&load.BuildLdflags, "ldflags", "")
 	cmd.Flag.BoolVar(&cfg.BuildLinkshared, "linkshared", false, "")
+	cmd.Flag.StringVar(&cfg.BuildPGO, "pgo", "auto", "")
 	cmd.Flag.StringVar(&cfg.BuildPkgdir, "pkgdir", "", "")
 	cmd.Flag.BoolVar(&cfg.BuildRace, "race", false, "")
 	cmd.Flag.BoolVar(&cfg.BuildMSan, "msan", false, "")
@@ -446,6 +456,31 @@ When module-aware mode is disabled, other packages are installed in the
 directory $GOPATH/pkg/$GOOS_$GOARCH. When module-aware mode is enabled,
 other packages are built and cached but not installed.
 
+If the arguments have version suffixes (like @latest or @v1.0.0), "go install"
+builds packages in module-aware mode, ignoring the go.mod file in the current
+directory or any parent directory, if there is one. This is useful for
+installing executables without affecting the dependencies of the main module.
+To eliminate ambiguity about which module versions are used in the build, the
+arguments must satisfy the following constraints:
+
+- Arguments must be package paths or package patterns (with "..." wildcards).
+They must not be standard packages (like fmt), meta-patterns (std, cmd,
+all), or relative or absolute file paths.
+
+- All arguments must have the same version suffix. Different queries are not
+allowed, even if they refer to the same version.
+
+- All arguments must refer to packages in the same module at the same version.
+
+- No module is considered the "main" module. If the module containing
+packages named on the command line has a go.mod file, it must not contain
+directives (replace and exclude) that would cause it to be interpreted
+differently than if it were the main module. The go.mod file is never
+updated, and no go.sum file is written.
+
+- Packages named on the command line must be main packages. Packages matched
+only by a pattern are skipped if they are not main packages.
+
 The -i flag installs the dependencies of the named packages as well.
 
 For more about the build flags, see 'go help build'.
@@ -512,10 +547,122 @@ func libname(args []string, pkgs []*load.Package) (string, error) {
 }
 
 func runInstall(cmd *base.Command, args []string) {
+	if HasVersionSuffix(args) {
+		InstallPackages(args, PackagesOutsideModule(args))
+		return
+	}
 	BuildInit()
 	InstallPackages(args, load.PackagesForBuild(args))
 }
 
+// HasVersionSuffix reports whether any of args is a package path
+// with a version suffix, as in path@version.
+func HasVersionSuffix(args []string) bool {
+	for _, arg := range args {
+		if strings.Contains(arg, "@") && !build.IsLocalImport(arg) && !filepath.IsAbs(arg) {
+			return true
+		}
+	}
+	return false
+}
+
+// PackagesOutsideModule loads the main packages named by args for
+// 'go install' or 'go run'. Each argument must have the form path@version,
+// and all arguments must have the same version. The module providing the
+// first argument is resolved at that version and loaded as the only
+// requirement of an otherwise empty main module: any go.mod file in the
+// current directory or its parents is ignored, and nothing is written back.
+//
+// PackagesOutsideModule initializes the build, so callers must not call
+// BuildInit themselves.
+func PackagesOutsideModule(args []string) []*load.Package {
+	// Check that the arguments satisfy syntactic constraints.
+	var version string
+	for _, arg := range args {
+		if i := strings.Index(arg, "@"); i >= 0 {
+			version = arg[i+1:]
+			if version == "" {
+				base.Fatalf("go %s %s: version must not be empty", cfg.CmdName, arg)
+			}
+			break
+		}
+	}
+	patterns := make([]string, len(args))
+	for i, arg := range args {
+		if !strings.HasSuffix(arg, "@"+version) {
+			base.Errorf("go %s %s: all arguments must have the same version (@%s)", cfg.CmdName, arg, version)
+			continue
+		}
+		p := arg[:len(arg)-len(version)-1]
+		switch {
+		case build.IsLocalImport(p):
+			base.Errorf("go %s %s: argument must be a package path, not a relative path", cfg.CmdName, arg)
+		case filepath.IsAbs(p):
+			base.Errorf("go %s %s: argument must be a package path, not an absolute path", cfg.CmdName, arg)
+		case search.IsMetaPackage(p):
+			base.Errorf("go %s %s: argument must be a package path, not a meta-package", cfg.CmdName, arg)
+		case path.Clean(p) != p:
+			base.Errorf("go %s %s: argument must be a clean package path", cfg.CmdName, arg)
+		case search.IsStandardImportPath(p):
+			base.Errorf("go %s %s: argument must not be a package in the standard library", cfg.CmdName, arg)
+		default:
+			patterns[i] = p
+		}
+	}
+	base.ExitIfErrors()
+
+	installMod, err := load.ModInitOutsideModule(patterns[0], version)
+	if err != nil {
+		base.Fatalf("go %s %s: %v", cfg.CmdName, args[0], err)
+	}
+	BuildInit()
+
+	// Load packages for all arguments and keep only the main packages.
+	// Packages named literally must be main packages provided by installMod;
+	// packages matched only by a pattern are skipped otherwise.
+	matchers := make([]func(string) bool, len(patterns))
+	for i, p := range patterns {
+		if strings.Contains(p, "...") {
+			matchers[i] = search.MatchPattern(p)
+		}
+	}
+	pkgs := load.PackagesForBuild(patterns)
+	var mainPkgs []*load.Package
+	mainCount := make([]int, len(patterns))
+	for _, pkg := range pkgs {
+		literal := false
+		for i := range patterns {
+			if matchers[i] == nil && patterns[i] == pkg.ImportPath {
+				literal = true
+			}
+		}
+		switch {
+		case pkg.Module == nil || pkg.Module.Path != installMod.Path:
+			if literal {
+				base.Errorf("go %s: package %s is not provided by module %s", cfg.CmdName, pkg.ImportPath, installMod)
+			}
+		case pkg.Name != "main":
+			if literal {
+				base.Errorf("go %s: package %s is not a main package", cfg.CmdName, pkg.ImportPath)
+			}
+		default:
+			mainPkgs = append(mainPkgs, pkg)
+			for i := range patterns {
+				if matchers[i] != nil && matchers[i](pkg.ImportPath) {
+					mainCount[i]++
+				}
+			}
+		}
+	}
+	base.ExitIfErrors()
+	for i, p := range patterns {
+		if matchers[i] != nil && mainCount[i] == 0 {
+			fmt.Fprintf(os.Stderr, "go: warning: %q matched no main packages in %s\n", p, installMod)
+		}
+	}
+	return mainPkgs
+}
+
 // omitTestOnly returns pkgs with test-only packages removed.
 func omitTestOnly(pkgs []*load.Package) []*load.Package {
 	var list []*load.Package
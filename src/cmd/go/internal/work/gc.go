@@ -7,6 +7,7 @@ package work
 import (
 	"bufio"
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -79,7 +80,7 @@ func (gcToolchain) gc(b *Builder, a *Action, archive string, importcfg []byte, s
 	extFiles := len(p.CgoFiles) + len(p.CFiles) + len(p.CXXFiles) + len(p.MFiles) + len(p.FFiles) + len(p.SFiles) + len(p.SysoFiles) + len(p.SwigFiles) + len(p.SwigCXXFiles)
 	if p.Standard {
 		switch p.ImportPath {
-		case "bytes", "internal/poll", "net", "os", "runtime/pprof", "runtime/trace", "sync", "syscall", "time":
+		case "bytes", "internal/poll", "net", "os", "runtime/metrics", "runtime/pprof", "runtime/trace", "sync", "syscall", "time":
 			extFiles++
 		}
 	}
@@ -101,8 +102,14 @@ func (gcToolchain) gc(b *Builder, a *Action, archive string, importcfg []byte, s
 	if symabis != "" {
 		gcargs = append(gcargs, "-symabis", symabis)
 	}
+	if p.Internal.PGOProfile != "" {
+		gcargs = append(gcargs, "-pgoprofile", p.Internal.PGOProfile)
+	}
 
 	gcflags := str.StringList(forcedGcflags, p.Internal.Gcflags)
+	if p.Internal.FuzzInstrument {
+		gcflags = append(gcflags, "-d=fuzzcounters")
+	}
 	if compilingRuntime {
 		// Remove -N, if present.
 		// It is not possible to build the runtime with no optimizations,
@@ -123,6 +130,16 @@ func (gcToolchain) gc(b *Builder, a *Action, archive string, importcfg []byte, s
 		}
 		args = append(args, "-importcfg", objdir+"importcfg")
 	}
+	if p.Internal.Embed != nil {
+		embedcfg, err := embedConfig(p)
+		if err != nil {
+			return "", nil, err
+		}
+		if err := b.writeFile(objdir+"embedcfg", embedcfg); err != nil {
+			return "", nil, err
+		}
+		args = append(args, "-embedcfg", objdir+"embedcfg")
+	}
 	if ofile == archive {
 		args = append(args, "-pack")
 	}
@@ -485,6 +502,26 @@ func pluginPath(a *Action) string {
 	return fmt.Sprintf("plugin/unnamed-%x", h.Sum(nil))
 }
 
+// embedConfig returns the JSON configuration for the compiler's -embedcfg flag,
+// mapping the //go:embed patterns of p to the files they match
+// and those files to their locations on disk.
+func embedConfig(p *load.Package) ([]byte, error) {
+	var embed struct {
+		Patterns map[string][]string
+		Files    map[string]string
+	}
+	embed.Patterns = p.Internal.Embed
+	embed.Files = make(map[string]string)
+	for _, file := range p.EmbedFiles {
+		embed.Files[file] = filepath.Join(p.Dir, file)
+	}
+	js, err := json.MarshalIndent(&embed, "", "\t")
+	if err != nil {
+		return nil, fmt.Errorf("marshal embedcfg: %v", err)
+	}
+	return js, nil
+}
+
 func (gcToolchain) ld(b *Builder, root *Action, out, importcfg, mainpkg string) error {
 	cxx := len(root.Package.CXXFiles) > 0 || len(root.Package.SwigCXXFiles) > 0
 	for _, a := range root.Deps {
@@ -62,6 +62,9 @@ func checkGccgoBin() {
 
 func (tools gccgoToolchain) gc(b *Builder, a *Action, archive string, importcfg []byte, symabis string, asmhdr bool, gofiles []string) (ofile string, output []byte, err error) {
 	p := a.Package
+	if p.Internal.Embed != nil {
+		return "", nil, fmt.Errorf("%s: gccgo does not support //go:embed", p.ImportPath)
+	}
 	objdir := a.Objdir
 	out := "_go_.o"
 	ofile = objdir + out
@@ -0,0 +1,72 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// go work init
+
+package workcmd
+
+import (
+	"go/build"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cmd/go/internal/base"
+	"cmd/go/internal/modload"
+
+	"golang.org/x/mod/modfile"
+)
+
+var cmdInit = &base.Command{
+	UsageLine: "go work init [moddirs]",
+	Short:     "initialize workspace file",
+	Long: `
+Init initializes and writes a new go.work file in the current
+directory, in effect creating a new workspace there.
+The file go.work must not already exist.
+
+Init optionally accepts paths to the workspace modules as arguments.
+Each directory must contain a go.mod file, and is added to the
+go.work file with a use directive.
+
+See 'go help work' for more information about workspaces.
+	`,
+	Run: runInit,
+}
+
+func runInit(cmd *base.Command, args []string) {
+	if os.Getenv("GO111MODULE") == "off" {
+		base.Fatalf("go work init: modules disabled by GO111MODULE=off; see 'go help modules'")
+	}
+	workFile := filepath.Join(base.Cwd, "go.work")
+	if _, err := os.Stat(workFile); err == nil {
+		base.Fatalf("go work init: %s already exists", base.ShortPath(workFile))
+	}
+
+	wf, err := modload.ParseWorkFile(workFile, nil)
+	if err != nil {
+		base.Fatalf("go work init: %v", err)
+	}
+	wf.AddGoStmt(defaultGoVersion())
+	for _, dir := range args {
+		if !hasGoMod(dir) {
+			base.Fatalf("go work init: directory %s does not contain a go.mod file", dir)
+		}
+		wf.AddUse(useDir(base.Cwd, dir))
+	}
+	if err := modload.WriteWorkFile(workFile, wf); err != nil {
+		base.Fatalf("go work init: %v", err)
+	}
+}
+
+// defaultGoVersion returns the Go language version
+// to record in a new go.work file.
+func defaultGoVersion() string {
+	tags := build.Default.ReleaseTags
+	version := tags[len(tags)-1]
+	if !strings.HasPrefix(version, "go") || !modfile.GoVersionRE.MatchString(version[2:]) {
+		base.Fatalf("go: unrecognized default version %q", version)
+	}
+	return version[2:]
+}
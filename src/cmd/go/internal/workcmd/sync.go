@@ -0,0 +1,133 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// go work sync
+
+package workcmd
+
+import (
+	"path/filepath"
+
+	"cmd/go/internal/base"
+	"cmd/go/internal/lockedfile"
+	"cmd/go/internal/modload"
+	"cmd/go/internal/mvs"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+var cmdSync = &base.Command{
+	UsageLine: "go work sync",
+	Short:     "sync workspace build list to modules",
+	Long: `
+Sync syncs the workspace's build list back to the workspace's modules.
+
+The workspace's build list is the set of versions of all the
+(transitive) dependency modules used to do builds in the workspace.
+It is computed by the minimal version selection algorithm from the
+requirements of all the workspace modules together.
+
+Sync then updates the go.mod file of each workspace module: for every
+module that the workspace module depends on, directly or indirectly,
+the requirement is raised to the version in the workspace's build list
+if that version is higher than the one the module would select on
+its own. Dependencies that the module does not yet require directly
+are added as indirect requirements. Requirements are only ever
+upgraded, never downgraded, and requirements on other workspace
+modules are left unchanged.
+
+See 'go help work' for more information about workspaces.
+	`,
+	Run: runSync,
+}
+
+func runSync(cmd *base.Command, args []string) {
+	if len(args) != 0 {
+		base.Fatalf("go work sync: sync takes no arguments")
+	}
+	if modload.WorkFilePath() == "" {
+		base.Fatalf("go work sync: no go.work file found\n\t(run 'go work init' first or specify path using GOWORK environment variable)")
+	}
+
+	selected := make(map[string]string)
+	for _, m := range modload.LoadBuildList() {
+		selected[m.Path] = m.Version
+	}
+	mainModules := modload.MainModules()
+	isMain := make(map[string]bool)
+	for _, m := range mainModules {
+		isMain[m.Path] = true
+	}
+
+	reqs := &moduleReqs{Reqs: modload.Reqs(), isMain: isMain}
+	for _, mm := range mainModules {
+		// Compute the build list mm would have on its own,
+		// ignoring the other workspace modules.
+		own, err := mvs.BuildList([]module.Version{mm}, reqs)
+		if err != nil {
+			base.Errorf("go work sync: %v", err)
+			continue
+		}
+
+		gomod := filepath.Join(modload.MainModuleDir(mm), "go.mod")
+		err = lockedfile.Transform(gomod, func(data []byte) ([]byte, error) {
+			f, err := modfile.Parse(gomod, data, nil)
+			if err != nil {
+				return nil, err
+			}
+			current := make(map[string]string)
+			for _, r := range f.Require {
+				current[r.Mod.Path] = r.Mod.Version
+			}
+			changed := false
+			for _, m := range own[1:] {
+				v := selected[m.Path]
+				if semver.Compare(v, m.Version) <= 0 {
+					continue
+				}
+				if _, ok := current[m.Path]; ok {
+					if err := f.AddRequire(m.Path, v); err != nil {
+						return nil, err
+					}
+				} else {
+					f.AddNewRequire(m.Path, v, true)
+				}
+				changed = true
+			}
+			if !changed {
+				return data, nil
+			}
+			f.SortBlocks()
+			f.Cleanup()
+			return f.Format()
+		})
+		if err != nil {
+			base.Errorf("go work sync: %v", err)
+		}
+	}
+	base.ExitIfErrors()
+}
+
+// moduleReqs is the module requirement graph of the workspace
+// with the requirements on workspace modules removed.
+type moduleReqs struct {
+	mvs.Reqs
+	isMain map[string]bool
+}
+
+func (r *moduleReqs) Required(m module.Version) ([]module.Version, error) {
+	required, err := r.Reqs.Required(m)
+	if err != nil {
+		return nil, err
+	}
+	var list []module.Version
+	for _, rm := range required {
+		if !r.isMain[rm.Path] {
+			list = append(list, rm)
+		}
+	}
+	return list, nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// go work use
+
+package workcmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cmd/go/internal/base"
+	"cmd/go/internal/modload"
+	"cmd/go/internal/search"
+)
+
+var cmdUse = &base.Command{
+	UsageLine: "go work use [-r] moddirs",
+	Short:     "add modules to workspace file",
+	Long: `
+Use provides a command-line interface for adding directories,
+optionally recursively, to a go.work file.
+
+A use directive is added to the go.work file for each argument
+directory that contains a go.mod file. Use directives for argument
+directories that do not contain a go.mod file are removed from the
+go.work file, so that 'go work use' can also be used to drop a module
+that has been deleted from the workspace.
+
+The -r flag searches recursively for modules in the argument
+directories, and the use command operates as if each of the
+directories were specified as arguments: directories containing a
+go.mod file are added, and existing use directives for directories
+below the arguments that no longer contain a go.mod file are removed.
+
+See 'go help work' for more information about workspaces.
+	`,
+}
+
+var useR bool
+
+func init() {
+	cmdUse.Run = runUse // break init cycle
+	cmdUse.Flag.BoolVar(&useR, "r", false, "")
+}
+
+func runUse(cmd *base.Command, args []string) {
+	if len(args) == 0 {
+		base.Fatalf("go work use: no directories specified")
+	}
+	workFile := modload.WorkFilePath()
+	if workFile == "" {
+		base.Fatalf("go work use: no go.work file found\n\t(run 'go work init' first or specify path using GOWORK environment variable)")
+	}
+	wf, err := modload.ReadWorkFile(workFile)
+	if err != nil {
+		base.Fatalf("go work use: %v", err)
+	}
+	workDir := filepath.Dir(workFile)
+
+	// haveDirs maps the absolute form of each directory
+	// already in the go.work file to the form used there.
+	haveDirs := make(map[string][]string)
+	for _, dir := range wf.Use {
+		abs := absDir(workDir, dir)
+		haveDirs[abs] = append(haveDirs[abs], dir)
+	}
+
+	keep := make(map[string]bool)
+	add := func(dir string) {
+		abs := absDir(base.Cwd, dir)
+		if !hasGoMod(abs) {
+			return
+		}
+		keep[abs] = true
+		if len(haveDirs[abs]) == 0 {
+			wf.AddUse(useDir(workDir, abs))
+			haveDirs[abs] = []string{useDir(workDir, abs)}
+		}
+	}
+	drop := func(abs string) {
+		for _, dir := range haveDirs[abs] {
+			wf.DropUse(dir)
+		}
+		delete(haveDirs, abs)
+	}
+
+	for _, dir := range args {
+		abs := absDir(base.Cwd, dir)
+		if !useR {
+			if hasGoMod(abs) {
+				add(abs)
+			} else {
+				drop(abs)
+			}
+			continue
+		}
+
+		fi, err := os.Stat(abs)
+		if err != nil || !fi.IsDir() {
+			// The directory is gone; drop it and anything below it.
+			for have := range haveDirs {
+				if search.InDir(have, abs) != "" {
+					drop(have)
+				}
+			}
+			continue
+		}
+		err = filepath.Walk(abs, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() {
+				return nil
+			}
+			if path != abs {
+				if elem := info.Name(); strings.HasPrefix(elem, ".") || strings.HasPrefix(elem, "_") || elem == "testdata" || elem == "vendor" {
+					return filepath.SkipDir
+				}
+			}
+			add(path)
+			return nil
+		})
+		if err != nil {
+			base.Errorf("go work use: %v", err)
+		}
+		for have := range haveDirs {
+			if search.InDir(have, abs) != "" && !keep[have] {
+				drop(have)
+			}
+		}
+	}
+	base.ExitIfErrors()
+
+	if err := modload.WriteWorkFile(workFile, wf); err != nil {
+		base.Fatalf("go work use: %v", err)
+	}
+}
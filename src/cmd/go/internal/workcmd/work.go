@@ -0,0 +1,99 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package workcmd implements the ``go work'' command.
+package workcmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cmd/go/internal/base"
+)
+
+var CmdWork = &base.Command{
+	UsageLine: "go work",
+	Short:     "workspace maintenance",
+	Long: `Go work provides access to operations on workspaces.
+
+A workspace is a set of modules, each in its own directory, that are
+developed together. The modules in a workspace are listed by the use
+directives of a go.work file:
+
+	go 1.14
+
+	use (
+		./hello
+		./example
+	)
+
+When the go command finds a go.work file in the current directory or
+one of its parents, it runs in workspace mode: every module listed in
+go.work is a main module, so that build, test, list, vet and the other
+build commands resolve imports of any of those modules to the listed
+directories rather than to a version in the module cache. This makes it
+possible to work on several interdependent modules at once without
+adding temporary replace directives to their go.mod files.
+
+A go.work file may also contain replace directives, using the same
+syntax as go.mod. They apply to every module in the workspace and take
+precedence over replacements of the same module in the go.mod files of
+the workspace modules.
+
+In workspace mode the go.mod files of the workspace modules are never
+updated, and the -mod flag may only be set to readonly. Checksums found
+in the go.sum files of the workspace modules are trusted, and any new
+checksums are recorded in a go.work.sum file next to go.work.
+
+The GOWORK environment variable overrides the search for go.work:
+it may name the go.work file to use, or be set to "off" to disable
+workspace mode.
+
+Note that support for workspaces is built into all the go commands,
+not just 'go work'. See 'go help modules' for an overview of module
+functionality.
+	`,
+
+	Commands: []*base.Command{
+		cmdInit,
+		cmdSync,
+		cmdUse,
+	},
+}
+
+// useDir returns the form of the module directory dir to record
+// in a use directive of the go.work file in workDir.
+// Directories inside workDir are recorded as relative paths
+// with forward slashes; others are recorded as absolute paths.
+func useDir(workDir, dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		base.Fatalf("go: %v", err)
+	}
+	rel, err := filepath.Rel(workDir, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(abs)
+	}
+	if rel == "." {
+		return "."
+	}
+	return "./" + filepath.ToSlash(rel)
+}
+
+// absDir returns the absolute form of the directory dir named in a use
+// directive of the go.work file in workDir.
+func absDir(workDir, dir string) string {
+	dir = filepath.FromSlash(dir)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(workDir, dir)
+	}
+	return filepath.Clean(dir)
+}
+
+// hasGoMod reports whether dir contains a go.mod file.
+func hasGoMod(dir string) bool {
+	fi, err := os.Stat(filepath.Join(dir, "go.mod"))
+	return err == nil && !fi.IsDir()
+}
@@ -1485,6 +1485,12 @@ func (ctxt *Link) dodata() {
 		}
 		sect.Length = uint64(datsize) - sect.Vaddr
 		checkdatsize(ctxt, datsize, sym.SLIBFUZZER_EXTRA_COUNTER)
+		if s := ctxt.Syms.ROLookup("internal/fuzz._counters", 0); s != nil {
+			s.Sect = sect
+		}
+		if s := ctxt.Syms.ROLookup("internal/fuzz._ecounters", 0); s != nil {
+			s.Sect = sect
+		}
 	}
 
 	if len(data[sym.STLSBSS]) > 0 {
@@ -2251,6 +2257,7 @@ func (ctxt *Link) address() []*sym.Segment {
 	var noptr *sym.Section
 	var bss *sym.Section
 	var noptrbss *sym.Section
+	var fuzzCounters *sym.Section
 	for i, s := range Segdata.Sections {
 		if (ctxt.IsELF || ctxt.HeadType == objabi.Haix) && s.Name == ".tbss" {
 			continue
@@ -2274,6 +2281,9 @@ func (ctxt *Link) address() []*sym.Segment {
 		if s.Name == ".noptrbss" {
 			noptrbss = s
 		}
+		if s.Name == "__libfuzzer_extra_counters" {
+			fuzzCounters = s
+		}
 	}
 
 	// Assign Segdata's Filelen omitting the BSS. We do this here
@@ -2388,6 +2398,18 @@ func (ctxt *Link) address() []*sym.Segment {
 	ctxt.xdefine("runtime.enoptrbss", sym.SNOPTRBSS, int64(noptrbss.Vaddr+noptrbss.Length))
 	ctxt.xdefine("runtime.end", sym.SBSS, int64(Segdata.Vaddr+Segdata.Length))
 
+	// The fuzzing engine of go test finds the coverage counters
+	// through internal/fuzz._counters and internal/fuzz._ecounters.
+	// Without instrumentation the range is empty.
+	if ctxt.Syms.ROLookup("internal/fuzz._counters", 0) != nil {
+		start, end := int64(noptrbss.Vaddr), int64(noptrbss.Vaddr)
+		if fuzzCounters != nil {
+			start, end = int64(fuzzCounters.Vaddr), int64(fuzzCounters.Vaddr+fuzzCounters.Length)
+		}
+		ctxt.xdefine("internal/fuzz._counters", sym.SNOPTRBSS, start)
+		ctxt.xdefine("internal/fuzz._ecounters", sym.SNOPTRBSS, end)
+	}
+
 	return order
 }
 
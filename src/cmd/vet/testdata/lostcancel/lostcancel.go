@@ -12,3 +12,8 @@ func _() {
 		_ = cancel
 	}
 } // ERROR "this return statement may be reached without using the cancel var defined on line 10"
+
+func _() {
+	ctx, _ := context.WithCancelCause(context.Background()) // ERROR "the cancel function returned by context.WithCancelCause should be called, not discarded, to avoid a context leak"
+	_, _ = context.WithTimeoutCause(ctx, 0, nil)            // ERROR "the cancel function returned by context.WithTimeoutCause should be called, not discarded, to avoid a context leak"
+}
@@ -246,6 +246,12 @@ func PrintfTests() {
 	Errorf(1, "%d", 3)    // OK
 	Errorf(1, "%d", "hi") // ERROR "Errorf format %d has arg \x22hi\x22 of wrong type string"
 
+	// Errorf may wrap several errors.
+	_ = fmt.Errorf("%w", os.ErrExist)                          // OK
+	_ = fmt.Errorf("%w: %w", os.ErrExist, os.ErrNotExist)      // OK
+	_ = fmt.Errorf("%[2]w %[1]w", os.ErrExist, os.ErrNotExist) // OK
+	fmt.Printf("%w", os.ErrExist)                              // ERROR "Printf call has error-wrapping directive %w"
+
 	// Multiple string arguments before variadic args
 	errorf("WARNING", "foobar")            // OK
 	errorf("INFO", "s=%s, n=%d", "foo", 1) // OK
@@ -0,0 +1,35 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package list
+
+import "iter"
+
+// All returns an iterator over the elements of l, from front to back.
+// Like the loop over Front and Next, the iterator permits removing
+// the element it has just yielded.
+func (l *List) All() iter.Seq[*Element] {
+	return func(yield func(*Element) bool) {
+		for e := l.Front(); e != nil; {
+			next := e.Next()
+			if !yield(e) {
+				return
+			}
+			e = next
+		}
+	}
+}
+
+// Backward returns an iterator over the elements of l, from back to front.
+func (l *List) Backward() iter.Seq[*Element] {
+	return func(yield func(*Element) bool) {
+		for e := l.Back(); e != nil; {
+			prev := e.Prev()
+			if !yield(e) {
+				return
+			}
+			e = prev
+		}
+	}
+}
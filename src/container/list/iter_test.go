@@ -0,0 +1,50 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package list
+
+import "testing"
+
+func TestAll(t *testing.T) {
+	l := New()
+	for i := 0; i < 5; i++ {
+		l.PushBack(i)
+	}
+	want := 0
+	for e := range l.All() {
+		if e.Value != want {
+			t.Fatalf("All yielded %v, want %v", e.Value, want)
+		}
+		if want == 2 {
+			l.Remove(e) // removing the current element is permitted
+		}
+		want++
+	}
+	if want != 5 {
+		t.Fatalf("All yielded %d elements, want 5", want)
+	}
+	checkList(t, l, []interface{}{0, 1, 3, 4})
+
+	want = 4
+	for e := range l.Backward() {
+		if e.Value != want {
+			t.Fatalf("Backward yielded %v, want %v", e.Value, want)
+		}
+		if want == 3 {
+			want = 1
+			continue
+		}
+		if want == 1 {
+			break
+		}
+		want--
+	}
+	if want != 1 {
+		t.Fatalf("Backward stopped at %v, want 1", want)
+	}
+
+	for range New().All() {
+		t.Fatal("All yielded an element of an empty list")
+	}
+}
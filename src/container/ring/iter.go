@@ -0,0 +1,45 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ring
+
+import "iter"
+
+// All returns an iterator over the values of the elements of the ring,
+// in forward order starting with r. The behavior of All is undefined if
+// the ring is changed during the iteration.
+func (r *Ring) All() iter.Seq[interface{}] {
+	return func(yield func(interface{}) bool) {
+		if r == nil {
+			return
+		}
+		if !yield(r.Value) {
+			return
+		}
+		for p := r.Next(); p != r; p = p.next {
+			if !yield(p.Value) {
+				return
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over the values of the elements of the
+// ring, in backward order starting with r. The behavior of Backward is
+// undefined if the ring is changed during the iteration.
+func (r *Ring) Backward() iter.Seq[interface{}] {
+	return func(yield func(interface{}) bool) {
+		if r == nil {
+			return
+		}
+		if !yield(r.Value) {
+			return
+		}
+		for p := r.Prev(); p != r; p = p.prev {
+			if !yield(p.Value) {
+				return
+			}
+		}
+	}
+}
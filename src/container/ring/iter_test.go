@@ -0,0 +1,39 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ring
+
+import "testing"
+
+func TestAll(t *testing.T) {
+	var r *Ring
+	for range r.All() {
+		t.Fatal("All yielded a value of a nil ring")
+	}
+
+	r = New(5)
+	for i := 0; i < 5; i++ {
+		r.Value = i
+		r = r.Next()
+	}
+
+	var got []interface{}
+	for v := range r.All() {
+		got = append(got, v)
+	}
+	if len(got) != 5 || got[0] != 0 || got[4] != 4 {
+		t.Errorf("All yielded %v, want [0 1 2 3 4]", got)
+	}
+
+	got = got[:0]
+	for v := range r.Backward() {
+		got = append(got, v)
+		if len(got) == 3 {
+			break
+		}
+	}
+	if len(got) != 3 || got[0] != 0 || got[1] != 4 || got[2] != 3 {
+		t.Errorf("Backward yielded %v, want [0 4 3]", got)
+	}
+}
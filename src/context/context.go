@@ -22,6 +22,12 @@
 // fires. The go vet tool checks that CancelFuncs are used on all
 // control-flow paths.
 //
+// The WithCancelCause function returns a CancelCauseFunc, which
+// takes an error and records it as the cancellation cause. Calling
+// Cause on the canceled context or any of its children retrieves
+// the cause. If no cause is specified, Cause(ctx) returns the same
+// value as ctx.Err().
+//
 // Programs that use Contexts should follow these rules to keep interfaces
 // consistent across packages and enable static analysis tools to check context
 // propagation:
@@ -232,7 +238,108 @@ type CancelFunc func()
 func WithCancel(parent Context) (ctx Context, cancel CancelFunc) {
 	c := newCancelCtx(parent)
 	propagateCancel(parent, &c)
-	return &c, func() { c.cancel(true, Canceled) }
+	return &c, func() { c.cancel(true, Canceled, nil) }
+}
+
+// A CancelCauseFunc behaves like a CancelFunc but additionally sets
+// the cancellation cause. This cause can be retrieved by calling
+// Cause on the canceled Context or on any of its derived Contexts.
+//
+// If the context has already been canceled, CancelCauseFunc does not
+// set the cause. For example, if child is derived from parent and
+// parent is canceled with cause1 before child is canceled with cause2,
+// then Cause(child) is cause1. If child is canceled with cause2 first,
+// Cause(child) is cause2 while Cause(parent) is whatever cause parent
+// is later canceled with.
+type CancelCauseFunc func(cause error)
+
+// WithCancelCause behaves like WithCancel but returns a
+// CancelCauseFunc instead of a CancelFunc. Calling cancel with a
+// non-nil error (the "cause") records that error in ctx; it can then
+// be retrieved using Cause(ctx). Calling cancel with nil sets the
+// cause to Canceled.
+//
+// Example use:
+//
+// 	ctx, cancel := context.WithCancelCause(parent)
+// 	cancel(myError)
+// 	ctx.Err() // returns context.Canceled
+// 	context.Cause(ctx) // returns myError
+func WithCancelCause(parent Context) (ctx Context, cancel CancelCauseFunc) {
+	c := newCancelCtx(parent)
+	propagateCancel(parent, &c)
+	return &c, func(cause error) { c.cancel(true, Canceled, cause) }
+}
+
+// Cause returns a non-nil error explaining why c was canceled. The
+// first cancellation of c or one of its parents sets the cause. If
+// that cancellation happened via a call to CancelCauseFunc(err), then
+// Cause returns err. Otherwise Cause(c) returns the same value as
+// c.Err(). Cause returns nil if c has not been canceled yet.
+func Cause(c Context) error {
+	if cc, ok := c.Value(&cancelCtxKey).(*cancelCtx); ok {
+		cc.mu.Lock()
+		defer cc.mu.Unlock()
+		return cc.cause
+	}
+	// There is no cancelCtx in c's chain, so c is either never
+	// canceled or a custom implementation; either way, Err is the
+	// best explanation available.
+	return c.Err()
+}
+
+// AfterFunc arranges to call f in its own goroutine after ctx is
+// done (canceled or timed out). If ctx is already done, AfterFunc
+// calls f immediately in its own goroutine.
+//
+// Multiple calls to AfterFunc on a context operate independently;
+// one does not replace another.
+//
+// Calling the returned stop function stops the association of ctx
+// with f. It returns true if the call stopped f from being run. If
+// stop returns false, either the context is done and f has been
+// started in its own goroutine; or f was already stopped. The stop
+// function does not wait for f to complete before returning. If the
+// caller needs to know whether f is completed, it must coordinate
+// with f explicitly.
+//
+// When ctx is derived from the contexts of this package, f is
+// registered with the parent directly and no goroutine is started
+// until ctx is done.
+func AfterFunc(ctx Context, f func()) (stop func() bool) {
+	a := &afterFuncCtx{
+		cancelCtx: newCancelCtx(ctx),
+		f:         f,
+	}
+	propagateCancel(ctx, a)
+	return func() bool {
+		stopped := false
+		a.once.Do(func() {
+			stopped = true
+		})
+		if stopped {
+			a.cancel(true, Canceled, nil)
+		}
+		return stopped
+	}
+}
+
+// An afterFuncCtx is the canceler registered by AfterFunc. Being
+// canceled starts f, unless stop was called first.
+type afterFuncCtx struct {
+	cancelCtx
+	once sync.Once // either starts running f or stops f from running
+	f    func()
+}
+
+func (a *afterFuncCtx) cancel(removeFromParent bool, err, cause error) {
+	a.cancelCtx.cancel(false, err, cause)
+	if removeFromParent {
+		removeChild(a.Context, a)
+	}
+	a.once.Do(func() {
+		go a.f()
+	})
 }
 
 // newCancelCtx returns an initialized cancelCtx.
@@ -253,7 +360,7 @@ func propagateCancel(parent Context, child canceler) {
 	select {
 	case <-done:
 		// parent is already canceled
-		child.cancel(false, parent.Err())
+		child.cancel(false, parent.Err(), Cause(parent))
 		return
 	default:
 	}
@@ -262,7 +369,7 @@ func propagateCancel(parent Context, child canceler) {
 		p.mu.Lock()
 		if p.err != nil {
 			// parent has already been canceled
-			child.cancel(false, p.err)
+			child.cancel(false, p.err, p.cause)
 		} else {
 			if p.children == nil {
 				p.children = make(map[canceler]struct{})
@@ -275,7 +382,7 @@ func propagateCancel(parent Context, child canceler) {
 		go func() {
 			select {
 			case <-parent.Done():
-				child.cancel(false, parent.Err())
+				child.cancel(false, parent.Err(), Cause(parent))
 			case <-child.Done():
 			}
 		}()
@@ -323,9 +430,9 @@ func removeChild(parent Context, child canceler) {
 }
 
 // A canceler is a context type that can be canceled directly. The
-// implementations are *cancelCtx and *timerCtx.
+// implementations are *cancelCtx, *timerCtx and *afterFuncCtx.
 type canceler interface {
-	cancel(removeFromParent bool, err error)
+	cancel(removeFromParent bool, err, cause error)
 	Done() <-chan struct{}
 }
 
@@ -345,6 +452,7 @@ type cancelCtx struct {
 	done     chan struct{}         // created lazily, closed by first cancel call
 	children map[canceler]struct{} // set to nil by the first cancel call
 	err      error                 // set to non-nil by the first cancel call
+	cause    error                 // set to non-nil by the first cancel call
 }
 
 func (c *cancelCtx) Value(key interface{}) interface{} {
@@ -388,16 +496,21 @@ func (c *cancelCtx) String() string {
 
 // cancel closes c.done, cancels each of c's children, and, if
 // removeFromParent is true, removes c from its parent's children.
-func (c *cancelCtx) cancel(removeFromParent bool, err error) {
+// cancel sets c.cause to cause if this is the first time c is canceled.
+func (c *cancelCtx) cancel(removeFromParent bool, err, cause error) {
 	if err == nil {
 		panic("context: internal error: missing cancel error")
 	}
+	if cause == nil {
+		cause = err
+	}
 	c.mu.Lock()
 	if c.err != nil {
 		c.mu.Unlock()
 		return // already canceled
 	}
 	c.err = err
+	c.cause = cause
 	if c.done == nil {
 		c.done = closedchan
 	} else {
@@ -405,7 +518,7 @@ func (c *cancelCtx) cancel(removeFromParent bool, err error) {
 	}
 	for child := range c.children {
 		// NOTE: acquiring the child's lock while holding parent's lock.
-		child.cancel(false, err)
+		child.cancel(false, err, cause)
 	}
 	c.children = nil
 	c.mu.Unlock()
@@ -425,6 +538,13 @@ func (c *cancelCtx) cancel(removeFromParent bool, err error) {
 // Canceling this context releases resources associated with it, so code should
 // call cancel as soon as the operations running in this Context complete.
 func WithDeadline(parent Context, d time.Time) (Context, CancelFunc) {
+	return WithDeadlineCause(parent, d, nil)
+}
+
+// WithDeadlineCause behaves like WithDeadline but also sets the cause
+// of the returned Context when the deadline is exceeded. The returned
+// CancelFunc does not set the cause.
+func WithDeadlineCause(parent Context, d time.Time, cause error) (Context, CancelFunc) {
 	if cur, ok := parent.Deadline(); ok && cur.Before(d) {
 		// The current deadline is already sooner than the new one.
 		return WithCancel(parent)
@@ -436,17 +556,17 @@ func WithDeadline(parent Context, d time.Time) (Context, CancelFunc) {
 	propagateCancel(parent, c)
 	dur := time.Until(d)
 	if dur <= 0 {
-		c.cancel(true, DeadlineExceeded) // deadline has already passed
-		return c, func() { c.cancel(false, Canceled) }
+		c.cancel(true, DeadlineExceeded, cause) // deadline has already passed
+		return c, func() { c.cancel(false, Canceled, nil) }
 	}
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	if c.err == nil {
 		c.timer = time.AfterFunc(dur, func() {
-			c.cancel(true, DeadlineExceeded)
+			c.cancel(true, DeadlineExceeded, cause)
 		})
 	}
-	return c, func() { c.cancel(true, Canceled) }
+	return c, func() { c.cancel(true, Canceled, nil) }
 }
 
 // A timerCtx carries a timer and a deadline. It embeds a cancelCtx to
@@ -469,8 +589,8 @@ func (c *timerCtx) String() string {
 		time.Until(c.deadline).String() + "])"
 }
 
-func (c *timerCtx) cancel(removeFromParent bool, err error) {
-	c.cancelCtx.cancel(false, err)
+func (c *timerCtx) cancel(removeFromParent bool, err, cause error) {
+	c.cancelCtx.cancel(false, err, cause)
 	if removeFromParent {
 		// Remove this timerCtx from its parent cancelCtx's children.
 		removeChild(c.cancelCtx.Context, c)
@@ -497,6 +617,53 @@ func WithTimeout(parent Context, timeout time.Duration) (Context, CancelFunc) {
 	return WithDeadline(parent, time.Now().Add(timeout))
 }
 
+// WithTimeoutCause behaves like WithTimeout but also sets the cause
+// of the returned Context when the timeout expires. The returned
+// CancelFunc does not set the cause.
+func WithTimeoutCause(parent Context, timeout time.Duration, cause error) (Context, CancelFunc) {
+	return WithDeadlineCause(parent, time.Now().Add(timeout), cause)
+}
+
+// WithoutCancel returns a copy of parent that is not canceled when
+// parent is canceled. The returned context returns no Deadline or
+// Err, and its Done channel is nil. Calling Cause on the returned
+// context returns nil. Values of parent remain visible.
+func WithoutCancel(parent Context) Context {
+	if parent == nil {
+		panic("cannot create context from nil parent")
+	}
+	return withoutCancelCtx{parent}
+}
+
+type withoutCancelCtx struct {
+	c Context
+}
+
+func (withoutCancelCtx) Deadline() (deadline time.Time, ok bool) {
+	return
+}
+
+func (withoutCancelCtx) Done() <-chan struct{} {
+	return nil
+}
+
+func (withoutCancelCtx) Err() error {
+	return nil
+}
+
+func (c withoutCancelCtx) Value(key interface{}) interface{} {
+	if key == &cancelCtxKey {
+		// Hide the parent's cancelCtx so that Cause reports nil
+		// and children do not attach to it.
+		return nil
+	}
+	return c.c.Value(key)
+}
+
+func (c withoutCancelCtx) String() string {
+	return contextName(c.c) + ".WithoutCancel"
+}
+
 // WithValue returns a copy of parent in which the value associated with key is
 // val.
 //
@@ -729,3 +729,314 @@ func XTestCustomContextGoroutines(t testingT) {
 	defer cancel7()
 	checkNoGoroutine()
 }
+
+func XTestCause(t testingT) {
+	var (
+		parentCause = fmt.Errorf("parentCause")
+		childCause  = fmt.Errorf("childCause")
+	)
+	for _, test := range []struct {
+		name  string
+		ctx   func() Context
+		err   error
+		cause error
+	}{
+		{
+			name:  "Background",
+			ctx:   Background,
+			err:   nil,
+			cause: nil,
+		},
+		{
+			name: "WithCancel",
+			ctx: func() Context {
+				ctx, cancel := WithCancel(Background())
+				cancel()
+				return ctx
+			},
+			err:   Canceled,
+			cause: Canceled,
+		},
+		{
+			name: "WithCancelCause",
+			ctx: func() Context {
+				ctx, cancel := WithCancelCause(Background())
+				cancel(parentCause)
+				return ctx
+			},
+			err:   Canceled,
+			cause: parentCause,
+		},
+		{
+			name: "WithCancelCause nil",
+			ctx: func() Context {
+				ctx, cancel := WithCancelCause(Background())
+				cancel(nil)
+				return ctx
+			},
+			err:   Canceled,
+			cause: Canceled,
+		},
+		{
+			name: "WithCancelCause parent canceled first",
+			ctx: func() Context {
+				ctx, cancelParent := WithCancelCause(Background())
+				ctx, cancelChild := WithCancelCause(ctx)
+				cancelParent(parentCause)
+				cancelChild(childCause)
+				return ctx
+			},
+			err:   Canceled,
+			cause: parentCause,
+		},
+		{
+			name: "WithCancelCause child canceled first",
+			ctx: func() Context {
+				ctx, cancelParent := WithCancelCause(Background())
+				ctx, cancelChild := WithCancelCause(ctx)
+				cancelChild(childCause)
+				cancelParent(parentCause)
+				return ctx
+			},
+			err:   Canceled,
+			cause: childCause,
+		},
+		{
+			name: "WithCancelCause through WithValue and custom context",
+			ctx: func() Context {
+				ctx, cancelParent := WithCancelCause(Background())
+				ctx = otherContext{WithValue(ctx, "key", "value")}
+				ctx, cancelChild := WithCancel(ctx)
+				defer cancelChild()
+				cancelParent(parentCause)
+				time.Sleep(10 * time.Millisecond) // let cancellation propagate
+				return ctx
+			},
+			err:   Canceled,
+			cause: parentCause,
+		},
+		{
+			name: "WithDeadlineCause",
+			ctx: func() Context {
+				ctx, cancel := WithDeadlineCause(Background(), time.Now().Add(-time.Second), parentCause)
+				defer cancel()
+				return ctx
+			},
+			err:   DeadlineExceeded,
+			cause: parentCause,
+		},
+		{
+			name: "WithTimeoutCause",
+			ctx: func() Context {
+				ctx, cancel := WithTimeoutCause(Background(), 0, parentCause)
+				defer cancel()
+				return ctx
+			},
+			err:   DeadlineExceeded,
+			cause: parentCause,
+		},
+		{
+			name: "WithTimeoutCause canceled",
+			ctx: func() Context {
+				ctx, cancel := WithTimeoutCause(Background(), time.Hour, parentCause)
+				cancel()
+				return ctx
+			},
+			err:   Canceled,
+			cause: Canceled,
+		},
+		{
+			name: "WithTimeout parent canceled with cause",
+			ctx: func() Context {
+				ctx, cancelParent := WithCancelCause(Background())
+				ctx, cancelChild := WithTimeout(ctx, time.Hour)
+				defer cancelChild()
+				cancelParent(parentCause)
+				return ctx
+			},
+			err:   Canceled,
+			cause: parentCause,
+		},
+		{
+			name: "WithoutCancel",
+			ctx: func() Context {
+				ctx, cancel := WithCancelCause(Background())
+				cancel(parentCause)
+				return WithoutCancel(ctx)
+			},
+			err:   nil,
+			cause: nil,
+		},
+	} {
+		ctx := test.ctx()
+		if got, want := ctx.Err(), test.err; want != got {
+			t.Errorf("%s: ctx.Err() = %v want %v", test.name, got, want)
+		}
+		if got, want := Cause(ctx), test.cause; want != got {
+			t.Errorf("%s: Cause(ctx) = %v want %v", test.name, got, want)
+		}
+	}
+}
+
+func XTestCauseRace(t testingT) {
+	cause := fmt.Errorf("cause")
+	ctx, cancel := WithCancelCause(Background())
+	go func() {
+		cancel(cause)
+	}()
+	for {
+		// Poll Cause, rather than waiting for Done, to test that
+		// access to the underlying cause is synchronized properly.
+		if err := Cause(ctx); err != nil {
+			if err != cause {
+				t.Errorf("Cause returned %v, want %v", err, cause)
+			}
+			break
+		}
+		runtime.Gosched()
+	}
+}
+
+func XTestWithoutCancel(t testingT) {
+	key, value := "key", "value"
+	ctx := WithValue(Background(), key, value)
+	ctx = WithoutCancel(ctx)
+	if d, ok := ctx.Deadline(); !d.IsZero() || ok != false {
+		t.Errorf("ctx.Deadline() = %v, %v want zero, false", d, ok)
+	}
+	if done := ctx.Done(); done != nil {
+		t.Errorf("ctx.Done() = %v want nil", done)
+	}
+	if err := ctx.Err(); err != nil {
+		t.Errorf("ctx.Err() = %v want nil", err)
+	}
+	if v := ctx.Value(key); v != value {
+		t.Errorf("ctx.Value(%q) = %q want %q", key, v, value)
+	}
+	if got, want := fmt.Sprint(ctx), "context.Background.WithValue(type string, val value).WithoutCancel"; got != want {
+		t.Errorf("ctx.String() = %q want %q", got, want)
+	}
+
+	parent, cancel := WithTimeout(ctx, time.Hour)
+	ctx = WithoutCancel(parent)
+	child, cancelChild := WithCancel(ctx)
+	defer cancelChild()
+	cancel()
+	if err := parent.Err(); err != Canceled {
+		t.Errorf("parent.Err() = %v want %v", err, Canceled)
+	}
+	if err := ctx.Err(); err != nil {
+		t.Errorf("WithoutCancel(parent).Err() = %v want nil", err)
+	}
+	if err := child.Err(); err != nil {
+		t.Errorf("child.Err() = %v want nil", err)
+	}
+	if v := child.Value(key); v != value {
+		t.Errorf("child.Value(%q) = %q want %q", key, v, value)
+	}
+}
+
+func XTestAfterFuncCalledAfterCancel(t testingT) {
+	ctx, cancel := WithCancel(Background())
+	donec := make(chan struct{})
+	stop := AfterFunc(ctx, func() {
+		close(donec)
+	})
+	select {
+	case <-donec:
+		t.Fatalf("AfterFunc called before context is done")
+	case <-time.After(10 * time.Millisecond):
+	}
+	cancel()
+	select {
+	case <-donec:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("AfterFunc not called after context is canceled")
+	}
+	if stop() {
+		t.Fatalf("stop() = true, want false")
+	}
+}
+
+func XTestAfterFuncCalledAfterTimeout(t testingT) {
+	ctx, cancel := WithTimeout(Background(), 5*time.Millisecond)
+	defer cancel()
+	donec := make(chan struct{})
+	AfterFunc(ctx, func() {
+		close(donec)
+	})
+	select {
+	case <-donec:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("AfterFunc not called after context is canceled")
+	}
+}
+
+func XTestAfterFuncCalledImmediately(t testingT) {
+	ctx, cancel := WithCancel(Background())
+	cancel()
+	donec := make(chan struct{})
+	AfterFunc(ctx, func() {
+		close(donec)
+	})
+	select {
+	case <-donec:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("AfterFunc not called for already-canceled context")
+	}
+}
+
+func XTestAfterFuncNotCalledAfterStop(t testingT) {
+	ctx, cancel := WithCancel(Background())
+	donec := make(chan struct{})
+	stop := AfterFunc(ctx, func() {
+		close(donec)
+	})
+	if !stop() {
+		t.Fatalf("stop() = false, want true")
+	}
+	cancel()
+	select {
+	case <-donec:
+		t.Fatalf("AfterFunc called after stop")
+	case <-time.After(10 * time.Millisecond):
+	}
+	if stop() {
+		t.Fatalf("stop() = true, want false")
+	}
+}
+
+// This test verifies that a stopped AfterFunc does not stay
+// registered as a child of its context.
+func XTestAfterFuncStopRemoves(t testingT) {
+	ctx, cancel := WithCancel(Background())
+	defer cancel()
+	stop := AfterFunc(ctx, func() {})
+	c := ctx.(*cancelCtx)
+	c.mu.Lock()
+	n := len(c.children)
+	c.mu.Unlock()
+	if n != 1 {
+		t.Fatalf("after AfterFunc: %d children, want 1", n)
+	}
+	stop()
+	c.mu.Lock()
+	n = len(c.children)
+	c.mu.Unlock()
+	if n != 0 {
+		t.Fatalf("after stop: %d children, want 0", n)
+	}
+}
+
+func XTestAfterFuncGoroutines(t testingT) {
+	ctx, cancel := WithCancel(Background())
+	defer cancel()
+	g := atomic.LoadInt32(&goroutines)
+	for i := 0; i < 10; i++ {
+		stop := AfterFunc(ctx, func() {})
+		defer stop()
+	}
+	if now := atomic.LoadInt32(&goroutines); now != g {
+		t.Fatalf("%d goroutines created, want 0", now-g)
+	}
+}
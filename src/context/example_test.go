@@ -6,6 +6,7 @@ package context_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 )
@@ -116,3 +117,25 @@ func ExampleWithValue() {
 	// found value: Go
 	// key not found: color
 }
+
+// This example uses AfterFunc to close a connection-like resource
+// when its context is canceled, and reports why it was canceled.
+func ExampleAfterFunc() {
+	errShutdown := errors.New("server shutting down")
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	closed := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		fmt.Println("closing:", context.Cause(ctx))
+		close(closed)
+	})
+	defer stop()
+
+	cancel(errShutdown)
+	<-closed
+	fmt.Println(ctx.Err())
+
+	// Output:
+	// closing: server shutting down
+	// context canceled
+}
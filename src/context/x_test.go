@@ -28,3 +28,12 @@ func TestWithCancelCanceledParent(t *testing.T)        { XTestWithCancelCanceled
 func TestWithValueChecksKey(t *testing.T)              { XTestWithValueChecksKey(t) }
 func TestDeadlineExceededSupportsTimeout(t *testing.T) { XTestDeadlineExceededSupportsTimeout(t) }
 func TestCustomContextGoroutines(t *testing.T)         { XTestCustomContextGoroutines(t) }
+func TestCause(t *testing.T)                           { XTestCause(t) }
+func TestCauseRace(t *testing.T)                       { XTestCauseRace(t) }
+func TestWithoutCancel(t *testing.T)                   { XTestWithoutCancel(t) }
+func TestAfterFuncCalledAfterCancel(t *testing.T)      { XTestAfterFuncCalledAfterCancel(t) }
+func TestAfterFuncCalledAfterTimeout(t *testing.T)     { XTestAfterFuncCalledAfterTimeout(t) }
+func TestAfterFuncCalledImmediately(t *testing.T)      { XTestAfterFuncCalledImmediately(t) }
+func TestAfterFuncNotCalledAfterStop(t *testing.T)     { XTestAfterFuncNotCalledAfterStop(t) }
+func TestAfterFuncStopRemoves(t *testing.T)            { XTestAfterFuncStopRemoves(t) }
+func TestAfterFuncGoroutines(t *testing.T)             { XTestAfterFuncGoroutines(t) }
@@ -0,0 +1,238 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ecdh_test
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"testing"
+)
+
+// Check that PublicKey and PrivateKey implement the interfaces documented in
+// crypto.PublicKey and crypto.PrivateKey.
+var _ interface {
+	Equal(x crypto.PublicKey) bool
+} = &ecdh.PublicKey{}
+var _ interface {
+	Public() crypto.PublicKey
+	Equal(x crypto.PrivateKey) bool
+} = &ecdh.PrivateKey{}
+
+var curves = []ecdh.Curve{ecdh.P256(), ecdh.P384(), ecdh.P521(), ecdh.X25519()}
+
+func TestECDH(t *testing.T) {
+	for _, curve := range curves {
+		t.Run(fmt.Sprint(curve), func(t *testing.T) {
+			aliceKey, err := curve.GenerateKey(rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			bobKey, err := curve.GenerateKey(rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			alicePubKey, err := curve.NewPublicKey(aliceKey.PublicKey().Bytes())
+			if err != nil {
+				t.Error(err)
+			}
+			if !alicePubKey.Equal(aliceKey.PublicKey()) {
+				t.Error("encoded and decoded public keys are different")
+			}
+			if !alicePubKey.Equal(aliceKey.Public()) {
+				t.Error("encoded and decoded public keys are different")
+			}
+
+			alicePrivKey, err := curve.NewPrivateKey(aliceKey.Bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !alicePrivKey.Equal(aliceKey) {
+				t.Error("encoded and decoded private keys are different")
+			}
+			if alicePrivKey.Equal(bobKey) || alicePubKey.Equal(bobKey.PublicKey()) {
+				t.Error("different keys are reported as equal")
+			}
+
+			bobSecret, err := bobKey.ECDH(aliceKey.PublicKey())
+			if err != nil {
+				t.Fatal(err)
+			}
+			aliceSecret, err := aliceKey.ECDH(bobKey.PublicKey())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(bobSecret, aliceSecret) {
+				t.Error("two ECDH computations came out different")
+			}
+		})
+	}
+}
+
+func TestX25519(t *testing.T) {
+	// Test vectors from RFC 7748, Section 6.1.
+	alice, _ := hex.DecodeString("77076d0a7318a57d3c16c17251b26645df4c2f87ebc0992ab177fba51db92c2a")
+	alicePub, _ := hex.DecodeString("8520f0098930a754748b7ddcb43ef75a0dbf3a0d26381af4eba4a98eaa9b4e6a")
+	bob, _ := hex.DecodeString("5dab087e624a8a4b79e17f8b83800ee66f3bb1292618b6fd1c2f8b27ff88e0eb")
+	bobPub, _ := hex.DecodeString("de9edb7d7b7dc1b4d35b61c2ece435373f8343c85b78674dadfc7e146f882b4f")
+	shared, _ := hex.DecodeString("4a5d9d5ba4ce2de1728e3bf480350f25e07e21c947d19e3376f09b3c1e161742")
+
+	aliceKey, err := ecdh.X25519().NewPrivateKey(alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := aliceKey.PublicKey().Bytes(); !bytes.Equal(got, alicePub) {
+		t.Errorf("public key = %x, want %x", got, alicePub)
+	}
+	bobKey, err := ecdh.X25519().NewPrivateKey(bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := bobKey.PublicKey().Bytes(); !bytes.Equal(got, bobPub) {
+		t.Errorf("public key = %x, want %x", got, bobPub)
+	}
+	secret, err := aliceKey.ECDH(bobKey.PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(secret, shared) {
+		t.Errorf("shared secret = %x, want %x", secret, shared)
+	}
+
+	// A low order point produces an all-zero output, which is rejected.
+	zeroKey, err := ecdh.X25519().NewPublicKey(make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := aliceKey.ECDH(zeroKey); err == nil {
+		t.Error("ECDH with a low order point succeeded")
+	}
+}
+
+// TestNISTCompat checks the NIST curves against crypto/elliptic.
+func TestNISTCompat(t *testing.T) {
+	for _, tt := range []struct {
+		curve    ecdh.Curve
+		elliptic elliptic.Curve
+	}{
+		{ecdh.P256(), elliptic.P256()},
+		{ecdh.P384(), elliptic.P384()},
+		{ecdh.P521(), elliptic.P521()},
+	} {
+		t.Run(fmt.Sprint(tt.curve), func(t *testing.T) {
+			for i := 0; i < 5; i++ {
+				key, err := tt.curve.GenerateKey(rand.Reader)
+				if err != nil {
+					t.Fatal(err)
+				}
+				x, y := tt.elliptic.ScalarBaseMult(key.Bytes())
+				if got, want := key.PublicKey().Bytes(), elliptic.Marshal(tt.elliptic, x, y); !bytes.Equal(got, want) {
+					t.Errorf("public key = %x, want %x", got, want)
+				}
+
+				peer, err := tt.curve.GenerateKey(rand.Reader)
+				if err != nil {
+					t.Fatal(err)
+				}
+				secret, err := key.ECDH(peer.PublicKey())
+				if err != nil {
+					t.Fatal(err)
+				}
+				sx, _ := tt.elliptic.ScalarMult(x, y, peer.Bytes())
+				want := sx.Bytes()
+				want = append(make([]byte, len(secret)-len(want)), want...)
+				if !bytes.Equal(secret, want) {
+					t.Errorf("shared secret = %x, want %x", secret, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNISTInvalidKeys(t *testing.T) {
+	for _, curve := range curves[:3] {
+		t.Run(fmt.Sprint(curve), func(t *testing.T) {
+			key, err := curve.GenerateKey(rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			size := len(key.Bytes())
+			ones := bytes.Repeat([]byte{0xff}, size)
+			for _, b := range [][]byte{nil, make([]byte, size), ones, key.Bytes()[1:]} {
+				if _, err := curve.NewPrivateKey(b); err == nil {
+					t.Errorf("NewPrivateKey(%x) succeeded", b)
+				}
+			}
+
+			pub := key.PublicKey().Bytes()
+			notOnCurve := append([]byte{}, pub...)
+			notOnCurve[len(notOnCurve)-1] ^= 1
+			compressed := append([]byte{2}, pub[1:1+size]...)
+			for _, b := range [][]byte{nil, {0}, pub[:len(pub)-1], notOnCurve, compressed} {
+				if _, err := curve.NewPublicKey(b); err == nil {
+					t.Errorf("NewPublicKey(%x) succeeded", b)
+				}
+			}
+		})
+	}
+}
+
+func TestMismatchedCurves(t *testing.T) {
+	a, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.ECDH(b.PublicKey()); err == nil {
+		t.Error("ECDH with mismatched curves succeeded")
+	}
+}
+
+type zr struct{}
+
+// Read replaces the contents of dst with zeros. It is safe for concurrent use.
+func (zr) Read(dst []byte) (n int, err error) {
+	for i := range dst {
+		dst[i] = 0
+	}
+	return len(dst), nil
+}
+
+var zeroReader = io.Reader(zr{})
+
+func TestGenerateKeyZeroReader(t *testing.T) {
+	for _, curve := range curves {
+		if _, err := curve.GenerateKey(zeroReader); err != nil {
+			t.Errorf("%v: GenerateKey with a zero reader failed: %v", curve, err)
+		}
+	}
+}
+
+func BenchmarkECDH(b *testing.B) {
+	for _, curve := range curves {
+		b.Run(fmt.Sprint(curve), func(b *testing.B) {
+			key, _ := curve.GenerateKey(rand.Reader)
+			peer, _ := curve.GenerateKey(rand.Reader)
+			peerPub := peer.PublicKey()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := key.ECDH(peerPub); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
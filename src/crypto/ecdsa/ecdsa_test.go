@@ -6,6 +6,7 @@ package ecdsa
 
 import (
 	"bufio"
+	"bytes"
 	"compress/bzip2"
 	"crypto/elliptic"
 	"crypto/rand"
@@ -365,3 +366,37 @@ func TestZeroHashSignature(t *testing.T) {
 		}
 	}
 }
+
+func TestECDHConversion(t *testing.T) {
+	for _, c := range []elliptic.Curve{elliptic.P256(), elliptic.P384(), elliptic.P521()} {
+		priv, err := GenerateKey(c, rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ecdhPriv, err := priv.ECDH()
+		if err != nil {
+			t.Fatalf("%s: ECDH private key conversion failed: %v", c.Params().Name, err)
+		}
+		ecdhPub, err := priv.PublicKey.ECDH()
+		if err != nil {
+			t.Fatalf("%s: ECDH public key conversion failed: %v", c.Params().Name, err)
+		}
+		if !ecdhPub.Equal(ecdhPriv.PublicKey()) {
+			t.Errorf("%s: converted public key does not match the converted private key", c.Params().Name)
+		}
+		if want := elliptic.Marshal(c, priv.X, priv.Y); !bytes.Equal(ecdhPub.Bytes(), want) {
+			t.Errorf("%s: converted public key = %x, want %x", c.Params().Name, ecdhPub.Bytes(), want)
+		}
+	}
+
+	priv, err := GenerateKey(elliptic.P224(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := priv.ECDH(); err == nil {
+		t.Error("P-224 private key conversion succeeded")
+	}
+	if _, err := priv.PublicKey.ECDH(); err == nil {
+		t.Error("P-224 public key conversion succeeded")
+	}
+}
@@ -0,0 +1,330 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package hpke implements the base mode of Hybrid Public Key Encryption,
+// as specified in RFC 9180, for the algorithms used by Encrypted Client
+// Hello in crypto/tls.
+package hpke
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"io"
+	"math/bits"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+// KEM, KDF, and AEAD identifiers from the IANA HPKE registry.
+const (
+	DHKEM_X25519_HKDF_SHA256 = 0x0020
+
+	KDF_HKDF_SHA256 = 0x0001
+
+	AEAD_AES_128_GCM      = 0x0001
+	AEAD_AES_256_GCM      = 0x0002
+	AEAD_ChaCha20Poly1305 = 0x0003
+)
+
+// SupportedKEMs, SupportedKDFs and SupportedAEADs report which
+// algorithms this package implements.
+var (
+	SupportedKEMs = map[uint16]bool{
+		DHKEM_X25519_HKDF_SHA256: true,
+	}
+	SupportedKDFs = map[uint16]bool{
+		KDF_HKDF_SHA256: true,
+	}
+	SupportedAEADs = map[uint16]bool{
+		AEAD_AES_128_GCM:      true,
+		AEAD_AES_256_GCM:      true,
+		AEAD_ChaCha20Poly1305: true,
+	}
+)
+
+// hkdfSuite implements the labeled HKDF functions of RFC 9180, Section 4,
+// for a particular suite_id.
+type hkdfSuite struct {
+	hash    func() hash.Hash
+	suiteID []byte
+}
+
+func (s hkdfSuite) labeledExtract(salt []byte, label string, ikm []byte) []byte {
+	labeledIKM := make([]byte, 0, 7+len(s.suiteID)+len(label)+len(ikm))
+	labeledIKM = append(labeledIKM, "HPKE-v1"...)
+	labeledIKM = append(labeledIKM, s.suiteID...)
+	labeledIKM = append(labeledIKM, label...)
+	labeledIKM = append(labeledIKM, ikm...)
+	return hkdf.Extract(s.hash, labeledIKM, salt)
+}
+
+func (s hkdfSuite) labeledExpand(prk []byte, label string, info []byte, length uint16) []byte {
+	labeledInfo := make([]byte, 0, 2+7+len(s.suiteID)+len(label)+len(info))
+	labeledInfo = append(labeledInfo, byte(length>>8), byte(length))
+	labeledInfo = append(labeledInfo, "HPKE-v1"...)
+	labeledInfo = append(labeledInfo, s.suiteID...)
+	labeledInfo = append(labeledInfo, label...)
+	labeledInfo = append(labeledInfo, info...)
+	out := make([]byte, length)
+	r := hkdf.Expand(s.hash, prk, labeledInfo)
+	if _, err := r.Read(out); err != nil {
+		panic("hpke: internal error: " + err.Error())
+	}
+	return out
+}
+
+// dhKEM implements DHKEM(X25519, HKDF-SHA256), RFC 9180, Section 4.1.
+type dhKEM struct {
+	hkdfSuite
+	nSecret uint16
+}
+
+var x25519KEM = dhKEM{
+	hkdfSuite: hkdfSuite{
+		hash:    sha256.New,
+		suiteID: []byte{'K', 'E', 'M', 0x00, 0x20},
+	},
+	nSecret: 32,
+}
+
+func (k dhKEM) extractAndExpand(dh, kemContext []byte) []byte {
+	eaePRK := k.labeledExtract(nil, "eae_prk", dh)
+	return k.labeledExpand(eaePRK, "shared_secret", kemContext, k.nSecret)
+}
+
+// deriveKeyPair implements DeriveKeyPair for X25519, RFC 9180, Section 7.1.3.
+func (k dhKEM) deriveKeyPair(ikm []byte) (priv []byte) {
+	dkpPRK := k.labeledExtract(nil, "dkp_prk", ikm)
+	return k.labeledExpand(dkpPRK, "sk", nil, 32)
+}
+
+func (k dhKEM) encap(pubRecipient []byte) (sharedSecret, encapPub []byte, err error) {
+	var privEph []byte
+	if testingOnlyGenerateKey != nil {
+		privEph, err = testingOnlyGenerateKey()
+	} else {
+		privEph, err = GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	dh, err := curve25519.X25519(privEph, pubRecipient)
+	if err != nil {
+		return nil, nil, err
+	}
+	encapPub, err = curve25519.X25519(privEph, curve25519.Basepoint)
+	if err != nil {
+		return nil, nil, err
+	}
+	kemContext := append(append([]byte{}, encapPub...), pubRecipient...)
+	return k.extractAndExpand(dh, kemContext), encapPub, nil
+}
+
+func (k dhKEM) decap(privRecipient, encapPub []byte) ([]byte, error) {
+	dh, err := curve25519.X25519(privRecipient, encapPub)
+	if err != nil {
+		return nil, err
+	}
+	pubRecipient, err := curve25519.X25519(privRecipient, curve25519.Basepoint)
+	if err != nil {
+		return nil, err
+	}
+	kemContext := append(append([]byte{}, encapPub...), pubRecipient...)
+	return k.extractAndExpand(dh, kemContext), nil
+}
+
+// testingOnlyGenerateKey, if not nil, replaces the generation of the
+// ephemeral key in SetupSender, to allow testing against known answers.
+var testingOnlyGenerateKey func() ([]byte, error)
+
+// GenerateKey returns a new X25519 private key read from rand.
+func GenerateKey(rand io.Reader) ([]byte, error) {
+	priv := make([]byte, 32)
+	if _, err := io.ReadFull(rand, priv); err != nil {
+		return nil, err
+	}
+	return priv, nil
+}
+
+// PublicKey returns the X25519 public key corresponding to priv.
+func PublicKey(priv []byte) ([]byte, error) {
+	return curve25519.X25519(priv, curve25519.Basepoint)
+}
+
+type context struct {
+	aead cipher.AEAD
+
+	sharedSecret []byte
+
+	suiteID []byte
+
+	key            []byte
+	baseNonce      []byte
+	exporterSecret []byte
+
+	seqNum uint64
+}
+
+// A Sender is the encryption context of the sender of HPKE messages.
+type Sender struct {
+	*context
+}
+
+// A Recipient is the decryption context of the recipient of HPKE messages.
+type Recipient struct {
+	*context
+}
+
+func newAEAD(id uint16, key []byte) (cipher.AEAD, error) {
+	switch id {
+	case AEAD_AES_128_GCM, AEAD_AES_256_GCM:
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	case AEAD_ChaCha20Poly1305:
+		return chacha20poly1305.New(key)
+	}
+	return nil, errors.New("hpke: unsupported AEAD id")
+}
+
+func aeadKeySize(id uint16) uint16 {
+	switch id {
+	case AEAD_AES_128_GCM:
+		return 16
+	case AEAD_AES_256_GCM, AEAD_ChaCha20Poly1305:
+		return 32
+	}
+	return 0
+}
+
+func newContext(sharedSecret []byte, kemID, kdfID, aeadID uint16, info []byte) (*context, error) {
+	if kdfID != KDF_HKDF_SHA256 {
+		return nil, errors.New("hpke: unsupported KDF id")
+	}
+	if !SupportedAEADs[aeadID] {
+		return nil, errors.New("hpke: unsupported AEAD id")
+	}
+
+	suiteID := make([]byte, 0, 10)
+	suiteID = append(suiteID, "HPKE"...)
+	suiteID = append(suiteID, byte(kemID>>8), byte(kemID))
+	suiteID = append(suiteID, byte(kdfID>>8), byte(kdfID))
+	suiteID = append(suiteID, byte(aeadID>>8), byte(aeadID))
+	kdf := hkdfSuite{hash: sha256.New, suiteID: suiteID}
+
+	// Only the base mode is implemented, so psk and psk_id are empty.
+	pskIDHash := kdf.labeledExtract(nil, "psk_id_hash", nil)
+	infoHash := kdf.labeledExtract(nil, "info_hash", info)
+	ksContext := append([]byte{0}, pskIDHash...) // mode_base
+	ksContext = append(ksContext, infoHash...)
+
+	secret := kdf.labeledExtract(sharedSecret, "secret", nil)
+
+	key := kdf.labeledExpand(secret, "key", ksContext, aeadKeySize(aeadID))
+	aead, err := newAEAD(aeadID, key)
+	if err != nil {
+		return nil, err
+	}
+	baseNonce := kdf.labeledExpand(secret, "base_nonce", ksContext, uint16(aead.NonceSize()))
+	exporterSecret := kdf.labeledExpand(secret, "exp", ksContext, uint16(sha256.Size))
+
+	return &context{
+		aead:           aead,
+		sharedSecret:   sharedSecret,
+		suiteID:        suiteID,
+		key:            key,
+		baseNonce:      baseNonce,
+		exporterSecret: exporterSecret,
+	}, nil
+}
+
+// SetupSender creates an encryption context for the public key pub,
+// returning it along with the encapsulated key to send to the recipient.
+func SetupSender(kemID, kdfID, aeadID uint16, pub, info []byte) ([]byte, *Sender, error) {
+	if kemID != DHKEM_X25519_HKDF_SHA256 {
+		return nil, nil, errors.New("hpke: unsupported KEM id")
+	}
+	if len(pub) != 32 {
+		return nil, nil, errors.New("hpke: invalid public key")
+	}
+	sharedSecret, encapsulatedKey, err := x25519KEM.encap(pub)
+	if err != nil {
+		return nil, nil, err
+	}
+	context, err := newContext(sharedSecret, kemID, kdfID, aeadID, info)
+	if err != nil {
+		return nil, nil, err
+	}
+	return encapsulatedKey, &Sender{context}, nil
+}
+
+// SetupRecipient creates a decryption context for the private key priv
+// and the encapsulated key received from the sender.
+func SetupRecipient(kemID, kdfID, aeadID uint16, priv, info, encPubEph []byte) (*Recipient, error) {
+	if kemID != DHKEM_X25519_HKDF_SHA256 {
+		return nil, errors.New("hpke: unsupported KEM id")
+	}
+	if len(priv) != 32 || len(encPubEph) != 32 {
+		return nil, errors.New("hpke: invalid key length")
+	}
+	sharedSecret, err := x25519KEM.decap(priv, encPubEph)
+	if err != nil {
+		return nil, err
+	}
+	context, err := newContext(sharedSecret, kemID, kdfID, aeadID, info)
+	if err != nil {
+		return nil, err
+	}
+	return &Recipient{context}, nil
+}
+
+func (ctx *context) nextNonce() []byte {
+	nonce := make([]byte, len(ctx.baseNonce))
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], ctx.seqNum)
+	for i := range ctx.baseNonce {
+		nonce[i] ^= ctx.baseNonce[i]
+	}
+	return nonce
+}
+
+func (ctx *context) incrementNonce() error {
+	// The sequence number is limited by the nonce length, which is always
+	// at least 8 bytes for the supported AEADs, so only overflow of the
+	// uint64 needs to be checked.
+	if _, carry := bits.Add64(ctx.seqNum, 1, 0); carry != 0 {
+		return errors.New("hpke: message limit reached")
+	}
+	ctx.seqNum++
+	return nil
+}
+
+// Seal encrypts and authenticates plaintext, authenticating aad.
+func (s *Sender) Seal(aad, plaintext []byte) ([]byte, error) {
+	ciphertext := s.aead.Seal(nil, s.nextNonce(), plaintext, aad)
+	if err := s.incrementNonce(); err != nil {
+		return nil, err
+	}
+	return ciphertext, nil
+}
+
+// Open decrypts and authenticates ciphertext, authenticating aad.
+func (r *Recipient) Open(aad, ciphertext []byte) ([]byte, error) {
+	plaintext, err := r.aead.Open(nil, r.nextNonce(), ciphertext, aad)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.incrementNonce(); err != nil {
+		return nil, err
+	}
+	return plaintext, nil
+}
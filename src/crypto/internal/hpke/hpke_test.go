@@ -0,0 +1,206 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hpke
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"testing"
+)
+
+type vectorEncryption struct {
+	AAD        string `json:"aad"`
+	Ciphertext string `json:"ct"`
+	Nonce      string `json:"nonce"`
+	Plaintext  string `json:"pt"`
+}
+
+type vector struct {
+	Mode           uint16             `json:"mode"`
+	KEMID          uint16             `json:"kem_id"`
+	KDFID          uint16             `json:"kdf_id"`
+	AEADID         uint16             `json:"aead_id"`
+	Info           string             `json:"info"`
+	IKMR           string             `json:"ikmR"`
+	IKME           string             `json:"ikmE"`
+	SKRM           string             `json:"skRm"`
+	SKEM           string             `json:"skEm"`
+	PKRM           string             `json:"pkRm"`
+	PKEM           string             `json:"pkEm"`
+	Enc            string             `json:"enc"`
+	SharedSecret   string             `json:"shared_secret"`
+	Key            string             `json:"key"`
+	BaseNonce      string             `json:"base_nonce"`
+	ExporterSecret string             `json:"exporter_secret"`
+	Encryptions    []vectorEncryption `json:"encryptions"`
+}
+
+func mustDecodeHex(t *testing.T, in string) []byte {
+	b, err := hex.DecodeString(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestRFC9180Vectors(t *testing.T) {
+	vectorsJSON, err := ioutil.ReadFile("testdata/rfc9180-vectors.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vectors []vector
+	if err := json.Unmarshal(vectorsJSON, &vectors); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, v := range vectors {
+		name := fmt.Sprintf("mode %04x kem %04x kdf %04x aead %04x", v.Mode, v.KEMID, v.KDFID, v.AEADID)
+		t.Run(name, func(t *testing.T) {
+			if v.Mode != 0 {
+				t.Skip("only base mode is supported")
+			}
+
+			skE := x25519KEM.deriveKeyPair(mustDecodeHex(t, v.IKME))
+			if got, want := skE, mustDecodeHex(t, v.SKEM); !bytes.Equal(got, want) {
+				t.Errorf("derived skEm = %x, want %x", got, want)
+			}
+			skR := x25519KEM.deriveKeyPair(mustDecodeHex(t, v.IKMR))
+			if got, want := skR, mustDecodeHex(t, v.SKRM); !bytes.Equal(got, want) {
+				t.Errorf("derived skRm = %x, want %x", got, want)
+			}
+			pkR, err := PublicKey(skR)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := mustDecodeHex(t, v.PKRM); !bytes.Equal(pkR, want) {
+				t.Errorf("pkRm = %x, want %x", pkR, want)
+			}
+
+			testingOnlyGenerateKey = func() ([]byte, error) {
+				return skE, nil
+			}
+			defer func() { testingOnlyGenerateKey = nil }()
+
+			info := mustDecodeHex(t, v.Info)
+			enc, sender, err := SetupSender(v.KEMID, v.KDFID, v.AEADID, pkR, info)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := mustDecodeHex(t, v.Enc); !bytes.Equal(enc, want) {
+				t.Errorf("enc = %x, want %x", enc, want)
+			}
+			recipient, err := SetupRecipient(v.KEMID, v.KDFID, v.AEADID, skR, info, enc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, ctx := range []*context{sender.context, recipient.context} {
+				if want := mustDecodeHex(t, v.SharedSecret); !bytes.Equal(ctx.sharedSecret, want) {
+					t.Errorf("shared secret = %x, want %x", ctx.sharedSecret, want)
+				}
+				if want := mustDecodeHex(t, v.Key); !bytes.Equal(ctx.key, want) {
+					t.Errorf("key = %x, want %x", ctx.key, want)
+				}
+				if want := mustDecodeHex(t, v.BaseNonce); !bytes.Equal(ctx.baseNonce, want) {
+					t.Errorf("base nonce = %x, want %x", ctx.baseNonce, want)
+				}
+				if want := mustDecodeHex(t, v.ExporterSecret); !bytes.Equal(ctx.exporterSecret, want) {
+					t.Errorf("exporter secret = %x, want %x", ctx.exporterSecret, want)
+				}
+			}
+
+			for _, enc := range v.Encryptions {
+				if got, want := sender.nextNonce(), mustDecodeHex(t, enc.Nonce); !bytes.Equal(got, want) {
+					t.Errorf("nonce = %x, want %x", got, want)
+				}
+				aad := mustDecodeHex(t, enc.AAD)
+				pt := mustDecodeHex(t, enc.Plaintext)
+				ct, err := sender.Seal(aad, pt)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if want := mustDecodeHex(t, enc.Ciphertext); !bytes.Equal(ct, want) {
+					t.Errorf("ciphertext = %x, want %x", ct, want)
+				}
+				got, err := recipient.Open(aad, ct)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(got, pt) {
+					t.Errorf("plaintext = %x, want %x", got, pt)
+				}
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for aeadID := range SupportedAEADs {
+		t.Run(fmt.Sprintf("aead %04x", aeadID), func(t *testing.T) {
+			priv, err := GenerateKey(rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pub, err := PublicKey(priv)
+			if err != nil {
+				t.Fatal(err)
+			}
+			info := []byte("info")
+			enc, sender, err := SetupSender(DHKEM_X25519_HKDF_SHA256, KDF_HKDF_SHA256, aeadID, pub, info)
+			if err != nil {
+				t.Fatal(err)
+			}
+			recipient, err := SetupRecipient(DHKEM_X25519_HKDF_SHA256, KDF_HKDF_SHA256, aeadID, priv, info, enc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 3; i++ {
+				aad := []byte(fmt.Sprintf("aad %d", i))
+				pt := []byte(fmt.Sprintf("message %d", i))
+				ct, err := sender.Seal(aad, pt)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, err := recipient.Open(aad, ct)
+				if err != nil {
+					t.Fatalf("Open message %d: %v", i, err)
+				}
+				if !bytes.Equal(got, pt) {
+					t.Errorf("message %d: got %q, want %q", i, got, pt)
+				}
+			}
+
+			// A message sealed out of order must not open.
+			ct, err := sender.Seal(nil, []byte("skipped"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := sender.Seal(nil, []byte("next")); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := recipient.Open([]byte("wrong aad"), ct); err == nil {
+				t.Errorf("Open succeeded with the wrong additional data")
+			}
+		})
+	}
+}
+
+func TestUnsupportedAlgorithms(t *testing.T) {
+	pub := make([]byte, 32)
+	pub[0] = 9
+	if _, _, err := SetupSender(0x0010, KDF_HKDF_SHA256, AEAD_AES_128_GCM, pub, nil); err == nil {
+		t.Errorf("SetupSender accepted an unsupported KEM")
+	}
+	if _, _, err := SetupSender(DHKEM_X25519_HKDF_SHA256, 0x0002, AEAD_AES_128_GCM, pub, nil); err == nil {
+		t.Errorf("SetupSender accepted an unsupported KDF")
+	}
+	if _, _, err := SetupSender(DHKEM_X25519_HKDF_SHA256, KDF_HKDF_SHA256, 0xffff, pub, nil); err == nil {
+		t.Errorf("SetupSender accepted an unsupported AEAD")
+	}
+}
@@ -0,0 +1,327 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package mlkem768 implements the quantum-resistant key encapsulation method
+// ML-KEM (formerly known as Kyber), as specified in FIPS 203, with the
+// ML-KEM-768 parameter set.
+//
+// It is used by crypto/tls for the X25519MLKEM768 hybrid key exchange.
+package mlkem768
+
+import (
+	"crypto/internal/sha3"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"io"
+)
+
+const (
+	// ML-KEM global constants.
+	n = 256
+	q = 3329
+
+	log2q = 12
+
+	// ML-KEM-768 parameters. The code makes assumptions based on these values,
+	// they can't be changed blindly.
+	k  = 3
+	η  = 2
+	du = 10
+	dv = 4
+
+	// encodingSizeX is the byte size of a ringElement or nttElement encoded
+	// by ByteEncode_X (FIPS 203, Algorithm 5).
+	encodingSize12 = n * log2q / 8
+	encodingSize10 = n * du / 8
+	encodingSize4  = n * dv / 8
+	encodingSize1  = n * 1 / 8
+
+	messageSize       = encodingSize1
+	decryptionKeySize = k * encodingSize12
+
+	CiphertextSize       = k*encodingSize10 + encodingSize4
+	EncapsulationKeySize = k*encodingSize12 + 32
+	SharedKeySize        = 32
+	SeedSize             = 32 + 32
+)
+
+// A DecapsulationKey is the secret key used to decapsulate a shared key from a
+// ciphertext. It includes various precomputed values.
+type DecapsulationKey struct {
+	d [32]byte // decapsulation key seed
+	z [32]byte // implicit rejection sampling seed
+
+	ρ [32]byte // sampleNTT seed for A, stored for the encapsulation key
+	h [32]byte // H(ek), stored for ML-KEM.Decaps_internal
+
+	encryptionKey
+	decryptionKey
+}
+
+// Bytes returns the decapsulation key as a 64-byte seed in the "d || z" form.
+func (dk *DecapsulationKey) Bytes() []byte {
+	var b [SeedSize]byte
+	copy(b[:], dk.d[:])
+	copy(b[32:], dk.z[:])
+	return b[:]
+}
+
+// EncapsulationKey returns the public encapsulation key necessary to produce
+// ciphertexts.
+func (dk *DecapsulationKey) EncapsulationKey() []byte {
+	b := make([]byte, 0, EncapsulationKeySize)
+	for i := range dk.t {
+		b = polyByteEncode(b, dk.t[i])
+	}
+	b = append(b, dk.ρ[:]...)
+	return b
+}
+
+// encryptionKey is the parsed and expanded form of a PKE encryption key.
+type encryptionKey struct {
+	t [k]nttElement     // ByteDecode₁₂(ek[:384k])
+	A [k * k]nttElement // A[i*k+j] = sampleNTT(ρ, j, i)
+}
+
+// decryptionKey is the parsed and expanded form of a PKE decryption key.
+type decryptionKey struct {
+	s [k]nttElement // ByteDecode₁₂(dk[:decryptionKeySize])
+}
+
+// GenerateKey generates a new decapsulation key, drawing random bytes from
+// crypto/rand. The decapsulation key must be kept secret.
+func GenerateKey() (*DecapsulationKey, error) {
+	var d, z [32]byte
+	if _, err := io.ReadFull(rand.Reader, d[:]); err != nil {
+		return nil, errors.New("mlkem768: crypto/rand Read failed: " + err.Error())
+	}
+	if _, err := io.ReadFull(rand.Reader, z[:]); err != nil {
+		return nil, errors.New("mlkem768: crypto/rand Read failed: " + err.Error())
+	}
+	return kemKeyGen(&d, &z), nil
+}
+
+// NewKeyFromSeed deterministically generates a decapsulation key from a 64-byte
+// seed in the "d || z" form. The seed must be uniformly random.
+func NewKeyFromSeed(seed []byte) (*DecapsulationKey, error) {
+	if len(seed) != SeedSize {
+		return nil, errors.New("mlkem768: invalid seed length")
+	}
+	var d, z [32]byte
+	copy(d[:], seed[:32])
+	copy(z[:], seed[32:])
+	return kemKeyGen(&d, &z), nil
+}
+
+// kemKeyGen generates a decapsulation key.
+//
+// It implements ML-KEM.KeyGen_internal according to FIPS 203, Algorithm 16,
+// and K-PKE.KeyGen according to FIPS 203, Algorithm 13.
+func kemKeyGen(d, z *[32]byte) *DecapsulationKey {
+	dk := &DecapsulationKey{d: *d, z: *z}
+
+	g := sha3.New512()
+	g.Write(d[:])
+	g.Write([]byte{k}) // Module dimension as a domain separator.
+	G := g.Sum(make([]byte, 0, 64))
+	ρ, σ := G[:32], G[32:]
+	copy(dk.ρ[:], ρ)
+
+	A := &dk.A
+	for i := byte(0); i < k; i++ {
+		for j := byte(0); j < k; j++ {
+			A[i*k+j] = sampleNTT(ρ, j, i)
+		}
+	}
+
+	var N byte
+	s := &dk.s
+	for i := range s {
+		s[i] = ntt(samplePolyCBD(σ, N))
+		N++
+	}
+	e := make([]nttElement, k)
+	for i := range e {
+		e[i] = ntt(samplePolyCBD(σ, N))
+		N++
+	}
+
+	t := &dk.t
+	for i := range t { // t = A ◦ s + e
+		t[i] = e[i]
+		for j := range s {
+			t[i] = nttAdd(t[i], nttMul(A[i*k+j], s[j]))
+		}
+	}
+
+	H := sha3.New256()
+	H.Write(dk.EncapsulationKey())
+	H.Sum(dk.h[:0])
+
+	return dk
+}
+
+// Encapsulate generates a shared key and an associated ciphertext from an
+// encapsulation key, drawing random bytes from crypto/rand.
+// If the encapsulation key is not valid, Encapsulate returns an error.
+//
+// The shared key must be kept secret.
+func Encapsulate(encapsulationKey []byte) (ciphertext, sharedKey []byte, err error) {
+	var m [messageSize]byte
+	if _, err := io.ReadFull(rand.Reader, m[:]); err != nil {
+		return nil, nil, errors.New("mlkem768: crypto/rand Read failed: " + err.Error())
+	}
+	return encapsulateDerand(encapsulationKey, &m)
+}
+
+// encapsulateDerand is Encapsulate with a fixed message, for testing.
+func encapsulateDerand(encapsulationKey []byte, m *[messageSize]byte) (ciphertext, sharedKey []byte, err error) {
+	if len(encapsulationKey) != EncapsulationKeySize {
+		return nil, nil, errors.New("mlkem768: invalid encapsulation key length")
+	}
+	ex, err := parseEK(encapsulationKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	ciphertext, sharedKey = kemEncaps(ex, encapsulationKey, m)
+	return ciphertext, sharedKey, nil
+}
+
+// parseEK parses an encryption key from its encoded form.
+//
+// It implements the initial stages of K-PKE.Encrypt according to FIPS 203,
+// Algorithm 14, including the modulus check of Section 7.2.
+func parseEK(ekPKE []byte) (*encryptionKey, error) {
+	ex := &encryptionKey{}
+	for i := range ex.t {
+		var err error
+		ex.t[i], err = polyByteDecode(ekPKE[:encodingSize12])
+		if err != nil {
+			return nil, err
+		}
+		ekPKE = ekPKE[encodingSize12:]
+	}
+	ρ := ekPKE
+
+	for i := byte(0); i < k; i++ {
+		for j := byte(0); j < k; j++ {
+			ex.A[i*k+j] = sampleNTT(ρ, j, i)
+		}
+	}
+	return ex, nil
+}
+
+// kemEncaps generates a shared key and an associated ciphertext.
+//
+// It implements ML-KEM.Encaps_internal according to FIPS 203, Algorithm 17.
+func kemEncaps(ex *encryptionKey, ek []byte, m *[messageSize]byte) (c, K []byte) {
+	h := sha3.Sum256(ek)
+	g := sha3.New512()
+	g.Write(m[:])
+	g.Write(h[:])
+	G := g.Sum(nil)
+	K, r := G[:SharedKeySize], G[SharedKeySize:]
+	c = pkeEncrypt(ex, m, r)
+	return c, K
+}
+
+// pkeEncrypt encrypt a plaintext message.
+//
+// It implements K-PKE.Encrypt according to FIPS 203, Algorithm 14, although the
+// computation of t and AT is done in parseEK.
+func pkeEncrypt(ex *encryptionKey, m *[messageSize]byte, rnd []byte) []byte {
+	var N byte
+	r, e1 := make([]nttElement, k), make([]ringElement, k)
+	for i := range r {
+		r[i] = ntt(samplePolyCBD(rnd, N))
+		N++
+	}
+	for i := range e1 {
+		e1[i] = samplePolyCBD(rnd, N)
+		N++
+	}
+	e2 := samplePolyCBD(rnd, N)
+
+	u := make([]ringElement, k) // NTT⁻¹(AT ◦ r) + e1
+	for i := range u {
+		u[i] = e1[i]
+		for j := range r {
+			// Note that i and j are inverted, as we need the transposed of A.
+			u[i] = polyAdd(u[i], inverseNTT(nttMul(ex.A[j*k+i], r[j])))
+		}
+	}
+
+	μ := ringDecodeAndDecompress1(m)
+
+	var vNTT nttElement // t⊺ ◦ r
+	for i := range ex.t {
+		vNTT = nttAdd(vNTT, nttMul(ex.t[i], r[i]))
+	}
+	v := polyAdd(polyAdd(inverseNTT(vNTT), e2), μ)
+
+	c := make([]byte, 0, CiphertextSize)
+	for _, f := range u {
+		c = ringCompressAndEncode(c, f, du)
+	}
+	c = ringCompressAndEncode(c, v, dv)
+
+	return c
+}
+
+// Decapsulate generates a shared key from a ciphertext and a decapsulation key.
+// If the ciphertext is not valid, Decapsulate returns an error.
+//
+// The shared key must be kept secret.
+func Decapsulate(dk *DecapsulationKey, ciphertext []byte) (sharedKey []byte, err error) {
+	if len(ciphertext) != CiphertextSize {
+		return nil, errors.New("mlkem768: invalid ciphertext length")
+	}
+	return kemDecaps(dk, ciphertext), nil
+}
+
+// kemDecaps produces a shared key from a ciphertext.
+//
+// It implements ML-KEM.Decaps_internal according to FIPS 203, Algorithm 18.
+func kemDecaps(dk *DecapsulationKey, c []byte) (K []byte) {
+	m := pkeDecrypt(&dk.decryptionKey, c)
+	g := sha3.New512()
+	g.Write(m[:])
+	g.Write(dk.h[:])
+	G := g.Sum(make([]byte, 0, 64))
+	Kprime, r := G[:SharedKeySize], G[SharedKeySize:]
+	J := sha3.NewShake256()
+	J.Write(dk.z[:])
+	J.Write(c)
+	Kout := make([]byte, SharedKeySize)
+	J.Read(Kout)
+	c1 := pkeEncrypt(&dk.encryptionKey, &m, r)
+
+	subtle.ConstantTimeCopy(subtle.ConstantTimeCompare(c, c1), Kout, Kprime)
+	return Kout
+}
+
+// pkeDecrypt decrypts a ciphertext.
+//
+// It implements K-PKE.Decrypt according to FIPS 203, Algorithm 15,
+// although s is retained from kemKeyGen.
+func pkeDecrypt(dx *decryptionKey, c []byte) (m [messageSize]byte) {
+	u := make([]ringElement, k)
+	for i := range u {
+		b := c[encodingSize10*i : encodingSize10*(i+1)]
+		u[i] = ringDecodeAndDecompress(b, du)
+	}
+
+	b := c[encodingSize10*k:]
+	v := ringDecodeAndDecompress(b, dv)
+
+	var mask nttElement // s⊺ ◦ NTT(u)
+	for i := range dx.s {
+		mask = nttAdd(mask, nttMul(dx.s[i], ntt(u[i])))
+	}
+	w := polySub(v, inverseNTT(mask))
+
+	copy(m[:], ringCompressAndEncode1(nil, w))
+	return m
+}
@@ -0,0 +1,200 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mlkem768
+
+import (
+	"bytes"
+	"crypto/internal/sha3"
+	"encoding/hex"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	dk, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ek := dk.EncapsulationKey()
+	if len(ek) != EncapsulationKeySize {
+		t.Errorf("encapsulation key length = %d, want %d", len(ek), EncapsulationKeySize)
+	}
+	c, Ke, err := Encapsulate(ek)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c) != CiphertextSize {
+		t.Errorf("ciphertext length = %d, want %d", len(c), CiphertextSize)
+	}
+	Kd, err := Decapsulate(dk, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(Ke, Kd) {
+		t.Fail()
+	}
+
+	dk1, err := NewKeyFromSeed(dk.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(dk1.EncapsulationKey(), ek) {
+		t.Errorf("key from Bytes() has a different encapsulation key")
+	}
+
+	dk2, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(dk.EncapsulationKey(), dk2.EncapsulationKey()) {
+		t.Fail()
+	}
+
+	// A different decapsulation key implicitly rejects the ciphertext.
+	Kd2, err := Decapsulate(dk2, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(Ke, Kd2) {
+		t.Fail()
+	}
+}
+
+func TestBadLengths(t *testing.T) {
+	dk, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ek := dk.EncapsulationKey()
+
+	for i := 0; i < len(ek)-1; i += 97 {
+		if _, _, err := Encapsulate(ek[:i]); err == nil {
+			t.Errorf("expected error for ek length %d", i)
+		}
+	}
+	if _, _, err := Encapsulate(append(ek, 0)); err == nil {
+		t.Errorf("expected error for long ek")
+	}
+
+	// Coefficients must be reduced modulo q.
+	bad := append([]byte{}, ek...)
+	bad[0], bad[1] = 0xff, 0x0f
+	if _, _, err := Encapsulate(bad); err == nil {
+		t.Errorf("expected error for unreduced ek")
+	}
+
+	c, _, err := Encapsulate(ek)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range []int{0, len(c) - 1, len(c) + 1} {
+		if _, err := Decapsulate(dk, append(c, 0)[:l]); err == nil {
+			t.Errorf("expected error for c length %d", l)
+		}
+	}
+
+	if _, err := NewKeyFromSeed(make([]byte, SeedSize-1)); err == nil {
+		t.Errorf("expected error for short seed")
+	}
+}
+
+// TestAccumulated checks a deterministic sequence of key generations,
+// encapsulations and decapsulations, including of random ciphertexts, which
+// exercise implicit rejection. The inputs are drawn from a SHAKE128 stream,
+// and all outputs are hashed together with SHAKE128.
+func TestAccumulated(t *testing.T) {
+	n := 1000
+	expected := "78d7c03e462a9b629602564d7a25a61fe1082beaea54b3b6d13d3d7bea50b43d"
+	if testing.Short() {
+		n = 100
+		expected = "1114b1b6699ed191734fa339376afa7e285c9e6acf6ff0177d346696ce564415"
+	}
+
+	s := sha3.NewShake128()
+	o := sha3.NewShake128()
+	seed := make([]byte, SeedSize)
+	var msg [messageSize]byte
+	ct1 := make([]byte, CiphertextSize)
+
+	for i := 0; i < n; i++ {
+		s.Read(seed)
+		dk, err := NewKeyFromSeed(seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ek := dk.EncapsulationKey()
+		o.Write(ek)
+
+		s.Read(msg[:])
+		ct, k, err := encapsulateDerand(ek, &msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		o.Write(ct)
+		o.Write(k)
+
+		kk, err := Decapsulate(dk, ct)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(kk, k) {
+			t.Errorf("k: got %x, expected %x", kk, k)
+		}
+
+		s.Read(ct1)
+		k1, err := Decapsulate(dk, ct1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		o.Write(k1)
+	}
+
+	got := hex.EncodeToString(o.Sum(nil))
+	if got != expected {
+		t.Errorf("got %s, expected %s", got, expected)
+	}
+}
+
+var sink byte
+
+func BenchmarkKeyGen(b *testing.B) {
+	var d, z [32]byte
+	for i := 0; i < b.N; i++ {
+		dk := kemKeyGen(&d, &z)
+		sink ^= dk.EncapsulationKey()[0]
+	}
+}
+
+func BenchmarkEncaps(b *testing.B) {
+	dk, err := GenerateKey()
+	if err != nil {
+		b.Fatal(err)
+	}
+	ek := dk.EncapsulationKey()
+	var m [messageSize]byte
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c, K, err := encapsulateDerand(ek, &m)
+		if err != nil {
+			b.Fatal(err)
+		}
+		sink ^= c[0] ^ K[0]
+	}
+}
+
+func BenchmarkDecaps(b *testing.B) {
+	dk, err := GenerateKey()
+	if err != nil {
+		b.Fatal(err)
+	}
+	c, _, err := Encapsulate(dk.EncapsulationKey())
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		K, _ := Decapsulate(dk, c)
+		sink ^= K[0]
+	}
+}
@@ -0,0 +1,270 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nistec
+
+import (
+	"errors"
+	"math/bits"
+)
+
+// maxLimbs is the number of 64-bit limbs needed for the largest supported
+// field, GF(2⁵²¹ - 1).
+const maxLimbs = 9
+
+// fieldElement is an integer modulo the field prime, as little-endian 64-bit
+// limbs in the Montgomery domain. Only the first n limbs of the field it
+// belongs to are used, and the value is always fully reduced.
+type fieldElement [maxLimbs]uint64
+
+// field implements constant-time arithmetic modulo an odd prime p, using
+// Montgomery multiplication with R = 2^(64n).
+type field struct {
+	n       int // number of limbs
+	byteLen int // length of the big-endian encoding
+	p       fieldElement
+	pInv    uint64       // -p⁻¹ mod 2⁶⁴
+	rr      fieldElement // R² mod p
+	one     fieldElement // R mod p, that is 1 in the Montgomery domain
+	pMinus2 []byte       // p - 2, big-endian, for inversion
+}
+
+// newField returns a field for the prime with the given big-endian hex
+// encoding, which must be odd and byteLen bytes long.
+func newField(pHex string, byteLen int) *field {
+	f := &field{byteLen: byteLen, n: (byteLen + 7) / 8}
+	pBytes := mustDecodeHex(pHex, byteLen)
+	limbsFromBytes(&f.p, pBytes)
+
+	// Newton's iteration doubles the number of correct low bits of the
+	// inverse every step, starting from 1 bit (p is odd, so p⁻¹ ≡ 1 mod 2).
+	inv := uint64(1)
+	for i := 0; i < 6; i++ {
+		inv *= 2 - f.p[0]*inv
+	}
+	f.pInv = -inv
+
+	// Compute R mod p and R² mod p by repeated doubling, which only needs
+	// modular addition, starting from 1.
+	var x fieldElement
+	x[0] = 1
+	for i := 0; i < 64*f.n; i++ {
+		f.add(&x, &x, &x)
+	}
+	f.one = x
+	for i := 0; i < 64*f.n; i++ {
+		f.add(&x, &x, &x)
+	}
+	f.rr = x
+
+	var two, pm2 fieldElement
+	two[0] = 2
+	var borrow uint64
+	for i := 0; i < f.n; i++ {
+		pm2[i], borrow = bits.Sub64(f.p[i], two[i], borrow)
+	}
+	f.pMinus2 = f.toBytes(&pm2)
+	return f
+}
+
+// add sets out = a + b mod p.
+func (f *field) add(out, a, b *fieldElement) {
+	var t, u fieldElement
+	var carry, borrow uint64
+	for i := 0; i < f.n; i++ {
+		t[i], carry = bits.Add64(a[i], b[i], carry)
+	}
+	for i := 0; i < f.n; i++ {
+		u[i], borrow = bits.Sub64(t[i], f.p[i], borrow)
+	}
+	// The sum is below 2p. Keep t if there was no carry out of it and
+	// subtracting p borrowed, that is if t < p.
+	_, borrow = bits.Sub64(carry, 0, borrow)
+	f.selectLimbs(out, &t, &u, borrow)
+}
+
+// sub sets out = a - b mod p.
+func (f *field) sub(out, a, b *fieldElement) {
+	var t fieldElement
+	var borrow, carry uint64
+	for i := 0; i < f.n; i++ {
+		t[i], borrow = bits.Sub64(a[i], b[i], borrow)
+	}
+	// If the subtraction underflowed, add p back.
+	mask := -borrow
+	for i := 0; i < f.n; i++ {
+		t[i], carry = bits.Add64(t[i], f.p[i]&mask, carry)
+	}
+	*out = t
+}
+
+// mul sets out = a * b / R mod p.
+func (f *field) mul(out, a, b *fieldElement) {
+	// Coarsely integrated operand scanning (CIOS) Montgomery multiplication.
+	var t [maxLimbs + 2]uint64
+	n := f.n
+	for i := 0; i < n; i++ {
+		var c uint64
+		for j := 0; j < n; j++ {
+			hi, lo := bits.Mul64(a[j], b[i])
+			var cc uint64
+			lo, cc = bits.Add64(lo, t[j], 0)
+			hi += cc
+			lo, cc = bits.Add64(lo, c, 0)
+			hi += cc
+			t[j], c = lo, hi
+		}
+		var cc uint64
+		t[n], cc = bits.Add64(t[n], c, 0)
+		t[n+1] = cc
+
+		m := t[0] * f.pInv
+		hi, lo := bits.Mul64(m, f.p[0])
+		_, cc = bits.Add64(lo, t[0], 0)
+		c = hi + cc
+		for j := 1; j < n; j++ {
+			hi, lo := bits.Mul64(m, f.p[j])
+			lo, cc = bits.Add64(lo, t[j], 0)
+			hi += cc
+			lo, cc = bits.Add64(lo, c, 0)
+			hi += cc
+			t[j-1], c = lo, hi
+		}
+		t[n-1], cc = bits.Add64(t[n], c, 0)
+		t[n] = t[n+1] + cc
+	}
+
+	// The result is below 2p, so a single conditional subtraction reduces it.
+	var r, u fieldElement
+	copy(r[:n], t[:n])
+	var borrow uint64
+	for i := 0; i < n; i++ {
+		u[i], borrow = bits.Sub64(r[i], f.p[i], borrow)
+	}
+	_, borrow = bits.Sub64(t[n], 0, borrow)
+	f.selectLimbs(out, &r, &u, borrow)
+}
+
+// square sets out = a² / R mod p.
+func (f *field) square(out, a *fieldElement) {
+	f.mul(out, a, a)
+}
+
+// invert sets out = 1 / a mod p, or zero if a is zero.
+func (f *field) invert(out, a *fieldElement) {
+	// Fermat's little theorem: a⁻¹ = a^(p-2) mod p. The exponent is public,
+	// so the square-and-multiply chain doesn't leak anything about a.
+	x := *a
+	r := f.one
+	for _, b := range f.pMinus2 {
+		for i := 7; i >= 0; i-- {
+			f.square(&r, &r)
+			if b>>uint(i)&1 == 1 {
+				f.mul(&r, &r, &x)
+			}
+		}
+	}
+	*out = r
+}
+
+// selectLimbs sets out = a if cond == 1, and out = b if cond == 0.
+func (f *field) selectLimbs(out, a, b *fieldElement, cond uint64) {
+	mask := -cond
+	for i := 0; i < f.n; i++ {
+		out[i] = a[i]&mask | b[i]&^mask
+	}
+}
+
+// isZero returns 1 if a == 0, and 0 otherwise.
+func (f *field) isZero(a *fieldElement) int {
+	var acc uint64
+	for i := 0; i < f.n; i++ {
+		acc |= a[i]
+	}
+	return int(1 ^ (acc|-acc)>>63)
+}
+
+// equal returns 1 if a == b, and 0 otherwise.
+func (f *field) equal(a, b *fieldElement) int {
+	var acc uint64
+	for i := 0; i < f.n; i++ {
+		acc |= a[i] ^ b[i]
+	}
+	return int(1 ^ (acc|-acc)>>63)
+}
+
+var errInvalidFieldElement = errors.New("invalid field element encoding")
+
+// setBytes sets out to the value of the big-endian encoding b, which must be
+// exactly byteLen bytes long and encode a value lower than p.
+func (f *field) setBytes(out *fieldElement, b []byte) error {
+	if len(b) != f.byteLen {
+		return errInvalidFieldElement
+	}
+	var x, u fieldElement
+	limbsFromBytes(&x, b)
+	var borrow uint64
+	for i := 0; i < f.n; i++ {
+		u[i], borrow = bits.Sub64(x[i], f.p[i], borrow)
+	}
+	for i := f.n; i < maxLimbs; i++ {
+		if x[i] != 0 {
+			return errInvalidFieldElement
+		}
+	}
+	if borrow != 1 {
+		return errInvalidFieldElement
+	}
+	f.mul(out, &x, &f.rr)
+	return nil
+}
+
+// bytes returns the byteLen bytes big-endian encoding of a.
+func (f *field) bytes(a *fieldElement) []byte {
+	var one, x fieldElement
+	one[0] = 1
+	f.mul(&x, a, &one)
+	return f.toBytes(&x)
+}
+
+// toBytes encodes the limbs of x, which is not in the Montgomery domain.
+func (f *field) toBytes(x *fieldElement) []byte {
+	out := make([]byte, f.byteLen)
+	for i := range out {
+		j := f.byteLen - 1 - i
+		out[i] = byte(x[j/8] >> uint(8*(j%8)))
+	}
+	return out
+}
+
+// limbsFromBytes sets x to the big-endian encoding b, which must be at most
+// 8*maxLimbs bytes long.
+func limbsFromBytes(x *fieldElement, b []byte) {
+	*x = fieldElement{}
+	for i := range b {
+		j := len(b) - 1 - i
+		x[j/8] |= uint64(b[i]) << uint(8*(j%8))
+	}
+}
+
+func mustDecodeHex(s string, byteLen int) []byte {
+	if len(s) != 2*byteLen {
+		panic("nistec: internal error: bad constant length")
+	}
+	out := make([]byte, byteLen)
+	for i := range out {
+		out[i] = fromHexChar(s[2*i])<<4 | fromHexChar(s[2*i+1])
+	}
+	return out
+}
+
+func fromHexChar(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	}
+	panic("nistec: internal error: bad hex constant")
+}
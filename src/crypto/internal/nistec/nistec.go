@@ -0,0 +1,364 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package nistec implements the NIST P elliptic curves from FIPS 186-4.
+//
+// This package uses fixed-size limbs, complete addition formulas, and
+// constant-time field operations and table lookups, so that the timing of
+// every operation is independent of the (secret) scalars and points involved.
+// It is the implementation backing crypto/ecdh, and is not exposed to
+// applications directly.
+package nistec
+
+import (
+	"crypto/subtle"
+	"errors"
+)
+
+// A Curve is one of the NIST prime-order curves y² = x³ - 3x + b.
+type Curve struct {
+	name   string
+	f      *field
+	b      fieldElement // in the Montgomery domain
+	gx, gy fieldElement
+	order  []byte // big-endian, byteLen long
+}
+
+func newCurve(name string, byteLen int, p, n, b, gx, gy string) *Curve {
+	c := &Curve{name: name, f: newField(p, byteLen)}
+	if err := c.f.setBytes(&c.b, mustDecodeHex(b, byteLen)); err != nil {
+		panic("nistec: internal error: invalid b")
+	}
+	if err := c.f.setBytes(&c.gx, mustDecodeHex(gx, byteLen)); err != nil {
+		panic("nistec: internal error: invalid generator")
+	}
+	if err := c.f.setBytes(&c.gy, mustDecodeHex(gy, byteLen)); err != nil {
+		panic("nistec: internal error: invalid generator")
+	}
+	c.order = mustDecodeHex(n, byteLen)
+	return c
+}
+
+var (
+	p256 = newCurve("P-256", 32,
+		"ffffffff00000001000000000000000000000000ffffffffffffffffffffffff",
+		"ffffffff00000000ffffffffffffffffbce6faada7179e84f3b9cac2fc632551",
+		"5ac635d8aa3a93e7b3ebbd55769886bc651d06b0cc53b0f63bce3c3e27d2604b",
+		"6b17d1f2e12c4247f8bce6e563a440f277037d812deb33a0f4a13945d898c296",
+		"4fe342e2fe1a7f9b8ee7eb4a7c0f9e162bce33576b315ececbb6406837bf51f5")
+	p384 = newCurve("P-384", 48,
+		"fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffeffffffff0000000000000000ffffffff",
+		"ffffffffffffffffffffffffffffffffffffffffffffffffc7634d81f4372ddf581a0db248b0a77aecec196accc52973",
+		"b3312fa7e23ee7e4988e056be3f82d19181d9c6efe8141120314088f5013875ac656398d8a2ed19d2a85c8edd3ec2aef",
+		"aa87ca22be8b05378eb1c71ef320ad746e1d3b628ba79b9859f741e082542a385502f25dbf55296c3a545e3872760ab7",
+		"3617de4a96262c6f5d9e98bf9292dc29f8f41dbd289a147ce9da3113b5f0b8c00a60b1ce1d7e819d7a431d7c90ea0e5f")
+	p521 = newCurve("P-521", 66,
+		"01ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		"01fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffa51868783bf2f966b7fcc0148f709a5d03bb5c9b8899c47aebb6fb71e91386409",
+		"0051953eb9618e1c9a1f929a21a0b68540eea2da725b99b315f3b8b489918ef109e156193951ec7e937b1652c0bd3bb1bf073573df883d2c34f1ef451fd46b503f00",
+		"00c6858e06b70404e9cd9e3ecb662395b4429c648139053fb521f828af606b4d3dbaa14b5e77efe75928fe1dc127a2ffa8de3348b3c1856a429bf97e7e31c2e5bd66",
+		"011839296a789a3bc0045c8a5fb42c7d1bd998f54449579b446817afbd17273e662c97ee72995ef42640c550b9013fad0761353c7086a272c24088be94769fd16650")
+)
+
+// P256 returns the P-256 curve, also known as secp256r1 or prime256v1.
+func P256() *Curve { return p256 }
+
+// P384 returns the P-384 curve, also known as secp384r1.
+func P384() *Curve { return p384 }
+
+// P521 returns the P-521 curve, also known as secp521r1.
+func P521() *Curve { return p521 }
+
+// Name returns the canonical name of the curve, such as "P-256".
+func (c *Curve) Name() string { return c.name }
+
+// ScalarSize returns the length of the encoding of scalars and of field
+// elements, which for these curves are the same.
+func (c *Curve) ScalarSize() int { return c.f.byteLen }
+
+// Order returns the big-endian encoding of the order of the group, padded
+// to ScalarSize bytes. The caller must not modify it.
+func (c *Curve) Order() []byte { return c.order }
+
+// A Point is a point on a Curve, in projective coordinates, (X:Y:Z) with
+// x = X/Z and y = Y/Z. The point at infinity is (0:1:0).
+//
+// The zero value is NOT valid, and may be used only as a receiver of
+// operations that set the curve, such as NewPoint.
+type Point struct {
+	c       *Curve
+	x, y, z fieldElement
+}
+
+// NewPoint returns a new Point representing the point at infinity.
+func (c *Curve) NewPoint() *Point {
+	return &Point{c: c, y: c.f.one}
+}
+
+// NewGenerator returns a new Point set to the canonical generator.
+func (c *Curve) NewGenerator() *Point {
+	return &Point{c: c, x: c.gx, y: c.gy, z: c.f.one}
+}
+
+// Set sets p = q and returns p.
+func (p *Point) Set(q *Point) *Point {
+	*p = *q
+	return p
+}
+
+// SetBytes sets p to the uncompressed or infinity value encoded in b, as
+// specified in SEC 1, Version 2.0, Section 2.3.4. If the point is not on the
+// curve, it returns nil and an error, and the receiver is unchanged.
+// Otherwise, it returns p.
+//
+// Compressed encodings are not supported and are rejected.
+func (p *Point) SetBytes(b []byte) (*Point, error) {
+	c := p.c
+	l := c.f.byteLen
+	switch {
+	case len(b) == 1 && b[0] == 0:
+		return p.Set(c.NewPoint()), nil
+	case len(b) == 1+2*l && b[0] == 4:
+		var x, y fieldElement
+		if err := c.f.setBytes(&x, b[1:1+l]); err != nil {
+			return nil, errors.New("invalid P point encoding")
+		}
+		if err := c.f.setBytes(&y, b[1+l:]); err != nil {
+			return nil, errors.New("invalid P point encoding")
+		}
+		if err := c.checkOnCurve(&x, &y); err != nil {
+			return nil, err
+		}
+		p.x, p.y, p.z = x, y, c.f.one
+		return p, nil
+	default:
+		return nil, errors.New("invalid P point encoding")
+	}
+}
+
+// checkOnCurve returns an error unless y² = x³ - 3x + b.
+func (c *Curve) checkOnCurve(x, y *fieldElement) error {
+	f := c.f
+	var rhs, t, y2 fieldElement
+	f.square(&rhs, x)
+	f.mul(&rhs, &rhs, x)
+	f.add(&t, x, x)
+	f.add(&t, &t, x)
+	f.sub(&rhs, &rhs, &t)
+	f.add(&rhs, &rhs, &c.b)
+	f.square(&y2, y)
+	if f.equal(&rhs, &y2) != 1 {
+		return errors.New("P point not on curve")
+	}
+	return nil
+}
+
+// Bytes returns the uncompressed or infinity encoding of p, as specified in
+// SEC 1, Version 2.0, Section 2.3.3. Note that the encoding of the point at
+// infinity is shorter than all other encodings.
+func (p *Point) Bytes() []byte {
+	f := p.c.f
+	if f.isZero(&p.z) == 1 {
+		return []byte{0}
+	}
+	x, y := p.affine()
+	out := make([]byte, 0, 1+2*f.byteLen)
+	out = append(out, 4)
+	out = append(out, f.bytes(&x)...)
+	out = append(out, f.bytes(&y)...)
+	return out
+}
+
+// BytesX returns the encoding of the x-coordinate of p, as specified in SEC 1,
+// Version 2.0, Section 2.3.5, or an error if p is the point at infinity.
+func (p *Point) BytesX() ([]byte, error) {
+	f := p.c.f
+	if f.isZero(&p.z) == 1 {
+		return nil, errors.New("P point is the point at infinity")
+	}
+	x, _ := p.affine()
+	return f.bytes(&x), nil
+}
+
+func (p *Point) affine() (x, y fieldElement) {
+	f := p.c.f
+	var zinv fieldElement
+	f.invert(&zinv, &p.z)
+	f.mul(&x, &p.x, &zinv)
+	f.mul(&y, &p.y, &zinv)
+	return x, y
+}
+
+// Add sets q = p1 + p2, and returns q. The points may overlap.
+func (q *Point) Add(p1, p2 *Point) *Point {
+	// Complete addition formula for a = -3 from "Complete addition formulas
+	// for prime order elliptic curves" (https://eprint.iacr.org/2015/1060),
+	// Algorithm 4.
+	f := p1.c.f
+	b := &p1.c.b
+	var t0, t1, t2, t3, t4, x3, y3, z3 fieldElement
+	f.mul(&t0, &p1.x, &p2.x)
+	f.mul(&t1, &p1.y, &p2.y)
+	f.mul(&t2, &p1.z, &p2.z)
+	f.add(&t3, &p1.x, &p1.y)
+	f.add(&t4, &p2.x, &p2.y)
+	f.mul(&t3, &t3, &t4)
+	f.add(&t4, &t0, &t1)
+	f.sub(&t3, &t3, &t4)
+	f.add(&t4, &p1.y, &p1.z)
+	f.add(&x3, &p2.y, &p2.z)
+	f.mul(&t4, &t4, &x3)
+	f.add(&x3, &t1, &t2)
+	f.sub(&t4, &t4, &x3)
+	f.add(&x3, &p1.x, &p1.z)
+	f.add(&y3, &p2.x, &p2.z)
+	f.mul(&x3, &x3, &y3)
+	f.add(&y3, &t0, &t2)
+	f.sub(&y3, &x3, &y3)
+	f.mul(&z3, b, &t2)
+	f.sub(&x3, &y3, &z3)
+	f.add(&z3, &x3, &x3)
+	f.add(&x3, &x3, &z3)
+	f.sub(&z3, &t1, &x3)
+	f.add(&x3, &t1, &x3)
+	f.mul(&y3, b, &y3)
+	f.add(&t1, &t2, &t2)
+	f.add(&t2, &t1, &t2)
+	f.sub(&y3, &y3, &t2)
+	f.sub(&y3, &y3, &t0)
+	f.add(&t1, &y3, &y3)
+	f.add(&y3, &t1, &y3)
+	f.add(&t1, &t0, &t0)
+	f.add(&t0, &t1, &t0)
+	f.sub(&t0, &t0, &t2)
+	f.mul(&t1, &t4, &y3)
+	f.mul(&t2, &t0, &y3)
+	f.mul(&y3, &x3, &z3)
+	f.add(&y3, &y3, &t2)
+	f.mul(&x3, &t3, &x3)
+	f.sub(&x3, &x3, &t1)
+	f.mul(&z3, &t4, &z3)
+	f.mul(&t1, &t3, &t0)
+	f.add(&z3, &z3, &t1)
+
+	q.c = p1.c
+	q.x, q.y, q.z = x3, y3, z3
+	return q
+}
+
+// Double sets q = p + p, and returns q. The points may overlap.
+func (q *Point) Double(p *Point) *Point {
+	// Complete addition formula for a = -3 from "Complete addition formulas
+	// for prime order elliptic curves" (https://eprint.iacr.org/2015/1060),
+	// Algorithm 6.
+	f := p.c.f
+	b := &p.c.b
+	var t0, t1, t2, t3, x3, y3, z3 fieldElement
+	f.square(&t0, &p.x)
+	f.square(&t1, &p.y)
+	f.square(&t2, &p.z)
+	f.mul(&t3, &p.x, &p.y)
+	f.add(&t3, &t3, &t3)
+	f.mul(&z3, &p.x, &p.z)
+	f.add(&z3, &z3, &z3)
+	f.mul(&y3, b, &t2)
+	f.sub(&y3, &y3, &z3)
+	f.add(&x3, &y3, &y3)
+	f.add(&y3, &x3, &y3)
+	f.sub(&x3, &t1, &y3)
+	f.add(&y3, &t1, &y3)
+	f.mul(&y3, &x3, &y3)
+	f.mul(&x3, &x3, &t3)
+	f.add(&t3, &t2, &t2)
+	f.add(&t2, &t2, &t3)
+	f.mul(&z3, b, &z3)
+	f.sub(&z3, &z3, &t2)
+	f.sub(&z3, &z3, &t0)
+	f.add(&t3, &z3, &z3)
+	f.add(&z3, &z3, &t3)
+	f.add(&t3, &t0, &t0)
+	f.add(&t0, &t3, &t0)
+	f.sub(&t0, &t0, &t2)
+	f.mul(&t0, &t0, &z3)
+	f.add(&y3, &y3, &t0)
+	f.mul(&t0, &p.y, &p.z)
+	f.add(&t0, &t0, &t0)
+	f.mul(&z3, &t0, &z3)
+	f.sub(&x3, &x3, &z3)
+	f.mul(&z3, &t0, &t1)
+	f.add(&z3, &z3, &z3)
+	f.add(&z3, &z3, &z3)
+
+	q.c = p.c
+	q.x, q.y, q.z = x3, y3, z3
+	return q
+}
+
+// Select sets q to p1 if cond == 1, and to p2 if cond == 0.
+func (q *Point) Select(p1, p2 *Point, cond int) *Point {
+	f := p1.c.f
+	q.c = p1.c
+	c := uint64(cond)
+	f.selectLimbs(&q.x, &p1.x, &p2.x, c)
+	f.selectLimbs(&q.y, &p1.y, &p2.y, c)
+	f.selectLimbs(&q.z, &p1.z, &p2.z, c)
+	return q
+}
+
+// pointTable is a table of the first 15 multiples of a point, from 1p to 15p.
+type pointTable [15]*Point
+
+// selectInto sets p to the n-th multiple of the table base, or to the point
+// at infinity if n is zero, in constant time.
+func (table *pointTable) selectInto(p *Point, n uint8) {
+	p.Set(p.c.NewPoint())
+	for i := uint8(1); i < 16; i++ {
+		cond := subtle.ConstantTimeByteEq(i, n)
+		p.Select(table[i-1], p, cond)
+	}
+}
+
+// ScalarMult sets p = scalar * q, and returns p. The scalar must be a
+// big-endian value of exactly ScalarSize bytes.
+func (p *Point) ScalarMult(q *Point, scalar []byte) (*Point, error) {
+	c := q.c
+	if len(scalar) != c.f.byteLen {
+		return nil, errors.New("invalid scalar length")
+	}
+
+	var table pointTable
+	table[0] = new(Point).Set(q)
+	for i := 1; i < 15; i += 2 {
+		table[i] = new(Point).Double(table[i/2])
+		table[i+1] = new(Point).Add(table[i], q)
+	}
+
+	// Fixed 4-bit window, from the most significant nibble down.
+	r := c.NewPoint()
+	t := c.NewPoint()
+	for i, b := range scalar {
+		if i != 0 {
+			r.Double(r)
+			r.Double(r)
+			r.Double(r)
+			r.Double(r)
+		}
+		table.selectInto(t, b>>4)
+		r.Add(r, t)
+
+		r.Double(r)
+		r.Double(r)
+		r.Double(r)
+		r.Double(r)
+		table.selectInto(t, b&0b1111)
+		r.Add(r, t)
+	}
+	return p.Set(r), nil
+}
+
+// ScalarBaseMult sets p = scalar * generator, and returns p. The scalar must
+// be a big-endian value of exactly ScalarSize bytes.
+func (p *Point) ScalarBaseMult(scalar []byte) (*Point, error) {
+	return p.ScalarMult(p.c.NewGenerator(), scalar)
+}
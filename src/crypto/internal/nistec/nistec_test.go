@@ -0,0 +1,150 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nistec_test
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"crypto/internal/nistec"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+var curves = []struct {
+	c        *nistec.Curve
+	elliptic elliptic.Curve
+}{
+	{nistec.P256(), elliptic.P256()},
+	{nistec.P384(), elliptic.P384()},
+	{nistec.P521(), elliptic.P521()},
+}
+
+func TestScalarMult(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	for _, tt := range curves {
+		t.Run(tt.c.Name(), func(t *testing.T) {
+			params := tt.elliptic.Params()
+			size := tt.c.ScalarSize()
+			scalars := [][]byte{
+				make([]byte, size),
+				scalarBytes(big.NewInt(1), size),
+				scalarBytes(new(big.Int).Sub(params.N, big.NewInt(1)), size),
+				scalarBytes(params.N, size),
+			}
+			for i := 0; i < 10; i++ {
+				s := new(big.Int).Rand(r, params.N)
+				scalars = append(scalars, scalarBytes(s, size))
+			}
+
+			for _, s := range scalars {
+				p, err := tt.c.NewPoint().ScalarBaseMult(s)
+				if err != nil {
+					t.Fatal(err)
+				}
+				x, y := tt.elliptic.ScalarBaseMult(s)
+				checkPoint(t, tt.elliptic, p, x, y)
+
+				// Multiply a non-generator point too.
+				q, err := tt.c.NewPoint().ScalarMult(p, s)
+				if err != nil {
+					t.Fatal(err)
+				}
+				x, y = tt.elliptic.ScalarMult(x, y, s)
+				checkPoint(t, tt.elliptic, q, x, y)
+			}
+
+			if _, err := tt.c.NewPoint().ScalarBaseMult(make([]byte, size+1)); err == nil {
+				t.Error("ScalarBaseMult accepted a long scalar")
+			}
+		})
+	}
+}
+
+func scalarBytes(n *big.Int, size int) []byte {
+	b := n.Bytes()
+	return append(make([]byte, size-len(b)), b...)
+}
+
+// checkPoint checks that p is (x, y), or the point at infinity if x and y
+// are zero, as crypto/elliptic represents it.
+func checkPoint(t *testing.T, curve elliptic.Curve, p *nistec.Point, x, y *big.Int) {
+	t.Helper()
+	want := []byte{0}
+	if x.Sign() != 0 || y.Sign() != 0 {
+		want = elliptic.Marshal(curve, x, y)
+	}
+	if got := p.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("point = %x, want %x", got, want)
+	}
+}
+
+func TestAddDouble(t *testing.T) {
+	for _, tt := range curves {
+		t.Run(tt.c.Name(), func(t *testing.T) {
+			g := tt.c.NewGenerator()
+			inf := tt.c.NewPoint()
+
+			// Complete formulas handle doubling and the identity in Add.
+			sum := tt.c.NewPoint().Add(g, g)
+			dbl := tt.c.NewPoint().Double(g)
+			if !bytes.Equal(sum.Bytes(), dbl.Bytes()) {
+				t.Errorf("G + G = %x, 2G = %x", sum.Bytes(), dbl.Bytes())
+			}
+			if p := tt.c.NewPoint().Add(g, inf); !bytes.Equal(p.Bytes(), g.Bytes()) {
+				t.Errorf("G + O = %x, want G", p.Bytes())
+			}
+			if p := tt.c.NewPoint().Double(inf); !bytes.Equal(p.Bytes(), []byte{0}) {
+				t.Errorf("2O = %x, want O", p.Bytes())
+			}
+			if _, err := inf.BytesX(); err == nil {
+				t.Error("BytesX of the point at infinity succeeded")
+			}
+		})
+	}
+}
+
+func TestSetBytes(t *testing.T) {
+	for _, tt := range curves {
+		t.Run(tt.c.Name(), func(t *testing.T) {
+			g := tt.c.NewGenerator().Bytes()
+			p, err := tt.c.NewPoint().SetBytes(g)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(p.Bytes(), g) {
+				t.Errorf("round-trip of G = %x, want %x", p.Bytes(), g)
+			}
+			if p, err := tt.c.NewPoint().SetBytes([]byte{0}); err != nil || !bytes.Equal(p.Bytes(), []byte{0}) {
+				t.Errorf("SetBytes of the point at infinity failed: %v", err)
+			}
+
+			notOnCurve := append([]byte{}, g...)
+			notOnCurve[len(notOnCurve)-1] ^= 1
+			size := tt.c.ScalarSize()
+			tooLarge := append([]byte{4}, bytes.Repeat([]byte{0xff}, 2*size)...)
+			compressed := append([]byte{2 | g[len(g)-1]&1}, g[1:1+size]...)
+			for _, b := range [][]byte{nil, g[:len(g)-1], notOnCurve, tooLarge, compressed} {
+				if _, err := tt.c.NewPoint().SetBytes(b); err == nil {
+					t.Errorf("SetBytes(%x) succeeded, expected an error", b)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkScalarMult(b *testing.B) {
+	for _, tt := range curves {
+		b.Run(tt.c.Name(), func(b *testing.B) {
+			scalar := bytes.Repeat([]byte{0xa5}, tt.c.ScalarSize())
+			scalar[0] &= tt.c.Order()[0]
+			p := tt.c.NewGenerator()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				p.ScalarMult(p, scalar)
+			}
+		})
+	}
+}
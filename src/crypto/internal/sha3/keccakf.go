@@ -0,0 +1,80 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sha3
+
+import "math/bits"
+
+// rc stores the round constants for use in the ι step.
+var rc = [24]uint64{
+	0x0000000000000001,
+	0x0000000000008082,
+	0x800000000000808A,
+	0x8000000080008000,
+	0x000000000000808B,
+	0x0000000080000001,
+	0x8000000080008081,
+	0x8000000000008009,
+	0x000000000000008A,
+	0x0000000000000088,
+	0x0000000080008009,
+	0x000000008000000A,
+	0x000000008000808B,
+	0x800000000000008B,
+	0x8000000000008089,
+	0x8000000000008003,
+	0x8000000000008002,
+	0x8000000000000080,
+	0x000000000000800A,
+	0x800000008000000A,
+	0x8000000080008081,
+	0x8000000000008080,
+	0x0000000080000001,
+	0x8000000080008008,
+}
+
+// rotc and piln are the rotation offsets and lane permutation of the ρ and π
+// steps, following the lanes visited from (1, 0).
+var (
+	rotc = [24]int{1, 3, 6, 10, 15, 21, 28, 36, 45, 55, 2, 14, 27, 41, 56, 8, 25, 43, 62, 18, 39, 61, 20, 44}
+	piln = [24]int{10, 7, 11, 17, 18, 3, 5, 16, 8, 21, 24, 4, 15, 23, 19, 13, 12, 2, 20, 14, 22, 9, 6, 1}
+)
+
+// keccakF1600 applies the Keccak permutation to a 1600-bit state, as a
+// 25-lane array indexed by x + 5y.
+func keccakF1600(a *[25]uint64) {
+	var bc [5]uint64
+	for round := 0; round < 24; round++ {
+		// θ step
+		for x := 0; x < 5; x++ {
+			bc[x] = a[x] ^ a[x+5] ^ a[x+10] ^ a[x+15] ^ a[x+20]
+		}
+		for x := 0; x < 5; x++ {
+			d := bc[(x+4)%5] ^ bits.RotateLeft64(bc[(x+1)%5], 1)
+			for y := 0; y < 25; y += 5 {
+				a[y+x] ^= d
+			}
+		}
+
+		// ρ and π steps
+		t := a[1]
+		for i := 0; i < 24; i++ {
+			j := piln[i]
+			t, a[j] = a[j], bits.RotateLeft64(t, rotc[i])
+		}
+
+		// χ step
+		for y := 0; y < 25; y += 5 {
+			for x := 0; x < 5; x++ {
+				bc[x] = a[y+x]
+			}
+			for x := 0; x < 5; x++ {
+				a[y+x] ^= ^bc[(x+1)%5] & bc[(x+2)%5]
+			}
+		}
+
+		// ι step
+		a[0] ^= rc[round]
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package sha3 implements the SHA-3 fixed-output-length hash functions and
+// the SHAKE variable-output-length functions defined by FIPS 202.
+//
+// It is a minimal, portable implementation for use by other crypto packages,
+// such as crypto/internal/mlkem768.
+package sha3
+
+import (
+	"encoding/binary"
+	"hash"
+)
+
+const (
+	// dsbyte values for the domain separation of SHA-3 and SHAKE.
+	dsbyteSHA3  = 0x06
+	dsbyteShake = 0x1f
+)
+
+// state is a Keccak sponge. It absorbs input until the first call to Read
+// or Sum, and squeezes output afterwards.
+type state struct {
+	a         [25]uint64
+	buf       [200]byte // pending input or output bytes of the current block
+	n         int       // number of bytes of buf in use
+	rate      int       // block size in bytes
+	outputLen int       // default output length for Sum, in bytes
+	dsbyte    byte
+	squeezing bool
+}
+
+// BlockSize returns the rate of the sponge.
+func (d *state) BlockSize() int { return d.rate }
+
+// Size returns the output size of the hash function in bytes.
+func (d *state) Size() int { return d.outputLen }
+
+// Reset clears the internal state.
+func (d *state) Reset() {
+	d.a = [25]uint64{}
+	d.buf = [200]byte{}
+	d.n = 0
+	d.squeezing = false
+}
+
+func (d *state) clone() *state {
+	c := *d
+	return &c
+}
+
+// permute XORs the rate bytes of buf into the state and applies the
+// permutation.
+func (d *state) permute() {
+	for i := 0; i < d.rate/8; i++ {
+		d.a[i] ^= binary.LittleEndian.Uint64(d.buf[8*i:])
+	}
+	keccakF1600(&d.a)
+}
+
+// Write absorbs more data into the state. It panics if called after Read.
+func (d *state) Write(p []byte) (int, error) {
+	if d.squeezing {
+		panic("sha3: Write after Read")
+	}
+	n := len(p)
+	for len(p) > 0 {
+		c := copy(d.buf[d.n:d.rate], p)
+		d.n += c
+		p = p[c:]
+		if d.n == d.rate {
+			d.permute()
+			d.n = 0
+		}
+	}
+	return n, nil
+}
+
+// padAndPermute appends the domain separation bits and the final padding
+// bit, and switches the sponge to squeezing.
+func (d *state) padAndPermute() {
+	for i := d.n; i < d.rate; i++ {
+		d.buf[i] = 0
+	}
+	d.buf[d.n] ^= d.dsbyte
+	d.buf[d.rate-1] ^= 0x80
+	d.permute()
+	d.fillOutput()
+	d.squeezing = true
+}
+
+// fillOutput copies the rate bytes of the state into buf for squeezing.
+func (d *state) fillOutput() {
+	for i := 0; i < d.rate/8; i++ {
+		binary.LittleEndian.PutUint64(d.buf[8*i:], d.a[i])
+	}
+	d.n = 0
+}
+
+// Read squeezes an arbitrary number of bytes from the sponge.
+func (d *state) Read(out []byte) (int, error) {
+	if !d.squeezing {
+		d.padAndPermute()
+	}
+	n := len(out)
+	for len(out) > 0 {
+		if d.n == d.rate {
+			keccakF1600(&d.a)
+			d.fillOutput()
+		}
+		c := copy(out, d.buf[d.n:d.rate])
+		d.n += c
+		out = out[c:]
+	}
+	return n, nil
+}
+
+// Sum appends the hash of the data written so far to b. It does not change
+// the underlying state. It panics if called after Read.
+func (d *state) Sum(b []byte) []byte {
+	if d.squeezing {
+		panic("sha3: Sum after Read")
+	}
+	out := make([]byte, d.outputLen)
+	d.clone().Read(out)
+	return append(b, out...)
+}
+
+// New256 returns a new hash.Hash computing the SHA3-256 hash.
+func New256() hash.Hash {
+	return &state{rate: 136, outputLen: 32, dsbyte: dsbyteSHA3}
+}
+
+// New512 returns a new hash.Hash computing the SHA3-512 hash.
+func New512() hash.Hash {
+	return &state{rate: 72, outputLen: 64, dsbyte: dsbyteSHA3}
+}
+
+// Sum256 returns the SHA3-256 digest of the data.
+func Sum256(data []byte) (digest [32]byte) {
+	h := New256()
+	h.Write(data)
+	h.Sum(digest[:0])
+	return
+}
+
+// Sum512 returns the SHA3-512 digest of the data.
+func Sum512(data []byte) (digest [64]byte) {
+	h := New512()
+	h.Write(data)
+	h.Sum(digest[:0])
+	return
+}
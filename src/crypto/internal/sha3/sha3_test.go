@@ -0,0 +1,100 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sha3
+
+import (
+	"bytes"
+	"encoding/hex"
+	"hash"
+	"strings"
+	"testing"
+)
+
+func newShake128() hash.Hash { return NewShake128() }
+func newShake256() hash.Hash { return NewShake256() }
+
+var hashTests = []struct {
+	newHash func() hash.Hash
+	in      string
+	out     string
+}{
+	{New256, "",
+		"a7ffc6f8bf1ed76651c14756a061d662f580ff4de43b49fa82d80a4b80f8434a"},
+	{New256, "abc",
+		"3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532"},
+	{New256, strings.Repeat("a", 1000),
+		"8f3934e6f7a15698fe0f396b95d8c4440929a8fa6eae140171c068b4549fbf81"},
+	{New512, "",
+		"a69f73cca23a9ac5c8b567dc185a756e97c982164fe25859e0d1dcc1475c80a615b2123af1f5f94c11e3e9402c3ac558f500199d95b6d3e301758586281dcd26"},
+	{New512, "abc",
+		"b751850b1a57168a5693cd924b6b096e08f621827444f70d884f5d0240d2712e10e116e9192af3c91a7ec57647e3934057340b4cf408d5a56592f8274eec53f0"},
+	{New512, strings.Repeat("a", 1000),
+		"ac7e95cc95aa7f24aaa95e040ca0c79b39cd9cc84a10abb84ddd8dd5e4b45cf96543aaa70d0ef99fbf8d2769639981ee1fd0b0276f4756b9d504d0b7de19b700"},
+	{newShake128, "",
+		"7f9c2ba4e88f827d616045507605853ed73b8093f6efbc88eb1a6eacfa66ef26"},
+	{newShake128, "abc",
+		"5881092dd818bf5cf8a3ddb793fbcba74097d5c526a6d35f97b83351940f2cc8"},
+	{newShake128, strings.Repeat("a", 1000),
+		"c340a5d49d81d4dcf3e6fa3387202b9b67e8ab78482f9956be63d1f09b9cb436"},
+	{newShake256, "",
+		"46b9dd2b0ba88d13233b3feb743eeb243fcd52ea62b81b82b50c27646ed5762fd75dc4ddd8c0f200cb05019d67b592f6fc821c49479ab48640292eacb3b7c4be"},
+	{newShake256, "abc",
+		"483366601360a8771c6863080cc4114d8db44530f8f1e1ee4f94ea37e78b5739d5a15bef186a5386c75744c0527e1faa9f8726e462a12a4feb06bd8801e751e4"},
+	{newShake256, strings.Repeat("a", 1000),
+		"e262331ad290c96ab1c0fa045470244b415ba6696a934d60f2999b8e92aaa24ee8eb039abd7af7d64fde39fa73267b02fdd3a50e1b8651b846a9bb2cc4f344c5"},
+}
+
+func TestHashes(t *testing.T) {
+	for _, tt := range hashTests {
+		h := tt.newHash()
+		h.Write([]byte(tt.in))
+		if got := hex.EncodeToString(h.Sum(nil)); got != tt.out {
+			t.Errorf("hash of %.10q = %s, want %s", tt.in, got, tt.out)
+		}
+		// Sum must not change the state.
+		if got := hex.EncodeToString(h.Sum(nil)); got != tt.out {
+			t.Errorf("second Sum of %.10q = %s, want %s", tt.in, got, tt.out)
+		}
+
+		// Writing one byte at a time must produce the same hash.
+		h.Reset()
+		for i := 0; i < len(tt.in); i++ {
+			h.Write([]byte{tt.in[i]})
+		}
+		if got := hex.EncodeToString(h.Sum(nil)); got != tt.out {
+			t.Errorf("incremental hash of %.10q = %s, want %s", tt.in, got, tt.out)
+		}
+	}
+}
+
+func TestShakeRead(t *testing.T) {
+	// Read across several blocks, in uneven chunks.
+	want, _ := hex.DecodeString("aa3d3b78e3f2061adcdead407085901803ec6f17f0ec650a292198275211a56b")
+	h := NewShake128()
+	h.Write([]byte("abc"))
+	clone := h.Clone()
+	out := make([]byte, 500)
+	for i := 0; i < len(out); i += 7 {
+		end := i + 7
+		if end > len(out) {
+			end = len(out)
+		}
+		h.Read(out[i:end])
+	}
+	if !bytes.Equal(out[len(out)-32:], want) {
+		t.Errorf("SHAKE128 output bytes 468-500 = %x, want %x", out[len(out)-32:], want)
+	}
+	one := make([]byte, 500)
+	clone.Read(one)
+	if !bytes.Equal(one, out) {
+		t.Errorf("reading at once and in chunks produced different output")
+	}
+
+	buf := make([]byte, 16)
+	ShakeSum256(buf, []byte(strings.Repeat("a", 1000)))
+	if got, want := hex.EncodeToString(buf), hashTests[len(hashTests)-1].out[:32]; got != want {
+		t.Errorf("ShakeSum256 = %s, want %s", got, want)
+	}
+}
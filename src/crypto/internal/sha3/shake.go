@@ -0,0 +1,53 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sha3
+
+import "hash"
+
+// A ShakeHash is a hash.Hash that can also produce output of arbitrary
+// length. Sum returns Size bytes of output without changing the state, while
+// Read squeezes output and must not be followed by Write.
+type ShakeHash interface {
+	hash.Hash
+
+	// Read reads more output from the hash. It never returns an error, but
+	// subsequent calls to Write panic.
+	Read(p []byte) (n int, err error)
+
+	// Clone returns a copy of the ShakeHash in its current state.
+	Clone() ShakeHash
+}
+
+type shake struct {
+	*state
+}
+
+func (s shake) Clone() ShakeHash {
+	return shake{s.clone()}
+}
+
+// NewShake128 creates a new SHAKE128 XOF. Its Sum method returns 32 bytes.
+func NewShake128() ShakeHash {
+	return shake{&state{rate: 168, outputLen: 32, dsbyte: dsbyteShake}}
+}
+
+// NewShake256 creates a new SHAKE256 XOF. Its Sum method returns 64 bytes.
+func NewShake256() ShakeHash {
+	return shake{&state{rate: 136, outputLen: 64, dsbyte: dsbyteShake}}
+}
+
+// ShakeSum128 writes an arbitrary-length digest of data into hash.
+func ShakeSum128(hash, data []byte) {
+	h := NewShake128()
+	h.Write(data)
+	h.Read(hash)
+}
+
+// ShakeSum256 writes an arbitrary-length digest of data into hash.
+func ShakeSum256(hash, data []byte) {
+	h := NewShake256()
+	h.Write(data)
+	h.Read(hash)
+}
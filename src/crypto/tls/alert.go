@@ -42,6 +42,7 @@ const (
 	alertUnsupportedExtension   alert = 110
 	alertUnrecognizedName       alert = 112
 	alertNoApplicationProtocol  alert = 120
+	alertECHRequired            alert = 121
 )
 
 var alertText = map[alert]string{
@@ -72,6 +73,7 @@ var alertText = map[alert]string{
 	alertUnsupportedExtension:   "unsupported extension",
 	alertUnrecognizedName:       "unrecognized name",
 	alertNoApplicationProtocol:  "no application protocol",
+	alertECHRequired:            "encrypted client hello required",
 }
 
 func (e alert) String() string {
@@ -100,6 +100,9 @@ const (
 	extensionCertificateAuthorities  uint16 = 47
 	extensionSignatureAlgorithmsCert uint16 = 50
 	extensionKeyShare                uint16 = 51
+	extensionQUICTransportParameters uint16 = 57
+	extensionECHOuterExtensions      uint16 = 0xfd00
+	extensionEncryptedClientHello    uint16 = 0xfe0d
 	extensionRenegotiationInfo       uint16 = 0xff01
 )
 
@@ -112,7 +115,8 @@ const (
 // https://www.iana.org/assignments/tls-parameters/tls-parameters.xml#tls-parameters-8.
 //
 // In TLS 1.3, this type is called NamedGroup, but at this time this library
-// only supports Elliptic Curve based groups. See RFC 8446, Section 4.2.7.
+// only supports Elliptic Curve based groups and the X25519MLKEM768 hybrid.
+// See RFC 8446, Section 4.2.7.
 type CurveID uint16
 
 const (
@@ -120,6 +124,12 @@ const (
 	CurveP384 CurveID = 24
 	CurveP521 CurveID = 25
 	X25519    CurveID = 29
+
+	// X25519MLKEM768 is the hybrid post-quantum key exchange combining
+	// ML-KEM-768 and X25519, as specified by draft-kwiatkowski-tls-ecdhe-mlkem.
+	// It can only be used in TLS 1.3, and is not enabled by default: it must
+	// be listed in Config.CurvePreferences.
+	X25519MLKEM768 CurveID = 4588
 )
 
 // TLS 1.3 Key Share. See RFC 8446, Section 4.2.8.
@@ -221,6 +231,7 @@ type ConnectionState struct {
 	VerifiedChains              [][]*x509.Certificate // verified chains built from PeerCertificates
 	SignedCertificateTimestamps [][]byte              // SCTs from the peer, if any
 	OCSPResponse                []byte                // stapled OCSP response from peer, if any
+	ECHAccepted                 bool                  // the server accepted Encrypted Client Hello
 
 	// ekm is a closure exposed via ExportKeyingMaterial.
 	ekm func(label string, context []byte, length int) ([]byte, error)
@@ -277,9 +288,11 @@ type ClientSessionState struct {
 	receivedAt         time.Time             // When the session ticket was received from the server
 
 	// TLS 1.3 fields.
-	nonce  []byte    // Ticket nonce sent by the server, to derive PSK
-	useBy  time.Time // Expiration of the ticket lifetime as set by the server
-	ageAdd uint32    // Random obfuscation factor for sending the ticket age
+	nonce        []byte    // Ticket nonce sent by the server, to derive PSK
+	useBy        time.Time // Expiration of the ticket lifetime as set by the server
+	ageAdd       uint32    // Random obfuscation factor for sending the ticket age
+	earlyData    bool      // Whether the ticket allows QUIC 0-RTT
+	alpnProtocol string    // ALPN protocol negotiated for the session, for 0-RTT
 }
 
 // ClientSessionCache is a cache of ClientSessionState objects that can be used
@@ -597,6 +610,9 @@ type Config struct {
 	// an ECDHE handshake, in preference order. If empty, the default will
 	// be used. The client will use the first preference as the type for
 	// its key share in TLS 1.3. This may change in the future.
+	//
+	// TLS 1.3-only groups, such as X25519MLKEM768, are ignored in TLS 1.2
+	// handshakes.
 	CurvePreferences []CurveID
 
 	// DynamicRecordSizingDisabled disables adaptive sizing of TLS records.
@@ -617,6 +633,54 @@ type Config struct {
 	// used for debugging.
 	KeyLogWriter io.Writer
 
+	// EncryptedClientHelloConfigList is a serialized ECHConfigList. If
+	// provided, clients will attempt to connect to servers using Encrypted
+	// Client Hello (ECH) using one of the provided ECHConfigs.
+	//
+	// Servers do not use this field. In order to configure ECH for servers,
+	// see the EncryptedClientHelloKeys field.
+	//
+	// If the list contains no valid ECH configs, the handshake will fail
+	// and return an error.
+	//
+	// If EncryptedClientHelloConfigList is set, MinVersion, if set, must
+	// be VersionTLS13.
+	//
+	// When EncryptedClientHelloConfigList is set, the handshake will only
+	// succeed if ECH is successfully negotiated. If the server rejects ECH,
+	// an ECHRejectionError error will be returned, which may contain a new
+	// ECHConfigList that the server suggests using.
+	//
+	// How this field is parsed may change in future Go versions, if the
+	// encoding described in the final Encrypted Client Hello RFC changes.
+	EncryptedClientHelloConfigList []byte
+
+	// EncryptedClientHelloRejectionVerify, if not nil, is called when ECH is
+	// rejected by the remote server, in order to verify the ECH provider
+	// certificate in the outer ClientHello. If it returns a non-nil error, the
+	// handshake is aborted and that error results.
+	//
+	// On the server side this field is not used.
+	//
+	// Unlike VerifyPeerCertificate, it is called even if InsecureSkipVerify
+	// is set, and the normal verification of the certificate against the
+	// public name of the ECH config is not performed.
+	EncryptedClientHelloRejectionVerify func(ConnectionState) error
+
+	// EncryptedClientHelloKeys are the ECH keys to use when a client
+	// attempts ECH.
+	//
+	// If EncryptedClientHelloKeys is set, MinVersion, if set, must be
+	// VersionTLS13.
+	//
+	// If a client attempts ECH, but it is rejected by the server, the server
+	// will send a list of configs to retry based on the set of
+	// EncryptedClientHelloKeys which have the SendAsRetry field set.
+	//
+	// On the client side, this field is ignored. In order to configure ECH
+	// for clients, see the EncryptedClientHelloConfigList field.
+	EncryptedClientHelloKeys []EncryptedClientHelloKey
+
 	serverInitOnce sync.Once // guards calling (*Config).serverInit
 
 	// mutex protects sessionTicketKeys.
@@ -695,6 +759,10 @@ func (c *Config) Clone() *Config {
 		Renegotiation:               c.Renegotiation,
 		KeyLogWriter:                c.KeyLogWriter,
 		sessionTicketKeys:           sessionTicketKeys,
+
+		EncryptedClientHelloConfigList:      c.EncryptedClientHelloConfigList,
+		EncryptedClientHelloRejectionVerify: c.EncryptedClientHelloRejectionVerify,
+		EncryptedClientHelloKeys:            c.EncryptedClientHelloKeys,
 	}
 }
 
@@ -837,6 +905,12 @@ func (c *Config) curvePreferences() []CurveID {
 	return c.CurvePreferences
 }
 
+// isTLS13OnlyKeyExchange returns whether curve can only be negotiated in
+// TLS 1.3, and must be ignored by the TLS 1.2 ECDHE key agreement.
+func isTLS13OnlyKeyExchange(curve CurveID) bool {
+	return curve == X25519MLKEM768
+}
+
 func (c *Config) supportsCurve(curve CurveID) bool {
 	for _, cc := range c.curvePreferences() {
 		if cc == curve {
@@ -0,0 +1,527 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tls
+
+import (
+	"bytes"
+	"crypto/internal/hpke"
+	"errors"
+	"hash"
+	"net"
+
+	"golang.org/x/crypto/cryptobyte"
+)
+
+// This file implements Encrypted Client Hello, as specified in
+// draft-ietf-tls-esni-18. Only the DHKEM(X25519, HKDF-SHA256) KEM and the
+// HKDF-SHA256 KDF are supported.
+
+// An EncryptedClientHelloKey holds a private key that is associated
+// with a specific ECH config known to a client.
+type EncryptedClientHelloKey struct {
+	// Config should be a marshalled ECHConfig associated with PrivateKey. This
+	// must match the config provided to clients byte-for-byte. The config
+	// should only specify the DHKEM(X25519, HKDF-SHA256) KEM ID (0x0020), the
+	// HKDF-SHA256 KDF ID (0x0001), and a subset of the following AEAD IDs:
+	// AES-128-GCM (0x0001), AES-256-GCM (0x0002), ChaCha20Poly1305 (0x0003).
+	Config []byte
+	// PrivateKey should be a marshalled X25519 private key associated with
+	// the public key in Config.
+	PrivateKey []byte
+	// SendAsRetry indicates if Config should be sent as part of the list of
+	// retry configs when ECH is requested by the client but rejected by the
+	// server.
+	SendAsRetry bool
+}
+
+// ECHRejectionError is the error type returned when ECH is rejected by a
+// remote server. If the server offered a ECHConfigList to use for retries,
+// the RetryConfigList field will contain this list.
+//
+// The client may treat an ECHRejectionError with an empty set of
+// RetryConfigs as a secure signal from the server.
+type ECHRejectionError struct {
+	RetryConfigList []byte
+}
+
+func (e *ECHRejectionError) Error() string {
+	return "tls: server rejected ECH"
+}
+
+// Types of the encrypted_client_hello extension, see
+// draft-ietf-tls-esni-18, Section 5.
+const (
+	outerECHExt uint8 = 0
+	innerECHExt uint8 = 1
+)
+
+type echCipher struct {
+	kdfID  uint16
+	aeadID uint16
+}
+
+type echExtension struct {
+	typ  uint16
+	data []byte
+}
+
+type echConfig struct {
+	raw []byte
+
+	configID      uint8
+	kemID         uint16
+	publicKey     []byte
+	cipherSuites  []echCipher
+	maxNameLength uint8
+	publicName    string
+	extensions    []echExtension
+}
+
+var errMalformedECHConfig = errors.New("tls: malformed ECHConfigList")
+
+// readECHConfig reads a single ECHConfig from s. It reports skip if the
+// config has an unknown version and should be ignored.
+func readECHConfig(s *cryptobyte.String) (ec echConfig, skip bool, err error) {
+	start := *s
+	var version uint16
+	var contents cryptobyte.String
+	if !s.ReadUint16(&version) || !s.ReadUint16LengthPrefixed(&contents) {
+		return echConfig{}, false, errMalformedECHConfig
+	}
+	if version != extensionEncryptedClientHello {
+		return echConfig{}, true, nil
+	}
+	ec.raw = start[:len(start)-len(*s)]
+
+	var publicKey, cipherSuites, publicName, extensions cryptobyte.String
+	if !contents.ReadUint8(&ec.configID) ||
+		!contents.ReadUint16(&ec.kemID) ||
+		!contents.ReadUint16LengthPrefixed(&publicKey) || publicKey.Empty() ||
+		!contents.ReadUint16LengthPrefixed(&cipherSuites) || cipherSuites.Empty() ||
+		!contents.ReadUint8(&ec.maxNameLength) ||
+		!contents.ReadUint8LengthPrefixed(&publicName) || publicName.Empty() ||
+		!contents.ReadUint16LengthPrefixed(&extensions) ||
+		!contents.Empty() {
+		return echConfig{}, false, errMalformedECHConfig
+	}
+	ec.publicKey = publicKey
+	ec.publicName = string(publicName)
+	for !cipherSuites.Empty() {
+		var c echCipher
+		if !cipherSuites.ReadUint16(&c.kdfID) || !cipherSuites.ReadUint16(&c.aeadID) {
+			return echConfig{}, false, errMalformedECHConfig
+		}
+		ec.cipherSuites = append(ec.cipherSuites, c)
+	}
+	for !extensions.Empty() {
+		var e echExtension
+		var data cryptobyte.String
+		if !extensions.ReadUint16(&e.typ) || !extensions.ReadUint16LengthPrefixed(&data) {
+			return echConfig{}, false, errMalformedECHConfig
+		}
+		e.data = data
+		ec.extensions = append(ec.extensions, e)
+	}
+
+	return ec, false, nil
+}
+
+// parseECHConfig parses a single marshalled ECHConfig, as found in
+// EncryptedClientHelloKey.Config.
+func parseECHConfig(data []byte) (ec echConfig, skip bool, err error) {
+	s := cryptobyte.String(data)
+	ec, skip, err = readECHConfig(&s)
+	if err == nil && !s.Empty() {
+		return echConfig{}, false, errMalformedECHConfig
+	}
+	return ec, skip, err
+}
+
+// parseECHConfigList parses a draft-ietf-tls-esni-18 ECHConfigList,
+// returning the configs with a supported version.
+func parseECHConfigList(data []byte) ([]echConfig, error) {
+	s := cryptobyte.String(data)
+	var list cryptobyte.String
+	if !s.ReadUint16LengthPrefixed(&list) || !s.Empty() || list.Empty() {
+		return nil, errMalformedECHConfig
+	}
+	var configs []echConfig
+	for !list.Empty() {
+		ec, skip, err := readECHConfig(&list)
+		if err != nil {
+			return nil, err
+		}
+		if !skip {
+			configs = append(configs, ec)
+		}
+	}
+	return configs, nil
+}
+
+// pickECHConfig returns the first config in list that uses a supported KEM
+// and cipher suite and has no mandatory extensions, or nil if there is none.
+func pickECHConfig(list []echConfig) *echConfig {
+	for i := range list {
+		config := &list[i]
+		if !hpke.SupportedKEMs[config.kemID] {
+			continue
+		}
+		if _, err := pickECHCipherSuite(config.cipherSuites); err != nil {
+			continue
+		}
+		// The public name must be a DNS name, not an IP address.
+		if net.ParseIP(config.publicName) != nil {
+			continue
+		}
+		mandatoryExtension := false
+		for _, ext := range config.extensions {
+			// Extensions with the high bit set are mandatory, and since
+			// none are supported the config must be ignored.
+			if ext.typ&0x8000 != 0 {
+				mandatoryExtension = true
+				break
+			}
+		}
+		if mandatoryExtension {
+			continue
+		}
+		return config
+	}
+	return nil
+}
+
+func pickECHCipherSuite(suites []echCipher) (echCipher, error) {
+	for _, s := range suites {
+		if hpke.SupportedKDFs[s.kdfID] && hpke.SupportedAEADs[s.aeadID] {
+			return s, nil
+		}
+	}
+	return echCipher{}, errors.New("tls: no supported ECH cipher suite")
+}
+
+// echInfo returns the HPKE info parameter for the given marshalled ECHConfig.
+func echInfo(config []byte) []byte {
+	info := append([]byte("tls ech"), 0)
+	return append(info, config...)
+}
+
+// echClientContext is the state a client keeps for an ECH offer.
+type echClientContext struct {
+	config          *echConfig
+	hpkeContext     *hpke.Sender
+	encapsulatedKey []byte
+	innerHello      *clientHelloMsg
+	innerTranscript hash.Hash
+	kdfID           uint16
+	aeadID          uint16
+	echRejected     bool
+	retryConfigs    []byte
+}
+
+// echServerContext is the state a server keeps after accepting ECH.
+type echServerContext struct {
+	hpkeContext *hpke.Recipient
+	configID    uint8
+	cipherSuite echCipher
+}
+
+// encodeInnerClientHello marshals inner as an EncodedClientHelloInner,
+// padded as recommended by draft-ietf-tls-esni-18, Section 6.1.3.
+// No extensions are compressed with ech_outer_extensions.
+func encodeInnerClientHello(inner *clientHelloMsg, maxNameLength int) []byte {
+	h := *inner
+	h.raw = nil
+	h.sessionId = nil
+	encoded := h.marshal()[4:]
+
+	var paddingLen int
+	if inner.serverName != "" {
+		paddingLen = maxNameLength - len(inner.serverName)
+		if paddingLen < 0 {
+			paddingLen = 0
+		}
+	} else {
+		paddingLen = maxNameLength + 9
+	}
+	paddingLen += 31 - ((len(encoded) + paddingLen - 1) % 32)
+
+	return append(encoded, make([]byte, paddingLen)...)
+}
+
+func generateOuterECHExt(configID uint8, kdfID, aeadID uint16, encodedKey, payload []byte) []byte {
+	var b cryptobyte.Builder
+	b.AddUint8(outerECHExt)
+	b.AddUint16(kdfID)
+	b.AddUint16(aeadID)
+	b.AddUint8(configID)
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddBytes(encodedKey)
+	})
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddBytes(payload)
+	})
+	return b.BytesOrPanic()
+}
+
+// computeAndUpdateOuterECHExtension encrypts inner and stores the result in
+// the encrypted_client_hello extension of outer. The encapsulated key is
+// only sent in the first ClientHello, so useKey is false after a
+// HelloRetryRequest.
+func computeAndUpdateOuterECHExtension(outer, inner *clientHelloMsg, ech *echClientContext, useKey bool) error {
+	var encapKey []byte
+	if useKey {
+		encapKey = ech.encapsulatedKey
+	}
+	encodedInner := encodeInnerClientHello(inner, int(ech.config.maxNameLength))
+
+	// The payload is authenticated along with the rest of the outer
+	// ClientHello, with the payload itself replaced by zeroes. All the
+	// supported AEADs have a 16 byte tag.
+	encryptedLen := len(encodedInner) + 16
+	outer.encryptedClientHello = generateOuterECHExt(ech.config.configID, ech.kdfID, ech.aeadID, encapKey, make([]byte, encryptedLen))
+	outer.raw = nil
+	aad := outer.marshal()[4:]
+	encryptedInner, err := ech.hpkeContext.Seal(aad, encodedInner)
+	if err != nil {
+		return err
+	}
+	outer.encryptedClientHello = generateOuterECHExt(ech.config.configID, ech.kdfID, ech.aeadID, encapKey, encryptedInner)
+	outer.raw = nil
+	return nil
+}
+
+// echAcceptConfirmation computes the eight byte confirmation signal of
+// draft-ietf-tls-esni-18, Section 7.2, over transcript, which must already
+// include the ServerHello or HelloRetryRequest with the signal zeroed.
+func echAcceptConfirmation(suite *cipherSuiteTLS13, innerRandom []byte, label string, transcript hash.Hash) []byte {
+	return suite.expandLabel(suite.extract(innerRandom, nil), label, transcript.Sum(nil), 8)
+}
+
+const (
+	echAcceptConfirmationLabel    = "ech accept confirmation"
+	echHRRAcceptConfirmationLabel = "hrr ech accept confirmation"
+)
+
+// hrrWithoutECHConfirmation returns a copy of the marshalled
+// HelloRetryRequest hrr with the contents of its encrypted_client_hello
+// extension replaced by zeroes.
+func hrrWithoutECHConfirmation(hrr []byte) ([]byte, bool) {
+	out := append([]byte{}, hrr...)
+	s := cryptobyte.String(out[4:])
+	var sessionID, extensions cryptobyte.String
+	if !s.Skip(2+32) || !s.ReadUint8LengthPrefixed(&sessionID) ||
+		!s.Skip(2+1) || !s.ReadUint16LengthPrefixed(&extensions) {
+		return nil, false
+	}
+	for !extensions.Empty() {
+		var typ uint16
+		var data cryptobyte.String
+		if !extensions.ReadUint16(&typ) || !extensions.ReadUint16LengthPrefixed(&data) {
+			return nil, false
+		}
+		if typ == extensionEncryptedClientHello {
+			// data aliases out, so this zeroes the signal in place.
+			for i := range data {
+				data[i] = 0
+			}
+			return out, true
+		}
+	}
+	return nil, false
+}
+
+// parseECHExt parses the encrypted_client_hello extension of a ClientHello.
+func parseECHExt(ext []byte) (echType uint8, cs echCipher, configID uint8, encap, payload []byte, err error) {
+	data := cryptobyte.String(ext)
+	if !data.ReadUint8(&echType) {
+		return 0, echCipher{}, 0, nil, nil, errors.New("tls: malformed encrypted_client_hello extension")
+	}
+	if echType == innerECHExt {
+		if !data.Empty() {
+			return 0, echCipher{}, 0, nil, nil, errors.New("tls: malformed encrypted_client_hello extension")
+		}
+		return echType, echCipher{}, 0, nil, nil, nil
+	}
+	if echType != outerECHExt {
+		return 0, echCipher{}, 0, nil, nil, errors.New("tls: unknown encrypted_client_hello extension type")
+	}
+	var encapStr, payloadStr cryptobyte.String
+	if !data.ReadUint16(&cs.kdfID) || !data.ReadUint16(&cs.aeadID) ||
+		!data.ReadUint8(&configID) ||
+		!data.ReadUint16LengthPrefixed(&encapStr) ||
+		!data.ReadUint16LengthPrefixed(&payloadStr) || payloadStr.Empty() ||
+		!data.Empty() {
+		return 0, echCipher{}, 0, nil, nil, errors.New("tls: malformed encrypted_client_hello extension")
+	}
+	return echType, cs, configID, encapStr, payloadStr, nil
+}
+
+// decryptECHPayload opens the encrypted ClientHelloInner payload of the
+// marshalled outer ClientHello hello.
+func decryptECHPayload(context *hpke.Recipient, hello, payload []byte) ([]byte, error) {
+	aad := bytes.Replace(hello[4:], payload, make([]byte, len(payload)), 1)
+	return context.Open(aad, payload)
+}
+
+// clientHelloExtensions returns the extensions of the marshalled
+// ClientHello hello, in order.
+func clientHelloExtensions(hello []byte) ([]echExtension, bool) {
+	s := cryptobyte.String(hello)
+	var sessionID, cipherSuites, compressionMethods, extensions cryptobyte.String
+	if !s.Skip(4+2+32) || !s.ReadUint8LengthPrefixed(&sessionID) ||
+		!s.ReadUint16LengthPrefixed(&cipherSuites) ||
+		!s.ReadUint8LengthPrefixed(&compressionMethods) {
+		return nil, false
+	}
+	if s.Empty() {
+		return nil, true
+	}
+	if !s.ReadUint16LengthPrefixed(&extensions) || !s.Empty() {
+		return nil, false
+	}
+	var exts []echExtension
+	for !extensions.Empty() {
+		var e echExtension
+		var data cryptobyte.String
+		if !extensions.ReadUint16(&e.typ) || !extensions.ReadUint16LengthPrefixed(&data) {
+			return nil, false
+		}
+		e.data = data
+		exts = append(exts, e)
+	}
+	return exts, true
+}
+
+var errInvalidInnerClientHello = errors.New("tls: client sent invalid ClientHelloInner")
+
+// decodeInnerClientHello reconstructs the ClientHelloInner from its
+// encoding, restoring the legacy_session_id and any extensions compressed
+// with ech_outer_extensions from outer.
+func decodeInnerClientHello(outer *clientHelloMsg, encoded []byte) (*clientHelloMsg, error) {
+	s := cryptobyte.String(encoded)
+	var vers uint16
+	var random []byte
+	var sessionID, cipherSuites, compressionMethods, extensions cryptobyte.String
+	if !s.ReadUint16(&vers) || !s.ReadBytes(&random, 32) ||
+		!s.ReadUint8LengthPrefixed(&sessionID) || !sessionID.Empty() ||
+		!s.ReadUint16LengthPrefixed(&cipherSuites) ||
+		!s.ReadUint8LengthPrefixed(&compressionMethods) ||
+		!s.ReadUint16LengthPrefixed(&extensions) {
+		return nil, errInvalidInnerClientHello
+	}
+	// The rest is padding, which must be all zeroes.
+	for _, b := range s {
+		if b != 0 {
+			return nil, errInvalidInnerClientHello
+		}
+	}
+
+	outerExts, ok := clientHelloExtensions(outer.marshal())
+	if !ok {
+		return nil, errInvalidInnerClientHello
+	}
+
+	var b cryptobyte.Builder
+	b.AddUint8(typeClientHello)
+	b.AddUint24LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddUint16(vers)
+		b.AddBytes(random)
+		b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) {
+			b.AddBytes(outer.sessionId)
+		})
+		b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+			b.AddBytes(cipherSuites)
+		})
+		b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) {
+			b.AddBytes(compressionMethods)
+		})
+		b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+			// Referenced outer extensions must appear in the same order as
+			// in the outer ClientHello, see draft-ietf-tls-esni-18, Section 5.1.
+			next := 0
+			for !extensions.Empty() {
+				var typ uint16
+				var data cryptobyte.String
+				if !extensions.ReadUint16(&typ) || !extensions.ReadUint16LengthPrefixed(&data) {
+					b.SetError(errInvalidInnerClientHello)
+					return
+				}
+				if typ != extensionECHOuterExtensions {
+					b.AddUint16(typ)
+					b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+						b.AddBytes(data)
+					})
+					continue
+				}
+				var refs cryptobyte.String
+				if !data.ReadUint8LengthPrefixed(&refs) || refs.Empty() || !data.Empty() {
+					b.SetError(errInvalidInnerClientHello)
+					return
+				}
+				for !refs.Empty() {
+					var ref uint16
+					if !refs.ReadUint16(&ref) || ref == extensionEncryptedClientHello {
+						b.SetError(errInvalidInnerClientHello)
+						return
+					}
+					for next < len(outerExts) && outerExts[next].typ != ref {
+						next++
+					}
+					if next == len(outerExts) {
+						b.SetError(errInvalidInnerClientHello)
+						return
+					}
+					ext := outerExts[next]
+					next++
+					b.AddUint16(ext.typ)
+					b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+						b.AddBytes(ext.data)
+					})
+				}
+			}
+		})
+	})
+	innerBytes, err := b.Bytes()
+	if err != nil {
+		return nil, errInvalidInnerClientHello
+	}
+
+	inner := new(clientHelloMsg)
+	if !inner.unmarshal(innerBytes) {
+		return nil, errInvalidInnerClientHello
+	}
+	if len(inner.encryptedClientHello) != 1 || inner.encryptedClientHello[0] != innerECHExt {
+		return nil, errors.New("tls: ClientHelloInner does not have an inner encrypted_client_hello extension")
+	}
+	if len(inner.supportedVersions) == 0 {
+		return nil, errors.New("tls: ClientHelloInner does not offer TLS 1.3")
+	}
+	for _, v := range inner.supportedVersions {
+		if v < VersionTLS13 {
+			return nil, errors.New("tls: ClientHelloInner offers a TLS version older than TLS 1.3")
+		}
+	}
+	return inner, nil
+}
+
+// buildRetryConfigList returns the ECHConfigList of the keys marked
+// SendAsRetry, or nil if there are none.
+func buildRetryConfigList(keys []EncryptedClientHelloKey) ([]byte, error) {
+	var atLeastOneRetryConfig bool
+	var b cryptobyte.Builder
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+		for _, key := range keys {
+			if !key.SendAsRetry {
+				continue
+			}
+			atLeastOneRetryConfig = true
+			b.AddBytes(key.Config)
+		}
+	})
+	if !atLeastOneRetryConfig {
+		return nil, nil
+	}
+	return b.Bytes()
+}
@@ -0,0 +1,242 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tls
+
+import (
+	"bytes"
+	"crypto/internal/hpke"
+	"crypto/rand"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"golang.org/x/crypto/cryptobyte"
+)
+
+// marshalECHConfig returns an ECHConfig with the given parameters, and
+// the private key matching its public key.
+func marshalECHConfig(t *testing.T, configID uint8, publicName string, extensions []echExtension) (config, priv []byte) {
+	priv, err := hpke.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := hpke.PublicKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b cryptobyte.Builder
+	b.AddUint16(extensionEncryptedClientHello)
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddUint8(configID)
+		b.AddUint16(hpke.DHKEM_X25519_HKDF_SHA256)
+		b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+			b.AddBytes(pub)
+		})
+		b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+			b.AddUint16(hpke.KDF_HKDF_SHA256)
+			b.AddUint16(hpke.AEAD_AES_128_GCM)
+			b.AddUint16(hpke.KDF_HKDF_SHA256)
+			b.AddUint16(hpke.AEAD_ChaCha20Poly1305)
+		})
+		b.AddUint8(32)
+		b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) {
+			b.AddBytes([]byte(publicName))
+		})
+		b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+			for _, ext := range extensions {
+				b.AddUint16(ext.typ)
+				b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+					b.AddBytes(ext.data)
+				})
+			}
+		})
+	})
+	return b.BytesOrPanic(), priv
+}
+
+func marshalECHConfigList(configs ...[]byte) []byte {
+	var b cryptobyte.Builder
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+		for _, config := range configs {
+			b.AddBytes(config)
+		}
+	})
+	return b.BytesOrPanic()
+}
+
+func TestParseECHConfigList(t *testing.T) {
+	config, _ := marshalECHConfig(t, 7, "public.example", nil)
+	mandatory, _ := marshalECHConfig(t, 8, "public.example", []echExtension{{typ: 0xfaaa, data: []byte{1}}})
+	optional, _ := marshalECHConfig(t, 9, "public.example", []echExtension{{typ: 0x0aaa}})
+	ipName, _ := marshalECHConfig(t, 10, "192.0.2.1", nil)
+	unknownVersion := []byte{0xfe, 0x0a, 0x00, 0x02, 0xaa, 0xbb}
+
+	configs, err := parseECHConfigList(marshalECHConfigList(unknownVersion, mandatory, ipName, optional, config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(configs) != 4 {
+		t.Fatalf("parsed %d configs, want 4", len(configs))
+	}
+	if !bytes.Equal(configs[3].raw, config) {
+		t.Errorf("raw config = %x, want %x", configs[3].raw, config)
+	}
+	if configs[3].configID != 7 || configs[3].publicName != "public.example" ||
+		configs[3].maxNameLength != 32 || len(configs[3].cipherSuites) != 2 {
+		t.Errorf("unexpected parsed config: %+v", configs[3])
+	}
+	if picked := pickECHConfig(configs); picked == nil || picked.configID != 9 {
+		t.Errorf("pickECHConfig picked %+v, want config 9", picked)
+	}
+
+	for _, bad := range [][]byte{
+		nil,
+		{0x00, 0x00},
+		marshalECHConfigList(config)[:len(config)],
+		append(marshalECHConfigList(config), 0),
+		marshalECHConfigList(config[:len(config)-1]),
+	} {
+		if _, err := parseECHConfigList(bad); err == nil {
+			t.Errorf("parseECHConfigList(%x) succeeded, expected an error", bad)
+		}
+	}
+}
+
+// echHandshake runs a handshake between a client and a server, reading
+// any post-handshake messages on the client side.
+func echHandshake(t *testing.T, clientConfig, serverConfig *Config) (clientState, serverState ConnectionState, clientErr, serverErr error) {
+	c, s := localPipe(t)
+	done := make(chan bool)
+	go func() {
+		defer close(done)
+		cli := Client(c, clientConfig)
+		clientErr = cli.Handshake()
+		if clientErr == nil {
+			clientState = cli.ConnectionState()
+			ioutil.ReadAll(cli)
+		}
+		c.Close()
+	}()
+	server := Server(s, serverConfig)
+	serverErr = server.Handshake()
+	if serverErr == nil {
+		serverState = server.ConnectionState()
+	}
+	server.Close()
+	<-done
+	return
+}
+
+func testECHConfigs(t *testing.T) (clientConfig, serverConfig *Config) {
+	echConfig, echKey := marshalECHConfig(t, 42, "public.example", nil)
+
+	clientConfig = testConfig.Clone()
+	clientConfig.ServerName = "example.golang"
+	clientConfig.EncryptedClientHelloConfigList = marshalECHConfigList(echConfig)
+
+	serverConfig = testConfig.Clone()
+	serverConfig.EncryptedClientHelloKeys = []EncryptedClientHelloKey{{
+		Config:      echConfig,
+		PrivateKey:  echKey,
+		SendAsRetry: true,
+	}}
+	return clientConfig, serverConfig
+}
+
+func TestECHHandshake(t *testing.T) {
+	for _, hrr := range []bool{false, true} {
+		clientConfig, serverConfig := testECHConfigs(t)
+		if hrr {
+			// The client sends an X25519 key share, so a server preferring
+			// P-256 sends a HelloRetryRequest.
+			serverConfig.CurvePreferences = []CurveID{CurveP256}
+		}
+
+		clientState, serverState, clientErr, serverErr := echHandshake(t, clientConfig, serverConfig)
+		if clientErr != nil || serverErr != nil {
+			t.Fatalf("hrr=%v: handshake failed: client: %v, server: %v", hrr, clientErr, serverErr)
+		}
+		if !clientState.ECHAccepted || !serverState.ECHAccepted {
+			t.Errorf("hrr=%v: ECHAccepted = %v (client), %v (server), want true",
+				hrr, clientState.ECHAccepted, serverState.ECHAccepted)
+		}
+		if serverState.ServerName != "example.golang" {
+			t.Errorf("hrr=%v: server saw ServerName %q, want the inner name", hrr, serverState.ServerName)
+		}
+	}
+}
+
+func TestECHResumption(t *testing.T) {
+	clientConfig, serverConfig := testECHConfigs(t)
+	clientConfig.ClientSessionCache = NewLRUClientSessionCache(1)
+
+	for i, wantResume := range []bool{false, true} {
+		clientState, _, clientErr, serverErr := echHandshake(t, clientConfig, serverConfig)
+		if clientErr != nil || serverErr != nil {
+			t.Fatalf("#%d: handshake failed: client: %v, server: %v", i, clientErr, serverErr)
+		}
+		if !clientState.ECHAccepted {
+			t.Errorf("#%d: ECH was not accepted", i)
+		}
+		if clientState.DidResume != wantResume {
+			t.Errorf("#%d: DidResume = %v, want %v", i, clientState.DidResume, wantResume)
+		}
+	}
+}
+
+func TestECHRejected(t *testing.T) {
+	clientConfig, serverConfig := testECHConfigs(t)
+	retryConfig, retryKey := marshalECHConfig(t, 43, "public.example", nil)
+	serverConfig.EncryptedClientHelloKeys = []EncryptedClientHelloKey{
+		{Config: retryConfig, PrivateKey: retryKey, SendAsRetry: true},
+	}
+
+	var verifiedName string
+	clientConfig.EncryptedClientHelloRejectionVerify = func(cs ConnectionState) error {
+		verifiedName = cs.ServerName
+		if len(cs.PeerCertificates) == 0 {
+			return errors.New("no peer certificates")
+		}
+		return nil
+	}
+
+	_, serverState, clientErr, _ := echHandshake(t, clientConfig, serverConfig)
+	var echErr *ECHRejectionError
+	if !errors.As(clientErr, &echErr) {
+		t.Fatalf("client error = %v, want an ECHRejectionError", clientErr)
+	}
+	if want := marshalECHConfigList(retryConfig); !bytes.Equal(echErr.RetryConfigList, want) {
+		t.Errorf("RetryConfigList = %x, want %x", echErr.RetryConfigList, want)
+	}
+	if verifiedName != "public.example" {
+		t.Errorf("EncryptedClientHelloRejectionVerify saw ServerName %q, want the public name", verifiedName)
+	}
+	if serverState.ECHAccepted {
+		t.Errorf("server reported ECH as accepted")
+	}
+
+	// Without EncryptedClientHelloRejectionVerify, the certificate is
+	// verified against the public name even with InsecureSkipVerify.
+	clientConfig.EncryptedClientHelloRejectionVerify = nil
+	_, _, clientErr, _ = echHandshake(t, clientConfig, serverConfig)
+	if clientErr == nil || errors.As(clientErr, &echErr) {
+		t.Errorf("client error = %v, want a certificate verification error", clientErr)
+	}
+}
+
+func TestECHClientConfigErrors(t *testing.T) {
+	clientConfig, serverConfig := testECHConfigs(t)
+	clientConfig.MinVersion = VersionTLS12
+	if _, _, clientErr, _ := echHandshake(t, clientConfig, serverConfig); clientErr == nil {
+		t.Errorf("handshake with MinVersion TLS 1.2 and ECH succeeded")
+	}
+
+	clientConfig, serverConfig = testECHConfigs(t)
+	mandatory, _ := marshalECHConfig(t, 8, "public.example", []echExtension{{typ: 0xfaaa}})
+	clientConfig.EncryptedClientHelloConfigList = marshalECHConfigList(mandatory)
+	if _, _, clientErr, _ := echHandshake(t, clientConfig, serverConfig); clientErr == nil {
+		t.Errorf("handshake with no usable ECH config succeeded")
+	}
+}
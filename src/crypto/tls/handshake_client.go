@@ -9,6 +9,7 @@ import (
 	"crypto"
 	"crypto/ecdsa"
 	"crypto/ed25519"
+	"crypto/internal/hpke"
 	"crypto/rsa"
 	"crypto/subtle"
 	"crypto/x509"
@@ -31,27 +32,38 @@ type clientHandshakeState struct {
 	session      *ClientSessionState
 }
 
-func (c *Conn) makeClientHello() (*clientHelloMsg, ecdheParameters, error) {
+func (c *Conn) makeClientHello() (*clientHelloMsg, ecdheParameters, *echClientContext, error) {
 	config := c.config
 	if len(config.ServerName) == 0 && !config.InsecureSkipVerify {
-		return nil, nil, errors.New("tls: either ServerName or InsecureSkipVerify must be specified in the tls.Config")
+		return nil, nil, nil, errors.New("tls: either ServerName or InsecureSkipVerify must be specified in the tls.Config")
 	}
 
 	nextProtosLength := 0
 	for _, proto := range config.NextProtos {
 		if l := len(proto); l == 0 || l > 255 {
-			return nil, nil, errors.New("tls: invalid NextProtos value")
+			return nil, nil, nil, errors.New("tls: invalid NextProtos value")
 		} else {
 			nextProtosLength += 1 + l
 		}
 	}
 	if nextProtosLength > 0xffff {
-		return nil, nil, errors.New("tls: NextProtos values too large")
+		return nil, nil, nil, errors.New("tls: NextProtos values too large")
 	}
 
 	supportedVersions := config.supportedVersions()
 	if len(supportedVersions) == 0 {
-		return nil, nil, errors.New("tls: no supported versions satisfy MinVersion and MaxVersion")
+		return nil, nil, nil, errors.New("tls: no supported versions satisfy MinVersion and MaxVersion")
+	}
+	if config.EncryptedClientHelloConfigList != nil {
+		// Encrypted Client Hello requires TLS 1.3, see
+		// draft-ietf-tls-esni-18, Section 6.1.
+		if config.MinVersion != 0 && config.MinVersion < VersionTLS13 {
+			return nil, nil, nil, errors.New("tls: MinVersion must be >= VersionTLS13 if EncryptedClientHelloConfigList is populated")
+		}
+		if supportedVersions[0] != VersionTLS13 {
+			return nil, nil, nil, errors.New("tls: EncryptedClientHelloConfigList requires TLS 1.3 to be enabled")
+		}
+		supportedVersions = supportedVersions[:1]
 	}
 
 	clientHelloVersion := supportedVersions[0]
@@ -101,14 +113,17 @@ func (c *Conn) makeClientHello() (*clientHelloMsg, ecdheParameters, error) {
 
 	_, err := io.ReadFull(config.rand(), hello.random)
 	if err != nil {
-		return nil, nil, errors.New("tls: short read from Rand: " + err.Error())
+		return nil, nil, nil, errors.New("tls: short read from Rand: " + err.Error())
 	}
 
 	// A random session ID is used to detect when the server accepted a ticket
 	// and is resuming a session (see RFC 5077). In TLS 1.3, it's always set as
-	// a compatibility measure (see RFC 8446, Section 4.1.2).
-	if _, err := io.ReadFull(config.rand(), hello.sessionId); err != nil {
-		return nil, nil, errors.New("tls: short read from Rand: " + err.Error())
+	// a compatibility measure (see RFC 8446, Section 4.1.2), except over QUIC,
+	// which has no middleboxes to be compatible with (see RFC 9001, Section 8.4).
+	if c.quic != nil {
+		hello.sessionId = nil
+	} else if _, err := io.ReadFull(config.rand(), hello.sessionId); err != nil {
+		return nil, nil, nil, errors.New("tls: short read from Rand: " + err.Error())
 	}
 
 	if hello.vers >= VersionTLS12 {
@@ -120,17 +135,53 @@ func (c *Conn) makeClientHello() (*clientHelloMsg, ecdheParameters, error) {
 		hello.cipherSuites = append(hello.cipherSuites, defaultCipherSuitesTLS13()...)
 
 		curveID := config.curvePreferences()[0]
-		if _, ok := curveForCurveID(curveID); curveID != X25519 && !ok {
-			return nil, nil, errors.New("tls: CurvePreferences includes unsupported curve")
+		if _, ok := curveForCurveID(curveID); curveID != X25519 && curveID != X25519MLKEM768 && !ok {
+			return nil, nil, nil, errors.New("tls: CurvePreferences includes unsupported curve")
 		}
 		params, err = generateECDHEParameters(config.rand(), curveID)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 		hello.keyShares = []keyShare{{group: curveID, data: params.PublicKey()}}
 	}
 
-	return hello, params, nil
+	if c.quic != nil {
+		p, err := c.quicGetTransportParameters()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if p == nil {
+			p = []byte{}
+		}
+		hello.quicTransportParameters = p
+	}
+
+	var ech *echClientContext
+	if config.EncryptedClientHelloConfigList != nil {
+		echConfigs, err := parseECHConfigList(config.EncryptedClientHelloConfigList)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		echConfig := pickECHConfig(echConfigs)
+		if echConfig == nil {
+			return nil, nil, nil, errors.New("tls: EncryptedClientHelloConfigList contains no valid configs")
+		}
+		ech = &echClientContext{config: echConfig}
+		suite, err := pickECHCipherSuite(echConfig.cipherSuites)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		ech.kdfID, ech.aeadID = suite.kdfID, suite.aeadID
+		ech.encapsulatedKey, ech.hpkeContext, err = hpke.SetupSender(echConfig.kemID,
+			suite.kdfID, suite.aeadID, echConfig.publicKey, echInfo(echConfig.raw))
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		// hello becomes the ClientHelloInner, see clientHandshake.
+		hello.encryptedClientHello = []byte{innerECHExt}
+	}
+
+	return hello, params, ech, nil
 }
 
 func (c *Conn) clientHandshake() (err error) {
@@ -142,7 +193,7 @@ func (c *Conn) clientHandshake() (err error) {
 	// need to be reset.
 	c.didResume = false
 
-	hello, ecdheParams, err := c.makeClientHello()
+	hello, ecdheParams, ech, err := c.makeClientHello()
 	if err != nil {
 		return err
 	}
@@ -162,10 +213,44 @@ func (c *Conn) clientHandshake() (err error) {
 		}()
 	}
 
+	if ech != nil {
+		// Send a ClientHelloOuter with the public name of the ECH config
+		// and a fresh random, carrying the encrypted ClientHelloInner. The
+		// PSK and early data are only offered in the ClientHelloInner.
+		ech.innerHello = hello
+		outer := *hello
+		outer.raw = nil
+		outer.serverName = ech.config.publicName
+		outer.random = make([]byte, 32)
+		if _, err := io.ReadFull(c.config.rand(), outer.random); err != nil {
+			return errors.New("tls: short read from Rand: " + err.Error())
+		}
+		outer.pskIdentities = nil
+		outer.pskBinders = nil
+		outer.earlyData = false
+		if err := computeAndUpdateOuterECHExtension(&outer, ech.innerHello, ech, true); err != nil {
+			return err
+		}
+		hello = &outer
+		c.echPublicName = ech.config.publicName
+	}
+
 	if _, err := c.writeRecord(recordTypeHandshake, hello.marshal()); err != nil {
 		return err
 	}
 
+	earlyHello := hello
+	if ech != nil {
+		earlyHello = ech.innerHello
+	}
+	if earlyHello.earlyData {
+		suite := cipherSuiteTLS13ByID(session.cipherSuite)
+		transcript := suite.hash.New()
+		transcript.Write(earlyHello.marshal())
+		earlyTrafficSecret := suite.deriveSecret(earlySecret, clientEarlyTrafficLabel, transcript)
+		c.quicSetWriteSecret(QUICEncryptionLevelEarly, suite.id, earlyTrafficSecret)
+	}
+
 	msg, err := c.readHandshake()
 	if err != nil {
 		return err
@@ -190,6 +275,7 @@ func (c *Conn) clientHandshake() (err error) {
 			session:     session,
 			earlySecret: earlySecret,
 			binderKey:   binderKey,
+			echContext:  ech,
 		}
 
 		// In TLS 1.3, session tickets are delivered after the handshake.
@@ -238,7 +324,7 @@ func (c *Conn) loadSession(hello *clientHelloMsg) (cacheKey string,
 	}
 
 	// Try to resume a previously negotiated TLS session, if available.
-	cacheKey = clientSessionCacheKey(c.conn.RemoteAddr(), c.config)
+	cacheKey = c.clientSessionCacheKey()
 	session, ok := c.config.ClientSessionCache.Get(cacheKey)
 	if !ok || session == nil {
 		return cacheKey, nil, nil, nil
@@ -319,6 +405,19 @@ func (c *Conn) loadSession(hello *clientHelloMsg) (cacheKey string,
 	hello.pskIdentities = []pskIdentity{identity}
 	hello.pskBinders = [][]byte{make([]byte, cipherSuite.hash.Size())}
 
+	// 0-RTT is only supported over QUIC, and requires the exact cipher
+	// suite and ALPN protocol of the original connection to be offered.
+	// See RFC 8446, Section 4.2.10.
+	if c.quic != nil && session.earlyData &&
+		mutualCipherSuiteTLS13(hello.cipherSuites, session.cipherSuite) != nil {
+		for _, alpn := range hello.alpnProtocols {
+			if alpn == session.alpnProtocol {
+				hello.earlyData = true
+				break
+			}
+		}
+	}
+
 	// Compute the PSK binders. See RFC 8446, Section 4.2.11.2.
 	psk := cipherSuite.expandLabel(session.masterSecret, "resumption",
 		session.nonce, cipherSuite.hash.Size())
@@ -801,7 +900,41 @@ func (c *Conn) verifyServerCertificate(certificates [][]byte) error {
 		certs[i] = cert
 	}
 
-	if !c.config.InsecureSkipVerify {
+	// If ECH was rejected, the certificate must be valid for the public
+	// name of the ECH config, regardless of InsecureSkipVerify, since the
+	// handshake is only used to securely deliver the retry configs. See
+	// draft-ietf-tls-esni-18, Section 6.1.7.
+	echRejected := c.config.EncryptedClientHelloConfigList != nil && !c.echAccepted
+	if echRejected {
+		if c.config.EncryptedClientHelloRejectionVerify != nil {
+			state := ConnectionState{
+				Version:          c.vers,
+				CipherSuite:      c.cipherSuite,
+				ServerName:       c.echPublicName,
+				PeerCertificates: certs,
+			}
+			if err := c.config.EncryptedClientHelloRejectionVerify(state); err != nil {
+				c.sendAlert(alertBadCertificate)
+				return err
+			}
+		} else {
+			opts := x509.VerifyOptions{
+				Roots:         c.config.RootCAs,
+				CurrentTime:   c.config.time(),
+				DNSName:       c.echPublicName,
+				Intermediates: x509.NewCertPool(),
+			}
+			for _, cert := range certs[1:] {
+				opts.Intermediates.AddCert(cert)
+			}
+			var err error
+			c.verifiedChains, err = certs[0].Verify(opts)
+			if err != nil {
+				c.sendAlert(alertBadCertificate)
+				return err
+			}
+		}
+	} else if !c.config.InsecureSkipVerify {
 		opts := x509.VerifyOptions{
 			Roots:         c.config.RootCAs,
 			CurrentTime:   c.config.time(),
@@ -819,7 +952,7 @@ func (c *Conn) verifyServerCertificate(certificates [][]byte) error {
 		}
 	}
 
-	if c.config.VerifyPeerCertificate != nil {
+	if c.config.VerifyPeerCertificate != nil && !echRejected {
 		if err := c.config.VerifyPeerCertificate(certificates, c.verifiedChains); err != nil {
 			c.sendAlert(alertBadCertificate)
 			return err
@@ -922,11 +1055,14 @@ func (c *Conn) getClientCertificate(cri *CertificateRequestInfo) (*Certificate,
 
 // clientSessionCacheKey returns a key used to cache sessionTickets that could
 // be used to resume previously negotiated TLS sessions with a server.
-func clientSessionCacheKey(serverAddr net.Addr, config *Config) string {
-	if len(config.ServerName) > 0 {
-		return config.ServerName
+func (c *Conn) clientSessionCacheKey() string {
+	if len(c.config.ServerName) > 0 {
+		return c.config.ServerName
+	}
+	if c.conn != nil {
+		return c.conn.RemoteAddr().String()
 	}
-	return serverAddr.String()
+	return ""
 }
 
 // mutualProtocol finds the mutual Next Protocol Negotiation or ALPN protocol
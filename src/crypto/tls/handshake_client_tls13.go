@@ -9,6 +9,7 @@ import (
 	"crypto"
 	"crypto/hmac"
 	"crypto/rsa"
+	"crypto/subtle"
 	"errors"
 	"hash"
 	"sync/atomic"
@@ -24,6 +25,7 @@ type clientHandshakeStateTLS13 struct {
 	session     *ClientSessionState
 	earlySecret []byte
 	binderKey   []byte
+	echContext  *echClientContext
 
 	certReq       *certificateRequestMsgTLS13
 	usingPSK      bool
@@ -35,7 +37,8 @@ type clientHandshakeStateTLS13 struct {
 }
 
 // handshake requires hs.c, hs.hello, hs.serverHello, hs.ecdheParams, and,
-// optionally, hs.session, hs.earlySecret and hs.binderKey to be set.
+// optionally, hs.session, hs.earlySecret, hs.binderKey and hs.echContext to
+// be set.
 func (hs *clientHandshakeStateTLS13) handshake() error {
 	c := hs.c
 
@@ -58,7 +61,14 @@ func (hs *clientHandshakeStateTLS13) handshake() error {
 	hs.transcript = hs.suite.hash.New()
 	hs.transcript.Write(hs.hello.marshal())
 
+	if hs.echContext != nil {
+		hs.echContext.innerTranscript = hs.suite.hash.New()
+		hs.echContext.innerTranscript.Write(hs.echContext.innerHello.marshal())
+	}
+
+	sawHRR := false
 	if bytes.Equal(hs.serverHello.random, helloRetryRequestRandom) {
+		sawHRR = true
 		if err := hs.sendDummyChangeCipherSpec(); err != nil {
 			return err
 		}
@@ -67,6 +77,32 @@ func (hs *clientHandshakeStateTLS13) handshake() error {
 		}
 	}
 
+	if hs.echContext != nil && !hs.echContext.echRejected {
+		// The server signals that it accepted ECH in the last eight bytes
+		// of the ServerHello random. See draft-ietf-tls-esni-18, Section 7.2.
+		serverHello := hs.serverHello.marshal()
+		confTranscript := cloneHash(hs.echContext.innerTranscript, hs.suite.hash)
+		confTranscript.Write(serverHello[:30])
+		confTranscript.Write(make([]byte, 8))
+		confTranscript.Write(serverHello[38:])
+		acceptConfirmation := echAcceptConfirmation(hs.suite,
+			hs.echContext.innerHello.random, echAcceptConfirmationLabel, confTranscript)
+		if subtle.ConstantTimeCompare(acceptConfirmation, hs.serverHello.random[24:]) == 1 {
+			hs.hello = hs.echContext.innerHello
+			hs.transcript = hs.echContext.innerTranscript
+			c.echAccepted = true
+		} else if sawHRR {
+			// The server accepted ECH in the HelloRetryRequest.
+			c.sendAlert(alertIllegalParameter)
+			return errors.New("tls: server rejected ECH after accepting it in the HelloRetryRequest")
+		} else {
+			hs.echContext.echRejected = true
+		}
+	}
+	if hs.echContext != nil && hs.echContext.echRejected && hs.echContext.innerHello.earlyData {
+		c.quicRejectedEarlyData()
+	}
+
 	hs.transcript.Write(hs.serverHello.marshal())
 
 	c.buffering = true
@@ -98,6 +134,11 @@ func (hs *clientHandshakeStateTLS13) handshake() error {
 		return err
 	}
 
+	if hs.echContext != nil && hs.echContext.echRejected {
+		c.sendAlert(alertECHRequired)
+		return &ECHRejectionError{hs.echContext.retryConfigs}
+	}
+
 	atomic.StoreUint32(&c.handshakeStatus, 1)
 
 	return nil
@@ -161,6 +202,9 @@ func (hs *clientHandshakeStateTLS13) checkServerHelloOrHRR() error {
 // sendDummyChangeCipherSpec sends a ChangeCipherSpec record for compatibility
 // with middleboxes that didn't implement TLS correctly. See RFC 8446, Appendix D.4.
 func (hs *clientHandshakeStateTLS13) sendDummyChangeCipherSpec() error {
+	if hs.c.quic != nil {
+		return nil
+	}
 	if hs.sentDummyCCS {
 		return nil
 	}
@@ -183,6 +227,39 @@ func (hs *clientHandshakeStateTLS13) processHelloRetryRequest() error {
 	hs.transcript.Write(chHash)
 	hs.transcript.Write(hs.serverHello.marshal())
 
+	var innerCHHash []byte
+	if hs.echContext != nil {
+		innerCHHash = hs.echContext.innerTranscript.Sum(nil)
+		hs.echContext.innerTranscript.Reset()
+		hs.echContext.innerTranscript.Write([]byte{typeMessageHash, 0, 0, uint8(len(innerCHHash))})
+		hs.echContext.innerTranscript.Write(innerCHHash)
+
+		// The server signals that it accepted ECH in the
+		// encrypted_client_hello extension of the HelloRetryRequest.
+		// See draft-ietf-tls-esni-18, Section 7.2.1.
+		accepted := false
+		if hs.serverHello.encryptedClientHello != nil {
+			if len(hs.serverHello.encryptedClientHello) != 8 {
+				c.sendAlert(alertDecodeError)
+				return errors.New("tls: received malformed encrypted_client_hello extension")
+			}
+			hrr, ok := hrrWithoutECHConfirmation(hs.serverHello.marshal())
+			if !ok {
+				return c.sendAlert(alertInternalError)
+			}
+			confTranscript := cloneHash(hs.echContext.innerTranscript, hs.suite.hash)
+			confTranscript.Write(hrr)
+			acceptConfirmation := echAcceptConfirmation(hs.suite,
+				hs.echContext.innerHello.random, echHRRAcceptConfirmationLabel, confTranscript)
+			accepted = subtle.ConstantTimeCompare(acceptConfirmation, hs.serverHello.encryptedClientHello) == 1
+		}
+		if !accepted {
+			hs.echContext.echRejected = true
+		}
+
+		hs.echContext.innerTranscript.Write(hs.serverHello.marshal())
+	}
+
 	if hs.serverHello.serverShare.group != 0 {
 		c.sendAlert(alertDecodeError)
 		return errors.New("tls: received malformed key_share extension")
@@ -208,7 +285,7 @@ func (hs *clientHandshakeStateTLS13) processHelloRetryRequest() error {
 		c.sendAlert(alertIllegalParameter)
 		return errors.New("tls: server sent an unnecessary HelloRetryRequest message")
 	}
-	if _, ok := curveForCurveID(curveID); curveID != X25519 && !ok {
+	if _, ok := curveForCurveID(curveID); curveID != X25519 && curveID != X25519MLKEM768 && !ok {
 		c.sendAlert(alertInternalError)
 		return errors.New("tls: CurvePreferences includes unsupported curve")
 	}
@@ -222,29 +299,38 @@ func (hs *clientHandshakeStateTLS13) processHelloRetryRequest() error {
 
 	hs.hello.cookie = hs.serverHello.cookie
 
+	// The server rejects early data by sending a HelloRetryRequest; the
+	// second ClientHello must not offer it. See RFC 8446, Section 4.1.2.
+	earlyHello := hs.hello
+	if hs.echContext != nil {
+		earlyHello = hs.echContext.innerHello
+	}
+	if earlyHello.earlyData {
+		earlyHello.earlyData = false
+		c.quicRejectedEarlyData()
+	}
+
 	hs.hello.raw = nil
-	if len(hs.hello.pskIdentities) > 0 {
-		pskSuite := cipherSuiteTLS13ByID(hs.session.cipherSuite)
-		if pskSuite == nil {
-			return c.sendAlert(alertInternalError)
+	if err := hs.updatePSK(hs.hello, chHash); err != nil {
+		return err
+	}
+
+	if hs.echContext != nil {
+		// Both ClientHellos are updated, and the ClientHelloInner is
+		// encrypted again with the same HPKE context, without repeating
+		// the encapsulated key. See draft-ietf-tls-esni-18, Section 6.1.5.
+		inner := hs.echContext.innerHello
+		inner.keyShares = hs.hello.keyShares
+		inner.cookie = hs.hello.cookie
+		inner.raw = nil
+		if err := hs.updatePSK(inner, innerCHHash); err != nil {
+			return err
 		}
-		if pskSuite.hash == hs.suite.hash {
-			// Update binders and obfuscated_ticket_age.
-			ticketAge := uint32(c.config.time().Sub(hs.session.receivedAt) / time.Millisecond)
-			hs.hello.pskIdentities[0].obfuscatedTicketAge = ticketAge + hs.session.ageAdd
-
-			transcript := hs.suite.hash.New()
-			transcript.Write([]byte{typeMessageHash, 0, 0, uint8(len(chHash))})
-			transcript.Write(chHash)
-			transcript.Write(hs.serverHello.marshal())
-			transcript.Write(hs.hello.marshalWithoutBinders())
-			pskBinders := [][]byte{hs.suite.finishedHash(hs.binderKey, transcript)}
-			hs.hello.updateBinders(pskBinders)
-		} else {
-			// Server selected a cipher suite incompatible with the PSK.
-			hs.hello.pskIdentities = nil
-			hs.hello.pskBinders = nil
+		if err := computeAndUpdateOuterECHExtension(hs.hello, inner, hs.echContext, false); err != nil {
+			c.sendAlert(alertInternalError)
+			return err
 		}
+		hs.echContext.innerTranscript.Write(inner.marshal())
 	}
 
 	hs.transcript.Write(hs.hello.marshal())
@@ -271,6 +357,40 @@ func (hs *clientHandshakeStateTLS13) processHelloRetryRequest() error {
 	return nil
 }
 
+// updatePSK updates the obfuscated_ticket_age and binders of the
+// pre_shared_key extension of hello, if any, for the second ClientHello.
+// chHash is the hash of the first ClientHello.
+func (hs *clientHandshakeStateTLS13) updatePSK(hello *clientHelloMsg, chHash []byte) error {
+	c := hs.c
+
+	if len(hello.pskIdentities) == 0 {
+		return nil
+	}
+	pskSuite := cipherSuiteTLS13ByID(hs.session.cipherSuite)
+	if pskSuite == nil {
+		return c.sendAlert(alertInternalError)
+	}
+	if pskSuite.hash != hs.suite.hash {
+		// Server selected a cipher suite incompatible with the PSK.
+		hello.pskIdentities = nil
+		hello.pskBinders = nil
+		return nil
+	}
+
+	// Update binders and obfuscated_ticket_age.
+	ticketAge := uint32(c.config.time().Sub(hs.session.receivedAt) / time.Millisecond)
+	hello.pskIdentities[0].obfuscatedTicketAge = ticketAge + hs.session.ageAdd
+
+	transcript := hs.suite.hash.New()
+	transcript.Write([]byte{typeMessageHash, 0, 0, uint8(len(chHash))})
+	transcript.Write(chHash)
+	transcript.Write(hs.serverHello.marshal())
+	transcript.Write(hello.marshalWithoutBinders())
+	pskBinders := [][]byte{hs.suite.finishedHash(hs.binderKey, transcript)}
+	hello.updateBinders(pskBinders)
+	return nil
+}
+
 func (hs *clientHandshakeStateTLS13) processServerHello() error {
 	c := hs.c
 
@@ -344,10 +464,19 @@ func (hs *clientHandshakeStateTLS13) establishHandshakeKeys() error {
 
 	clientSecret := hs.suite.deriveSecret(handshakeSecret,
 		clientHandshakeTrafficLabel, hs.transcript)
-	c.out.setTrafficSecret(hs.suite, clientSecret)
+	c.out.setTrafficSecret(hs.suite, QUICEncryptionLevelHandshake, clientSecret)
 	serverSecret := hs.suite.deriveSecret(handshakeSecret,
 		serverHandshakeTrafficLabel, hs.transcript)
-	c.in.setTrafficSecret(hs.suite, serverSecret)
+	c.in.setTrafficSecret(hs.suite, QUICEncryptionLevelHandshake, serverSecret)
+
+	if c.quic != nil {
+		if c.hand.Len() != 0 {
+			c.sendAlert(alertUnexpectedMessage)
+			return errors.New("tls: handshake data received at the wrong encryption level")
+		}
+		c.quicSetWriteSecret(QUICEncryptionLevelHandshake, hs.suite.id, clientSecret)
+		c.quicSetReadSecret(QUICEncryptionLevelHandshake, hs.suite.id, serverSecret)
+	}
 
 	err := c.config.writeKeyLog(keyLogLabelClientHandshake, hs.hello.random, clientSecret)
 	if err != nil {
@@ -387,6 +516,44 @@ func (hs *clientHandshakeStateTLS13) readServerParameters() error {
 	}
 	c.clientProtocol = encryptedExtensions.alpnProtocol
 
+	if len(encryptedExtensions.echRetryConfigs) != 0 {
+		if hs.echContext == nil || !hs.echContext.echRejected {
+			c.sendAlert(alertUnsupportedExtension)
+			return errors.New("tls: server sent ECH retry configs when ECH was not rejected")
+		}
+		hs.echContext.retryConfigs = encryptedExtensions.echRetryConfigs
+	}
+
+	if c.quic != nil {
+		if encryptedExtensions.quicTransportParameters == nil {
+			// RFC 9001, Section 8.2.
+			c.sendAlert(alertMissingExtension)
+			return errors.New("tls: server did not send a quic_transport_parameters extension")
+		}
+		c.quicSetTransportParameters(encryptedExtensions.quicTransportParameters)
+	} else if encryptedExtensions.quicTransportParameters != nil {
+		c.sendAlert(alertUnsupportedExtension)
+		return errors.New("tls: server sent an unexpected quic_transport_parameters extension")
+	}
+
+	if !hs.hello.earlyData && encryptedExtensions.earlyData {
+		c.sendAlert(alertUnsupportedExtension)
+		return errors.New("tls: server sent an unexpected early_data extension")
+	}
+	if hs.hello.earlyData && !encryptedExtensions.earlyData {
+		c.quicRejectedEarlyData()
+	}
+	if encryptedExtensions.earlyData {
+		if hs.session.cipherSuite != c.cipherSuite {
+			c.sendAlert(alertHandshakeFailure)
+			return errors.New("tls: server accepted 0-RTT with the wrong cipher suite")
+		}
+		if hs.session.alpnProtocol != c.clientProtocol {
+			c.sendAlert(alertHandshakeFailure)
+			return errors.New("tls: server accepted 0-RTT with the wrong ALPN")
+		}
+	}
+
 	return nil
 }
 
@@ -498,7 +665,7 @@ func (hs *clientHandshakeStateTLS13) readServerFinished() error {
 		clientApplicationTrafficLabel, hs.transcript)
 	serverSecret := hs.suite.deriveSecret(hs.masterSecret,
 		serverApplicationTrafficLabel, hs.transcript)
-	c.in.setTrafficSecret(hs.suite, serverSecret)
+	c.in.setTrafficSecret(hs.suite, QUICEncryptionLevelApplication, serverSecret)
 
 	err = c.config.writeKeyLog(keyLogLabelClientTraffic, hs.hello.random, hs.trafficSecret)
 	if err != nil {
@@ -523,13 +690,20 @@ func (hs *clientHandshakeStateTLS13) sendClientCertificate() error {
 		return nil
 	}
 
-	cert, err := c.getClientCertificate(&CertificateRequestInfo{
-		AcceptableCAs:    hs.certReq.certificateAuthorities,
-		SignatureSchemes: hs.certReq.supportedSignatureAlgorithms,
-		Version:          c.vers,
-	})
-	if err != nil {
-		return err
+	// If ECH was rejected, the handshake is with the client-facing server
+	// only to deliver the retry configs, so no certificate is sent. See
+	// draft-ietf-tls-esni-18, Section 6.1.7.
+	cert := new(Certificate)
+	var err error
+	if hs.echContext == nil || !hs.echContext.echRejected {
+		cert, err = c.getClientCertificate(&CertificateRequestInfo{
+			AcceptableCAs:    hs.certReq.certificateAuthorities,
+			SignatureSchemes: hs.certReq.supportedSignatureAlgorithms,
+			Version:          c.vers,
+		})
+		if err != nil {
+			return err
+		}
 	}
 
 	certMsg := new(certificateMsgTLS13)
@@ -596,7 +770,15 @@ func (hs *clientHandshakeStateTLS13) sendClientFinished() error {
 		return err
 	}
 
-	c.out.setTrafficSecret(hs.suite, hs.trafficSecret)
+	c.out.setTrafficSecret(hs.suite, QUICEncryptionLevelApplication, hs.trafficSecret)
+
+	if c.quic != nil {
+		if c.hand.Len() != 0 {
+			c.sendAlert(alertUnexpectedMessage)
+			return errors.New("tls: handshake data received at the wrong encryption level")
+		}
+		c.quicSetWriteSecret(QUICEncryptionLevelApplication, hs.suite.id, hs.trafficSecret)
+	}
 
 	if !c.config.SessionTicketsDisabled && c.config.ClientSessionCache != nil {
 		c.resumptionSecret = hs.suite.deriveSecret(hs.masterSecret,
@@ -646,9 +828,11 @@ func (c *Conn) handleNewSessionTicket(msg *newSessionTicketMsgTLS13) error {
 		nonce:              msg.nonce,
 		useBy:              c.config.time().Add(lifetime),
 		ageAdd:             msg.ageAdd,
+		earlyData:          c.quic != nil && msg.maxEarlyData == 0xffffffff, // RFC 9001, Section 4.6.1
+		alpnProtocol:       c.clientProtocol,
 	}
 
-	cacheKey := clientSessionCacheKey(c.conn.RemoteAddr(), c.config)
+	cacheKey := c.clientSessionCacheKey()
 	c.config.ClientSessionCache.Put(cacheKey, session)
 
 	return nil
@@ -190,6 +190,12 @@ func (*clientHelloMsg) Generate(rand *rand.Rand, size int) reflect.Value {
 	if rand.Intn(10) > 5 {
 		m.earlyData = true
 	}
+	if rand.Intn(10) > 5 {
+		m.quicTransportParameters = randomBytes(rand.Intn(50), rand)
+	}
+	if rand.Intn(10) > 5 {
+		m.encryptedClientHello = randomBytes(rand.Intn(200)+1, rand)
+	}
 
 	return reflect.ValueOf(m)
 }
@@ -234,6 +240,9 @@ func (*serverHelloMsg) Generate(rand *rand.Rand, size int) reflect.Value {
 		}
 	} else if rand.Intn(10) > 5 {
 		m.selectedGroup = CurveID(rand.Intn(30000) + 1)
+		if rand.Intn(10) > 5 {
+			m.encryptedClientHello = randomBytes(8, rand)
+		}
 	}
 	if rand.Intn(10) > 5 {
 		m.selectedIdentityPresent = true
@@ -249,6 +258,15 @@ func (*encryptedExtensionsMsg) Generate(rand *rand.Rand, size int) reflect.Value
 	if rand.Intn(10) > 5 {
 		m.alpnProtocol = randomString(rand.Intn(32)+1, rand)
 	}
+	if rand.Intn(10) > 5 {
+		m.quicTransportParameters = randomBytes(rand.Intn(50), rand)
+	}
+	if rand.Intn(10) > 5 {
+		m.earlyData = true
+	}
+	if rand.Intn(10) > 5 {
+		m.echRetryConfigs = randomBytes(rand.Intn(200)+1, rand)
+	}
 
 	return reflect.ValueOf(m)
 }
@@ -335,6 +353,10 @@ func (*sessionStateTLS13) Generate(rand *rand.Rand, size int) reflect.Value {
 				s.certificate.SignedCertificateTimestamps, randomBytes(rand.Intn(500)+1, rand))
 		}
 	}
+	if rand.Intn(10) > 5 {
+		s.earlyData = true
+		s.alpnProtocol = randomString(rand.Intn(32), rand)
+	}
 	return reflect.ValueOf(s)
 }
 
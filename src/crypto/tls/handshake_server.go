@@ -8,6 +8,7 @@ import (
 	"crypto"
 	"crypto/ecdsa"
 	"crypto/ed25519"
+	"crypto/internal/hpke"
 	"crypto/rsa"
 	"crypto/subtle"
 	"crypto/x509"
@@ -40,7 +41,7 @@ func (c *Conn) serverHandshake() error {
 	// encrypt the tickets with.
 	c.config.serverInitOnce.Do(func() { c.config.serverInit(nil) })
 
-	clientHello, err := c.readClientHello()
+	clientHello, ech, err := c.readClientHello()
 	if err != nil {
 		return err
 	}
@@ -49,6 +50,7 @@ func (c *Conn) serverHandshake() error {
 		hs := serverHandshakeStateTLS13{
 			c:           c,
 			clientHello: clientHello,
+			echContext:  ech,
 		}
 		return hs.handshake()
 	}
@@ -131,22 +133,32 @@ func (hs *serverHandshakeState) handshake() error {
 }
 
 // readClientHello reads a ClientHello message and selects the protocol version.
-func (c *Conn) readClientHello() (*clientHelloMsg, error) {
+// If the client offered Encrypted Client Hello and it could be decrypted, the
+// returned message is the ClientHelloInner, and the ECH context is returned.
+func (c *Conn) readClientHello() (*clientHelloMsg, *echServerContext, error) {
 	msg, err := c.readHandshake()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	clientHello, ok := msg.(*clientHelloMsg)
 	if !ok {
 		c.sendAlert(alertUnexpectedMessage)
-		return nil, unexpectedMessageError(clientHello, msg)
+		return nil, nil, unexpectedMessageError(clientHello, msg)
+	}
+
+	var ech *echServerContext
+	if len(clientHello.encryptedClientHello) != 0 {
+		clientHello, ech, err = c.processECHClientHello(clientHello)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 
 	if c.config.GetConfigForClient != nil {
 		chi := clientHelloInfo(c, clientHello)
 		if newConfig, err := c.config.GetConfigForClient(chi); err != nil {
 			c.sendAlert(alertInternalError)
-			return nil, err
+			return nil, nil, err
 		} else if newConfig != nil {
 			newConfig.serverInitOnce.Do(func() { newConfig.serverInit(c.config) })
 			c.config = newConfig
@@ -160,13 +172,73 @@ func (c *Conn) readClientHello() (*clientHelloMsg, error) {
 	c.vers, ok = c.config.mutualVersion(clientVersions)
 	if !ok {
 		c.sendAlert(alertProtocolVersion)
-		return nil, fmt.Errorf("tls: client offered only unsupported versions: %x", clientVersions)
+		return nil, nil, fmt.Errorf("tls: client offered only unsupported versions: %x", clientVersions)
 	}
 	c.haveVers = true
 	c.in.version = c.vers
 	c.out.version = c.vers
 
-	return clientHello, nil
+	return clientHello, ech, nil
+}
+
+// processECHClientHello attempts to decrypt the ClientHelloInner carried by
+// outer with one of the configured EncryptedClientHelloKeys. If that's not
+// possible, ECH is rejected and outer is returned with a nil context.
+func (c *Conn) processECHClientHello(outer *clientHelloMsg) (*clientHelloMsg, *echServerContext, error) {
+	echType, echCipherSuite, configID, encap, payload, err := parseECHExt(outer.encryptedClientHello)
+	if err != nil {
+		c.sendAlert(alertDecodeError)
+		return nil, nil, errors.New("tls: client sent invalid encrypted_client_hello extension")
+	}
+	if echType == innerECHExt {
+		// This server is not acting as the backend of a split mode
+		// deployment, so the ClientHello is treated as if it had no ECH.
+		return outer, nil, nil
+	}
+
+	for _, echKey := range c.config.EncryptedClientHelloKeys {
+		config, skip, err := parseECHConfig(echKey.Config)
+		if err != nil || skip {
+			c.sendAlert(alertInternalError)
+			return nil, nil, errors.New("tls: invalid EncryptedClientHelloKeys Config")
+		}
+		if config.configID != configID {
+			continue
+		}
+		supported := false
+		for _, cs := range config.cipherSuites {
+			if cs == echCipherSuite {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			continue
+		}
+		hpkeContext, err := hpke.SetupRecipient(config.kemID, echCipherSuite.kdfID,
+			echCipherSuite.aeadID, echKey.PrivateKey, echInfo(config.raw), encap)
+		if err != nil {
+			// Attempt the next trial decryption.
+			continue
+		}
+		encodedInner, err := decryptECHPayload(hpkeContext, outer.marshal(), payload)
+		if err != nil {
+			continue
+		}
+		inner, err := decodeInnerClientHello(outer, encodedInner)
+		if err != nil {
+			c.sendAlert(alertIllegalParameter)
+			return nil, nil, err
+		}
+		c.echAccepted = true
+		return inner, &echServerContext{
+			hpkeContext: hpkeContext,
+			configID:    configID,
+			cipherSuite: echCipherSuite,
+		}, nil
+	}
+
+	return outer, nil, nil
 }
 
 func (hs *serverHandshakeState) processClientHello() error {
@@ -280,7 +352,7 @@ func (hs *serverHandshakeState) processClientHello() error {
 func supportsECDHE(c *Config, supportedCurves []CurveID, supportedPoints []uint8) bool {
 	supportsCurve := false
 	for _, curve := range supportedCurves {
-		if c.supportsCurve(curve) {
+		if c.supportsCurve(curve) && !isTLS13OnlyKeyExchange(curve) {
 			supportsCurve = true
 			break
 		}
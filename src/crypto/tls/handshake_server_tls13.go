@@ -27,6 +27,7 @@ type serverHandshakeStateTLS13 struct {
 	hello           *serverHelloMsg
 	sentDummyCCS    bool
 	usingPSK        bool
+	earlyData       bool
 	suite           *cipherSuiteTLS13
 	cert            *Certificate
 	sigAlg          SignatureScheme
@@ -37,6 +38,7 @@ type serverHandshakeStateTLS13 struct {
 	trafficSecret   []byte // client_application_traffic_secret_0
 	transcript      hash.Hash
 	clientFinished  []byte
+	echContext      *echServerContext
 }
 
 func (hs *serverHandshakeStateTLS13) handshake() error {
@@ -133,7 +135,12 @@ func (hs *serverHandshakeStateTLS13) processClientHello() error {
 		return errors.New("tls: initial handshake had non-empty renegotiation extension")
 	}
 
-	if hs.clientHello.earlyData {
+	if hs.clientHello.earlyData && c.quic != nil {
+		if len(hs.clientHello.pskIdentities) == 0 {
+			c.sendAlert(alertIllegalParameter)
+			return errors.New("tls: early_data without pre_shared_key")
+		}
+	} else if hs.clientHello.earlyData {
 		// See RFC 8446, Section 4.2.10 for the complicated behavior required
 		// here. The scenario is that a different server at our address offered
 		// to accept early data in the past, which we can't handle. For now, all
@@ -203,23 +210,65 @@ GroupSelection:
 		clientKeyShare = &hs.clientHello.keyShares[0]
 	}
 
-	if _, ok := curveForCurveID(selectedGroup); selectedGroup != X25519 && !ok {
-		c.sendAlert(alertInternalError)
-		return errors.New("tls: CurvePreferences includes unsupported curve")
+	if selectedGroup == X25519MLKEM768 {
+		serverShare, sharedKey, err := x25519MLKEM768Encapsulate(c.config.rand(), clientKeyShare.data)
+		if err != nil {
+			c.sendAlert(alertIllegalParameter)
+			return errors.New("tls: invalid client key share")
+		}
+		hs.hello.serverShare = keyShare{group: selectedGroup, data: serverShare}
+		hs.sharedKey = sharedKey
+	} else {
+		if _, ok := curveForCurveID(selectedGroup); selectedGroup != X25519 && !ok {
+			c.sendAlert(alertInternalError)
+			return errors.New("tls: CurvePreferences includes unsupported curve")
+		}
+		params, err := generateECDHEParameters(c.config.rand(), selectedGroup)
+		if err != nil {
+			c.sendAlert(alertInternalError)
+			return err
+		}
+		hs.hello.serverShare = keyShare{group: selectedGroup, data: params.PublicKey()}
+		hs.sharedKey = params.SharedKey(clientKeyShare.data)
+		if hs.sharedKey == nil {
+			c.sendAlert(alertIllegalParameter)
+			return errors.New("tls: invalid client key share")
+		}
 	}
-	params, err := generateECDHEParameters(c.config.rand(), selectedGroup)
-	if err != nil {
-		c.sendAlert(alertInternalError)
-		return err
+
+	c.serverName = hs.clientHello.serverName
+
+	if len(hs.clientHello.alpnProtocols) > 0 {
+		if selectedProto, fallback := mutualProtocol(hs.clientHello.alpnProtocols, c.config.NextProtos); !fallback {
+			c.clientProtocol = selectedProto
+		}
 	}
-	hs.hello.serverShare = keyShare{group: selectedGroup, data: params.PublicKey()}
-	hs.sharedKey = params.SharedKey(clientKeyShare.data)
-	if hs.sharedKey == nil {
-		c.sendAlert(alertIllegalParameter)
-		return errors.New("tls: invalid client key share")
+
+	if c.quic != nil {
+		// RFC 9001, Section 4.2: clients must not offer TLS versions
+		// older than TLS 1.3.
+		for _, v := range hs.clientHello.supportedVersions {
+			if v < VersionTLS13 {
+				c.sendAlert(alertProtocolVersion)
+				return errors.New("tls: client offered TLS version older than TLS 1.3")
+			}
+		}
+		// RFC 9001, Section 8.1.
+		if len(c.config.NextProtos) > 0 && c.clientProtocol == "" {
+			c.sendAlert(alertNoApplicationProtocol)
+			return errors.New("tls: no application protocol supported by both client and server")
+		}
+		// RFC 9001, Section 8.2.
+		if hs.clientHello.quicTransportParameters == nil {
+			c.sendAlert(alertMissingExtension)
+			return errors.New("tls: client did not send a quic_transport_parameters extension")
+		}
+		c.quicSetTransportParameters(hs.clientHello.quicTransportParameters)
+	} else if hs.clientHello.quicTransportParameters != nil {
+		c.sendAlert(alertUnsupportedExtension)
+		return errors.New("tls: client sent an unexpected quic_transport_parameters extension")
 	}
 
-	c.serverName = hs.clientHello.serverName
 	return nil
 }
 
@@ -270,7 +319,8 @@ func (hs *serverHandshakeStateTLS13) checkForResumption() error {
 
 		// We don't check the obfuscated ticket age because it's affected by
 		// clock skew and it's only a freshness signal useful for shrinking the
-		// window for replay attacks, which don't affect us as we don't do 0-RTT.
+		// window for replay attacks. 0-RTT is only done over QUIC, where the
+		// transport is responsible for any anti-replay measures.
 
 		pskSuite := cipherSuiteTLS13ByID(sessionState.cipherSuite)
 		if pskSuite == nil || pskSuite.hash != hs.suite.hash {
@@ -310,6 +360,19 @@ func (hs *serverHandshakeStateTLS13) checkForResumption() error {
 			return err
 		}
 
+		// Accept 0-RTT only for the first identity, if the ticket allows
+		// it and the connection parameters match. See RFC 8446, Section 4.2.10.
+		if hs.clientHello.earlyData && i == 0 &&
+			sessionState.earlyData && sessionState.cipherSuite == hs.suite.id &&
+			sessionState.alpnProtocol == c.clientProtocol {
+			hs.earlyData = true
+
+			transcript := hs.suite.hash.New()
+			transcript.Write(hs.clientHello.marshal())
+			earlyTrafficSecret := hs.suite.deriveSecret(hs.earlySecret, clientEarlyTrafficLabel, transcript)
+			c.quicSetReadSecret(QUICEncryptionLevelEarly, hs.suite.id, earlyTrafficSecret)
+		}
+
 		hs.hello.selectedIdentityPresent = true
 		hs.hello.selectedIdentity = uint16(i)
 		hs.usingPSK = true
@@ -385,6 +448,9 @@ func (hs *serverHandshakeStateTLS13) pickCertificate() error {
 // sendDummyChangeCipherSpec sends a ChangeCipherSpec record for compatibility
 // with middleboxes that didn't implement TLS correctly. See RFC 8446, Appendix D.4.
 func (hs *serverHandshakeStateTLS13) sendDummyChangeCipherSpec() error {
+	if hs.c.quic != nil {
+		return nil
+	}
 	if hs.sentDummyCCS {
 		return nil
 	}
@@ -415,6 +481,18 @@ func (hs *serverHandshakeStateTLS13) doHelloRetryRequest(selectedGroup CurveID)
 		selectedGroup:     selectedGroup,
 	}
 
+	if hs.echContext != nil {
+		// Signal that ECH was accepted in the encrypted_client_hello
+		// extension. See draft-ietf-tls-esni-18, Section 7.2.1.
+		helloRetryRequest.encryptedClientHello = make([]byte, 8)
+		confTranscript := cloneHash(hs.transcript, hs.suite.hash)
+		confTranscript.Write(helloRetryRequest.marshal())
+		acceptConfirmation := echAcceptConfirmation(hs.suite, hs.clientHello.random,
+			echHRRAcceptConfirmationLabel, confTranscript)
+		copy(helloRetryRequest.encryptedClientHello, acceptConfirmation)
+		helloRetryRequest.raw = nil
+	}
+
 	hs.transcript.Write(helloRetryRequest.marshal())
 	if _, err := c.writeRecord(recordTypeHandshake, helloRetryRequest.marshal()); err != nil {
 		return err
@@ -435,6 +513,13 @@ func (hs *serverHandshakeStateTLS13) doHelloRetryRequest(selectedGroup CurveID)
 		return unexpectedMessageError(clientHello, msg)
 	}
 
+	if hs.echContext != nil {
+		clientHello, err = hs.decryptSecondECHClientHello(clientHello)
+		if err != nil {
+			return err
+		}
+	}
+
 	if len(clientHello.keyShares) != 1 || clientHello.keyShares[0].group != selectedGroup {
 		c.sendAlert(alertIllegalParameter)
 		return errors.New("tls: client sent invalid key share in second ClientHello")
@@ -454,6 +539,39 @@ func (hs *serverHandshakeStateTLS13) doHelloRetryRequest(selectedGroup CurveID)
 	return nil
 }
 
+// decryptSecondECHClientHello returns the ClientHelloInner carried by the
+// second ClientHelloOuter, which must be encrypted with the HPKE context of
+// the first one. See draft-ietf-tls-esni-18, Section 7.1.1.
+func (hs *serverHandshakeStateTLS13) decryptSecondECHClientHello(outer *clientHelloMsg) (*clientHelloMsg, error) {
+	c := hs.c
+
+	if len(outer.encryptedClientHello) == 0 {
+		c.sendAlert(alertMissingExtension)
+		return nil, errors.New("tls: second ClientHello is missing the encrypted_client_hello extension")
+	}
+	echType, echCipherSuite, configID, encap, payload, err := parseECHExt(outer.encryptedClientHello)
+	if err != nil {
+		c.sendAlert(alertDecodeError)
+		return nil, errors.New("tls: client sent invalid encrypted_client_hello extension")
+	}
+	if echType != outerECHExt || echCipherSuite != hs.echContext.cipherSuite ||
+		configID != hs.echContext.configID || len(encap) != 0 {
+		c.sendAlert(alertIllegalParameter)
+		return nil, errors.New("tls: client sent invalid encrypted_client_hello extension in second ClientHello")
+	}
+	encodedInner, err := decryptECHPayload(hs.echContext.hpkeContext, outer.marshal(), payload)
+	if err != nil {
+		c.sendAlert(alertDecryptError)
+		return nil, errors.New("tls: failed to decrypt second ClientHelloInner")
+	}
+	inner, err := decodeInnerClientHello(outer, encodedInner)
+	if err != nil {
+		c.sendAlert(alertIllegalParameter)
+		return nil, err
+	}
+	return inner, nil
+}
+
 // illegalClientHelloChange reports whether the two ClientHello messages are
 // different, with the exception of the changes allowed before and after a
 // HelloRetryRequest. See RFC 8446, Section 4.1.2.
@@ -516,6 +634,19 @@ func (hs *serverHandshakeStateTLS13) sendServerParameters() error {
 	c := hs.c
 
 	hs.transcript.Write(hs.clientHello.marshal())
+
+	if hs.echContext != nil {
+		// Signal that ECH was accepted in the last eight bytes of the
+		// ServerHello random. See draft-ietf-tls-esni-18, Section 7.2.
+		copy(hs.hello.random[24:], make([]byte, 8))
+		confTranscript := cloneHash(hs.transcript, hs.suite.hash)
+		confTranscript.Write(hs.hello.marshal())
+		acceptConfirmation := echAcceptConfirmation(hs.suite, hs.clientHello.random,
+			echAcceptConfirmationLabel, confTranscript)
+		copy(hs.hello.random[24:], acceptConfirmation)
+		hs.hello.raw = nil
+	}
+
 	hs.transcript.Write(hs.hello.marshal())
 	if _, err := c.writeRecord(recordTypeHandshake, hs.hello.marshal()); err != nil {
 		return err
@@ -534,10 +665,19 @@ func (hs *serverHandshakeStateTLS13) sendServerParameters() error {
 
 	clientSecret := hs.suite.deriveSecret(hs.handshakeSecret,
 		clientHandshakeTrafficLabel, hs.transcript)
-	c.in.setTrafficSecret(hs.suite, clientSecret)
+	c.in.setTrafficSecret(hs.suite, QUICEncryptionLevelHandshake, clientSecret)
 	serverSecret := hs.suite.deriveSecret(hs.handshakeSecret,
 		serverHandshakeTrafficLabel, hs.transcript)
-	c.out.setTrafficSecret(hs.suite, serverSecret)
+	c.out.setTrafficSecret(hs.suite, QUICEncryptionLevelHandshake, serverSecret)
+
+	if c.quic != nil {
+		if c.hand.Len() != 0 {
+			c.sendAlert(alertUnexpectedMessage)
+			return errors.New("tls: handshake data received at the wrong encryption level")
+		}
+		c.quicSetWriteSecret(QUICEncryptionLevelHandshake, hs.suite.id, serverSecret)
+		c.quicSetReadSecret(QUICEncryptionLevelHandshake, hs.suite.id, clientSecret)
+	}
 
 	err := c.config.writeKeyLog(keyLogLabelClientHandshake, hs.clientHello.random, clientSecret)
 	if err != nil {
@@ -551,12 +691,27 @@ func (hs *serverHandshakeStateTLS13) sendServerParameters() error {
 	}
 
 	encryptedExtensions := new(encryptedExtensionsMsg)
+	encryptedExtensions.alpnProtocol = c.clientProtocol
 
-	if len(hs.clientHello.alpnProtocols) > 0 {
-		if selectedProto, fallback := mutualProtocol(hs.clientHello.alpnProtocols, c.config.NextProtos); !fallback {
-			encryptedExtensions.alpnProtocol = selectedProto
-			c.clientProtocol = selectedProto
+	// If the client offered ECH and it was rejected, send the retry
+	// configs. See draft-ietf-tls-esni-18, Section 7.1.
+	if hs.echContext == nil && len(hs.clientHello.encryptedClientHello) != 0 &&
+		len(c.config.EncryptedClientHelloKeys) != 0 {
+		retryConfigs, err := buildRetryConfigList(c.config.EncryptedClientHelloKeys)
+		if err != nil {
+			c.sendAlert(alertInternalError)
+			return err
+		}
+		encryptedExtensions.echRetryConfigs = retryConfigs
+	}
+
+	if c.quic != nil {
+		p, err := c.quicGetTransportParameters()
+		if err != nil {
+			return err
 		}
+		encryptedExtensions.quicTransportParameters = p
+		encryptedExtensions.earlyData = hs.earlyData
 	}
 
 	hs.transcript.Write(encryptedExtensions.marshal())
@@ -662,7 +817,15 @@ func (hs *serverHandshakeStateTLS13) sendServerFinished() error {
 		clientApplicationTrafficLabel, hs.transcript)
 	serverSecret := hs.suite.deriveSecret(hs.masterSecret,
 		serverApplicationTrafficLabel, hs.transcript)
-	c.out.setTrafficSecret(hs.suite, serverSecret)
+	c.out.setTrafficSecret(hs.suite, QUICEncryptionLevelApplication, serverSecret)
+
+	if c.quic != nil {
+		if c.hand.Len() != 0 {
+			c.sendAlert(alertUnexpectedMessage)
+			return errors.New("tls: handshake data received at the wrong encryption level")
+		}
+		c.quicSetWriteSecret(QUICEncryptionLevelApplication, hs.suite.id, serverSecret)
+	}
 
 	err := c.config.writeKeyLog(keyLogLabelClientTraffic, hs.clientHello.random, hs.trafficSecret)
 	if err != nil {
@@ -694,6 +857,11 @@ func (hs *serverHandshakeStateTLS13) shouldSendSessionTickets() bool {
 		return false
 	}
 
+	// QUIC tickets are sent by QUICConn.SendSessionTicket, not automatically.
+	if hs.c.quic != nil {
+		return false
+	}
+
 	// Don't send tickets the client wouldn't use. See RFC 8446, Section 4.2.9.
 	for _, pskMode := range hs.clientHello.pskModes {
 		if pskMode == pskModeDHE {
@@ -712,13 +880,28 @@ func (hs *serverHandshakeStateTLS13) sendSessionTickets() error {
 	}
 	hs.transcript.Write(finishedMsg.marshal())
 
-	if !hs.shouldSendSessionTickets() {
+	if c.config.SessionTicketsDisabled {
 		return nil
 	}
 
-	resumptionSecret := hs.suite.deriveSecret(hs.masterSecret,
+	c.resumptionSecret = hs.suite.deriveSecret(hs.masterSecret,
 		resumptionLabel, hs.transcript)
 
+	if !hs.shouldSendSessionTickets() {
+		return nil
+	}
+	return c.sendSessionTicket(false)
+}
+
+// sendSessionTicket sends a NewSessionTicket message derived from
+// c.resumptionSecret. If earlyData is true, the ticket allows the client
+// to send 0-RTT data when resuming over QUIC.
+func (c *Conn) sendSessionTicket(earlyData bool) error {
+	suite := cipherSuiteTLS13ByID(c.cipherSuite)
+	if suite == nil || c.resumptionSecret == nil {
+		return errors.New("tls: internal error: missing resumption secret")
+	}
+
 	m := new(newSessionTicketMsgTLS13)
 
 	var certsFromClient [][]byte
@@ -726,14 +909,16 @@ func (hs *serverHandshakeStateTLS13) sendSessionTickets() error {
 		certsFromClient = append(certsFromClient, cert.Raw)
 	}
 	state := sessionStateTLS13{
-		cipherSuite:      hs.suite.id,
+		cipherSuite:      suite.id,
 		createdAt:        uint64(c.config.time().Unix()),
-		resumptionSecret: resumptionSecret,
+		resumptionSecret: c.resumptionSecret,
 		certificate: Certificate{
 			Certificate:                 certsFromClient,
 			OCSPStaple:                  c.ocspResponse,
 			SignedCertificateTimestamps: c.scts,
 		},
+		earlyData:    earlyData,
+		alpnProtocol: c.clientProtocol,
 	}
 	var err error
 	m.label, err = c.encryptTicket(state.marshal())
@@ -741,6 +926,10 @@ func (hs *serverHandshakeStateTLS13) sendSessionTickets() error {
 		return err
 	}
 	m.lifetime = uint32(maxSessionTicketLifetime / time.Second)
+	if earlyData {
+		// RFC 9001, Section 4.6.1
+		m.maxEarlyData = 0xffffffff
+	}
 
 	if _, err := c.writeRecord(recordTypeHandshake, m.marshal()); err != nil {
 		return err
@@ -838,7 +1027,7 @@ func (hs *serverHandshakeStateTLS13) readClientFinished() error {
 		return errors.New("tls: invalid client finished hash")
 	}
 
-	c.in.setTrafficSecret(hs.suite, hs.trafficSecret)
+	c.in.setTrafficSecret(hs.suite, QUICEncryptionLevelApplication, hs.trafficSecret)
 
 	return nil
 }
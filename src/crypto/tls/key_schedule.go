@@ -7,6 +7,7 @@ package tls
 import (
 	"crypto/elliptic"
 	"crypto/hmac"
+	"crypto/internal/mlkem768"
 	"errors"
 	"hash"
 	"io"
@@ -22,6 +23,7 @@ import (
 
 const (
 	resumptionBinderLabel         = "res binder"
+	clientEarlyTrafficLabel       = "c e traffic"
 	clientHandshakeTrafficLabel   = "c hs traffic"
 	serverHandshakeTrafficLabel   = "s hs traffic"
 	clientApplicationTrafficLabel = "c ap traffic"
@@ -102,7 +104,8 @@ func (c *cipherSuiteTLS13) exportKeyingMaterial(masterSecret []byte, transcript
 }
 
 // ecdheParameters implements Diffie-Hellman with either NIST curves or X25519,
-// according to RFC 8446, Section 4.2.8.2.
+// according to RFC 8446, Section 4.2.8.2, or the client side of the
+// X25519MLKEM768 hybrid key exchange.
 type ecdheParameters interface {
 	CurveID() CurveID
 	PublicKey() []byte
@@ -110,6 +113,18 @@ type ecdheParameters interface {
 }
 
 func generateECDHEParameters(rand io.Reader, curveID CurveID) (ecdheParameters, error) {
+	if curveID == X25519MLKEM768 {
+		ecdhe, err := generateECDHEParameters(rand, X25519)
+		if err != nil {
+			return nil, err
+		}
+		dk, err := mlkem768.GenerateKey()
+		if err != nil {
+			return nil, err
+		}
+		return &x25519MLKEM768Parameters{x25519: ecdhe, mlkem: dk}, nil
+	}
+
 	if curveID == X25519 {
 		privateKey := make([]byte, curve25519.ScalarSize)
 		if _, err := io.ReadFull(rand, privateKey); err != nil {
@@ -200,3 +215,60 @@ func (p *x25519Parameters) SharedKey(peerPublicKey []byte) []byte {
 	}
 	return sharedKey
 }
+
+// x25519MLKEM768Parameters implements the client side of the X25519MLKEM768
+// hybrid key exchange. The client key share is the ML-KEM-768 encapsulation
+// key followed by the X25519 public key, and the server key share is the
+// ML-KEM-768 ciphertext followed by the X25519 public key. The shared key is
+// the concatenation of the two shared secrets, ML-KEM first.
+type x25519MLKEM768Parameters struct {
+	x25519 ecdheParameters
+	mlkem  *mlkem768.DecapsulationKey
+}
+
+func (p *x25519MLKEM768Parameters) CurveID() CurveID {
+	return X25519MLKEM768
+}
+
+func (p *x25519MLKEM768Parameters) PublicKey() []byte {
+	return append(p.mlkem.EncapsulationKey(), p.x25519.PublicKey()...)
+}
+
+func (p *x25519MLKEM768Parameters) SharedKey(peerPublicKey []byte) []byte {
+	if len(peerPublicKey) != mlkem768.CiphertextSize+curve25519.PointSize {
+		return nil
+	}
+	mlkemShared, err := mlkem768.Decapsulate(p.mlkem, peerPublicKey[:mlkem768.CiphertextSize])
+	if err != nil {
+		return nil
+	}
+	x25519Shared := p.x25519.SharedKey(peerPublicKey[mlkem768.CiphertextSize:])
+	if x25519Shared == nil {
+		return nil
+	}
+	return append(mlkemShared, x25519Shared...)
+}
+
+// x25519MLKEM768Encapsulate implements the server side of the X25519MLKEM768
+// hybrid key exchange, returning the server key share and the shared key for
+// the client key share clientShare.
+func x25519MLKEM768Encapsulate(rand io.Reader, clientShare []byte) (serverShare, sharedKey []byte, err error) {
+	if len(clientShare) != mlkem768.EncapsulationKeySize+curve25519.PointSize {
+		return nil, nil, errors.New("tls: invalid X25519MLKEM768 client key share")
+	}
+	ciphertext, mlkemShared, err := mlkem768.Encapsulate(clientShare[:mlkem768.EncapsulationKeySize])
+	if err != nil {
+		return nil, nil, err
+	}
+	ecdhe, err := generateECDHEParameters(rand, X25519)
+	if err != nil {
+		return nil, nil, err
+	}
+	x25519Shared := ecdhe.SharedKey(clientShare[mlkem768.EncapsulationKeySize:])
+	if x25519Shared == nil {
+		return nil, nil, errors.New("tls: invalid X25519MLKEM768 client key share")
+	}
+	serverShare = append(ciphertext, ecdhe.PublicKey()...)
+	sharedKey = append(mlkemShared, x25519Shared...)
+	return serverShare, sharedKey, nil
+}
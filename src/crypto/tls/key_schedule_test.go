@@ -6,11 +6,15 @@ package tls
 
 import (
 	"bytes"
+	"crypto/internal/mlkem768"
+	"crypto/rand"
 	"encoding/hex"
 	"hash"
 	"strings"
 	"testing"
 	"unicode"
+
+	"golang.org/x/crypto/curve25519"
 )
 
 // This file contains tests derived from draft-ietf-tls-tls13-vectors-07.
@@ -173,3 +177,73 @@ func TestExtract(t *testing.T) {
 		})
 	}
 }
+
+func TestX25519MLKEM768KeyShare(t *testing.T) {
+	params, err := generateECDHEParameters(rand.Reader, X25519MLKEM768)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientShare := params.PublicKey()
+	if len(clientShare) != mlkem768.EncapsulationKeySize+curve25519.PointSize {
+		t.Fatalf("client key share is %d bytes long", len(clientShare))
+	}
+	serverShare, serverKey, err := x25519MLKEM768Encapsulate(rand.Reader, clientShare)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(serverShare) != mlkem768.CiphertextSize+curve25519.PointSize {
+		t.Fatalf("server key share is %d bytes long", len(serverShare))
+	}
+	clientKey := params.SharedKey(serverShare)
+	if !bytes.Equal(clientKey, serverKey) || len(clientKey) != 64 {
+		t.Errorf("shared keys differ: client %x, server %x", clientKey, serverKey)
+	}
+
+	if params.SharedKey(serverShare[1:]) != nil {
+		t.Error("SharedKey accepted a short server key share")
+	}
+	if _, _, err := x25519MLKEM768Encapsulate(rand.Reader, clientShare[1:]); err == nil {
+		t.Error("x25519MLKEM768Encapsulate accepted a short client key share")
+	}
+}
+
+func TestHandshakeX25519MLKEM768(t *testing.T) {
+	for _, tt := range []struct {
+		name           string
+		client, server []CurveID
+		maxVersion     uint16
+	}{
+		{"hybrid", []CurveID{X25519MLKEM768}, []CurveID{X25519MLKEM768}, 0},
+		// The client sends a hybrid key share, and the server sends a
+		// HelloRetryRequest for X25519.
+		{"HelloRetryRequest", []CurveID{X25519MLKEM768, X25519}, []CurveID{X25519}, 0},
+		// The server sends a HelloRetryRequest for the hybrid group.
+		{"HelloRetryRequestHybrid", []CurveID{X25519, X25519MLKEM768}, []CurveID{X25519MLKEM768}, 0},
+		{"ServerOnly", nil, []CurveID{X25519MLKEM768, X25519}, 0},
+		// TLS 1.2 ignores the hybrid group.
+		{"TLS12", []CurveID{X25519MLKEM768, CurveP256}, []CurveID{X25519MLKEM768, CurveP256}, VersionTLS12},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			clientConfig := testConfig.Clone()
+			clientConfig.CurvePreferences = tt.client
+			clientConfig.MaxVersion = tt.maxVersion
+			serverConfig := testConfig.Clone()
+			serverConfig.CurvePreferences = tt.server
+			serverConfig.MaxVersion = tt.maxVersion
+			if _, _, err := testHandshake(t, clientConfig, serverConfig); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+
+	// A TLS 1.2 handshake fails if the hybrid group is the only one.
+	clientConfig := testConfig.Clone()
+	clientConfig.MaxVersion = VersionTLS12
+	clientConfig.CipherSuites = []uint16{TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}
+	clientConfig.CurvePreferences = []CurveID{X25519MLKEM768}
+	serverConfig := testConfig.Clone()
+	serverConfig.CurvePreferences = []CurveID{X25519MLKEM768}
+	if _, _, err := testHandshake(t, clientConfig, serverConfig); err == nil {
+		t.Error("TLS 1.2 handshake with only X25519MLKEM768 succeeded")
+	}
+}
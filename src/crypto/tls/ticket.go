@@ -107,26 +107,38 @@ func (s *sessionState) unmarshal(data []byte) bool {
 
 // sessionStateTLS13 is the content of a TLS 1.3 session ticket. Its first
 // version (revision = 0) doesn't carry any of the information needed for 0-RTT
-// validation and the nonce is always empty.
+// validation and the nonce is always empty. Revision 1 adds the fields needed
+// to accept QUIC 0-RTT, and is only used for tickets that allow it.
 type sessionStateTLS13 struct {
 	// uint8 version  = 0x0304;
-	// uint8 revision = 0;
+	// uint8 revision = 0 or 1;
 	cipherSuite      uint16
 	createdAt        uint64
 	resumptionSecret []byte      // opaque resumption_master_secret<1..2^8-1>;
 	certificate      Certificate // CertificateEntry certificate_list<0..2^24-1>;
+	earlyData        bool        // revision 1 only
+	alpnProtocol     string      // revision 1 only: opaque alpn_protocol<0..2^8-1>;
 }
 
 func (m *sessionStateTLS13) marshal() []byte {
 	var b cryptobyte.Builder
 	b.AddUint16(VersionTLS13)
-	b.AddUint8(0) // revision
+	if m.earlyData {
+		b.AddUint8(1) // revision
+	} else {
+		b.AddUint8(0) // revision
+	}
 	b.AddUint16(m.cipherSuite)
 	addUint64(&b, m.createdAt)
 	b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) {
 		b.AddBytes(m.resumptionSecret)
 	})
 	marshalCertificate(&b, m.certificate)
+	if m.earlyData {
+		b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) {
+			b.AddBytes([]byte(m.alpnProtocol))
+		})
+	}
 	return b.BytesOrPanic()
 }
 
@@ -135,16 +147,21 @@ func (m *sessionStateTLS13) unmarshal(data []byte) bool {
 	s := cryptobyte.String(data)
 	var version uint16
 	var revision uint8
-	return s.ReadUint16(&version) &&
+	var alpn []byte
+	ok := s.ReadUint16(&version) &&
 		version == VersionTLS13 &&
 		s.ReadUint8(&revision) &&
-		revision == 0 &&
+		revision <= 1 &&
 		s.ReadUint16(&m.cipherSuite) &&
 		readUint64(&s, &m.createdAt) &&
 		readUint8LengthPrefixed(&s, &m.resumptionSecret) &&
 		len(m.resumptionSecret) != 0 &&
 		unmarshalCertificate(&s, &m.certificate) &&
+		(revision == 0 || readUint8LengthPrefixed(&s, &alpn)) &&
 		s.Empty()
+	m.earlyData = revision == 1
+	m.alpnProtocol = string(alpn)
+	return ok
 }
 
 func (c *Conn) encryptTicket(state []byte) ([]byte, error) {
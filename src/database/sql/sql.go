@@ -404,6 +404,9 @@ type DB struct {
 	// on 32-bit platforms. Of type time.Duration.
 	waitDuration int64 // Total time waited for new connections.
 
+	// Atomic access only.
+	badConnClosed int64 // Total number of connections closed due to driver.ErrBadConn.
+
 	connector driver.Connector
 	// numClosed is an atomic counter which represents a total number of
 	// closed connections. Stmt.openStmt checks it before cleaning closed
@@ -428,10 +431,12 @@ type DB struct {
 	maxIdle           int                    // zero means defaultMaxIdleConns; negative means 0
 	maxOpen           int                    // <= 0 means unlimited
 	maxLifetime       time.Duration          // maximum amount of time a connection may be reused
+	maxIdleTime       time.Duration          // maximum amount of time a connection may be idle before being closed
 	cleanerCh         chan struct{}
 	waitCount         int64 // Total number of connections waited for.
-	maxIdleClosed     int64 // Total number of connections closed due to idle.
-	maxLifetimeClosed int64 // Total number of connections closed due to max free limit.
+	maxIdleClosed     int64 // Total number of connections closed due to idle count.
+	maxIdleTimeClosed int64 // Total number of connections closed due to idle time.
+	maxLifetimeClosed int64 // Total number of connections closed due to max connection lifetime limit.
 
 	stop func() // stop cancels the connection opener and the session resetter.
 }
@@ -465,8 +470,14 @@ type driverConn struct {
 
 	// guarded by db.mu
 	inUse      bool
-	onPut      []func() // code (with db.mu held) run when conn is next returned
-	dbmuClosed bool     // same as closed, but guarded by db.mu, for removeClosedStmtLocked
+	returnedAt time.Time // Time the connection was created or returned.
+	onPut      []func()  // code (with db.mu held) run when conn is next returned
+	dbmuClosed bool      // same as closed, but guarded by db.mu, for removeClosedStmtLocked
+
+	// trace is the Trace of the context the connection was last
+	// acquired with, if any. It is only accessed by the holder of the
+	// connection.
+	trace *Trace
 }
 
 func (dc *driverConn) releaseConn(err error) {
@@ -930,12 +941,50 @@ func (db *DB) SetConnMaxLifetime(d time.Duration) {
 	db.mu.Unlock()
 }
 
+// SetConnMaxIdleTime sets the maximum amount of time a connection may be idle.
+//
+// Expired connections may be closed lazily before reuse.
+//
+// If d <= 0, connections are not closed due to a connection's idle time.
+func (db *DB) SetConnMaxIdleTime(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	// Wake cleaner up when idle time is shortened.
+	if d > 0 && d < db.maxIdleTime && db.cleanerCh != nil {
+		select {
+		case db.cleanerCh <- struct{}{}:
+		default:
+		}
+	}
+	db.maxIdleTime = d
+	db.startCleanerLocked()
+}
+
 // startCleanerLocked starts connectionCleaner if needed.
 func (db *DB) startCleanerLocked() {
-	if db.maxLifetime > 0 && db.numOpen > 0 && db.cleanerCh == nil {
+	if (db.maxLifetime > 0 || db.maxIdleTime > 0) && db.numOpen > 0 && db.cleanerCh == nil {
 		db.cleanerCh = make(chan struct{}, 1)
-		go db.connectionCleaner(db.maxLifetime)
+		go db.connectionCleaner(db.shortestIdleTimeLocked())
+	}
+}
+
+// shortestIdleTimeLocked returns the shorter of the non-zero connection
+// lifetime and idle time limits, or zero if neither is set.
+func (db *DB) shortestIdleTimeLocked() time.Duration {
+	if db.maxIdleTime <= 0 {
+		return db.maxLifetime
+	}
+	if db.maxLifetime <= 0 {
+		return db.maxIdleTime
+	}
+	if db.maxIdleTime < db.maxLifetime {
+		return db.maxIdleTime
 	}
+	return db.maxLifetime
 }
 
 func (db *DB) connectionCleaner(d time.Duration) {
@@ -949,31 +998,18 @@ func (db *DB) connectionCleaner(d time.Duration) {
 	for {
 		select {
 		case <-t.C:
-		case <-db.cleanerCh: // maxLifetime was changed or db was closed.
+		case <-db.cleanerCh: // maxLifetime or maxIdleTime was changed or db was closed.
 		}
 
 		db.mu.Lock()
-		d = db.maxLifetime
+		d = db.shortestIdleTimeLocked()
 		if db.closed || db.numOpen == 0 || d <= 0 {
 			db.cleanerCh = nil
 			db.mu.Unlock()
 			return
 		}
 
-		expiredSince := nowFunc().Add(-d)
-		var closing []*driverConn
-		for i := 0; i < len(db.freeConn); i++ {
-			c := db.freeConn[i]
-			if c.createdAt.Before(expiredSince) {
-				closing = append(closing, c)
-				last := len(db.freeConn) - 1
-				db.freeConn[i] = db.freeConn[last]
-				db.freeConn[last] = nil
-				db.freeConn = db.freeConn[:last]
-				i--
-			}
-		}
-		db.maxLifetimeClosed += int64(len(closing))
+		closing := db.connectionCleanerRunLocked()
 		db.mu.Unlock()
 
 		for _, c := range closing {
@@ -987,6 +1023,46 @@ func (db *DB) connectionCleaner(d time.Duration) {
 	}
 }
 
+// connectionCleanerRunLocked removes from the idle pool the connections
+// that have exceeded the lifetime or idle time limits and returns them
+// so that the caller can close them without holding db.mu.
+func (db *DB) connectionCleanerRunLocked() (closing []*driverConn) {
+	if db.maxLifetime > 0 {
+		expiredSince := nowFunc().Add(-db.maxLifetime)
+		n := len(closing)
+		closing = db.removeFreeConnLocked(closing, func(c *driverConn) bool {
+			return c.createdAt.Before(expiredSince)
+		})
+		db.maxLifetimeClosed += int64(len(closing) - n)
+	}
+	if db.maxIdleTime > 0 {
+		expiredSince := nowFunc().Add(-db.maxIdleTime)
+		n := len(closing)
+		closing = db.removeFreeConnLocked(closing, func(c *driverConn) bool {
+			return c.returnedAt.Before(expiredSince)
+		})
+		db.maxIdleTimeClosed += int64(len(closing) - n)
+	}
+	return closing
+}
+
+// removeFreeConnLocked removes from db.freeConn the connections for which
+// expired returns true and appends them to closing.
+func (db *DB) removeFreeConnLocked(closing []*driverConn, expired func(*driverConn) bool) []*driverConn {
+	for i := 0; i < len(db.freeConn); i++ {
+		c := db.freeConn[i]
+		if expired(c) {
+			closing = append(closing, c)
+			last := len(db.freeConn) - 1
+			db.freeConn[i] = db.freeConn[last]
+			db.freeConn[last] = nil
+			db.freeConn = db.freeConn[:last]
+			i--
+		}
+	}
+	return closing
+}
+
 // DBStats contains database statistics.
 type DBStats struct {
 	MaxOpenConnections int // Maximum number of open connections to the database.
@@ -1000,12 +1076,15 @@ type DBStats struct {
 	WaitCount         int64         // The total number of connections waited for.
 	WaitDuration      time.Duration // The total time blocked waiting for a new connection.
 	MaxIdleClosed     int64         // The total number of connections closed due to SetMaxIdleConns.
+	MaxIdleTimeClosed int64         // The total number of connections closed due to SetConnMaxIdleTime.
 	MaxLifetimeClosed int64         // The total number of connections closed due to SetConnMaxLifetime.
+	BadConnClosed     int64         // The total number of connections closed due to driver.ErrBadConn.
 }
 
 // Stats returns database statistics.
 func (db *DB) Stats() DBStats {
 	wait := atomic.LoadInt64(&db.waitDuration)
+	badConn := atomic.LoadInt64(&db.badConnClosed)
 
 	db.mu.Lock()
 	defer db.mu.Unlock()
@@ -1020,7 +1099,9 @@ func (db *DB) Stats() DBStats {
 		WaitCount:         db.waitCount,
 		WaitDuration:      time.Duration(wait),
 		MaxIdleClosed:     db.maxIdleClosed,
+		MaxIdleTimeClosed: db.maxIdleTimeClosed,
 		MaxLifetimeClosed: db.maxLifetimeClosed,
+		BadConnClosed:     badConn,
 	}
 	return stats
 }
@@ -1097,9 +1178,10 @@ func (db *DB) openNewConnection(ctx context.Context) {
 		return
 	}
 	dc := &driverConn{
-		db:        db,
-		createdAt: nowFunc(),
-		ci:        ci,
+		db:         db,
+		createdAt:  nowFunc(),
+		returnedAt: nowFunc(),
+		ci:         ci,
 	}
 	if db.putConnDBLocked(dc, err) {
 		db.addDepLocked(dc, dc)
@@ -1129,6 +1211,13 @@ func (db *DB) nextRequestKeyLocked() uint64 {
 
 // conn returns a newly-opened or cached *driverConn.
 func (db *DB) conn(ctx context.Context, strategy connReuseStrategy) (*driverConn, error) {
+	// Call the GetConn hook before taking db.mu, so that
+	// it may use the DB.
+	trace := ContextTrace(ctx)
+	if trace != nil && trace.GetConn != nil {
+		trace.GetConn()
+	}
+
 	db.mu.Lock()
 	if db.closed {
 		db.mu.Unlock()
@@ -1150,19 +1239,24 @@ func (db *DB) conn(ctx context.Context, strategy connReuseStrategy) (*driverConn
 		copy(db.freeConn, db.freeConn[1:])
 		db.freeConn = db.freeConn[:numFree-1]
 		conn.inUse = true
-		db.mu.Unlock()
 		if conn.expired(lifetime) {
+			db.maxLifetimeClosed++
+			db.mu.Unlock()
 			conn.Close()
 			return nil, driver.ErrBadConn
 		}
+		idle := nowFunc().Sub(conn.returnedAt)
+		db.mu.Unlock()
 		// Lock around reading lastErr to ensure the session resetter finished.
 		conn.Lock()
 		err := conn.lastErr
 		conn.Unlock()
 		if err == driver.ErrBadConn {
+			atomic.AddInt64(&db.badConnClosed, 1)
 			conn.Close()
 			return nil, driver.ErrBadConn
 		}
+		conn.traceGotConn(trace, GotConnInfo{WasIdle: true, IdleTime: idle})
 		return conn, nil
 	}
 
@@ -1199,12 +1293,16 @@ func (db *DB) conn(ctx context.Context, strategy connReuseStrategy) (*driverConn
 			}
 			return nil, ctx.Err()
 		case ret, ok := <-req:
-			atomic.AddInt64(&db.waitDuration, int64(time.Since(waitStart)))
+			wait := time.Since(waitStart)
+			atomic.AddInt64(&db.waitDuration, int64(wait))
 
 			if !ok {
 				return nil, errDBClosed
 			}
 			if ret.err == nil && ret.conn.expired(lifetime) {
+				db.mu.Lock()
+				db.maxLifetimeClosed++
+				db.mu.Unlock()
 				ret.conn.Close()
 				return nil, driver.ErrBadConn
 			}
@@ -1216,16 +1314,24 @@ func (db *DB) conn(ctx context.Context, strategy connReuseStrategy) (*driverConn
 			err := ret.conn.lastErr
 			ret.conn.Unlock()
 			if err == driver.ErrBadConn {
+				atomic.AddInt64(&db.badConnClosed, 1)
 				ret.conn.Close()
 				return nil, driver.ErrBadConn
 			}
+			ret.conn.traceGotConn(trace, GotConnInfo{WaitDuration: wait})
 			return ret.conn, ret.err
 		}
 	}
 
 	db.numOpen++ // optimistically
 	db.mu.Unlock()
+	if trace != nil && trace.ConnectStart != nil {
+		trace.ConnectStart()
+	}
 	ci, err := db.connector.Connect(ctx)
+	if trace != nil && trace.ConnectDone != nil {
+		trace.ConnectDone(err)
+	}
 	if err != nil {
 		db.mu.Lock()
 		db.numOpen-- // correct for earlier optimism
@@ -1235,13 +1341,15 @@ func (db *DB) conn(ctx context.Context, strategy connReuseStrategy) (*driverConn
 	}
 	db.mu.Lock()
 	dc := &driverConn{
-		db:        db,
-		createdAt: nowFunc(),
-		ci:        ci,
-		inUse:     true,
+		db:         db,
+		createdAt:  nowFunc(),
+		returnedAt: nowFunc(),
+		ci:         ci,
+		inUse:      true,
 	}
 	db.addDepLocked(dc, dc)
 	db.mu.Unlock()
+	dc.traceGotConn(trace, GotConnInfo{})
 	return dc, nil
 }
 
@@ -1275,6 +1383,12 @@ const debugGetPut = false
 // putConn adds a connection to the db's free pool.
 // err is optionally the last error that occurred on this connection.
 func (db *DB) putConn(dc *driverConn, err error, resetSession bool) {
+	if trace := dc.trace; trace != nil {
+		dc.trace = nil
+		if trace.PutConn != nil {
+			trace.PutConn(err)
+		}
+	}
 	db.mu.Lock()
 	if !dc.inUse {
 		if debugGetPut {
@@ -1286,6 +1400,7 @@ func (db *DB) putConn(dc *driverConn, err error, resetSession bool) {
 		db.lastPut[dc] = stack()
 	}
 	dc.inUse = false
+	dc.returnedAt = nowFunc()
 
 	for _, fn := range dc.onPut {
 		fn()
@@ -1297,6 +1412,7 @@ func (db *DB) putConn(dc *driverConn, err error, resetSession bool) {
 		// Since the conn is considered bad and is being discarded, treat it
 		// as closed. Don't decrement the open count here, finalClose will
 		// take care of that.
+		atomic.AddInt64(&db.badConnClosed, 1)
 		db.maybeOpenNewConnections()
 		db.mu.Unlock()
 		dc.Close()
@@ -1500,7 +1616,9 @@ func (db *DB) exec(ctx context.Context, query string, args []interface{}, strate
 }
 
 func (db *DB) execDC(ctx context.Context, dc *driverConn, release func(error), query string, args []interface{}) (res Result, err error) {
+	done := traceQuery(ctx, query, args)
 	defer func() {
+		done(err)
 		release(err)
 	}()
 	execerCtx, ok := dc.ci.(driver.ExecerContext)
@@ -1575,6 +1693,7 @@ func (db *DB) query(ctx context.Context, query string, args []interface{}, strat
 // The ctx context is from a query method and the txctx context is from an
 // optional transaction context.
 func (db *DB) queryDC(ctx, txctx context.Context, dc *driverConn, releaseConn func(error), query string, args []interface{}) (*Rows, error) {
+	done := traceQuery(ctx, query, args)
 	queryerCtx, ok := dc.ci.(driver.QueryerContext)
 	var queryer driver.Queryer
 	if !ok {
@@ -1592,6 +1711,7 @@ func (db *DB) queryDC(ctx, txctx context.Context, dc *driverConn, releaseConn fu
 			rowsi, err = ctxDriverQuery(ctx, queryerCtx, queryer, query, nvdargs)
 		})
 		if err != driver.ErrSkip {
+			done(err)
 			if err != nil {
 				releaseConn(err)
 				return nil, err
@@ -1614,12 +1734,14 @@ func (db *DB) queryDC(ctx, txctx context.Context, dc *driverConn, releaseConn fu
 		si, err = ctxDriverPrepare(ctx, dc.ci, query)
 	})
 	if err != nil {
+		done(err)
 		releaseConn(err)
 		return nil, err
 	}
 
 	ds := &driverStmt{Locker: dc, si: si}
 	rowsi, err := rowsiFromStatement(ctx, dc.ci, ds, args...)
+	done(err)
 	if err != nil {
 		ds.Close()
 		releaseConn(err)
@@ -1704,6 +1826,9 @@ func (db *DB) beginDC(ctx context.Context, dc *driverConn, release func(error),
 	withLock(dc, func() {
 		txi, err = ctxDriverBegin(ctx, opts, dc.ci)
 	})
+	if trace := ContextTrace(ctx); trace != nil && trace.TxBegin != nil {
+		trace.TxBegin(err)
+	}
 	if err != nil {
 		release(err)
 		return nil, err
@@ -2094,6 +2219,9 @@ func (tx *Tx) Commit() error {
 	withLock(tx.dc, func() {
 		err = tx.txi.Commit()
 	})
+	if trace := ContextTrace(tx.ctx); trace != nil && trace.TxCommit != nil {
+		trace.TxCommit(err)
+	}
 	if err != driver.ErrBadConn {
 		tx.closePrepared()
 	}
@@ -2111,6 +2239,9 @@ func (tx *Tx) rollback(discardConn bool) error {
 	withLock(tx.dc, func() {
 		err = tx.txi.Rollback()
 	})
+	if trace := ContextTrace(tx.ctx); trace != nil && trace.TxRollback != nil {
+		trace.TxRollback(err)
+	}
 	if err != driver.ErrBadConn {
 		tx.closePrepared()
 	}
@@ -2408,7 +2539,9 @@ func (s *Stmt) ExecContext(ctx context.Context, args ...interface{}) (Result, er
 			return nil, err
 		}
 
+		done := traceQuery(ctx, s.query, args)
 		res, err = resultFromStatement(ctx, dc.ci, ds, args...)
+		done(err)
 		releaseConn(err)
 		if err != driver.ErrBadConn {
 			return res, err
@@ -2553,7 +2686,9 @@ func (s *Stmt) QueryContext(ctx context.Context, args ...interface{}) (*Rows, er
 			return nil, err
 		}
 
+		done := traceQuery(ctx, s.query, args)
 		rowsi, err = rowsiFromStatement(ctx, dc.ci, ds, args...)
+		done(err)
 		if err == nil {
 			// Note: ownership of ci passes to the *Rows, to be freed
 			// with releaseConn.
@@ -2299,6 +2299,80 @@ func TestConnMaxLifetime(t *testing.T) {
 	if closes != 1 {
 		t.Errorf("closes = %d; want 1", closes)
 	}
+	if g, w := db.Stats().MaxLifetimeClosed, int64(1); g != w {
+		t.Errorf("MaxLifetimeClosed = %d; want %d", g, w)
+	}
+}
+
+func TestConnMaxIdleTime(t *testing.T) {
+	usedConns := 5
+	list := []struct {
+		wantMaxIdleTime   time.Duration
+		wantIdleTimeClose int64
+		timeOffset        time.Duration
+	}{
+		{time.Nanosecond, int64(usedConns - 1), 10 * time.Millisecond},
+		{time.Hour, 0, 10 * time.Millisecond},
+	}
+	baseTime := time.Unix(0, 0)
+	defer func() {
+		nowFunc = time.Now
+	}()
+	for _, item := range list {
+		nowFunc = func() time.Time {
+			return baseTime
+		}
+		t.Run(fmt.Sprintf("%v", item.wantMaxIdleTime), func(t *testing.T) {
+			db := newTestDB(t, "people")
+			defer closeDB(t, db)
+
+			db.SetMaxOpenConns(usedConns)
+			db.SetMaxIdleConns(usedConns)
+			db.SetConnMaxIdleTime(item.wantMaxIdleTime)
+			db.SetConnMaxLifetime(0)
+
+			preMaxIdleTimeClosed := db.Stats().MaxIdleTimeClosed
+
+			ctx := context.Background()
+			// Busy all connections.
+			conns := make([]*Conn, usedConns)
+			for i := range conns {
+				conn, err := db.Conn(ctx)
+				if err != nil {
+					t.Fatal(err)
+				}
+				conns[i] = conn
+			}
+			for _, conn := range conns {
+				conn.Close()
+			}
+			nowFunc = func() time.Time {
+				return baseTime.Add(item.timeOffset)
+			}
+			// Reuse one connection so that it is no longer idle
+			// since baseTime.
+			conn, err := db.Conn(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			conn.Close()
+
+			db.mu.Lock()
+			closing := db.connectionCleanerRunLocked()
+			db.mu.Unlock()
+			for _, c := range closing {
+				c.Close()
+			}
+			if g, w := int64(len(closing)), item.wantIdleTimeClose; g != w {
+				t.Errorf("got: %d; want %d closed conns", g, w)
+			}
+
+			st := db.Stats()
+			if g, w := st.MaxIdleTimeClosed-preMaxIdleTimeClosed, item.wantIdleTimeClose; g != w {
+				t.Errorf("MaxIdleTimeClosed = %d; want %d", g, w)
+			}
+		})
+	}
 }
 
 // golang.org/issue/5323
@@ -3589,6 +3663,153 @@ func TestStatsMaxIdleClosedTen(t *testing.T) {
 	}
 }
 
+func TestStatsBadConnClosed(t *testing.T) {
+	db := newTestDB(t, "people")
+	defer closeDB(t, db)
+
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Raw(func(dc interface{}) error {
+		dc.(*fakeConn).stickyBad = true
+		return nil
+	})
+	if _, err := conn.ExecContext(context.Background(), "WIPE"); err != driver.ErrBadConn {
+		t.Fatalf("Exec error = %v; want %v", err, driver.ErrBadConn)
+	}
+	conn.Close()
+
+	if g, w := db.Stats().BadConnClosed, int64(1); g != w {
+		t.Errorf("BadConnClosed = %d; want %d", g, w)
+	}
+}
+
+func TestTrace(t *testing.T) {
+	db := newTestDB(t, "people")
+	defer closeDB(t, db)
+	db.clearAllConns(t)
+	db.SetMaxIdleConns(1)
+
+	var (
+		mu     sync.Mutex
+		events []string
+	)
+	record := func(format string, args ...interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, fmt.Sprintf(format, args...))
+	}
+	trace := &Trace{
+		GetConn:      func() { record("GetConn") },
+		GotConn:      func(info GotConnInfo) { record("GotConn idle=%v", info.WasIdle) },
+		PutConn:      func(err error) { record("PutConn %v", err) },
+		ConnectStart: func() { record("ConnectStart") },
+		ConnectDone:  func(err error) { record("ConnectDone %v", err) },
+		QueryStart:   func(info QueryStartInfo) { record("QueryStart %s %v", info.Query, info.Args) },
+		QueryDone:    func(err error) { record("QueryDone %v", err) },
+		TxBegin:      func(err error) { record("TxBegin %v", err) },
+		TxCommit:     func(err error) { record("TxCommit %v", err) },
+		TxRollback:   func(err error) { record("TxRollback %v", err) },
+	}
+	ctx := WithTrace(context.Background(), trace)
+	if got := ContextTrace(ctx); got != trace {
+		t.Fatalf("ContextTrace = %p; want %p", got, trace)
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT|people|name|age=?", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT|people|name=?,age=?", "Dave", 4); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err = db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Operations without a trace in their context are not traced.
+	if _, err := db.Exec("INSERT|people|name=?,age=?", "Eve", 5); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"GetConn",
+		"ConnectStart",
+		"ConnectDone <nil>",
+		"GotConn idle=false",
+		"QueryStart SELECT|people|name|age=? [3]",
+		"QueryDone <nil>",
+		"PutConn <nil>",
+		"GetConn",
+		"GotConn idle=true",
+		"TxBegin <nil>",
+		"QueryStart INSERT|people|name=?,age=? [Dave 4]",
+		"QueryDone <nil>",
+		"TxCommit <nil>",
+		"PutConn <nil>",
+		"GetConn",
+		"GotConn idle=true",
+		"TxBegin <nil>",
+		"TxRollback <nil>",
+		"PutConn <nil>",
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("trace events:\n%s\nwant:\n%s", strings.Join(events, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+// Trace hooks may call methods of the DB.
+func TestTraceHooksUseDB(t *testing.T) {
+	db := newTestDB(t, "people")
+
+	var stats []DBStats
+	trace := &Trace{
+		GetConn: func() { stats = append(stats, db.Stats()) },
+		GotConn: func(GotConnInfo) { stats = append(stats, db.Stats()) },
+		PutConn: func(error) { stats = append(stats, db.Stats()) },
+	}
+	ctx := WithTrace(context.Background(), trace)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := db.ExecContext(ctx, "INSERT|people|name=?,age=?", "Dave", 4)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		// Closing the DB would block on the deadlock too.
+		t.Fatal("timeout waiting for ExecContext; deadlock in a trace hook?")
+	}
+	closeDB(t, db)
+	if len(stats) != 3 {
+		t.Errorf("hooks called %d times; want 3", len(stats))
+	}
+}
+
 type nvcDriver struct {
 	fakeDriver
 	skipNamedValueCheck bool
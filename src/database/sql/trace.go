@@ -0,0 +1,136 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sql
+
+import (
+	"context"
+	"time"
+)
+
+// unique type to prevent assignment.
+type traceContextKey struct{}
+
+// Trace is a set of hooks to run at various stages of the database
+// operations issued with a context carrying the Trace. Any particular
+// hook may be nil. Functions may be called concurrently from different
+// goroutines.
+//
+// Operations that are retried after the driver reports
+// driver.ErrBadConn call the hooks once per attempt.
+type Trace struct {
+	// GetConn is called before a connection is taken from the
+	// idle pool or opened.
+	GetConn func()
+
+	// GotConn is called after a connection has been obtained.
+	// It is not called if obtaining a connection fails.
+	GotConn func(GotConnInfo)
+
+	// PutConn is called when a connection obtained by GotConn is
+	// released, either back to the idle pool or to be closed.
+	// The err is the last error that occurred on the connection, if any.
+	PutConn func(err error)
+
+	// ConnectStart is called before a new connection is opened
+	// with the driver.
+	ConnectStart func()
+
+	// ConnectDone is called when opening a new connection with the
+	// driver completes. The err indicates whether it succeeded.
+	ConnectDone func(err error)
+
+	// QueryStart is called before a query or statement is sent to
+	// the driver.
+	QueryStart func(QueryStartInfo)
+
+	// QueryDone is called when the driver returns from executing a
+	// query or statement. For queries, this is before any rows are
+	// read.
+	QueryDone func(err error)
+
+	// TxBegin is called after the driver begins a transaction.
+	// The err indicates whether it succeeded.
+	TxBegin func(err error)
+
+	// TxCommit is called after the driver commits a transaction.
+	TxCommit func(err error)
+
+	// TxRollback is called after the driver rolls back a transaction,
+	// including when the transaction's context is canceled.
+	TxRollback func(err error)
+}
+
+// GotConnInfo is the argument to the Trace.GotConn hook and contains
+// information about the obtained connection.
+type GotConnInfo struct {
+	// WasIdle is whether this connection was taken from the idle pool.
+	WasIdle bool
+
+	// IdleTime reports how long the connection was idle, if
+	// WasIdle is true.
+	IdleTime time.Duration
+
+	// WaitDuration is the time spent waiting for another operation
+	// to release a connection because the SetMaxOpenConns limit
+	// was reached.
+	WaitDuration time.Duration
+}
+
+// QueryStartInfo is the argument to the Trace.QueryStart hook.
+type QueryStartInfo struct {
+	// Query is the query text.
+	Query string
+
+	// Args are the query arguments as passed by the caller.
+	Args []interface{}
+}
+
+// WithTrace returns a new context based on the provided parent
+// ctx. Database operations made with the returned context will use
+// the provided trace hooks. A Trace already present in ctx is
+// replaced.
+func WithTrace(ctx context.Context, trace *Trace) context.Context {
+	if trace == nil {
+		panic("nil trace")
+	}
+	return context.WithValue(ctx, traceContextKey{}, trace)
+}
+
+// ContextTrace returns the Trace associated with the
+// provided context. If none, it returns nil.
+func ContextTrace(ctx context.Context) *Trace {
+	trace, _ := ctx.Value(traceContextKey{}).(*Trace)
+	return trace
+}
+
+// traceGotConn associates trace with dc, so that its PutConn hook is
+// called when dc is released, and calls the GotConn hook.
+func (dc *driverConn) traceGotConn(trace *Trace, info GotConnInfo) {
+	if trace == nil {
+		return
+	}
+	dc.trace = trace
+	if trace.GotConn != nil {
+		trace.GotConn(info)
+	}
+}
+
+func nopQueryDone(error) {}
+
+// traceQuery calls the QueryStart hook of the Trace in ctx, if any,
+// and returns a function that calls the matching QueryDone hook.
+func traceQuery(ctx context.Context, query string, args []interface{}) func(error) {
+	trace := ContextTrace(ctx)
+	if trace == nil {
+		return nopQueryDone
+	}
+	if trace.QueryStart != nil {
+		trace.QueryStart(QueryStartInfo{Query: query, Args: args})
+	}
+	if trace.QueryDone == nil {
+		return nopQueryDone
+	}
+	return trace.QueryDone
+}
@@ -0,0 +1,138 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package embedtest
+
+import (
+	"embed"
+	"io/ioutil"
+	"testing"
+	"testing/fstest"
+)
+
+//go:embed testdata/h*.txt
+//go:embed c*.txt testdata/g*.txt
+var global embed.FS
+
+//go:embed c*txt
+var concurrency string
+
+//go:embed testdata/g*.txt
+var glass []byte
+
+//go:embed testdata
+var testDirAll embed.FS
+
+//go:embed "testdata/hello.txt" `testdata/ken.txt`
+var quoted embed.FS
+
+func testFiles(t *testing.T, f embed.FS, name, data string) {
+	t.Helper()
+	d, err := f.ReadFile(name)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if string(d) != data {
+		t.Errorf("read %v = %q, want %q", name, d, data)
+	}
+}
+
+func testString(t *testing.T, s, name, data string) {
+	t.Helper()
+	if s != data {
+		t.Errorf("%v = %q, want %q", name, s, data)
+	}
+}
+
+func testDir(t *testing.T, f embed.FS, name string, expect ...string) {
+	t.Helper()
+	dirs, err := f.ReadDir(name)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	var names []string
+	for _, d := range dirs {
+		name := d.Name()
+		if d.IsDir() {
+			name += "/"
+		}
+		names = append(names, name)
+	}
+	if len(names) != len(expect) {
+		t.Errorf("readdir %v = %v, want %v", name, names, expect)
+		return
+	}
+	for i := range names {
+		if names[i] != expect[i] {
+			t.Errorf("readdir %v = %v, want %v", name, names, expect)
+			return
+		}
+	}
+}
+
+func TestGlobal(t *testing.T) {
+	testFiles(t, global, "concurrency.txt", "Concurrency is not parallelism.\n")
+	testFiles(t, global, "testdata/hello.txt", "hello, world\n")
+	testFiles(t, global, "testdata/glass.txt", "In a world of compromise, some don't.\n")
+
+	if err := fstest.TestFS(global, "concurrency.txt", "testdata/hello.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	testString(t, concurrency, "concurrency", "Concurrency is not parallelism.\n")
+	testString(t, string(glass), "glass", "In a world of compromise, some don't.\n")
+}
+
+func TestGlobalBytesWritable(t *testing.T) {
+	// The []byte variable must be backed by its own writable copy.
+	old := glass[0]
+	glass[0] = 'X'
+	glass[0] = old
+}
+
+func TestDir(t *testing.T) {
+	all := testDirAll
+	testFiles(t, all, "testdata/hello.txt", "hello, world\n")
+	testFiles(t, all, "testdata/i/j/k/k8s.txt", "k\n")
+	testFiles(t, all, "testdata/ken/name.txt", "ken\n")
+
+	testDir(t, all, ".", "testdata/")
+	testDir(t, all, "testdata", "glass.txt", "hello.txt", "i/", "ken/", "ken.txt")
+	testDir(t, all, "testdata/i/j", "k/")
+
+	// Files beginning with . or _ are excluded when embedding a directory.
+	if _, err := all.ReadFile("testdata/.hidden"); err == nil {
+		t.Errorf("ReadFile(testdata/.hidden) succeeded, want error")
+	}
+	if _, err := all.ReadFile("testdata/_underscore.txt"); err == nil {
+		t.Errorf("ReadFile(testdata/_underscore.txt) succeeded, want error")
+	}
+}
+
+func TestQuoted(t *testing.T) {
+	testFiles(t, quoted, "testdata/hello.txt", "hello, world\n")
+	testFiles(t, quoted, "testdata/ken.txt", "If a program is too slow, it must have a loop.\n")
+	testDir(t, quoted, "testdata", "hello.txt", "ken.txt")
+}
+
+func TestOpen(t *testing.T) {
+	f, err := global.Open("testdata/hello.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "hello, world\n" {
+		t.Errorf("read %q, want %q", data, "hello, world\n")
+	}
+
+	if _, err := global.Open("testdata/missing.txt"); err == nil {
+		t.Errorf("Open(testdata/missing.txt) succeeded, want error")
+	}
+}
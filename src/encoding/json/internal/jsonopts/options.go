@@ -0,0 +1,146 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package jsonopts implements the options shared by the
+// encoding/json/jsontext and encoding/json/v2 packages.
+//
+// All options, whichever package declares them, are represented by the
+// same types so that a single option list can be passed to both the
+// syntactic and the semantic layer.
+package jsonopts
+
+// Options is implemented by every option. It is exported as
+// jsontext.Options and json.Options.
+type Options interface {
+	// JSONOptions is a marker method that prevents types outside
+	// of the encoding/json tree from implementing Options.
+	JSONOptions(NotForPublicUse)
+}
+
+// NotForPublicUse is an argument type that only this tree can name.
+type NotForPublicUse struct{}
+
+// Bools is a set of boolean options.
+type Bools uint64
+
+const (
+	// Options declared by package jsontext.
+	AllowDuplicateNames Bools = 1 << iota
+	AllowInvalidUTF8
+	EscapeForHTML
+
+	// Options declared by package json.
+	MatchCaseInsensitiveNames
+	RejectUnknownMembers
+	FormatNilSliceAsNull
+	FormatNilMapAsNull
+	Deterministic
+
+	// Presence bits for the non-boolean options.
+	indentSet
+	indentPrefixSet
+)
+
+// Bool is an option that sets the boolean option Flag, which must be a
+// single bit, to Value.
+type Bool struct {
+	Flag  Bools
+	Value bool
+}
+
+func (Bool) JSONOptions(NotForPublicUse) {}
+
+// Indent is the option that sets the indentation string.
+type Indent string
+
+func (Indent) JSONOptions(NotForPublicUse) {}
+
+// IndentPrefix is the option that sets the indentation prefix string.
+type IndentPrefix string
+
+func (IndentPrefix) JSONOptions(NotForPublicUse) {}
+
+// Marshalers is the option that holds caller-supplied marshal functions.
+// V is a *json.Marshalers.
+type Marshalers struct{ V interface{} }
+
+func (Marshalers) JSONOptions(NotForPublicUse) {}
+
+// Unmarshalers is the option that holds caller-supplied unmarshal
+// functions. V is a *json.Unmarshalers.
+type Unmarshalers struct{ V interface{} }
+
+func (Unmarshalers) JSONOptions(NotForPublicUse) {}
+
+// Struct is the combination of any number of options.
+// The zero value is the set of default options.
+type Struct struct {
+	set    Bools // options that have been explicitly specified
+	values Bools // values of the specified boolean options
+
+	Indent       string
+	IndentPrefix string
+	Marshalers   interface{}
+	Unmarshalers interface{}
+}
+
+func (*Struct) JSONOptions(NotForPublicUse) {}
+
+// Join merges opts into s. Later options take precedence over
+// earlier ones and over those already in s.
+func (s *Struct) Join(opts ...Options) {
+	for _, opt := range opts {
+		switch opt := opt.(type) {
+		case nil:
+		case Bool:
+			s.set |= opt.Flag
+			if opt.Value {
+				s.values |= opt.Flag
+			} else {
+				s.values &^= opt.Flag
+			}
+		case Indent:
+			s.set |= indentSet
+			s.Indent = string(opt)
+		case IndentPrefix:
+			s.set |= indentPrefixSet
+			s.IndentPrefix = string(opt)
+		case Marshalers:
+			s.Marshalers = opt.V
+		case Unmarshalers:
+			s.Unmarshalers = opt.V
+		case *Struct:
+			if opt == nil {
+				continue
+			}
+			s.values = s.values&^opt.set | opt.values&opt.set
+			s.set |= opt.set
+			if opt.set&indentSet != 0 {
+				s.Indent = opt.Indent
+			}
+			if opt.set&indentPrefixSet != 0 {
+				s.IndentPrefix = opt.IndentPrefix
+			}
+			if opt.Marshalers != nil {
+				s.Marshalers = opt.Marshalers
+			}
+			if opt.Unmarshalers != nil {
+				s.Unmarshalers = opt.Unmarshalers
+			}
+		default:
+			panic("jsonopts: unknown option type")
+		}
+	}
+}
+
+// Get reports whether the boolean option f is set to true.
+func (s *Struct) Get(f Bools) bool {
+	return s.values&f != 0
+}
+
+// Multiline reports whether output should be indented, which is the
+// case once either indentation option has been specified.
+func (s *Struct) Multiline() bool {
+	return s.set&(indentSet|indentPrefixSet) != 0
+}
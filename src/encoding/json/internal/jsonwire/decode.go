@@ -0,0 +1,348 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package jsonwire implements the low-level JSON grammar shared by the
+// encoding/json/jsontext and encoding/json/v2 packages: consuming and
+// validating tokens, quoting and unquoting strings, and formatting numbers.
+package jsonwire
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ErrInvalidUTF8 reports a string that contains invalid UTF-8.
+var ErrInvalidUTF8 = errors.New("invalid UTF-8 within string")
+
+// NewInvalidCharacterError returns an error reporting that the
+// character at the start of prefix is invalid in the context
+// described by where.
+func NewInvalidCharacterError(prefix []byte, where string) error {
+	r, _ := utf8.DecodeRune(prefix)
+	return errors.New("invalid character " + strconv.QuoteRune(r) + " " + where)
+}
+
+// NewInvalidEscapeSequenceError returns an error reporting the
+// invalid escape sequence esc.
+func NewInvalidEscapeSequenceError(esc []byte) error {
+	if len(esc) > 6 {
+		esc = esc[:6]
+	}
+	return errors.New("invalid escape sequence " + strconv.Quote(string(esc)) + " within string")
+}
+
+// IsWhitespace reports whether c is JSON whitespace.
+func IsWhitespace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+// ConsumeWhitespace returns the number of leading whitespace bytes in b.
+func ConsumeWhitespace(b []byte) int {
+	n := 0
+	for n < len(b) && IsWhitespace(b[n]) {
+		n++
+	}
+	return n
+}
+
+// ConsumeLiteral consumes the literal lit (null, false, or true) from
+// the start of b and returns its length.
+//
+// If b is a strict prefix of lit, it reports io.ErrUnexpectedEOF.
+// On any other error, the returned length is the offset of the
+// invalid character.
+func ConsumeLiteral(b []byte, lit string) (int, error) {
+	for i := 0; i < len(lit); i++ {
+		if i == len(b) {
+			return i, io.ErrUnexpectedEOF
+		}
+		if b[i] != lit[i] {
+			return i, NewInvalidCharacterError(b[i:], "within literal "+lit+" (expecting "+strconv.QuoteRune(rune(lit[i]))+")")
+		}
+	}
+	return len(lit), nil
+}
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+func consumeDigits(b []byte, n int) int {
+	for n < len(b) && isDigit(b[n]) {
+		n++
+	}
+	return n
+}
+
+// ConsumeNumber consumes a JSON number from the start of b and returns
+// its length.
+//
+// A number that reaches the end of b may continue in data that has not
+// been read yet, so unless atEOF is set such a number is reported as
+// io.ErrUnexpectedEOF. On any other error, the returned length is the
+// offset of the invalid character.
+func ConsumeNumber(b []byte, atEOF bool) (int, error) {
+	n := 0
+	if n < len(b) && b[n] == '-' {
+		n++
+	}
+	switch {
+	case n == len(b):
+		return n, io.ErrUnexpectedEOF
+	case b[n] == '0':
+		n++
+	case '1' <= b[n] && b[n] <= '9':
+		n = consumeDigits(b, n+1)
+	default:
+		return n, NewInvalidCharacterError(b[n:], "within number (expecting digit)")
+	}
+
+	if n < len(b) && b[n] == '.' {
+		n++
+		if n == len(b) {
+			return n, io.ErrUnexpectedEOF
+		}
+		if !isDigit(b[n]) {
+			return n, NewInvalidCharacterError(b[n:], "within number (expecting digit)")
+		}
+		n = consumeDigits(b, n)
+	}
+
+	if n < len(b) && (b[n] == 'e' || b[n] == 'E') {
+		n++
+		if n < len(b) && (b[n] == '+' || b[n] == '-') {
+			n++
+		}
+		if n == len(b) {
+			return n, io.ErrUnexpectedEOF
+		}
+		if !isDigit(b[n]) {
+			return n, NewInvalidCharacterError(b[n:], "within number (expecting digit)")
+		}
+		n = consumeDigits(b, n)
+	}
+
+	if n == len(b) && !atEOF {
+		return n, io.ErrUnexpectedEOF
+	}
+	return n, nil
+}
+
+func unhex(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
+// parseHex4 parses the four hexadecimal digits at the start of b.
+func parseHex4(b []byte) (r rune, ok bool) {
+	if len(b) < 4 {
+		return 0, false
+	}
+	for _, c := range b[:4] {
+		v, ok := unhex(c)
+		if !ok {
+			return 0, false
+		}
+		r = r*16 + rune(v)
+	}
+	return r, true
+}
+
+// isHexPrefix reports whether b is a strict prefix of
+// four hexadecimal digits.
+func isHexPrefix(b []byte) bool {
+	if len(b) >= 4 {
+		return false
+	}
+	for _, c := range b {
+		if _, ok := unhex(c); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// isUnicodeEscapePrefix reports whether b is a strict prefix
+// of a \uXXXX escape sequence.
+func isUnicodeEscapePrefix(b []byte) bool {
+	switch {
+	case len(b) == 0:
+		return true
+	case b[0] != '\\':
+		return false
+	case len(b) == 1:
+		return true
+	}
+	return b[1] == 'u' && isHexPrefix(b[2:])
+}
+
+// ConsumeString consumes a JSON string, which must start with a
+// double quote, from the start of b and returns its length and whether
+// it contains any escape sequences.
+//
+// If validateUTF8 is set, invalid UTF-8 and invalid UTF-16 surrogate
+// escapes are reported as errors.
+//
+// If the string is not terminated within b, it reports
+// io.ErrUnexpectedEOF. On any other error, the returned length is the
+// offset of the invalid character or escape sequence.
+func ConsumeString(b []byte, validateUTF8 bool) (n int, escaped bool, err error) {
+	return ConsumeStringResumable(b, 0, false, validateUTF8)
+}
+
+// ConsumeStringResumable is like ConsumeString, but resumes consuming
+// at offset resume with the escaped result of the earlier call.
+// When ConsumeString reports io.ErrUnexpectedEOF, the returned length
+// is a valid resume offset once more data has been appended to b.
+func ConsumeStringResumable(b []byte, resume int, escaped, validateUTF8 bool) (n int, escapedOut bool, err error) {
+	n = resume
+	if n == 0 {
+		n = 1 // skip the opening quote
+	}
+	for {
+		// Fast path for runs of plain ASCII characters.
+		for n < len(b) && b[n] >= ' ' && b[n] < utf8.RuneSelf && b[n] != '"' && b[n] != '\\' {
+			n++
+		}
+		if n == len(b) {
+			return n, escaped, io.ErrUnexpectedEOF
+		}
+		switch c := b[n]; {
+		case c == '"':
+			return n + 1, escaped, nil
+		case c == '\\':
+			escaped = true
+			if n+1 == len(b) {
+				return n, escaped, io.ErrUnexpectedEOF
+			}
+			switch b[n+1] {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+				n += 2
+			case 'u':
+				r, ok := parseHex4(b[n+2:])
+				if !ok {
+					if isHexPrefix(b[n+2:]) {
+						return n, escaped, io.ErrUnexpectedEOF
+					}
+					return n, escaped, NewInvalidEscapeSequenceError(b[n:])
+				}
+				start := n
+				n += 6
+				if !validateUTF8 || !utf16.IsSurrogate(r) {
+					break
+				}
+				rest := b[n:]
+				if r < 0xdc00 && isUnicodeEscapePrefix(rest) {
+					return start, escaped, io.ErrUnexpectedEOF
+				}
+				if r >= 0xdc00 || len(rest) < 2 || rest[0] != '\\' || rest[1] != 'u' {
+					return start, escaped, errors.New("invalid surrogate pair in string")
+				}
+				r2, ok := parseHex4(rest[2:])
+				if !ok {
+					return n, escaped, NewInvalidEscapeSequenceError(rest)
+				}
+				if utf16.DecodeRune(r, r2) == utf8.RuneError {
+					return start, escaped, errors.New("invalid surrogate pair in string")
+				}
+				n += 6
+			default:
+				return n, escaped, NewInvalidEscapeSequenceError(b[n : n+2])
+			}
+		case c < ' ':
+			return n, escaped, NewInvalidCharacterError(b[n:], "within string (expecting non-control character)")
+		default:
+			r, size := utf8.DecodeRune(b[n:])
+			if r == utf8.RuneError && size == 1 {
+				if !utf8.FullRune(b[n:]) {
+					return n, escaped, io.ErrUnexpectedEOF
+				}
+				if validateUTF8 {
+					return n, escaped, ErrInvalidUTF8
+				}
+			}
+			n += size
+		}
+	}
+}
+
+// AppendUnquote appends the unescaped contents of the JSON string src,
+// including its surrounding double quotes, to dst.
+// Invalid UTF-8 and unpaired UTF-16 surrogates are replaced with
+// utf8.RuneError.
+func AppendUnquote(dst, src []byte) ([]byte, error) {
+	n, escaped, err := ConsumeString(src, false)
+	if err != nil {
+		return dst, err
+	}
+	if n != len(src) {
+		return dst, NewInvalidCharacterError(src[n:], "after string")
+	}
+	s := src[1 : n-1]
+	if !escaped && utf8.Valid(s) {
+		return append(dst, s...), nil
+	}
+	var rb [utf8.UTFMax]byte
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == '\\':
+			switch s[i+1] {
+			case 'b':
+				dst = append(dst, '\b')
+			case 'f':
+				dst = append(dst, '\f')
+			case 'n':
+				dst = append(dst, '\n')
+			case 'r':
+				dst = append(dst, '\r')
+			case 't':
+				dst = append(dst, '\t')
+			case 'u':
+				r, _ := parseHex4(s[i+2:])
+				i += 6
+				if utf16.IsSurrogate(r) {
+					r2, ok := rune(0), false
+					if i+6 <= len(s) && s[i] == '\\' && s[i+1] == 'u' {
+						r2, ok = parseHex4(s[i+2:])
+					}
+					if dr := utf16.DecodeRune(r, r2); ok && dr != utf8.RuneError {
+						r = dr
+						i += 6
+					} else {
+						r = utf8.RuneError
+					}
+				}
+				dst = append(dst, rb[:utf8.EncodeRune(rb[:], r)]...)
+				continue
+			default: // '"', '\\', or '/'
+				dst = append(dst, s[i+1])
+			}
+			i += 2
+		case c < utf8.RuneSelf:
+			j := i + 1
+			for j < len(s) && s[j] < utf8.RuneSelf && s[j] != '\\' {
+				j++
+			}
+			dst = append(dst, s[i:j]...)
+			i = j
+		default:
+			r, size := utf8.DecodeRune(s[i:])
+			if r == utf8.RuneError && size == 1 {
+				dst = append(dst, "�"...)
+			} else {
+				dst = append(dst, s[i:i+size]...)
+			}
+			i += size
+		}
+	}
+	return dst, nil
+}
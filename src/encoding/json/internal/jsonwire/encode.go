@@ -0,0 +1,103 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonwire
+
+import (
+	"math"
+	"strconv"
+	"unicode/utf8"
+)
+
+const hex = "0123456789abcdef"
+
+// needsEscape reports whether the ASCII character c must be escaped
+// within a JSON string.
+func needsEscape(c byte, escapeHTML bool) bool {
+	return c < ' ' || c == '"' || c == '\\' || escapeHTML && (c == '<' || c == '>' || c == '&')
+}
+
+// AppendQuote appends src to dst as a double-quoted JSON string.
+//
+// Only the characters that JSON requires to be escaped are escaped,
+// plus <, >, and & if escapeHTML is set. Invalid UTF-8 is replaced
+// with utf8.RuneError; unless allowInvalidUTF8 is set, this is also
+// reported as ErrInvalidUTF8 after the whole string has been appended.
+func AppendQuote(dst []byte, src string, escapeHTML, allowInvalidUTF8 bool) ([]byte, error) {
+	var err error
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(src); {
+		if c := src[i]; c < utf8.RuneSelf {
+			if !needsEscape(c, escapeHTML) {
+				i++
+				continue
+			}
+			dst = append(dst, src[start:i]...)
+			switch c {
+			case '"', '\\':
+				dst = append(dst, '\\', c)
+			case '\b':
+				dst = append(dst, '\\', 'b')
+			case '\f':
+				dst = append(dst, '\\', 'f')
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(src[i:])
+		if r == utf8.RuneError && size == 1 {
+			dst = append(dst, src[start:i]...)
+			dst = append(dst, "�"...)
+			if !allowInvalidUTF8 {
+				err = ErrInvalidUTF8
+			}
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	dst = append(dst, src[start:]...)
+	dst = append(dst, '"')
+	return dst, err
+}
+
+// AppendFloat appends f, which must be finite, formatted as a JSON
+// number with the precision of a float of the given bit size.
+func AppendFloat(dst []byte, f float64, bits int) []byte {
+	// Convert as if by ES6 number to string conversion.
+	// This matches most other JSON generators.
+	// Like fmt %g, but the exponent cutoffs are different
+	// and exponents themselves are not padded to two digits.
+	abs := math.Abs(f)
+	fmt := byte('f')
+	// Note: Must use float32 comparisons for underlying float32 value to get precise cutoffs right.
+	if abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			fmt = 'e'
+		}
+	}
+	n := len(dst)
+	dst = strconv.AppendFloat(dst, f, fmt, -1, bits)
+	if fmt == 'e' {
+		// clean up e-09 to e-9
+		b := dst[n:]
+		m := len(b)
+		if m >= 4 && b[m-4] == 'e' && b[m-3] == '-' && b[m-2] == '0' {
+			b[m-2] = b[m-1]
+			dst = dst[:len(dst)-1]
+		}
+	}
+	return dst
+}
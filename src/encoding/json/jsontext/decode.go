@@ -0,0 +1,445 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsontext
+
+import (
+	"encoding/json/internal/jsonopts"
+	"encoding/json/internal/jsonwire"
+	"io"
+)
+
+// A Decoder is a streaming decoder for raw JSON tokens and values.
+// It is used to read a stream of top-level JSON values,
+// each separated by optional whitespace characters.
+//
+// ReadToken and ReadValue calls may be interleaved.
+// For example, the following JSON value:
+//
+//	{"name":"value","array":[null,false,true,3.14159],"object":{"k":"v"}}
+//
+// can be parsed with the following calls (ignoring errors for brevity):
+//
+//	d.ReadToken() // {
+//	d.ReadToken() // "name"
+//	d.ReadToken() // "value"
+//	d.ReadValue() // "array"
+//	d.ReadToken() // [
+//	d.ReadToken() // null
+//	d.ReadToken() // false
+//	d.ReadValue() // true
+//	d.ReadToken() // 3.14159
+//	d.ReadToken() // ]
+//	d.ReadValue() // "object"
+//	d.ReadValue() // {"k":"v"}
+//	d.ReadToken() // }
+//
+// The above is one of many possible sequences of calls and
+// may not represent the most sensible method to call for any given token/value.
+// For example, it is probably more common to call ReadToken to obtain a
+// string token for object names.
+//
+// The Decoder validates its input as it is read: tokens must appear in
+// an order permitted by the JSON grammar, strings must be valid UTF-8,
+// and, unless AllowDuplicateNames is specified, names within an object
+// must be unique. Errors are reported as a *SyntacticError that
+// records the byte offset of the problem.
+type Decoder struct {
+	s    state
+	opts jsonopts.Struct
+
+	rd    io.Reader
+	rdErr error // sticky error from rd, usually io.EOF
+
+	// buf[pos:] is the unread input and buf[0] is at baseOffset
+	// within the stream. The bytes starting at valueStart (or pos,
+	// if valueStart is negative) must be retained on refills.
+	buf        []byte
+	pos        int
+	baseOffset int64
+	valueStart int
+
+	// prevEnd is the stream offset just past the last read token.
+	prevEnd int64
+
+	// peeked reports whether pos is at the start of the next token,
+	// with the preceding whitespace and separator consumed.
+	peeked bool
+
+	// sawSep reports whether the ',' or ':' separator preceding the
+	// next token has been consumed.
+	sawSep bool
+
+	// needDelim reports whether the last token was a literal or
+	// number, which must be followed by whitespace or a delimiter.
+	needDelim bool
+}
+
+// NewDecoder constructs a new streaming decoder reading from r.
+// The decoder introduces its own buffering and may read data from r
+// beyond the JSON values requested.
+func NewDecoder(r io.Reader, opts ...Options) *Decoder {
+	d := new(Decoder)
+	d.Reset(r, opts...)
+	return d
+}
+
+// Reset resets a decoder such that it is reading afresh from r and
+// configured with the provided options.
+func (d *Decoder) Reset(r io.Reader, opts ...Options) {
+	d.opts = jsonopts.Struct{}
+	d.opts.Join(opts...)
+	d.s.reset(d.opts.Get(jsonopts.AllowDuplicateNames))
+	d.rd = r
+	d.rdErr = nil
+	d.buf = d.buf[:0]
+	d.pos = 0
+	d.baseOffset = 0
+	d.valueStart = -1
+	d.prevEnd = 0
+	d.peeked = false
+	d.sawSep = false
+	d.needDelim = false
+}
+
+// resetBytes resets a decoder to read the complete input b, which is
+// never modified.
+func (d *Decoder) resetBytes(b []byte, opts *jsonopts.Struct) {
+	d.Reset(nil, opts)
+	d.buf = b
+	d.rdErr = io.EOF
+}
+
+// Options returns the options used to construct the decoder.
+func (d *Decoder) Options() Options {
+	opts := d.opts
+	return &opts
+}
+
+// fetch reads more data into the buffer, discarding the data that no
+// longer needs to be retained. It reports io.EOF at the end of input.
+func (d *Decoder) fetch() error {
+	if d.rdErr != nil {
+		return d.rdErr
+	}
+
+	keep := d.pos
+	if d.valueStart >= 0 && d.valueStart < keep {
+		keep = d.valueStart
+	}
+	if keep > 0 {
+		n := copy(d.buf, d.buf[keep:])
+		d.buf = d.buf[:n]
+		d.baseOffset += int64(keep)
+		d.pos -= keep
+		if d.valueStart >= 0 {
+			d.valueStart -= keep
+		}
+	}
+	if len(d.buf) == cap(d.buf) {
+		const minBufferSize = 4096
+		n := 2 * cap(d.buf)
+		if n < minBufferSize {
+			n = minBufferSize
+		}
+		buf := make([]byte, len(d.buf), n)
+		copy(buf, d.buf)
+		d.buf = buf
+	}
+
+	for i := 0; i < 100; i++ {
+		n, err := d.rd.Read(d.buf[len(d.buf):cap(d.buf)])
+		d.buf = d.buf[:len(d.buf)+n]
+		if err != nil {
+			d.rdErr = err
+		}
+		if n > 0 {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	d.rdErr = io.ErrNoProgress
+	return d.rdErr
+}
+
+// atEOF reports whether all input has been read into the buffer.
+func (d *Decoder) atEOF() bool {
+	return d.rdErr != nil
+}
+
+// nextNonSpace skips whitespace and returns the next byte,
+// or io.EOF at the end of input.
+func (d *Decoder) nextNonSpace() (byte, error) {
+	for {
+		d.pos += jsonwire.ConsumeWhitespace(d.buf[d.pos:])
+		if d.pos < len(d.buf) {
+			return d.buf[d.pos], nil
+		}
+		if err := d.fetch(); err != nil {
+			return 0, err
+		}
+	}
+}
+
+func (d *Decoder) newError(off int, err error) error {
+	return &SyntacticError{ByteOffset: d.baseOffset + int64(d.pos+off), Err: err}
+}
+
+// eofError converts an io.EOF encountered in the middle of a value.
+func (d *Decoder) eofError(err error) error {
+	if err == io.EOF {
+		return d.newError(0, io.ErrUnexpectedEOF)
+	}
+	return err
+}
+
+// prepareNext consumes whitespace and the separator preceding the next
+// token and returns its first byte. It returns io.EOF if the input
+// ends between top-level values.
+func (d *Decoder) prepareNext() (byte, error) {
+	if d.peeked {
+		return d.buf[d.pos], nil
+	}
+	off := d.baseOffset + int64(d.pos)
+	c, err := d.nextNonSpace()
+	if err != nil {
+		if err == io.EOF && d.s.depth() == 0 {
+			return 0, io.EOF
+		}
+		return 0, d.eofError(err)
+	}
+	if !d.sawSep {
+		if d.needDelim && off == d.baseOffset+int64(d.pos) {
+			switch c {
+			case ',', ':', ']', '}':
+			default:
+				return 0, d.newError(0, jsonwire.NewInvalidCharacterError(d.buf[d.pos:], "after value (expecting whitespace or delimiter)"))
+			}
+		}
+		e := d.s.last()
+		switch {
+		case e.needValue() && c != ':':
+			return 0, d.newError(0, jsonwire.NewInvalidCharacterError(d.buf[d.pos:], "after object name (expecting ':')"))
+		case e.kind == '[' && e.n > 0 && c != ',' && c != ']':
+			return 0, d.newError(0, jsonwire.NewInvalidCharacterError(d.buf[d.pos:], "after array element (expecting ',' or ']')"))
+		case e.needName() && e.n > 0 && c != ',' && c != '}':
+			return 0, d.newError(0, jsonwire.NewInvalidCharacterError(d.buf[d.pos:], "after object value (expecting ',' or '}')"))
+		}
+		if c == ':' && e.needValue() || c == ',' && e.kind != 0 && e.n > 0 && !e.needValue() {
+			d.pos++
+			d.sawSep = true
+			if c, err = d.nextNonSpace(); err != nil {
+				return 0, d.eofError(err)
+			}
+		}
+	}
+	if d.sawSep && (c == ']' || c == '}') {
+		return 0, d.newError(0, jsonwire.NewInvalidCharacterError(d.buf[d.pos:], "at start of value"))
+	}
+	d.peeked = true
+	return c, nil
+}
+
+// PeekKind returns the kind of the token that would be returned by
+// ReadToken. It does not consume the token. It returns 0 if an error
+// occurs, in which case ReadToken reports the error.
+func (d *Decoder) PeekKind() Kind {
+	c, err := d.prepareNext()
+	if err != nil {
+		return 0
+	}
+	return Kind(c).normalize()
+}
+
+// consumeToken consumes the literal, string, or number of kind k at
+// d.pos, reading more input as needed, and returns its length.
+func (d *Decoder) consumeToken(k Kind) (n int, escaped bool, err error) {
+	resume := 0
+	for {
+		b := d.buf[d.pos:]
+		switch k {
+		case 'n':
+			n, err = jsonwire.ConsumeLiteral(b, "null")
+		case 'f':
+			n, err = jsonwire.ConsumeLiteral(b, "false")
+		case 't':
+			n, err = jsonwire.ConsumeLiteral(b, "true")
+		case '"':
+			n, escaped, err = jsonwire.ConsumeStringResumable(b, resume, escaped, !d.opts.Get(jsonopts.AllowInvalidUTF8))
+			resume = n
+		case '0':
+			n, err = jsonwire.ConsumeNumber(b, d.atEOF())
+		}
+		if err != io.ErrUnexpectedEOF || d.atEOF() {
+			if err != nil {
+				err = d.newError(n, err)
+			}
+			return n, escaped, err
+		}
+		if ferr := d.fetch(); ferr != nil && ferr != io.EOF {
+			return n, escaped, ferr
+		}
+	}
+}
+
+// ReadToken reads the next Token, advancing the read offset.
+// The returned token is only valid until the next Peek, Read, or Skip
+// call. It returns io.EOF if there are no more tokens.
+func (d *Decoder) ReadToken() (Token, error) {
+	c, err := d.prepareNext()
+	if err != nil {
+		return Token{}, err
+	}
+	e := d.s.last()
+	k := Kind(c).normalize()
+	switch k {
+	case 'n', 'f', 't', '"', '0':
+		if k != '"' && e.needName() {
+			return Token{}, d.newError(0, ErrNonStringName)
+		}
+		n, escaped, err := d.consumeToken(k)
+		if err != nil {
+			return Token{}, err
+		}
+		raw := d.buf[d.pos : d.pos+n]
+		if k == '"' && e.needName() && !d.s.allowDuplicateNames {
+			if escaped {
+				d.s.nameBuf, _ = jsonwire.AppendUnquote(d.s.nameBuf, raw)
+			} else {
+				d.s.nameBuf = append(d.s.nameBuf, raw[1:n-1]...)
+			}
+			if !d.s.insertName() {
+				return Token{}, d.newError(0, ErrDuplicateName)
+			}
+		}
+		e.n++
+		d.pos += n
+		d.prevEnd = d.baseOffset + int64(d.pos)
+		d.peeked = false
+		d.sawSep = false
+		d.needDelim = k != '"'
+		switch k {
+		case 'n':
+			return Null, nil
+		case 'f':
+			return False, nil
+		case 't':
+			return True, nil
+		}
+		return Token{raw: raw, kind: k}, nil
+	case '{', '[':
+		if e.needName() {
+			return Token{}, d.newError(0, ErrNonStringName)
+		}
+		if err := d.s.push(k); err != nil {
+			return Token{}, d.newError(0, err)
+		}
+	case '}', ']':
+		if err := d.s.pop(k - 2); err != nil { // '}'-2 == '{' and ']'-2 == '['
+			return Token{}, d.newError(0, err)
+		}
+	default:
+		if e.needName() {
+			return Token{}, d.newError(0, jsonwire.NewInvalidCharacterError(d.buf[d.pos:], "at start of string (expecting '\"')"))
+		}
+		return Token{}, d.newError(0, jsonwire.NewInvalidCharacterError(d.buf[d.pos:], "at start of value"))
+	}
+	d.pos++
+	d.prevEnd = d.baseOffset + int64(d.pos)
+	d.peeked = false
+	d.sawSep = false
+	d.needDelim = false
+	return Token{kind: k}, nil
+}
+
+// ReadValue returns the next raw JSON value, advancing the read offset.
+// The value is stripped of any leading or trailing whitespace.
+// The returned value is only valid until the next Peek, Read, or Skip
+// call and may not be mutated while the Decoder remains in use.
+// It returns io.EOF if there are no more values.
+// An error is reported if the next token is the end of an object
+// or array.
+func (d *Decoder) ReadValue() (RawValue, error) {
+	start, err := d.readValue(true)
+	if err != nil {
+		return nil, err
+	}
+	return RawValue(d.buf[start:d.pos]), nil
+}
+
+// SkipValue is semantically equivalent to calling ReadValue and
+// discarding the result except that memory is not wasted trying to
+// hold the entire result.
+func (d *Decoder) SkipValue() error {
+	_, err := d.readValue(false)
+	return err
+}
+
+// readValue consumes the next value and returns the index of its first
+// byte in d.buf. The index is only meaningful if keep is set.
+func (d *Decoder) readValue(keep bool) (int, error) {
+	c, err := d.prepareNext()
+	if err != nil {
+		return 0, err
+	}
+	if c == '}' || c == ']' {
+		return 0, d.newError(0, jsonwire.NewInvalidCharacterError(d.buf[d.pos:], "at start of value"))
+	}
+	if keep {
+		d.valueStart = d.pos
+		defer func() { d.valueStart = -1 }()
+	}
+	depth := d.s.depth()
+	for {
+		if _, err := d.ReadToken(); err != nil {
+			return 0, d.eofError(err)
+		}
+		if d.s.depth() == depth {
+			return d.valueStart, nil
+		}
+	}
+}
+
+// UnreadBuffer returns the data remaining in the unread buffer,
+// which may contain zero or more bytes.
+// The returned buffer must not be mutated while Decoder continues to be used.
+// The buffer contents are valid until the next Peek, Read, or Skip call.
+func (d *Decoder) UnreadBuffer() []byte {
+	return d.buf[d.pos:]
+}
+
+// InputOffset returns the current input byte offset. It gives the
+// location of the next byte immediately after the most recently
+// returned token or value.
+func (d *Decoder) InputOffset() int64 {
+	return d.prevEnd
+}
+
+// StackDepth returns the depth of the state machine for read JSON data.
+// Each level on the stack represents a nested JSON object or array.
+// It is incremented whenever a '{' or '[' token is read and
+// decremented whenever a '}' or ']' token is read.
+// The depth is zero-indexed, where zero represents the top-level JSON
+// value.
+func (d *Decoder) StackDepth() int {
+	return d.s.depth()
+}
+
+// StackIndex returns information about the specified stack level.
+// It must be a number between 0 and StackDepth, inclusive.
+// For each level, it reports the kind:
+//
+//	0 for a level of zero,
+//	'{' for a level representing a JSON object, and
+//	'[' for a level representing a JSON array.
+//
+// It also reports the length of that JSON object or array.
+// Each name and value in a JSON object is counted separately,
+// so the effective number of members would be half the length.
+// A complete JSON object must have an even length.
+func (d *Decoder) StackIndex(i int) (Kind, int64) {
+	return d.s.stackIndex(i)
+}
@@ -0,0 +1,204 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsontext
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// readTokens reads all tokens from d and formats them for comparison.
+func readTokens(d *Decoder) ([]string, error) {
+	var toks []string
+	for {
+		tok, err := d.ReadToken()
+		if err == io.EOF {
+			return toks, nil
+		}
+		if err != nil {
+			return toks, err
+		}
+		s := tok.String()
+		if tok.Kind() == '"' {
+			s = `"` + s + `"`
+		}
+		toks = append(toks, s)
+	}
+}
+
+func TestDecoderTokens(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{``, nil},
+		{` null `, []string{"null"}},
+		{`true false`, []string{"true", "false"}},
+		{`0 -1 1.5e+10 -0.25E-3`, []string{"0", "-1", "1.5e+10", "-0.25E-3"}},
+		{`"" "hello" "A\n😀"`, []string{`""`, `"hello"`, "\"A\n\U0001f600\""}},
+		{`[]`, []string{"[", "]"}},
+		{`{}`, []string{"{", "}"}},
+		{` { "a" : [ 1 , { "b" : null } ] , "c" : "d" } `, []string{"{", `"a"`, "[", "1", "{", `"b"`, "null", "}", "]", `"c"`, `"d"`, "}"}},
+		{`[[[]]][]`, []string{"[", "[", "[", "]", "]", "]", "[", "]"}},
+		{`{"a":1}{"a":2}`, []string{"{", `"a"`, "1", "}", "{", `"a"`, "2", "}"}},
+	}
+	for _, tt := range tests {
+		for _, oneByte := range []bool{false, true} {
+			var r io.Reader = strings.NewReader(tt.in)
+			if oneByte {
+				r = iotest.OneByteReader(r)
+			}
+			got, err := readTokens(NewDecoder(r))
+			if err != nil {
+				t.Errorf("%q (one byte reads: %v): ReadToken error: %v", tt.in, oneByte, err)
+				continue
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%q (one byte reads: %v): tokens = %q, want %q", tt.in, oneByte, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestDecoderErrors(t *testing.T) {
+	tests := []struct {
+		in      string
+		opts    []Options
+		offset  int64
+		wantErr string
+	}{
+		{in: `nul`, offset: 3, wantErr: "unexpected EOF"},
+		{in: `nulx`, offset: 3, wantErr: `invalid character 'x' within literal null (expecting 'l')`},
+		{in: `nullnull`, offset: 4, wantErr: `invalid character 'n' after value`},
+		{in: `01`, offset: 1, wantErr: `invalid character '1' after value`},
+		{in: `-`, offset: 1, wantErr: "unexpected EOF"},
+		{in: `1.`, offset: 2, wantErr: "unexpected EOF"},
+		{in: `1.e5`, offset: 2, wantErr: `invalid character 'e' within number (expecting digit)`},
+		{in: `"abc`, offset: 4, wantErr: "unexpected EOF"},
+		{in: "\"a\x01\"", offset: 2, wantErr: "within string (expecting non-control character)"},
+		{in: `"\x"`, offset: 1, wantErr: `invalid escape sequence "\\x" within string`},
+		{in: "\"\xff\"", offset: 1, wantErr: "invalid UTF-8 within string"},
+		{in: `"\ud800"`, offset: 1, wantErr: "invalid surrogate pair in string"},
+		{in: `[1,]`, offset: 3, wantErr: `invalid character ']' at start of value`},
+		{in: `[1 2]`, offset: 3, wantErr: `invalid character '2' after array element (expecting ',' or ']')`},
+		{in: `{"a" 1}`, offset: 5, wantErr: `invalid character '1' after object name (expecting ':')`},
+		{in: `{"a":1 "b":2}`, offset: 7, wantErr: `invalid character '"' after object value (expecting ',' or '}')`},
+		{in: `{1:2}`, offset: 1, wantErr: ErrNonStringName.Error()},
+		{in: `{"a":}`, offset: 5, wantErr: `invalid character '}' at start of value`},
+		{in: `{"a"}`, offset: 4, wantErr: `invalid character '}' after object name (expecting ':')`},
+		{in: `[}`, offset: 1, wantErr: errMismatchDelim.Error()},
+		{in: `]`, offset: 0, wantErr: errInvalidNesting.Error()},
+		{in: `[1`, offset: 2, wantErr: "unexpected EOF"},
+		{in: `{"a":1,"a":2}`, offset: 7, wantErr: ErrDuplicateName.Error()},
+		{in: `{"a":1,"\u0061":2}`, offset: 7, wantErr: ErrDuplicateName.Error()},
+		{in: `{"a":{"a":1},"b":{"a":1},"a":2}`, offset: 25, wantErr: ErrDuplicateName.Error()},
+		{in: `{"a":1,"a":2}`, opts: []Options{AllowDuplicateNames(true)}},
+		{in: "\"\xff\"", opts: []Options{AllowInvalidUTF8(true)}},
+		{in: strings.Repeat("[", maxNestingDepth+1), offset: maxNestingDepth, wantErr: errMaxDepth.Error()},
+	}
+	for _, tt := range tests {
+		_, err := readTokens(NewDecoder(strings.NewReader(tt.in), tt.opts...))
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%q: unexpected error: %v", tt.in, err)
+			}
+			continue
+		}
+		var serr *SyntacticError
+		if !errors.As(err, &serr) {
+			t.Errorf("%q: error = %v, want *SyntacticError", tt.in, err)
+			continue
+		}
+		if serr.ByteOffset != tt.offset || !strings.Contains(serr.Err.Error(), tt.wantErr) {
+			t.Errorf("%q: error = %v, want offset %d and %q", tt.in, err, tt.offset, tt.wantErr)
+		}
+	}
+}
+
+func TestDecoderReadValue(t *testing.T) {
+	const in = ` {"name":"value","array":[null,false,true,3.14159],"object":{"k":"v"}} `
+	d := NewDecoder(iotest.HalfReader(strings.NewReader(in)))
+	var got []string
+	for _, useValue := range []bool{false, false, false, true, false, false, false, true, false, false, true, true, false} {
+		if useValue {
+			v, err := d.ReadValue()
+			if err != nil {
+				t.Fatalf("ReadValue error: %v", err)
+			}
+			got = append(got, string(v))
+		} else {
+			tok, err := d.ReadToken()
+			if err != nil {
+				t.Fatalf("ReadToken error: %v", err)
+			}
+			got = append(got, tok.String())
+		}
+	}
+	want := []string{"{", "name", "value", `"array"`, "[", "null", "false", "true", "3.14159", "]", `"object"`, `{"k":"v"}`, "}"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := d.InputOffset(), int64(len(in)-1); got != want {
+		t.Errorf("InputOffset = %d, want %d", got, want)
+	}
+	if _, err := d.ReadValue(); err != io.EOF {
+		t.Errorf("ReadValue at end of input = %v, want io.EOF", err)
+	}
+}
+
+func TestDecoderState(t *testing.T) {
+	d := NewDecoder(strings.NewReader(`{"a":[1,2]}`))
+	for i := 0; i < 5; i++ {
+		if _, err := d.ReadToken(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := d.StackDepth(); got != 2 {
+		t.Errorf("StackDepth = %d, want 2", got)
+	}
+	if k, n := d.StackIndex(1); k != '{' || n != 2 {
+		t.Errorf("StackIndex(1) = %v, %d; want {, 2", k, n)
+	}
+	if k, n := d.StackIndex(2); k != '[' || n != 2 {
+		t.Errorf("StackIndex(2) = %v, %d; want [, 2", k, n)
+	}
+	if got := d.PeekKind(); got != ']' {
+		t.Errorf("PeekKind = %v, want ]", got)
+	}
+	if got, want := d.InputOffset(), int64(len(`{"a":[1,2`)); got != want {
+		t.Errorf("InputOffset = %d, want %d", got, want)
+	}
+	if err := d.SkipValue(); err == nil {
+		t.Errorf("SkipValue at end of array succeeded")
+	}
+}
+
+func TestDecoderAllocs(t *testing.T) {
+	in := []byte(`{"name":"value","array":[null,false,true,3.14159,"é"],"object":{"k":"v"}}`)
+	r := bytes.NewReader(in)
+	d := NewDecoder(r)
+	allocs := testing.AllocsPerRun(100, func() {
+		r.Reset(in)
+		d.Reset(r)
+		for {
+			tok, err := d.ReadToken()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			tok.Kind()
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("ReadToken allocated %v times, want 0", allocs)
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package jsontext implements syntactic processing of JSON
+// as specified in RFC 4627, RFC 7159, RFC 7493, RFC 8259, and RFC 8785.
+// JSON is a simple data interchange format that can represent
+// primitive data types such as booleans, strings, and numbers,
+// in addition to structured data types such as objects and arrays.
+//
+// The Encoder and Decoder types are used to encode or decode
+// a stream of JSON tokens or values. Tokens read by a Decoder refer to
+// its internal buffer, so reading a stream token by token does not
+// allocate. Errors in the input or output are reported as a
+// *SyntacticError that records the exact byte offset of the problem.
+//
+// Tokens
+//
+// A JSON token refers to the basic structural elements of JSON:
+//
+//   - a JSON literal (i.e., null, true, or false)
+//   - a JSON string (e.g., "hello, world!")
+//   - a JSON number (e.g., 123.456)
+//   - a start or end delimiter for a JSON object (i.e., '{' or '}')
+//   - a start or end delimiter for a JSON array (i.e., '[' or ']')
+//
+// A JSON token is represented by the Token type in Go. Technically,
+// there are two additional structural characters (i.e., ':' and ','),
+// but there is no Token representation for them since their presence
+// can be inferred by the structure of the JSON grammar itself.
+// For example, there must always be an implicit colon between
+// the name and value of a JSON object member.
+//
+// Values
+//
+// A JSON value refers to a complete unit of JSON data:
+//
+//   - a JSON literal, string, or number
+//   - a JSON object (e.g., `{"name":"value"}`)
+//   - a JSON array (e.g., `[1,2,3]`)
+//
+// A JSON value is represented by the RawValue type in Go and is a []byte
+// containing the raw textual representation of the value. There is some overlap
+// between tokens and values as both contain literals, strings, and numbers.
+// However, only a value can represent the entirety of a JSON object or array.
+//
+// The Encoder and Decoder types contain methods to read or write the next
+// Token or RawValue in a sequence. They maintain a state machine to validate
+// whether the sequence of JSON tokens and/or values produces a valid JSON.
+// Options may be passed to the NewEncoder or NewDecoder constructors
+// to configure the syntactic behavior of encoding and decoding.
+//
+// Package encoding/json/v2 builds on this package to convert between
+// JSON and Go values.
+package jsontext
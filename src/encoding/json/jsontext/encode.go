@@ -0,0 +1,327 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsontext
+
+import (
+	"encoding/json/internal/jsonopts"
+	"encoding/json/internal/jsonwire"
+	"errors"
+	"io"
+	"math"
+)
+
+// An Encoder is a streaming encoder from raw JSON tokens and values.
+// It is used to write a stream of top-level JSON values,
+// each terminated with a newline character.
+//
+// WriteToken and WriteValue calls may be interleaved.
+// For example, the following JSON value:
+//
+//	{"name":"value","array":[null,false,true,3.14159],"object":{"k":"v"}}
+//
+// can be composed with the following calls (ignoring errors for brevity):
+//
+//	e.WriteToken(BeginObject)        // {
+//	e.WriteToken(String("name"))     // "name"
+//	e.WriteToken(String("value"))    // "value"
+//	e.WriteValue(RawValue(`"array"`)) // "array"
+//	e.WriteToken(BeginArray)         // [
+//	e.WriteToken(Null)               // null
+//	e.WriteToken(False)              // false
+//	e.WriteValue(RawValue("true"))   // true
+//	e.WriteToken(Float(3.14159))     // 3.14159
+//	e.WriteToken(EndArray)           // ]
+//	e.WriteValue(RawValue(`"object"`)) // "object"
+//	e.WriteValue(RawValue(`{"k":"v"}`)) // {"k":"v"}
+//	e.WriteToken(EndObject)          // }
+//
+// The Encoder inserts the separators between tokens itself and rejects
+// tokens that would produce invalid JSON. Output is buffered and written
+// to the underlying io.Writer after each top-level value.
+type Encoder struct {
+	s    state
+	opts jsonopts.Struct
+
+	wr    io.Writer
+	wrErr error // sticky error from wr
+
+	buf        []byte
+	baseOffset int64 // number of bytes already written to wr
+
+	valDec Decoder // reused by WriteValue
+}
+
+// NewEncoder constructs a new streaming encoder writing to w
+// configured with the provided options.
+func NewEncoder(w io.Writer, opts ...Options) *Encoder {
+	e := new(Encoder)
+	e.Reset(w, opts...)
+	return e
+}
+
+// Reset resets an encoder such that it is writing afresh to w and
+// configured with the provided options.
+func (e *Encoder) Reset(w io.Writer, opts ...Options) {
+	e.opts = jsonopts.Struct{}
+	e.opts.Join(opts...)
+	e.s.reset(e.opts.Get(jsonopts.AllowDuplicateNames))
+	e.wr = w
+	e.wrErr = nil
+	e.buf = e.buf[:0]
+	e.baseOffset = 0
+}
+
+// Options returns the options used to construct the encoder.
+func (e *Encoder) Options() Options {
+	opts := e.opts
+	return &opts
+}
+
+// flushThreshold is the buffer size past which the Encoder writes
+// out a value that is still incomplete.
+const flushThreshold = 64 << 10
+
+func (e *Encoder) flush() error {
+	if e.wrErr != nil {
+		return e.wrErr
+	}
+	n, err := e.wr.Write(e.buf)
+	e.baseOffset += int64(n)
+	if err == nil && n < len(e.buf) {
+		err = io.ErrShortWrite
+	}
+	e.buf = e.buf[:0]
+	e.wrErr = err
+	return err
+}
+
+func (e *Encoder) newError(err error) error {
+	return &SyntacticError{ByteOffset: e.OutputOffset(), Err: err}
+}
+
+var errInvalidToken = errors.New("invalid token")
+
+// appendIndent appends a newline, the indentation prefix, and n copies
+// of the indentation.
+func (e *Encoder) appendIndent(n int) {
+	e.buf = append(e.buf, '\n')
+	e.buf = append(e.buf, e.opts.IndentPrefix...)
+	for i := 0; i < n; i++ {
+		e.buf = append(e.buf, e.opts.Indent...)
+	}
+}
+
+// appendSeparator appends the separator and indentation that precede
+// a token of kind k.
+func (e *Encoder) appendSeparator(k Kind) {
+	last := e.s.last()
+	multiline := e.opts.Multiline()
+	switch {
+	case last.kind == 0:
+	case k == '}' || k == ']':
+		if multiline && last.n > 0 {
+			e.appendIndent(e.s.depth() - 1)
+		}
+	case last.needValue():
+		e.buf = append(e.buf, ':')
+		if multiline {
+			e.buf = append(e.buf, ' ')
+		}
+	default:
+		if last.n > 0 {
+			e.buf = append(e.buf, ',')
+		}
+		if multiline {
+			e.appendIndent(e.s.depth())
+		}
+	}
+}
+
+// WriteToken writes the next token and advances the internal write offset.
+//
+// The provided token kind must be consistent with the JSON grammar.
+// For example, it is an error to provide a number when the encoder
+// is expecting an object name (which is always a string), or
+// to provide an end object delimiter when the encoder is finishing an array.
+// If the provided token is invalid, then it reports a *SyntacticError
+// and the internal state remains unchanged.
+func (e *Encoder) WriteToken(t Token) error {
+	if e.wrErr != nil {
+		return e.wrErr
+	}
+	k := t.Kind()
+	last := e.s.last()
+	switch k {
+	case 'n', 'f', 't', '0', '{', '[':
+		if last.needName() {
+			return e.newError(ErrNonStringName)
+		}
+	case '}', ']':
+		switch {
+		case last.kind == 0:
+			return e.newError(errInvalidNesting)
+		case last.kind != k-2:
+			return e.newError(errMismatchDelim)
+		case last.needValue():
+			return e.newError(errMissingValue)
+		}
+	case '"':
+	default:
+		return e.newError(errInvalidToken)
+	}
+
+	pos := len(e.buf)
+	e.appendSeparator(k)
+	tokPos := len(e.buf)
+	var err error
+	switch k {
+	case 'n':
+		e.buf = append(e.buf, "null"...)
+	case 'f':
+		e.buf = append(e.buf, "false"...)
+	case 't':
+		e.buf = append(e.buf, "true"...)
+	case '"':
+		err = e.appendString(t)
+		if err == nil && last.needName() && !e.s.allowDuplicateNames {
+			if t.raw != nil {
+				e.s.nameBuf, _ = jsonwire.AppendUnquote(e.s.nameBuf, t.raw)
+			} else {
+				e.s.nameBuf = append(e.s.nameBuf, t.str...)
+			}
+			if !e.s.insertName() {
+				err = ErrDuplicateName
+			}
+		}
+	case '0':
+		switch {
+		case t.raw != nil:
+			e.buf = append(e.buf, t.raw...)
+		case t.numKind == 'f':
+			if f := math.Float64frombits(t.num); math.IsNaN(f) || math.IsInf(f, 0) {
+				err = errors.New("invalid number " + t.String())
+				break
+			}
+			fallthrough
+		default:
+			e.buf = t.appendNumber(e.buf)
+		}
+	case '{', '[':
+		e.buf = append(e.buf, byte(k))
+		err = e.s.push(k)
+	case '}', ']':
+		e.buf = append(e.buf, byte(k))
+		e.s.pop(k - 2)
+	}
+	if err != nil {
+		e.buf = e.buf[:pos]
+		return &SyntacticError{ByteOffset: e.baseOffset + int64(tokPos), Err: err}
+	}
+	if k != '{' && k != '[' && k != '}' && k != ']' {
+		e.s.last().n++
+	}
+
+	if e.s.depth() == 0 {
+		e.buf = append(e.buf, '\n')
+		return e.flush()
+	}
+	if len(e.buf) > flushThreshold {
+		return e.flush()
+	}
+	return nil
+}
+
+// appendString appends the string token t.
+func (e *Encoder) appendString(t Token) error {
+	escapeHTML := e.opts.Get(jsonopts.EscapeForHTML)
+	allowInvalid := e.opts.Get(jsonopts.AllowInvalidUTF8)
+	if t.raw != nil {
+		if !escapeHTML {
+			// The Decoder has already validated the string.
+			e.buf = append(e.buf, t.raw...)
+			return nil
+		}
+		n := len(e.buf)
+		b, err := jsonwire.AppendUnquote(e.buf, t.raw)
+		if err != nil {
+			return err
+		}
+		s := string(b[n:])
+		e.buf, err = jsonwire.AppendQuote(b[:n], s, escapeHTML, allowInvalid)
+		return err
+	}
+	var err error
+	e.buf, err = jsonwire.AppendQuote(e.buf, t.str, escapeHTML, allowInvalid)
+	return err
+}
+
+// WriteValue writes the next raw value and advances the internal write offset.
+// The Encoder does not simply copy the provided value verbatim, but
+// validates and reformats it according to the Encoder's options.
+//
+// The provided value kind must be consistent with the JSON grammar
+// (see examples on Encoder.WriteToken). If the provided value is invalid,
+// then it reports a *SyntacticError and the internal state remains unchanged.
+func (e *Encoder) WriteValue(v RawValue) error {
+	if e.wrErr != nil {
+		return e.wrErr
+	}
+
+	// Validate the entire value before writing any of it.
+	d := &e.valDec
+	d.resetBytes(v, &e.opts)
+	if _, err := d.ReadValue(); err != nil {
+		if err == io.EOF {
+			err = d.newError(0, io.ErrUnexpectedEOF)
+		}
+		return err
+	}
+	if _, err := d.ReadToken(); err != io.EOF {
+		if err == nil {
+			err = d.newError(0, errors.New("unexpected data after top-level value"))
+		}
+		return err
+	}
+
+	d.resetBytes(v, &e.opts)
+	for {
+		t, err := d.ReadToken()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := e.WriteToken(t); err != nil {
+			return err
+		}
+	}
+}
+
+// OutputOffset returns the current output byte offset. It gives the
+// location of the next byte immediately after the most recently
+// written token or value. The number of bytes actually written to the
+// underlying io.Writer may be less than this offset due to internal
+// buffering effects.
+func (e *Encoder) OutputOffset() int64 {
+	return e.baseOffset + int64(len(e.buf))
+}
+
+// StackDepth returns the depth of the state machine for written JSON data.
+// Each level on the stack represents a nested JSON object or array.
+// It is incremented whenever a '{' or '[' token is written and
+// decremented whenever a '}' or ']' token is written.
+// The depth is zero-indexed, where zero represents the top-level JSON
+// value.
+func (e *Encoder) StackDepth() int {
+	return e.s.depth()
+}
+
+// StackIndex returns information about the specified stack level.
+// It must be a number between 0 and StackDepth, inclusive.
+// See Decoder.StackIndex for details.
+func (e *Encoder) StackIndex(i int) (Kind, int64) {
+	return e.s.stackIndex(i)
+}
@@ -0,0 +1,142 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsontext
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestEncoderTokens(t *testing.T) {
+	toks := []Token{
+		BeginObject,
+		String("name"), String("value"),
+		String("array"), BeginArray, Null, False, True, Float(3.14159), Int(-1), Uint(math.MaxUint64), EndArray,
+		String("empty"), BeginObject, EndObject,
+		String("html"), String("<&>"),
+		EndObject,
+		Int(0),
+	}
+	tests := []struct {
+		opts []Options
+		want string
+	}{{
+		want: `{"name":"value","array":[null,false,true,3.14159,-1,18446744073709551615],"empty":{},"html":"<&>"}` + "\n0\n",
+	}, {
+		opts: []Options{EscapeForHTML(true)},
+		want: `{"name":"value","array":[null,false,true,3.14159,-1,18446744073709551615],"empty":{},"html":"\u003c\u0026\u003e"}` + "\n0\n",
+	}, {
+		opts: []Options{WithIndent("\t")},
+		want: "{\n\t\"name\": \"value\",\n\t\"array\": [\n\t\tnull,\n\t\tfalse,\n\t\ttrue,\n\t\t3.14159,\n\t\t-1,\n\t\t18446744073709551615\n\t],\n\t\"empty\": {},\n\t\"html\": \"<&>\"\n}\n0\n",
+	}}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		e := NewEncoder(&buf, tt.opts...)
+		for _, tok := range toks {
+			if err := e.WriteToken(tok); err != nil {
+				t.Fatalf("WriteToken(%v) error: %v", tok, err)
+			}
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("output:\n%s\nwant:\n%s", got, tt.want)
+		}
+		if got := e.OutputOffset(); got != int64(len(tt.want)) {
+			t.Errorf("OutputOffset = %d, want %d", got, len(tt.want))
+		}
+	}
+}
+
+func TestEncoderErrors(t *testing.T) {
+	tests := []struct {
+		toks    []Token
+		wantErr error
+	}{
+		{[]Token{EndObject}, errInvalidNesting},
+		{[]Token{BeginArray, EndObject}, errMismatchDelim},
+		{[]Token{BeginObject, Null}, ErrNonStringName},
+		{[]Token{BeginObject, BeginArray}, ErrNonStringName},
+		{[]Token{BeginObject, String("a"), EndObject}, errMissingValue},
+		{[]Token{BeginObject, String("a"), Null, String("a")}, ErrDuplicateName},
+		{[]Token{Float(math.NaN())}, nil},
+		{[]Token{String("\xff")}, nil},
+		{[]Token{{}}, errInvalidToken},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		e := NewEncoder(&buf)
+		var err error
+		for _, tok := range tt.toks {
+			if err = e.WriteToken(tok); err != nil {
+				break
+			}
+		}
+		var serr *SyntacticError
+		if !errors.As(err, &serr) {
+			t.Errorf("%v: error = %v, want *SyntacticError", tt.toks, err)
+			continue
+		}
+		if tt.wantErr != nil && serr.Err != tt.wantErr {
+			t.Errorf("%v: error = %v, want %v", tt.toks, serr.Err, tt.wantErr)
+		}
+	}
+
+	// A failed write leaves the state unchanged.
+	var buf bytes.Buffer
+	e := NewEncoder(&buf)
+	e.WriteToken(BeginObject)
+	e.WriteToken(String("a"))
+	e.WriteToken(Null)
+	if err := e.WriteToken(String("a")); err == nil {
+		t.Fatalf("writing duplicate name succeeded")
+	}
+	if err := e.WriteToken(String("b")); err != nil {
+		t.Fatalf("WriteToken error: %v", err)
+	}
+	e.WriteToken(True)
+	e.WriteToken(EndObject)
+	if got, want := buf.String(), `{"a":null,"b":true}`+"\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestEncoderWriteValue(t *testing.T) {
+	var buf bytes.Buffer
+	e := NewEncoder(&buf, WithIndent("  "))
+	e.WriteToken(BeginObject)
+	e.WriteValue(RawValue(` "a" `))
+	if err := e.WriteValue(RawValue(` { "b" : [ 1 , 2 ] } `)); err != nil {
+		t.Fatalf("WriteValue error: %v", err)
+	}
+	for _, v := range []string{`{"c":1`, `1 2`, `[1,]`, `"\xff"`} {
+		if err := e.WriteValue(RawValue(v)); err == nil {
+			t.Errorf("WriteValue(%q) succeeded", v)
+		}
+	}
+	e.WriteToken(EndObject)
+	want := "{\n  \"a\": {\n    \"b\": [\n      1,\n      2\n    ]\n  }\n}\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestEncoderLargeOutput(t *testing.T) {
+	var buf bytes.Buffer
+	e := NewEncoder(&buf)
+	e.WriteToken(BeginArray)
+	s := strings.Repeat("x", 1000)
+	for i := 0; i < 100; i++ {
+		e.WriteToken(String(s))
+	}
+	if buf.Len() == 0 {
+		t.Errorf("large incomplete value was not flushed")
+	}
+	e.WriteToken(EndArray)
+	if !RawValue(buf.Bytes()).IsValid() {
+		t.Errorf("output is not valid JSON")
+	}
+}
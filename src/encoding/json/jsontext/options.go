@@ -0,0 +1,81 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsontext
+
+import (
+	"encoding/json/internal/jsonopts"
+	"strings"
+)
+
+// Options configures NewEncoder, Encoder.Reset, NewDecoder,
+// and Decoder.Reset with specific features.
+// Each function takes in a variadic list of options, where properties
+// set in latter options override the value of previously set properties.
+//
+// The Options type is identical to encoding/json/v2.Options.
+// Options from the other package may be passed to functionality in this
+// package, but are ignored. Options from this package may be used with
+// the other package.
+//
+// Options that do not affect a particular operation are ignored.
+type Options = jsonopts.Options
+
+// AllowDuplicateNames specifies that JSON objects may contain
+// duplicate member names. Disabling the duplicate name check may provide
+// performance benefits, but breaks compliance with RFC 7493, section 2.3.
+// The input or output will still be compliant with RFC 8259,
+// which leaves the handling of duplicate names as unspecified behavior.
+//
+// This affects either encoding or decoding.
+func AllowDuplicateNames(v bool) Options {
+	return jsonopts.Bool{Flag: jsonopts.AllowDuplicateNames, Value: v}
+}
+
+// AllowInvalidUTF8 specifies that JSON strings may contain invalid UTF-8,
+// which will be mangled as the Unicode replacement character, U+FFFD.
+// This causes the encoder or decoder to break compliance with
+// RFC 7493, section 2.1, and RFC 8259, section 8.1.
+//
+// This affects either encoding or decoding.
+func AllowInvalidUTF8(v bool) Options {
+	return jsonopts.Bool{Flag: jsonopts.AllowInvalidUTF8, Value: v}
+}
+
+// EscapeForHTML specifies that '<', '>', and '&' characters within JSON strings
+// should be escaped as a hexadecimal Unicode codepoint (e.g., \u003c) so that
+// the output is safe to embed within HTML.
+//
+// This only affects encoding and is ignored when decoding.
+func EscapeForHTML(v bool) Options {
+	return jsonopts.Bool{Flag: jsonopts.EscapeForHTML, Value: v}
+}
+
+// WithIndent specifies that the encoder should emit multiline output
+// where each element in a JSON object or array begins on a new, indented line
+// beginning with the indent prefix (see WithIndentPrefix)
+// followed by one or more copies of indent according to the nesting depth.
+// The indent must only be composed of space or tab characters.
+//
+// This only affects encoding and is ignored when decoding.
+func WithIndent(indent string) Options {
+	if strings.Trim(indent, " \t") != "" {
+		panic("jsontext: invalid character in indent")
+	}
+	return jsonopts.Indent(indent)
+}
+
+// WithIndentPrefix specifies that the encoder should emit multiline output
+// where each element in a JSON object or array begins on a new, indented line
+// beginning with the indent prefix followed by one or more copies of indent
+// (see WithIndent) according to the nesting depth.
+// The prefix must only be composed of space or tab characters.
+//
+// This only affects encoding and is ignored when decoding.
+func WithIndentPrefix(prefix string) Options {
+	if strings.Trim(prefix, " \t") != "" {
+		panic("jsontext: invalid character in indent prefix")
+	}
+	return jsonopts.IndentPrefix(prefix)
+}
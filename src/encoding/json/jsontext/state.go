@@ -0,0 +1,180 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsontext
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+)
+
+// maxNestingDepth is the maximum depth of nested objects and arrays.
+const maxNestingDepth = 10000
+
+var (
+	// ErrDuplicateName indicates that a JSON token could not be
+	// encoded or decoded because it results in a duplicate JSON object
+	// name. This error is wrapped within a SyntacticError.
+	ErrDuplicateName = errors.New("duplicate object member name")
+
+	// ErrNonStringName indicates that a JSON token could not be
+	// encoded or decoded because it is not a string, as required
+	// for JSON object names. This error is wrapped within a
+	// SyntacticError.
+	ErrNonStringName = errors.New("object member name must be a string")
+
+	errMaxDepth       = errors.New("exceeded max depth")
+	errMismatchDelim  = errors.New("mismatching structural token for object or array")
+	errMissingValue   = errors.New("missing value after object name")
+	errInvalidNesting = errors.New("end of object or array outside of any object or array")
+)
+
+// SyntacticError is a description of a syntactic error that occurred
+// when encoding or decoding JSON according to the grammar.
+type SyntacticError struct {
+	// ByteOffset indicates that an error occurred after this byte offset.
+	ByteOffset int64
+
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *SyntacticError) Error() string {
+	return "jsontext: syntactic error at byte offset " + strconv.FormatInt(e.ByteOffset, 10) + ": " + e.Err.Error()
+}
+
+func (e *SyntacticError) Unwrap() error {
+	return e.Err
+}
+
+// stateEntry is the state of a single JSON object or array, or of the
+// top-level sequence of values.
+type stateEntry struct {
+	kind Kind  // '{', '[', or 0 for the top level
+	n    int64 // number of tokens within, counting names and values
+
+	// The names of an object are tracked for duplicate detection in
+	// state.nameBuf and state.nameEnds, starting at these indexes.
+	nameIdx int
+	nameOff int
+	names   map[string]struct{} // built lazily for objects with many names
+}
+
+// needName reports whether the next token in an object must be a name.
+func (e *stateEntry) needName() bool {
+	return e.kind == '{' && e.n%2 == 0
+}
+
+// needValue reports whether the next token must complete an object member.
+func (e *stateEntry) needValue() bool {
+	return e.kind == '{' && e.n%2 == 1
+}
+
+// state tracks the position within the JSON grammar and is shared by
+// the Encoder and Decoder.
+type state struct {
+	stack []stateEntry
+
+	// allowDuplicateNames disables duplicate name detection.
+	allowDuplicateNames bool
+
+	// nameBuf holds the unescaped names of all open objects,
+	// delimited by nameEnds.
+	nameBuf  []byte
+	nameEnds []int
+}
+
+func (s *state) reset(allowDuplicateNames bool) {
+	s.stack = append(s.stack[:0], stateEntry{})
+	s.allowDuplicateNames = allowDuplicateNames
+	s.nameBuf = s.nameBuf[:0]
+	s.nameEnds = s.nameEnds[:0]
+}
+
+func (s *state) last() *stateEntry {
+	return &s.stack[len(s.stack)-1]
+}
+
+// depth reports the current nesting depth.
+func (s *state) depth() int {
+	return len(s.stack) - 1
+}
+
+// push enters a new object or array.
+func (s *state) push(k Kind) error {
+	if s.depth() >= maxNestingDepth {
+		return errMaxDepth
+	}
+	s.last().n++
+	s.stack = append(s.stack, stateEntry{
+		kind:    k,
+		nameIdx: len(s.nameEnds),
+		nameOff: len(s.nameBuf),
+	})
+	return nil
+}
+
+// pop leaves the current object or array, which must be of kind k.
+func (s *state) pop(k Kind) error {
+	e := s.last()
+	switch {
+	case e.kind == 0:
+		return errInvalidNesting
+	case e.kind != k:
+		return errMismatchDelim
+	case e.needValue():
+		return errMissingValue
+	}
+	s.nameBuf = s.nameBuf[:e.nameOff]
+	s.nameEnds = s.nameEnds[:e.nameIdx]
+	s.stack = s.stack[:len(s.stack)-1]
+	return nil
+}
+
+// insertName records the unescaped name that the caller has just
+// appended to nameBuf in the current object. It reports false if the name is a duplicate,
+// in which case the name is discarded.
+func (s *state) insertName() bool {
+	e := s.last()
+	start := e.nameOff
+	if n := len(s.nameEnds); n > e.nameIdx {
+		start = s.nameEnds[n-1]
+	}
+	name := s.nameBuf[start:]
+	const mapThreshold = 32
+	count := len(s.nameEnds) - e.nameIdx
+	if count < mapThreshold {
+		off := e.nameOff
+		for _, end := range s.nameEnds[e.nameIdx:] {
+			if bytes.Equal(s.nameBuf[off:end], name) {
+				s.nameBuf = s.nameBuf[:start]
+				return false
+			}
+			off = end
+		}
+	} else {
+		if e.names == nil {
+			e.names = make(map[string]struct{})
+			off := e.nameOff
+			for _, end := range s.nameEnds[e.nameIdx:] {
+				e.names[string(s.nameBuf[off:end])] = struct{}{}
+				off = end
+			}
+		}
+		if _, ok := e.names[string(name)]; ok {
+			s.nameBuf = s.nameBuf[:start]
+			return false
+		}
+		e.names[string(name)] = struct{}{}
+	}
+	s.nameEnds = append(s.nameEnds, len(s.nameBuf))
+	return true
+}
+
+// stackIndex implements StackIndex for the Encoder and Decoder.
+func (s *state) stackIndex(i int) (Kind, int64) {
+	e := s.stack[i]
+	return e.kind, e.n
+}
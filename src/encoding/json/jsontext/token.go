@@ -0,0 +1,287 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsontext
+
+import (
+	"encoding/json/internal/jsonwire"
+	"math"
+	"strconv"
+)
+
+// Kind represents each possible JSON token kind with a single byte,
+// which is the first byte of that kind of token:
+//
+//	'n': null
+//	'f': false
+//	't': true
+//	'"': string
+//	'0': number
+//	'{': begin object
+//	'}': end object
+//	'[': begin array
+//	']': end array
+//
+// An invalid kind is usually represented using 0,
+// but may be non-zero due to invalid JSON data.
+type Kind byte
+
+// String prints the kind in a humanly readable fashion.
+func (k Kind) String() string {
+	switch k {
+	case 'n':
+		return "null"
+	case 'f':
+		return "false"
+	case 't':
+		return "true"
+	case '"':
+		return "string"
+	case '0':
+		return "number"
+	case '{':
+		return "{"
+	case '}':
+		return "}"
+	case '[':
+		return "["
+	case ']':
+		return "]"
+	default:
+		return "<invalid json.Kind: " + strconv.Quote(string(rune(k))) + ">"
+	}
+}
+
+// normalize coalesces all possible starting characters of a number
+// as just '0'.
+func (k Kind) normalize() Kind {
+	if k == '-' || ('0' <= k && k <= '9') {
+		return '0'
+	}
+	return k
+}
+
+// A Token represents a lexical JSON token, which may be one of:
+// a null, a boolean, a string, a number, or the start or end of an
+// object or array. It does not represent a complete value: a Token
+// for the start of an object is followed by the object's members and
+// a Token for its end.
+//
+// A Token read by a Decoder refers to the Decoder's internal buffer
+// and is only valid until the next call to a Decoder method.
+// Use Clone to retain it for longer. Reading a Token does not allocate.
+//
+// The zero Token is invalid.
+type Token struct {
+	// raw is the encoded token, if it was read by a Decoder.
+	raw []byte
+
+	kind Kind
+
+	// Tokens that were not read by a Decoder hold their value in
+	// str or num, depending on kind.
+	str string
+	num uint64 // float64 bits, int64, or uint64 depending on numKind
+
+	numKind byte // 'f', 'i', or 'u'
+}
+
+var (
+	Null  = Token{kind: 'n'}
+	False = Token{kind: 'f'}
+	True  = Token{kind: 't'}
+
+	BeginObject = Token{kind: '{'}
+	EndObject   = Token{kind: '}'}
+	BeginArray  = Token{kind: '['}
+	EndArray    = Token{kind: ']'}
+)
+
+// Bool constructs a Token representing a JSON boolean.
+func Bool(b bool) Token {
+	if b {
+		return True
+	}
+	return False
+}
+
+// String constructs a Token representing a JSON string.
+// The provided string should contain valid UTF-8, otherwise invalid
+// characters may be mangled as the Unicode replacement character.
+func String(s string) Token {
+	return Token{kind: '"', str: s}
+}
+
+// Float constructs a Token representing a JSON number.
+// The values NaN, +Inf, and -Inf cannot be encoded; writing them
+// reports an error.
+func Float(n float64) Token {
+	return Token{kind: '0', numKind: 'f', num: math.Float64bits(n)}
+}
+
+// Int constructs a Token representing a JSON number from an int64.
+func Int(n int64) Token {
+	return Token{kind: '0', numKind: 'i', num: uint64(n)}
+}
+
+// Uint constructs a Token representing a JSON number from a uint64.
+func Uint(n uint64) Token {
+	return Token{kind: '0', numKind: 'u', num: n}
+}
+
+// Kind returns the token kind.
+func (t Token) Kind() Kind {
+	return t.kind
+}
+
+// Clone makes a copy of the Token such that its value remains valid
+// even after a subsequent Decoder read call.
+func (t Token) Clone() Token {
+	if t.raw != nil {
+		t.raw = append([]byte(nil), t.raw...)
+	}
+	return t
+}
+
+// Bool returns the value for a JSON boolean.
+// It panics if the token kind is not a JSON boolean.
+func (t Token) Bool() bool {
+	switch t.kind {
+	case 't':
+		return true
+	case 'f':
+		return false
+	}
+	panic("invalid JSON token kind: " + t.kind.String())
+}
+
+// String returns the unescaped string value for a JSON string.
+// For other JSON kinds, this returns the raw JSON representation.
+func (t Token) String() string {
+	switch {
+	case t.kind == '"' && t.raw != nil:
+		b, _ := jsonwire.AppendUnquote(nil, t.raw)
+		return string(b)
+	case t.kind == '"':
+		return t.str
+	case t.raw != nil:
+		return string(t.raw)
+	case t.kind == '0':
+		return string(t.appendNumber(nil))
+	case t.kind == 0:
+		return "<invalid json.Token>"
+	}
+	return t.kind.String()
+}
+
+// appendNumber appends the JSON representation of a number token that
+// was not read by a Decoder. Non-finite floats are not valid JSON and
+// are formatted as by strconv.
+func (t Token) appendNumber(b []byte) []byte {
+	switch t.numKind {
+	case 'i':
+		return strconv.AppendInt(b, int64(t.num), 10)
+	case 'u':
+		return strconv.AppendUint(b, t.num, 10)
+	}
+	f := math.Float64frombits(t.num)
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return strconv.AppendFloat(b, f, 'g', -1, 64)
+	}
+	return jsonwire.AppendFloat(b, f, 64)
+}
+
+// Float returns the floating-point value for a JSON number.
+// It returns a NaN, +Inf, or -Inf value for any JSON string
+// with the values "NaN", "Infinity", or "-Infinity".
+// It panics for all other cases.
+func (t Token) Float() float64 {
+	switch {
+	case t.kind == '0' && t.raw != nil:
+		f, _ := strconv.ParseFloat(string(t.raw), 64)
+		return f
+	case t.kind == '0':
+		switch t.numKind {
+		case 'i':
+			return float64(int64(t.num))
+		case 'u':
+			return float64(t.num)
+		}
+		return math.Float64frombits(t.num)
+	case t.kind == '"':
+		switch t.String() {
+		case "NaN":
+			return math.NaN()
+		case "Infinity":
+			return math.Inf(+1)
+		case "-Infinity":
+			return math.Inf(-1)
+		}
+	}
+	panic("invalid JSON token kind: " + t.kind.String())
+}
+
+// Int returns the signed integer value for a JSON number.
+// The fractional component of any number is ignored (truncation toward zero).
+// Any number beyond the representation of an int64 will be saturated
+// to the closest representable value.
+// It panics if the token kind is not a JSON number.
+func (t Token) Int() int64 {
+	if t.kind != '0' {
+		panic("invalid JSON token kind: " + t.kind.String())
+	}
+	if t.raw == nil {
+		switch t.numKind {
+		case 'i':
+			return int64(t.num)
+		case 'u':
+			if t.num > math.MaxInt64 {
+				return math.MaxInt64
+			}
+			return int64(t.num)
+		}
+	} else if n, err := strconv.ParseInt(string(t.raw), 10, 64); err == nil {
+		return n
+	}
+	f := t.Float()
+	switch {
+	case f >= math.MaxInt64:
+		return math.MaxInt64
+	case f <= math.MinInt64:
+		return math.MinInt64
+	}
+	return int64(f)
+}
+
+// Uint returns the unsigned integer value for a JSON number.
+// The fractional component of any number is ignored (truncation toward zero).
+// Any number beyond the representation of an uint64 will be saturated
+// to the closest representable value.
+// It panics if the token kind is not a JSON number.
+func (t Token) Uint() uint64 {
+	if t.kind != '0' {
+		panic("invalid JSON token kind: " + t.kind.String())
+	}
+	if t.raw == nil {
+		switch t.numKind {
+		case 'u':
+			return t.num
+		case 'i':
+			if int64(t.num) < 0 {
+				return 0
+			}
+			return t.num
+		}
+	} else if n, err := strconv.ParseUint(string(t.raw), 10, 64); err == nil {
+		return n
+	}
+	f := t.Float()
+	switch {
+	case f >= math.MaxUint64:
+		return math.MaxUint64
+	case f <= 0:
+		return 0
+	}
+	return uint64(f)
+}
@@ -0,0 +1,154 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsontext
+
+import (
+	"bytes"
+	"encoding/json/internal/jsonopts"
+	"encoding/json/internal/jsonwire"
+	"errors"
+	"io"
+)
+
+// RawValue represents a single raw JSON value, which may be one of the
+// following:
+//   - a JSON literal (i.e., null, true, or false)
+//   - a JSON string (e.g., "hello, world!")
+//   - a JSON number (e.g., 123.456)
+//   - an entire JSON object (e.g., {"fizz":"buzz"})
+//   - an entire JSON array (e.g., [1,2,3])
+//
+// RawValue can represent entire array or object values, while Token cannot.
+// RawValue may contain leading and/or trailing whitespace.
+type RawValue []byte
+
+// Clone returns a copy of v.
+func (v RawValue) Clone() RawValue {
+	if v == nil {
+		return nil
+	}
+	return append(RawValue{}, v...)
+}
+
+// String returns the string formatting of v.
+func (v RawValue) String() string {
+	if v == nil {
+		return "null"
+	}
+	return string(v)
+}
+
+// IsValid reports whether the raw JSON value is syntactically valid
+// according to RFC 7493.
+//
+// It verifies whether the input is properly encoded as UTF-8,
+// that escape sequences within strings decode to valid Unicode codepoints, and
+// that all names in each object are unique.
+// It does not verify whether numbers are representable within the limits
+// of any common numeric type (e.g., float64, int64, or uint64).
+func (v RawValue) IsValid() bool {
+	var d Decoder
+	d.resetBytes(v, &jsonopts.Struct{})
+	if _, err := d.ReadValue(); err != nil {
+		return false
+	}
+	_, err := d.ReadToken()
+	return err == io.EOF
+}
+
+// Compact removes all whitespace from the raw JSON value.
+//
+// It does not reformat JSON strings to use any other representation.
+// It is guaranteed to succeed if the input is valid.
+// If the value is already compacted, then the buffer is not mutated.
+func (v *RawValue) Compact() error {
+	return v.reformat(AllowDuplicateNames(true), AllowInvalidUTF8(true))
+}
+
+// Indent reformats the whitespace in the raw JSON value so that each element
+// in a JSON object or array begins on a new, indented line beginning with
+// prefix followed by one or more copies of indent according to the nesting.
+// The value does not begin with the prefix nor any indention,
+// to make it easier to embed inside other formatted JSON data.
+//
+// It does not reformat JSON strings to use any other representation.
+// It is guaranteed to succeed if the input is valid.
+// If the value is already indented properly, then the buffer is not mutated.
+// It panics if prefix or indent contain characters other than spaces and tabs.
+func (v *RawValue) Indent(prefix, indent string) error {
+	return v.reformat(AllowDuplicateNames(true), AllowInvalidUTF8(true), WithIndentPrefix(prefix), WithIndent(indent))
+}
+
+func (v *RawValue) reformat(opts ...Options) error {
+	var buf bytes.Buffer
+	e := NewEncoder(&buf, opts...)
+	if err := e.WriteValue(*v); err != nil {
+		return err
+	}
+	b := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+	if !bytes.Equal(b, *v) {
+		*v = append((*v)[:0], b...)
+	}
+	return nil
+}
+
+// Kind returns the starting token kind.
+// For a valid value, this will never include '}' or ']'.
+func (v RawValue) Kind() Kind {
+	if v := v[jsonwire.ConsumeWhitespace(v):]; len(v) > 0 {
+		return Kind(v[0]).normalize()
+	}
+	return 0
+}
+
+// MarshalJSON returns v as the JSON encoding of v.
+// It returns the stored value as the raw JSON output without any validation.
+// If v is nil, then this returns a JSON null.
+func (v RawValue) MarshalJSON() ([]byte, error) {
+	if v == nil {
+		return []byte("null"), nil
+	}
+	return v, nil
+}
+
+// UnmarshalJSON sets v as the JSON encoding of b.
+// It stores a copy of the provided raw JSON input without any validation.
+func (v *RawValue) UnmarshalJSON(b []byte) error {
+	if v == nil {
+		return errors.New("jsontext.RawValue: UnmarshalJSON on nil pointer")
+	}
+	*v = append((*v)[:0], b...)
+	return nil
+}
+
+// AppendQuote appends a double-quoted JSON string literal representing src
+// to dst and returns the extended buffer.
+// It uses the minimal string representation per RFC 8785, section 3.2.2.2.
+// Invalid UTF-8 bytes are replaced with the Unicode replacement character
+// and an error is returned at the end indicating the presence of invalid UTF-8.
+func AppendQuote(dst []byte, src string) ([]byte, error) {
+	return jsonwire.AppendQuote(dst, src, false, false)
+}
+
+// AppendUnquote appends the decoded interpretation of src as a
+// double-quoted JSON string literal to dst and returns the extended buffer.
+// The input src must be a JSON string without any surrounding whitespace.
+// Invalid UTF-8 bytes are replaced with the Unicode replacement character
+// and an error is returned at the end indicating the presence of invalid UTF-8.
+// Any trailing bytes after the JSON string literal results in an error.
+func AppendUnquote(dst, src []byte) ([]byte, error) {
+	n, _, err := jsonwire.ConsumeString(src, true)
+	if err == jsonwire.ErrInvalidUTF8 {
+		dst, _ = jsonwire.AppendUnquote(dst, src)
+		return dst, err
+	}
+	if err != nil {
+		return dst, err
+	}
+	if n != len(src) {
+		return dst, jsonwire.NewInvalidCharacterError(src[n:], "after string")
+	}
+	return jsonwire.AppendUnquote(dst, src)
+}
@@ -0,0 +1,94 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsontext
+
+import "testing"
+
+func TestRawValue(t *testing.T) {
+	tests := []struct {
+		in      string
+		valid   bool
+		compact string
+		indent  string
+	}{
+		{in: ``},
+		{in: `1 2`},
+		{in: ` null `, valid: true, compact: `null`, indent: `null`},
+		{in: ` [ ] `, valid: true, compact: `[]`, indent: `[]`},
+		{
+			in:      " { \"a\" : [ 1 , \"\\u00e9\" ] , \"b\" : { } } ",
+			valid:   true,
+			compact: `{"a":[1,"\u00e9"],"b":{}}`,
+			indent:  "{\n \t\"a\": [\n \t\t1,\n \t\t\"\\u00e9\"\n \t],\n \t\"b\": {}\n }",
+		},
+	}
+	for _, tt := range tests {
+		v := RawValue(tt.in)
+		if got := v.IsValid(); got != tt.valid {
+			t.Errorf("RawValue(%q).IsValid() = %v, want %v", tt.in, got, tt.valid)
+		}
+		v1 := v.Clone()
+		err := v1.Compact()
+		if (err == nil) != tt.valid {
+			t.Errorf("RawValue(%q).Compact() error = %v", tt.in, err)
+		}
+		if err == nil && string(v1) != tt.compact {
+			t.Errorf("RawValue(%q).Compact() = %q, want %q", tt.in, v1, tt.compact)
+		}
+		v2 := v.Clone()
+		err = v2.Indent(" ", "\t")
+		if (err == nil) != tt.valid {
+			t.Errorf("RawValue(%q).Indent() error = %v", tt.in, err)
+		}
+		if err == nil && string(v2) != tt.indent {
+			t.Errorf("RawValue(%q).Indent() = %q, want %q", tt.in, v2, tt.indent)
+		}
+	}
+}
+
+func TestRawValueDuplicateNames(t *testing.T) {
+	v := RawValue(`{"a":1, "a":2}`)
+	if v.IsValid() {
+		t.Errorf("RawValue(%q).IsValid() = true, want false", v)
+	}
+	if err := v.Compact(); err != nil || string(v) != `{"a":1,"a":2}` {
+		t.Errorf("Compact() = %q, %v", v, err)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		in, quoted string
+	}{
+		{"", `""`},
+		{"hello", `"hello"`},
+		{"\"\\\n\t\x00\x1f", `"\"\\\n\t\u0000\u001f"`},
+		{"<&>\u2028é😀", "\"<&>\u2028é😀\""},
+		{"\xff", "\"\ufffd\""},
+	}
+	for _, tt := range tests {
+		got, err := AppendQuote(nil, tt.in)
+		if string(got) != tt.quoted {
+			t.Errorf("AppendQuote(%q) = %s, want %s", tt.in, got, tt.quoted)
+		}
+		if (err != nil) != (tt.in == "\xff") {
+			t.Errorf("AppendQuote(%q) error = %v", tt.in, err)
+		}
+		if tt.in == "\xff" {
+			continue
+		}
+		unquoted, err := AppendUnquote(nil, got)
+		if err != nil || string(unquoted) != tt.in {
+			t.Errorf("AppendUnquote(%s) = %q, %v; want %q", got, unquoted, err, tt.in)
+		}
+	}
+	got, err := AppendUnquote(nil, []byte(`"\ud83d\ude00\u00e9\/"`))
+	if err != nil || string(got) != "😀é/" {
+		t.Errorf("AppendUnquote = %q, %v", got, err)
+	}
+	if _, err := AppendUnquote(nil, []byte(`"abc" `)); err == nil {
+		t.Errorf("AppendUnquote with trailing data succeeded")
+	}
+}
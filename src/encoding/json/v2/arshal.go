@@ -0,0 +1,473 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package json
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json/internal/jsonopts"
+	"encoding/json/jsontext"
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// MarshalerTo is implemented by types that can marshal themselves
+// directly into a jsontext.Encoder. It is preferred over Marshaler.
+//
+// The implementation must write exactly one JSON value to the Encoder.
+type MarshalerTo interface {
+	MarshalJSONTo(*jsontext.Encoder) error
+}
+
+// Marshaler is implemented by types that can marshal themselves into
+// valid JSON. It is the same interface as the one declared by
+// package encoding/json.
+type Marshaler interface {
+	MarshalJSON() ([]byte, error)
+}
+
+// UnmarshalerFrom is implemented by types that can unmarshal themselves
+// directly from a jsontext.Decoder. It is preferred over Unmarshaler.
+//
+// The implementation must read exactly one JSON value from the Decoder.
+type UnmarshalerFrom interface {
+	UnmarshalJSONFrom(*jsontext.Decoder) error
+}
+
+// Unmarshaler is implemented by types that can unmarshal a JSON
+// description of themselves. The input can be assumed to be a valid
+// encoding of a JSON value. UnmarshalJSON must copy the JSON data
+// if it wishes to retain the data after returning.
+// It is the same interface as the one declared by package encoding/json.
+type Unmarshaler interface {
+	UnmarshalJSON([]byte) error
+}
+
+var (
+	marshalerToType     = reflect.TypeOf((*MarshalerTo)(nil)).Elem()
+	marshalerType       = reflect.TypeOf((*Marshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	unmarshalerFromType = reflect.TypeOf((*UnmarshalerFrom)(nil)).Elem()
+	unmarshalerType     = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	rawValueType        = reflect.TypeOf(jsontext.RawValue(nil))
+)
+
+// Marshal serializes a Go value as a []byte according to the provided
+// marshal and encode options. It does not terminate the output with a newline.
+//
+// Type-specific marshal functions and methods take precedence over the
+// default representation of a value. In order of precedence, these are
+// the functions provided by WithMarshalers, the MarshalJSONTo method,
+// the MarshalJSON method, and the MarshalText method.
+// Values of type time.Time and time.Duration are an exception: their
+// representation is controlled by the `format` struct tag option and
+// their methods are not used.
+//
+// Otherwise, a Go value is marshaled according to its kind:
+//
+//   - A Go boolean is encoded as a JSON boolean (e.g., true or false).
+//
+//   - A Go string is encoded as a JSON string.
+//
+//   - A Go signed or unsigned integer is encoded as a JSON number.
+//
+//   - A Go float is encoded as a JSON number using the shortest
+//     representation that round-trips. NaN and infinite values
+//     cannot be marshaled.
+//
+//   - A Go map is encoded as a JSON object, where each Go map key and value
+//     is recursively encoded as a name and value pair in the JSON object.
+//     The Go map key must be a string, an integer, or implement
+//     encoding.TextMarshaler. Members are sorted by name if Deterministic
+//     is specified. A nil map is encoded as an empty JSON object,
+//     unless FormatNilMapAsNull is specified.
+//
+//   - A Go struct is encoded as a JSON object as described for the
+//     struct tags below.
+//
+//   - A Go slice is encoded as a JSON array, where each Go slice element
+//     is recursively encoded as the elements of the JSON array.
+//     A nil slice is encoded as an empty JSON array,
+//     unless FormatNilSliceAsNull is specified.
+//     A slice of bytes is encoded as a base64-encoded JSON string,
+//     unless the `format` option of its struct field selects
+//     base64url, base32, base32hex, base16 (or hex), or array.
+//
+//   - A Go array is encoded like a Go slice, except that it is never null.
+//
+//   - A Go pointer or interface is encoded as a JSON null if nil,
+//     and otherwise as the value that it refers to.
+//
+//   - Go channels, functions, and complex numbers cannot be marshaled.
+//
+// The encoding of each struct field can be customized by the format string
+// stored under the "json" key in the struct field's tag, which is a
+// comma-separated list. The first entry is the JSON object name of the
+// field; if it is empty, the Go field name is used. The remaining
+// entries are options:
+//
+//   - omitzero: The field is omitted if it is the zero Go value,
+//     or if the field type has an IsZero() bool method that reports true.
+//
+//   - omitempty: The field is omitted if it is a nil pointer or interface,
+//     or a string, slice, map, or array of length zero.
+//
+//   - string: Numbers within the field value are encoded as JSON strings.
+//
+//   - case:ignore or case:strict: Controls whether the name of the field
+//     is matched case-insensitively when unmarshaling
+//     (see MatchCaseInsensitiveNames).
+//
+//   - inline: The JSON object members of the field value are promoted
+//     into the enclosing JSON object. The field must be a struct,
+//     a map[string]T, or a jsontext.RawValue. An embedded Go struct
+//     without an explicit JSON name is always inlined.
+//
+//   - unknown: The field is a map[string]T or a jsontext.RawValue that
+//     holds the members of the JSON object that match no other field.
+//     An inlined map or jsontext.RawValue is treated the same way.
+//     There may be at most one such field in a struct.
+//
+//   - format:value: Selects a representation for the field value.
+//     A []byte or [N]byte accepts base64, base64url, base32, base32hex,
+//     base16, hex, or array. A slice or map accepts emitempty, which
+//     forces a nil value to be encoded as an empty JSON array or object.
+//     A time.Time accepts unix, unixmilli, unixmicro, unixnano,
+//     the name of a layout constant in package time (e.g., RFC1123),
+//     or a custom layout. A time.Duration accepts nanos or units.
+//     Other types do not accept a format. The value may be enclosed in
+//     single quotes in order to include commas.
+//
+// A field whose tag is "-" is ignored, as are unexported fields.
+// When several fields share a JSON name, the one with the shallowest
+// embedding depth is used, preferring a tagged one if there is a tie at
+// that depth, as with package encoding/json.
+func Marshal(in interface{}, opts ...Options) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := jsontext.NewEncoder(&buf, opts...)
+	if err := marshalEncode(enc, in, opts); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// MarshalWrite serializes a Go value into an io.Writer according to the
+// provided marshal and encode options, followed by a newline.
+// See Marshal for details about the conversion of a Go value into JSON.
+func MarshalWrite(out io.Writer, in interface{}, opts ...Options) error {
+	enc := jsontext.NewEncoder(out, opts...)
+	return marshalEncode(enc, in, opts)
+}
+
+// MarshalEncode serializes a Go value into a jsontext.Encoder according to
+// the provided marshal options, in addition to those of the Encoder.
+// It writes exactly one JSON value.
+// See Marshal for details about the conversion of a Go value into JSON.
+func MarshalEncode(out *jsontext.Encoder, in interface{}, opts ...Options) error {
+	return marshalEncode(out, in, opts)
+}
+
+func marshalEncode(enc *jsontext.Encoder, in interface{}, opts []Options) error {
+	if in == nil {
+		return enc.WriteToken(jsontext.Null)
+	}
+	o := new(arshalOptions)
+	o.opts.Join(enc.Options())
+	o.opts.Join(opts...)
+	o.marshalers, _ = o.opts.Marshalers.(*Marshalers)
+
+	// Make the value addressable so that methods with pointer receivers
+	// are always available.
+	v := reflect.ValueOf(in)
+	va := reflect.New(v.Type()).Elem()
+	va.Set(v)
+	return marshalValue(enc, va, o)
+}
+
+// Unmarshal decodes a []byte input into a Go value according to the
+// provided unmarshal and decode options. The input must be a single JSON
+// value with optional whitespace interspersed.
+// The output must be a non-nil pointer.
+//
+// Type-specific unmarshal functions and methods take precedence over the
+// default representation of a value. In order of precedence, these are
+// the functions provided by WithUnmarshalers, the UnmarshalJSONFrom method,
+// the UnmarshalJSON method, and the UnmarshalText method.
+//
+// Otherwise, a JSON value is unmarshaled into a Go value according to the
+// Go kind, mirroring the rules described by Marshal. In particular:
+//
+//   - A JSON null stores the zero value into the Go value.
+//
+//   - JSON object members are matched to Go struct fields by name,
+//     case-sensitively by default. Members that match no field are
+//     stored in the field marked `unknown`, if any, rejected if
+//     RejectUnknownMembers is specified, or skipped otherwise.
+//
+//   - A JSON array unmarshaled into a Go array must have exactly
+//     as many elements as the Go array.
+//
+//   - A JSON value unmarshaled into an empty Go interface is stored as
+//     a bool, string, float64, map[string]interface{}, []interface{}, or nil.
+//     A non-empty Go interface must already hold a value to unmarshal into.
+//
+// Duplicate JSON object names are rejected unless
+// jsontext.AllowDuplicateNames is specified.
+// Unmarshal stops at the first error, which is a *jsontext.SyntacticError
+// for malformed input, and a *SemanticError otherwise.
+func Unmarshal(in []byte, out interface{}, opts ...Options) error {
+	dec := jsontext.NewDecoder(bytes.NewReader(in), opts...)
+	return unmarshalFull(dec, out, opts)
+}
+
+// UnmarshalRead deserializes a Go value from an io.Reader according to the
+// provided unmarshal and decode options. The input must be a single JSON
+// value with optional whitespace interspersed and is consumed until io.EOF.
+// See Unmarshal for details about the conversion of JSON into a Go value.
+func UnmarshalRead(in io.Reader, out interface{}, opts ...Options) error {
+	dec := jsontext.NewDecoder(in, opts...)
+	return unmarshalFull(dec, out, opts)
+}
+
+func unmarshalFull(dec *jsontext.Decoder, out interface{}, opts []Options) error {
+	if err := unmarshalDecode(dec, out, opts); err != nil {
+		return err
+	}
+	off := dec.InputOffset()
+	if _, err := dec.ReadToken(); err != io.EOF {
+		if err == nil {
+			err = &jsontext.SyntacticError{ByteOffset: off, Err: errors.New("unexpected data after top-level value")}
+		}
+		return err
+	}
+	return nil
+}
+
+// UnmarshalDecode deserializes a Go value from a jsontext.Decoder according
+// to the provided unmarshal options, in addition to those of the Decoder.
+// It reads exactly one JSON value and does not check for trailing data.
+// See Unmarshal for details about the conversion of JSON into a Go value.
+func UnmarshalDecode(in *jsontext.Decoder, out interface{}, opts ...Options) error {
+	return unmarshalDecode(in, out, opts)
+}
+
+func unmarshalDecode(dec *jsontext.Decoder, out interface{}, opts []Options) error {
+	v := reflect.ValueOf(out)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return &SemanticError{action: "unmarshal", GoType: reflect.TypeOf(out), Err: errors.New("value must be passed as a non-nil pointer reference")}
+	}
+	o := new(arshalOptions)
+	o.opts.Join(dec.Options())
+	o.opts.Join(opts...)
+	o.unmarshalers, _ = o.opts.Unmarshalers.(*Unmarshalers)
+	if dec.PeekKind() == 0 {
+		_, err := dec.ReadToken()
+		if err == io.EOF {
+			err = &jsontext.SyntacticError{ByteOffset: dec.InputOffset(), Err: io.ErrUnexpectedEOF}
+		}
+		return err
+	}
+	return unmarshalValue(dec, v.Elem(), o)
+}
+
+// arshalOptions holds the state of a single Marshal or Unmarshal call.
+type arshalOptions struct {
+	opts         jsonopts.Struct
+	marshalers   *Marshalers
+	unmarshalers *Unmarshalers
+
+	// format is the format flag of the struct field currently being
+	// processed. It applies only to the field value itself and is
+	// consumed by the first arshaler that sees it.
+	format string
+
+	// stringify reports whether numbers are encoded as JSON strings.
+	// It applies to the entire value of a struct field.
+	stringify bool
+}
+
+// takeFormat returns and clears the pending format flag.
+func (o *arshalOptions) takeFormat() string {
+	f := o.format
+	o.format = ""
+	return f
+}
+
+// arshaler holds the marshal and unmarshal functions for a Go type.
+// Both functions operate on addressable values.
+type arshaler struct {
+	marshal   func(*jsontext.Encoder, reflect.Value, *arshalOptions) error
+	unmarshal func(*jsontext.Decoder, reflect.Value, *arshalOptions) error
+}
+
+var arshalerCache sync.Map // map[reflect.Type]*arshaler
+
+func lookupArshaler(t reflect.Type) *arshaler {
+	if fncs, ok := arshalerCache.Load(t); ok {
+		return fncs.(*arshaler)
+	}
+	fncs := makeDefaultArshaler(t)
+	fncs = makeMethodArshaler(fncs, t)
+	fncs = makeTimeArshaler(fncs, t)
+	v, _ := arshalerCache.LoadOrStore(t, fncs)
+	return v.(*arshaler)
+}
+
+// marshalValue marshals the addressable value va, giving precedence to
+// the caller-supplied marshalers.
+func marshalValue(enc *jsontext.Encoder, va reflect.Value, o *arshalOptions) error {
+	if o.marshalers != nil {
+		if handled, err := o.marshalers.marshal(enc, va, o); handled {
+			return err
+		}
+	}
+	return lookupArshaler(va.Type()).marshal(enc, va, o)
+}
+
+// unmarshalValue unmarshals into the addressable value va, giving
+// precedence to the caller-supplied unmarshalers.
+func unmarshalValue(dec *jsontext.Decoder, va reflect.Value, o *arshalOptions) error {
+	if o.unmarshalers != nil {
+		if handled, err := o.unmarshalers.unmarshal(dec, va, o); handled {
+			return err
+		}
+	}
+	return lookupArshaler(va.Type()).unmarshal(dec, va, o)
+}
+
+// addressable returns an addressable copy of v if v is not addressable.
+func addressable(v reflect.Value) reflect.Value {
+	if v.CanAddr() {
+		return v
+	}
+	va := reflect.New(v.Type()).Elem()
+	va.Set(v)
+	return va
+}
+
+// implements reports whether values of type t implement ifaceType,
+// either directly or through a pointer receiver.
+func implements(t, ifaceType reflect.Type) bool {
+	return t.Implements(ifaceType) || (t.Kind() != reflect.Interface && reflect.PtrTo(t).Implements(ifaceType))
+}
+
+// methodReceiver returns va or its address, whichever implements ifaceType.
+func methodReceiver(va reflect.Value, ifaceType reflect.Type) reflect.Value {
+	if va.Type().Implements(ifaceType) {
+		return va
+	}
+	return va.Addr()
+}
+
+// makeMethodArshaler wraps fncs to call the marshal and unmarshal methods
+// implemented by t, if any.
+func makeMethodArshaler(fncs *arshaler, t reflect.Type) *arshaler {
+	switch t.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		// Methods are handled on the concrete value that is pointed to,
+		// which is allocated as needed when unmarshaling.
+		return fncs
+	}
+	switch {
+	case implements(t, marshalerToType):
+		fncs = &arshaler{marshal: func(enc *jsontext.Encoder, va reflect.Value, o *arshalOptions) error {
+			if format := o.takeFormat(); format != "" {
+				return newInvalidFormatError("marshal", t, format)
+			}
+			prevDepth, prevOffset := enc.StackDepth(), enc.OutputOffset()
+			if err := methodReceiver(va, marshalerToType).Interface().(MarshalerTo).MarshalJSONTo(enc); err != nil {
+				return newMarshalError(enc, t, err)
+			}
+			if enc.StackDepth() != prevDepth || enc.OutputOffset() == prevOffset {
+				return newMarshalError(enc, t, errors.New("MarshalJSONTo must write exactly one JSON value"))
+			}
+			return nil
+		}, unmarshal: fncs.unmarshal}
+	case implements(t, marshalerType):
+		fncs = &arshaler{marshal: func(enc *jsontext.Encoder, va reflect.Value, o *arshalOptions) error {
+			if format := o.takeFormat(); format != "" {
+				return newInvalidFormatError("marshal", t, format)
+			}
+			b, err := methodReceiver(va, marshalerType).Interface().(Marshaler).MarshalJSON()
+			if err != nil {
+				return newMarshalError(enc, t, err)
+			}
+			if err := enc.WriteValue(b); err != nil {
+				return newMarshalError(enc, t, err)
+			}
+			return nil
+		}, unmarshal: fncs.unmarshal}
+	case implements(t, textMarshalerType):
+		fncs = &arshaler{marshal: func(enc *jsontext.Encoder, va reflect.Value, o *arshalOptions) error {
+			if format := o.takeFormat(); format != "" {
+				return newInvalidFormatError("marshal", t, format)
+			}
+			b, err := methodReceiver(va, textMarshalerType).Interface().(encoding.TextMarshaler).MarshalText()
+			if err != nil {
+				return newMarshalError(enc, t, err)
+			}
+			return enc.WriteToken(jsontext.String(string(b)))
+		}, unmarshal: fncs.unmarshal}
+	}
+
+	switch {
+	case implements(t, unmarshalerFromType):
+		fncs = &arshaler{marshal: fncs.marshal, unmarshal: func(dec *jsontext.Decoder, va reflect.Value, o *arshalOptions) error {
+			if format := o.takeFormat(); format != "" {
+				return newInvalidFormatError("unmarshal", t, format)
+			}
+			off := dec.InputOffset()
+			prevDepth := dec.StackDepth()
+			if err := methodReceiver(va, unmarshalerFromType).Interface().(UnmarshalerFrom).UnmarshalJSONFrom(dec); err != nil {
+				return newUnmarshalError(off, 0, t, err)
+			}
+			if dec.StackDepth() != prevDepth || dec.InputOffset() == off {
+				return newUnmarshalError(off, 0, t, errors.New("UnmarshalJSONFrom must read exactly one JSON value"))
+			}
+			return nil
+		}}
+	case implements(t, unmarshalerType):
+		fncs = &arshaler{marshal: fncs.marshal, unmarshal: func(dec *jsontext.Decoder, va reflect.Value, o *arshalOptions) error {
+			if format := o.takeFormat(); format != "" {
+				return newInvalidFormatError("unmarshal", t, format)
+			}
+			off := dec.InputOffset()
+			val, err := dec.ReadValue()
+			if err != nil {
+				return err
+			}
+			if err := methodReceiver(va, unmarshalerType).Interface().(Unmarshaler).UnmarshalJSON(val); err != nil {
+				return newUnmarshalError(off, val.Kind(), t, err)
+			}
+			return nil
+		}}
+	case implements(t, textUnmarshalerType):
+		fncs = &arshaler{marshal: fncs.marshal, unmarshal: func(dec *jsontext.Decoder, va reflect.Value, o *arshalOptions) error {
+			if format := o.takeFormat(); format != "" {
+				return newInvalidFormatError("unmarshal", t, format)
+			}
+			off := dec.InputOffset()
+			tok, err := dec.ReadToken()
+			if err != nil {
+				return err
+			}
+			switch tok.Kind() {
+			case 'n':
+				va.Set(reflect.Zero(t))
+				return nil
+			case '"':
+				if err := methodReceiver(va, textUnmarshalerType).Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(tok.String())); err != nil {
+					return newUnmarshalError(off, '"', t, err)
+				}
+				return nil
+			}
+			return newUnmarshalError(off, tok.Kind(), t, nil)
+		}}
+	}
+	return fncs
+}
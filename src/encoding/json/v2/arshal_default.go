@@ -0,0 +1,787 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package json
+
+import (
+	"encoding"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json/internal/jsonopts"
+	"encoding/json/internal/jsonwire"
+	"encoding/json/jsontext"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+func newInvalidFormatError(action string, t reflect.Type, format string) error {
+	return &SemanticError{action: action, GoType: t, Err: fmt.Errorf("invalid format flag %q", format)}
+}
+
+// makeDefaultArshaler returns the functions implementing the default
+// representation of values of type t, as described by Marshal.
+func makeDefaultArshaler(t reflect.Type) *arshaler {
+	switch t.Kind() {
+	case reflect.Bool:
+		return makeBoolArshaler(t)
+	case reflect.String:
+		return makeStringArshaler(t)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return makeIntArshaler(t)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return makeUintArshaler(t)
+	case reflect.Float32, reflect.Float64:
+		return makeFloatArshaler(t)
+	case reflect.Map:
+		return makeMapArshaler(t)
+	case reflect.Struct:
+		return makeStructArshaler(t)
+	case reflect.Slice:
+		return makeSliceArshaler(t)
+	case reflect.Array:
+		return makeArrayArshaler(t)
+	case reflect.Ptr:
+		return makePointerArshaler(t)
+	case reflect.Interface:
+		return makeInterfaceArshaler(t)
+	}
+	return makeInvalidArshaler(t)
+}
+
+func makeBoolArshaler(t reflect.Type) *arshaler {
+	return &arshaler{
+		marshal: func(enc *jsontext.Encoder, va reflect.Value, o *arshalOptions) error {
+			if format := o.takeFormat(); format != "" {
+				return newInvalidFormatError("marshal", t, format)
+			}
+			return enc.WriteToken(jsontext.Bool(va.Bool()))
+		},
+		unmarshal: func(dec *jsontext.Decoder, va reflect.Value, o *arshalOptions) error {
+			if format := o.takeFormat(); format != "" {
+				return newInvalidFormatError("unmarshal", t, format)
+			}
+			off := dec.InputOffset()
+			tok, err := dec.ReadToken()
+			if err != nil {
+				return err
+			}
+			switch k := tok.Kind(); k {
+			case 'n':
+				va.SetBool(false)
+			case 't', 'f':
+				va.SetBool(tok.Bool())
+			default:
+				return newUnmarshalError(off, k, t, nil)
+			}
+			return nil
+		},
+	}
+}
+
+func makeStringArshaler(t reflect.Type) *arshaler {
+	return &arshaler{
+		marshal: func(enc *jsontext.Encoder, va reflect.Value, o *arshalOptions) error {
+			if format := o.takeFormat(); format != "" {
+				return newInvalidFormatError("marshal", t, format)
+			}
+			return enc.WriteToken(jsontext.String(va.String()))
+		},
+		unmarshal: func(dec *jsontext.Decoder, va reflect.Value, o *arshalOptions) error {
+			if format := o.takeFormat(); format != "" {
+				return newInvalidFormatError("unmarshal", t, format)
+			}
+			off := dec.InputOffset()
+			tok, err := dec.ReadToken()
+			if err != nil {
+				return err
+			}
+			switch k := tok.Kind(); k {
+			case 'n':
+				va.SetString("")
+			case '"':
+				va.SetString(tok.String())
+			default:
+				return newUnmarshalError(off, k, t, nil)
+			}
+			return nil
+		},
+	}
+}
+
+// readNumber reads a JSON number, or a JSON string containing one if
+// numbers are stringified, and returns its text. It returns an empty
+// string for a JSON null.
+func readNumber(dec *jsontext.Decoder, t reflect.Type, o *arshalOptions) (s string, k jsontext.Kind, off int64, err error) {
+	off = dec.InputOffset()
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return "", 0, off, err
+	}
+	switch k = tok.Kind(); {
+	case k == 'n':
+		return "", k, off, nil
+	case k == '0', k == '"' && o.stringify:
+		s = tok.String()
+		if k == '"' && (s == "" || s[0] == '+' || s[0] == ' ') {
+			return "", k, off, newUnmarshalError(off, k, t, fmt.Errorf("invalid number %q", s))
+		}
+		return s, k, off, nil
+	}
+	return "", k, off, newUnmarshalError(off, k, t, nil)
+}
+
+func makeIntArshaler(t reflect.Type) *arshaler {
+	return &arshaler{
+		marshal: func(enc *jsontext.Encoder, va reflect.Value, o *arshalOptions) error {
+			if format := o.takeFormat(); format != "" {
+				return newInvalidFormatError("marshal", t, format)
+			}
+			if o.stringify {
+				return enc.WriteToken(jsontext.String(strconv.FormatInt(va.Int(), 10)))
+			}
+			return enc.WriteToken(jsontext.Int(va.Int()))
+		},
+		unmarshal: func(dec *jsontext.Decoder, va reflect.Value, o *arshalOptions) error {
+			if format := o.takeFormat(); format != "" {
+				return newInvalidFormatError("unmarshal", t, format)
+			}
+			s, k, off, err := readNumber(dec, t, o)
+			if err != nil || k == 'n' {
+				if err == nil {
+					va.SetInt(0)
+				}
+				return err
+			}
+			n, err := strconv.ParseInt(s, 10, t.Bits())
+			if err != nil {
+				return newUnmarshalError(off, k, t, err)
+			}
+			va.SetInt(n)
+			return nil
+		},
+	}
+}
+
+func makeUintArshaler(t reflect.Type) *arshaler {
+	return &arshaler{
+		marshal: func(enc *jsontext.Encoder, va reflect.Value, o *arshalOptions) error {
+			if format := o.takeFormat(); format != "" {
+				return newInvalidFormatError("marshal", t, format)
+			}
+			if o.stringify {
+				return enc.WriteToken(jsontext.String(strconv.FormatUint(va.Uint(), 10)))
+			}
+			return enc.WriteToken(jsontext.Uint(va.Uint()))
+		},
+		unmarshal: func(dec *jsontext.Decoder, va reflect.Value, o *arshalOptions) error {
+			if format := o.takeFormat(); format != "" {
+				return newInvalidFormatError("unmarshal", t, format)
+			}
+			s, k, off, err := readNumber(dec, t, o)
+			if err != nil || k == 'n' {
+				if err == nil {
+					va.SetUint(0)
+				}
+				return err
+			}
+			n, err := strconv.ParseUint(s, 10, t.Bits())
+			if err != nil {
+				return newUnmarshalError(off, k, t, err)
+			}
+			va.SetUint(n)
+			return nil
+		},
+	}
+}
+
+func makeFloatArshaler(t reflect.Type) *arshaler {
+	return &arshaler{
+		marshal: func(enc *jsontext.Encoder, va reflect.Value, o *arshalOptions) error {
+			if format := o.takeFormat(); format != "" {
+				return newInvalidFormatError("marshal", t, format)
+			}
+			f := va.Float()
+			if math.IsNaN(f) || math.IsInf(f, 0) {
+				return newMarshalError(enc, t, fmt.Errorf("unsupported value: %v", f))
+			}
+			switch {
+			case o.stringify:
+				return enc.WriteToken(jsontext.String(string(jsonwire.AppendFloat(nil, f, t.Bits()))))
+			case t.Bits() == 32:
+				return enc.WriteValue(jsonwire.AppendFloat(nil, f, 32))
+			}
+			return enc.WriteToken(jsontext.Float(f))
+		},
+		unmarshal: func(dec *jsontext.Decoder, va reflect.Value, o *arshalOptions) error {
+			if format := o.takeFormat(); format != "" {
+				return newInvalidFormatError("unmarshal", t, format)
+			}
+			s, k, off, err := readNumber(dec, t, o)
+			if err != nil || k == 'n' {
+				if err == nil {
+					va.SetFloat(0)
+				}
+				return err
+			}
+			f, err := strconv.ParseFloat(s, t.Bits())
+			if err != nil {
+				return newUnmarshalError(off, k, t, err)
+			}
+			va.SetFloat(f)
+			return nil
+		},
+	}
+}
+
+// mapKeyName returns the JSON object name for a Go map key.
+func mapKeyName(k reflect.Value) (string, error) {
+	switch {
+	case k.Kind() == reflect.String:
+		return k.String(), nil
+	case k.Type().Implements(textMarshalerType):
+		if k.Kind() == reflect.Ptr && k.IsNil() {
+			return "", nil
+		}
+		b, err := k.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+	return "", errUnsupportedType
+}
+
+// setMapKey stores the Go map key for the JSON object name into k.
+func setMapKey(k reflect.Value, name string) error {
+	switch {
+	case k.Kind() == reflect.String:
+		k.SetString(name)
+		return nil
+	case implements(k.Type(), textUnmarshalerType):
+		if k.Kind() == reflect.Ptr {
+			k.Set(reflect.New(k.Type().Elem()))
+		}
+		return methodReceiver(k, textUnmarshalerType).Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(name))
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(name, 10, k.Type().Bits())
+		k.SetInt(n)
+		return err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(name, 10, k.Type().Bits())
+		k.SetUint(n)
+		return err
+	}
+	return errUnsupportedType
+}
+
+// isValidMapKey reports whether t can be used as the key of a marshaled map.
+func isValidMapKey(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return t.Implements(textMarshalerType) && implements(t, textUnmarshalerType)
+}
+
+func makeMapArshaler(t reflect.Type) *arshaler {
+	if !isValidMapKey(t.Key()) {
+		return makeInvalidArshaler(t)
+	}
+	return &arshaler{
+		marshal: func(enc *jsontext.Encoder, va reflect.Value, o *arshalOptions) error {
+			emitEmpty := false
+			switch format := o.takeFormat(); format {
+			case "":
+			case "emitempty":
+				emitEmpty = true
+			default:
+				return newInvalidFormatError("marshal", t, format)
+			}
+			if va.IsNil() && !emitEmpty && o.opts.Get(jsonopts.FormatNilMapAsNull) {
+				return enc.WriteToken(jsontext.Null)
+			}
+			if err := enc.WriteToken(jsontext.BeginObject); err != nil {
+				return err
+			}
+			if err := marshalMapMembers(enc, va, o); err != nil {
+				return err
+			}
+			return enc.WriteToken(jsontext.EndObject)
+		},
+		unmarshal: func(dec *jsontext.Decoder, va reflect.Value, o *arshalOptions) error {
+			if format := o.takeFormat(); format != "" && format != "emitempty" {
+				return newInvalidFormatError("unmarshal", t, format)
+			}
+			off := dec.InputOffset()
+			tok, err := dec.ReadToken()
+			if err != nil {
+				return err
+			}
+			switch k := tok.Kind(); k {
+			case 'n':
+				va.Set(reflect.Zero(t))
+				return nil
+			case '{':
+			default:
+				return newUnmarshalError(off, k, t, nil)
+			}
+			if va.IsNil() {
+				va.Set(reflect.MakeMap(t))
+			}
+			for dec.PeekKind() != '}' {
+				if err := unmarshalMapMember(dec, va, o); err != nil {
+					return err
+				}
+			}
+			_, err = dec.ReadToken()
+			return err
+		},
+	}
+}
+
+// marshalMapMembers writes the members of the map va, which must be
+// a map with valid keys, without the enclosing delimiters.
+func marshalMapMembers(enc *jsontext.Encoder, va reflect.Value, o *arshalOptions) error {
+	if va.Len() == 0 {
+		return nil
+	}
+	t := va.Type()
+	val := reflect.New(t.Elem()).Elem()
+	if !o.opts.Get(jsonopts.Deterministic) || va.Len() == 1 {
+		iter := va.MapRange()
+		for iter.Next() {
+			name, err := mapKeyName(iter.Key())
+			if err != nil {
+				return newMarshalError(enc, t.Key(), err)
+			}
+			if err := enc.WriteToken(jsontext.String(name)); err != nil {
+				return err
+			}
+			val.Set(iter.Value())
+			if err := marshalValue(enc, val, o); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	type member struct {
+		name string
+		key  reflect.Value
+	}
+	members := make([]member, 0, va.Len())
+	for _, k := range va.MapKeys() {
+		name, err := mapKeyName(k)
+		if err != nil {
+			return newMarshalError(enc, t.Key(), err)
+		}
+		members = append(members, member{name, k})
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].name < members[j].name })
+	for _, m := range members {
+		if err := enc.WriteToken(jsontext.String(m.name)); err != nil {
+			return err
+		}
+		val.Set(va.MapIndex(m.key))
+		if err := marshalValue(enc, val, o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unmarshalMapMember reads a single object member into the map va,
+// merging with any existing value for the same key.
+func unmarshalMapMember(dec *jsontext.Decoder, va reflect.Value, o *arshalOptions) error {
+	t := va.Type()
+	off := dec.InputOffset()
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return err
+	}
+	key := reflect.New(t.Key()).Elem()
+	if err := setMapKey(key, tok.String()); err != nil {
+		return newUnmarshalError(off, '"', t.Key(), err)
+	}
+	val := reflect.New(t.Elem()).Elem()
+	if v := va.MapIndex(key); v.IsValid() {
+		val.Set(v)
+	}
+	if err := unmarshalValue(dec, val, o); err != nil {
+		return err
+	}
+	va.SetMapIndex(key, val)
+	return nil
+}
+
+// isBytes reports whether the slice or array type t is represented as a
+// JSON string of encoded bytes by default.
+func isBytes(t reflect.Type) bool {
+	e := t.Elem()
+	if e.Kind() != reflect.Uint8 {
+		return false
+	}
+	for _, iface := range []reflect.Type{marshalerToType, marshalerType, textMarshalerType, unmarshalerFromType, unmarshalerType, textUnmarshalerType} {
+		if implements(e, iface) {
+			return false
+		}
+	}
+	return true
+}
+
+// encodeBytes encodes b according to the format flag.
+func encodeBytes(format string, b []byte) (string, bool) {
+	switch format {
+	case "", "base64":
+		return base64.StdEncoding.EncodeToString(b), true
+	case "base64url":
+		return base64.URLEncoding.EncodeToString(b), true
+	case "base32":
+		return base32.StdEncoding.EncodeToString(b), true
+	case "base32hex":
+		return base32.HexEncoding.EncodeToString(b), true
+	case "base16", "hex":
+		return hex.EncodeToString(b), true
+	}
+	return "", false
+}
+
+// decodeBytes decodes s according to the format flag, which has already
+// been validated by encodeBytes.
+func decodeBytes(format, s string) ([]byte, error) {
+	switch format {
+	case "base64url":
+		return base64.URLEncoding.DecodeString(s)
+	case "base32":
+		return base32.StdEncoding.DecodeString(s)
+	case "base32hex":
+		return base32.HexEncoding.DecodeString(s)
+	case "base16", "hex":
+		return hex.DecodeString(s)
+	}
+	return base64.StdEncoding.DecodeString(s)
+}
+
+// marshalArrayElems writes the elements of the slice or array va
+// as a JSON array.
+func marshalArrayElems(enc *jsontext.Encoder, va reflect.Value, o *arshalOptions) error {
+	if err := enc.WriteToken(jsontext.BeginArray); err != nil {
+		return err
+	}
+	for i, n := 0, va.Len(); i < n; i++ {
+		if err := marshalValue(enc, va.Index(i), o); err != nil {
+			return err
+		}
+	}
+	return enc.WriteToken(jsontext.EndArray)
+}
+
+// readBytes reads a JSON string of bytes encoded according to format.
+func readBytes(tok jsontext.Token, off int64, t reflect.Type, format string) ([]byte, error) {
+	b, err := decodeBytes(format, tok.String())
+	if err != nil {
+		return nil, newUnmarshalError(off, '"', t, err)
+	}
+	return b, nil
+}
+
+func makeSliceArshaler(t reflect.Type) *arshaler {
+	bytes := isBytes(t)
+	return &arshaler{
+		marshal: func(enc *jsontext.Encoder, va reflect.Value, o *arshalOptions) error {
+			format := o.takeFormat()
+			emitEmpty := format == "emitempty"
+			if emitEmpty {
+				format = ""
+			}
+			if va.IsNil() && !emitEmpty && o.opts.Get(jsonopts.FormatNilSliceAsNull) {
+				return enc.WriteToken(jsontext.Null)
+			}
+			if bytes && format != "array" {
+				s, ok := encodeBytes(format, va.Bytes())
+				if !ok {
+					return newInvalidFormatError("marshal", t, format)
+				}
+				return enc.WriteToken(jsontext.String(s))
+			}
+			if format != "" && !(bytes && format == "array") {
+				return newInvalidFormatError("marshal", t, format)
+			}
+			return marshalArrayElems(enc, va, o)
+		},
+		unmarshal: func(dec *jsontext.Decoder, va reflect.Value, o *arshalOptions) error {
+			format := o.takeFormat()
+			if format == "emitempty" {
+				format = ""
+			}
+			if bytes && format != "array" {
+				if _, ok := encodeBytes(format, nil); !ok {
+					return newInvalidFormatError("unmarshal", t, format)
+				}
+			} else if format != "" && !(bytes && format == "array") {
+				return newInvalidFormatError("unmarshal", t, format)
+			}
+			off := dec.InputOffset()
+			tok, err := dec.ReadToken()
+			if err != nil {
+				return err
+			}
+			switch k := tok.Kind(); {
+			case k == 'n':
+				va.Set(reflect.Zero(t))
+				return nil
+			case k == '"' && bytes && format != "array":
+				b, err := readBytes(tok, off, t, format)
+				if err != nil {
+					return err
+				}
+				va.SetBytes(b)
+				return nil
+			case k != '[':
+				return newUnmarshalError(off, k, t, nil)
+			}
+			n := 0
+			zero := reflect.Zero(t.Elem())
+			for dec.PeekKind() != ']' {
+				if n == va.Cap() {
+					grown := reflect.MakeSlice(t, n, 2*n+4)
+					reflect.Copy(grown, va)
+					va.Set(grown)
+				}
+				va.SetLen(n + 1)
+				elem := va.Index(n)
+				elem.Set(zero)
+				if err := unmarshalValue(dec, elem, o); err != nil {
+					return err
+				}
+				n++
+			}
+			if va.IsNil() {
+				va.Set(reflect.MakeSlice(t, 0, 0))
+			} else {
+				va.SetLen(n)
+			}
+			_, err = dec.ReadToken()
+			return err
+		},
+	}
+}
+
+func makeArrayArshaler(t reflect.Type) *arshaler {
+	bytes := isBytes(t)
+	return &arshaler{
+		marshal: func(enc *jsontext.Encoder, va reflect.Value, o *arshalOptions) error {
+			format := o.takeFormat()
+			if bytes && format != "array" {
+				s, ok := encodeBytes(format, va.Slice(0, va.Len()).Bytes())
+				if !ok {
+					return newInvalidFormatError("marshal", t, format)
+				}
+				return enc.WriteToken(jsontext.String(s))
+			}
+			if format != "" && !(bytes && format == "array") {
+				return newInvalidFormatError("marshal", t, format)
+			}
+			return marshalArrayElems(enc, va, o)
+		},
+		unmarshal: func(dec *jsontext.Decoder, va reflect.Value, o *arshalOptions) error {
+			format := o.takeFormat()
+			if bytes && format != "array" {
+				if _, ok := encodeBytes(format, nil); !ok {
+					return newInvalidFormatError("unmarshal", t, format)
+				}
+			} else if format != "" && !(bytes && format == "array") {
+				return newInvalidFormatError("unmarshal", t, format)
+			}
+			off := dec.InputOffset()
+			tok, err := dec.ReadToken()
+			if err != nil {
+				return err
+			}
+			switch k := tok.Kind(); {
+			case k == 'n':
+				va.Set(reflect.Zero(t))
+				return nil
+			case k == '"' && bytes && format != "array":
+				b, err := readBytes(tok, off, t, format)
+				if err != nil {
+					return err
+				}
+				if len(b) != t.Len() {
+					return newUnmarshalError(off, k, t, fmt.Errorf("decoded length of %d mismatches array length of %d", len(b), t.Len()))
+				}
+				reflect.Copy(va, reflect.ValueOf(b))
+				return nil
+			case k != '[':
+				return newUnmarshalError(off, k, t, nil)
+			}
+			n := 0
+			zero := reflect.Zero(t.Elem())
+			for dec.PeekKind() != ']' {
+				if n == t.Len() {
+					return newUnmarshalError(off, '[', t, errors.New("too many array elements"))
+				}
+				elem := va.Index(n)
+				elem.Set(zero)
+				if err := unmarshalValue(dec, elem, o); err != nil {
+					return err
+				}
+				n++
+			}
+			if _, err := dec.ReadToken(); err != nil {
+				return err
+			}
+			if n < t.Len() {
+				return newUnmarshalError(off, '[', t, errors.New("too few array elements"))
+			}
+			return nil
+		},
+	}
+}
+
+func makePointerArshaler(t reflect.Type) *arshaler {
+	return &arshaler{
+		marshal: func(enc *jsontext.Encoder, va reflect.Value, o *arshalOptions) error {
+			if va.IsNil() {
+				o.format = ""
+				return enc.WriteToken(jsontext.Null)
+			}
+			return marshalValue(enc, va.Elem(), o)
+		},
+		unmarshal: func(dec *jsontext.Decoder, va reflect.Value, o *arshalOptions) error {
+			if dec.PeekKind() == 'n' {
+				o.format = ""
+				va.Set(reflect.Zero(t))
+				_, err := dec.ReadToken()
+				return err
+			}
+			if va.IsNil() {
+				va.Set(reflect.New(t.Elem()))
+			}
+			return unmarshalValue(dec, va.Elem(), o)
+		},
+	}
+}
+
+func makeInterfaceArshaler(t reflect.Type) *arshaler {
+	return &arshaler{
+		marshal: func(enc *jsontext.Encoder, va reflect.Value, o *arshalOptions) error {
+			if va.IsNil() {
+				o.format = ""
+				return enc.WriteToken(jsontext.Null)
+			}
+			return marshalValue(enc, addressable(va.Elem()), o)
+		},
+		unmarshal: func(dec *jsontext.Decoder, va reflect.Value, o *arshalOptions) error {
+			if dec.PeekKind() == 'n' {
+				o.format = ""
+				va.Set(reflect.Zero(t))
+				_, err := dec.ReadToken()
+				return err
+			}
+			if !va.IsNil() {
+				// Unmarshal into the existing value, either through a
+				// pointer or by replacing a copy of it.
+				if e := va.Elem(); e.Kind() == reflect.Ptr && !e.IsNil() {
+					return unmarshalValue(dec, e.Elem(), o)
+				}
+				if t.NumMethod() > 0 {
+					e := addressable(va.Elem())
+					if err := unmarshalValue(dec, e, o); err != nil {
+						return err
+					}
+					va.Set(e)
+					return nil
+				}
+			}
+			if t.NumMethod() > 0 {
+				return newUnmarshalError(dec.InputOffset(), dec.PeekKind(), t, errors.New("cannot derive concrete type for non-empty interface"))
+			}
+			if format := o.takeFormat(); format != "" {
+				return newInvalidFormatError("unmarshal", t, format)
+			}
+			v, err := unmarshalAny(dec)
+			if err != nil {
+				return err
+			}
+			va.Set(reflect.ValueOf(v))
+			return nil
+		},
+	}
+}
+
+// unmarshalAny unmarshals the next JSON value into the Go representation
+// used for an empty interface.
+func unmarshalAny(dec *jsontext.Decoder) (interface{}, error) {
+	off := dec.InputOffset()
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return nil, err
+	}
+	switch k := tok.Kind(); k {
+	case 'n':
+		return nil, nil
+	case 'f', 't':
+		return tok.Bool(), nil
+	case '"':
+		return tok.String(), nil
+	case '0':
+		f, err := strconv.ParseFloat(tok.String(), 64)
+		if err != nil {
+			return nil, newUnmarshalError(off, k, reflect.TypeOf(f), err)
+		}
+		return f, nil
+	case '{':
+		m := make(map[string]interface{})
+		for dec.PeekKind() != '}' {
+			tok, err := dec.ReadToken()
+			if err != nil {
+				return nil, err
+			}
+			name := tok.String()
+			v, err := unmarshalAny(dec)
+			if err != nil {
+				return nil, err
+			}
+			m[name] = v
+		}
+		_, err := dec.ReadToken()
+		return m, err
+	case '[':
+		a := []interface{}{}
+		for dec.PeekKind() != ']' {
+			v, err := unmarshalAny(dec)
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, v)
+		}
+		_, err := dec.ReadToken()
+		return a, err
+	}
+	return nil, newUnmarshalError(off, tok.Kind(), nil, nil)
+}
+
+func makeInvalidArshaler(t reflect.Type) *arshaler {
+	return &arshaler{
+		marshal: func(enc *jsontext.Encoder, va reflect.Value, o *arshalOptions) error {
+			return newMarshalError(enc, t, errUnsupportedType)
+		},
+		unmarshal: func(dec *jsontext.Decoder, va reflect.Value, o *arshalOptions) error {
+			return newUnmarshalError(dec.InputOffset(), 0, t, errUnsupportedType)
+		},
+	}
+}
@@ -0,0 +1,285 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package json
+
+import (
+	"encoding/json/jsontext"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// SkipFunc may be returned by MarshalFunc, MarshalToFunc, and
+// UnmarshalFromFunc functions to indicate that the value is to be
+// handled by the next applicable function or by the default behavior.
+// A MarshalToFunc or UnmarshalFromFunc function may only return SkipFunc
+// if it has not written or read anything. An UnmarshalFunc function
+// has already consumed its input and must not return SkipFunc.
+var SkipFunc = errors.New("json: skip function")
+
+var (
+	bytesType   = reflect.TypeOf([]byte(nil))
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+	encoderType = reflect.TypeOf((*jsontext.Encoder)(nil))
+	decoderType = reflect.TypeOf((*jsontext.Decoder)(nil))
+)
+
+// typedMarshaler is a marshal function for values of a single type.
+type typedMarshaler struct {
+	typ reflect.Type
+	fnc func(*jsontext.Encoder, reflect.Value) error
+}
+
+// Marshalers is a list of functions that may override the marshal behavior
+// of specific types. Populate it with MarshalFunc, MarshalToFunc, and
+// JoinMarshalers, and pass it to WithMarshalers.
+// The zero value and a nil pointer are empty lists.
+//
+// The functions are tried in order. A function for type T applies to
+// a Go value of type T, or to any Go value that implements T if T is
+// an interface type, including through a pointer receiver.
+type Marshalers struct {
+	fncs []typedMarshaler
+}
+
+// JoinMarshalers constructs a flattened list of marshal functions.
+// If multiple functions in the list are applicable for a value of a given
+// type, then those earlier in the list take precedence over those that
+// come later. If a function returns SkipFunc, then the next applicable
+// function is called, otherwise the default marshaling behavior is used.
+func JoinMarshalers(ms ...*Marshalers) *Marshalers {
+	var all []typedMarshaler
+	for _, m := range ms {
+		if m != nil {
+			all = append(all, m.fncs...)
+		}
+	}
+	return &Marshalers{fncs: all}
+}
+
+// funcType checks that fn is a function with the given parameter and
+// result types, where a nil parameter type stands for the type T being
+// handled, and returns the function value and T. It panics otherwise.
+func funcType(name string, fn interface{}, in, out []reflect.Type) (reflect.Value, reflect.Type) {
+	v := reflect.ValueOf(fn)
+	t := v.Type()
+	if v.Kind() != reflect.Func || v.IsNil() || t.NumIn() != len(in) || t.NumOut() != len(out) || t.IsVariadic() {
+		panic(fmt.Sprintf("json.%s: invalid function type %v", name, t))
+	}
+	var typ reflect.Type
+	for i, want := range in {
+		if want == nil {
+			typ = t.In(i)
+		} else if t.In(i) != want {
+			panic(fmt.Sprintf("json.%s: invalid function type %v", name, t))
+		}
+	}
+	for i, want := range out {
+		if t.Out(i) != want {
+			panic(fmt.Sprintf("json.%s: invalid function type %v", name, t))
+		}
+	}
+	return v, typ
+}
+
+// MarshalFunc constructs a type-specific marshaler that specifies how to
+// marshal values of type T. The function fn must be of the form
+// func(T) ([]byte, error) for some type T; MarshalFunc panics otherwise.
+// The function must marshal exactly one JSON value.
+// The value of T must not be retained outside the function call.
+func MarshalFunc(fn interface{}) *Marshalers {
+	v, t := funcType("MarshalFunc", fn, []reflect.Type{nil}, []reflect.Type{bytesType, errorType})
+	return &Marshalers{fncs: []typedMarshaler{{
+		typ: t,
+		fnc: func(enc *jsontext.Encoder, va reflect.Value) error {
+			out := v.Call([]reflect.Value{va})
+			if err, _ := out[1].Interface().(error); err != nil {
+				return err
+			}
+			return enc.WriteValue(out[0].Bytes())
+		},
+	}}}
+}
+
+// MarshalToFunc constructs a type-specific marshaler that specifies how to
+// marshal values of type T. The function fn must be of the form
+// func(*jsontext.Encoder, T) error for some type T; MarshalToFunc panics
+// otherwise. The function must marshal exactly one JSON value by calling
+// write methods on the Encoder, such as MarshalEncode.
+// The value of T must not be retained outside the function call.
+func MarshalToFunc(fn interface{}) *Marshalers {
+	v, t := funcType("MarshalToFunc", fn, []reflect.Type{encoderType, nil}, []reflect.Type{errorType})
+	return &Marshalers{fncs: []typedMarshaler{{
+		typ: t,
+		fnc: func(enc *jsontext.Encoder, va reflect.Value) error {
+			prevDepth, prevOffset := enc.StackDepth(), enc.OutputOffset()
+			out := v.Call([]reflect.Value{reflect.ValueOf(enc), va})
+			err, _ := out[0].Interface().(error)
+			wrote := enc.OutputOffset() != prevOffset || enc.StackDepth() != prevDepth
+			switch {
+			case err == SkipFunc && wrote:
+				return errors.New("json: MarshalToFunc function returned SkipFunc after writing output")
+			case err != nil:
+				return err
+			case !wrote || enc.StackDepth() != prevDepth:
+				return errors.New("json: MarshalToFunc function must write exactly one JSON value")
+			}
+			return nil
+		},
+	}}}
+}
+
+// applies reports whether a function for type fnType applies to the
+// addressable value va, and returns the value to pass to it.
+func applies(fnType reflect.Type, va reflect.Value) (reflect.Value, bool) {
+	t := va.Type()
+	switch {
+	case t == fnType:
+		return va, true
+	case fnType.Kind() == reflect.Interface && t.Implements(fnType):
+		return va.Convert(fnType), true
+	case fnType.Kind() == reflect.Interface && va.CanAddr() && reflect.PtrTo(t).Implements(fnType):
+		return va.Addr().Convert(fnType), true
+	case fnType.Kind() == reflect.Ptr && fnType.Elem() == t && va.CanAddr():
+		return va.Addr(), true
+	}
+	return reflect.Value{}, false
+}
+
+// marshal calls the first applicable function for va.
+// It reports whether any function handled the value.
+func (m *Marshalers) marshal(enc *jsontext.Encoder, va reflect.Value, o *arshalOptions) (bool, error) {
+	for _, fn := range m.fncs {
+		arg, ok := applies(fn.typ, va)
+		if !ok {
+			continue
+		}
+		err := fn.fnc(enc, arg)
+		if err == SkipFunc {
+			continue
+		}
+		o.format = ""
+		if err != nil {
+			return true, newMarshalError(enc, va.Type(), err)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// typedUnmarshaler is an unmarshal function for values of a single type.
+type typedUnmarshaler struct {
+	typ reflect.Type
+	fnc func(*jsontext.Decoder, reflect.Value) error
+}
+
+// Unmarshalers is a list of functions that may override the unmarshal
+// behavior of specific types. Populate it with UnmarshalFunc,
+// UnmarshalFromFunc, and JoinUnmarshalers, and pass it to WithUnmarshalers.
+// The zero value and a nil pointer are empty lists.
+//
+// The functions are tried in order, and apply to Go values in the same
+// way as for Marshalers. Since unmarshaling stores into a value,
+// a function usually takes a pointer type *T, which applies to values
+// of type T.
+type Unmarshalers struct {
+	fncs []typedUnmarshaler
+}
+
+// JoinUnmarshalers constructs a flattened list of unmarshal functions.
+// If multiple functions in the list are applicable for a value of a given
+// type, then those earlier in the list take precedence over those that
+// come later. If a function returns SkipFunc, then the next applicable
+// function is called, otherwise the default unmarshaling behavior is used.
+func JoinUnmarshalers(us ...*Unmarshalers) *Unmarshalers {
+	var all []typedUnmarshaler
+	for _, u := range us {
+		if u != nil {
+			all = append(all, u.fncs...)
+		}
+	}
+	return &Unmarshalers{fncs: all}
+}
+
+// UnmarshalFunc constructs a type-specific unmarshaler that specifies how
+// to unmarshal values of type T. The function fn must be of the form
+// func([]byte, T) error for some type T; UnmarshalFunc panics otherwise.
+// The function is passed a single, valid JSON value and must not retain
+// the []byte or the value of T outside the function call.
+func UnmarshalFunc(fn interface{}) *Unmarshalers {
+	v, t := funcType("UnmarshalFunc", fn, []reflect.Type{bytesType, nil}, []reflect.Type{errorType})
+	return &Unmarshalers{fncs: []typedUnmarshaler{{
+		typ: t,
+		fnc: func(dec *jsontext.Decoder, va reflect.Value) error {
+			val, err := dec.ReadValue()
+			if err != nil {
+				return err
+			}
+			out := v.Call([]reflect.Value{reflect.ValueOf([]byte(val)), va})
+			if err, _ := out[0].Interface().(error); err != nil {
+				if err == SkipFunc {
+					return errors.New("json: UnmarshalFunc function returned SkipFunc after reading input")
+				}
+				return err
+			}
+			return nil
+		},
+	}}}
+}
+
+// UnmarshalFromFunc constructs a type-specific unmarshaler that specifies
+// how to unmarshal values of type T. The function fn must be of the form
+// func(*jsontext.Decoder, T) error for some type T; UnmarshalFromFunc
+// panics otherwise. The function must unmarshal exactly one JSON value by
+// calling read methods on the Decoder, such as UnmarshalDecode.
+// The value of T must not be retained outside the function call.
+func UnmarshalFromFunc(fn interface{}) *Unmarshalers {
+	v, t := funcType("UnmarshalFromFunc", fn, []reflect.Type{decoderType, nil}, []reflect.Type{errorType})
+	return &Unmarshalers{fncs: []typedUnmarshaler{{
+		typ: t,
+		fnc: func(dec *jsontext.Decoder, va reflect.Value) error {
+			prevDepth, prevOffset := dec.StackDepth(), dec.InputOffset()
+			out := v.Call([]reflect.Value{reflect.ValueOf(dec), va})
+			err, _ := out[0].Interface().(error)
+			read := dec.InputOffset() != prevOffset || dec.StackDepth() != prevDepth
+			switch {
+			case err == SkipFunc && read:
+				return errors.New("json: UnmarshalFromFunc function returned SkipFunc after reading input")
+			case err != nil:
+				return err
+			case !read || dec.StackDepth() != prevDepth:
+				return errors.New("json: UnmarshalFromFunc function must read exactly one JSON value")
+			}
+			return nil
+		},
+	}}}
+}
+
+// unmarshal calls the first applicable function for va.
+// It reports whether any function handled the value.
+func (u *Unmarshalers) unmarshal(dec *jsontext.Decoder, va reflect.Value, o *arshalOptions) (bool, error) {
+	if va.Kind() == reflect.Ptr && va.IsNil() {
+		// Let the pointer be allocated first, so that a function for
+		// the pointer type applies to the value it points to.
+		return false, nil
+	}
+	for _, fn := range u.fncs {
+		arg, ok := applies(fn.typ, va)
+		if !ok {
+			continue
+		}
+		off := dec.InputOffset()
+		err := fn.fnc(dec, arg)
+		if err == SkipFunc {
+			continue
+		}
+		o.format = ""
+		if err != nil {
+			return true, newUnmarshalError(off, 0, va.Type(), err)
+		}
+		return true, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,419 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package json
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type (
+	structAll struct {
+		Bool    bool
+		String  string
+		Int     int64
+		Uint    uint8
+		Float   float32
+		Map     map[string]int
+		Slice   []string
+		Array   [2]int
+		Pointer *structAll
+		Iface   interface{}
+	}
+	structTags struct {
+		Renamed    string `json:"renamed"`
+		OmitZero   int    `json:",omitzero"`
+		OmitEmpty  []int  `json:",omitempty"`
+		Stringify  int    `json:",string"`
+		Ignored    int    `json:"-"`
+		unexported int
+	}
+	structEmbedded struct {
+		Inner
+		*InnerPtr
+		Outer string
+	}
+	Inner struct {
+		A, Shadowed string
+	}
+	InnerPtr struct {
+		B string
+	}
+	structShadow struct {
+		Inner
+		Shadowed int
+	}
+	structUnknownMap struct {
+		A       int
+		Unknown map[string]interface{} `json:",unknown"`
+	}
+	structUnknownRaw struct {
+		A       int
+		Unknown jsontext.RawValue `json:",unknown"`
+	}
+	structCase struct {
+		Strict  int `json:"strict,case:strict"`
+		Ignore  int `json:"ignore,case:ignore"`
+		Default int `json:"default"`
+	}
+	structFormats struct {
+		Bytes     []byte        `json:",format:hex"`
+		ByteArray [2]byte       `json:",format:array"`
+		Time      time.Time     `json:",format:unixmilli"`
+		Layout    time.Time     `json:",format:'2006,01,02'"`
+		Duration  time.Duration `json:",format:units"`
+		Nanos     time.Duration
+	}
+	zeroer       struct{ N int }
+	structZeroer struct {
+		Z zeroer `json:",omitzero"`
+	}
+	textKey struct{ s string }
+)
+
+func (z zeroer) IsZero() bool { return z.N <= 0 }
+
+func (k textKey) MarshalText() ([]byte, error) { return []byte("<" + k.s + ">"), nil }
+
+func (k *textKey) UnmarshalText(b []byte) error {
+	k.s = strings.Trim(string(b), "<>")
+	return nil
+}
+
+func TestRoundtrip(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Options
+		in   interface{}
+		want string
+	}{
+		{name: "Nil", in: nil, want: `null`},
+		{name: "Bool", in: true, want: `true`},
+		{name: "String", in: "hello <>", want: "\"hello <>\""},
+		{name: "Int", in: int64(math.MinInt64), want: `-9223372036854775808`},
+		{name: "Uint", in: uint64(math.MaxUint64), want: `18446744073709551615`},
+		{name: "Float32", in: float32(0.1), want: `0.1`},
+		{name: "Float64", in: 1e21, want: `1e+21`},
+		{name: "Bytes", in: []byte("hello"), want: `"aGVsbG8="`},
+		{name: "NilBytes", in: []byte(nil), want: `""`},
+		{name: "NilSlice", in: []int(nil), want: `[]`},
+		{name: "NilSliceAsNull", opts: []Options{FormatNilSliceAsNull(true)}, in: []int(nil), want: `null`},
+		{name: "NilMap", in: map[string]int(nil), want: `{}`},
+		{name: "NilMapAsNull", opts: []Options{FormatNilMapAsNull(true)}, in: map[string]int(nil), want: `null`},
+		{name: "Map", opts: []Options{Deterministic(true)}, in: map[string]int{"b": 2, "a": 1, "c": 3}, want: `{"a":1,"b":2,"c":3}`},
+		{name: "MapIntKeys", opts: []Options{Deterministic(true)}, in: map[int]bool{-1: true, 10: false}, want: `{"-1":true,"10":false}`},
+		{name: "MapTextKeys", in: map[textKey]int{{"k"}: 1}, want: `{"<k>":1}`},
+		{name: "Array", in: [3]int{1, 2, 3}, want: `[1,2,3]`},
+		{name: "Pointer", in: new(int), want: `0`},
+		{name: "NilPointer", in: (*int)(nil), want: `null`},
+		{
+			name: "Struct",
+			in: structAll{
+				Bool: true, String: "s", Int: -1, Uint: 1, Float: 1.5,
+				Map: map[string]int{"k": 1}, Slice: []string{"a"}, Array: [2]int{1, 2},
+				Pointer: &structAll{}, Iface: "i",
+			},
+			want: `{"Bool":true,"String":"s","Int":-1,"Uint":1,"Float":1.5,"Map":{"k":1},"Slice":["a"],"Array":[1,2],"Pointer":{"Bool":false,"String":"","Int":0,"Uint":0,"Float":0,"Map":{},"Slice":[],"Array":[0,0],"Pointer":null,"Iface":null},"Iface":"i"}`,
+		},
+		{name: "Tags", in: structTags{Renamed: "r", Stringify: 5}, want: `{"renamed":"r","Stringify":"5"}`},
+		{name: "TagsNonEmpty", in: structTags{OmitZero: 1, OmitEmpty: []int{1}}, want: `{"renamed":"","OmitZero":1,"OmitEmpty":[1],"Stringify":"0"}`},
+		{name: "Embedded", in: structEmbedded{Inner: Inner{A: "a"}, InnerPtr: &InnerPtr{B: "b"}, Outer: "o"}, want: `{"A":"a","Shadowed":"","B":"b","Outer":"o"}`},
+		{name: "EmbeddedNilPointer", in: structEmbedded{Outer: "o"}, want: `{"A":"","Shadowed":"","Outer":"o"}`},
+		{name: "Shadowed", in: structShadow{Inner: Inner{A: "a"}, Shadowed: 1}, want: `{"A":"a","Shadowed":1}`},
+		{name: "UnknownMap", in: structUnknownMap{A: 1, Unknown: map[string]interface{}{"B": "b"}}, want: `{"A":1,"B":"b"}`},
+		{name: "UnknownRaw", in: structUnknownRaw{A: 1, Unknown: jsontext.RawValue(`{"B":"b","C":[1]}`)}, want: `{"A":1,"B":"b","C":[1]}`},
+		{name: "IsZero", in: structZeroer{Z: zeroer{-1}}, want: `{}`},
+		{
+			name: "Formats",
+			in: structFormats{
+				Bytes:     []byte{0xde, 0xad},
+				ByteArray: [2]byte{1, 2},
+				Time:      time.Unix(1, 5e6).UTC(),
+				Layout:    time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+				Duration:  90 * time.Second,
+				Nanos:     time.Microsecond,
+			},
+			want: `{"Bytes":"dead","ByteArray":[1,2],"Time":1005,"Layout":"2020,01,02","Duration":"1m30s","Nanos":1000}`,
+		},
+		{name: "RawValue", in: jsontext.RawValue(` { "a" : 1 } `), want: `{"a":1}`},
+		{name: "TextMarshaler", in: net.IPv4(1, 2, 3, 4), want: `"1.2.3.4"`},
+		{name: "Time", in: time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC), want: `"2020-01-02T03:04:05.000000006Z"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Marshal(tt.in, tt.opts...)
+			if err != nil {
+				t.Fatalf("Marshal error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Fatalf("Marshal:\ngot  %s\nwant %s", got, tt.want)
+			}
+			if tt.in == nil {
+				return
+			}
+			out := reflect.New(reflect.TypeOf(tt.in))
+			if err := Unmarshal(got, out.Interface(), tt.opts...); err != nil {
+				t.Fatalf("Unmarshal error: %v", err)
+			}
+			if got2, err := Marshal(out.Elem().Interface(), tt.opts...); err != nil || string(got2) != tt.want {
+				t.Errorf("Marshal after Unmarshal = %s, %v; want %s", got2, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarshalIndent(t *testing.T) {
+	got, err := Marshal(structUnknownMap{A: 1}, jsontext.WithIndent("  "))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\n  \"A\": 1\n}"; string(got) != want {
+		t.Errorf("Marshal = %q, want %q", got, want)
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Options
+		in   string
+		out  interface{}
+		want interface{}
+	}{
+		{name: "Any", in: `{"a":[null,true,1.5,"s",{}]}`, out: new(interface{}), want: addr(interface{}(map[string]interface{}{"a": []interface{}{nil, true, 1.5, "s", map[string]interface{}{}}}))},
+		{name: "NullResets", in: `null`, out: &structAll{Int: 1}, want: &structAll{}},
+		{name: "MapMerge", in: `{"b":2}`, out: &map[string]int{"a": 1}, want: &map[string]int{"a": 1, "b": 2}},
+		{name: "CaseSensitive", in: `{"STRICT":1,"IGNORE":2,"DEFAULT":3}`, out: new(structCase), want: &structCase{Ignore: 2}},
+		{name: "CaseInsensitive", opts: []Options{MatchCaseInsensitiveNames(true)}, in: `{"STRICT":1,"IGNORE":2,"DEFAULT":3}`, out: new(structCase), want: &structCase{Ignore: 2, Default: 3}},
+		{name: "ExactPreferred", opts: []Options{MatchCaseInsensitiveNames(true)}, in: `{"strict":1}`, out: new(structCase), want: &structCase{Strict: 1}},
+		{name: "UnknownSkipped", in: `{"A":"1","B":{"C":[]}}`, out: new(Inner), want: &Inner{A: "1"}},
+		{name: "UnknownMap", in: `{"A":1,"B":"b","C":null}`, out: new(structUnknownMap), want: &structUnknownMap{A: 1, Unknown: map[string]interface{}{"B": "b", "C": nil}}},
+		{name: "UnknownRaw", in: `{"B":"b","A":1,"C":[ 1 ]}`, out: new(structUnknownRaw), want: &structUnknownRaw{A: 1, Unknown: jsontext.RawValue(`{"B":"b","C":[ 1 ]}`)}},
+		{name: "EmbeddedPointerAllocated", in: `{"B":"b"}`, out: new(structEmbedded), want: &structEmbedded{InnerPtr: &InnerPtr{B: "b"}}},
+		{name: "StringifiedNumber", in: `{"Stringify":"12"}`, out: new(structTags), want: &structTags{Stringify: 12}},
+		{name: "TextKeys", in: `{"<k>":1}`, out: new(map[textKey]int), want: &map[textKey]int{{"k"}: 1}},
+		{name: "Unix", in: `{"Time":-1500}`, out: new(structFormats), want: &structFormats{Time: time.Unix(-2, 5e8).UTC()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Unmarshal([]byte(tt.in), tt.out, tt.opts...); err != nil {
+				t.Fatalf("Unmarshal error: %v", err)
+			}
+			if !reflect.DeepEqual(tt.out, tt.want) {
+				t.Errorf("Unmarshal:\ngot  %#v\nwant %#v", tt.out, tt.want)
+			}
+		})
+	}
+}
+
+func addr(v interface{}) *interface{} { return &v }
+
+func TestUnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Options
+		in      string
+		out     interface{}
+		wantErr string
+	}{
+		{name: "NonPointer", in: `1`, out: 0, wantErr: "json: cannot unmarshal into Go int: value must be passed as a non-nil pointer reference"},
+		{name: "Empty", in: ``, out: new(int), wantErr: "jsontext: syntactic error at byte offset 0: unexpected EOF"},
+		{name: "TrailingData", in: `1 2`, out: new(int), wantErr: "jsontext: syntactic error at byte offset 1: unexpected data after top-level value"},
+		{name: "Syntax", in: `{"A":1,}`, out: new(structAll), wantErr: "jsontext: syntactic error at byte offset 7: invalid character '}' at start of value"},
+		{name: "DuplicateName", in: `{"Int":1,"Int":2}`, out: new(structAll), wantErr: "jsontext: syntactic error at byte offset 9: duplicate object member name"},
+		{name: "InvalidUTF8", in: "\"\xff\"", out: new(string), wantErr: "jsontext: syntactic error at byte offset 1: invalid UTF-8 within string"},
+		{name: "KindMismatch", in: `{"Int":"1"}`, out: new(structAll), wantErr: "json: cannot unmarshal JSON string into Go int64 after byte offset 6"},
+		{name: "Overflow", in: `{"Uint":256}`, out: new(structAll), wantErr: `json: cannot unmarshal JSON number into Go uint8 after byte offset 7: strconv.ParseUint: parsing "256": value out of range`},
+		{name: "RejectUnknown", opts: []Options{RejectUnknownMembers(true)}, in: `{"A":1,"B":2}`, out: new(structUnknownMap), wantErr: `json: cannot unmarshal JSON string into Go json.structUnknownMap after byte offset 6: unknown name "B"`},
+		{name: "ArrayTooShort", in: `[1]`, out: new([2]int), wantErr: "json: cannot unmarshal JSON array into Go [2]int: too few array elements"},
+		{name: "ArrayTooLong", in: `[1,2,3]`, out: new([2]int), wantErr: "json: cannot unmarshal JSON array into Go [2]int: too many array elements"},
+		{name: "NonEmptyInterface", in: `1`, out: new(fmt.Stringer), wantErr: "json: cannot unmarshal JSON number into Go fmt.Stringer: cannot derive concrete type for non-empty interface"},
+		{name: "InvalidFormat", in: `{"A":1}`, out: new(struct {
+			A int `json:",format:hex"`
+		}), wantErr: `json: cannot unmarshal into Go int: invalid format flag "hex"`},
+		{name: "InvalidTag", in: `{}`, out: new(struct {
+			A int `json:",bogus"`
+		}), wantErr: `Go struct field A has unknown "bogus" tag option`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Unmarshal([]byte(tt.in), tt.out, tt.opts...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Unmarshal error:\ngot  %v\nwant %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUnmarshalAllowDuplicateNames(t *testing.T) {
+	var got map[string]int
+	if err := Unmarshal([]byte(`{"a":1,"a":2}`), &got, jsontext.AllowDuplicateNames(true)); err != nil {
+		t.Fatal(err)
+	}
+	if got["a"] != 2 {
+		t.Errorf("got %v, want last value to win", got)
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      interface{}
+		wantErr string
+	}{
+		{name: "Chan", in: make(chan int), wantErr: "json: cannot marshal from Go chan int: unsupported type"},
+		{name: "NaN", in: []float64{math.NaN()}, wantErr: "json: cannot marshal from Go float64 after byte offset 1: unsupported value: NaN"},
+		{name: "DuplicateUnknownName", in: structUnknownMap{A: 1, Unknown: map[string]interface{}{"A": 2}}, wantErr: "jsontext: syntactic error at byte offset 7: duplicate object member name"},
+		{name: "BadRawValue", in: jsontext.RawValue(`{`), wantErr: "jsontext: syntactic error at byte offset 1: unexpected EOF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Marshal(tt.in)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Marshal error:\ngot  %v\nwant %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+type methods struct{ s string }
+
+func (m methods) MarshalJSONTo(enc *jsontext.Encoder) error {
+	return enc.WriteToken(jsontext.String("to:" + m.s))
+}
+
+func (m *methods) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return err
+	}
+	m.s = strings.TrimPrefix(tok.String(), "to:")
+	return nil
+}
+
+func TestMethods(t *testing.T) {
+	in := []methods{{"a"}, {"b"}}
+	b, err := Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `["to:a","to:b"]`; string(b) != want {
+		t.Errorf("Marshal = %s, want %s", b, want)
+	}
+	var out []methods
+	if err := Unmarshal(b, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("Unmarshal = %v, want %v", out, in)
+	}
+}
+
+func TestCallerFuncs(t *testing.T) {
+	marshalers := JoinMarshalers(
+		// Skip odd numbers, which fall through to the next function.
+		MarshalToFunc(func(enc *jsontext.Encoder, n int) error {
+			if n%2 != 0 {
+				return SkipFunc
+			}
+			return enc.WriteToken(jsontext.String("even"))
+		}),
+		MarshalFunc(func(n int) ([]byte, error) {
+			if n < 0 {
+				return nil, errors.New("negative")
+			}
+			return []byte(strconv.Quote("odd")), nil
+		}),
+		MarshalFunc(func(s fmt.Stringer) ([]byte, error) {
+			return []byte(strconv.Quote("stringer:" + s.String())), nil
+		}),
+	)
+	b, err := Marshal([]interface{}{1, 2, time.Second, "s"}, WithMarshalers(marshalers))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `["odd","even","stringer:1s","s"]`; string(b) != want {
+		t.Errorf("Marshal = %s, want %s", b, want)
+	}
+	if _, err := Marshal(-1, WithMarshalers(marshalers)); err == nil || !strings.Contains(err.Error(), "negative") {
+		t.Errorf("Marshal error = %v, want negative", err)
+	}
+
+	unmarshalers := JoinUnmarshalers(
+		// Only strings are handled; numbers fall through to the default.
+		UnmarshalFromFunc(func(dec *jsontext.Decoder, n *int) error {
+			if dec.PeekKind() != '"' {
+				return SkipFunc
+			}
+			tok, err := dec.ReadToken()
+			if err != nil {
+				return err
+			}
+			*n = len(tok.String())
+			return nil
+		}),
+		UnmarshalFunc(func(b []byte, s *string) error {
+			u, err := strconv.Unquote(string(b))
+			*s = strings.ToUpper(u)
+			return err
+		}),
+	)
+	var out struct {
+		A, B int
+		C    *int
+		D    string
+	}
+	if err := Unmarshal([]byte(`{"A":"four","B":2,"C":"xy","D":"d"}`), &out, WithUnmarshalers(unmarshalers)); err != nil {
+		t.Fatal(err)
+	}
+	if out.A != 4 || out.B != 2 || out.C == nil || *out.C != 2 || out.D != "D" {
+		t.Errorf("Unmarshal = %+v", out)
+	}
+}
+
+func TestCallerFuncsInvalid(t *testing.T) {
+	for _, fn := range []interface{}{nil, 1, func() {}, func(int) []byte { return nil }} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("MarshalFunc(%T) did not panic", fn)
+				}
+			}()
+			MarshalFunc(fn)
+		}()
+	}
+}
+
+func TestStreaming(t *testing.T) {
+	var buf bytes.Buffer
+	enc := jsontext.NewEncoder(&buf)
+	for i := 0; i < 3; i++ {
+		if err := MarshalEncode(enc, map[string]int{"i": i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dec := jsontext.NewDecoder(&buf)
+	for i := 0; i < 3; i++ {
+		var m map[string]int
+		if err := UnmarshalDecode(dec, &m); err != nil {
+			t.Fatal(err)
+		}
+		if m["i"] != i {
+			t.Errorf("value %d = %v", i, m)
+		}
+	}
+	if _, err := dec.ReadToken(); err == nil {
+		t.Errorf("unexpected trailing data")
+	}
+}
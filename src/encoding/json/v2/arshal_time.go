@@ -0,0 +1,214 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package json
+
+import (
+	"encoding/json/jsontext"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeDurationType = reflect.TypeOf(time.Duration(0))
+	timeTimeType     = reflect.TypeOf(time.Time{})
+)
+
+// timeLayouts maps the names of the layout constants in package time,
+// which may be used as format flags, to the layouts.
+var timeLayouts = map[string]string{
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RubyDate":    time.RubyDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"Stamp":       time.Stamp,
+	"StampMilli":  time.StampMilli,
+	"StampMicro":  time.StampMicro,
+	"StampNano":   time.StampNano,
+}
+
+// unixFormats maps the Unix time format flags to the number of
+// fractional digits of a second that make up a whole unit.
+var unixFormats = map[string]int{
+	"unix":      9,
+	"unixmilli": 6,
+	"unixmicro": 3,
+	"unixnano":  0,
+}
+
+// makeTimeArshaler replaces fncs for time.Time and time.Duration, whose
+// representation is selected by the format flag rather than by methods.
+func makeTimeArshaler(fncs *arshaler, t reflect.Type) *arshaler {
+	switch t {
+	case timeDurationType:
+		return &arshaler{
+			marshal: func(enc *jsontext.Encoder, va reflect.Value, o *arshalOptions) error {
+				d := time.Duration(va.Int())
+				switch format := o.takeFormat(); format {
+				case "", "nanos":
+					if o.stringify {
+						return enc.WriteToken(jsontext.String(strconv.FormatInt(int64(d), 10)))
+					}
+					return enc.WriteToken(jsontext.Int(int64(d)))
+				case "units":
+					return enc.WriteToken(jsontext.String(d.String()))
+				default:
+					return newInvalidFormatError("marshal", t, format)
+				}
+			},
+			unmarshal: func(dec *jsontext.Decoder, va reflect.Value, o *arshalOptions) error {
+				switch format := o.takeFormat(); format {
+				case "", "nanos":
+					return fncs.unmarshal(dec, va, o)
+				case "units":
+					off := dec.InputOffset()
+					tok, err := dec.ReadToken()
+					if err != nil {
+						return err
+					}
+					switch k := tok.Kind(); k {
+					case 'n':
+						va.SetInt(0)
+					case '"':
+						d, err := time.ParseDuration(tok.String())
+						if err != nil {
+							return newUnmarshalError(off, k, t, err)
+						}
+						va.SetInt(int64(d))
+					default:
+						return newUnmarshalError(off, k, t, nil)
+					}
+					return nil
+				default:
+					return newInvalidFormatError("unmarshal", t, format)
+				}
+			},
+		}
+	case timeTimeType:
+		return &arshaler{
+			marshal: func(enc *jsontext.Encoder, va reflect.Value, o *arshalOptions) error {
+				tt := va.Interface().(time.Time)
+				format := o.takeFormat()
+				if digits, ok := unixFormats[format]; ok {
+					return enc.WriteValue(appendUnix(nil, tt, digits))
+				}
+				layout := time.RFC3339Nano
+				if format != "" {
+					layout = format
+					if l, ok := timeLayouts[format]; ok {
+						layout = l
+					}
+				} else if y := tt.Year(); y < 0 || y >= 10000 {
+					return newMarshalError(enc, t, errors.New("year outside of range [0,9999]"))
+				}
+				return enc.WriteToken(jsontext.String(tt.Format(layout)))
+			},
+			unmarshal: func(dec *jsontext.Decoder, va reflect.Value, o *arshalOptions) error {
+				format := o.takeFormat()
+				off := dec.InputOffset()
+				tok, err := dec.ReadToken()
+				if err != nil {
+					return err
+				}
+				k := tok.Kind()
+				if k == 'n' {
+					va.Set(reflect.Zero(t))
+					return nil
+				}
+				var tt time.Time
+				if digits, ok := unixFormats[format]; ok {
+					if k != '0' {
+						return newUnmarshalError(off, k, t, nil)
+					}
+					tt, err = parseUnix(tok.String(), digits)
+				} else {
+					if k != '"' {
+						return newUnmarshalError(off, k, t, nil)
+					}
+					layout := time.RFC3339Nano
+					if format != "" {
+						layout = format
+						if l, ok := timeLayouts[format]; ok {
+							layout = l
+						}
+					}
+					tt, err = time.Parse(layout, tok.String())
+				}
+				if err != nil {
+					return newUnmarshalError(off, k, t, err)
+				}
+				va.Set(reflect.ValueOf(tt))
+				return nil
+			},
+		}
+	}
+	return fncs
+}
+
+// appendUnix appends the JSON number for the time since the Unix epoch
+// in units of 10^-(9-digits) seconds, with up to digits fractional digits.
+func appendUnix(b []byte, t time.Time, digits int) []byte {
+	sec, nsec := t.Unix(), int64(t.Nanosecond())
+	if sec < 0 {
+		b = append(b, '-')
+		if nsec > 0 {
+			sec, nsec = sec+1, 1e9-nsec
+		}
+		sec = -sec
+	}
+	unit := pow10(digits) // nanoseconds per unit
+	b = strconv.AppendInt(b, sec*(1e9/unit)+nsec/unit, 10)
+	if frac := nsec % unit; frac > 0 {
+		digits := strconv.FormatInt(unit+frac, 10)[1:] // zero-padded
+		b = append(append(b, '.'), strings.TrimRight(digits, "0")...)
+	}
+	return b
+}
+
+// parseUnix parses a JSON number produced by appendUnix.
+func parseUnix(s string, digits int) (time.Time, error) {
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+	}
+	n, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || strings.ContainsAny(frac, "eE") || len(frac) > digits {
+		return time.Time{}, fmt.Errorf("invalid Unix time %q", s)
+	}
+	var f int64
+	if frac != "" {
+		f, err = strconv.ParseInt(frac, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid Unix time %q", s)
+		}
+		f *= pow10(digits - len(frac))
+	}
+	perSec := 1e9 / pow10(digits) // units per second
+	sec, nsec := n/perSec, (n%perSec)*pow10(digits)+f
+	if neg {
+		sec, nsec = -sec, -nsec
+	}
+	return time.Unix(sec, nsec).UTC(), nil
+}
+
+func pow10(n int) int64 {
+	p := int64(1)
+	for ; n > 0; n-- {
+		p *= 10
+	}
+	return p
+}
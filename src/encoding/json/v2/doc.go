@@ -0,0 +1,20 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package json implements semantic processing of JSON as specified in
+// RFC 8259, converting between JSON and Go values. It is built on the
+// syntactic layer implemented by package encoding/json/jsontext.
+//
+// Compared to package encoding/json, which remains unchanged, this
+// package matches JSON object names to Go struct fields case-sensitively,
+// rejects duplicate JSON object names and invalid UTF-8, and reports
+// errors with the byte offset at which they occurred. The behavior of
+// both the syntactic and the semantic layer is configured with Options
+// passed to each call, rather than with methods on a stateful object.
+//
+// Marshaling and unmarshaling of particular types may be customized with
+// methods (see MarshalerTo and UnmarshalerFrom), with struct tag options
+// (see Marshal), or with caller-supplied functions (see WithMarshalers
+// and WithUnmarshalers).
+package json
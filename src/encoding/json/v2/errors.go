@@ -0,0 +1,112 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package json
+
+import (
+	"encoding/json/jsontext"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var errUnsupportedType = errors.New("unsupported type")
+
+// SemanticError describes an error determining the meaning
+// of JSON data as Go data or vice-versa.
+//
+// The contents of this error as produced by this package may change over time.
+type SemanticError struct {
+	action string // either "marshal" or "unmarshal"
+
+	// ByteOffset indicates that an error occurred after this byte offset.
+	ByteOffset int64
+
+	// JSONKind is the JSON kind that could not be handled.
+	JSONKind jsontext.Kind // may be zero if unknown
+
+	// GoType is the Go type that could not be handled.
+	GoType reflect.Type // may be nil if unknown
+
+	// Err is the underlying error.
+	Err error // may be nil
+}
+
+func (e *SemanticError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("json: cannot")
+	switch e.action {
+	case "marshal":
+		sb.WriteString(" marshal")
+		if e.GoType != nil {
+			sb.WriteString(" from Go " + e.GoType.String())
+		}
+		if e.JSONKind != 0 {
+			sb.WriteString(" into JSON " + kindName(e.JSONKind))
+		}
+	case "unmarshal":
+		sb.WriteString(" unmarshal")
+		if e.JSONKind != 0 {
+			sb.WriteString(" JSON " + kindName(e.JSONKind))
+		}
+		if e.GoType != nil {
+			sb.WriteString(" into Go " + e.GoType.String())
+		}
+	default:
+		sb.WriteString(" handle")
+		if e.JSONKind != 0 {
+			sb.WriteString(" JSON " + kindName(e.JSONKind))
+		}
+		if e.GoType != nil {
+			sb.WriteString(" with Go " + e.GoType.String())
+		}
+	}
+	if e.ByteOffset > 0 {
+		sb.WriteString(" after byte offset " + strconv.FormatInt(e.ByteOffset, 10))
+	}
+	if e.Err != nil {
+		sb.WriteString(": " + e.Err.Error())
+	}
+	return sb.String()
+}
+
+func (e *SemanticError) Unwrap() error {
+	return e.Err
+}
+
+// kindName returns a descriptive name for a JSON kind.
+func kindName(k jsontext.Kind) string {
+	switch k {
+	case '{', '}':
+		return "object"
+	case '[', ']':
+		return "array"
+	}
+	return k.String()
+}
+
+// isArshalError reports whether err already describes a failure in terms
+// of the JSON input or output and should be returned as is.
+func isArshalError(err error) bool {
+	switch err.(type) {
+	case *SemanticError, *jsontext.SyntacticError:
+		return true
+	}
+	return false
+}
+
+func newMarshalError(enc *jsontext.Encoder, t reflect.Type, err error) error {
+	if isArshalError(err) {
+		return err
+	}
+	return &SemanticError{action: "marshal", ByteOffset: enc.OutputOffset(), GoType: t, Err: err}
+}
+
+func newUnmarshalError(off int64, k jsontext.Kind, t reflect.Type, err error) error {
+	if isArshalError(err) {
+		return err
+	}
+	return &SemanticError{action: "unmarshal", ByteOffset: off, JSONKind: k, GoType: t, Err: err}
+}
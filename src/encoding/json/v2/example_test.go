@@ -0,0 +1,77 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package json_test
+
+import (
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"fmt"
+	"log"
+	"time"
+)
+
+// The `unknown` option preserves members that match no struct field,
+// and the `format` option selects the representation of a field.
+func Example_fieldOptions() {
+	type Event struct {
+		Name    string            `json:"name"`
+		When    time.Time         `json:"when,format:unix"`
+		Payload []byte            `json:"payload,format:hex"`
+		Extra   jsontext.RawValue `json:",unknown"`
+	}
+
+	in := `{"name":"deploy","when":1577836800,"payload":"cafe","region":"eu"}`
+	var e Event
+	if err := json.Unmarshal([]byte(in), &e); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(e.Name, e.When.Format(time.RFC3339), e.Payload, string(e.Extra))
+
+	out, err := json.Marshal(e, jsontext.WithIndent("  "))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(string(out))
+
+	// Output:
+	// deploy 2020-01-01T00:00:00Z [202 254] {"region":"eu"}
+	// {
+	//   "name": "deploy",
+	//   "when": 1577836800,
+	//   "payload": "cafe",
+	//   "region": "eu"
+	// }
+}
+
+// Caller-supplied functions override the representation of a type
+// without needing to declare methods on it.
+func ExampleWithMarshalers() {
+	marshalers := json.MarshalFunc(func(d time.Duration) ([]byte, error) {
+		return []byte(fmt.Sprintf("%q", d.String())), nil
+	})
+	out, err := json.Marshal(map[string]time.Duration{"timeout": 90 * time.Second},
+		json.WithMarshalers(marshalers))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(string(out))
+
+	// Output:
+	// {"timeout":"1m30s"}
+}
+
+// Unlike package encoding/json, names are matched case-sensitively and
+// duplicate names are rejected by default.
+func ExampleUnmarshal_strict() {
+	var v struct{ Name string }
+	fmt.Println(json.Unmarshal([]byte(`{"name":"gopher"}`), &v), v.Name == "")
+	fmt.Println(json.Unmarshal([]byte(`{"name":"gopher"}`), &v, json.MatchCaseInsensitiveNames(true)), v.Name)
+	fmt.Println(json.Unmarshal([]byte(`{"Name":"a","Name":"b"}`), &v))
+
+	// Output:
+	// <nil> true
+	// <nil> gopher
+	// jsontext: syntactic error at byte offset 12: duplicate object member name
+}
@@ -0,0 +1,532 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package json
+
+import (
+	"bytes"
+	"encoding/json/internal/jsonopts"
+	"encoding/json/jsontext"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const (
+	caseIgnore = 1 // `case:ignore`
+	caseStrict = 2 // `case:strict`
+)
+
+// structField is a Go struct field, possibly promoted from an embedded
+// struct, that is represented as a JSON object member.
+type structField struct {
+	index []int // field index sequence for reflect.Value.FieldByIndex
+	typ   reflect.Type
+
+	name    string
+	hasName bool // name was explicitly specified in the tag
+
+	omitzero  bool
+	omitempty bool
+	stringify bool
+	casing    int
+	inline    bool
+	unknown   bool
+	format    string
+}
+
+// structFields is the list of JSON members of a Go struct type.
+type structFields struct {
+	flattened       []structField  // sorted by index sequence
+	byName          map[string]int // index into flattened
+	byFoldedName    map[string][]int
+	inlinedFallback *structField // the `unknown` field, if any
+}
+
+// foldName returns the key used for case-insensitive name matching.
+func foldName(name string) string {
+	return strings.ToLower(name)
+}
+
+// lookup returns the field matching the JSON object name,
+// or nil if there is none.
+func (fs *structFields) lookup(name string, matchFold bool) *structField {
+	if i, ok := fs.byName[name]; ok {
+		return &fs.flattened[i]
+	}
+	for _, i := range fs.byFoldedName[foldName(name)] {
+		f := &fs.flattened[i]
+		if f.casing == caseIgnore || (matchFold && f.casing != caseStrict) {
+			return f
+		}
+	}
+	return nil
+}
+
+// parseFieldOptions parses the "json" tag of sf.
+// It reports ignored if the field is to be ignored.
+func parseFieldOptions(sf reflect.StructField) (f structField, ignored bool, err error) {
+	tag := sf.Tag.Get("json")
+	if tag == "-" {
+		return f, true, nil
+	}
+	name, opts := tag, ""
+	if i := strings.IndexByte(tag, ','); i >= 0 {
+		name, opts = tag[:i], tag[i+1:]
+	}
+	if name != "" {
+		if !utf8.ValidString(name) {
+			return f, false, fmt.Errorf("Go struct field %s has JSON object name %q with invalid UTF-8", sf.Name, name)
+		}
+		f.name = name
+		f.hasName = true
+	}
+	for opts != "" {
+		opt := opts
+		if strings.HasPrefix(opts, "format:'") {
+			// A quoted format may contain commas.
+			i := strings.IndexByte(opts[len("format:'"):], '\'')
+			if i < 0 {
+				return f, false, fmt.Errorf("Go struct field %s has unterminated quoted format", sf.Name)
+			}
+			end := len("format:'") + i + 1
+			opt, opts = opts[:end], opts[end:]
+			if opts != "" && opts[0] != ',' {
+				return f, false, fmt.Errorf("Go struct field %s has malformed format after closing quote", sf.Name)
+			}
+			opts = strings.TrimPrefix(opts, ",")
+		} else if i := strings.IndexByte(opts, ','); i >= 0 {
+			opt, opts = opts[:i], opts[i+1:]
+		} else {
+			opts = ""
+		}
+		switch {
+		case opt == "omitzero":
+			f.omitzero = true
+		case opt == "omitempty":
+			f.omitempty = true
+		case opt == "string":
+			f.stringify = true
+		case opt == "case:ignore":
+			f.casing = caseIgnore
+		case opt == "case:strict":
+			f.casing = caseStrict
+		case opt == "inline":
+			f.inline = true
+		case opt == "unknown":
+			f.unknown = true
+		case strings.HasPrefix(opt, "format:"):
+			f.format = strings.TrimPrefix(opt, "format:")
+			if strings.HasPrefix(f.format, "'") {
+				f.format = f.format[1 : len(f.format)-1]
+			}
+		default:
+			return f, false, fmt.Errorf("Go struct field %s has unknown %q tag option", sf.Name, opt)
+		}
+	}
+	return f, false, nil
+}
+
+// isFallbackType reports whether t can hold the unknown members of
+// a JSON object.
+func isFallbackType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr && t.Name() == "" {
+		t = t.Elem()
+	}
+	return t == rawValueType || (t.Kind() == reflect.Map && t.Key().Kind() == reflect.String)
+}
+
+// makeStructFields computes the JSON members of the struct type root.
+func makeStructFields(root reflect.Type) (structFields, error) {
+	type queueEntry struct {
+		typ   reflect.Type
+		index []int
+	}
+	var all, fallbacks []structField
+	queue := []queueEntry{{root, nil}}
+	visited := map[reflect.Type]bool{root: true}
+	for len(queue) > 0 {
+		e := queue[0]
+		queue = queue[1:]
+		for i := 0; i < e.typ.NumField(); i++ {
+			sf := e.typ.Field(i)
+			f, ignored, err := parseFieldOptions(sf)
+			if err != nil {
+				return structFields{}, err
+			}
+			if ignored {
+				continue
+			}
+			f.index = append(append([]int(nil), e.index...), i)
+			f.typ = sf.Type
+			exported := sf.PkgPath == ""
+			if sf.Anonymous && !f.hasName {
+				f.inline = true
+			}
+
+			if f.inline || f.unknown {
+				t := sf.Type
+				if t.Kind() == reflect.Ptr && t.Name() == "" {
+					t = t.Elem()
+				}
+				switch {
+				case isFallbackType(sf.Type):
+					if !exported {
+						continue
+					}
+					fallbacks = append(fallbacks, f)
+					continue
+				case f.unknown:
+					return structFields{}, fmt.Errorf("Go struct field %s with `unknown` option must be a map[string]T or jsontext.RawValue", sf.Name)
+				case t.Kind() == reflect.Struct:
+					if !exported && (!sf.Anonymous || sf.Type.Kind() == reflect.Ptr) {
+						// Unexported pointers cannot be allocated when
+						// unmarshaling, so they are ignored.
+						continue
+					}
+					if !visited[t] {
+						visited[t] = true
+						queue = append(queue, queueEntry{t, f.index})
+					}
+					continue
+				case sf.Anonymous && !f.hasName:
+					// An embedded non-struct type is an ordinary field
+					// named after its type.
+					f.inline = false
+				default:
+					return structFields{}, fmt.Errorf("Go struct field %s with `inline` option must be a struct, map[string]T, or jsontext.RawValue", sf.Name)
+				}
+			}
+			if !exported {
+				continue
+			}
+			if !f.hasName {
+				f.name = sf.Name
+			}
+			all = append(all, f)
+		}
+	}
+
+	// Resolve fields with the same name using the same dominance rules
+	// as Go's embedding, as package encoding/json does: the shallowest
+	// field wins, and a tie between fields at that depth is broken by the
+	// presence of an explicit name in the tag, or else none are used.
+	sort.SliceStable(all, func(i, j int) bool {
+		fi, fj := &all[i], &all[j]
+		switch {
+		case fi.name != fj.name:
+			return fi.name < fj.name
+		case len(fi.index) != len(fj.index):
+			return len(fi.index) < len(fj.index)
+		case fi.hasName != fj.hasName:
+			return fi.hasName
+		}
+		return indexLess(fi.index, fj.index)
+	})
+	var fs structFields
+	for i := 0; i < len(all); {
+		j := i + 1
+		for j < len(all) && all[j].name == all[i].name {
+			j++
+		}
+		if j-i == 1 || len(all[i].index) < len(all[i+1].index) || (all[i].hasName && !all[i+1].hasName) {
+			fs.flattened = append(fs.flattened, all[i])
+		}
+		i = j
+	}
+	sort.Slice(fs.flattened, func(i, j int) bool {
+		return indexLess(fs.flattened[i].index, fs.flattened[j].index)
+	})
+
+	fs.byName = make(map[string]int, len(fs.flattened))
+	fs.byFoldedName = make(map[string][]int, len(fs.flattened))
+	for i, f := range fs.flattened {
+		fs.byName[f.name] = i
+		k := foldName(f.name)
+		fs.byFoldedName[k] = append(fs.byFoldedName[k], i)
+	}
+
+	switch len(fallbacks) {
+	case 0:
+	case 1:
+		fs.inlinedFallback = &fallbacks[0]
+	default:
+		return structFields{}, errors.New("multiple inlined fields to hold unknown members")
+	}
+	return fs, nil
+}
+
+func indexLess(x, y []int) bool {
+	for i := 0; i < len(x) && i < len(y); i++ {
+		if x[i] != y[i] {
+			return x[i] < y[i]
+		}
+	}
+	return len(x) < len(y)
+}
+
+// fieldByIndex returns the field of the struct va at the index sequence,
+// following embedded pointers. If alloc is false, it returns the zero
+// Value when an embedded pointer is nil; otherwise it allocates it.
+func fieldByIndex(va reflect.Value, index []int, alloc bool) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && va.Kind() == reflect.Ptr {
+			if va.IsNil() {
+				if !alloc {
+					return reflect.Value{}, nil
+				}
+				if !va.CanSet() {
+					return reflect.Value{}, fmt.Errorf("cannot set embedded pointer to unexported struct type %v", va.Type().Elem())
+				}
+				va.Set(reflect.New(va.Type().Elem()))
+			}
+			va = va.Elem()
+		}
+		va = va.Field(x)
+	}
+	return va, nil
+}
+
+type isZeroer interface {
+	IsZero() bool
+}
+
+var isZeroerType = reflect.TypeOf((*isZeroer)(nil)).Elem()
+
+// isZero reports whether the field value va is zero for `omitzero`.
+func isZero(va reflect.Value) bool {
+	switch {
+	case (va.Kind() == reflect.Ptr || va.Kind() == reflect.Interface) && va.IsNil():
+		return true
+	case va.Type().Implements(isZeroerType):
+		return va.Interface().(isZeroer).IsZero()
+	case va.CanAddr() && reflect.PtrTo(va.Type()).Implements(isZeroerType):
+		return va.Addr().Interface().(isZeroer).IsZero()
+	}
+	return va.IsZero()
+}
+
+// isEmpty reports whether the field value va is empty for `omitempty`.
+func isEmpty(va reflect.Value) bool {
+	switch va.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return va.IsNil()
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return va.Len() == 0
+	}
+	return false
+}
+
+func makeStructArshaler(t reflect.Type) *arshaler {
+	var (
+		once    sync.Once
+		fields  structFields
+		errInit error
+	)
+	init := func() { fields, errInit = makeStructFields(t) }
+	return &arshaler{
+		marshal: func(enc *jsontext.Encoder, va reflect.Value, o *arshalOptions) error {
+			if format := o.takeFormat(); format != "" {
+				return newInvalidFormatError("marshal", t, format)
+			}
+			once.Do(init)
+			if errInit != nil {
+				return newMarshalError(enc, t, errInit)
+			}
+			if err := enc.WriteToken(jsontext.BeginObject); err != nil {
+				return err
+			}
+			stringify := o.stringify
+			for i := range fields.flattened {
+				f := &fields.flattened[i]
+				v, _ := fieldByIndex(va, f.index, false)
+				if !v.IsValid() || (f.omitzero && isZero(v)) || (f.omitempty && isEmpty(v)) {
+					continue
+				}
+				if err := enc.WriteToken(jsontext.String(f.name)); err != nil {
+					return err
+				}
+				o.format, o.stringify = f.format, stringify || f.stringify
+				err := marshalValue(enc, v, o)
+				o.format, o.stringify = "", stringify
+				if err != nil {
+					return err
+				}
+			}
+			if f := fields.inlinedFallback; f != nil {
+				v, _ := fieldByIndex(va, f.index, false)
+				if v.IsValid() {
+					if err := marshalFallback(enc, v, o); err != nil {
+						return err
+					}
+				}
+			}
+			return enc.WriteToken(jsontext.EndObject)
+		},
+		unmarshal: func(dec *jsontext.Decoder, va reflect.Value, o *arshalOptions) error {
+			if format := o.takeFormat(); format != "" {
+				return newInvalidFormatError("unmarshal", t, format)
+			}
+			off := dec.InputOffset()
+			tok, err := dec.ReadToken()
+			if err != nil {
+				return err
+			}
+			switch k := tok.Kind(); k {
+			case 'n':
+				va.Set(reflect.Zero(t))
+				return nil
+			case '{':
+			default:
+				return newUnmarshalError(off, k, t, nil)
+			}
+			once.Do(init)
+			if errInit != nil {
+				return newUnmarshalError(off, '{', t, errInit)
+			}
+			matchFold := o.opts.Get(jsonopts.MatchCaseInsensitiveNames)
+			rejectUnknown := o.opts.Get(jsonopts.RejectUnknownMembers)
+			stringify := o.stringify
+			for dec.PeekKind() != '}' {
+				off := dec.InputOffset()
+				tok, err := dec.ReadToken()
+				if err != nil {
+					return err
+				}
+				name := tok.String()
+				f := fields.lookup(name, matchFold)
+				if f == nil {
+					switch {
+					case rejectUnknown:
+						return newUnmarshalError(off, '"', t, fmt.Errorf("unknown name %q", name))
+					case fields.inlinedFallback != nil:
+						v, err := fieldByIndex(va, fields.inlinedFallback.index, true)
+						if err != nil {
+							return newUnmarshalError(off, '"', t, err)
+						}
+						if err := unmarshalFallback(dec, v, name, o); err != nil {
+							return err
+						}
+					default:
+						if err := dec.SkipValue(); err != nil {
+							return err
+						}
+					}
+					continue
+				}
+				v, err := fieldByIndex(va, f.index, true)
+				if err != nil {
+					return newUnmarshalError(off, '"', t, err)
+				}
+				o.format, o.stringify = f.format, stringify || f.stringify
+				err = unmarshalValue(dec, v, o)
+				o.format, o.stringify = "", stringify
+				if err != nil {
+					return err
+				}
+			}
+			_, err = dec.ReadToken()
+			return err
+		},
+	}
+}
+
+// marshalFallback writes the members held by the inlined fallback field va.
+func marshalFallback(enc *jsontext.Encoder, va reflect.Value, o *arshalOptions) error {
+	if va.Kind() == reflect.Ptr {
+		if va.IsNil() {
+			return nil
+		}
+		va = va.Elem()
+	}
+	if va.Kind() == reflect.Map {
+		return marshalMapMembers(enc, va, o)
+	}
+
+	raw := va.Interface().(jsontext.RawValue)
+	if len(raw) == 0 {
+		return nil
+	}
+	errNotObject := errors.New("inlined raw value must be a JSON object")
+	dec := jsontext.NewDecoder(bytes.NewReader(raw))
+	tok, err := dec.ReadToken()
+	if err != nil || tok.Kind() != '{' {
+		return newMarshalError(enc, rawValueType, errNotObject)
+	}
+	for dec.PeekKind() != '}' {
+		tok, err := dec.ReadToken()
+		if err != nil {
+			return newMarshalError(enc, rawValueType, err)
+		}
+		if err := enc.WriteToken(tok); err != nil {
+			return err
+		}
+		val, err := dec.ReadValue()
+		if err != nil {
+			return newMarshalError(enc, rawValueType, err)
+		}
+		if err := enc.WriteValue(val); err != nil {
+			return err
+		}
+	}
+	if _, err := dec.ReadToken(); err != nil {
+		return newMarshalError(enc, rawValueType, err)
+	}
+	if _, err := dec.ReadToken(); err != io.EOF {
+		return newMarshalError(enc, rawValueType, errNotObject)
+	}
+	return nil
+}
+
+// unmarshalFallback stores the member with the given name, whose value is
+// read next, into the inlined fallback field va.
+func unmarshalFallback(dec *jsontext.Decoder, va reflect.Value, name string, o *arshalOptions) error {
+	if va.Kind() == reflect.Ptr {
+		if va.IsNil() {
+			va.Set(reflect.New(va.Type().Elem()))
+		}
+		va = va.Elem()
+	}
+	if va.Kind() == reflect.Map {
+		t := va.Type()
+		if va.IsNil() {
+			va.Set(reflect.MakeMap(t))
+		}
+		key := reflect.ValueOf(name).Convert(t.Key())
+		val := reflect.New(t.Elem()).Elem()
+		if v := va.MapIndex(key); v.IsValid() {
+			val.Set(v)
+		}
+		if err := unmarshalValue(dec, val, o); err != nil {
+			return err
+		}
+		va.SetMapIndex(key, val)
+		return nil
+	}
+
+	val, err := dec.ReadValue()
+	if err != nil {
+		return err
+	}
+	raw := bytes.TrimRight(va.Bytes(), " \t\r\n")
+	if len(raw) == 0 {
+		raw = append(raw, '{')
+	} else {
+		raw = bytes.TrimRight(raw[:len(raw)-1], " \t\r\n")
+		if len(raw) > 0 && raw[len(raw)-1] != '{' {
+			raw = append(raw, ',')
+		}
+	}
+	raw, _ = jsontext.AppendQuote(raw, name)
+	raw = append(raw, ':')
+	raw = append(raw, val...)
+	raw = append(raw, '}')
+	va.SetBytes(raw)
+	return nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package json
+
+import (
+	"encoding/json/internal/jsonopts"
+	"encoding/json/jsontext"
+)
+
+// Options configure Marshal, MarshalWrite, MarshalEncode, Unmarshal,
+// UnmarshalRead, and UnmarshalDecode with specific features.
+// Each function takes in a variadic list of options, where properties
+// set in later options override the value of previously set properties.
+//
+// The syntactic options declared by package jsontext, such as
+// jsontext.AllowDuplicateNames or jsontext.WithIndent, may be passed
+// alongside the options declared in this package.
+// Options that do not affect a particular operation are ignored.
+type Options = jsonopts.Options
+
+// MatchCaseInsensitiveNames specifies that JSON object members are matched
+// against Go struct fields using a case-insensitive match of the name.
+// Go struct fields explicitly marked with `case:strict` are always
+// matched case-sensitively and those marked with `case:ignore` are always
+// matched case-insensitively.
+// By default, names are matched case-sensitively.
+//
+// This only affects unmarshaling and is ignored when marshaling.
+func MatchCaseInsensitiveNames(v bool) Options {
+	return jsonopts.Bool{Flag: jsonopts.MatchCaseInsensitiveNames, Value: v}
+}
+
+// RejectUnknownMembers specifies that unknown members should be rejected
+// when unmarshaling a JSON object, regardless of whether there is a field
+// to store unknown members in.
+//
+// This only affects unmarshaling and is ignored when marshaling.
+func RejectUnknownMembers(v bool) Options {
+	return jsonopts.Bool{Flag: jsonopts.RejectUnknownMembers, Value: v}
+}
+
+// FormatNilSliceAsNull specifies that a nil Go slice should marshal as a
+// JSON null instead of the default representation as an empty JSON array.
+// Slice fields explicitly marked with `format:emitempty` still marshal
+// as an empty JSON array.
+//
+// This only affects marshaling and is ignored when unmarshaling.
+func FormatNilSliceAsNull(v bool) Options {
+	return jsonopts.Bool{Flag: jsonopts.FormatNilSliceAsNull, Value: v}
+}
+
+// FormatNilMapAsNull specifies that a nil Go map should marshal as a
+// JSON null instead of the default representation as an empty JSON object.
+// Map fields explicitly marked with `format:emitempty` still marshal
+// as an empty JSON object.
+//
+// This only affects marshaling and is ignored when unmarshaling.
+func FormatNilMapAsNull(v bool) Options {
+	return jsonopts.Bool{Flag: jsonopts.FormatNilMapAsNull, Value: v}
+}
+
+// Deterministic specifies that the same input value will be serialized
+// as the exact same output bytes. Different processes of
+// the same program will serialize equal values to the same bytes,
+// but different versions of the same program are not guaranteed
+// to produce the exact same sequence of bytes.
+// In practice, this sorts the members of marshaled Go maps by name.
+//
+// This only affects marshaling and is ignored when unmarshaling.
+func Deterministic(v bool) Options {
+	return jsonopts.Bool{Flag: jsonopts.Deterministic, Value: v}
+}
+
+// WithMarshalers specifies a list of type-specific marshalers to use,
+// which can be used to override the default marshal behavior for values
+// of particular types.
+//
+// This only affects marshaling and is ignored when unmarshaling.
+func WithMarshalers(v *Marshalers) Options {
+	return jsonopts.Marshalers{V: v}
+}
+
+// WithUnmarshalers specifies a list of type-specific unmarshalers to use,
+// which can be used to override the default unmarshal behavior for values
+// of particular types.
+//
+// This only affects unmarshaling and is ignored when marshaling.
+func WithUnmarshalers(v *Unmarshalers) Options {
+	return jsonopts.Unmarshalers{V: v}
+}
+
+// JoinOptions coalesces the provided list of options into a single Options.
+// Properties set in later options override the value of previously set
+// properties.
+func JoinOptions(srcs ...Options) Options {
+	o := new(jsonopts.Struct)
+	o.Join(srcs...)
+	return o
+}
+
+// Ensure that the jsontext options can be combined with ours.
+var _ Options = jsontext.AllowDuplicateNames(false)
@@ -13,6 +13,7 @@
 //
 //	cmdline   os.Args
 //	memstats  runtime.Memstats
+//	metrics   runtime/metrics
 //
 // The package is sometimes only imported for the side effect of
 // registering its HTTP handler and the above variables. To use it
@@ -29,6 +30,7 @@ import (
 	"net/http"
 	"os"
 	"runtime"
+	"runtime/metrics"
 	"sort"
 	"strconv"
 	"strings"
@@ -360,8 +362,55 @@ func memstats() interface{} {
 	return *stats
 }
 
+// runtimeMetrics returns all metrics supported by runtime/metrics,
+// keyed by metric name. Unlike memstats, it does not stop the world.
+func runtimeMetrics() interface{} {
+	descs := metrics.All()
+	samples := make([]metrics.Sample, len(descs))
+	for i := range samples {
+		samples[i].Name = descs[i].Name
+	}
+	metrics.Read(samples)
+	m := make(map[string]interface{}, len(samples))
+	for _, s := range samples {
+		switch s.Value.Kind() {
+		case metrics.KindUint64:
+			m[s.Name] = s.Value.Uint64()
+		case metrics.KindFloat64:
+			m[s.Name] = s.Value.Float64()
+		case metrics.KindFloat64Histogram:
+			m[s.Name] = histogram(s.Value.Float64Histogram())
+		}
+	}
+	return m
+}
+
+// histogramJSON is the JSON form of a metrics.Float64Histogram.
+// JSON cannot represent infinities, so infinite bucket boundaries
+// are encoded as the strings "-Inf" and "+Inf".
+type histogramJSON struct {
+	Counts  []uint64      `json:"counts"`
+	Buckets []interface{} `json:"buckets"`
+}
+
+func histogram(h *metrics.Float64Histogram) histogramJSON {
+	buckets := make([]interface{}, len(h.Buckets))
+	for i, b := range h.Buckets {
+		switch {
+		case math.IsInf(b, -1):
+			buckets[i] = "-Inf"
+		case math.IsInf(b, 1):
+			buckets[i] = "+Inf"
+		default:
+			buckets[i] = b
+		}
+	}
+	return histogramJSON{Counts: h.Counts, Buckets: buckets}
+}
+
 func init() {
 	http.HandleFunc("/debug/vars", expvarHandler)
 	Publish("cmdline", Func(cmdline))
 	Publish("memstats", Func(memstats))
+	Publish("metrics", Func(runtimeMetrics))
 }
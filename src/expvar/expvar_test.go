@@ -13,6 +13,7 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"runtime"
+	"runtime/metrics"
 	"strconv"
 	"sync"
 	"sync/atomic"
@@ -442,6 +443,32 @@ func TestFunc(t *testing.T) {
 	}
 }
 
+func TestRuntimeMetrics(t *testing.T) {
+	var got map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(Func(runtimeMetrics).String()), &got); err != nil {
+		t.Fatalf("unmarshal metrics: %v", err)
+	}
+	for _, d := range metrics.All() {
+		if _, ok := got[d.Name]; !ok {
+			t.Errorf("metric %q not published", d.Name)
+		}
+	}
+
+	var hist struct {
+		Counts  []uint64
+		Buckets []interface{}
+	}
+	if err := json.Unmarshal(got["/gc/pauses:seconds"], &hist); err != nil {
+		t.Fatalf("unmarshal histogram: %v", err)
+	}
+	if len(hist.Counts) != len(hist.Buckets)-1 {
+		t.Errorf("histogram has %d counts for %d buckets", len(hist.Counts), len(hist.Buckets))
+	}
+	if n := len(hist.Buckets); n < 2 || hist.Buckets[0] != "-Inf" || hist.Buckets[n-1] != "+Inf" {
+		t.Errorf("histogram buckets not bounded by -Inf and +Inf")
+	}
+}
+
 func TestHandler(t *testing.T) {
 	RemoveAll()
 	m := NewMap("map1")
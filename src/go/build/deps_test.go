@@ -47,6 +47,7 @@ var pkgDeps = map[string][]string{
 	"internal/cpu":            {},
 	"internal/bytealg":        {"unsafe", "internal/cpu"},
 	"internal/reflectlite":    {"runtime", "unsafe"},
+	"log/internal":            {},
 
 	"L0": {
 		"errors",
@@ -63,20 +64,28 @@ var pkgDeps = map[string][]string{
 
 	// L1 adds simple functions and strings processing,
 	// but not Unicode tables.
+	"cmp":           {},
+	"iter":          {},
+	"maps":          {"iter"},
 	"math":          {"internal/cpu", "unsafe", "math/bits"},
 	"math/bits":     {"unsafe"},
 	"math/cmplx":    {"math"},
 	"math/rand":     {"L0", "math"},
+	"slices":        {"cmp", "iter", "math/bits", "unsafe"},
 	"strconv":       {"L0", "unicode/utf8", "math", "math/bits"},
 	"unicode/utf16": {},
 	"unicode/utf8":  {},
 
 	"L1": {
 		"L0",
+		"cmp",
+		"iter",
+		"maps",
 		"math",
 		"math/bits",
 		"math/cmplx",
 		"math/rand",
+		"slices",
 		"sort",
 		"strconv",
 		"unicode/utf16",
@@ -86,8 +95,8 @@ var pkgDeps = map[string][]string{
 	// L2 adds Unicode and strings processing.
 	"bufio":   {"L0", "unicode/utf8", "bytes"},
 	"bytes":   {"L0", "unicode", "unicode/utf8"},
-	"path":    {"L0", "unicode/utf8", "strings"},
-	"strings": {"L0", "unicode", "unicode/utf8"},
+	"path":    {"L0", "unicode/utf8"},
+	"strings": {"L0", "iter", "unicode", "unicode/utf8"},
 	"unicode": {},
 
 	"L2": {
@@ -170,15 +179,18 @@ var pkgDeps = map[string][]string{
 	"internal/cfg":     {"L0"},
 	"internal/poll":    {"L0", "internal/oserror", "internal/race", "syscall", "time", "unicode/utf16", "unicode/utf8", "internal/syscall/windows"},
 	"internal/testlog": {"L0"},
-	"os":               {"L1", "os", "syscall", "time", "internal/oserror", "internal/poll", "internal/syscall/windows", "internal/syscall/unix", "internal/testlog"},
-	"path/filepath":    {"L2", "os", "syscall", "internal/syscall/windows"},
+	"io/fs":            {"L0", "internal/oserror", "path", "sort", "time", "unicode/utf8"},
+	"embed":            {"L0", "io/fs", "time"},
+	"os":               {"L1", "os", "io/fs", "syscall", "time", "internal/oserror", "internal/poll", "internal/syscall/windows", "internal/syscall/unix", "internal/testlog"},
+	"path/filepath":    {"L2", "io/fs", "os", "syscall", "internal/syscall/windows"},
 	"io/ioutil":        {"L2", "os", "path/filepath", "time"},
-	"os/exec":          {"L2", "os", "context", "path/filepath", "syscall"},
+	"os/exec":          {"L2", "os", "context", "path/filepath", "syscall", "time"},
 	"os/signal":        {"L2", "os", "syscall"},
 
 	// OS enables basic operating system functionality,
 	// but not direct use of package syscall, nor os/signal.
 	"OS": {
+		"io/fs",
 		"io/ioutil",
 		"os",
 		"os/exec",
@@ -188,17 +200,19 @@ var pkgDeps = map[string][]string{
 
 	// Formatted I/O: few dependencies (L1) but we must add reflect and internal/fmtsort.
 	"fmt": {"L1", "os", "reflect", "internal/fmtsort"},
-	"log": {"L1", "os", "fmt", "time"},
+	"log": {"L1", "os", "fmt", "time", "log/internal"},
 
 	// Packages used by testing must be low-level (L2+fmt).
-	"regexp":         {"L2", "regexp/syntax"},
-	"regexp/syntax":  {"L2"},
-	"runtime/debug":  {"L2", "fmt", "io/ioutil", "os", "time"},
-	"runtime/pprof":  {"L2", "compress/gzip", "context", "encoding/binary", "fmt", "io/ioutil", "os", "text/tabwriter", "time"},
-	"runtime/trace":  {"L0", "context", "fmt"},
-	"text/tabwriter": {"L2"},
-
-	"testing":                  {"L2", "flag", "fmt", "internal/race", "os", "runtime/debug", "runtime/pprof", "runtime/trace", "time"},
+	"regexp":          {"L2", "regexp/syntax"},
+	"regexp/syntax":   {"L2"},
+	"runtime/debug":   {"L2", "fmt", "io/ioutil", "os", "time"},
+	"runtime/metrics": {"L0", "math"},
+	"runtime/pprof":   {"L2", "compress/gzip", "context", "encoding/binary", "fmt", "io/ioutil", "os", "text/tabwriter", "time"},
+	"runtime/trace":   {"L0", "context", "fmt"},
+	"text/tabwriter":  {"L2"},
+
+	"testing":                  {"L2", "context", "flag", "fmt", "internal/race", "io/ioutil", "math/rand", "os", "path/filepath", "reflect", "runtime/debug", "runtime/pprof", "runtime/trace", "time"},
+	"testing/fstest":           {"L2", "OS", "fmt", "reflect", "time"},
 	"testing/iotest":           {"L2", "log"},
 	"testing/quick":            {"L2", "flag", "fmt", "reflect", "time"},
 	"internal/obscuretestdata": {"L2", "OS", "encoding/base64"},
@@ -243,56 +257,66 @@ var pkgDeps = map[string][]string{
 	"go/internal/srcimporter":   {"L4", "OS", "fmt", "go/ast", "go/build", "go/parser", "go/token", "go/types", "path/filepath"},
 	"go/types":                  {"L4", "GOPARSER", "container/heap", "go/constant"},
 
+	// JSON processing built on a syntactic layer, and its internals.
+	"encoding/json/internal/jsonopts": {},
+	"encoding/json/internal/jsonwire": {"L2"},
+	"encoding/json/jsontext":          {"L4", "encoding/json/internal/jsonopts", "encoding/json/internal/jsonwire"},
+	"encoding/json/v2":                {"L4", "encoding", "encoding/hex", "encoding/json/internal/jsonopts", "encoding/json/internal/jsonwire", "encoding/json/jsontext"},
+
 	// One of a kind.
-	"archive/tar":                    {"L4", "OS", "syscall", "os/user"},
-	"archive/zip":                    {"L4", "OS", "compress/flate"},
-	"container/heap":                 {"sort"},
-	"compress/bzip2":                 {"L4"},
-	"compress/flate":                 {"L4"},
-	"compress/gzip":                  {"L4", "compress/flate"},
-	"compress/lzw":                   {"L4"},
-	"compress/zlib":                  {"L4", "compress/flate"},
-	"context":                        {"errors", "internal/reflectlite", "sync", "sync/atomic", "time"},
-	"database/sql":                   {"L4", "container/list", "context", "database/sql/driver", "database/sql/internal"},
-	"database/sql/driver":            {"L4", "context", "time", "database/sql/internal"},
-	"debug/dwarf":                    {"L4"},
-	"debug/elf":                      {"L4", "OS", "debug/dwarf", "compress/zlib"},
-	"debug/gosym":                    {"L4"},
-	"debug/macho":                    {"L4", "OS", "debug/dwarf", "compress/zlib"},
-	"debug/pe":                       {"L4", "OS", "debug/dwarf", "compress/zlib"},
-	"debug/plan9obj":                 {"L4", "OS"},
-	"encoding":                       {"L4"},
-	"encoding/ascii85":               {"L4"},
-	"encoding/asn1":                  {"L4", "math/big"},
-	"encoding/csv":                   {"L4"},
-	"encoding/gob":                   {"L4", "OS", "encoding"},
-	"encoding/hex":                   {"L4"},
-	"encoding/json":                  {"L4", "encoding"},
-	"encoding/pem":                   {"L4"},
-	"encoding/xml":                   {"L4", "encoding"},
-	"flag":                           {"L4", "OS"},
-	"go/build":                       {"L4", "OS", "GOPARSER", "internal/goroot", "internal/goversion"},
-	"html":                           {"L4"},
-	"image/draw":                     {"L4", "image/internal/imageutil"},
-	"image/gif":                      {"L4", "compress/lzw", "image/color/palette", "image/draw"},
-	"image/internal/imageutil":       {"L4"},
-	"image/jpeg":                     {"L4", "image/internal/imageutil"},
-	"image/png":                      {"L4", "compress/zlib"},
-	"index/suffixarray":              {"L4", "regexp"},
-	"internal/goroot":                {"L4", "OS"},
-	"internal/singleflight":          {"sync"},
-	"internal/trace":                 {"L4", "OS", "container/heap"},
-	"internal/xcoff":                 {"L4", "OS", "debug/dwarf"},
-	"math/big":                       {"L4"},
-	"mime":                           {"L4", "OS", "syscall", "internal/syscall/windows/registry"},
-	"mime/quotedprintable":           {"L4"},
-	"net/internal/socktest":          {"L4", "OS", "syscall", "internal/syscall/windows"},
-	"net/url":                        {"L4"},
-	"plugin":                         {"L0", "OS", "CGO"},
-	"runtime/pprof/internal/profile": {"L4", "OS", "compress/gzip", "regexp"},
-	"testing/internal/testdeps":      {"L4", "internal/testlog", "runtime/pprof", "regexp"},
-	"text/scanner":                   {"L4", "OS"},
-	"text/template/parse":            {"L4"},
+	"archive/tar":               {"L4", "OS", "syscall", "os/user"},
+	"archive/zip":               {"L4", "OS", "compress/flate"},
+	"container/heap":            {"sort"},
+	"container/list":            {"iter"},
+	"container/ring":            {"iter"},
+	"compress/bzip2":            {"L4"},
+	"compress/flate":            {"L4"},
+	"compress/gzip":             {"L4", "compress/flate"},
+	"compress/lzw":              {"L4"},
+	"compress/zlib":             {"L4", "compress/flate"},
+	"context":                   {"errors", "internal/reflectlite", "sync", "sync/atomic", "time"},
+	"database/sql":              {"L4", "container/list", "context", "database/sql/driver", "database/sql/internal"},
+	"database/sql/driver":       {"L4", "context", "time", "database/sql/internal"},
+	"debug/dwarf":               {"L4"},
+	"debug/elf":                 {"L4", "OS", "debug/dwarf", "compress/zlib"},
+	"debug/gosym":               {"L4"},
+	"debug/macho":               {"L4", "OS", "debug/dwarf", "compress/zlib"},
+	"debug/pe":                  {"L4", "OS", "debug/dwarf", "compress/zlib"},
+	"debug/plan9obj":            {"L4", "OS"},
+	"encoding":                  {"L4"},
+	"encoding/ascii85":          {"L4"},
+	"encoding/asn1":             {"L4", "math/big"},
+	"encoding/csv":              {"L4"},
+	"encoding/gob":              {"L4", "OS", "encoding"},
+	"encoding/hex":              {"L4"},
+	"encoding/json":             {"L4", "encoding"},
+	"encoding/pem":              {"L4"},
+	"encoding/xml":              {"L4", "encoding"},
+	"flag":                      {"L4", "OS"},
+	"go/build":                  {"L4", "OS", "GOPARSER", "internal/goroot", "internal/goversion"},
+	"html":                      {"L4"},
+	"image/draw":                {"L4", "image/internal/imageutil"},
+	"image/gif":                 {"L4", "compress/lzw", "image/color/palette", "image/draw"},
+	"image/internal/imageutil":  {"L4"},
+	"image/jpeg":                {"L4", "image/internal/imageutil"},
+	"image/png":                 {"L4", "compress/zlib"},
+	"index/suffixarray":         {"L4", "regexp"},
+	"log/slog":                  {"L4", "context", "encoding", "encoding/json", "log/internal"},
+	"internal/fuzz":             {"L4", "OS", "context", "crypto/sha256", "encoding/json", "go/ast", "go/parser", "go/token", "syscall"},
+	"internal/goroot":           {"L4", "OS"},
+	"internal/singleflight":     {"sync"},
+	"internal/trace":            {"L4", "OS", "container/heap"},
+	"internal/xcoff":            {"L4", "OS", "debug/dwarf"},
+	"math/big":                  {"L4"},
+	"mime":                      {"L4", "OS", "syscall", "internal/syscall/windows/registry"},
+	"mime/quotedprintable":      {"L4"},
+	"net/internal/socktest":     {"L4", "OS", "syscall", "internal/syscall/windows"},
+	"net/url":                   {"L4"},
+	"plugin":                    {"L0", "OS", "CGO"},
+	"internal/profile":          {"L4", "OS", "compress/gzip", "regexp"},
+	"testing/internal/testdeps": {"L4", "OS", "context", "internal/fuzz", "internal/testlog", "os/signal", "runtime/pprof", "regexp"},
+	"text/scanner":              {"L4", "OS"},
+	"text/template/parse":       {"L4"},
 
 	"html/template": {
 		"L4", "OS", "encoding/json", "html", "text/template",
@@ -322,12 +346,16 @@ var pkgDeps = map[string][]string{
 	// Internal package used only for testing.
 	"os/signal/internal/pty": {"CGO", "fmt", "os", "syscall"},
 
+	// Value-type IP addresses, used by net.
+	"internal/intern": {"L0", "runtime", "sync", "unsafe"},
+	"net/netip":       {"L0", "math", "math/bits", "strconv", "internal/bytealg", "internal/intern"},
+
 	// Basic networking.
 	// Because net must be used by any package that wants to
 	// do networking portably, it must have a small dependency set: just L0+basic os.
 	"net": {
 		"L0", "CGO",
-		"context", "math/rand", "os", "sort", "syscall", "time",
+		"context", "math/rand", "net/netip", "os", "sort", "syscall", "time",
 		"internal/nettrace", "internal/poll", "internal/syscall/unix",
 		"internal/syscall/windows", "internal/singleflight", "internal/race",
 		"golang.org/x/net/dns/dnsmessage", "golang.org/x/net/lif", "golang.org/x/net/route",
@@ -381,16 +409,22 @@ var pkgDeps = map[string][]string{
 	"crypto/ed25519":                       {"L3", "CRYPTO", "crypto/rand", "crypto/ed25519/internal/edwards25519"},
 	"crypto/ed25519/internal/edwards25519": {"encoding/binary"},
 
+	// Elliptic Curve Diffie-Hellman, with constant-time implementations
+	// of the NIST curves that don't depend on math/big.
+	"crypto/internal/nistec": {"L2", "crypto/subtle"},
+	"crypto/ecdh":            {"L3", "CRYPTO", "crypto/internal/nistec"},
+
 	// Mathematical crypto: dependencies on fmt (L4) and math/big.
 	// We could avoid some of the fmt, but math/big imports fmt anyway.
 	"crypto/dsa":      {"L4", "CRYPTO", "math/big"},
-	"crypto/ecdsa":    {"L4", "CRYPTO", "crypto/elliptic", "math/big", "encoding/asn1"},
+	"crypto/ecdsa":    {"L4", "CRYPTO", "crypto/ecdh", "crypto/elliptic", "math/big", "encoding/asn1"},
 	"crypto/elliptic": {"L4", "CRYPTO", "math/big"},
 	"crypto/rsa":      {"L4", "CRYPTO", "crypto/rand", "math/big"},
 
 	"CRYPTO-MATH": {
 		"CRYPTO",
 		"crypto/dsa",
+		"crypto/ecdh",
 		"crypto/ecdsa",
 		"crypto/elliptic",
 		"crypto/rand",
@@ -399,10 +433,18 @@ var pkgDeps = map[string][]string{
 		"math/big",
 	},
 
+	// SHA-3 and ML-KEM, used by the post-quantum TLS key exchange.
+	"crypto/internal/sha3":     {"L2", "encoding/binary", "hash"},
+	"crypto/internal/mlkem768": {"L3", "CRYPTO", "crypto/internal/sha3", "crypto/rand"},
+
+	// Hybrid Public Key Encryption, used by Encrypted Client Hello.
+	"crypto/internal/hpke": {"L3", "CRYPTO", "crypto/rand", "golang.org/x/crypto/hkdf"},
+
 	// SSL/TLS.
 	"crypto/tls": {
 		"L4", "CRYPTO-MATH", "OS", "golang.org/x/crypto/cryptobyte", "golang.org/x/crypto/hkdf",
-		"container/list", "crypto/x509", "encoding/pem", "net", "syscall", "crypto/ed25519",
+		"container/list", "context", "crypto/x509", "encoding/pem", "net", "syscall", "crypto/ed25519",
+		"crypto/internal/hpke", "crypto/internal/mlkem768",
 	},
 	"crypto/x509": {
 		"L4", "CRYPTO-MATH", "OS", "CGO", "crypto/ed25519",
@@ -440,7 +482,7 @@ var pkgDeps = map[string][]string{
 	"net/http/httptrace": {"context", "crypto/tls", "internal/nettrace", "net", "net/textproto", "reflect", "time"},
 
 	// HTTP-using packages.
-	"expvar":             {"L4", "OS", "encoding/json", "net/http"},
+	"expvar":             {"L4", "OS", "encoding/json", "net/http", "runtime/metrics"},
 	"net/http/cgi":       {"L4", "NET", "OS", "crypto/tls", "net/http", "regexp"},
 	"net/http/cookiejar": {"L4", "NET", "net/http"},
 	"net/http/fcgi":      {"L4", "NET", "OS", "context", "net/http", "net/http/cgi"},
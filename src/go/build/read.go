@@ -7,7 +7,13 @@ package build
 import (
 	"bufio"
 	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
 	"io"
+	"strconv"
+	"strings"
+	"unicode"
 	"unicode/utf8"
 )
 
@@ -245,3 +251,118 @@ func readImports(f io.Reader, reportSyntaxError bool, imports *[]string) ([]byte
 
 	return r.buf, r.err
 }
+
+// fileEmbed is a single //go:embed pattern and the position
+// in the source file at which it appears.
+type fileEmbed struct {
+	pattern string
+	pos     token.Position
+}
+
+// readGoEmbed returns the //go:embed patterns listed in the Go source
+// file filename, whose complete contents are data.
+// A //go:embed directive, like other //go: directives, must begin
+// at the start of a line.
+func readGoEmbed(fset *token.FileSet, filename string, data []byte) ([]fileEmbed, error) {
+	f, err := parser.ParseFile(fset, filename, data, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	var list []fileEmbed
+	for _, cg := range f.Comments {
+		for _, c := range cg.List {
+			const directive = "//go:embed"
+			if !strings.HasPrefix(c.Text, directive) {
+				continue
+			}
+			args := c.Text[len(directive):]
+			if args != "" && args[0] != ' ' && args[0] != '\t' {
+				continue // some other directive, like //go:embedded
+			}
+			pos := fset.Position(c.Slash)
+			if pos.Column != 1 {
+				continue // not a directive
+			}
+			pos.Offset += len(directive)
+			pos.Column += len(directive)
+			embeds, err := parseGoEmbed(args, pos)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", pos, err)
+			}
+			list = append(list, embeds...)
+		}
+	}
+	return list, nil
+}
+
+// parseGoEmbed parses the text following "//go:embed" to extract the glob patterns.
+// It accepts unquoted space-separated patterns as well as double-quoted and back-quoted Go strings.
+// There is a copy of this code in cmd/compile/internal/gc/noder.go as well.
+func parseGoEmbed(args string, pos token.Position) ([]fileEmbed, error) {
+	trimBytes := func(n int) {
+		pos.Offset += n
+		pos.Column += utf8.RuneCountInString(args[:n])
+		args = args[n:]
+	}
+	trimSpace := func() {
+		trim := strings.TrimLeftFunc(args, unicode.IsSpace)
+		trimBytes(len(args) - len(trim))
+	}
+
+	var list []fileEmbed
+	for trimSpace(); args != ""; trimSpace() {
+		var path string
+		pathPos := pos
+	Switch:
+		switch args[0] {
+		default:
+			i := len(args)
+			for j, c := range args {
+				if unicode.IsSpace(c) {
+					i = j
+					break
+				}
+			}
+			path = args[:i]
+			trimBytes(i)
+
+		case '`':
+			i := strings.Index(args[1:], "`")
+			if i < 0 {
+				return nil, fmt.Errorf("invalid quoted string in //go:embed: %s", args)
+			}
+			path = args[1 : 1+i]
+			trimBytes(1 + i + 1)
+
+		case '"':
+			i := 1
+			for ; i < len(args); i++ {
+				if args[i] == '\\' {
+					i++
+					continue
+				}
+				if args[i] == '"' {
+					q, err := strconv.Unquote(args[:i+1])
+					if err != nil {
+						return nil, fmt.Errorf("invalid quoted string in //go:embed: %s", args[:i+1])
+					}
+					path = q
+					trimBytes(i + 1)
+					break Switch
+				}
+			}
+			if i >= len(args) {
+				return nil, fmt.Errorf("invalid quoted string in //go:embed: %s", args)
+			}
+		}
+
+		if args != "" {
+			r, _ := utf8.DecodeRuneInString(args)
+			if !unicode.IsSpace(r) {
+				return nil, fmt.Errorf("invalid quoted string in //go:embed: %s", args)
+			}
+		}
+		list = append(list, fileEmbed{path, pathPos})
+	}
+	return list, nil
+}
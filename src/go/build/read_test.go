@@ -5,7 +5,9 @@
 package build
 
 import (
+	"go/token"
 	"io"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -221,3 +223,51 @@ func TestReadFailuresIgnored(t *testing.T) {
 	}
 	testRead(t, tests, func(r io.Reader) ([]byte, error) { return readImports(r, false, nil) })
 }
+
+var readEmbedTests = []struct {
+	in  string
+	out []string
+}{
+	{
+		"package p\n",
+		nil,
+	},
+	{
+		"package p\nimport \"embed\"\nvar i int\n//go:embed x y z\nvar files embed.FS",
+		[]string{"x", "y", "z"},
+	},
+	{
+		"package p\nimport \"embed\"\nvar i int\n//go:embed x \"\\x79\" `z`\nvar files embed.FS",
+		[]string{"x", "y", "z"},
+	},
+	{
+		"package p\nimport \"embed\"\nvar i int\n//go:embed x y\n//go:embed z\nvar files embed.FS",
+		[]string{"x", "y", "z"},
+	},
+	{
+		"package p\nimport \"embed\"\nvar i int\n\t //go:embed x y\n\t //go:embed z\n\t var files embed.FS",
+		nil,
+	},
+	{
+		"package p\nimport \"embed\"\n//go:embedx y\nvar files embed.FS",
+		nil,
+	},
+}
+
+func TestReadEmbed(t *testing.T) {
+	fset := token.NewFileSet()
+	for i, tt := range readEmbedTests {
+		embeds, err := readGoEmbed(fset, "test.go", []byte(tt.in))
+		if err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		var got []string
+		for _, e := range embeds {
+			got = append(got, e.pattern)
+		}
+		if !reflect.DeepEqual(got, tt.out) {
+			t.Errorf("#%d: embeds = %q, want %q", i, got, tt.out)
+		}
+	}
+}
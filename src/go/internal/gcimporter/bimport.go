@@ -1013,6 +1013,9 @@ var predeclared = []types.Type{
 
 	// used internally by gc; never used by this package or in .a files
 	anyType{},
+
+	// comparable
+	types.Universe.Lookup("comparable").Type(),
 }
 
 type anyType struct{}
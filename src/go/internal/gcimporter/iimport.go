@@ -54,6 +54,9 @@ const (
 	signatureType
 	structType
 	interfaceType
+	typeParamType
+	instanceType
+	unionType
 )
 
 // iImportData imports a package from the serialized package data
@@ -61,7 +64,7 @@ const (
 // If the export data version is not recognized or the format is otherwise
 // compromised, an error is returned.
 func iImportData(fset *token.FileSet, imports map[string]*types.Package, data []byte, path string) (_ int, pkg *types.Package, err error) {
-	const currentVersion = 1
+	const currentVersion = 2
 	version := int64(-1)
 	defer func() {
 		if e := recover(); e != nil {
@@ -77,7 +80,7 @@ func iImportData(fset *token.FileSet, imports map[string]*types.Package, data []
 
 	version = int64(r.uint64())
 	switch version {
-	case currentVersion, 0:
+	case currentVersion, 1, 0:
 	default:
 		errorf("unknown iexport format version %d", version)
 	}
@@ -102,6 +105,9 @@ func iImportData(fset *token.FileSet, imports map[string]*types.Package, data []
 		pkgIndex: make(map[*types.Package]map[string]uint64),
 		typCache: make(map[uint64]types.Type),
 
+		ctxt:        types.NewContext(),
+		tparamIndex: make(map[ident][]*types.TypeParam),
+
 		fake: fakeFileSet{
 			fset:  fset,
 			files: make(map[string]*token.File),
@@ -183,6 +189,20 @@ type iimporter struct {
 
 	fake          fakeFileSet
 	interfaceList []*types.Interface
+
+	ctxt        *types.Context
+	tparamIndex map[ident][]*types.TypeParam
+
+	// recvTParams, if set, holds the receiver type parameters of
+	// the method being read; they replace the type parameters of
+	// its generic receiver base type.
+	recvTParams map[ident][]*types.TypeParam
+}
+
+// An ident identifies a package-level declaration.
+type ident struct {
+	pkg  *types.Package
+	name string
 }
 
 func (p *iimporter) doDecl(pkg *types.Package, name string) {
@@ -227,7 +247,11 @@ func (p *iimporter) pkgAt(off uint64) *types.Package {
 }
 
 func (p *iimporter) typAt(off uint64, base *types.Named) types.Type {
-	if t, ok := p.typCache[off]; ok && (base == nil || !isInterface(t)) {
+	// Types read for a method of a generic type are in terms of
+	// the method's receiver type parameters and must not be shared.
+	cache := p.recvTParams == nil || off < predeclReserved
+
+	if t, ok := p.typCache[off]; ok && cache && (base == nil || !isInterface(t)) {
 		return t
 	}
 
@@ -239,12 +263,29 @@ func (p *iimporter) typAt(off uint64, base *types.Named) types.Type {
 	r.declReader.Reset(p.declData[off-predeclReserved:])
 	t := r.doType(base)
 
-	if base == nil || !isInterface(t) {
+	if cache && (base == nil || !isInterface(t)) {
 		p.typCache[off] = t
 	}
 	return t
 }
 
+// typeParam returns the index'th type parameter of the generic
+// declaration pkg.name.
+func (p *iimporter) typeParam(pkg *types.Package, name string, index uint64) *types.TypeParam {
+	id := ident{pkg, name}
+	tparams, ok := p.recvTParams[id]
+	if !ok {
+		if _, ok := p.tparamIndex[id]; !ok {
+			p.doDecl(pkg, name)
+		}
+		tparams = p.tparamIndex[id]
+	}
+	if index >= uint64(len(tparams)) {
+		errorf("%v.%v has no type parameter %d", pkg, name, index)
+	}
+	return tparams[index]
+}
+
 type importReader struct {
 	p          *iimporter
 	declReader bytes.Reader
@@ -274,6 +315,39 @@ func (r *importReader) obj(name string) {
 
 		r.declare(types.NewFunc(pos, r.currPkg, name, sig))
 
+	case 'G':
+		tparams, _ := r.typeParams(name)
+		sig := r.signature(nil)
+		sig = types.NewSignatureType(nil, nil, tparams, sig.Params(), sig.Results(), sig.Variadic())
+
+		r.declare(types.NewFunc(pos, r.currPkg, name, sig))
+
+	case 'U':
+		// Like for 'T', set up a stub declaration before
+		// recursing.
+		obj := types.NewTypeName(pos, r.currPkg, name, nil)
+		named := types.NewNamed(obj, nil, nil)
+		r.declare(obj)
+
+		tparams, constraints := r.typeParams(name)
+		named.SetTypeParams(tparams)
+
+		underlying := r.p.typAt(r.uint64(), named).Underlying()
+		named.SetUnderlying(underlying)
+
+		for n := r.uint64(); n > 0; n-- {
+			mpos := r.pos()
+			mname := r.ident()
+
+			rparams := r.recvTypeParams(name, tparams, constraints)
+			recv := r.param()
+			msig := r.signature(recv)
+			r.p.recvTParams = nil
+			msig = types.NewSignatureType(recv, rparams, nil, msig.Params(), msig.Results(), msig.Variadic())
+
+			named.AddMethod(types.NewFunc(mpos, r.currPkg, mname, msig))
+		}
+
 	case 'T':
 		// Types can be recursive. We need to setup a stub
 		// declaration before recursing.
@@ -305,6 +379,45 @@ func (r *importReader) obj(name string) {
 	}
 }
 
+// typeParams reads the type parameters of the generic declaration
+// name. It also returns the offsets of their constraints.
+func (r *importReader) typeParams(name string) ([]*types.TypeParam, []uint64) {
+	tparams := make([]*types.TypeParam, r.uint64())
+	constraints := make([]uint64, len(tparams))
+	for i := range tparams {
+		pos := r.pos()
+		obj := types.NewTypeName(pos, r.currPkg, r.string(), nil)
+		tparams[i] = types.NewTypeParam(obj, nil)
+		constraints[i] = r.uint64()
+	}
+
+	// Constraints may refer to the type parameters.
+	r.p.tparamIndex[ident{r.currPkg, name}] = tparams
+	for i, tparam := range tparams {
+		tparam.SetConstraint(r.p.typAt(constraints[i], nil))
+	}
+	return tparams, constraints
+}
+
+// recvTypeParams returns new receiver type parameters for a method
+// of the generic type name with the type parameters tparams, and
+// arranges for the method's types to be read in terms of them.
+func (r *importReader) recvTypeParams(name string, tparams []*types.TypeParam, constraints []uint64) []*types.TypeParam {
+	rparams := make([]*types.TypeParam, len(tparams))
+	for i, tparam := range tparams {
+		obj := types.NewTypeName(tparam.Obj().Pos(), r.currPkg, tparam.Obj().Name(), nil)
+		rparams[i] = types.NewTypeParam(obj, nil)
+	}
+
+	// Reread the constraints in terms of the receiver type
+	// parameters.
+	r.p.recvTParams = map[ident][]*types.TypeParam{{r.currPkg, name}: rparams}
+	for i, rparam := range rparams {
+		rparam.SetConstraint(r.p.typAt(constraints[i], nil))
+	}
+	return rparams
+}
+
 func (r *importReader) declare(obj types.Object) {
 	obj.Pkg().Scope().Insert(obj)
 }
@@ -560,6 +673,34 @@ func (r *importReader) doType(base *types.Named) types.Type {
 		typ := types.NewInterfaceType(methods, embeddeds)
 		r.p.interfaceList = append(r.p.interfaceList, typ)
 		return typ
+
+	case typeParamType:
+		pkg, name := r.qualifiedIdent()
+		return r.p.typeParam(pkg, name, r.uint64())
+
+	case instanceType:
+		pkg, name := r.qualifiedIdent()
+		r.p.doDecl(pkg, name)
+		orig := pkg.Scope().Lookup(name).(*types.TypeName).Type()
+
+		targs := make([]types.Type, r.uint64())
+		for i := range targs {
+			targs[i] = r.typ()
+		}
+
+		inst, err := types.Instantiate(r.p.ctxt, orig, targs, false)
+		if err != nil {
+			errorf("instantiating %v: %v", orig, err)
+		}
+		return inst
+
+	case unionType:
+		terms := make([]*types.Term, r.uint64())
+		for i := range terms {
+			tilde := r.bool()
+			terms[i] = types.NewTerm(tilde, r.typ())
+		}
+		return types.NewUnion(terms)
 	}
 }
 
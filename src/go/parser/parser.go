@@ -663,26 +663,126 @@ func (p *parser) parseTypeName() ast.Expr {
 	return ident
 }
 
-func (p *parser) parseArrayType() ast.Expr {
+// parseArrayType parses an array or slice type. If lbrack is valid,
+// the opening "[" has already been consumed. If len is not nil, the
+// array length has already been parsed as well.
+func (p *parser) parseArrayType(lbrack token.Pos, len ast.Expr) ast.Expr {
 	if p.trace {
 		defer un(trace(p, "ArrayType"))
 	}
 
+	if !lbrack.IsValid() {
+		lbrack = p.expect(token.LBRACK)
+	}
+	if len == nil {
+		p.exprLev++
+		// always permit ellipsis for more fault-tolerant parsing
+		if p.tok == token.ELLIPSIS {
+			len = &ast.Ellipsis{Ellipsis: p.pos}
+			p.next()
+		} else if p.tok != token.RBRACK {
+			len = p.parseRhs()
+		}
+		p.exprLev--
+	}
+	p.expect(token.RBRACK)
+	elt := p.parseType()
+
+	return &ast.ArrayType{Lbrack: lbrack, Len: len, Elt: elt}
+}
+
+// parseTypeInstance parses the type argument list of the generic
+// type typ, starting with the opening "[".
+func (p *parser) parseTypeInstance(typ ast.Expr) ast.Expr {
+	if p.trace {
+		defer un(trace(p, "TypeInstance"))
+	}
+
+	p.resolve(typ)
+	lbrack := p.expect(token.LBRACK)
+	p.exprLev++
+	var list []ast.Expr
+	for p.tok != token.RBRACK && p.tok != token.EOF {
+		list = append(list, p.parseType())
+		if !p.atComma("type argument list", token.RBRACK) {
+			break
+		}
+		p.next()
+	}
+	p.exprLev--
+	rbrack := p.expectClosing(token.RBRACK, "type argument list")
+
+	if len(list) == 0 {
+		p.errorExpected(rbrack, "type argument list")
+		return &ast.BadExpr{From: typ.Pos(), To: p.safePos(rbrack + 1)}
+	}
+
+	return packIndexExpr(typ, lbrack, list, rbrack)
+}
+
+// packIndexExpr returns an IndexExpr x[exprs[0]] if there is a single
+// index, and an IndexListExpr x[exprs...] otherwise.
+func packIndexExpr(x ast.Expr, lbrack token.Pos, exprs []ast.Expr, rbrack token.Pos) ast.Expr {
+	if len(exprs) == 1 {
+		return &ast.IndexExpr{X: x, Lbrack: lbrack, Index: exprs[0], Rbrack: rbrack}
+	}
+	return &ast.IndexListExpr{X: x, Lbrack: lbrack, Indices: exprs, Rbrack: rbrack}
+}
+
+// parseNameOrVarType parses an element of the list of identifiers or
+// types that starts a field or parameter declaration. A name followed
+// by "[" may be a field or parameter name followed by an array or
+// slice type, or an instantiated generic type. In the former case, the
+// name is returned as x and the array or slice type as typ; otherwise
+// typ is nil.
+//
+// If the result x is an identifier, it is not resolved.
+func (p *parser) parseNameOrVarType(isParam bool) (x, typ ast.Expr) {
+	if p.tok != token.IDENT {
+		return p.parseVarType(isParam), nil
+	}
+	x = p.parseTypeName()
+	if p.tok != token.LBRACK {
+		return x, nil
+	}
+
 	lbrack := p.expect(token.LBRACK)
+	if p.tok == token.RBRACK {
+		// x []E
+		p.next()
+		elt := p.parseType()
+		return x, &ast.ArrayType{Lbrack: lbrack, Elt: elt}
+	}
+
 	p.exprLev++
-	var len ast.Expr
-	// always permit ellipsis for more fault-tolerant parsing
+	var args []ast.Expr
 	if p.tok == token.ELLIPSIS {
-		len = &ast.Ellipsis{Ellipsis: p.pos}
+		// x [...]E; always permit ellipsis for more fault-tolerant parsing
+		args = append(args, &ast.Ellipsis{Ellipsis: p.pos})
+		p.next()
+	} else {
+		args = append(args, p.parseRhsOrType())
+	}
+	for p.tok == token.COMMA {
 		p.next()
-	} else if p.tok != token.RBRACK {
-		len = p.parseRhs()
+		if p.tok == token.RBRACK {
+			break
+		}
+		args = append(args, p.parseType())
 	}
 	p.exprLev--
-	p.expect(token.RBRACK)
-	elt := p.parseType()
+	rbrack := p.expect(token.RBRACK)
 
-	return &ast.ArrayType{Lbrack: lbrack, Len: len, Elt: elt}
+	if len(args) == 1 {
+		if elt := p.tryType(); elt != nil {
+			// x [N]E
+			return x, &ast.ArrayType{Lbrack: lbrack, Len: args[0], Elt: elt}
+		}
+	}
+
+	// x[A, ...]
+	p.resolve(x)
+	return packIndexExpr(x, lbrack, args, rbrack), nil
 }
 
 func (p *parser) makeIdentList(list []ast.Expr) []*ast.Ident {
@@ -711,15 +811,20 @@ func (p *parser) parseFieldDecl(scope *ast.Scope) *ast.Field {
 	// 1st FieldDecl
 	// A type name used as an anonymous field looks like a field identifier.
 	var list []ast.Expr
+	var typ ast.Expr
 	for {
-		list = append(list, p.parseVarType(false))
-		if p.tok != token.COMMA {
+		var x ast.Expr
+		x, typ = p.parseNameOrVarType(false)
+		list = append(list, x)
+		if typ != nil || p.tok != token.COMMA {
 			break
 		}
 		p.next()
 	}
 
-	typ := p.tryVarType(false)
+	if typ == nil {
+		typ = p.tryVarType(false)
+	}
 
 	// analyze case
 	var idents []*ast.Ident
@@ -732,7 +837,7 @@ func (p *parser) parseFieldDecl(scope *ast.Scope) *ast.Field {
 		if n := len(list); n > 1 {
 			p.errorExpected(p.pos, "type")
 			typ = &ast.BadExpr{From: p.pos, To: p.pos}
-		} else if !isTypeName(deref(typ)) {
+		} else if t := deref(typ); !isTypeName(t) && !isTypeInstance(t) {
 			p.errorExpected(typ.Pos(), "anonymous field")
 			typ = &ast.BadExpr{From: typ.Pos(), To: p.safePos(typ.End())}
 		}
@@ -829,9 +934,12 @@ func (p *parser) parseParameterList(scope *ast.Scope, ellipsisOk bool) (params [
 	// 1st ParameterDecl
 	// A list of identifiers looks like a list of type names.
 	var list []ast.Expr
+	var typ ast.Expr
 	for {
-		list = append(list, p.parseVarType(ellipsisOk))
-		if p.tok != token.COMMA {
+		var x ast.Expr
+		x, typ = p.parseNameOrVarType(ellipsisOk)
+		list = append(list, x)
+		if typ != nil || p.tok != token.COMMA {
 			break
 		}
 		p.next()
@@ -841,7 +949,10 @@ func (p *parser) parseParameterList(scope *ast.Scope, ellipsisOk bool) (params [
 	}
 
 	// analyze case
-	if typ := p.tryVarType(ellipsisOk); typ != nil {
+	if typ == nil {
+		typ = p.tryVarType(ellipsisOk)
+	}
+	if typ != nil {
 		// IdentifierList Type
 		idents := p.makeIdentList(list)
 		field := &ast.Field{Names: idents, Type: typ}
@@ -937,6 +1048,116 @@ func (p *parser) parseFuncType() (*ast.FuncType, *ast.Scope) {
 	return &ast.FuncType{Func: pos, Params: params, Results: results}, scope
 }
 
+// parseTypeParams parses a type parameter list and declares the type
+// parameters in scope. The opening "[" at lbrack has already been
+// consumed. If name0 is not nil, it is the already parsed name of the
+// first type parameter.
+func (p *parser) parseTypeParams(scope *ast.Scope, lbrack token.Pos, name0 *ast.Ident) *ast.FieldList {
+	if p.trace {
+		defer un(trace(p, "TypeParams"))
+	}
+
+	var list []*ast.Field
+	for p.tok != token.RBRACK && p.tok != token.EOF || name0 != nil {
+		var idents []*ast.Ident
+		if name0 != nil {
+			idents = append(idents, name0)
+			name0 = nil
+		} else {
+			idents = append(idents, p.parseIdent())
+		}
+		for p.tok == token.COMMA {
+			p.next()
+			idents = append(idents, p.parseIdent())
+		}
+		// Type parameters are in scope in their own constraints.
+		field := &ast.Field{Names: idents}
+		p.declare(field, nil, scope, ast.Typ, idents...)
+		field.Type = p.parseUnion(nil)
+		list = append(list, field)
+		if !p.atComma("type parameter list", token.RBRACK) {
+			break
+		}
+		p.next()
+	}
+	rbrack := p.expectClosing(token.RBRACK, "type parameter list")
+
+	if len(list) == 0 {
+		p.error(rbrack, "empty type parameter list")
+	}
+
+	return &ast.FieldList{Opening: lbrack, List: list, Closing: rbrack}
+}
+
+// parseUnion parses a type constraint or interface element: a single
+// type term or a union of type terms such as ~int | ~string. If x is
+// not nil, it is the already parsed first term.
+func (p *parser) parseUnion(x ast.Expr) ast.Expr {
+	if p.trace {
+		defer un(trace(p, "Union"))
+	}
+
+	if x == nil {
+		x = p.parseTypeTerm()
+	}
+	for p.tok == token.OR {
+		pos := p.pos
+		p.next()
+		y := p.parseTypeTerm()
+		x = &ast.BinaryExpr{X: x, OpPos: pos, Op: token.OR, Y: y}
+	}
+
+	return x
+}
+
+// parseTypeTerm parses a type or an underlying type term ~T.
+func (p *parser) parseTypeTerm() ast.Expr {
+	if p.tok == token.TILDE {
+		pos := p.pos
+		p.next()
+		typ := p.parseType()
+		return &ast.UnaryExpr{OpPos: pos, Op: token.TILDE, X: typ}
+	}
+	return p.parseType()
+}
+
+// declareRecvTypeParams declares the type parameters of a generic
+// receiver type, such as T in (l *List[T]), in the method's scope.
+// They were parsed as type arguments and resolved as uses; since they
+// are declarations, they are also dropped from the unresolved list.
+func (p *parser) declareRecvTypeParams(recv *ast.FieldList, scope *ast.Scope) {
+	if recv == nil || len(recv.List) != 1 {
+		return
+	}
+	var indices []ast.Expr
+	switch t := unparen(deref(unparen(recv.List[0].Type))).(type) {
+	case *ast.IndexExpr:
+		indices = []ast.Expr{t.Index}
+	case *ast.IndexListExpr:
+		indices = t.Indices
+	default:
+		return
+	}
+
+	declared := make(map[*ast.Ident]bool)
+	for _, x := range indices {
+		ident, ok := x.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		ident.Obj = nil
+		p.declare(recv.List[0], nil, scope, ast.Typ, ident)
+		declared[ident] = true
+	}
+	list := p.unresolved[:0]
+	for _, ident := range p.unresolved {
+		if !declared[ident] {
+			list = append(list, ident)
+		}
+	}
+	p.unresolved = list
+}
+
 func (p *parser) parseMethodSpec(scope *ast.Scope) *ast.Field {
 	if p.trace {
 		defer un(trace(p, "MethodSpec"))
@@ -953,9 +1174,16 @@ func (p *parser) parseMethodSpec(scope *ast.Scope) *ast.Field {
 		params, results := p.parseSignature(scope)
 		typ = &ast.FuncType{Func: token.NoPos, Params: params, Results: results}
 	} else {
-		// embedded interface
+		// embedded interface or type set element
 		typ = x
-		p.resolve(typ)
+		if p.tok == token.LBRACK {
+			typ = p.parseTypeInstance(typ)
+		} else {
+			p.resolve(typ)
+		}
+		if p.tok == token.OR {
+			typ = p.parseUnion(typ)
+		}
 	}
 	p.expectSemi() // call before accessing p.linecomment
 
@@ -974,8 +1202,21 @@ func (p *parser) parseInterfaceType() *ast.InterfaceType {
 	lbrace := p.expect(token.LBRACE)
 	scope := ast.NewScope(nil) // interface scope
 	var list []*ast.Field
-	for p.tok == token.IDENT {
-		list = append(list, p.parseMethodSpec(scope))
+L:
+	for {
+		switch p.tok {
+		case token.IDENT:
+			list = append(list, p.parseMethodSpec(scope))
+		case token.TILDE, token.MUL, token.LBRACK, token.STRUCT, token.FUNC,
+			token.INTERFACE, token.MAP, token.CHAN, token.ARROW, token.LPAREN:
+			// type set element
+			doc := p.leadComment
+			typ := p.parseUnion(nil)
+			p.expectSemi() // call before accessing p.linecomment
+			list = append(list, &ast.Field{Doc: doc, Type: typ, Comment: p.lineComment})
+		default:
+			break L
+		}
 	}
 	rbrace := p.expect(token.RBRACE)
 
@@ -1032,9 +1273,13 @@ func (p *parser) parseChanType() *ast.ChanType {
 func (p *parser) tryIdentOrType() ast.Expr {
 	switch p.tok {
 	case token.IDENT:
-		return p.parseTypeName()
+		typ := p.parseTypeName()
+		if p.tok == token.LBRACK {
+			typ = p.parseTypeInstance(typ)
+		}
+		return typ
 	case token.LBRACK:
-		return p.parseArrayType()
+		return p.parseArrayType(token.NoPos, nil)
 	case token.STRUCT:
 		return p.parseStructType()
 	case token.MUL:
@@ -1222,7 +1467,26 @@ func (p *parser) parseIndexOrSlice(x ast.Expr) ast.Expr {
 	var index [N]ast.Expr
 	var colons [N - 1]token.Pos
 	if p.tok != token.COLON {
-		index[0] = p.parseRhs()
+		// The index may be a type argument.
+		index[0] = p.parseRhsOrType()
+	}
+	if p.tok == token.COMMA {
+		// instance with multiple type arguments
+		args := []ast.Expr{index[0]}
+		for p.tok == token.COMMA {
+			p.next()
+			if p.tok == token.RBRACK {
+				break
+			}
+			args = append(args, p.parseType())
+		}
+		p.exprLev--
+		rbrack := p.expectClosing(token.RBRACK, "type argument list")
+		return packIndexExpr(x, lbrack, args, rbrack)
+	}
+	if p.tok == token.COLON && index[0] != nil {
+		// slice indices must be expressions
+		index[0] = p.checkExpr(index[0])
 	}
 	ncolons := 0
 	for p.tok == token.COLON && ncolons < len(colons) {
@@ -1385,6 +1649,7 @@ func (p *parser) checkExpr(x ast.Expr) ast.Expr {
 		panic("unreachable")
 	case *ast.SelectorExpr:
 	case *ast.IndexExpr:
+	case *ast.IndexListExpr:
 	case *ast.SliceExpr:
 	case *ast.TypeAssertExpr:
 		// If t.Type == nil we have a type assertion of the form
@@ -1418,6 +1683,18 @@ func isTypeName(x ast.Expr) bool {
 	return true
 }
 
+// isTypeInstance reports whether x is a (qualified) TypeName
+// followed by type arguments.
+func isTypeInstance(x ast.Expr) bool {
+	switch t := x.(type) {
+	case *ast.IndexExpr:
+		return isTypeName(t.X)
+	case *ast.IndexListExpr:
+		return isTypeName(t.X)
+	}
+	return false
+}
+
 // isLiteralType reports whether x is a legal composite literal type.
 func isLiteralType(x ast.Expr) bool {
 	switch t := x.(type) {
@@ -1426,6 +1703,8 @@ func isLiteralType(x ast.Expr) bool {
 	case *ast.SelectorExpr:
 		_, isIdent := t.X.(*ast.Ident)
 		return isIdent
+	case *ast.IndexExpr, *ast.IndexListExpr:
+		return isTypeInstance(t)
 	case *ast.ArrayType:
 	case *ast.StructType:
 	case *ast.MapType:
@@ -1469,13 +1748,17 @@ func (p *parser) checkExprOrType(x ast.Expr) ast.Expr {
 	return x
 }
 
-// If lhs is set and the result is an identifier, it is not resolved.
-func (p *parser) parsePrimaryExpr(lhs bool) ast.Expr {
+// If x is not nil, it is the already parsed operand of the primary
+// expression. If lhs is set and the result is an identifier, it is not
+// resolved.
+func (p *parser) parsePrimaryExpr(x ast.Expr, lhs bool) ast.Expr {
 	if p.trace {
 		defer un(trace(p, "PrimaryExpr"))
 	}
 
-	x := p.parseOperand(lhs)
+	if x == nil {
+		x = p.parseOperand(lhs)
+	}
 L:
 	for {
 		switch p.tok {
@@ -1507,7 +1790,7 @@ L:
 			}
 			x = p.parseCallOrConversion(p.checkExprOrType(x))
 		case token.LBRACE:
-			if isLiteralType(x) && (p.exprLev >= 0 || !isTypeName(x)) {
+			if isLiteralType(x) && (p.exprLev >= 0 || !isTypeName(x) && !isTypeInstance(x)) {
 				if lhs {
 					p.resolve(x)
 				}
@@ -1591,7 +1874,7 @@ func (p *parser) parseUnaryExpr(lhs bool) ast.Expr {
 		return &ast.StarExpr{Star: pos, X: p.checkExprOrType(x)}
 	}
 
-	return p.parsePrimaryExpr(lhs)
+	return p.parsePrimaryExpr(nil, lhs)
 }
 
 func (p *parser) tokPrec() (token.Token, int) {
@@ -1602,13 +1885,17 @@ func (p *parser) tokPrec() (token.Token, int) {
 	return tok, tok.Precedence()
 }
 
-// If lhs is set and the result is an identifier, it is not resolved.
-func (p *parser) parseBinaryExpr(lhs bool, prec1 int) ast.Expr {
+// If x is not nil, it is the already parsed first operand of the
+// binary expression. If lhs is set and the result is an identifier, it
+// is not resolved.
+func (p *parser) parseBinaryExpr(x ast.Expr, lhs bool, prec1 int) ast.Expr {
 	if p.trace {
 		defer un(trace(p, "BinaryExpr"))
 	}
 
-	x := p.parseUnaryExpr(lhs)
+	if x == nil {
+		x = p.parseUnaryExpr(lhs)
+	}
 	for {
 		op, oprec := p.tokPrec()
 		if oprec < prec1 {
@@ -1619,7 +1906,7 @@ func (p *parser) parseBinaryExpr(lhs bool, prec1 int) ast.Expr {
 			p.resolve(x)
 			lhs = false
 		}
-		y := p.parseBinaryExpr(false, oprec+1)
+		y := p.parseBinaryExpr(nil, false, oprec+1)
 		x = &ast.BinaryExpr{X: p.checkExpr(x), OpPos: pos, Op: op, Y: p.checkExpr(y)}
 	}
 }
@@ -1633,7 +1920,7 @@ func (p *parser) parseExpr(lhs bool) ast.Expr {
 		defer un(trace(p, "Expression"))
 	}
 
-	return p.parseBinaryExpr(lhs, token.LowestPrec+1)
+	return p.parseBinaryExpr(nil, lhs, token.LowestPrec+1)
 }
 
 func (p *parser) parseRhs() ast.Expr {
@@ -2387,17 +2674,65 @@ func (p *parser) parseTypeSpec(doc *ast.CommentGroup, _ token.Token, _ int) ast.
 	// (Global identifiers are resolved in a separate phase after parsing.)
 	spec := &ast.TypeSpec{Doc: doc, Name: ident}
 	p.declare(spec, nil, p.topScope, ast.Typ, ident)
-	if p.tok == token.ASSIGN {
-		spec.Assign = p.pos
+
+	if p.tok == token.LBRACK {
+		// array or slice type, or type parameter list
+		lbrack := p.pos
 		p.next()
+		if p.tok == token.IDENT {
+			// An array length may start with an identifier, too. A type
+			// parameter name is followed by another name or a comma, or
+			// by the start of a constraint that cannot continue an
+			// expression. For [P *C], use [P interface{*C}] instead.
+			x := p.parseIdent()
+			if isTypeParamFollow(p.tok) {
+				p.openScope()
+				spec.TypeParams = p.parseTypeParams(p.topScope, lbrack, x)
+				if p.tok == token.ASSIGN {
+					p.error(p.pos, "generic type cannot be alias")
+					p.next()
+				}
+				spec.Type = p.parseType()
+				p.closeScope()
+			} else {
+				// x starts the array length
+				p.resolve(x)
+				p.exprLev++
+				old := p.inRhs
+				p.inRhs = true
+				len := p.checkExpr(p.parseBinaryExpr(p.parsePrimaryExpr(x, false), false, token.LowestPrec+1))
+				p.inRhs = old
+				p.exprLev--
+				spec.Type = p.parseArrayType(lbrack, len)
+			}
+		} else {
+			spec.Type = p.parseArrayType(lbrack, nil)
+		}
+	} else {
+		if p.tok == token.ASSIGN {
+			spec.Assign = p.pos
+			p.next()
+		}
+		spec.Type = p.parseType()
 	}
-	spec.Type = p.parseType()
 	p.expectSemi() // call before accessing p.linecomment
 	spec.Comment = p.lineComment
 
 	return spec
 }
 
+// isTypeParamFollow reports whether tok may follow the name of the
+// first type parameter in a type declaration, as opposed to continuing
+// an array length expression.
+func isTypeParamFollow(tok token.Token) bool {
+	switch tok {
+	case token.IDENT, token.COMMA, token.TILDE, token.LBRACK, token.STRUCT,
+		token.FUNC, token.INTERFACE, token.MAP, token.CHAN, token.ARROW:
+		return true
+	}
+	return false
+}
+
 func (p *parser) parseGenDecl(keyword token.Token, f parseSpecFunction) *ast.GenDecl {
 	if p.trace {
 		defer un(trace(p, "GenDecl("+keyword.String()+")"))
@@ -2441,10 +2776,21 @@ func (p *parser) parseFuncDecl() *ast.FuncDecl {
 	var recv *ast.FieldList
 	if p.tok == token.LPAREN {
 		recv = p.parseParameters(scope, false)
+		p.declareRecvTypeParams(recv, scope)
 	}
 
 	ident := p.parseIdent()
 
+	var tparams *ast.FieldList
+	if p.tok == token.LBRACK {
+		lbrack := p.pos
+		p.next()
+		tparams = p.parseTypeParams(scope, lbrack, nil)
+		if recv != nil {
+			p.error(tparams.Opening, "method must have no type parameters")
+		}
+	}
+
 	params, results := p.parseSignature(scope)
 
 	var body *ast.BlockStmt
@@ -2468,9 +2814,10 @@ func (p *parser) parseFuncDecl() *ast.FuncDecl {
 		Recv: recv,
 		Name: ident,
 		Type: &ast.FuncType{
-			Func:    pos,
-			Params:  params,
-			Results: results,
+			Func:       pos,
+			TypeParams: tparams,
+			Params:     params,
+			Results:    results,
 		},
 		Body: body,
 	}
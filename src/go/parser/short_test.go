@@ -48,6 +48,20 @@ var valids = []string{
 	`package p; var _ = map[*P]int{&P{}:0, {}:1}`,
 	`package p; type T = int`,
 	`package p; type (T = p.T; _ = struct{}; x = *T)`,
+	`package p; type T[P any] struct{ x P }`,
+	`package p; type T[P1, P2 any, Q interface{ m() }] []P1`,
+	`package p; type T[P ~int | ~string] int`,
+	`package p; type T[P *C] int`,
+	`package p; type T [N]int`,
+	`package p; type T [a * b]int`,
+	`package p; func f[P any](x P) P { return x }`,
+	`package p; func (t T[P]) m() {}`,
+	`package p; func (t *T[P, Q]) m() {}`,
+	`package p; func f() { _ = f[int]; _ = f[int, string]; _ = T[int]{} }`,
+	`package p; var _ T[int]`,
+	`package p; type _ struct{ T[int] }`,
+	`package p; type _ interface{ ~int | ~uint; m() }`,
+	`package p; type _ interface{ int; T[int] }`,
 }
 
 func TestValid(t *testing.T) {
@@ -78,6 +92,8 @@ var invalids = []string{
 	`package p; var a = map /* ERROR "expected expression" */ [int]int`,
 	`package p; var a = chan /* ERROR "expected expression" */ int;`,
 	`package p; var a = []int{[ /* ERROR "expected expression" */ ]int};`,
+	`package p; type T[P any] = /* ERROR "generic type cannot be alias" */ int`,
+	`package p; func (T) m[ /* ERROR "method must have no type parameters" */ P any]() {}`,
 	`package p; var a = ( /* ERROR "expected expression" */ []int);`,
 	`package p; var a = a[[ /* ERROR "expected expression" */ ]int:[]int];`,
 	`package p; var a = <- /* ERROR "expected expression" */ chan int;`,
@@ -11,8 +11,106 @@ import (
 	"go/token"
 )
 
+// funcInst type-checks a function instantiation inst and returns the result in x.
+// The operand x must be the evaluation of inst.x and its type must be a signature.
+func (check *Checker) funcInst(x *operand, ix *indexedExpr) {
+	targs := check.typeList(ix.indices)
+	if targs == nil {
+		x.mode = invalid
+		x.expr = ix.orig
+		return
+	}
+	assert(len(targs) == len(ix.indices))
+
+	// check number of type arguments (got) vs number of type parameters (want)
+	sig := x.typ.(*Signature)
+	got, want := len(targs), sig.TypeParams().Len()
+	if got > want {
+		check.errorf(ix.indices[want].Pos(), "got %d type arguments but %s has %d type parameters", got, x.expr, want)
+		x.mode = invalid
+		x.expr = ix.orig
+		return
+	}
+
+	if got < want {
+		targs = check.infer(ix.orig, sig.TypeParams().list(), targs, nil, nil)
+		if targs == nil {
+			// error was already reported
+			x.mode = invalid
+			x.expr = ix.orig
+			return
+		}
+	}
+
+	// instantiate function signature
+	res := check.instantiateSignature(x.pos(), sig, targs, ix.indices)
+	check.recordInstance(ix.orig, targs, res)
+	x.typ = res
+	x.mode = value
+	x.expr = ix.orig
+}
+
+// instantiateSignature instantiates the generic signature typ with the
+// type arguments targs and verifies that the type arguments satisfy their
+// constraints. The expressions xlist, if any, are used for error positions.
+func (check *Checker) instantiateSignature(pos token.Pos, typ *Signature, targs []Type, xlist []ast.Expr) *Signature {
+	inst := check.instance(pos, typ, targs, check.ctxt).(*Signature)
+	assert(len(xlist) <= len(targs))
+
+	// verify instantiation lazily (was issue #50450)
+	tparams := typ.TypeParams().list()
+	check.later(func() {
+		if i, err := check.verify(pos, tparams, targs, check.ctxt); err != nil {
+			// best position for error reporting
+			pos := pos
+			if i < len(xlist) {
+				pos = xlist[i].Pos()
+			}
+			check.softErrorf(pos, "%s", err)
+		}
+	})
+
+	return inst
+}
+
 func (check *Checker) call(x *operand, e *ast.CallExpr) exprKind {
-	check.exprOrType(x, e.Fun)
+	var targs []Type     // explicit type arguments, if any
+	var xlist []ast.Expr // type argument expressions, if any
+	switch e.Fun.(type) {
+	case *ast.IndexExpr, *ast.IndexListExpr:
+		ix := unpackIndexedExpr(e.Fun)
+		if check.indexExpr(x, ix) {
+			// Delay function instantiation to argument checking,
+			// where we combine type and value arguments for type
+			// inference.
+			assert(x.mode == value)
+			xlist = ix.indices
+			targs = check.typeList(xlist)
+			if targs == nil {
+				check.use(e.Args...)
+				x.mode = invalid
+				x.expr = e
+				return statement
+			}
+			assert(len(targs) == len(xlist))
+
+			// check number of type arguments (got) vs number of type parameters (want)
+			got, want := len(targs), x.typ.(*Signature).TypeParams().Len()
+			if got > want {
+				check.errorf(xlist[want].Pos(), "got %d type arguments but %s has %d type parameters", got, x.expr, want)
+				check.use(e.Args...)
+				x.mode = invalid
+				x.expr = e
+				return statement
+			}
+		}
+		x.expr = e.Fun
+		if x.mode != invalid && targs == nil {
+			check.recordTypeAndValue(e.Fun, x.mode, x.typ, x.val)
+		}
+	default:
+		check.exprOrType(x, e.Fun, true)
+	}
 
 	switch x.mode {
 	case invalid:
@@ -23,6 +121,12 @@ func (check *Checker) call(x *operand, e *ast.CallExpr) exprKind {
 
 	case typexpr:
 		// conversion
+		check.nonGeneric(x)
+		if x.mode == invalid {
+			check.use(e.Args...)
+			x.expr = e
+			return statement
+		}
 		T := x.typ
 		x.mode = invalid
 		switch n := len(e.Args); n {
@@ -54,7 +158,7 @@ func (check *Checker) call(x *operand, e *ast.CallExpr) exprKind {
 
 	default:
 		// function/method call
-		sig, _ := x.typ.Underlying().(*Signature)
+		sig, _ := coreType(x.typ).(*Signature)
 		if sig == nil {
 			check.invalidOp(x.pos(), "cannot call non-function %s", x)
 			x.mode = invalid
@@ -63,6 +167,11 @@ func (check *Checker) call(x *operand, e *ast.CallExpr) exprKind {
 		}
 
 		arg, n, _ := unpack(func(x *operand, i int) { check.multiExpr(x, e.Args[i]) }, len(e.Args), false)
+		if arg != nil && sig.TypeParams().Len() > 0 {
+			// infer missing type arguments from the function arguments
+			// before checking them against the instantiated signature
+			sig, arg = check.inferCall(x, e, sig, targs, xlist, arg, n)
+		}
 		if arg != nil {
 			check.arguments(x, e, sig, arg, n)
 		} else {
@@ -88,6 +197,61 @@ func (check *Checker) call(x *operand, e *ast.CallExpr) exprKind {
 	}
 }
 
+// inferCall evaluates the n arguments provided by arg, infers the type
+// arguments of the generic signature sig not provided by targs, and returns
+// the instantiated signature together with a getter for the evaluated
+// arguments. If inference fails, the result getter is nil.
+func (check *Checker) inferCall(x *operand, call *ast.CallExpr, sig *Signature, targs []Type, xlist []ast.Expr, arg getter, n int) (*Signature, getter) {
+	args := make([]*operand, n)
+	for i := range args {
+		var a operand
+		arg(&a, i)
+		if a.mode == invalid {
+			check.useGetter(arg, n)
+			return sig, nil
+		}
+		args[i] = &a
+	}
+	get := func(x *operand, i int) { *x = *args[i] }
+
+	// collect the parameter for each argument
+	var params []*Var
+	nparams := sig.params.Len()
+	for i := 0; i < n; i++ {
+		var v *Var
+		switch {
+		case sig.variadic && i >= nparams-1:
+			v = sig.params.vars[nparams-1]
+			if !call.Ellipsis.IsValid() {
+				// use the variadic parameter slice's element type
+				if s, _ := v.typ.(*Slice); s != nil {
+					v = NewParam(v.pos, v.pkg, v.name, s.elem)
+				}
+			}
+		case i < nparams:
+			v = sig.params.vars[i]
+		}
+		if v == nil {
+			break // too many arguments; reported by Checker.arguments
+		}
+		params = append(params, v)
+	}
+
+	targs = check.infer(call, sig.TypeParams().list(), targs, NewTuple(params...), args[:len(params)])
+	if targs == nil {
+		x.mode = invalid
+		return sig, nil
+	}
+
+	// compute result signature
+	rsig := check.instantiateSignature(call.Pos(), sig, targs, xlist)
+	check.recordInstance(call.Fun, targs, rsig)
+	// Update the recorded type of call.Fun to its instantiated type.
+	check.recordTypeAndValue(call.Fun, value, rsig, nil)
+
+	return rsig, get
+}
+
 // use type-checks each argument.
 // Useful to make sure expressions are evaluated
 // (and variables are "used") in the presence of other errors.
@@ -98,7 +262,7 @@ func (check *Checker) use(arg ...ast.Expr) {
 		// The nil check below is necessary since certain AST fields
 		// may legally be nil (e.g., the ast.SliceExpr.High field).
 		if e != nil {
-			check.rawExpr(&x, e, nil)
+			check.rawExpr(&x, e, nil, false)
 		}
 	}
 }
@@ -130,7 +294,7 @@ func (check *Checker) useLHS(arg ...ast.Expr) {
 				}
 			}
 		}
-		check.rawExpr(&x, e, nil)
+		check.rawExpr(&x, e, nil, false)
 		if v != nil {
 			v.used = v_used // restore v.used
 		}
@@ -290,7 +454,7 @@ func (check *Checker) argument(sig *Signature, i int, x *operand, ellipsis token
 			check.errorf(ellipsis, "can only use ... with matching parameter")
 			return
 		}
-		if _, ok := x.typ.Underlying().(*Slice); !ok && x.typ != Typ[UntypedNil] { // see issue #18268
+		if _, ok := coreType(x.typ).(*Slice); !ok && x.typ != Typ[UntypedNil] { // see issue #18268
 			check.errorf(x.pos(), "cannot use %s as parameter of type %s", x, typ)
 			return
 		}
@@ -365,7 +529,7 @@ func (check *Checker) selector(x *operand, e *ast.SelectorExpr) {
 		}
 	}
 
-	check.exprOrType(x, e.X)
+	check.exprOrType(x, e.X, false)
 	if x.mode == invalid {
 		goto Error
 	}
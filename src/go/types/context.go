@@ -0,0 +1,65 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package types
+
+import "sync"
+
+// A Context is an opaque type checking context. It may be used to share
+// identical type instances across type-checked packages or calls to
+// Instantiate. Contexts are safe for concurrent use.
+//
+// The use of a shared context does not guarantee that identical instances are
+// deduplicated in all cases.
+type Context struct {
+	mu    sync.Mutex
+	insts map[*Named][]*Named // instances of generic types, by origin type
+}
+
+// NewContext creates a new Context.
+func NewContext() *Context {
+	return &Context{insts: make(map[*Named][]*Named)}
+}
+
+// lookup returns an instance of orig with type arguments identical
+// to targs if ctxt has recorded one; otherwise it returns nil.
+func (ctxt *Context) lookup(orig *Named, targs []Type) *Named {
+	ctxt.mu.Lock()
+	defer ctxt.mu.Unlock()
+
+	for _, inst := range ctxt.insts[orig] {
+		if identicalTypeLists(inst.targs.list(), targs) {
+			return inst
+		}
+	}
+	return nil
+}
+
+// update records inst as an instance of orig unless an identical
+// instance was recorded already. It returns the recorded instance.
+func (ctxt *Context) update(orig, inst *Named) *Named {
+	ctxt.mu.Lock()
+	defer ctxt.mu.Unlock()
+
+	for _, other := range ctxt.insts[orig] {
+		if identicalTypeLists(other.targs.list(), inst.targs.list()) {
+			return other
+		}
+	}
+	ctxt.insts[orig] = append(ctxt.insts[orig], inst)
+	return inst
+}
+
+// identicalTypeLists reports whether the types in x and y are pairwise identical.
+func identicalTypeLists(x, y []Type) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	for i, t := range x {
+		if !Identical(t, y[i]) {
+			return false
+		}
+	}
+	return true
+}
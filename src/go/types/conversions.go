@@ -13,13 +13,12 @@ import "go/constant"
 func (check *Checker) conversion(x *operand, T Type) {
 	constArg := x.mode == constant_
 
-	var ok bool
-	switch {
-	case constArg && isConstType(T):
-		// constant conversion
-		switch t := T.Underlying().(*Basic); {
-		case representableConst(x.val, check, t, &x.val):
-			ok = true
+	constConvertibleTo := func(T Type, val *constant.Value) bool {
+		switch t, _ := T.Underlying().(*Basic); {
+		case t == nil:
+			// nothing to do
+		case representableConst(x.val, check, t, val):
+			return true
 		case isInteger(x.typ) && isString(t):
 			codepoint := int64(-1)
 			if i, ok := constant.Int64Val(x.val); ok {
@@ -28,9 +27,26 @@ func (check *Checker) conversion(x *operand, T Type) {
 			// If codepoint < 0 the absolute value is too large (or unknown) for
 			// conversion. This is the same as converting any other out-of-range
 			// value - let string(codepoint) do the work.
-			x.val = constant.MakeString(string(codepoint))
-			ok = true
+			if val != nil {
+				*val = constant.MakeString(string(codepoint))
+			}
+			return true
 		}
+		return false
+	}
+
+	var ok bool
+	switch {
+	case constArg && isConstType(T):
+		// constant conversion
+		ok = constConvertibleTo(T, &x.val)
+	case constArg && isTypeParam(T):
+		// x is convertible to T if it is convertible
+		// to each specific type in the type set of T
+		ok = T.(*TypeParam).underIs(func(u Type) bool {
+			return u != nil && constConvertibleTo(u, nil)
+		})
+		x.mode = value // type parameters are not constants
 	case x.convertibleTo(check, T):
 		// non-constant conversion
 		x.mode = value
@@ -133,6 +149,38 @@ func (x *operand) convertibleTo(check *Checker, T Type) bool {
 		return true
 	}
 
+	// type parameters: the conversion must be valid for each
+	// specific type in the respective type sets
+	// (generic operands cannot be constants, so we can ignore x.val)
+	Vp, _ := V.(*TypeParam)
+	Tp, _ := T.(*TypeParam)
+	switch {
+	case Vp != nil && Tp != nil:
+		x := *x // don't clobber outer x
+		return Vp.is(func(V *term) bool {
+			if V == nil {
+				return false // no specific types
+			}
+			x.typ = V.typ
+			return Tp.is(func(T *term) bool {
+				return T != nil && x.convertibleTo(check, T.typ)
+			})
+		})
+	case Vp != nil:
+		x := *x // don't clobber outer x
+		return Vp.is(func(V *term) bool {
+			if V == nil {
+				return false // no specific types
+			}
+			x.typ = V.typ
+			return x.convertibleTo(check, T)
+		})
+	case Tp != nil:
+		return Tp.is(func(T *term) bool {
+			return T != nil && x.convertibleTo(check, T.typ)
+		})
+	}
+
 	return false
 }
 
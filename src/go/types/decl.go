@@ -189,7 +189,7 @@ func (check *Checker) objDecl(obj Object, def *Named) {
 		check.varDecl(obj, d.lhs, d.typ, d.init)
 	case *TypeName:
 		// invalid recursive types are detected via path
-		check.typeDecl(obj, d.typ, def, d.alias)
+		check.typeDecl(obj, d.tdecl, def)
 	case *Func:
 		// functions may be recursive - no need to track dependencies
 		check.funcDecl(obj, d)
@@ -311,6 +311,13 @@ func (check *Checker) validType(typ Type, path []Object) typeInfo {
 		}
 
 	case *Named:
+		// An instance is valid if its generic type is valid; invalid
+		// recursive instantiations such as T[int] in the declaration
+		// of T are found via the generic type.
+		if t.orig != t {
+			return check.validType(t.orig, path)
+		}
+
 		// don't touch the type if it is from a different package or the Universe scope
 		// (doing so would lead to a race condition - was issue #35049)
 		if t.obj.pkg != check.pkg {
@@ -327,7 +334,7 @@ func (check *Checker) validType(typ Type, path []Object) typeInfo {
 		switch t.info {
 		case unknown:
 			t.info = marked
-			t.info = check.validType(t.orig, append(path, t.obj)) // only types of current package added to path
+			t.info = check.validType(t.fromRHS, append(path, t.obj)) // only types of current package added to path
 		case marked:
 			// cycle detected
 			for i, tn := range path {
@@ -419,7 +426,7 @@ func (check *Checker) varDecl(obj *Var, lhs []*Var, typ, init ast.Expr) {
 
 	// determine type, if any
 	if typ != nil {
-		obj.typ = check.typ(typ)
+		obj.typ = check.varType(typ)
 		// We cannot spread the type to all lhs variables if there
 		// are more than one since that would mark them as checked
 		// (see Checker.objDecl) and the assignment of init exprs,
@@ -492,9 +499,13 @@ func (check *Checker) underlying(typ Type) Type {
 
 	// If the underlying type of a defined type is not a defined
 	// type, then that is the desired underlying type.
-	typ = n0.underlying
+	typ = n0.expand().underlying
 	n, _ := typ.(*Named)
 	if n == nil {
+		if typ == nil && n0.orig != n0 {
+			// instance of a generic type that is not set up yet
+			typ = Typ[Invalid]
+		}
 		return typ // common case
 	}
 
@@ -502,7 +513,14 @@ func (check *Checker) underlying(typ Type) Type {
 	seen := map[*Named]int{n0: 0}
 	path := []Object{n0.obj}
 	for {
-		typ = n.underlying
+		typ = n.expand().underlying
+		if typ == nil && n.orig != n {
+			// instance of a generic type that is not set up yet:
+			// the generic type is part of a cycle
+			check.cycleError(path)
+			typ = Typ[Invalid]
+			break
+		}
 		n1, _ := typ.(*Named)
 		if n1 == nil {
 			break // end of chain
@@ -539,26 +557,45 @@ func (n *Named) setUnderlying(typ Type) {
 	}
 }
 
-func (check *Checker) typeDecl(obj *TypeName, typ ast.Expr, def *Named, alias bool) {
+func (check *Checker) typeDecl(obj *TypeName, tdecl *ast.TypeSpec, def *Named) {
 	assert(obj.typ == nil)
 
 	check.later(func() {
 		check.validType(obj.typ, nil)
 	})
 
+	alias := tdecl.Assign.IsValid()
+	if alias && tdecl.TypeParams != nil {
+		// The parser will ensure this but we may still get an invalid AST.
+		// Complain and continue as regular type definition.
+		check.errorf(tdecl.Assign, "generic type cannot be alias")
+		alias = false
+	}
+
 	if alias {
 
 		obj.typ = Typ[Invalid]
-		obj.typ = check.typ(typ)
+		obj.typ = check.typ(tdecl.Type)
 
 	} else {
 
-		named := &Named{obj: obj}
+		named := &Named{check: check, obj: obj}
+		named.orig = named
 		def.setUnderlying(named)
 		obj.typ = named // make sure recursive type declarations terminate
 
+		if tdecl.TypeParams != nil {
+			// The type parameters are declared in their own scope,
+			// which encloses the type's underlying type expression.
+			scope := NewScope(check.scope, tdecl.Pos(), tdecl.End(), "type parameters")
+			check.recordScope(tdecl, scope)
+			defer func(s *Scope) { check.scope = s }(check.scope)
+			check.scope = scope
+			named.tparams = bindTParams(check.collectTypeParams(tdecl.TypeParams))
+		}
+
 		// determine underlying type of named
-		named.orig = check.definedType(typ, named)
+		named.fromRHS = check.definedType(tdecl.Type, named)
 
 		// The underlying type of named may be itself a named type that is
 		// incomplete:
@@ -575,11 +612,91 @@ func (check *Checker) typeDecl(obj *TypeName, typ ast.Expr, def *Named, alias bo
 		// any forward chain.
 		named.underlying = check.underlying(named)
 
+		// A generic type cannot be defined by a type parameter.
+		if named.tparams != nil && isTypeParam(named.underlying) {
+			check.errorf(tdecl.Type.Pos(), "cannot use a type parameter as RHS in type declaration")
+			named.underlying = Typ[Invalid]
+		}
+
 	}
 
 	check.addMethodDecls(obj)
 }
 
+// collectTypeParams declares the type parameters in list in the current
+// scope and returns them in source order.
+func (check *Checker) collectTypeParams(list *ast.FieldList) []*TypeParam {
+	// Declare type parameters up-front. The scope of type parameters starts
+	// at the beginning of the type parameter list, so that constraints may
+	// refer to type parameters declared later in the list.
+	var tparams []*TypeParam
+	for _, f := range list.List {
+		for _, name := range f.Names {
+			tparams = append(tparams, check.declareTypeParam(name))
+		}
+	}
+
+	index := 0
+	for _, f := range list.List {
+		var bound Type
+		if f.Type != nil {
+			bound = check.bound(f.Type)
+			if isTypeParam(bound) {
+				// We may be able to allow this since it is now well-defined what
+				// the underlying type and thus type set of a type parameter is.
+				// But we may need some additional form of cycle detection within
+				// type parameter lists.
+				check.error(f.Type.Pos(), "cannot use a type parameter as constraint")
+				bound = Typ[Invalid]
+			}
+		} else {
+			check.invalidAST(f.Pos(), "missing type constraint")
+			bound = Typ[Invalid]
+		}
+		for i := range f.Names {
+			tparams[index+i].bound = implicitConstraint(bound)
+		}
+		index += len(f.Names)
+	}
+
+	return tparams
+}
+
+func (check *Checker) declareTypeParam(name *ast.Ident) *TypeParam {
+	// Use Typ[Invalid] for the type constraint to ensure that a type
+	// is present even if the actual constraint has not been assigned
+	// yet.
+	tname := NewTypeName(name.Pos(), check.pkg, name.Name, nil)
+	tpar := check.newTypeParam(tname, nil)
+	tpar.bound = Typ[Invalid]
+	check.declare(check.scope, name, tname, check.scope.pos)
+	return tpar
+}
+
+// bound type-checks the type constraint expression x and returns its type.
+func (check *Checker) bound(x ast.Expr) Type {
+	// A type set literal of the form ~T and A|B may only appear as constraint;
+	// embed it in an implicit interface so that only interface type-checking
+	// needs to take care of such type expressions.
+	wrap := false
+	switch op := x.(type) {
+	case *ast.UnaryExpr:
+		wrap = op.Op == token.TILDE
+	case *ast.BinaryExpr:
+		wrap = op.Op == token.OR
+	}
+	if wrap {
+		x = &ast.InterfaceType{Interface: x.Pos(), Methods: &ast.FieldList{List: []*ast.Field{{Type: x}}}}
+		t := check.typ(x)
+		// mark t as implicit interface if all went well
+		if t, _ := t.(*Interface); t != nil {
+			t.implicit = true
+		}
+		return t
+	}
+	return check.typ(x)
+}
+
 func (check *Checker) addMethodDecls(obj *TypeName) {
 	// get associated methods
 	// (Checker.collectObjects only collects methods with non-blank names;
@@ -783,7 +900,7 @@ func (check *Checker) declStmt(decl ast.Decl) {
 				check.declare(check.scope, s.Name, obj, scopePos)
 				// mark and unmark type before calling typeDecl; its type is still nil (see Checker.objDecl)
 				obj.setColor(grey + color(check.push(obj)))
-				check.typeDecl(obj, s.Type, nil, s.Assign.IsValid())
+				check.typeDecl(obj, s, nil)
 				check.pop().setColor(black)
 			default:
 				check.invalidAST(s.Pos(), "const, type, or var declaration expected")
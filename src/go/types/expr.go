@@ -94,7 +94,7 @@ func (check *Checker) unary(x *operand, e *ast.UnaryExpr, op token.Token) {
 		return
 
 	case token.ARROW:
-		typ, ok := x.typ.Underlying().(*Chan)
+		typ, ok := coreType(x.typ).(*Chan)
 		if !ok {
 			check.invalidOp(x.pos(), "cannot receive from non-channel %s", x)
 			x.mode = invalid
@@ -475,6 +475,11 @@ func (check *Checker) updateExprType(x ast.Expr, typ Type, final bool) {
 	}
 
 	// Everything's fine, record final type and value for x.
+	if isTypeParam(typ) {
+		// a constant converted to a type parameter type is a value
+		check.recordTypeAndValue(x, value, typ, nil)
+		return
+	}
 	check.recordTypeAndValue(x, old.mode, typ, old.val)
 }
 
@@ -570,6 +575,19 @@ func (check *Checker) convertUntyped(x *operand, target Type) {
 		}
 		// keep nil untyped - see comment for interfaces, above
 		target = Typ[UntypedNil]
+	case *TypeParam:
+		// x must be assignable to each specific type in t's type set
+		if !x.assignableTo(check, target, nil) {
+			goto Error
+		}
+		if x.isNil() {
+			// keep nil untyped - see comment for interfaces, above
+			target = Typ[UntypedNil]
+		} else if x.mode == constant_ {
+			// a constant converted to a type parameter type is a value
+			x.mode = value
+			x.val = nil
+		}
 	default:
 		goto Error
 	}
@@ -967,8 +985,10 @@ const (
 // rawExpr typechecks expression e and initializes x with the expression
 // value or type. If an error occurred, x.mode is set to invalid.
 // If hint != nil, it is the type of a composite literal element.
+// If allowGeneric is set, the operand type may be an uninstantiated
+// parameterized type or function value.
 //
-func (check *Checker) rawExpr(x *operand, e ast.Expr, hint Type) exprKind {
+func (check *Checker) rawExpr(x *operand, e ast.Expr, hint Type, allowGeneric bool) exprKind {
 	if trace {
 		check.trace(e.Pos(), "%s", e)
 		check.indent++
@@ -980,6 +1000,10 @@ func (check *Checker) rawExpr(x *operand, e ast.Expr, hint Type) exprKind {
 
 	kind := check.exprInternal(x, e, hint)
 
+	if !allowGeneric {
+		check.nonGeneric(x)
+	}
+
 	// convert x into a user-friendly set of values
 	// TODO(gri) this code can be simplified
 	var typ Type
@@ -1008,6 +1032,30 @@ func (check *Checker) rawExpr(x *operand, e ast.Expr, hint Type) exprKind {
 	return kind
 }
 
+// If x is a generic function or type, nonGeneric reports an error and invalidates x.mode and x.typ.
+// Otherwise it leaves x alone.
+func (check *Checker) nonGeneric(x *operand) {
+	if x.mode == invalid || x.mode == novalue {
+		return
+	}
+	var what string
+	switch t := x.typ.(type) {
+	case *Named:
+		if isGeneric(t) {
+			what = "type"
+		}
+	case *Signature:
+		if t.tparams != nil {
+			what = "function"
+		}
+	}
+	if what != "" {
+		check.errorf(x.pos(), "cannot use generic %s %s without instantiation", what, x.expr)
+		x.mode = invalid
+		x.typ = Typ[Invalid]
+	}
+}
+
 // exprInternal contains the core of type checking of expressions.
 // Must only be called by rawExpr.
 //
@@ -1082,7 +1130,10 @@ func (check *Checker) exprInternal(x *operand, e ast.Expr, hint Type) exprKind {
 		case hint != nil:
 			// no composite literal type present - use hint (element type of enclosing type)
 			typ = hint
-			base, _ = deref(typ.Underlying()) // *T implies &T{}
+			base = typ
+			if b, _ := deref(coreType(typ)); b != nil {
+				base = b // *T implies &T{}
+			}
 
 		default:
 			// TODO(gri) provide better error messages depending on context
@@ -1090,7 +1141,7 @@ func (check *Checker) exprInternal(x *operand, e ast.Expr, hint Type) exprKind {
 			goto Error
 		}
 
-		switch utyp := base.Underlying().(type) {
+		switch utyp := coreType(base).(type) {
 		case *Struct:
 			if len(e.Elts) == 0 {
 				break
@@ -1260,83 +1311,26 @@ func (check *Checker) exprInternal(x *operand, e ast.Expr, hint Type) exprKind {
 		x.typ = typ
 
 	case *ast.ParenExpr:
-		kind := check.rawExpr(x, e.X, nil)
+		kind := check.rawExpr(x, e.X, nil, false)
 		x.expr = e
 		return kind
 
 	case *ast.SelectorExpr:
 		check.selector(x, e)
 
-	case *ast.IndexExpr:
-		check.expr(x, e.X)
+	case *ast.IndexExpr, *ast.IndexListExpr:
+		ix := unpackIndexedExpr(e)
+		if check.indexExpr(x, ix) {
+			check.funcInst(x, ix)
+		}
 		if x.mode == invalid {
-			check.use(e.Index)
 			goto Error
 		}
-
-		valid := false
-		length := int64(-1) // valid if >= 0
-		switch typ := x.typ.Underlying().(type) {
-		case *Basic:
-			if isString(typ) {
-				valid = true
-				if x.mode == constant_ {
-					length = int64(len(constant.StringVal(x.val)))
-				}
-				// an indexed string always yields a byte value
-				// (not a constant) even if the string and the
-				// index are constant
-				x.mode = value
-				x.typ = universeByte // use 'byte' name
-			}
-
-		case *Array:
-			valid = true
-			length = typ.len
-			if x.mode != variable {
-				x.mode = value
-			}
-			x.typ = typ.elem
-
-		case *Pointer:
-			if typ, _ := typ.base.Underlying().(*Array); typ != nil {
-				valid = true
-				length = typ.len
-				x.mode = variable
-				x.typ = typ.elem
-			}
-
-		case *Slice:
-			valid = true
-			x.mode = variable
-			x.typ = typ.elem
-
-		case *Map:
-			var key operand
-			check.expr(&key, e.Index)
-			check.assignment(&key, typ.key, "map index")
-			if x.mode == invalid {
-				goto Error
-			}
-			x.mode = mapindex
-			x.typ = typ.elem
+		if x.mode == mapindex {
 			x.expr = e
 			return expression
 		}
 
-		if !valid {
-			check.invalidOp(x.pos(), "cannot index %s", x)
-			goto Error
-		}
-
-		if e.Index == nil {
-			check.invalidAST(e.Pos(), "missing index for %s", x)
-			goto Error
-		}
-
-		check.index(e.Index, length)
-		// ok to continue
-
 	case *ast.SliceExpr:
 		check.expr(x, e.X)
 		if x.mode == invalid {
@@ -1346,7 +1340,7 @@ func (check *Checker) exprInternal(x *operand, e ast.Expr, hint Type) exprKind {
 
 		valid := false
 		length := int64(-1) // valid if >= 0
-		switch typ := x.typ.Underlying().(type) {
+		switch typ := coreType(x.typ).(type) {
 		case *Basic:
 			if isString(typ) {
 				if e.Slice3 {
@@ -1453,7 +1447,7 @@ func (check *Checker) exprInternal(x *operand, e ast.Expr, hint Type) exprKind {
 			check.invalidAST(e.Pos(), "use of .(type) outside type switch")
 			goto Error
 		}
-		T := check.typ(e.Type)
+		T := check.varType(e.Type)
 		if T == Typ[Invalid] {
 			goto Error
 		}
@@ -1465,14 +1459,14 @@ func (check *Checker) exprInternal(x *operand, e ast.Expr, hint Type) exprKind {
 		return check.call(x, e)
 
 	case *ast.StarExpr:
-		check.exprOrType(x, e.X)
+		check.exprOrType(x, e.X, false)
 		switch x.mode {
 		case invalid:
 			goto Error
 		case typexpr:
 			x.typ = &Pointer{base: x.typ}
 		default:
-			if typ, ok := x.typ.Underlying().(*Pointer); ok {
+			if typ, ok := coreType(x.typ).(*Pointer); ok {
 				x.mode = variable
 				x.typ = typ.base
 			} else {
@@ -1530,6 +1524,203 @@ Error:
 	return statement // avoid follow-up errors
 }
 
+// indexExpr type-checks the index expression e. If e denotes a generic
+// function, indexExpr reports true; the function instantiation is left
+// to the caller. Otherwise x is set to the indexed value or the type
+// instance, or x.mode is set to invalid if an error occurred.
+func (check *Checker) indexExpr(x *operand, e *indexedExpr) (isFuncInst bool) {
+	check.exprOrType(x, e.x, true)
+	// x may be generic
+
+	switch x.mode {
+	case invalid:
+		check.use(e.indices...)
+		return false
+
+	case typexpr:
+		// type instantiation
+		x.mode = invalid
+		x.typ = check.varType(e.orig)
+		if x.typ != Typ[Invalid] {
+			x.mode = typexpr
+		}
+		return false
+
+	case value:
+		if sig, _ := x.typ.Underlying().(*Signature); sig != nil && sig.TypeParams().Len() > 0 {
+			// function instantiation
+			return true
+		}
+	}
+
+	// x should not be generic at this point, but be safe and check
+	check.nonGeneric(x)
+	if x.mode == invalid {
+		return false
+	}
+
+	// ordinary index expression
+	valid := false
+	length := int64(-1) // valid if >= 0
+	switch typ := x.typ.Underlying().(type) {
+	case *Basic:
+		if isString(typ) {
+			valid = true
+			if x.mode == constant_ {
+				length = int64(len(constant.StringVal(x.val)))
+			}
+			// an indexed string always yields a byte value
+			// (not a constant) even if the string and the
+			// index are constant
+			x.mode = value
+			x.typ = universeByte // use 'byte' name
+		}
+
+	case *Array:
+		valid = true
+		length = typ.len
+		if x.mode != variable {
+			x.mode = value
+		}
+		x.typ = typ.elem
+
+	case *Pointer:
+		if typ, _ := typ.base.Underlying().(*Array); typ != nil {
+			valid = true
+			length = typ.len
+			x.mode = variable
+			x.typ = typ.elem
+		}
+
+	case *Slice:
+		valid = true
+		x.mode = variable
+		x.typ = typ.elem
+
+	case *Map:
+		index := check.singleIndex(e)
+		if index == nil {
+			x.mode = invalid
+			return false
+		}
+		var key operand
+		check.expr(&key, index)
+		check.assignment(&key, typ.key, "map index")
+		// ok to continue even if indexing failed - map element type is known
+		x.mode = mapindex
+		x.typ = typ.elem
+		return false
+
+	case *TypeParam:
+		// x is indexable if all types in the type set of x support
+		// indexing with identical element (and, for maps, key) types
+		var key, elem Type // key != nil: we must have all maps
+		mode := variable   // non-maps result mode
+		if typ.underIs(func(u Type) bool {
+			l := int64(-1) // valid if >= 0
+			var k, e Type  // k is only set for maps
+			switch t := u.(type) {
+			case *Basic:
+				if isString(t) {
+					e = universeByte
+					mode = value
+				}
+			case *Array:
+				l = t.len
+				e = t.elem
+				if x.mode != variable {
+					mode = value
+				}
+			case *Pointer:
+				if t, _ := t.base.Underlying().(*Array); t != nil {
+					l = t.len
+					e = t.elem
+				}
+			case *Slice:
+				e = t.elem
+			case *Map:
+				k = t.key
+				e = t.elem
+			}
+			if e == nil {
+				return false
+			}
+			if elem == nil {
+				// first type
+				length = l
+				key, elem = k, e
+				return true
+			}
+			// all map keys must be identical (incl. all nil)
+			// (that is, we cannot mix maps with other types)
+			if !Identical(key, k) {
+				return false
+			}
+			// all element types must be identical
+			if !Identical(elem, e) {
+				return false
+			}
+			// track the minimal length for arrays, if any
+			if l >= 0 && l < length {
+				length = l
+			}
+			return true
+		}) {
+			// For maps, the index expression must be assignable to the map key type.
+			if key != nil {
+				index := check.singleIndex(e)
+				if index == nil {
+					x.mode = invalid
+					return false
+				}
+				var k operand
+				check.expr(&k, index)
+				check.assignment(&k, key, "map index")
+				// ok to continue even if indexing failed - map element type is known
+				x.mode = mapindex
+				x.typ = elem
+				return false
+			}
+
+			// no maps
+			valid = true
+			x.mode = mode
+			x.typ = elem
+		}
+	}
+
+	if !valid {
+		check.invalidOp(x.pos(), "cannot index %s", x)
+		check.use(e.indices...)
+		x.mode = invalid
+		return false
+	}
+
+	index := check.singleIndex(e)
+	if index == nil {
+		x.mode = invalid
+		return false
+	}
+
+	check.index(index, length)
+	// ok to continue
+	return false
+}
+
+// singleIndex returns the (single) index from the index expression e.
+// If the index is missing, or if there are multiple indices, an error
+// is reported and the result is nil.
+func (check *Checker) singleIndex(e *indexedExpr) ast.Expr {
+	if len(e.indices) == 0 {
+		check.invalidAST(e.Pos(), "index expression %v with 0 indices", e.orig)
+		return nil
+	}
+	if len(e.indices) > 1 {
+		check.invalidOp(e.indices[1].Pos(), "more than one index")
+	}
+	return e.indices[0]
+}
+
 func keyVal(x constant.Value) interface{} {
 	switch x.Kind() {
 	case constant.Bool:
@@ -1592,7 +1783,7 @@ func (check *Checker) expr(x *operand, e ast.Expr) {
 
 // multiExpr is like expr but the result may be a multi-value.
 func (check *Checker) multiExpr(x *operand, e ast.Expr) {
-	check.rawExpr(x, e, nil)
+	check.rawExpr(x, e, nil, false)
 	var msg string
 	switch x.mode {
 	default:
@@ -1614,7 +1805,7 @@ func (check *Checker) multiExpr(x *operand, e ast.Expr) {
 //
 func (check *Checker) exprWithHint(x *operand, e ast.Expr, hint Type) {
 	assert(hint != nil)
-	check.rawExpr(x, e, hint)
+	check.rawExpr(x, e, hint, false)
 	check.singleValue(x)
 	var msg string
 	switch x.mode {
@@ -1632,10 +1823,12 @@ func (check *Checker) exprWithHint(x *operand, e ast.Expr, hint Type) {
 }
 
 // exprOrType typechecks expression or type e and initializes x with the expression value or type.
+// If allowGeneric is set, the operand type may be an uninstantiated parameterized type or function
+// value.
 // If an error occurred, x.mode is set to invalid.
 //
-func (check *Checker) exprOrType(x *operand, e ast.Expr) {
-	check.rawExpr(x, e, nil)
+func (check *Checker) exprOrType(x *operand, e ast.Expr, allowGeneric bool) {
+	check.rawExpr(x, e, nil, allowGeneric)
 	check.singleValue(x)
 	if x.mode == novalue {
 		check.errorf(x.pos(), "%s used as value or type", x)
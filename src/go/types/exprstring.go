@@ -71,6 +71,17 @@ func WriteExpr(buf *bytes.Buffer, x ast.Expr) {
 		WriteExpr(buf, x.Index)
 		buf.WriteByte(']')
 
+	case *ast.IndexListExpr:
+		WriteExpr(buf, x.X)
+		buf.WriteByte('[')
+		for i, index := range x.Indices {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			WriteExpr(buf, index)
+		}
+		buf.WriteByte(']')
+
 	case *ast.SliceExpr:
 		WriteExpr(buf, x.X)
 		buf.WriteByte('[')
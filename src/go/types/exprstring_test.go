@@ -31,6 +31,7 @@ var testExprs = []testEntry{
 	dup("(x)"),
 	dup("x.f"),
 	dup("a[i]"),
+	dup("a[i, j]"),
 
 	dup("s[:]"),
 	dup("s[i:]"),
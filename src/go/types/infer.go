@@ -0,0 +1,394 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file implements type parameter inference given
+// a list of concrete arguments and a parameter list.
+
+package types
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// infer attempts to infer the complete set of type arguments for generic function
+// instantiation/call based on the given type parameters tparams, type arguments
+// targs, function parameters params, and function arguments args, if any. There
+// must be at least one type parameter, no more type arguments than type parameters,
+// and params and args must match in number (incl. zero). If successful, infer
+// returns the complete list of type arguments, one for each type parameter.
+// Otherwise the result is nil and appropriate errors will be reported.
+//
+// Inference proceeds as follows:
+//
+//	Starting with given type arguments
+//	1) apply function argument type inference with typed arguments,
+//	2) apply constraint type inference,
+//	3) use default types for type parameters with untyped arguments only, and
+//	4) apply constraint type inference again.
+//
+func (check *Checker) infer(posn ast.Node, tparams []*TypeParam, targs []Type, params *Tuple, args []*operand) []Type {
+	n := len(tparams)
+	assert(n > 0 && len(targs) <= n)
+	assert(params.Len() == len(args))
+
+	// If we already have all type arguments, we're done.
+	if len(targs) == n {
+		return targs
+	}
+
+	// If we have invalid (ordinary) arguments, an error was reported before.
+	// Avoid additional inference errors and exit early.
+	for _, arg := range args {
+		if arg.mode == invalid {
+			return nil
+		}
+	}
+
+	// The arguments may mention the type parameters of the function
+	// being inferred if the function calls itself recursively. Rename
+	// the type parameters to avoid confusing them with the argument
+	// types.
+	for _, arg := range args {
+		if isParameterized(tparams, arg.typ) {
+			var typ Type
+			tparams, typ = check.renameTParams(posn.Pos(), tparams, params)
+			params = typ.(*Tuple)
+			break
+		}
+	}
+
+	u := newUnifier(tparams, targs)
+
+	// Unify parameter and argument types for generic parameters with typed arguments
+	// and collect the indices of generic parameters with untyped arguments.
+	var untyped []int
+	for i, arg := range args {
+		par := params.At(i)
+		if !isParameterized(tparams, par.typ) {
+			continue
+		}
+		if isTyped(arg.typ) {
+			if !u.unify(par.typ, arg.typ) {
+				check.inferError(arg, par.typ, u)
+				return nil
+			}
+		} else if _, ok := par.typ.(*TypeParam); ok {
+			// Since default types are all basic (i.e., non-composite) types, an
+			// untyped argument will never match a composite parameter type; the
+			// only parameter type it can possibly match against is a *TypeParam.
+			// Thus, for untyped arguments we only need to look at parameter types
+			// that are single type parameters.
+			untyped = append(untyped, i)
+		}
+	}
+
+	// Use information from type parameter constraints.
+	if !check.inferConstraints(posn, u) {
+		return nil
+	}
+
+	// Use any untyped arguments to infer additional type arguments.
+	// If several untyped arguments are passed for the same type parameter,
+	// the default type of the "largest" untyped kind is used.
+	if untyped != nil {
+		defaults := make([]Type, n)
+		for _, i := range untyped {
+			tpar := params.At(i).typ.(*TypeParam) // is type parameter by construction of untyped
+			if u.at(tpar) != nil {
+				continue
+			}
+			arg := args[i]
+			j := tparamIndex(tparams, tpar)
+			switch d := defaults[j]; {
+			case d == nil:
+				defaults[j] = arg.typ
+			case maxUntyped(d, arg.typ) != nil:
+				defaults[j] = maxUntyped(d, arg.typ)
+			default:
+				check.errorf(arg.pos(), "mismatched types %s and %s (cannot infer %s)", d, arg.typ, tpar.obj.name)
+				return nil
+			}
+		}
+		for i, d := range defaults {
+			if d != nil && u.types[i] == nil {
+				if t := Default(d); t != Typ[UntypedNil] {
+					u.types[i] = t
+				}
+			}
+		}
+
+		if !check.inferConstraints(posn, u) {
+			return nil
+		}
+	}
+
+	// The inferred types may mention other type parameters whose types
+	// were inferred as well (e.g., via a constraint with core type []E).
+	// Substitute until nothing changes anymore; inferred types that still
+	// mention type parameters are treated as unresolved.
+	targs = u.inferred()
+	smap := makeSubstMap(tparams, targs)
+	for range tparams {
+		changed := false
+		for i, t := range targs {
+			if t != nil && isParameterized(tparams, t) {
+				if nt := check.subst(token.NoPos, t, smap, check.ctxt); nt != t {
+					targs[i] = nt
+					smap[tparams[i]] = nt
+					changed = true
+				}
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	for i, t := range targs {
+		if t == nil || isParameterized(tparams, t) {
+			check.errorf(posn.Pos(), "cannot infer %s", tparams[i].obj.name)
+			return nil
+		}
+	}
+
+	return targs
+}
+
+// inferError reports that the argument arg doesn't match the generic
+// parameter type ptyp.
+func (check *Checker) inferError(arg *operand, ptyp Type, u *unifier) {
+	if tpar, _ := ptyp.(*TypeParam); tpar != nil {
+		if inferred := u.at(tpar); inferred != nil {
+			check.errorf(arg.pos(), "type %s of %s does not match inferred type %s for %s", arg.typ, arg.expr, inferred, tpar)
+			return
+		}
+	}
+	check.errorf(arg.pos(), "type %s of %s does not match %s", arg.typ, arg.expr, ptyp)
+}
+
+// inferConstraints uses the core types of the type parameter constraints
+// to infer additional type arguments: If the type argument for a type
+// parameter with a core type is known, it must unify with the core type;
+// if it is not known and the constraint has a single specific type, that
+// type is the type argument. It reports whether it succeeded; otherwise
+// an error was reported.
+func (check *Checker) inferConstraints(posn ast.Node, u *unifier) bool {
+	// Repeat until nothing changes anymore: each step may
+	// provide information for other type parameters.
+	for range u.tparams {
+		before := u.inferred()
+		for i, tpar := range u.tparams {
+			core, single := coreTerm(tpar)
+			if core == nil {
+				continue
+			}
+			tx := u.types[i]
+			switch {
+			case tx != nil:
+				// The type argument tx is known; if core is ~T,
+				// its underlying (or core) type must unify with T,
+				// otherwise tx itself must unify with core.typ.
+				if core.tilde {
+					tx = coreType(tx)
+					if tx == nil {
+						continue
+					}
+				}
+				if !u.unify(tx, core.typ) {
+					check.errorf(posn.Pos(), "%s does not match %s", tpar, core.typ)
+					return false
+				}
+			case single && !core.tilde:
+				// The corresponding type argument tx is unknown and there's a single
+				// specific type and no tilde: in this case the type argument must be
+				// that single type.
+				u.types[i] = core.typ
+			}
+		}
+		changed := false
+		for i, t := range u.types {
+			if t != before[i] {
+				changed = true
+				break
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+	return true
+}
+
+// coreTerm returns the core term of type parameter tpar if there is one;
+// that is, either a single specific type term or a term over the core type
+// of tpar's type set. The result single reports whether the core term is
+// the single term of the type set; it is only meaningful if the result
+// term is non-nil.
+func coreTerm(tpar *TypeParam) (core *term, single bool) {
+	n := 0
+	var tilde bool
+	tpar.is(func(t *term) bool {
+		if t == nil {
+			return false // no terms
+		}
+		n++
+		core = t
+		if t.tilde {
+			tilde = true
+		}
+		return true
+	})
+	if n == 1 {
+		return core, true
+	}
+	if typ := coreType(tpar); typ != nil {
+		// A core type is always an underlying type. If any term of
+		// tpar has a tilde, we don't have a precise core type and we
+		// must return a tilde as well.
+		return &term{tilde, typ}, false
+	}
+	return nil, false
+}
+
+// maxUntyped returns the "largest" of the untyped numeric types x and y,
+// or x if x and y are identical, or nil if they are not compatible.
+func maxUntyped(x, y Type) Type {
+	if x == y {
+		return x
+	}
+	if isNumeric(x) && isNumeric(y) {
+		// untyped types are basic types
+		if x.(*Basic).kind > y.(*Basic).kind {
+			return x
+		}
+		return y
+	}
+	return nil
+}
+
+// renameTParams renames the type parameters in the given type such that
+// each type parameter is given a new identity. renameTParams returns the
+// new type parameters and the updated type.
+func (check *Checker) renameTParams(pos token.Pos, tparams []*TypeParam, typ Type) ([]*TypeParam, Type) {
+	tparams2 := make([]*TypeParam, len(tparams))
+	targs := make([]Type, len(tparams))
+	for i, tparam := range tparams {
+		tname := NewTypeName(tparam.obj.pos, tparam.obj.pkg, tparam.obj.name, nil)
+		tparams2[i] = check.newTypeParam(tname, nil)
+		tparams2[i].index = tparam.index
+		targs[i] = tparams2[i]
+	}
+
+	smap := makeSubstMap(tparams, targs)
+	for i, tparam := range tparams {
+		tparams2[i].bound = check.subst(pos, tparam.bound, smap, check.ctxt)
+	}
+
+	return tparams2, check.subst(pos, typ, smap, check.ctxt)
+}
+
+// isParameterized reports whether typ contains any of the type parameters of tparams.
+func isParameterized(tparams []*TypeParam, typ Type) bool {
+	w := tpWalker{
+		seen:    make(map[Type]bool),
+		tparams: tparams,
+	}
+	return w.isParameterized(typ)
+}
+
+type tpWalker struct {
+	seen    map[Type]bool
+	tparams []*TypeParam
+}
+
+func (w *tpWalker) isParameterized(typ Type) (res bool) {
+	// detect cycles
+	if x, ok := w.seen[typ]; ok {
+		return x
+	}
+	w.seen[typ] = false
+	defer func() {
+		w.seen[typ] = res
+	}()
+
+	switch t := typ.(type) {
+	case nil, *Basic:
+		break
+
+	case *Array:
+		return w.isParameterized(t.elem)
+
+	case *Slice:
+		return w.isParameterized(t.elem)
+
+	case *Struct:
+		for _, fld := range t.fields {
+			if w.isParameterized(fld.typ) {
+				return true
+			}
+		}
+
+	case *Pointer:
+		return w.isParameterized(t.base)
+
+	case *Tuple:
+		n := t.Len()
+		for i := 0; i < n; i++ {
+			if w.isParameterized(t.At(i).typ) {
+				return true
+			}
+		}
+
+	case *Signature:
+		// t.tparams may not be nil if we are looking at a signature
+		// of a generic function type (or an interface method) that is
+		// part of the type we're testing. We don't care about these type
+		// parameters.
+		// Similarly, the receiver of a method may declare (rather than
+		// use) type parameters, we don't care about those either.
+		// Thus, we only need to look at the input and result parameters.
+		return w.isParameterized(t.params) || w.isParameterized(t.results)
+
+	case *Interface:
+		for _, m := range t.methods {
+			if w.isParameterized(m.typ) {
+				return true
+			}
+		}
+		for _, e := range t.embeddeds {
+			if w.isParameterized(e) {
+				return true
+			}
+		}
+
+	case *Union:
+		for _, t := range t.terms {
+			if w.isParameterized(t.typ) {
+				return true
+			}
+		}
+
+	case *Map:
+		return w.isParameterized(t.key) || w.isParameterized(t.elem)
+
+	case *Chan:
+		return w.isParameterized(t.elem)
+
+	case *Named:
+		for _, t := range t.targs.list() {
+			if w.isParameterized(t) {
+				return true
+			}
+		}
+
+	case *TypeParam:
+		return tparamIndex(w.tparams, t) >= 0
+
+	default:
+		panic("unreachable")
+	}
+
+	return false
+}
@@ -0,0 +1,311 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file implements instantiation of generic types
+// through substitution of type parameters by type arguments.
+
+package types
+
+import (
+	"fmt"
+	"go/token"
+)
+
+// Instantiate instantiates the type orig with the given type arguments targs.
+// orig must be a generic *Named or *Signature type. If there is no error, the
+// resulting Type is an instantiated type of the same kind (either a *Named or
+// a *Signature). Methods attached to a *Named type are also instantiated, and
+// associated with a new *Func that has the same position as the original
+// method, but nil function scope.
+//
+// If ctxt is non-nil, it may be used to de-duplicate the instance against
+// previous instances with the same identity.
+//
+// If validate is set, Instantiate verifies that the number of type arguments
+// and parameters match, and that the type arguments satisfy their
+// corresponding type constraints. If verification fails, the resulting error
+// may wrap an *ArgumentError indicating which type argument did not satisfy
+// its corresponding type parameter constraint, and why.
+//
+// If validate is not set, Instantiate does not verify the type argument count
+// or whether the type arguments satisfy their constraints. Instantiate is
+// guaranteed to not return an error, but may panic.
+func Instantiate(ctxt *Context, orig Type, targs []Type, validate bool) (Type, error) {
+	if ctxt == nil {
+		ctxt = NewContext()
+	}
+
+	var tparams []*TypeParam
+	switch t := orig.(type) {
+	case *Named:
+		tparams = t.TypeParams().list()
+		orig = t.orig
+	case *Signature:
+		tparams = t.TypeParams().list()
+	default:
+		panic(fmt.Sprintf("cannot instantiate %s: not a generic *Named or *Signature type", orig))
+	}
+
+	if validate {
+		if len(tparams) == 0 {
+			return nil, fmt.Errorf("cannot instantiate non-generic %s", orig)
+		}
+		if len(targs) != len(tparams) {
+			return nil, fmt.Errorf("got %d type arguments but %s has %d type parameters", len(targs), orig, len(tparams))
+		}
+		if i, err := (*Checker)(nil).verify(token.NoPos, tparams, targs, ctxt); err != nil {
+			return nil, &ArgumentError{i, err}
+		}
+	}
+
+	return (*Checker)(nil).instance(token.NoPos, orig, targs, ctxt), nil
+}
+
+// instance instantiates the given original (generic) function or type with
+// the provided type arguments and returns the resulting instance. If an
+// identical instance of a generic type exists already in ctxt, it returns
+// that instance. If there is an error (such as wrong number of type
+// arguments), the result is Typ[Invalid].
+//
+// For *Named types, the underlying type and the methods of the instance
+// are set up lazily.
+func (check *Checker) instance(pos token.Pos, orig Type, targs []Type, ctxt *Context) Type {
+	switch orig := orig.(type) {
+	case *Named:
+		if inst := ctxt.lookup(orig, targs); inst != nil {
+			return inst
+		}
+		inst := &Named{check: check, ctxt: ctxt, obj: orig.obj, orig: orig, targs: newTypeList(targs)}
+		return ctxt.update(orig, inst)
+
+	case *Signature:
+		tparams := orig.TypeParams()
+		if tparams.Len() != len(targs) {
+			return Typ[Invalid]
+		}
+		sig := check.subst(pos, orig, makeSubstMap(tparams.list(), targs), ctxt).(*Signature)
+		// If the signature doesn't use its type parameters, subst
+		// will not make a copy. In that case, make a copy now (so
+		// we can set tparams to nil w/o causing side-effects).
+		if sig == orig {
+			copy := *sig
+			sig = &copy
+		}
+		// After instantiating a generic signature, it is not generic
+		// anymore; we need to set tparams to nil.
+		sig.tparams = nil
+		return sig
+	}
+
+	unreachable()
+	return nil
+}
+
+// expand sets up the underlying type of the instance t if its origin's
+// underlying type is known, and returns t. Instances are expanded lazily
+// because the origin may not be set up yet when an instance is created.
+func (t *Named) expand() *Named {
+	orig := t.orig
+	if orig == t || orig == nil || t.underlying != nil {
+		return t
+	}
+
+	u := orig.underlying
+	if u == nil {
+		return t // origin not set up yet
+	}
+	if _, ok := u.(*Named); ok {
+		return t // origin not set up yet
+	}
+
+	tparams := orig.tparams.list()
+	if len(tparams) != t.targs.Len() || u == Typ[Invalid] {
+		// error reported elsewhere
+		t.underlying = Typ[Invalid]
+		return t
+	}
+
+	// Mark t as expanded before substituting to terminate
+	// if substitution ends up expanding t again.
+	t.underlying = Typ[Invalid]
+	smap := makeSubstMap(tparams, t.targs.list())
+	u = t.check.subst(t.obj.pos, u, smap, t.ctxt)
+
+	// Interface methods of the origin have the origin as receiver
+	// (see Checker.interfaceType); the instance's methods must have
+	// the instance as receiver.
+	if iface, _ := u.(*Interface); iface != nil {
+		if methods, copied := replaceRecvType(iface.methods, orig, t); copied {
+			if iface == orig.underlying {
+				iface = &Interface{embeddeds: iface.embeddeds, implicit: iface.implicit}
+			}
+			iface.methods = methods
+			iface.allMethods = nil
+			iface.Complete()
+			u = iface
+		}
+	}
+
+	t.underlying = u
+	return t
+}
+
+// methodList returns the methods of the named type t. For an instance,
+// the methods are instantiated from the methods of its origin as needed.
+func (t *Named) methodList() []*Func {
+	orig := t.orig
+	if orig == t || orig == nil {
+		return t.methods
+	}
+
+	for i := len(t.methods); i < len(orig.methods); i++ {
+		m := orig.methods[i]
+		f := NewFunc(m.pos, m.pkg, m.name, nil)
+		f.hasPtrRecv = m.hasPtrRecv
+		f.origin = m
+		t.methods = append(t.methods, f)
+	}
+
+	for _, f := range t.methods {
+		if f.typ == nil || f.typ == f.origin.typ {
+			t.instantiateMethod(f)
+		}
+	}
+
+	return t.methods
+}
+
+// instantiateMethod sets the type of the instance method f to the type of
+// its origin method with the receiver type parameters substituted by t's
+// type arguments.
+func (t *Named) instantiateMethod(f *Func) {
+	m := f.origin
+
+	// methods may not have a fully set up signature yet
+	if m.typ == nil && t.check != nil {
+		t.check.objDecl(m, nil)
+	}
+
+	sig, _ := m.typ.(*Signature)
+	if sig == nil || sig.recv == nil {
+		// The origin's signature is not set up yet (we are in
+		// a cycle) or the origin is invalid. Use its type as is
+		// and try again later.
+		f.typ = m.typ
+		f.color_ = colorFor(f.typ)
+		return
+	}
+
+	rparams := sig.rparams.list()
+	if len(rparams) != t.targs.Len() {
+		// invalid receiver (error reported elsewhere)
+		f.typ = sig
+		f.color_ = black
+		return
+	}
+
+	smap := makeSubstMap(rparams, t.targs.list())
+	nsig := t.check.subst(m.pos, sig, smap, t.ctxt).(*Signature)
+	if nsig == sig {
+		copy := *sig
+		nsig = &copy
+	}
+	recv := *sig.recv
+	recv.typ = t.check.subst(recv.pos, recv.typ, smap, t.ctxt)
+	nsig.recv = &recv
+	nsig.rparams = nil
+	nsig.scope = nil
+
+	f.typ = nsig
+	f.color_ = black
+}
+
+// verify checks that each type argument satisfies the constraint of its
+// corresponding type parameter. If a type argument doesn't, verify returns
+// its index and the reason.
+func (check *Checker) verify(pos token.Pos, tparams []*TypeParam, targs []Type, ctxt *Context) (int, error) {
+	smap := makeSubstMap(tparams, targs)
+	for i, tpar := range tparams {
+		// The type parameter bound is parameterized with the same type
+		// parameters as the instantiated type; before we can use it for
+		// bounds checking we need to instantiate it with the type arguments
+		// with which we instantiated the parameterized type.
+		bound := check.subst(pos, tpar.bound, smap, ctxt)
+		if err := check.implements(targs[i], bound); err != nil {
+			return i, err
+		}
+	}
+	return -1, nil
+}
+
+// implements checks if V implements T. The receiver may be nil if implements
+// is called through an exported API call such as Instantiate.
+func (check *Checker) implements(V, T Type) error {
+	Vu := check.under(V)
+	Tu := check.under(T)
+	if Vu == Typ[Invalid] || Tu == Typ[Invalid] {
+		return nil // avoid follow-on errors
+	}
+
+	Ti, _ := Tu.(*Interface)
+	if Ti == nil {
+		return fmt.Errorf("%s is not an interface", check.typeString(T))
+	}
+	check.completeInterface(Ti)
+
+	// Every type satisfies the empty interface.
+	if Ti.Empty() {
+		return nil
+	}
+
+	// V must implement T's methods, if any.
+	if m, wrong := check.missingMethod(V, Ti, true); m != nil {
+		if wrong {
+			return fmt.Errorf("%s does not implement %s (wrong type for method %s)", check.typeString(V), check.typeString(T), m.name)
+		}
+		return fmt.Errorf("%s does not implement %s (missing method %s)", check.typeString(V), check.typeString(T), m.name)
+	}
+
+	// If T is comparable, V must be comparable.
+	if Ti.allComparable && !Comparable(V) {
+		return fmt.Errorf("%s does not implement comparable", check.typeString(V))
+	}
+
+	// V must also be in the set of types of T, if any.
+	if Ti.allTerms == nil {
+		return nil
+	}
+
+	// If V is itself a type parameter or an interface, its type set
+	// must be a subset of T's type set.
+	var Vterms termlist
+	switch v := Vu.(type) {
+	case *TypeParam:
+		Vterms = v.typeSet()
+	case *Interface:
+		check.completeInterface(v)
+		Vterms = v.allTerms
+	default:
+		if Ti.allTerms.includes(V) {
+			return nil
+		}
+		if len(Ti.allTerms) == 0 {
+			return fmt.Errorf("cannot satisfy %s (empty type set)", check.typeString(T))
+		}
+		return fmt.Errorf("%s does not implement %s (%s missing in %s)", check.typeString(V), check.typeString(T), check.typeString(V), Ti.allTerms)
+	}
+	if Vterms == nil || !Vterms.subsetOf(Ti.allTerms) {
+		return fmt.Errorf("%s does not implement %s", check.typeString(V), check.typeString(T))
+	}
+	return nil
+}
+
+// typeString returns the string representation of typ, qualified
+// relative to the package being checked. check may be nil.
+func (check *Checker) typeString(typ Type) string {
+	if check == nil {
+		return TypeString(typ, nil)
+	}
+	return TypeString(typ, check.qualifier)
+}
@@ -54,7 +54,7 @@ func (check *Checker) LookupFieldOrMethod(T Type, addressable bool, pkg *Package
 	// pointer type but discard the result if it is a method since we would
 	// not have found it for T (see also issue 8590).
 	if t, _ := T.(*Named); t != nil {
-		if p, _ := t.underlying.(*Pointer); p != nil {
+		if p, _ := t.Underlying().(*Pointer); p != nil {
 			obj, index, indirect = check.lookupFieldOrMethod(p, false, pkg, name)
 			if _, ok := obj.(*Func); ok {
 				return nil, nil, false
@@ -82,8 +82,8 @@ func (check *Checker) lookupFieldOrMethod(T Type, addressable bool, pkg *Package
 
 	typ, isPtr := deref(T)
 
-	// *typ where typ is an interface has no methods.
-	if isPtr && IsInterface(typ) {
+	// *typ where typ is an interface or type parameter has no methods.
+	if isPtr && (IsInterface(typ) || isTypeParam(typ)) {
 		return
 	}
 
@@ -124,7 +124,7 @@ func (check *Checker) lookupFieldOrMethod(T Type, addressable bool, pkg *Package
 				seen[named] = true
 
 				// look for a matching attached method
-				if i, m := lookupMethod(named.methods, pkg, name); m != nil {
+				if i, m := lookupMethod(named.methodList(), pkg, name); m != nil {
 					// potential match
 					// caution: method may not have a proper signature yet
 					index = concat(e.index, i)
@@ -137,7 +137,7 @@ func (check *Checker) lookupFieldOrMethod(T Type, addressable bool, pkg *Package
 				}
 
 				// continue with underlying type
-				typ = named.underlying
+				typ = named.Underlying()
 			}
 
 			switch t := typ.(type) {
@@ -185,6 +185,18 @@ func (check *Checker) lookupFieldOrMethod(T Type, addressable bool, pkg *Package
 					obj = m
 					indirect = e.indirect
 				}
+
+			case *TypeParam:
+				// look for a matching method of the type parameter's constraint
+				if i, m := lookupMethod(t.iface().allMethods, pkg, name); m != nil {
+					assert(m.typ != nil)
+					index = concat(e.index, i)
+					if obj != nil || e.multiples {
+						return nil, index, false // collision
+					}
+					obj = m
+					indirect = e.indirect
+				}
 			}
 		}
 
@@ -78,8 +78,8 @@ func NewMethodSet(T Type) *MethodSet {
 
 	typ, isPtr := deref(T)
 
-	// *typ where typ is an interface has no methods.
-	if isPtr && IsInterface(typ) {
+	// *typ where typ is an interface or type parameter has no methods.
+	if isPtr && (IsInterface(typ) || isTypeParam(typ)) {
 		return &emptyMethodSet
 	}
 
@@ -122,10 +122,10 @@ func NewMethodSet(T Type) *MethodSet {
 				}
 				seen[named] = true
 
-				mset = mset.add(named.methods, e.index, e.indirect, e.multiples)
+				mset = mset.add(named.methodList(), e.index, e.indirect, e.multiples)
 
 				// continue with underlying type
-				typ = named.underlying
+				typ = named.Underlying()
 			}
 
 			switch t := typ.(type) {
@@ -148,6 +148,9 @@ func NewMethodSet(T Type) *MethodSet {
 
 			case *Interface:
 				mset = mset.add(t.allMethods, e.index, true, e.multiples)
+
+			case *TypeParam:
+				mset = mset.add(t.iface().allMethods, e.index, true, e.multiples)
 			}
 		}
 
@@ -222,6 +222,12 @@ func (x *operand) assignableTo(check *Checker, T Type, reason *string) bool {
 	// TODO(gri) This is borrowing from checker.convertUntyped and
 	//           checker.representable. Need to clean up.
 	if isUntyped(Vu) {
+		if t, _ := T.(*TypeParam); t != nil {
+			// x must be assignable to each specific type in T's type set
+			return t.is(func(t *term) bool {
+				return t != nil && x.assignableTo(check, t.typ, nil)
+			})
+		}
 		switch t := Tu.(type) {
 		case *Basic:
 			if x.isNil() && t.kind == UnsafePointer {
@@ -274,5 +280,26 @@ func (x *operand) assignableTo(check *Checker, T Type, reason *string) bool {
 		}
 	}
 
+	// T is a type parameter, x's type V is not a named type, and
+	// x is assignable to each specific type in T's type set
+	if Tp, _ := T.(*TypeParam); Tp != nil && !isNamed(V) {
+		return Tp.is(func(t *term) bool {
+			return t != nil && x.assignableTo(check, t.typ, reason)
+		})
+	}
+
+	// x's type V is a type parameter, T is not a named type, and
+	// values of each specific type in V's type set are assignable to T
+	if Vp, _ := V.(*TypeParam); Vp != nil && !isNamed(T) {
+		x := *x // don't clobber outer x
+		return Vp.is(func(v *term) bool {
+			if v == nil {
+				return false
+			}
+			x.typ = v.typ
+			return x.assignableTo(check, T, reason)
+		})
+	}
+
 	return false
 }
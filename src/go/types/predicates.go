@@ -6,49 +6,49 @@
 
 package types
 
-import "sort"
+import (
+	"go/token"
+	"sort"
+)
 
+// isNamed reports whether typ has a name: basic types, defined
+// types, and type parameters are named types.
 func isNamed(typ Type) bool {
-	if _, ok := typ.(*Basic); ok {
-		return ok
+	switch typ.(type) {
+	case *Basic, *Named, *TypeParam:
+		return true
 	}
-	_, ok := typ.(*Named)
-	return ok
-}
-
-func isBoolean(typ Type) bool {
-	t, ok := typ.Underlying().(*Basic)
-	return ok && t.info&IsBoolean != 0
-}
-
-func isInteger(typ Type) bool {
-	t, ok := typ.Underlying().(*Basic)
-	return ok && t.info&IsInteger != 0
-}
-
-func isUnsigned(typ Type) bool {
-	t, ok := typ.Underlying().(*Basic)
-	return ok && t.info&IsUnsigned != 0
-}
-
-func isFloat(typ Type) bool {
-	t, ok := typ.Underlying().(*Basic)
-	return ok && t.info&IsFloat != 0
-}
-
-func isComplex(typ Type) bool {
-	t, ok := typ.Underlying().(*Basic)
-	return ok && t.info&IsComplex != 0
+	return false
 }
 
-func isNumeric(typ Type) bool {
-	t, ok := typ.Underlying().(*Basic)
-	return ok && t.info&IsNumeric != 0
+// isTypeParam reports whether typ is a type parameter.
+func isTypeParam(typ Type) bool {
+	_, ok := typ.(*TypeParam)
+	return ok
 }
 
-func isString(typ Type) bool {
+func isBoolean(typ Type) bool  { return allBasic(typ, IsBoolean) }
+func isInteger(typ Type) bool  { return allBasic(typ, IsInteger) }
+func isUnsigned(typ Type) bool { return allBasic(typ, IsUnsigned) }
+func isFloat(typ Type) bool    { return allBasic(typ, IsFloat) }
+func isComplex(typ Type) bool  { return allBasic(typ, IsComplex) }
+func isNumeric(typ Type) bool  { return allBasic(typ, IsNumeric) }
+func isString(typ Type) bool   { return allBasic(typ, IsString) }
+func isOrdered(typ Type) bool  { return allBasic(typ, IsOrdered) }
+
+// allBasic reports whether the underlying type of typ is a basic type
+// with one of the given info bits set. If typ is a type parameter, allBasic
+// reports whether this is true for each type in the type parameter's type
+// set; a type parameter without specific types satisfies no info bits.
+func allBasic(typ Type, info BasicInfo) bool {
+	if tpar, _ := typ.(*TypeParam); tpar != nil {
+		return tpar.underIs(func(u Type) bool {
+			t, ok := u.(*Basic)
+			return ok && t.info&info != 0
+		})
+	}
 	t, ok := typ.Underlying().(*Basic)
-	return ok && t.info&IsString != 0
+	return ok && t.info&info != 0
 }
 
 func isTyped(typ Type) bool {
@@ -61,11 +61,6 @@ func isUntyped(typ Type) bool {
 	return ok && t.info&IsUntyped != 0
 }
 
-func isOrdered(typ Type) bool {
-	t, ok := typ.Underlying().(*Basic)
-	return ok && t.info&IsOrdered != 0
-}
-
 func isConstType(typ Type) bool {
 	t, ok := typ.Underlying().(*Basic)
 	return ok && t.info&IsConstType != 0
@@ -95,6 +90,8 @@ func Comparable(T Type) bool {
 		return true
 	case *Array:
 		return Comparable(t.elem)
+	case *TypeParam:
+		return t.iface().IsComparable()
 	}
 	return false
 }
@@ -106,6 +103,11 @@ func hasNil(typ Type) bool {
 		return t.kind == UnsafePointer
 	case *Slice, *Pointer, *Signature, *Interface, *Map, *Chan:
 		return true
+	case *TypeParam:
+		// A type parameter includes nil if all types in its type set do.
+		return t.underIs(func(u Type) bool {
+			return u != nil && hasNil(u)
+		})
 	}
 	return false
 }
@@ -210,11 +212,44 @@ func (check *Checker) identical0(x, y Type, cmpTags bool, p *ifacePair) bool {
 		// and either both functions are variadic or neither is. Parameter and result
 		// names are not required to match.
 		if y, ok := y.(*Signature); ok {
+			// Generic signatures are identical if they have the same
+			// number of type parameters with identical constraints, and
+			// if they are identical once y's type parameters are renamed
+			// to x's type parameters.
+			if x.tparams.Len() != y.tparams.Len() {
+				return false
+			}
+			if x.tparams.Len() > 0 {
+				xtparams := x.tparams.list()
+				targs := make([]Type, len(xtparams))
+				for i, tpar := range xtparams {
+					targs[i] = tpar
+				}
+				ytparams := y.tparams.list()
+				smap := makeSubstMap(ytparams, targs)
+				ctxt := NewContext()
+				for i, tpar := range xtparams {
+					if !check.identical0(tpar.bound, check.subst(token.NoPos, ytparams[i].bound, smap, ctxt), cmpTags, p) {
+						return false
+					}
+				}
+				yparams := check.subst(token.NoPos, y.params, smap, ctxt)
+				yresults := check.subst(token.NoPos, y.results, smap, ctxt)
+				return x.variadic == y.variadic &&
+					check.identical0(x.params, yparams, cmpTags, p) &&
+					check.identical0(x.results, yresults, cmpTags, p)
+			}
 			return x.variadic == y.variadic &&
 				check.identical0(x.params, y.params, cmpTags, p) &&
 				check.identical0(x.results, y.results, cmpTags, p)
 		}
 
+	case *Union:
+		// Two union types are identical if they describe the same type set.
+		if y, ok := y.(*Union); ok {
+			return check.unionTerms(x).equal(check.unionTerms(y))
+		}
+
 	case *Interface:
 		// Two interface types are identical if they have the same set of methods with
 		// the same names and identical function types. Lower-case method names from
@@ -228,6 +263,11 @@ func (check *Checker) identical0(x, y Type, cmpTags bool, p *ifacePair) bool {
 				check.completeInterface(x)
 				check.completeInterface(y)
 			}
+			// Interfaces that restrict their type sets must restrict
+			// them to the same type sets.
+			if x.allComparable != y.allComparable || !identicalTerms(x.allTerms, y.allTerms) {
+				return false
+			}
 			a := x.allMethods
 			b := y.allMethods
 			if len(a) == len(b) {
@@ -289,11 +329,29 @@ func (check *Checker) identical0(x, y Type, cmpTags bool, p *ifacePair) bool {
 
 	case *Named:
 		// Two named types are identical if their type names originate
-		// in the same type declaration.
+		// in the same type declaration; instances of a generic type
+		// must also have identical type arguments.
 		if y, ok := y.(*Named); ok {
-			return x.obj == y.obj
+			if x.obj != y.obj {
+				return false
+			}
+			xargs := x.targs.list()
+			yargs := y.targs.list()
+			if len(xargs) != len(yargs) {
+				return false
+			}
+			for i, xa := range xargs {
+				if !check.identical0(xa, yargs[i], cmpTags, p) {
+					return false
+				}
+			}
+			return true
 		}
 
+	case *TypeParam:
+		// Two type parameters are identical only if they are the
+		// same type parameter (x == y, handled above).
+
 	case nil:
 
 	default:
@@ -303,6 +361,15 @@ func (check *Checker) identical0(x, y Type, cmpTags bool, p *ifacePair) bool {
 	return false
 }
 
+// identicalTerms reports whether the type set restrictions x and y
+// of two completed interfaces are the same; nil means no restriction.
+func identicalTerms(x, y termlist) bool {
+	if x == nil || y == nil {
+		return x == nil && y == nil
+	}
+	return x.equal(y)
+}
+
 // Default returns the default "typed" type for an "untyped" type;
 // it returns the incoming type for all other types. The default type
 // for untyped nil is untyped nil.
@@ -326,3 +393,64 @@ func Default(typ Type) Type {
 	}
 	return typ
 }
+
+// coreType returns the core type of t: If t is not a type parameter,
+// coreType returns the underlying type of t. If t is a type parameter,
+// coreType returns the single underlying type of all types in its type
+// set if it exists, or nil otherwise. If the type set contains only
+// unrestricted and restricted channel types (with identical element
+// types), the single underlying type is the restricted channel type if
+// the restrictions are always the same, or nil otherwise.
+func coreType(t Type) Type {
+	tpar, _ := t.(*TypeParam)
+	if tpar == nil {
+		return t.Underlying()
+	}
+
+	var su Type
+	if tpar.underIs(func(u Type) bool {
+		if u == nil {
+			return false
+		}
+		if su != nil {
+			u = match(su, u)
+			if u == nil {
+				return false
+			}
+		}
+		// su == nil || match(su, u) != nil
+		su = u
+		return true
+	}) {
+		return su
+	}
+	return nil
+}
+
+// match reports whether x and y are identical types or channel types with
+// identical element types and the same or no direction restrictions. It
+// returns the more restrictive of the two types in the latter case, and
+// nil if the types don't match.
+func match(x, y Type) Type {
+	// Common case: we don't have channels.
+	if Identical(x, y) {
+		return x
+	}
+
+	// We may have channels that differ in direction only.
+	if x, _ := x.(*Chan); x != nil {
+		if y, _ := y.(*Chan); y != nil && Identical(x.elem, y.elem) {
+			// We have channels that differ in direction only.
+			// If there's an unrestricted channel, select the restricted one.
+			switch {
+			case x.dir == SendRecv:
+				return y
+			case y.dir == SendRecv:
+				return x
+			}
+		}
+	}
+
+	// types are different
+	return nil
+}
@@ -21,6 +21,7 @@ type declInfo struct {
 	lhs   []*Var        // lhs of n:1 variable declarations, or nil
 	typ   ast.Expr      // type, or nil
 	init  ast.Expr      // init/orig expression, or nil
+	tdecl *ast.TypeSpec // type declaration, or nil
 	fdecl *ast.FuncDecl // func declaration, or nil
 	alias bool          // type alias declaration
 
@@ -385,7 +386,7 @@ func (check *Checker) collectObjects() {
 
 					case *ast.TypeSpec:
 						obj := NewTypeName(s.Name.Pos(), pkg, s.Name.Name, nil)
-						check.declarePkgObj(s.Name, obj, &declInfo{file: fileScope, typ: s.Type, alias: s.Assign.IsValid()})
+						check.declarePkgObj(s.Name, obj, &declInfo{file: fileScope, tdecl: s, typ: s.Type, alias: s.Assign.IsValid()})
 
 					default:
 						check.invalidAST(s.Pos(), "unknown ast.Spec node %T", s)
@@ -397,6 +398,9 @@ func (check *Checker) collectObjects() {
 				obj := NewFunc(d.Name.Pos(), pkg, name, nil)
 				if d.Recv == nil {
 					// regular function
+					if (name == "init" || name == "main" && pkg.name == "main") && d.Type.TypeParams != nil {
+						check.softErrorf(d.Type.TypeParams.Pos(), "func %s must have no type parameters", name)
+					}
 					if name == "init" {
 						// don't declare init functions in the package scope - they are invisible
 						obj.parent = pkg.scope
@@ -494,6 +498,18 @@ func (check *Checker) resolveBaseTypeName(typ ast.Expr) (ptr bool, base *TypeNam
 			typ = unparen(pexpr.X) // continue with pointer base type
 		}
 
+		// The receiver of a method of a generic type has the form
+		// T[P] or *T[P, Q]; continue with T. Methods cannot be
+		// associated with instantiated types via alias names.
+		if seen == nil {
+			switch x := typ.(type) {
+			case *ast.IndexExpr:
+				typ = unparen(x.X)
+			case *ast.IndexListExpr:
+				typ = unparen(x.X)
+			}
+		}
+
 		// typ must be a name
 		name, _ := typ.(*ast.Ident)
 		if name == nil {
@@ -168,7 +168,7 @@ func assignOp(op token.Token) token.Token {
 func (check *Checker) suspendedCall(keyword string, call *ast.CallExpr) {
 	var x operand
 	var msg string
-	switch check.rawExpr(&x, call, nil) {
+	switch check.rawExpr(&x, call, nil, false) {
 	case conversion:
 		msg = "requires function call, not conversion"
 	case expression:
@@ -322,7 +322,7 @@ func (check *Checker) stmt(ctxt stmtContext, s ast.Stmt) {
 		// function and method calls and receive operations can appear
 		// in statement context. Such statements may be parenthesized."
 		var x operand
-		kind := check.rawExpr(&x, s.X, nil)
+		kind := check.rawExpr(&x, s.X, nil, false)
 		var msg string
 		switch x.mode {
 		default:
@@ -345,7 +345,7 @@ func (check *Checker) stmt(ctxt stmtContext, s ast.Stmt) {
 			return
 		}
 
-		tch, ok := ch.typ.Underlying().(*Chan)
+		tch, ok := coreType(ch.typ).(*Chan)
 		if !ok {
 			check.invalidOp(s.Arrow, "cannot send to non-chan type %s", ch.typ)
 			return
@@ -746,7 +746,7 @@ func (check *Checker) stmt(ctxt stmtContext, s ast.Stmt) {
 		// determine key/value types
 		var key, val Type
 		if x.mode != invalid {
-			switch typ := x.typ.Underlying().(type) {
+			switch typ := coreType(x.typ).(type) {
 			case *Basic:
 				if isString(typ) {
 					key = Typ[Int]
@@ -777,6 +777,28 @@ func (check *Checker) stmt(ctxt stmtContext, s ast.Stmt) {
 					check.errorf(s.Value.Pos(), "iteration over %s permits only one iteration variable", &x)
 					// ok to continue
 				}
+			case *Signature:
+				var cause string
+				key, val, cause = rangeFuncTypes(typ)
+				if cause != "" {
+					check.errorf(x.pos(), "cannot range over %s: func must be func(yield func(...) bool): %s", &x, cause)
+					key = Typ[Invalid] // avoid follow-on error below
+					break
+				}
+				switch {
+				case key == nil && s.Key != nil:
+					check.errorf(s.Key.Pos(), "range over %s permits no iteration variables", &x)
+					// ok to continue
+				case val == nil && s.Value != nil:
+					check.errorf(s.Value.Pos(), "range over %s permits only one iteration variable", &x)
+					// ok to continue
+				}
+				if key == nil {
+					key = Typ[Invalid]
+				}
+				if val == nil {
+					val = Typ[Invalid]
+				}
 			}
 		}
 
@@ -864,3 +886,32 @@ func (check *Checker) stmt(ctxt stmtContext, s ast.Stmt) {
 		check.error(s.Pos(), "invalid statement")
 	}
 }
+
+// rangeFuncTypes returns the key and value types of a range over an
+// iterator function of type sig, either of which is nil if the yield
+// function has fewer parameters. If sig is not a valid iterator type,
+// the result is the reason why.
+func rangeFuncTypes(sig *Signature) (key, val Type, cause string) {
+	switch {
+	case sig.params.Len() != 1:
+		return nil, nil, "wrong argument count"
+	case sig.results.Len() != 0:
+		return nil, nil, "wrong result count"
+	}
+	yield, _ := sig.params.At(0).typ.Underlying().(*Signature)
+	switch {
+	case yield == nil:
+		return nil, nil, "argument is not func"
+	case yield.params.Len() > 2:
+		return nil, nil, "yield func has too many parameters"
+	case yield.results.Len() != 1 || !Identical(yield.results.At(0).typ, Typ[Bool]):
+		return nil, nil, "yield func does not return bool"
+	}
+	if yield.params.Len() > 0 {
+		key = yield.params.At(0).typ
+	}
+	if yield.params.Len() > 1 {
+		val = yield.params.At(1).typ
+	}
+	return key, val, ""
+}
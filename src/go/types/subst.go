@@ -0,0 +1,300 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file implements type parameter substitution.
+
+package types
+
+import "go/token"
+
+// A substMap maps type parameters to the types they are replaced with.
+type substMap map[*TypeParam]Type
+
+// makeSubstMap creates a new substitution map mapping tparams[i] to targs[i].
+// If targs[i] is nil, tparams[i] is not substituted.
+func makeSubstMap(tparams []*TypeParam, targs []Type) substMap {
+	assert(len(tparams) == len(targs))
+	smap := make(substMap, len(tparams))
+	for i, tpar := range tparams {
+		smap[tpar] = targs[i]
+	}
+	return smap
+}
+
+func (m substMap) lookup(tpar *TypeParam) Type {
+	if t := m[tpar]; t != nil {
+		return t
+	}
+	return tpar
+}
+
+// subst returns the type typ with its type parameters replaced by the
+// corresponding types in smap, recursively. subst doesn't modify the
+// incoming type. If a substitution took place, the result type is
+// different from the incoming type. Instances of generic types are
+// canonicalized through ctxt, which must not be nil. check may be nil
+// if subst is invoked through an exported API call.
+func (check *Checker) subst(pos token.Pos, typ Type, smap substMap, ctxt *Context) Type {
+	if len(smap) == 0 {
+		return typ
+	}
+
+	// common cases
+	switch t := typ.(type) {
+	case *Basic:
+		return typ // nothing to do
+	case *TypeParam:
+		return smap.lookup(t)
+	}
+
+	// general case
+	subst := subster{pos, smap, check, ctxt}
+	return subst.typ(typ)
+}
+
+type subster struct {
+	pos   token.Pos
+	smap  substMap
+	check *Checker // nil if called via Instantiate
+	ctxt  *Context
+}
+
+func (subst *subster) typ(typ Type) Type {
+	switch t := typ.(type) {
+	case nil:
+		// a nil type may appear in erroneous programs where
+		// a type is used before it is set up
+		return Typ[Invalid]
+
+	case *Basic:
+		// nothing to do
+
+	case *Array:
+		elem := subst.typ(t.elem)
+		if elem != t.elem {
+			return &Array{len: t.len, elem: elem}
+		}
+
+	case *Slice:
+		elem := subst.typ(t.elem)
+		if elem != t.elem {
+			return &Slice{elem: elem}
+		}
+
+	case *Struct:
+		if fields, copied := subst.varList(t.fields); copied {
+			return &Struct{fields: fields, tags: t.tags}
+		}
+
+	case *Pointer:
+		base := subst.typ(t.base)
+		if base != t.base {
+			return &Pointer{base: base}
+		}
+
+	case *Tuple:
+		return subst.tuple(t)
+
+	case *Signature:
+		// The receiver is preserved: it is handled during *Interface
+		// and *Named type substitution. Substituting it here would
+		// recurse endlessly for interface methods whose receiver is
+		// the interface itself.
+		params := subst.tuple(t.params)
+		results := subst.tuple(t.results)
+		if params != t.params || results != t.results {
+			return &Signature{
+				rparams: t.rparams,
+				tparams: t.tparams,
+				// instantiated signatures have a nil scope
+				recv:     t.recv,
+				params:   params,
+				results:  results,
+				variadic: t.variadic,
+			}
+		}
+
+	case *Union:
+		if terms, copied := subst.termList(t.terms); copied {
+			return &Union{terms}
+		}
+
+	case *Interface:
+		methods, mcopied := subst.funcList(t.methods)
+		embeddeds, ecopied := subst.typeList(t.embeddeds)
+		if mcopied || ecopied {
+			iface := &Interface{embeddeds: embeddeds, implicit: t.implicit}
+			// Methods whose receiver is the original interface
+			// must have the new interface as receiver.
+			iface.methods, _ = replaceRecvType(methods, t, iface)
+			if t.allMethods != nil {
+				iface.Complete()
+			}
+			return iface
+		}
+
+	case *Map:
+		key := subst.typ(t.key)
+		elem := subst.typ(t.elem)
+		if key != t.key || elem != t.elem {
+			return &Map{key: key, elem: elem}
+		}
+
+	case *Chan:
+		elem := subst.typ(t.elem)
+		if elem != t.elem {
+			return &Chan{dir: t.dir, elem: elem}
+		}
+
+	case *Named:
+		// Only instances of generic types contain type parameters.
+		// They are substituted by instantiating the origin type with
+		// the substituted type arguments. Don't expand t here; the
+		// instance may be the one currently being expanded.
+		if t.targs.Len() == 0 {
+			return t
+		}
+		if targs, copied := subst.typeList(t.targs.list()); copied {
+			return subst.check.instance(subst.pos, t.orig, targs, subst.ctxt)
+		}
+
+	case *TypeParam:
+		return subst.smap.lookup(t)
+
+	default:
+		panic("unreachable")
+	}
+
+	return typ
+}
+
+func (subst *subster) var_(v *Var) *Var {
+	if v != nil {
+		if typ := subst.typ(v.typ); typ != v.typ {
+			copy := *v
+			copy.typ = typ
+			return &copy
+		}
+	}
+	return v
+}
+
+func (subst *subster) tuple(t *Tuple) *Tuple {
+	if t != nil {
+		if vars, copied := subst.varList(t.vars); copied {
+			return &Tuple{vars: vars}
+		}
+	}
+	return t
+}
+
+func (subst *subster) varList(in []*Var) (out []*Var, copied bool) {
+	out = in
+	for i, v := range in {
+		if w := subst.var_(v); w != v {
+			if !copied {
+				// first variable that got substituted => allocate new out slice
+				// and copy all variables
+				out = make([]*Var, len(in))
+				copy(out, in)
+				copied = true
+			}
+			out[i] = w
+		}
+	}
+	return
+}
+
+func (subst *subster) func_(f *Func) *Func {
+	if f != nil {
+		if typ := subst.typ(f.typ); typ != f.typ {
+			copy := *f
+			copy.typ = typ
+			return &copy
+		}
+	}
+	return f
+}
+
+func (subst *subster) funcList(in []*Func) (out []*Func, copied bool) {
+	out = in
+	for i, f := range in {
+		if g := subst.func_(f); g != f {
+			if !copied {
+				// first function that got substituted => allocate new out slice
+				// and copy all functions
+				out = make([]*Func, len(in))
+				copy(out, in)
+				copied = true
+			}
+			out[i] = g
+		}
+	}
+	return
+}
+
+func (subst *subster) typeList(in []Type) (out []Type, copied bool) {
+	out = in
+	for i, t := range in {
+		if u := subst.typ(t); u != t {
+			if !copied {
+				// first type that got substituted => allocate new out slice
+				// and copy all types
+				out = make([]Type, len(in))
+				copy(out, in)
+				copied = true
+			}
+			out[i] = u
+		}
+	}
+	return
+}
+
+func (subst *subster) termList(in []*Term) (out []*Term, copied bool) {
+	out = in
+	for i, t := range in {
+		if u := subst.typ(t.typ); u != t.typ {
+			if !copied {
+				// first term that got substituted => allocate new out slice
+				// and copy all terms
+				out = make([]*Term, len(in))
+				copy(out, in)
+				copied = true
+			}
+			out[i] = NewTerm(t.tilde, u)
+		}
+	}
+	return
+}
+
+// replaceRecvType updates any function receivers that have type old to have
+// type new. It does not modify the input slice; if modifications are required,
+// the input slice and any affected signatures will be copied before mutating.
+//
+// The resulting out slice contains the updated functions, and copied reports
+// if anything was modified.
+func replaceRecvType(in []*Func, old, new Type) (out []*Func, copied bool) {
+	out = in
+	for i, method := range in {
+		sig := method.typ.(*Signature)
+		if sig.recv != nil && sig.recv.typ == old {
+			if !copied {
+				// Allocate a new methods slice before mutating for the first time.
+				// Methods may be shared across instantiations of a given
+				// interface type if they do not get substituted.
+				out = make([]*Func, len(in))
+				copy(out, in)
+				copied = true
+			}
+			newsig := *sig
+			recv := *sig.recv
+			recv.typ = new
+			newsig.recv = &recv
+			m := *method
+			m.typ = &newsig
+			out[i] = &m
+		}
+	}
+	return
+}
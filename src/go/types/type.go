@@ -199,11 +199,13 @@ type Signature struct {
 	// and store it in the Func Object) because when type-checking a function
 	// literal we call the general type checker which returns a general Type.
 	// We then unpack the *Signature and use the scope for the literal body.
-	scope    *Scope // function scope, present for package-local signatures
-	recv     *Var   // nil if not a method
-	params   *Tuple // (incoming) parameters from left to right; or nil
-	results  *Tuple // (outgoing) results from left to right; or nil
-	variadic bool   // true if the last parameter's type is of the form ...T (or string, for append built-in only)
+	scope    *Scope         // function scope, present for package-local signatures
+	recv     *Var           // nil if not a method
+	rparams  *TypeParamList // receiver type parameters from left to right, or nil
+	tparams  *TypeParamList // type parameters from left to right, or nil
+	params   *Tuple         // (incoming) parameters from left to right; or nil
+	results  *Tuple         // (outgoing) results from left to right; or nil
+	variadic bool           // true if the last parameter's type is of the form ...T (or string, for append built-in only)
 }
 
 // NewSignature returns a new function type for the given receiver, parameters,
@@ -220,7 +222,29 @@ func NewSignature(recv *Var, params, results *Tuple, variadic bool) *Signature {
 			panic("types.NewSignature: variadic parameter must be of unnamed slice type")
 		}
 	}
-	return &Signature{nil, recv, params, results, variadic}
+	return &Signature{recv: recv, params: params, results: results, variadic: variadic}
+}
+
+// NewSignatureType creates a new function type for the given receiver,
+// receiver type parameters, type parameters, parameters, and results. If
+// variadic is set, params must hold at least one parameter and the last
+// parameter must be of unnamed slice type. If recv is non-nil, typeParams
+// must be empty. If recvTypeParams is non-empty, recv must be non-nil.
+func NewSignatureType(recv *Var, recvTypeParams, typeParams []*TypeParam, params, results *Tuple, variadic bool) *Signature {
+	sig := NewSignature(recv, params, results, variadic)
+	if len(recvTypeParams) != 0 {
+		if recv == nil {
+			panic("types.NewSignatureType: function with receiver type parameters must have a receiver")
+		}
+		sig.rparams = bindTParams(recvTypeParams)
+	}
+	if len(typeParams) != 0 {
+		if recv != nil {
+			panic("types.NewSignatureType: function with type parameters cannot have a receiver")
+		}
+		sig.tparams = bindTParams(typeParams)
+	}
+	return sig
 }
 
 // Recv returns the receiver of signature s (if a method), or nil if a
@@ -231,6 +255,12 @@ func NewSignature(recv *Var, params, results *Tuple, variadic bool) *Signature {
 // contain methods whose receiver type is a different interface.
 func (s *Signature) Recv() *Var { return s.recv }
 
+// TypeParams returns the type parameters of signature s, or nil.
+func (s *Signature) TypeParams() *TypeParamList { return s.tparams }
+
+// RecvTypeParams returns the receiver type parameters of signature s, or nil.
+func (s *Signature) RecvTypeParams() *TypeParamList { return s.rparams }
+
 // Params returns the parameters of signature s, or nil.
 func (s *Signature) Params() *Tuple { return s.params }
 
@@ -243,9 +273,12 @@ func (s *Signature) Variadic() bool { return s.variadic }
 // An Interface represents an interface type.
 type Interface struct {
 	methods   []*Func // ordered list of explicitly declared methods
-	embeddeds []Type  // ordered list of explicitly embedded types
+	embeddeds []Type  // ordered list of explicitly embedded elements
+	implicit  bool    // interface is the implicit wrapper of a constraint such as ~int or A|B
 
-	allMethods []*Func // ordered list of methods declared with or embedded in this interface (TODO(gri): replace with mset)
+	allMethods    []*Func  // ordered list of methods declared with or embedded in this interface (TODO(gri): replace with mset)
+	allTerms      termlist // terms restricting the type set of the interface; nil if there are none
+	allComparable bool     // the type set of the interface only contains comparable types
 }
 
 // emptyInterface represents the empty (completed) interface
@@ -272,9 +305,8 @@ func NewInterface(methods []*Func, embeddeds []*Named) *Interface {
 }
 
 // NewInterfaceType returns a new (incomplete) interface for the given methods and embedded types.
-// Each embedded type must have an underlying type of interface type (this property is not
-// verified for defined types, which may be in the process of being set up and which don't
-// have a valid underlying type yet).
+// Embedded types are typically interfaces; an interface that is only used as a type constraint
+// may also embed other types and unions (*Union) of types, which restrict its type set.
 // NewInterfaceType takes ownership of the provided methods and may modify their types by setting
 // missing receivers. To compute the method set of the interface, Complete must be called.
 func NewInterfaceType(methods []*Func, embeddeds []Type) *Interface {
@@ -290,16 +322,6 @@ func NewInterfaceType(methods []*Func, embeddeds []Type) *Interface {
 		}
 	}
 
-	// All embedded types should be interfaces; however, defined types
-	// may not yet be fully resolved. Only verify that non-defined types
-	// are interfaces. This matches the behavior of the code before the
-	// fix for #25301 (issue #25596).
-	for _, t := range embeddeds {
-		if _, ok := t.(*Named); !ok && !IsInterface(t) {
-			panic("embedded type is not an interface")
-		}
-	}
-
 	// sort for API stability
 	sort.Sort(byUniqueMethodName(methods))
 	sort.Stable(byUniqueTypeName(embeddeds))
@@ -345,7 +367,33 @@ func (t *Interface) Method(i int) *Func { t.assertCompleteness(); return t.allMe
 
 // Empty reports whether t is the empty interface.
 // The interface must have been completed.
-func (t *Interface) Empty() bool { t.assertCompleteness(); return len(t.allMethods) == 0 }
+func (t *Interface) Empty() bool {
+	t.assertCompleteness()
+	return len(t.allMethods) == 0 && t.allTerms == nil && !t.allComparable
+}
+
+// IsComparable reports whether each type in the type set of interface t is comparable.
+// The interface must have been completed.
+func (t *Interface) IsComparable() bool {
+	t.assertCompleteness()
+	return t.allComparable || t.allTerms != nil && t.allTerms.all(func(x *term) bool { return Comparable(x.typ) })
+}
+
+// IsMethodSet reports whether the interface t is fully described by its method set.
+// The interface must have been completed.
+func (t *Interface) IsMethodSet() bool {
+	t.assertCompleteness()
+	return t.allTerms == nil && !t.allComparable
+}
+
+// IsImplicit reports whether the interface t is a wrapper for a type set literal.
+func (t *Interface) IsImplicit() bool { return t.implicit }
+
+// MarkImplicit marks the interface t as implicit, meaning this interface
+// corresponds to a constraint literal such as ~T or A|B without explicit
+// interface embedding. MarkImplicit should be called before any concurrent use
+// of implicit interfaces.
+func (t *Interface) MarkImplicit() { t.implicit = true }
 
 // Complete computes the interface's method set. It must be called by users of
 // NewInterfaceType and NewInterface after the interface's embedded types are
@@ -379,13 +427,17 @@ func (t *Interface) Complete() *Interface {
 		addMethod(m, true)
 	}
 
+	var tset typeSet
 	for _, typ := range t.embeddeds {
-		typ := typ.Underlying().(*Interface)
-		typ.Complete()
-		for _, m := range typ.allMethods {
-			addMethod(m, false)
+		if typ, _ := typ.Underlying().(*Interface); typ != nil {
+			typ.Complete()
+			for _, m := range typ.allMethods {
+				addMethod(m, false)
+			}
 		}
+		tset.embed(nil, typ)
 	}
+	tset.setTerms(t)
 
 	for i := 0; i < len(todo); i += 2 {
 		m := todo[i]
@@ -448,11 +500,16 @@ func (c *Chan) Elem() Type { return c.elem }
 
 // A Named represents a named type.
 type Named struct {
-	info       typeInfo  // for cycle detection
-	obj        *TypeName // corresponding declared object
-	orig       Type      // type (on RHS of declaration) this *Named type is derived of (for cycle reporting)
-	underlying Type      // possibly a *Named during setup; never a *Named once set up completely
-	methods    []*Func   // methods declared for this type (not the method set of this type); signatures are type-checked lazily
+	check      *Checker       // for lazy set up of instance methods; nil for types not created by a Checker
+	ctxt       *Context       // for canonicalizing types created while expanding an instance; nil if t is not an instance
+	info       typeInfo       // for cycle detection
+	obj        *TypeName      // corresponding declared object
+	orig       *Named         // original, uninstantiated type; t itself if t is not an instance
+	fromRHS    Type           // type (on RHS of declaration) this *Named type is derived of (for cycle reporting)
+	underlying Type           // possibly a *Named during setup; never a *Named once set up completely
+	tparams    *TypeParamList // type parameters, or nil
+	targs      *TypeList      // type arguments (after instantiation), or nil
+	methods    []*Func        // methods declared for this type (not the method set of this type); signatures are type-checked lazily
 }
 
 // NewNamed returns a new named type for the given type name, underlying type, and associated methods.
@@ -462,7 +519,8 @@ func NewNamed(obj *TypeName, underlying Type, methods []*Func) *Named {
 	if _, ok := underlying.(*Named); ok {
 		panic("types.NewNamed: underlying type must not be *Named")
 	}
-	typ := &Named{obj: obj, orig: underlying, underlying: underlying, methods: methods}
+	typ := &Named{obj: obj, fromRHS: underlying, underlying: underlying, methods: methods}
+	typ.orig = typ
 	if obj.typ == nil {
 		obj.typ = typ
 	}
@@ -470,16 +528,48 @@ func NewNamed(obj *TypeName, underlying Type, methods []*Func) *Named {
 }
 
 // Obj returns the type name for the named type t.
+// For an instantiated type, it is the type name of the origin type.
 func (t *Named) Obj() *TypeName { return t.obj }
 
+// Origin returns the generic type from which the named type t is
+// instantiated. If t is not an instantiated type, the result is t.
+func (t *Named) Origin() *Named { return t.orig }
+
+// TypeParams returns the type parameters of the named type t, or nil.
+// The result is non-nil for an (originally) generic type even if it is instantiated.
+func (t *Named) TypeParams() *TypeParamList { return t.orig.tparams }
+
+// SetTypeParams sets the type parameters of the named type t.
+// t must not have type arguments.
+func (t *Named) SetTypeParams(tparams []*TypeParam) {
+	if t.targs != nil {
+		panic("types.Named.SetTypeParams: type must not be an instance")
+	}
+	t.tparams = bindTParams(tparams)
+}
+
+// TypeArgs returns the type arguments used to instantiate the named type t.
+func (t *Named) TypeArgs() *TypeList { return t.targs }
+
 // NumMethods returns the number of explicit methods whose receiver is named type t.
-func (t *Named) NumMethods() int { return len(t.methods) }
+func (t *Named) NumMethods() int { return len(t.orig.methods) }
 
 // Method returns the i'th method of named type t for 0 <= i < t.NumMethods().
-func (t *Named) Method(i int) *Func { return t.methods[i] }
+// For an instantiated type, the receiver and signature of the method are
+// those of the origin's method with the type arguments substituted.
+func (t *Named) Method(i int) *Func {
+	if t.orig == t {
+		return t.methods[i]
+	}
+	return t.methodList()[i]
+}
 
 // SetUnderlying sets the underlying type and marks t as complete.
+// t must not have type arguments.
 func (t *Named) SetUnderlying(underlying Type) {
+	if t.targs != nil {
+		panic("types.Named.SetUnderlying: type must not be an instance")
+	}
 	if underlying == nil {
 		panic("types.Named.SetUnderlying: underlying type must not be nil")
 	}
@@ -490,7 +580,11 @@ func (t *Named) SetUnderlying(underlying Type) {
 }
 
 // AddMethod adds method m unless it is already in the method list.
+// t must not have type arguments.
 func (t *Named) AddMethod(m *Func) {
+	if t.targs != nil {
+		panic("types.Named.AddMethod: type must not be an instance")
+	}
 	if i, _ := lookupMethod(t.methods, m.pkg, m.name); i < 0 {
 		t.methods = append(t.methods, m)
 	}
@@ -508,7 +602,7 @@ func (s *Signature) Underlying() Type { return s }
 func (t *Interface) Underlying() Type { return t }
 func (m *Map) Underlying() Type       { return m }
 func (c *Chan) Underlying() Type      { return c }
-func (t *Named) Underlying() Type     { return t.underlying }
+func (t *Named) Underlying() Type     { return t.expand().underlying }
 
 func (b *Basic) String() string     { return TypeString(b, nil) }
 func (a *Array) String() string     { return TypeString(a, nil) }
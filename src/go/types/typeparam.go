@@ -0,0 +1,205 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package types
+
+import (
+	"bytes"
+	"sync/atomic"
+)
+
+// lastID is used to number type parameters that are not
+// created by a Checker.
+var lastID uint64
+
+// nextID returns a value increasing monotonically by 1 with
+// each call, starting with 1. It may be called concurrently.
+func nextID() uint64 { return atomic.AddUint64(&lastID, 1) }
+
+// A TypeParam represents a type parameter of a generic type or function.
+// A type parameter has no underlying type other than itself; the set of
+// types it stands for is described by its constraint.
+type TypeParam struct {
+	check *Checker  // for lazy constraint completion; nil if not created by a Checker
+	id    uint64    // unique id, for debugging only
+	obj   *TypeName // corresponding type name
+	index int       // type parameter index in source order, starting at 0; -1 if not yet bound
+	bound Type      // constraint; its underlying type is an *Interface for correct programs
+}
+
+// NewTypeParam returns a new TypeParam. Type parameters may be set on a Named
+// or Signature type by calling SetTypeParams or NewSignatureType. Setting a type
+// parameter on more than one type will result in a panic.
+//
+// The constraint argument can be nil, and set later via SetConstraint.
+func NewTypeParam(obj *TypeName, constraint Type) *TypeParam {
+	return (*Checker)(nil).newTypeParam(obj, constraint)
+}
+
+// check may be nil
+func (check *Checker) newTypeParam(obj *TypeName, constraint Type) *TypeParam {
+	id := nextID()
+	typ := &TypeParam{check: check, id: id, obj: obj, index: -1}
+	if constraint != nil {
+		typ.bound = implicitConstraint(constraint)
+	}
+	if obj.typ == nil {
+		obj.typ = typ
+	}
+	return typ
+}
+
+// Obj returns the type name for the type parameter t.
+func (t *TypeParam) Obj() *TypeName { return t.obj }
+
+// Index returns the index of the type parameter within its parameter list,
+// or -1 if the type parameter has not yet been bound to a type.
+func (t *TypeParam) Index() int { return t.index }
+
+// Constraint returns the type constraint specified for t.
+func (t *TypeParam) Constraint() Type { return t.bound }
+
+// SetConstraint sets the type constraint for t.
+// A constraint that is not an interface is wrapped into an implicit
+// interface, as is done for constraints such as ~int or A|B.
+func (t *TypeParam) SetConstraint(bound Type) {
+	if bound == nil {
+		panic("types.TypeParam.SetConstraint: constraint must not be nil")
+	}
+	t.bound = implicitConstraint(bound)
+}
+
+// implicitConstraint returns bound if it is (or is defined by) an interface,
+// and an implicit interface embedding bound otherwise. Defined types whose
+// underlying type is not yet known are assumed to be interfaces.
+func implicitConstraint(bound Type) Type {
+	switch u := bound.Underlying().(type) {
+	case nil, *Interface:
+		return bound
+	case *Basic:
+		if u == Typ[Invalid] {
+			return bound
+		}
+	}
+	ityp := NewInterfaceType(nil, []Type{bound})
+	ityp.implicit = true
+	return ityp
+}
+
+// iface returns the completed constraint interface of t.
+func (t *TypeParam) iface() *Interface {
+	ityp, _ := t.bound.Underlying().(*Interface)
+	if ityp == nil {
+		return &emptyInterface
+	}
+	t.check.completeInterface(ityp)
+	return ityp
+}
+
+// typeSet returns the terms of t's constraint, or nil if
+// the constraint does not restrict the set of types.
+func (t *TypeParam) typeSet() termlist { return t.iface().allTerms }
+
+// is calls f with the specific type terms of t's constraint and reports
+// whether all calls to f returned true. If there are no specific terms,
+// is returns the result of f(nil).
+func (t *TypeParam) is(f func(*term) bool) bool {
+	terms := t.typeSet()
+	if terms == nil {
+		return f(nil)
+	}
+	for _, x := range terms {
+		if !f(x) {
+			return false
+		}
+	}
+	return true
+}
+
+// underIs calls f with the underlying types of the specific type terms
+// of t's constraint and reports whether all calls to f returned true.
+// If there are no specific terms, underIs returns the result of f(nil).
+func (t *TypeParam) underIs(f func(Type) bool) bool {
+	return t.is(func(x *term) bool {
+		if x == nil {
+			return f(nil)
+		}
+		return f(x.typ.Underlying())
+	})
+}
+
+func (t *TypeParam) Underlying() Type { return t }
+func (t *TypeParam) String() string   { return TypeString(t, nil) }
+
+// TypeParamList holds a list of type parameters.
+type TypeParamList struct{ tparams []*TypeParam }
+
+// Len returns the number of type parameters in the list.
+// It is safe to call on a nil receiver.
+func (l *TypeParamList) Len() int { return len(l.list()) }
+
+// At returns the i'th type parameter in the list.
+func (l *TypeParamList) At(i int) *TypeParam { return l.tparams[i] }
+
+// list is for internal use where we expect a []*TypeParam.
+func (l *TypeParamList) list() []*TypeParam {
+	if l == nil {
+		return nil
+	}
+	return l.tparams
+}
+
+func (l *TypeParamList) String() string {
+	var buf bytes.Buffer
+	writeTParamList(&buf, l.list(), nil, nil)
+	return buf.String()
+}
+
+// bindTParams binds the type parameters in list to their list position
+// and returns the corresponding TypeParamList, or nil if list is empty.
+// It panics if a type parameter is already bound.
+func bindTParams(list []*TypeParam) *TypeParamList {
+	if len(list) == 0 {
+		return nil
+	}
+	for i, tp := range list {
+		if tp.index >= 0 {
+			panic("type parameter bound more than once")
+		}
+		tp.index = i
+	}
+	return &TypeParamList{tparams: list}
+}
+
+// TypeList holds a list of types.
+type TypeList struct{ types []Type }
+
+// newTypeList returns a new TypeList with the types in list.
+func newTypeList(list []Type) *TypeList {
+	if len(list) == 0 {
+		return nil
+	}
+	return &TypeList{list}
+}
+
+// Len returns the number of types in the list.
+// It is safe to call on a nil receiver.
+func (l *TypeList) Len() int { return len(l.list()) }
+
+// At returns the i'th type in the list.
+func (l *TypeList) At(i int) Type { return l.types[i] }
+
+// list is for internal use where we expect a []Type.
+func (l *TypeList) list() []Type {
+	if l == nil {
+		return nil
+	}
+	return l.types
+}
+
+func (l *TypeList) String() string {
+	var buf bytes.Buffer
+	writeTypeList(&buf, l.list(), nil, nil)
+	return buf.String()
+}
@@ -143,7 +143,28 @@ func writeType(buf *bytes.Buffer, typ Type, qf Qualifier, visited []Type) {
 		buf.WriteString("func")
 		writeSignature(buf, t, qf, visited)
 
+	case *Union:
+		for i, t := range t.terms {
+			if i > 0 {
+				buf.WriteString(" | ")
+			}
+			if t.tilde {
+				buf.WriteByte('~')
+			}
+			writeType(buf, t.typ, qf, visited)
+		}
+
 	case *Interface:
+		if t == universeAny {
+			buf.WriteString("any")
+			break
+		}
+		if t.implicit && len(t.methods) == 0 && len(t.embeddeds) == 1 {
+			// print implicit constraint interfaces such as ~int or A|B
+			// the way they were written
+			writeType(buf, t.embeddeds[0], qf, visited)
+			break
+		}
 		// We write the source-level methods and embedded types rather
 		// than the actual method set since resolved method signatures
 		// may have non-printable cycles if parameters have embedded
@@ -238,6 +259,20 @@ func writeType(buf *bytes.Buffer, typ Type, qf Qualifier, visited []Type) {
 			s = obj.name
 		}
 		buf.WriteString(s)
+		if t.targs != nil {
+			// instantiated type
+			writeTypeList(buf, t.targs.list(), qf, visited)
+		} else if t.tparams != nil {
+			// generic type
+			writeTParamList(buf, t.tparams.list(), qf, visited)
+		}
+
+	case *TypeParam:
+		s := "<TypeParam w/o object>"
+		if obj := t.obj; obj != nil {
+			s = obj.name
+		}
+		buf.WriteString(s)
 
 	default:
 		// For externally defined implementations of Type.
@@ -245,6 +280,42 @@ func writeType(buf *bytes.Buffer, typ Type, qf Qualifier, visited []Type) {
 	}
 }
 
+func writeTypeList(buf *bytes.Buffer, list []Type, qf Qualifier, visited []Type) {
+	buf.WriteByte('[')
+	for i, typ := range list {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		writeType(buf, typ, qf, visited)
+	}
+	buf.WriteByte(']')
+}
+
+func writeTParamList(buf *bytes.Buffer, list []*TypeParam, qf Qualifier, visited []Type) {
+	buf.WriteByte('[')
+	var prev Type
+	for i, tpar := range list {
+		// Consecutive type parameters with the same constraint
+		// share the constraint, as in [P, Q any].
+		bound := tpar.bound
+		if i > 0 {
+			if bound != prev {
+				// bound changed - write previous one before advancing
+				buf.WriteByte(' ')
+				writeType(buf, prev, qf, visited)
+			}
+			buf.WriteString(", ")
+		}
+		prev = bound
+		writeType(buf, tpar, qf, visited)
+	}
+	if prev != nil {
+		buf.WriteByte(' ')
+		writeType(buf, prev, qf, visited)
+	}
+	buf.WriteByte(']')
+}
+
 func writeTuple(buf *bytes.Buffer, tup *Tuple, variadic bool, qf Qualifier, visited []Type) {
 	buf.WriteByte('(')
 	if tup != nil {
@@ -287,6 +358,10 @@ func WriteSignature(buf *bytes.Buffer, sig *Signature, qf Qualifier) {
 }
 
 func writeSignature(buf *bytes.Buffer, sig *Signature, qf Qualifier, visited []Type) {
+	if sig.tparams != nil {
+		writeTParamList(buf, sig.tparams.list(), qf, visited)
+	}
+
 	writeTuple(buf, sig.params, sig.variadic, qf, visited)
 
 	n := sig.results.Len()
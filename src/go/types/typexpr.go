@@ -7,6 +7,7 @@
 package types
 
 import (
+	"fmt"
 	"go/ast"
 	"go/constant"
 	"go/token"
@@ -28,7 +29,14 @@ func (check *Checker) ident(x *operand, e *ast.Ident, def *Named, wantType bool)
 	scope, obj := check.scope.LookupParent(e.Name, check.pos)
 	if obj == nil {
 		if e.Name == "_" {
-			check.errorf(e.Pos(), "cannot use _ as value or type")
+			// Blank receiver type parameters are not declared but
+			// are recorded in check.recvTParamMap.
+			if tpar := check.recvTParamMap[e]; tpar != nil {
+				x.mode = typexpr
+				x.typ = tpar
+			} else {
+				check.errorf(e.Pos(), "cannot use _ as value or type")
+			}
 		} else {
 			check.errorf(e.Pos(), "undeclared name: %s", e.Name)
 		}
@@ -116,10 +124,41 @@ func (check *Checker) ident(x *operand, e *ast.Ident, def *Named, wantType bool)
 }
 
 // typ type-checks the type expression e and returns its type, or Typ[Invalid].
+// The type must not be an (uninstantiated) generic type.
 func (check *Checker) typ(e ast.Expr) Type {
 	return check.definedType(e, nil)
 }
 
+// varType type-checks the type expression e and returns its type, or Typ[Invalid].
+// The type must not be an (uninstantiated) generic type and it must not be a
+// constraint interface.
+func (check *Checker) varType(e ast.Expr) Type {
+	typ := check.definedType(e, nil)
+	check.validVarType(e, typ)
+	return typ
+}
+
+// validVarType reports an error if typ is a constraint interface.
+// The expression e is used for error reporting, if any.
+func (check *Checker) validVarType(e ast.Expr, typ Type) {
+	// If we have a type parameter there's nothing to do.
+	if isTypeParam(typ) {
+		return
+	}
+
+	// We don't want to call check.underlying or complete interfaces while we
+	// are in the middle of type-checking parameter declarations that might
+	// belong to interface methods. Delay this check to the end of type-checking.
+	check.later(func() {
+		if t, _ := check.underlying(typ).(*Interface); t != nil {
+			check.completeInterface(t)
+			if !t.IsMethodSet() {
+				check.errorf(e.Pos(), "interface contains type constraints")
+			}
+		}
+	})
+}
+
 // definedType is like typ but also accepts a type name def.
 // If def != nil, e is the type specification for the defined type def, declared
 // in a type declaration, and def.underlying will be set to the type of e before
@@ -137,17 +176,104 @@ func (check *Checker) definedType(e ast.Expr, def *Named) (T Type) {
 
 	T = check.typInternal(e, def)
 	assert(isTyped(T))
+	if isGeneric(T) {
+		check.errorf(e.Pos(), "cannot use generic type %s without instantiation", T)
+		T = Typ[Invalid]
+	}
 	check.recordTypeAndValue(e, typexpr, T, nil)
 
 	return
 }
 
+// genericType is like typ but the type must be an (uninstantiated) generic
+// type. If reportErr is set, an error is reported if the type is not generic.
+func (check *Checker) genericType(e ast.Expr, reportErr bool) Type {
+	typ := check.typInternal(e, nil)
+	assert(isTyped(typ))
+	if typ != Typ[Invalid] && !isGeneric(typ) {
+		if reportErr {
+			check.errorf(e.Pos(), "%s is not a generic type", typ)
+		}
+		typ = Typ[Invalid]
+	}
+	check.recordTypeAndValue(e, typexpr, typ, nil)
+	return typ
+}
+
+// isGeneric reports whether typ is a generic, uninstantiated defined type.
+func isGeneric(typ Type) bool {
+	named, _ := typ.(*Named)
+	return named != nil && named.orig == named && named.tparams != nil
+}
+
 // funcType type-checks a function or method type.
 func (check *Checker) funcType(sig *Signature, recvPar *ast.FieldList, ftyp *ast.FuncType) {
 	scope := NewScope(check.scope, token.NoPos, token.NoPos, "function")
 	scope.isFunc = true
 	check.recordScope(ftyp, scope)
 
+	if recvPar != nil && len(recvPar.List) > 0 {
+		// Collect generic receiver type parameters, if any.
+		// A receiver type parameter is like any other type parameter,
+		// except that it is declared implicitly by the receiver
+		// specification, which acts as its local declaration.
+		_, rname, rparams := check.unpackRecv(recvPar.List[0].Type, true)
+		if len(rparams) > 0 {
+			defer func(s *Scope) { check.scope = s }(check.scope)
+			check.scope = scope
+			tparams := make([]*TypeParam, len(rparams))
+			for i, rparam := range rparams {
+				tparams[i] = check.declareTypeParam(rparam)
+				if rparam.Name == "_" {
+					if check.recvTParamMap == nil {
+						check.recvTParamMap = make(map[*ast.Ident]*TypeParam)
+					}
+					check.recvTParamMap[rparam] = tparams[i]
+				}
+			}
+			sig.rparams = bindTParams(tparams)
+			// Determine the receiver type to get its type parameters
+			// and the respective type parameter constraints.
+			var recvTParams []*TypeParam
+			if rname != nil {
+				// recv should be a Named type (otherwise an error is reported elsewhere)
+				// Don't report an error via genericType since it will be reported
+				// again when we type-check the signature.
+				if recv, _ := check.genericType(rname, false).(*Named); recv != nil {
+					recvTParams = recv.TypeParams().list()
+				}
+			}
+			// provide type parameter constraints
+			if len(tparams) == len(recvTParams) {
+				targs := make([]Type, len(tparams))
+				for i, tpar := range tparams {
+					targs[i] = tpar
+				}
+				smap := makeSubstMap(recvTParams, targs)
+				for i, tpar := range tparams {
+					// The receiver type's constraint is (possibly) parameterized
+					// in the context of the receiver type declaration; substitute
+					// the method's receiver type parameters.
+					tpar.bound = check.subst(tpar.obj.pos, recvTParams[i].bound, smap, check.ctxt)
+				}
+			} else if rname != nil && len(recvTParams) > 0 {
+				check.errorf(recvPar.List[0].Type.Pos(), "got %d type parameters, but receiver base type declares %d", len(tparams), len(recvTParams))
+			}
+		}
+	}
+
+	if ftyp.TypeParams != nil {
+		if check.scope != scope {
+			defer func(s *Scope) { check.scope = s }(check.scope)
+			check.scope = scope
+		}
+		sig.tparams = bindTParams(check.collectTypeParams(ftyp.TypeParams))
+		// Always type-check method type parameters but complain that they are not allowed.
+		if recvPar != nil {
+			check.errorf(ftyp.TypeParams.Opening, "methods cannot have type parameters")
+		}
+	}
+
 	recvList, _ := check.collectParams(scope, recvPar, false)
 	params, variadic := check.collectParams(scope, ftyp.Params, true)
 	results, _ := check.collectParams(scope, ftyp.Results, false)
@@ -180,7 +306,7 @@ func (check *Checker) funcType(sig *Signature, recvPar *ast.FieldList, ftyp *ast
 					err = "type not defined in this package"
 				} else {
 					// TODO(gri) This is not correct if the underlying type is unknown yet.
-					switch u := T.underlying.(type) {
+					switch u := T.orig.underlying.(type) {
 					case *Basic:
 						// unsafe.Pointer is treated like a regular pointer
 						if u.kind == UnsafePointer {
@@ -207,6 +333,157 @@ func (check *Checker) funcType(sig *Signature, recvPar *ast.FieldList, ftyp *ast
 	sig.variadic = variadic
 }
 
+// An indexedExpr is an *ast.IndexExpr or *ast.IndexListExpr,
+// unpacked into its components.
+type indexedExpr struct {
+	orig    ast.Expr   // the original expression
+	x       ast.Expr   // expression
+	lbrack  token.Pos  // position of "["
+	indices []ast.Expr // index expressions
+	rbrack  token.Pos  // position of "]"
+}
+
+func (x *indexedExpr) Pos() token.Pos { return x.orig.Pos() }
+
+// unpackIndexedExpr unpacks the index expression e, which
+// must be an *ast.IndexExpr or an *ast.IndexListExpr.
+func unpackIndexedExpr(e ast.Expr) *indexedExpr {
+	switch e := e.(type) {
+	case *ast.IndexExpr:
+		return &indexedExpr{e, e.X, e.Lbrack, []ast.Expr{e.Index}, e.Rbrack}
+	case *ast.IndexListExpr:
+		return &indexedExpr{e, e.X, e.Lbrack, e.Indices, e.Rbrack}
+	}
+	unreachable()
+	return nil
+}
+
+// instantiatedType type-checks the instantiation ix of a generic type
+// and returns the instance, or Typ[Invalid].
+func (check *Checker) instantiatedType(ix *indexedExpr, def *Named) Type {
+	gtyp := check.genericType(ix.x, true)
+	if gtyp == Typ[Invalid] {
+		check.use(ix.indices...)
+		return gtyp // error already reported
+	}
+	orig, _ := gtyp.(*Named)
+	if orig == nil {
+		panic(fmt.Sprintf("%v: cannot instantiate %v", ix.Pos(), gtyp))
+	}
+
+	// evaluate arguments
+	targs := check.typeList(ix.indices)
+	if targs == nil {
+		def.setUnderlying(Typ[Invalid]) // avoid errors later due to lazy instantiation
+		return Typ[Invalid]
+	}
+
+	// validate the number of type arguments
+	tparams := orig.TypeParams().list()
+	if len(targs) != len(tparams) {
+		qual := "not enough"
+		pos := ix.rbrack
+		if len(targs) > len(tparams) {
+			qual = "too many"
+			pos = ix.indices[len(tparams)].Pos()
+		}
+		check.errorf(pos, "%s type arguments for type %s: have %d, want %d", qual, orig.obj.name, len(targs), len(tparams))
+		return Typ[Invalid]
+	}
+
+	inst := check.instance(ix.Pos(), orig, targs, check.ctxt).(*Named)
+	def.setUnderlying(inst)
+	check.recordInstance(ix.orig, targs, inst)
+
+	// Make sure the type arguments satisfy their constraints once the
+	// constraints and the underlying type of orig are set up.
+	check.later(func() {
+		if i, err := check.verify(ix.Pos(), tparams, targs, check.ctxt); err != nil {
+			pos := ix.Pos()
+			if i < len(ix.indices) {
+				pos = ix.indices[i].Pos()
+			}
+			check.softErrorf(pos, "%s", err)
+		}
+		check.validType(inst, nil)
+	})
+
+	return inst
+}
+
+// typeList type-checks the list of type expressions and returns their
+// types. If an error occurred, the result is nil, but all list elements
+// were type-checked.
+func (check *Checker) typeList(list []ast.Expr) []Type {
+	res := make([]Type, len(list)) // res != nil even if len(list) == 0
+	for i, x := range list {
+		t := check.varType(x)
+		if t == Typ[Invalid] {
+			res = nil
+		}
+		if res != nil {
+			res[i] = t
+		}
+	}
+	return res
+}
+
+// unpackRecv unpacks a receiver type and returns its components: ptr indicates
+// whether rtyp is a pointer receiver, rname is the receiver type name, and
+// tparams are its type parameters, if any. The type parameters are only
+// unpacked if unpackParams is set. If rname is nil, the receiver is unusable
+// (i.e., the source has a bug which we cannot easily work around).
+func (check *Checker) unpackRecv(rtyp ast.Expr, unpackParams bool) (ptr bool, rname *ast.Ident, tparams []*ast.Ident) {
+L: // unpack receiver type
+	// This accepts invalid receivers such as ***T and does not
+	// work for other invalid receivers, but we don't care. The
+	// validity of receiver expressions is checked elsewhere.
+	for {
+		switch t := rtyp.(type) {
+		case *ast.ParenExpr:
+			rtyp = t.X
+		case *ast.StarExpr:
+			ptr = true
+			rtyp = t.X
+		default:
+			break L
+		}
+	}
+
+	// unpack type parameters, if any
+	var indices []ast.Expr
+	switch x := rtyp.(type) {
+	case *ast.IndexExpr:
+		rtyp = x.X
+		indices = []ast.Expr{x.Index}
+	case *ast.IndexListExpr:
+		rtyp = x.X
+		indices = x.Indices
+	}
+	if unpackParams {
+		for _, arg := range indices {
+			var par *ast.Ident
+			switch arg := arg.(type) {
+			case *ast.Ident:
+				par = arg
+			case *ast.BadExpr:
+				// ignore - error already reported by parser
+			default:
+				check.errorf(arg.Pos(), "receiver type parameter %s must be an identifier", arg)
+			}
+			if par == nil {
+				par = &ast.Ident{NamePos: arg.Pos(), Name: "_"}
+			}
+			tparams = append(tparams, par)
+		}
+	}
+
+	// unpack receiver name
+	rname, _ = rtyp.(*ast.Ident)
+
+	return
+}
+
 // typInternal drives type checking of types.
 // Must only be called by definedType.
 //
@@ -249,7 +526,12 @@ func (check *Checker) typInternal(e ast.Expr, def *Named) Type {
 			check.errorf(x.pos(), "%s is not a type", &x)
 		}
 
+	case *ast.IndexExpr, *ast.IndexListExpr:
+		return check.instantiatedType(unpackIndexedExpr(e), def)
+
 	case *ast.ParenExpr:
+		// Generic types must be instantiated before they can be used in any form.
+		// Consequently, generic types cannot be parenthesized.
 		return check.definedType(e.X, def)
 
 	case *ast.ArrayType:
@@ -257,13 +539,13 @@ func (check *Checker) typInternal(e ast.Expr, def *Named) Type {
 			typ := new(Array)
 			def.setUnderlying(typ)
 			typ.len = check.arrayLength(e.Len)
-			typ.elem = check.typ(e.Elt)
+			typ.elem = check.varType(e.Elt)
 			return typ
 
 		} else {
 			typ := new(Slice)
 			def.setUnderlying(typ)
-			typ.elem = check.typ(e.Elt)
+			typ.elem = check.varType(e.Elt)
 			return typ
 		}
 
@@ -276,7 +558,7 @@ func (check *Checker) typInternal(e ast.Expr, def *Named) Type {
 	case *ast.StarExpr:
 		typ := new(Pointer)
 		def.setUnderlying(typ)
-		typ.base = check.typ(e.X)
+		typ.base = check.varType(e.X)
 		return typ
 
 	case *ast.FuncType:
@@ -295,8 +577,8 @@ func (check *Checker) typInternal(e ast.Expr, def *Named) Type {
 		typ := new(Map)
 		def.setUnderlying(typ)
 
-		typ.key = check.typ(e.Key)
-		typ.elem = check.typ(e.Value)
+		typ.key = check.varType(e.Key)
+		typ.elem = check.varType(e.Value)
 
 		// spec: "The comparison operators == and != must be fully defined
 		// for operands of the key type; thus the key type must not be a
@@ -330,7 +612,7 @@ func (check *Checker) typInternal(e ast.Expr, def *Named) Type {
 		}
 
 		typ.dir = dir
-		typ.elem = check.typ(e.Value)
+		typ.elem = check.varType(e.Value)
 		return typ
 
 	default:
@@ -348,7 +630,7 @@ func (check *Checker) typInternal(e ast.Expr, def *Named) Type {
 //
 func (check *Checker) typOrNil(e ast.Expr) Type {
 	var x operand
-	check.rawExpr(&x, e, nil)
+	check.rawExpr(&x, e, nil, false)
 	switch x.mode {
 	case invalid:
 		// ignore - error reported before
@@ -411,7 +693,7 @@ func (check *Checker) collectParams(scope *Scope, list *ast.FieldList, variadicO
 				// ignore ... and continue
 			}
 		}
-		typ := check.typ(ftype)
+		typ := check.varType(ftype)
 		// The parser ensures that f.Tag is nil and we don't
 		// care if a constructed AST contains a non-nil tag.
 		if len(field.Names) > 0 {
@@ -493,16 +775,12 @@ func (check *Checker) interfaceType(ityp *Interface, iface *ast.InterfaceType, d
 			check.recordDef(name, m)
 			ityp.methods = append(ityp.methods, m)
 		} else {
-			// We have an embedded interface and f.Type is its
-			// (possibly qualified) embedded type name. Collect
-			// it if it's a valid interface.
-			typ := check.typ(f.Type)
-
-			utyp := check.underlying(typ)
-			if _, ok := utyp.(*Interface); !ok {
-				if utyp != Typ[Invalid] {
-					check.errorf(f.Type.Pos(), "%s is not an interface", typ)
-				}
+			// We have an embedded element f.Type: an interface, another
+			// type, or a union of (possibly approximated) types. Elements
+			// other than method-only interfaces restrict the type set of
+			// the interface.
+			typ := check.embeddedElem(f.Type)
+			if typ == Typ[Invalid] {
 				continue
 			}
 
@@ -524,6 +802,76 @@ func (check *Checker) interfaceType(ityp *Interface, iface *ast.InterfaceType, d
 	check.later(func() { check.completeInterface(ityp) })
 }
 
+// embeddedElem type-checks the embedded interface element e and returns its
+// type: a *Union for union and ~T elements, the embedded type otherwise.
+func (check *Checker) embeddedElem(e ast.Expr) Type {
+	var exprs []ast.Expr
+	var collect func(x ast.Expr)
+	collect = func(x ast.Expr) {
+		if b, _ := unparen(x).(*ast.BinaryExpr); b != nil && b.Op == token.OR {
+			collect(b.X)
+			collect(b.Y)
+			return
+		}
+		exprs = append(exprs, x)
+	}
+	collect(e)
+
+	if len(exprs) == 1 {
+		if u, _ := exprs[0].(*ast.UnaryExpr); u == nil || u.Op != token.TILDE {
+			typ := check.typ(exprs[0])
+			if isTypeParam(typ) {
+				check.errorf(exprs[0].Pos(), "cannot embed a type parameter")
+				return Typ[Invalid]
+			}
+			return typ
+		}
+	}
+
+	terms := make([]*Term, len(exprs))
+	for i, x := range exprs {
+		tilde := false
+		if u, _ := x.(*ast.UnaryExpr); u != nil && u.Op == token.TILDE {
+			tilde = true
+			x = u.X
+		}
+		typ := check.typ(x)
+		if isTypeParam(typ) {
+			check.errorf(x.Pos(), "term cannot be a type parameter")
+			typ = Typ[Invalid]
+		}
+		terms[i] = NewTerm(tilde, typ)
+	}
+
+	// Check validity of the terms once all types are set up.
+	check.later(func() {
+		for i, t := range terms {
+			if t.typ == Typ[Invalid] {
+				continue
+			}
+			x := exprs[i]
+			u := check.underlying(t.typ)
+			if t.tilde && !Identical(t.typ, u) {
+				check.errorf(x.Pos(), "invalid use of ~ (underlying type of %s is %s)", t.typ, u)
+				continue
+			}
+			if ityp, _ := u.(*Interface); ityp != nil && len(terms) > 1 {
+				check.completeInterface(ityp)
+				switch {
+				case t.typ == universeComparable:
+					check.errorf(x.Pos(), "cannot use comparable in union")
+				case len(ityp.allMethods) > 0:
+					check.errorf(x.Pos(), "cannot use %s in union (%s contains methods)", t.typ, t.typ)
+				case ityp.allComparable:
+					check.errorf(x.Pos(), "cannot use %s in union (%s embeds comparable)", t.typ, t.typ)
+				}
+			}
+		}
+	})
+
+	return &Union{terms}
+}
+
 func (check *Checker) completeInterface(ityp *Interface) {
 	if ityp.allMethods != nil {
 		return
@@ -534,7 +882,10 @@ func (check *Checker) completeInterface(ityp *Interface) {
 	// in which case check will be nil. In this case, type-checking
 	// must be finished and all interfaces should have been completed.
 	if check == nil {
-		panic("internal error: incomplete interface")
+		// Interfaces created by instantiation through the
+		// external API are completed on demand.
+		ityp.Complete()
+		return
 	}
 
 	if trace {
@@ -593,19 +944,24 @@ func (check *Checker) completeInterface(ityp *Interface) {
 	}
 
 	posList := check.posMap[ityp]
+	var tset typeSet
 	for i, typ := range ityp.embeddeds {
-		pos := posList[i] // embedding position
-		typ, ok := check.underlying(typ).(*Interface)
-		if !ok {
-			// An error was reported when collecting the embedded types.
-			// Ignore it.
-			continue
+		// Interfaces created by substitution have no recorded
+		// embedding positions.
+		pos := token.NoPos
+		if i < len(posList) {
+			pos = posList[i] // embedding position
 		}
-		check.completeInterface(typ)
-		for _, m := range typ.allMethods {
-			addMethod(pos, m, false) // use embedding position pos rather than m.pos
+		if typ, ok := check.underlying(typ).(*Interface); ok {
+			check.completeInterface(typ)
+			for _, m := range typ.allMethods {
+				addMethod(pos, m, false) // use embedding position pos rather than m.pos
+			}
 		}
+		// Non-interface types and unions restrict the type set.
+		tset.embed(check, typ)
 	}
+	tset.setTerms(ityp)
 
 	if methods != nil {
 		sort.Sort(byUniqueMethodName(methods))
@@ -690,7 +1046,7 @@ func (check *Checker) structType(styp *Struct, e *ast.StructType) {
 	}
 
 	for _, f := range list.List {
-		typ = check.typ(f.Type)
+		typ = check.varType(f.Type)
 		tag = check.tag(f.Tag)
 		if len(f.Names) > 0 {
 			// named fields
@@ -0,0 +1,278 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file implements type unification.
+
+package types
+
+// The unifier maintains the current type parameters for x and y
+// and the respective types inferred for each type parameter.
+// A unifier is created by calling newUnifier.
+//
+// Unification is used to infer the type arguments of a generic
+// function from its (value) arguments. Only the type parameters
+// passed to newUnifier are inferred; all other types, including
+// other type parameters, must match exactly.
+//
+// Unification is inexact: if a defined type is unified with a
+// type literal, the underlying type of the defined type is used
+// instead. This matches assignability, which is the relation that
+// must hold between function arguments and parameters.
+type unifier struct {
+	tparams []*TypeParam
+	types   []Type // inferred types, one for each type parameter; nil if not yet known
+	depth   int    // recursion depth during unification
+}
+
+// unificationDepthLimit bounds the recursion depth of unification to
+// guard against endless recursion for recursive types.
+const unificationDepthLimit = 50
+
+// newUnifier returns a new unifier initialized with the given type
+// parameter list and the corresponding type arguments, which may be
+// nil if not known yet.
+func newUnifier(tparams []*TypeParam, targs []Type) *unifier {
+	assert(len(tparams) >= len(targs))
+	types := make([]Type, len(tparams))
+	copy(types, targs)
+	return &unifier{tparams: tparams, types: types}
+}
+
+// unify attempts to unify x and y and reports whether it succeeded.
+func (u *unifier) unify(x, y Type) bool {
+	return u.nify(x, y)
+}
+
+// index returns the index of the type parameter typ in the unifier's
+// type parameter list, or -1 if typ is not one of those type parameters.
+func (u *unifier) index(typ Type) int {
+	if tpar, _ := typ.(*TypeParam); tpar != nil {
+		return tparamIndex(u.tparams, tpar)
+	}
+	return -1
+}
+
+// tparamIndex returns the index of the type parameter tpar in list,
+// or -1 if tpar is not in list.
+func tparamIndex(list []*TypeParam, tpar *TypeParam) int {
+	if i := tpar.index; 0 <= i && i < len(list) && list[i] == tpar {
+		return i
+	}
+	return -1
+}
+
+// at returns the type inferred for the type parameter tpar, or nil.
+func (u *unifier) at(tpar *TypeParam) Type {
+	if i := tparamIndex(u.tparams, tpar); i >= 0 {
+		return u.types[i]
+	}
+	return nil
+}
+
+// inferred returns the list of inferred types, one for each type
+// parameter; types that could not be inferred are nil.
+func (u *unifier) inferred() []Type {
+	list := make([]Type, len(u.types))
+	copy(list, u.types)
+	return list
+}
+
+func (u *unifier) nify(x, y Type) bool {
+	if x == y {
+		return true
+	}
+
+	if u.depth >= unificationDepthLimit {
+		return false
+	}
+	u.depth++
+	defer func() { u.depth-- }()
+
+	// Cases where at least one of x or y is a type parameter
+	// whose type is to be inferred.
+	if i := u.index(x); i >= 0 {
+		if t := u.types[i]; t != nil {
+			// x has an inferred type which must match y
+			return u.nify(t, y)
+		}
+		// otherwise, infer type from y
+		u.types[i] = y
+		return true
+	}
+	if j := u.index(y); j >= 0 {
+		if t := u.types[j]; t != nil {
+			// y has an inferred type which must match x
+			return u.nify(x, t)
+		}
+		// otherwise, infer type from x
+		u.types[j] = x
+		return true
+	}
+
+	// If unification would fail because we attempt to match a defined
+	// type against a type literal, consider the underlying type of the
+	// defined type.
+	if nx, _ := x.(*Named); nx != nil {
+		if _, ok := y.(*Named); !ok && !isTypeParam(y) {
+			x = nx.Underlying()
+		}
+	} else if ny, _ := y.(*Named); ny != nil && !isTypeParam(x) {
+		y = ny.Underlying()
+	}
+
+	// Likewise, match a type parameter that is not inferred against
+	// a type literal by considering its core type, if any.
+	if px, _ := x.(*TypeParam); px != nil && !isNamed(y) {
+		if cx := coreType(px); cx != nil {
+			x = cx
+		}
+	} else if py, _ := y.(*TypeParam); py != nil && !isNamed(x) {
+		if cy := coreType(py); cy != nil {
+			y = cy
+		}
+	}
+
+	switch x := x.(type) {
+	case *Basic:
+		// Basic types are singletons except for the rune and byte
+		// aliases, thus we cannot solely rely on the x == y check
+		// above.
+		if y, ok := y.(*Basic); ok {
+			return x.kind == y.kind
+		}
+
+	case *Array:
+		if y, ok := y.(*Array); ok {
+			// If one or both array lengths are unknown (< 0) due to some error,
+			// assume they are the same to avoid spurious follow-on errors.
+			return (x.len < 0 || y.len < 0 || x.len == y.len) && u.nify(x.elem, y.elem)
+		}
+
+	case *Slice:
+		if y, ok := y.(*Slice); ok {
+			return u.nify(x.elem, y.elem)
+		}
+
+	case *Struct:
+		if y, ok := y.(*Struct); ok {
+			if x.NumFields() == y.NumFields() {
+				for i, f := range x.fields {
+					g := y.fields[i]
+					if f.embedded != g.embedded ||
+						x.Tag(i) != y.Tag(i) ||
+						!f.sameId(g.pkg, g.name) ||
+						!u.nify(f.typ, g.typ) {
+						return false
+					}
+				}
+				return true
+			}
+		}
+
+	case *Pointer:
+		if y, ok := y.(*Pointer); ok {
+			return u.nify(x.base, y.base)
+		}
+
+	case *Tuple:
+		if y, ok := y.(*Tuple); ok {
+			if x.Len() == y.Len() {
+				if x != nil {
+					for i, v := range x.vars {
+						w := y.vars[i]
+						if !u.nify(v.typ, w.typ) {
+							return false
+						}
+					}
+				}
+				return true
+			}
+		}
+
+	case *Signature:
+		// Two function types unify if they have the same number of
+		// parameters and result values, corresponding parameter and
+		// result types unify, and either both functions are variadic
+		// or neither is. Generic signatures don't unify.
+		if y, ok := y.(*Signature); ok {
+			return x.TypeParams().Len() == 0 && y.TypeParams().Len() == 0 &&
+				x.variadic == y.variadic &&
+				u.nify(x.params, y.params) &&
+				u.nify(x.results, y.results)
+		}
+
+	case *Interface:
+		// Two interface types unify if they have the same set of methods
+		// with the same names, corresponding method types unify, and they
+		// have the same type sets.
+		if y, ok := y.(*Interface); ok {
+			x.Complete()
+			y.Complete()
+			if x.allComparable != y.allComparable || !identicalTerms(x.allTerms, y.allTerms) {
+				return false
+			}
+			a := x.allMethods
+			b := y.allMethods
+			if len(a) == len(b) {
+				// Interface methods are sorted by unique name,
+				// so corresponding methods are at the same index.
+				for i, f := range a {
+					g := b[i]
+					if f.Id() != g.Id() || !u.nify(f.typ, g.typ) {
+						return false
+					}
+				}
+				return true
+			}
+		}
+
+	case *Map:
+		if y, ok := y.(*Map); ok {
+			return u.nify(x.key, y.key) && u.nify(x.elem, y.elem)
+		}
+
+	case *Chan:
+		// Two channel types unify if their value types unify and
+		// they have the same direction.
+		if y, ok := y.(*Chan); ok {
+			return x.dir == y.dir && u.nify(x.elem, y.elem)
+		}
+
+	case *Named:
+		// Two named types unify if they are instances of the same
+		// generic type and their type arguments unify, or if they
+		// are identical.
+		if y, ok := y.(*Named); ok {
+			if x.orig != y.orig {
+				return false
+			}
+			xargs := x.targs.list()
+			yargs := y.targs.list()
+			if len(xargs) != len(yargs) {
+				return false
+			}
+			for i, xarg := range xargs {
+				if !u.nify(xarg, yargs[i]) {
+					return false
+				}
+			}
+			return true
+		}
+
+	case *TypeParam:
+		// x is not one of the type parameters to infer;
+		// it must be identical to y (checked above).
+
+	case *Union:
+		return Identical(x, y)
+
+	case nil:
+		// avoid a crash in case of nil type
+
+	default:
+		panic("unreachable")
+	}
+
+	return false
+}
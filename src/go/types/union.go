@@ -0,0 +1,135 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package types
+
+// A Union represents a union of terms embedded in an interface.
+type Union struct {
+	terms []*Term // list of syntactic terms (not a canonicalized termlist)
+}
+
+// NewUnion returns a new Union type with the given terms.
+// It is an error to create an empty union; they are syntactically not possible.
+func NewUnion(terms []*Term) *Union {
+	if len(terms) == 0 {
+		panic("empty union")
+	}
+	return &Union{terms}
+}
+
+// Len returns the number of terms in the union u.
+func (u *Union) Len() int { return len(u.terms) }
+
+// Term returns the i'th term of the union u.
+func (u *Union) Term(i int) *Term { return u.terms[i] }
+
+func (u *Union) Underlying() Type { return u }
+func (u *Union) String() string   { return TypeString(u, nil) }
+
+// A Term represents a term in a Union.
+type Term term
+
+// NewTerm returns a new union term.
+func NewTerm(tilde bool, typ Type) *Term { return &Term{tilde, typ} }
+
+// Tilde reports whether the term has the form ~T.
+func (t *Term) Tilde() bool { return t.tilde }
+
+// Type returns the type of the term t.
+func (t *Term) Type() Type { return t.typ }
+
+func (t *Term) String() string { return (*term)(t).String() }
+
+// unionTerms returns the type set described by the union u.
+// Interface terms contribute their own type sets.
+func (check *Checker) unionTerms(u *Union) termlist {
+	var terms termlist
+	for _, t := range u.terms {
+		if !t.tilde {
+			if ityp, _ := check.under(t.typ).(*Interface); ityp != nil {
+				check.completeInterface(ityp)
+				if ityp.allTerms == nil {
+					return allTermlist
+				}
+				terms = append(terms, ityp.allTerms...)
+				continue
+			}
+		}
+		terms = append(terms, (*term)(t))
+	}
+	return terms.norm()
+}
+
+// A typeSet accumulates the restrictions that the elements embedded
+// in an interface impose on the interface's type set.
+type typeSet struct {
+	restricted bool     // terms is valid
+	terms      termlist // intersection of the embedded elements' type sets
+	comparable bool     // an embedded element requires comparable types
+}
+
+// embed adds the restrictions of the embedded element typ to s.
+// If typ is an interface, it must have been completed. check may
+// be nil if embed is invoked through an exported API call.
+func (s *typeSet) embed(check *Checker, typ Type) {
+	switch u := check.under(typ).(type) {
+	case nil:
+		// embedded type is not set up yet (error reported elsewhere)
+	case *Interface:
+		if u.allComparable {
+			s.comparable = true
+		}
+		if u.allTerms != nil {
+			s.intersect(u.allTerms)
+		}
+	case *Union:
+		s.intersect(check.unionTerms(u))
+	default:
+		if u == Typ[Invalid] {
+			return
+		}
+		s.intersect(termlist{&term{false, typ}})
+	}
+}
+
+func (s *typeSet) intersect(terms termlist) {
+	if !s.restricted {
+		s.restricted = true
+		s.terms = terms.norm()
+		return
+	}
+	s.terms = s.terms.intersect(terms)
+}
+
+// setTerms records the accumulated type set restrictions in t.
+func (s *typeSet) setTerms(t *Interface) {
+	t.allComparable = s.comparable
+	switch {
+	case !s.restricted || s.terms.isAll():
+		t.allTerms = nil
+	case s.terms.isEmpty():
+		t.allTerms = termlist{}
+	default:
+		t.allTerms = s.terms
+	}
+}
+
+// all reports whether f(x) is true for all terms x of xl.
+func (xl termlist) all(f func(*term) bool) bool {
+	for _, x := range xl {
+		if !f(x) {
+			return false
+		}
+	}
+	return true
+}
+
+// under returns the underlying type of typ. check may be nil
+// if under is invoked through an exported API call.
+func (check *Checker) under(typ Type) Type {
+	if check == nil {
+		return typ.Underlying()
+	}
+	return check.underlying(typ)
+}
@@ -21,9 +21,11 @@ var Universe *Scope
 var Unsafe *Package
 
 var (
-	universeIota *Const
-	universeByte *Basic // uint8 alias, but has name "byte"
-	universeRune *Basic // int32 alias, but has name "rune"
+	universeIota       *Const
+	universeByte       *Basic     // uint8 alias, but has name "byte"
+	universeRune       *Basic     // int32 alias, but has name "rune"
+	universeAny        *Interface // alias for the empty interface, but has name "any"
+	universeComparable *Named
 )
 
 // Typ contains the predeclared *Basic types indexed by their
@@ -81,8 +83,21 @@ func defPredeclaredTypes() {
 	sig := &Signature{results: NewTuple(res)}
 	err := NewFunc(token.NoPos, nil, "Error", sig)
 	typ := &Named{underlying: NewInterfaceType([]*Func{err}, nil).Complete()}
+	typ.orig = typ
 	sig.recv = NewVar(token.NoPos, nil, "", typ)
 	def(NewTypeName(token.NoPos, nil, "error", typ))
+
+	// type any = interface{}
+	// Note: don't use &emptyInterface for the type of any. Using a unique
+	// pointer allows us to detect any and print it as "any" rather than
+	// interface{}.
+	def(NewTypeName(token.NoPos, nil, "any", &Interface{allMethods: markComplete}))
+
+	// type comparable interface{} // marked as comparable
+	obj := NewTypeName(token.NoPos, nil, "comparable", nil)
+	obj.setColor(black)
+	NewNamed(obj, &Interface{allMethods: markComplete, allComparable: true}, nil)
+	def(obj)
 }
 
 var predeclaredConsts = [...]struct {
@@ -200,6 +215,8 @@ func init() {
 	universeIota = Universe.Lookup("iota").(*Const)
 	universeByte = Universe.Lookup("byte").(*TypeName).typ.(*Basic)
 	universeRune = Universe.Lookup("rune").(*TypeName).typ.(*Basic)
+	universeAny = Universe.Lookup("any").(*TypeName).typ.(*Interface)
+	universeComparable = Universe.Lookup("comparable").(*TypeName).typ.(*Named)
 }
 
 // Objects with names containing blanks are internal and not entered into
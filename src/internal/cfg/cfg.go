@@ -58,6 +58,7 @@ const KnownEnv = `
 	GOTMPDIR
 	GOTOOLDIR
 	GOWASM
+	GOWORK
 	GO_EXTLINK_ENABLED
 	PKG_CONFIG
 `
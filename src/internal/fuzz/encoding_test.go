@@ -0,0 +1,184 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fuzz
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestUnmarshalMarshal(t *testing.T) {
+	var tests = []struct {
+		in string
+		ok bool
+	}{
+		{
+			in: "int(1234)",
+			ok: false, // missing version
+		},
+		{
+			in: `go test fuzz v1
+string("a"bcad")`,
+			ok: false, // malformed
+		},
+		{
+			in: `go test fuzz v1
+int()`,
+			ok: false, // empty value
+		},
+		{
+			in: `go test fuzz v1
+uint(-32)`,
+			ok: false, // invalid negative uint
+		},
+		{
+			in: `go test fuzz v1
+int8(1234456)`,
+			ok: false, // int8 too large
+		},
+		{
+			in: `go test fuzz v1
+int(20*5)`,
+			ok: false, // expression in int value
+		},
+		{
+			in: `go test fuzz v1
+int(--5)`,
+			ok: false, // expression in int value
+		},
+		{
+			in: `go test fuzz v1
+bool(0)`,
+			ok: false, // malformed bool
+		},
+		{
+			in: `go test fuzz v1
+byte('aa)`,
+			ok: false, // malformed byte
+		},
+		{
+			in: `go test fuzz v1
+byte('☃')`,
+			ok: false, // byte out of range
+		},
+		{
+			in: `go test fuzz v1
+string("has final newline")
+`,
+			ok: true, // has final newline
+		},
+		{
+			in: `go test fuzz v1
+string("extra")
+[]byte("spacing")
+    `,
+			ok: true, // extra spaces in the final newline
+		},
+		{
+			in: `go test fuzz v1
+float64(0)
+float32(0)`,
+			ok: true, // will be an integer literal since there is no decimal
+		},
+		{
+			in: `go test fuzz v1
+int(-23)
+int8(-2)
+int64(2342425)
+uint(1)
+uint16(234)
+uint32(352342)
+uint64(123)
+rune('œ')
+byte('K')
+byte('ÿ')
+[]byte("hello¿")
+[]byte("a")
+bool(true)
+string("hello\\xbd\\xb2=\\xbc ⌘")
+float64(-12.5)
+float32(2.5)`,
+			ok: true,
+		},
+		{
+			in: `go test fuzz v1
+float32(-0)
+float64(-0)
+math.Float64frombits(0x7ff0000000000000)
+math.Float64frombits(0xfff0000000000000)
+math.Float32frombits(0x7f800000)
+math.Float64frombits(0x7ff8000000000002)
+math.Float32frombits(0x7fc00001)`,
+			ok: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.in, func(t *testing.T) {
+			vals, err := unmarshalCorpusFile([]byte(test.in))
+			if test.ok && err != nil {
+				t.Fatalf("unmarshal unexpected error: %v", err)
+			} else if !test.ok && err == nil {
+				t.Fatalf("unmarshal unexpected success")
+			}
+			if !test.ok {
+				return // skip the rest of the test
+			}
+			newB := marshalCorpusFile(vals...)
+			if newB[len(newB)-1] != '\n' {
+				t.Error("didn't write final newline to corpus file")
+			}
+
+			// Reading the marshaled file back must produce the same values.
+			newVals, err := unmarshalCorpusFile(newB)
+			if err != nil {
+				t.Fatalf("unmarshal of marshaled values failed: %v\n%s", err, newB)
+			}
+			if len(vals) != len(newVals) {
+				t.Fatalf("got %d values after round trip, want %d", len(newVals), len(vals))
+			}
+			for i := range vals {
+				if !sameValue(vals[i], newVals[i]) {
+					t.Errorf("value %d: got %#v after round trip, want %#v", i, newVals[i], vals[i])
+				}
+			}
+		})
+	}
+}
+
+// sameValue reports whether a and b have the same type and value, treating
+// floating-point values as equal when their bit patterns are equal.
+func sameValue(a, b interface{}) bool {
+	switch a := a.(type) {
+	case float64:
+		b, ok := b.(float64)
+		return ok && math.Float64bits(a) == math.Float64bits(b)
+	case float32:
+		b, ok := b.(float32)
+		return ok && math.Float32bits(a) == math.Float32bits(b)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func TestMarshalUnmarshalNaNBits(t *testing.T) {
+	nan64 := math.Float64frombits(0x7ff8000000000002)
+	nan32 := math.Float32frombits(0x7fc00001)
+	b := marshalCorpusFile(nan64, nan32)
+	if !strings.Contains(string(b), "0x7ff8000000000002") || !strings.Contains(string(b), "0x7fc00001") {
+		t.Fatalf("NaN bit patterns not preserved in encoding:\n%s", b)
+	}
+	vals, err := unmarshalCorpusFile(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := math.Float64bits(vals[0].(float64)); got != 0x7ff8000000000002 {
+		t.Errorf("got float64 bits %s, want 0x7ff8000000000002", strconv.FormatUint(got, 16))
+	}
+	if got := math.Float32bits(vals[1].(float32)); got != 0x7fc00001 {
+		t.Errorf("got float32 bits %s, want 0x7fc00001", strconv.FormatUint(uint64(got), 16))
+	}
+}
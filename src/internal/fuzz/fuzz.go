@@ -0,0 +1,618 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package fuzz provides common fuzzing functionality for tests built with
+// "go test" and for programs that use fuzzing functionality in the testing
+// package.
+package fuzz
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// CorpusEntry represents an individual input for fuzzing.
+//
+// We must use an equivalent type in the testing and testing/internal/testdeps
+// packages, but testing can't import this package directly, and we don't want
+// to export this type from testing. Instead, we use the same struct type and
+// use a type alias (not a defined type) for convenience.
+type CorpusEntry = struct {
+	Parent string
+
+	// Path is the path of the corpus file, if the entry was loaded from disk.
+	// For other entries, including seed values provided by f.Add, Path is the
+	// name of the test, e.g. seed#0.
+	Path string
+
+	// Data is the raw input data. Data should only be populated for entries
+	// that were loaded from disk or found by the fuzzer.
+	Data []byte
+
+	// Values is the unmarshaled values from a corpus file.
+	Values []interface{}
+
+	Generation int
+
+	// IsSeed indicates whether this entry is part of the seed corpus.
+	IsSeed bool
+}
+
+// CoordinateFuzzingOpts is a set of arguments for CoordinateFuzzing.
+// The zero value is valid for each field unless specified otherwise.
+type CoordinateFuzzingOpts struct {
+	// Log is a writer for logging progress messages and warnings.
+	// If nil, ioutil.Discard will be used instead.
+	Log io.Writer
+
+	// Timeout is the amount of wall clock time to spend fuzzing after the
+	// corpus has loaded. If zero, there will be no time limit.
+	Timeout time.Duration
+
+	// MinimizeTimeout is the amount of wall clock time to spend minimizing
+	// an input after finding a crash. If zero, the input is not minimized.
+	MinimizeTimeout time.Duration
+
+	// Parallel is the number of worker processes to run in parallel. If zero,
+	// CoordinateFuzzing will run GOMAXPROCS workers.
+	Parallel int
+
+	// Seed is a list of seed values added by the fuzz target with testing.F.Add
+	// and in testdata.
+	Seed []CorpusEntry
+
+	// Types is the list of types which make up a corpus entry.
+	// Types must be set and must match values in Seed.
+	Types []reflect.Type
+
+	// CorpusDir is a directory where files containing values that crash the
+	// code being tested may be written. CorpusDir must be set.
+	CorpusDir string
+
+	// CacheDir is a directory containing additional "interesting" values.
+	// The fuzzer may derive new values from these, and may write new values here.
+	CacheDir string
+}
+
+// CoordinateFuzzing creates several worker processes and communicates with
+// them to test random inputs that could trigger crashes and expose bugs.
+// The worker processes run the same binary in the same directory with the
+// same environment variables as the coordinator process. Workers also run
+// with the same arguments as the coordinator, except with the -test.fuzzworker
+// flag prepended to the argument list.
+//
+// If a crash occurs, the function will return an error containing information
+// about the crash, which can be reported to the user.
+func CoordinateFuzzing(ctx context.Context, opts CoordinateFuzzingOpts) (err error) {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !fuzzSupported {
+		return fmt.Errorf("fuzzing is not supported on %s/%s", runtime.GOOS, runtime.GOARCH)
+	}
+	if opts.Log == nil {
+		opts.Log = ioutil.Discard
+	}
+	if opts.Parallel == 0 {
+		opts.Parallel = runtime.GOMAXPROCS(0)
+	}
+	if opts.Timeout > 0 {
+		var cancel func()
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	c, err := newCoordinator(opts)
+	if err != nil {
+		return err
+	}
+
+	// Workers stop when ctx is done: when the time limit is reached, the
+	// user interrupts the coordinator, or a crash is found.
+	ctx, cancelWorkers := context.WithCancel(ctx)
+	defer cancelWorkers()
+
+	// Start workers.
+	dir := "" // same as self
+	binPath := os.Args[0]
+	args := append([]string{"-test.fuzzworker"}, os.Args[1:]...)
+	env := os.Environ() // same as self
+
+	errC := make(chan error)
+	workers := make([]*worker, opts.Parallel)
+	for i := range workers {
+		workers[i], err = newWorker(c, dir, binPath, args, env)
+		if err != nil {
+			for _, w := range workers[:i] {
+				w.cleanup()
+			}
+			return err
+		}
+	}
+	for i := range workers {
+		w := workers[i]
+		go func() {
+			err := w.coordinate(ctx)
+			if cleanErr := w.cleanup(); err == nil {
+				err = cleanErr
+			}
+			errC <- err
+		}()
+	}
+
+	// Main event loop.
+	// Do not return until all workers have terminated. We avoid a deadlock by
+	// receiving messages from workers even after ctx is canceled.
+	activeWorkers := len(workers)
+	statTicker := time.NewTicker(3 * time.Second)
+	defer statTicker.Stop()
+	defer c.logStats()
+
+	c.logStats()
+	var crashErr error
+	for {
+		var inputC chan fuzzInput
+		input, ok := c.peekInput()
+		if ok && ctx.Err() == nil {
+			inputC = c.inputC
+		}
+
+		select {
+		case err := <-errC:
+			// A worker terminated, possibly after encountering a fatal error.
+			activeWorkers--
+			if err != nil && crashErr == nil {
+				crashErr = err
+				cancelWorkers()
+			}
+			if activeWorkers == 0 {
+				return crashErr
+			}
+
+		case result := <-c.resultC:
+			// Received response from worker.
+			if crashErr != nil {
+				// A crash was already reported; ignore the rest.
+				break
+			}
+			c.updateStats(result)
+
+			if result.crasherMsg != "" {
+				if result.entry.IsSeed {
+					crashErr = fmt.Errorf("%s\nseed corpus entry %s failed", result.crasherMsg, result.entry.Path)
+				} else {
+					crashErr = c.writeCrasher(result)
+				}
+				cancelWorkers()
+				break
+			}
+
+			if c.warmupRun() {
+				// Merge the coverage of every entry in the starting corpus
+				// into the global coverage.
+				if result.coverageData != nil {
+					c.updateCoverage(result.coverageData)
+				}
+				c.warmupInputLeft--
+				if c.warmupInputLeft == 0 {
+					what := "gathering baseline coverage"
+					if len(c.coverageMask) == 0 {
+						what = "testing seed corpus"
+					}
+					fmt.Fprintf(c.opts.Log, "fuzz: elapsed: %s, %s: %d/%d completed, now fuzzing with %d workers\n", c.elapsed(), what, c.warmupInputCount, c.warmupInputCount, c.opts.Parallel)
+				}
+			} else if result.coverageData != nil && diffCoverage(c.coverageMask, result.coverageData) != nil {
+				// The input expanded the coverage. Save it so it can be
+				// mutated further.
+				c.updateCoverage(result.coverageData)
+				c.corpus = append(c.corpus, result.entry)
+				c.interestingCount++
+				if err := c.writeToCache(result.entry); err != nil {
+					fmt.Fprintf(c.opts.Log, "fuzz: failed to cache interesting input: %v\n", err)
+				}
+			}
+
+		case inputC <- input:
+			// Sent the next input to a worker.
+			c.sentInput(input)
+
+		case <-statTicker.C:
+			c.logStats()
+		}
+	}
+}
+
+// crashError wraps a crasher written to the seed corpus. It saves the name
+// of the file where the input causing the crasher was saved. The testing
+// framework uses this to report a command to re-run that specific input.
+type crashError struct {
+	path string
+	err  error
+}
+
+func (e *crashError) Error() string {
+	return e.err.Error()
+}
+
+func (e *crashError) Unwrap() error {
+	return e.err
+}
+
+// CrashPath returns the path of the file in the seed corpus containing the
+// input that caused the crash.
+func (e *crashError) CrashPath() string {
+	return e.path
+}
+
+// ReadCorpus reads the corpus from the provided dir. The returned corpus
+// entries are guaranteed to match the given types. Any malformed files will
+// be saved in a MalformedCorpusError and returned, along with the most recent
+// error. A missing directory yields an empty corpus.
+func ReadCorpus(dir string, types []reflect.Type) ([]CorpusEntry, error) {
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil // No corpus to read
+	} else if err != nil {
+		return nil, fmt.Errorf("reading seed corpus from testdata: %v", err)
+	}
+	var corpus []CorpusEntry
+	var errs []error
+	for _, file := range files {
+		// TODO: Consider reading the corpus recursively. One
+		// reason not to is that the subdirectories may be used
+		// for other test data.
+		if file.IsDir() {
+			continue
+		}
+		filename := filepath.Join(dir, file.Name())
+		data, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read corpus file: %v", err)
+		}
+		var vals []interface{}
+		vals, err = readCorpusData(data, types)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%q: %v", filename, err))
+			continue
+		}
+		corpus = append(corpus, CorpusEntry{Path: filename, Data: data, Values: vals})
+	}
+	if len(errs) > 0 {
+		return corpus, &MalformedCorpusError{errs: errs}
+	}
+	return corpus, nil
+}
+
+func readCorpusData(data []byte, types []reflect.Type) ([]interface{}, error) {
+	vals, err := unmarshalCorpusFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal: %v", err)
+	}
+	if err = CheckCorpus(vals, types); err != nil {
+		return nil, err
+	}
+	return vals, nil
+}
+
+// CheckCorpus verifies that the types in vals match the expected types
+// provided.
+func CheckCorpus(vals []interface{}, types []reflect.Type) error {
+	if len(vals) != len(types) {
+		return fmt.Errorf("wrong number of values in corpus entry: %d, want %d", len(vals), len(types))
+	}
+	valsT := make([]reflect.Type, len(vals))
+	for valsI, v := range vals {
+		valsT[valsI] = reflect.TypeOf(v)
+	}
+	for i := range types {
+		if valsT[i] != types[i] {
+			return fmt.Errorf("mismatched types in corpus entry: %v, want %v", valsT, types)
+		}
+	}
+	return nil
+}
+
+// MalformedCorpusError is an error found while reading the corpus from the
+// filesystem. All of the errors are stored in the errs list. The testing
+// framework uses this to report malformed files in testdata.
+type MalformedCorpusError struct {
+	errs []error
+}
+
+func (e *MalformedCorpusError) Error() string {
+	var msgs []string
+	for _, s := range e.errs {
+		msgs = append(msgs, s.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// writeToCorpus atomically writes the given bytes to a new file in testdata.
+// If the directory does not exist, it will create one. If the file already
+// exists, writeToCorpus will not rewrite it. writeToCorpus returns the
+// file's name, or an error if it failed.
+func writeToCorpus(data []byte, dir string) (name string, err error) {
+	sum := fmt.Sprintf("%x", sha256.Sum256(data))[:16]
+	name = filepath.Join(dir, sum)
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return "", err
+	}
+	if err := ioutil.WriteFile(name, data, 0666); err != nil {
+		os.Remove(name) // remove partially written file
+		return "", err
+	}
+	return name, nil
+}
+
+// fuzzInput is the input the coordinator sends to a worker: a corpus entry
+// to mutate, or, during the warmup phase, an entry to run as is.
+type fuzzInput struct {
+	// entry is the value to test initially. The worker will randomly mutate
+	// values from this starting point.
+	entry CorpusEntry
+
+	// timeout is the time to spend fuzzing variations of this input,
+	// not including starting or cleaning up.
+	timeout time.Duration
+
+	// warmup indicates whether this is a warmup input before fuzzing begins.
+	// If true, the input should not be mutated.
+	warmup bool
+
+	// coverageData reflects the coordinator's current coverageMask.
+	coverageData []byte
+}
+
+// fuzzResult is the outcome of a fuzzInput, reported by a worker.
+type fuzzResult struct {
+	// entry is an interesting value or a crasher.
+	entry CorpusEntry
+
+	// crasherMsg is an error message from a crash. It's "" if no crash was found.
+	crasherMsg string
+
+	// coverageData is set if the worker found new coverage.
+	coverageData []byte
+
+	// count is the number of values the worker actually tested.
+	count int64
+
+	// totalDuration is the time the worker spent testing inputs.
+	totalDuration time.Duration
+}
+
+// coordinator holds channels that workers can use to communicate with
+// the coordinator.
+type coordinator struct {
+	opts CoordinateFuzzingOpts
+
+	// startTime is the time we started the workers after reading the corpus.
+	// Used for logging.
+	startTime time.Time
+
+	// inputC is sent values to fuzz by the coordinator. Any worker may receive
+	// values from this channel. Workers send results to resultC.
+	inputC chan fuzzInput
+
+	// resultC is sent results of fuzzing by workers. The coordinator
+	// receives these. Multiple types of messages are allowed.
+	resultC chan fuzzResult
+
+	// count is the number of values fuzzed so far.
+	count int64
+
+	// countLastLog is the number of values fuzzed when the output was last
+	// logged.
+	countLastLog int64
+
+	// timeLastLog is the time at which the output was last logged.
+	timeLastLog time.Time
+
+	// interestingCount is the number of unique interesting values which have
+	// been found this execution.
+	interestingCount int
+
+	// warmupInputCount is the count of all entries in the corpus which will
+	// need to be received from workers to run once during warmup, but not
+	// fuzz. This could be for coverage data, or only for the purposes of
+	// verifying that the seed corpus doesn't have any crashers.
+	warmupInputCount int
+
+	// warmupInputLeft is the number of entries in the corpus which still need
+	// to be received from workers to run once during warmup, but not fuzz.
+	warmupInputLeft int
+
+	// corpus is a set of interesting values, including the seed corpus and
+	// generated values that workers reported as interesting.
+	corpus []CorpusEntry
+
+	// corpusIndex is the next value to send to workers.
+	corpusIndex int
+
+	// coverageMask aggregates coverage that was found for all inputs in the
+	// corpus. Each byte represents a single basic execution block. Each set
+	// bit within the byte indicates that an input has triggered that block
+	// at least 1 << n times, where n is the position of the bit in the byte.
+	coverageMask []byte
+}
+
+func newCoordinator(opts CoordinateFuzzingOpts) (*coordinator, error) {
+	// Make sure all of the seed corpus given by f.Add has marshalled data.
+	for i := range opts.Seed {
+		if opts.Seed[i].Data == nil && opts.Seed[i].Values != nil {
+			opts.Seed[i].Data = marshalCorpusFile(opts.Seed[i].Values...)
+		}
+	}
+	c := &coordinator{
+		opts:         opts,
+		startTime:    time.Now(),
+		inputC:       make(chan fuzzInput),
+		resultC:      make(chan fuzzResult),
+		timeLastLog:  time.Now(),
+		coverageMask: make([]byte, len(coverage())),
+	}
+	if err := c.readCache(); err != nil {
+		return nil, err
+	}
+	if len(c.corpus) == 0 {
+		// No seed corpus and no cache: start from the zero values of the
+		// fuzz function's arguments.
+		vals := make([]interface{}, len(opts.Types))
+		for i, t := range opts.Types {
+			vals[i] = zeroValue(t)
+		}
+		data := marshalCorpusFile(vals...)
+		c.corpus = append(c.corpus, CorpusEntry{Data: data, Values: vals})
+	}
+	c.warmupInputCount = len(c.corpus)
+	c.warmupInputLeft = c.warmupInputCount
+	return c, nil
+}
+
+// updateStats records the number of values tested and the time spent
+// testing them.
+func (c *coordinator) updateStats(result fuzzResult) {
+	c.count += result.count
+}
+
+func (c *coordinator) elapsed() time.Duration {
+	return time.Since(c.startTime).Round(1 * time.Second)
+}
+
+func (c *coordinator) logStats() {
+	now := time.Now()
+	if c.warmupRun() {
+		runSoFar := c.warmupInputCount - c.warmupInputLeft
+		if len(c.coverageMask) > 0 {
+			fmt.Fprintf(c.opts.Log, "fuzz: elapsed: %s, gathering baseline coverage: %d/%d completed\n", c.elapsed(), runSoFar, c.warmupInputCount)
+		} else {
+			fmt.Fprintf(c.opts.Log, "fuzz: elapsed: %s, testing seed corpus: %d/%d completed\n", c.elapsed(), runSoFar, c.warmupInputCount)
+		}
+		return
+	}
+	rate := float64(c.count-c.countLastLog) / now.Sub(c.timeLastLog).Seconds()
+	if len(c.coverageMask) > 0 {
+		total := c.warmupInputCount + c.interestingCount
+		fmt.Fprintf(c.opts.Log, "fuzz: elapsed: %s, execs: %d (%.0f/sec), new interesting: %d (total: %d)\n", c.elapsed(), c.count, rate, c.interestingCount, total)
+	} else {
+		fmt.Fprintf(c.opts.Log, "fuzz: elapsed: %s, execs: %d (%.0f/sec)\n", c.elapsed(), c.count, rate)
+	}
+	c.countLastLog = c.count
+	c.timeLastLog = now
+}
+
+// peekInput returns the next value that should be sent to workers.
+// If the number of executions is limited, the returned value includes
+// a limit for one worker. If there are no executions left, peekInput
+// returns a zero value and false.
+//
+// peekInput doesn't actually remove the input from the queue. The caller
+// must call sentInput after sending the input.
+//
+// If the input queue is empty and the coverage/testing-only run has
+// completed, queue refills are not needed: the corpus is used round-robin.
+func (c *coordinator) peekInput() (fuzzInput, bool) {
+	if c.warmupRun() && c.corpusIndex >= c.warmupInputCount {
+		// Wait for coverage of the starting corpus before mutating.
+		return fuzzInput{}, false
+	}
+	entry := c.corpus[c.corpusIndex%len(c.corpus)]
+	input := fuzzInput{
+		entry:   entry,
+		timeout: workerFuzzDuration,
+		warmup:  c.warmupRun(),
+	}
+	if len(c.coverageMask) > 0 && !input.warmup {
+		input.coverageData = make([]byte, len(c.coverageMask))
+		copy(input.coverageData, c.coverageMask)
+	}
+	return input, true
+}
+
+// sentInput updates internal counters after an input is sent to c.inputC.
+func (c *coordinator) sentInput(input fuzzInput) {
+	c.corpusIndex++
+}
+
+// warmupRun returns true while the coordinator is running inputs without
+// mutating them as a warmup before fuzzing. This could be to gather baseline
+// coverage data for entries in the corpus, or to test all of the seed corpus
+// for errors before fuzzing begins.
+func (c *coordinator) warmupRun() bool {
+	return c.warmupInputLeft > 0
+}
+
+// updateCoverage sets bits in c.coverageMask that are set in newCoverage.
+// updateCoverage returns the number of newly set bits.
+func (c *coordinator) updateCoverage(newCoverage []byte) int {
+	if len(newCoverage) != len(c.coverageMask) {
+		panic(fmt.Sprintf("number of coverage counters changed at runtime: %d, expected %d", len(newCoverage), len(c.coverageMask)))
+	}
+	newBitCount := 0
+	for i := range newCoverage {
+		diff := newCoverage[i] &^ c.coverageMask[i]
+		newBitCount += countBits([]byte{diff})
+		c.coverageMask[i] |= newCoverage[i]
+	}
+	return newBitCount
+}
+
+// readCache creates a combined corpus from seed values and values in the
+// cache (in GOCACHE/fuzz). Malformed or mismatched cache entries are
+// ignored, since the fuzz function may have changed since they were written.
+func (c *coordinator) readCache() error {
+	for _, e := range c.opts.Seed {
+		c.corpus = append(c.corpus, e)
+	}
+	if c.opts.CacheDir == "" {
+		return nil
+	}
+	entries, err := ReadCorpus(c.opts.CacheDir, c.opts.Types)
+	if err != nil {
+		if _, ok := err.(*MalformedCorpusError); !ok {
+			return err
+		}
+	}
+	c.corpus = append(c.corpus, entries...)
+	return nil
+}
+
+// writeToCache saves an interesting input in the cache directory so that
+// later runs can start from it.
+func (c *coordinator) writeToCache(e CorpusEntry) error {
+	if c.opts.CacheDir == "" {
+		return nil
+	}
+	_, err := writeToCorpus(e.Data, c.opts.CacheDir)
+	return err
+}
+
+// writeCrasher saves a failing input in the seed corpus and returns the
+// error to report to the user.
+func (c *coordinator) writeCrasher(result fuzzResult) error {
+	path, err := writeToCorpus(result.entry.Data, c.opts.CorpusDir)
+	if err != nil {
+		return fmt.Errorf("%s\nfuzzing found an error, but could not write the failing input: %v", result.crasherMsg, err)
+	}
+	return &crashError{path: path, err: errors.New(result.crasherMsg)}
+}
+
+// zeroValue returns the zero value of t as an interface, using a non-nil
+// empty slice for []byte.
+func zeroValue(t reflect.Type) interface{} {
+	if t.Kind() == reflect.Slice {
+		return reflect.MakeSlice(t, 0, 0).Interface()
+	}
+	return reflect.Zero(t).Interface()
+}
@@ -0,0 +1,118 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fuzz
+
+// minimizeInput reduces the []byte and string values in vals in place,
+// keeping each reduction for which try reports that the input still fails.
+// Values of other types are left unchanged. minimizeInput returns early
+// once shouldStop reports true.
+func minimizeInput(vals []interface{}, try func([]interface{}) bool, shouldStop func() bool) {
+	for i, v := range vals {
+		switch v := v.(type) {
+		case []byte:
+			tryBytes := func(b []byte) bool {
+				vals[i] = append([]byte(nil), b...)
+				if try(vals) {
+					return true
+				}
+				vals[i] = v
+				return false
+			}
+			v = minimizeBytes(v, tryBytes, shouldStop)
+			vals[i] = v
+		case string:
+			tryString := func(b []byte) bool {
+				vals[i] = string(b)
+				if try(vals) {
+					return true
+				}
+				vals[i] = v
+				return false
+			}
+			vals[i] = string(minimizeBytes([]byte(v), tryString, shouldStop))
+		}
+		if shouldStop() {
+			return
+		}
+	}
+}
+
+// minimizeBytes returns the smallest variant of v it could find for which
+// try returns true. try must not retain its argument.
+func minimizeBytes(v []byte, try func([]byte) bool, shouldStop func() bool) []byte {
+	tmp := make([]byte, len(v))
+
+	// First, try to cut the tail.
+	for n := 1024; n != 0; n /= 2 {
+		for len(v) > n {
+			if shouldStop() {
+				return v
+			}
+			candidate := v[:len(v)-n]
+			if !try(candidate) {
+				break
+			}
+			// Set v to the new value to continue iterating.
+			v = candidate
+		}
+	}
+
+	// Then, try to remove each individual byte.
+	for i := 0; i < len(v)-1; i++ {
+		if shouldStop() {
+			return v
+		}
+		candidate := tmp[:len(v)-1]
+		copy(candidate[:i], v[:i])
+		copy(candidate[i:], v[i+1:])
+		if !try(candidate) {
+			continue
+		}
+		// Update v to delete the value at index i.
+		copy(v[i:], v[i+1:])
+		v = v[:len(candidate)]
+		// v[i] is now different, so redo v[i].
+		i--
+	}
+
+	// Then, try to remove each possible subset of bytes.
+	for i := 0; i < len(v)-1; i++ {
+		copy(tmp, v[:i])
+		for j := len(v); j > i+1; j-- {
+			if shouldStop() {
+				return v
+			}
+			candidate := tmp[:len(v)-j+i]
+			copy(candidate[i:], v[j:])
+			if !try(candidate) {
+				continue
+			}
+			// Update v and reset the loop with the new length.
+			copy(v[i:], v[j:])
+			v = v[:len(candidate)]
+			j = len(v)
+		}
+	}
+
+	// Finally, try to make the value more human-readable by replacing
+	// each byte with a printable character.
+	printableChars := []byte("012789ABCXYZabcxyz !\"#$%&'()*+,.")
+	for i, b := range v {
+		if shouldStop() {
+			return v
+		}
+		for _, pc := range printableChars {
+			if b == pc {
+				break
+			}
+			v[i] = pc
+			if try(v) {
+				break
+			}
+			v[i] = b
+		}
+	}
+	return v
+}
@@ -0,0 +1,91 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fuzz
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestMinimizeInput(t *testing.T) {
+	never := func() bool { return false }
+	type testcase struct {
+		name     string
+		input    []interface{}
+		fails    func([]interface{}) bool
+		expected []interface{}
+	}
+	cases := []testcase{
+		{
+			name:  "ones_byte",
+			input: []interface{}{[]byte{0, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}},
+			fails: func(vals []interface{}) bool {
+				return bytes.Count(vals[0].([]byte), []byte{1}) >= 3
+			},
+			expected: []interface{}{[]byte{1, 1, 1}},
+		},
+		{
+			name:  "single_byte",
+			input: []interface{}{[]byte("12345")},
+			fails: func(vals []interface{}) bool {
+				return bytes.IndexByte(vals[0].([]byte), '3') >= 0
+			},
+			expected: []interface{}{[]byte("3")},
+		},
+		{
+			name:  "ones_string",
+			input: []interface{}{"001010001000000000000000000"},
+			fails: func(vals []interface{}) bool {
+				return bytes.Count([]byte(vals[0].(string)), []byte("1")) >= 3
+			},
+			expected: []interface{}{"111"},
+		},
+		{
+			name:  "printable",
+			input: []interface{}{[]byte{0xff, 0xfe, 'x', 0xfd}},
+			fails: func(vals []interface{}) bool {
+				return len(vals[0].([]byte)) >= 2
+			},
+			expected: []interface{}{[]byte("00")},
+		},
+		{
+			name:  "other_types_unchanged",
+			input: []interface{}{int(7), []byte("abc"), true},
+			fails: func(vals []interface{}) bool {
+				return vals[0].(int) == 7 && vals[2].(bool)
+			},
+			// minimizeBytes never removes the last byte of a value.
+			expected: []interface{}{int(7), []byte("0"), true},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			vals := append([]interface{}(nil), tc.input...)
+			minimizeInput(vals, tc.fails, never)
+			if !reflect.DeepEqual(vals, tc.expected) {
+				t.Errorf("got %#v, want %#v", vals, tc.expected)
+			}
+		})
+	}
+}
+
+func TestMinimizeInputStops(t *testing.T) {
+	calls := 0
+	fails := func([]interface{}) bool {
+		calls++
+		return true
+	}
+	stop := func() bool { return calls >= 1 }
+	vals := []interface{}{[]byte("abcdefgh"), "abcdefgh"}
+	minimizeInput(vals, fails, stop)
+	if calls != 1 {
+		t.Errorf("minimizeInput called try %d times after shouldStop reported true, want 1", calls)
+	}
+	if vals[1] != "abcdefgh" {
+		t.Errorf("second value was minimized after shouldStop reported true: %q", vals[1])
+	}
+}
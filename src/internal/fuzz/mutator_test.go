@@ -0,0 +1,68 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fuzz
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMutateBytesSize(t *testing.T) {
+	const maxBytes = 1000
+	maxPerVal := maxBytes/2 - 100
+	m := newMutator(1)
+	vals := []interface{}{[]byte("abc"), "def"}
+	for i := 0; i < 10000; i++ {
+		m.mutate(vals, maxBytes)
+		for _, v := range vals {
+			var n int
+			switch v := v.(type) {
+			case []byte:
+				n = len(v)
+			case string:
+				n = len(v)
+			}
+			if n > maxPerVal {
+				t.Fatalf("mutated value has length %d, limit is %d", n, maxPerVal)
+			}
+		}
+	}
+}
+
+func TestMutateDoesNotAlias(t *testing.T) {
+	m := newMutator(1)
+	orig := []byte("hello, world")
+	vals := []interface{}{orig}
+	var prev []byte
+	for i := 0; i < 100; i++ {
+		vals[0] = orig
+		m.mutate(vals, workerSharedMemSize)
+		if !bytes.Equal(orig, []byte("hello, world")) {
+			t.Fatalf("mutate modified its input: %q", orig)
+		}
+		b := vals[0].([]byte)
+		if prev != nil && len(prev) > 0 && len(b) > 0 && &prev[0] == &b[0] {
+			t.Fatalf("mutate returned the same backing array twice")
+		}
+		prev = b
+	}
+}
+
+func TestMutateAllTypes(t *testing.T) {
+	m := newMutator(1)
+	vals := []interface{}{
+		int(0), int8(0), int16(0), int32(0), int64(0),
+		uint(0), uint16(0), uint32(0), uint64(0),
+		float32(0), float64(0), bool(false), byte(0),
+		"", []byte(nil),
+	}
+	for i := 0; i < 10000; i++ {
+		m.mutate(vals, workerSharedMemSize)
+	}
+	// Each mutated value must still encode and decode successfully.
+	if _, err := unmarshalCorpusFile(marshalCorpusFile(vals...)); err != nil {
+		t.Fatal(err)
+	}
+}
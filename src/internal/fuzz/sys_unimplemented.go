@@ -0,0 +1,34 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+// TODO: pass the worker pipes and shared memory on Windows, where
+// exec.Cmd.ExtraFiles is not supported.
+
+package fuzz
+
+import (
+	"errors"
+	"os/exec"
+)
+
+const (
+	fuzzSupported      = false
+	interruptSupported = false
+)
+
+var errNotSupported = errors.New("fuzzing is not supported on this platform")
+
+func setWorkerComm(cmd *exec.Cmd, comm workerComm) {
+	panic("not implemented")
+}
+
+func getWorkerComm() (comm workerComm, err error) {
+	return workerComm{}, errNotSupported
+}
+
+func isInterruptError(err error) bool {
+	return false
+}
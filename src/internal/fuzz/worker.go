@@ -0,0 +1,816 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fuzz
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+const (
+	// workerFuzzDuration is the amount of time a worker can spend testing
+	// random variations of an input given by the coordinator.
+	workerFuzzDuration = 100 * time.Millisecond
+
+	// workerTimeoutDuration is the amount of time a worker can go without
+	// responding to the coordinator before being stopped.
+	workerTimeoutDuration = 1 * time.Second
+
+	// workerExitCode is used as an exit code by fuzz worker processes after an
+	// internal error. This distinguishes internal errors from uncontrolled
+	// panics and other crashes.
+	workerExitCode = 70
+
+	// workerSharedMemSize is the maximum size of the shared memory file used
+	// to communicate with workers. This limits the size of fuzz inputs.
+	workerSharedMemSize = 100 << 20 // 100 MB
+)
+
+// worker manages a worker process running a test binary. The worker object
+// exists only in the coordinator (the process started by 'go test -fuzz').
+// workerClient is used by the coordinator to send RPCs to the worker process,
+// which handles them with workerServer.
+type worker struct {
+	dir     string   // working directory, same as package directory
+	binPath string   // path to test executable
+	args    []string // arguments for test executable
+	env     []string // environment for test executable
+
+	coordinator *coordinator
+
+	memFile *os.File // input for the worker; written before each call
+
+	cmd     *exec.Cmd     // current worker process
+	client  *workerClient // used to communicate with worker process
+	waitErr error         // last error returned by wait, set before termC is closed.
+	stderr  *tailBuffer   // last output written by the worker to stderr
+	termC   chan struct{} // closed by wait when worker process terminates
+}
+
+func newWorker(c *coordinator, dir, binPath string, args, env []string) (*worker, error) {
+	memFile, err := ioutil.TempFile("", "fuzz-mem-")
+	if err != nil {
+		return nil, err
+	}
+	return &worker{
+		dir:         dir,
+		binPath:     binPath,
+		args:        args,
+		env:         env[:len(env):len(env)], // copy on append to ensure workers don't overwrite each other.
+		coordinator: c,
+		memFile:     memFile,
+	}, nil
+}
+
+// cleanup releases persistent resources associated with the worker.
+func (w *worker) cleanup() error {
+	if w.memFile == nil {
+		return nil
+	}
+	name := w.memFile.Name()
+	err := w.memFile.Close()
+	if rmErr := os.Remove(name); err == nil {
+		err = rmErr
+	}
+	w.memFile = nil
+	return err
+}
+
+// coordinate runs the test binary to perform fuzzing.
+//
+// coordinate loops until ctx is cancelled or a fatal error is encountered.
+// If a test process terminates unexpectedly while fuzzing, coordinate will
+// attempt to restart and continue unless the termination can be attributed
+// to an interruption (from a timer or the user).
+//
+// While looping, coordinate receives inputs from the coordinator, passes
+// those inputs to the worker process, then passes the results back to
+// the coordinator.
+func (w *worker) coordinate(ctx context.Context) error {
+	// Main event loop.
+	for {
+		// Start or restart the worker if it's not running.
+		if !w.isRunning() {
+			if err := w.startAndPing(ctx); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			// Worker was told to stop.
+			err := w.stop()
+			if err != nil && !w.interrupted() && !isInterruptError(err) {
+				return err
+			}
+			return nil
+
+		case <-w.termC:
+			// Worker process terminated unexpectedly while waiting for input.
+			err := w.stop()
+			if w.interrupted() {
+				return nil
+			}
+			if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == workerExitCode {
+				return fmt.Errorf("fuzzing process exited unexpectedly due to an internal failure: %v", err)
+			}
+			return fmt.Errorf("fuzzing process terminated unexpectedly while waiting for input: %v", err)
+
+		case input := <-w.coordinator.inputC:
+			// Received input from coordinator.
+			args := fuzzArgs{
+				Data:         input.entry.Data,
+				Timeout:      input.timeout,
+				Warmup:       input.warmup,
+				CoverageData: input.coverageData,
+			}
+			resp, err := w.client.fuzz(args)
+			result := fuzzResult{
+				count:         resp.Count,
+				totalDuration: resp.TotalDuration,
+				entry:         input.entry,
+			}
+			if err != nil {
+				// Error communicating with worker: the process most likely
+				// crashed. The last input it wrote to memFile caused the crash.
+				w.stop()
+				if ctx.Err() != nil && w.interrupted() {
+					// Timeout or interruption.
+					return nil
+				}
+				data, readErr := w.readMem()
+				if readErr != nil {
+					return readErr
+				}
+				result.crasherMsg = w.crashMessage()
+				result.entry = w.mutatedEntry(input, data)
+				if !input.warmup {
+					result.entry.Data, result.crasherMsg = w.minimize(result.entry.Data, result.crasherMsg)
+				}
+			} else if resp.Err != "" {
+				// Worker found a crasher.
+				result.crasherMsg = resp.Err
+				result.entry = w.mutatedEntry(input, resp.Data)
+				if !input.warmup {
+					result.entry.Data, result.crasherMsg = w.minimize(result.entry.Data, result.crasherMsg)
+				}
+			} else if resp.CoverageData != nil {
+				result.coverageData = resp.CoverageData
+				if !input.warmup {
+					// Found new coverage.
+					result.entry = w.mutatedEntry(input, resp.Data)
+				}
+			}
+			w.coordinator.resultC <- result
+		}
+	}
+}
+
+// mutatedEntry returns a new corpus entry derived from input with the
+// given data. Entries run during warmup are returned unchanged.
+func (w *worker) mutatedEntry(input fuzzInput, data []byte) CorpusEntry {
+	if input.warmup {
+		return input.entry
+	}
+	return CorpusEntry{
+		Parent:     input.entry.Path,
+		Data:       data,
+		Generation: input.entry.Generation + 1,
+	}
+}
+
+// minimize tries to find a smaller input that fails the same way as data.
+// The search runs in the worker process, so it survives the process
+// crashing on a candidate: the candidate written to memFile then becomes
+// the new smallest known failing input and the process is restarted.
+// minimize gives up after MinimizeTimeout and returns the smallest failing
+// input found along with the error it caused.
+func (w *worker) minimize(data []byte, msg string) ([]byte, string) {
+	timeout := w.coordinator.opts.MinimizeTimeout
+	if timeout <= 0 {
+		return data, msg
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		left := time.Until(deadline)
+		if left <= 0 {
+			return data, msg
+		}
+		if !w.isRunning() {
+			if err := w.startAndPing(context.Background()); err != nil {
+				return data, msg
+			}
+		}
+		resp, err := w.client.minimize(minimizeArgs{Data: data, Timeout: left})
+		if err == nil {
+			if resp.Data != nil {
+				data, msg = resp.Data, resp.Err
+			}
+			return data, msg
+		}
+		// The process died on a candidate, which is still a failing input.
+		w.stop()
+		memData, readErr := w.readMem()
+		if readErr != nil || len(memData) == 0 {
+			return data, msg
+		}
+		data, msg = memData, w.crashMessage()
+	}
+}
+
+// crashMessage returns a description of an unexpected termination of the
+// worker process, including whatever it last wrote to stderr.
+func (w *worker) crashMessage() string {
+	msg := "fuzzing process terminated unexpectedly"
+	if w.waitErr != nil {
+		msg += ": " + w.waitErr.Error()
+	}
+	if out := bytes.TrimSpace(w.stderr.Bytes()); len(out) > 0 {
+		msg += "\n" + string(out)
+	}
+	return msg
+}
+
+// readMem returns the input the worker process wrote to memFile before
+// it last ran the fuzz function.
+func (w *worker) readMem() ([]byte, error) {
+	var hdr [8]byte
+	if _, err := w.memFile.ReadAt(hdr[:], 0); err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, err
+	}
+	n := binary.LittleEndian.Uint64(hdr[:])
+	if n > workerSharedMemSize {
+		return nil, fmt.Errorf("fuzz: input in shared memory is too large: %d bytes", n)
+	}
+	data := make([]byte, n)
+	if _, err := w.memFile.ReadAt(data, int64(len(hdr))); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return data, nil
+}
+
+// startAndPing starts the worker process and sends it a message to make sure
+// it can communicate.
+//
+// startAndPing returns an error if any part of this didn't work, including if
+// the context is expired or the worker process was interrupted before it
+// responded. Errors that happen after start but before the ping response
+// likely indicate that the worker did not call F.Fuzz or called F.Fail first.
+// We don't record crashers for these errors.
+func (w *worker) startAndPing(ctx context.Context) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err := w.start(); err != nil {
+		return err
+	}
+	if err := w.client.ping(); err != nil {
+		w.stop()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if isInterruptError(err) {
+			// User may have pressed ^C before worker responded.
+			return err
+		}
+		// TODO: record and return stderr.
+		return fmt.Errorf("fuzzing process terminated without fuzzing: %v", err)
+	}
+	return nil
+}
+
+// start runs a new worker process.
+//
+// If the process couldn't be started, start returns an error. Start won't
+// return later termination errors from the process if they occur.
+//
+// If the process starts successfully, start returns nil. stop must be called
+// once later to clean up, even if the process terminates on its own.
+//
+// When the process terminates, w.waitErr is set to the error (if any), and
+// w.termC is closed.
+func (w *worker) start() (err error) {
+	if w.isRunning() {
+		panic("worker already started")
+	}
+	w.waitErr = nil
+	w.stderr = &tailBuffer{}
+
+	cmd := exec.Command(w.binPath, w.args...)
+	cmd.Dir = w.dir
+	cmd.Env = w.env
+	cmd.Stderr = w.stderr
+
+	// Create the "fuzz_in" and "fuzz_out" pipes so we can communicate with
+	// the worker. We don't use stdin and stdout, since the test binary may
+	// do something else with those.
+	//
+	// Each pipe has a reader and a writer. The coordinator writes to fuzzInW
+	// and reads from fuzzOutR. The worker inherits fuzzInR and fuzzOutW.
+	// The coordinator closes fuzzInR and fuzzOutW after starting the worker,
+	// since we have no further need of them.
+	fuzzInR, fuzzInW, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer fuzzInR.Close()
+	fuzzOutR, fuzzOutW, err := os.Pipe()
+	if err != nil {
+		fuzzInW.Close()
+		return err
+	}
+	defer fuzzOutW.Close()
+	setWorkerComm(cmd, workerComm{fuzzIn: fuzzInR, fuzzOut: fuzzOutW, memFile: w.memFile})
+
+	// Start the worker process.
+	if err := cmd.Start(); err != nil {
+		fuzzInW.Close()
+		fuzzOutR.Close()
+		return err
+	}
+
+	// Worker started successfully.
+	// After this, w.client owns fuzzInW and fuzzOutR, so w.client.Close must be
+	// called later by stop.
+	w.cmd = cmd
+	w.termC = make(chan struct{})
+	w.client = newWorkerClient(workerComm{fuzzIn: fuzzInW, fuzzOut: fuzzOutR})
+
+	go func() {
+		w.waitErr = w.cmd.Wait()
+		close(w.termC)
+	}()
+
+	return nil
+}
+
+// stop tells the worker process to exit by closing w.client, then blocks until
+// it terminates. If the worker doesn't terminate after a short time, stop
+// signals it with os.Interrupt (where supported), then os.Kill.
+//
+// stop returns the error the process terminated with, if any (same as
+// w.waitErr).
+//
+// stop must be called at least once after start returns successfully, even if
+// the worker process terminates unexpectedly.
+func (w *worker) stop() error {
+	if w.termC == nil {
+		panic("worker was not started successfully")
+	}
+	select {
+	case <-w.termC:
+		// Worker already terminated.
+		if w.client == nil {
+			// stop already called.
+			return w.waitErr
+		}
+		// Possible unexpected termination.
+		w.client.Close()
+		w.cmd = nil
+		w.client = nil
+		return w.waitErr
+	default:
+		// Worker still running.
+	}
+
+	// Tell the worker to stop by closing fuzz_in. It won't actually stop until it
+	// finishes with earlier calls.
+	closeC := make(chan struct{})
+	go func() {
+		w.client.Close()
+		close(closeC)
+	}()
+
+	sig := os.Interrupt
+	if !interruptSupported {
+		// Windows and Plan 9 do not support os.Interrupt.
+		sig = os.Kill
+	}
+
+	t := time.NewTimer(workerTimeoutDuration)
+	for {
+		select {
+		case <-w.termC:
+			// Worker terminated.
+			t.Stop()
+			<-closeC
+			w.cmd = nil
+			w.client = nil
+			return w.waitErr
+
+		case <-t.C:
+			// Timer fired before worker terminated.
+			switch sig {
+			case os.Interrupt:
+				// Try to stop the worker with SIGINT and wait a little longer.
+				w.cmd.Process.Signal(sig)
+				sig = os.Kill
+				t.Reset(workerTimeoutDuration)
+
+			case os.Kill:
+				// Try to stop the worker with SIGKILL and keep waiting forever.
+				w.cmd.Process.Signal(sig)
+				sig = nil
+				t.Reset(workerTimeoutDuration)
+
+			case nil:
+				// Still waiting. Print a message to let the user know why.
+				fmt.Fprintf(w.coordinator.opts.Log, "waiting for fuzzing process to terminate...\n")
+			}
+		}
+	}
+}
+
+// isRunning reports whether the worker process is running.
+func (w *worker) isRunning() bool {
+	return w.cmd != nil
+}
+
+// interrupted reports whether the worker process was most likely stopped
+// by a signal sent by the coordinator or the user.
+func (w *worker) interrupted() bool {
+	return isInterruptError(w.waitErr)
+}
+
+// RunFuzzWorker is called in a worker process to communicate with the
+// coordinator process in order to fuzz random inputs. RunFuzzWorker loops
+// until the coordinator tells it to stop.
+//
+// fn is a wrapper on the fuzz function. It may return an error to indicate
+// a given input "crashed". The coordinator will also record a crasher if
+// the function times out or terminates the process.
+//
+// RunFuzzWorker returns an error if it could not communicate with the
+// coordinator process.
+func RunFuzzWorker(ctx context.Context, fn func(CorpusEntry) error) error {
+	comm, err := getWorkerComm()
+	if err != nil {
+		return err
+	}
+	srv := &workerServer{
+		workerComm: comm,
+		fuzzFn:     fn,
+		m:          newMutator(time.Now().UnixNano()),
+	}
+	return srv.serve(ctx)
+}
+
+// call is serialized and sent from the coordinator on fuzz_in. It acts as
+// a minimalist RPC mechanism. Exactly one of its fields must be set to indicate
+// which method to call.
+type call struct {
+	Ping     *pingArgs
+	Fuzz     *fuzzArgs
+	Minimize *minimizeArgs
+}
+
+// pingArgs contains arguments to workerServer.ping.
+type pingArgs struct{}
+
+// pingResponse contains results from workerServer.ping.
+type pingResponse struct{}
+
+// fuzzArgs contains arguments to workerServer.fuzz. The value to fuzz is
+// encoded in the corpus file format.
+type fuzzArgs struct {
+	// Data is the input to start from.
+	Data []byte
+
+	// Timeout is the time to spend fuzzing, not including starting or
+	// cleaning up.
+	Timeout time.Duration
+
+	// Warmup indicates whether this is part of a warmup run, meaning that
+	// fuzzing should not occur. If coverage is enabled, the worker reports
+	// the coverage of the input.
+	Warmup bool
+
+	// CoverageData is the coverage data. If set, the worker should update its
+	// local coverage data prior to fuzzing.
+	CoverageData []byte
+}
+
+// fuzzResponse contains results from workerServer.fuzz.
+type fuzzResponse struct {
+	// TotalDuration is the time the worker spent testing inputs.
+	TotalDuration time.Duration
+
+	// Count is the number of values tested.
+	Count int64
+
+	// Data is the input that crashed or found new coverage, encoded in the
+	// corpus file format.
+	Data []byte
+
+	// CoverageData is set if the value in Data expanded coverage, or if the
+	// input was run during warmup.
+	CoverageData []byte
+
+	// Err is the error string caused by the value in Data, if any.
+	Err string
+}
+
+// minimizeArgs contains arguments to workerServer.minimize. Data is an input
+// known to fail, encoded in the corpus file format.
+type minimizeArgs struct {
+	Data []byte
+
+	// Timeout is the time to spend minimizing.
+	Timeout time.Duration
+}
+
+// minimizeResponse contains results from workerServer.minimize.
+type minimizeResponse struct {
+	// Data is the smallest failing input found, encoded in the corpus file
+	// format.
+	Data []byte
+
+	// Err is the error string caused by the value in Data.
+	Err string
+}
+
+// workerComm holds pipes and shared memory used for communication
+// between the coordinator process (client) and a worker process (server).
+type workerComm struct {
+	fuzzIn, fuzzOut *os.File
+	memFile         *os.File // used by the worker to record the current input
+}
+
+// workerServer is a minimalist RPC server, run by fuzz worker processes.
+// It allows the coordinator process (using workerClient) to call methods in a
+// worker process. This system allows the coordinator to run multiple worker
+// processes in parallel and to collect inputs that caused crashes from shared
+// memory after a worker process terminates unexpectedly.
+type workerServer struct {
+	workerComm
+	m *mutator
+
+	// coverageMask is the local coverage data for the worker. It is
+	// periodically updated to reflect the data in the coordinator when new
+	// coverage is found.
+	coverageMask []byte
+
+	// fuzzFn runs the worker's fuzz function on the given input and returns
+	// an error if it finds a crasher (the process may also exit or crash).
+	fuzzFn func(CorpusEntry) error
+
+	// memBuf is reused by writeMem.
+	memBuf []byte
+}
+
+// serve reads serialized RPC messages on fuzzIn. When serve receives a message,
+// it calls the corresponding method, then sends the serialized result back
+// on fuzzOut.
+//
+// serve handles RPC calls synchronously; it will not attempt to read a message
+// until the previous call has finished.
+//
+// serve returns errors that occurred when communicating over pipes. serve
+// does not return errors from method calls; those are passed through serialized
+// responses.
+func (ws *workerServer) serve(ctx context.Context) error {
+	enc := json.NewEncoder(ws.fuzzOut)
+	dec := json.NewDecoder(ws.fuzzIn)
+	for {
+		var c call
+		if err := dec.Decode(&c); err != nil {
+			if err == io.EOF || ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		var resp interface{}
+		switch {
+		case c.Fuzz != nil:
+			resp = ws.fuzz(ctx, *c.Fuzz)
+		case c.Minimize != nil:
+			resp = ws.minimize(ctx, *c.Minimize)
+		case c.Ping != nil:
+			resp = pingResponse{}
+		default:
+			return errors.New("no arguments provided for any call")
+		}
+
+		if err := enc.Encode(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// fuzz runs the test function on random variations of the input value in
+// args.Data for a limited duration. fuzz returns early if it finds an
+// input that crashes the fuzz function or one that expands coverage.
+func (ws *workerServer) fuzz(ctx context.Context, args fuzzArgs) (resp fuzzResponse) {
+	if args.CoverageData != nil {
+		if ws.coverageMask != nil && len(args.CoverageData) != len(ws.coverageMask) {
+			resp.Err = fmt.Sprintf("unexpected size for CoverageData: got %d, expected %d", len(args.CoverageData), len(ws.coverageMask))
+			return resp
+		}
+		ws.coverageMask = args.CoverageData
+	}
+	start := time.Now()
+	defer func() { resp.TotalDuration = time.Since(start) }()
+
+	vals, err := unmarshalCorpusFile(args.Data)
+	if err != nil {
+		resp.Err = fmt.Sprintf("fuzz: malformed input: %v", err)
+		return resp
+	}
+
+	if args.Warmup {
+		resp.Count = 1
+		if err := ws.run(args.Data, vals); err != nil {
+			resp.Data = args.Data
+			resp.Err = err.Error()
+			return resp
+		}
+		if cov := coverage(); len(cov) > 0 {
+			resp.CoverageData = append([]byte(nil), coverageSnapshot...)
+		}
+		return resp
+	}
+
+	deadline := start.Add(args.Timeout)
+	mutated := make([]interface{}, len(vals))
+	for time.Now().Before(deadline) && ctx.Err() == nil {
+		copy(mutated, vals)
+		ws.m.mutate(mutated, workerSharedMemSize)
+		data := marshalCorpusFile(mutated...)
+		resp.Count++
+		if err := ws.run(data, mutated); err != nil {
+			resp.Data = data
+			resp.Err = err.Error()
+			return resp
+		}
+		if ws.coverageMask != nil && diffCoverage(ws.coverageMask, coverageSnapshot) != nil {
+			resp.Data = data
+			resp.CoverageData = append([]byte(nil), coverageSnapshot...)
+			return resp
+		}
+	}
+	return resp
+}
+
+// run records data in shared memory, then runs the fuzz function on vals
+// and snapshots the coverage it reached.
+func (ws *workerServer) run(data []byte, vals []interface{}) error {
+	ws.writeMem(data)
+	resetCoverage()
+	defer snapshotCoverage()
+	return ws.fuzzFn(CorpusEntry{Values: vals})
+}
+
+// writeMem stores data in the shared memory file, prefixed by its length,
+// so that the coordinator can recover it if the process crashes.
+func (ws *workerServer) writeMem(data []byte) {
+	ws.memBuf = append(ws.memBuf[:0], make([]byte, 8)...)
+	binary.LittleEndian.PutUint64(ws.memBuf, uint64(len(data)))
+	ws.memBuf = append(ws.memBuf, data...)
+	ws.memFile.WriteAt(ws.memBuf, 0)
+}
+
+// minimize tries to find a smaller value that still causes an error. See
+// minimizeInput for the reduction strategy.
+func (ws *workerServer) minimize(ctx context.Context, args minimizeArgs) (resp minimizeResponse) {
+	vals, err := unmarshalCorpusFile(args.Data)
+	if err != nil {
+		return resp
+	}
+	deadline := time.Now().Add(args.Timeout)
+	var lastErr string
+	try := func(candidate []interface{}) bool {
+		data := marshalCorpusFile(candidate...)
+		err := ws.run(data, candidate)
+		if err == nil {
+			return false
+		}
+		lastErr = err.Error()
+		return true
+	}
+	shouldStop := func() bool {
+		return ctx.Err() != nil || time.Now().After(deadline)
+	}
+
+	// Confirm the input fails in this process, and record its error.
+	if !try(vals) {
+		return resp
+	}
+	minimizeInput(vals, try, shouldStop)
+	resp.Data = marshalCorpusFile(vals...)
+	resp.Err = lastErr
+	return resp
+}
+
+// workerClient is a minimalist RPC client. The coordinator process uses a
+// workerClient to call methods in each worker process (handled by
+// workerServer).
+type workerClient struct {
+	workerComm
+	mu  sync.Mutex
+	enc *json.Encoder
+	dec *json.Decoder
+}
+
+func newWorkerClient(comm workerComm) *workerClient {
+	return &workerClient{
+		workerComm: comm,
+		enc:        json.NewEncoder(comm.fuzzIn),
+		dec:        json.NewDecoder(comm.fuzzOut),
+	}
+}
+
+// Close shuts down the connection to the RPC server (the worker process) by
+// closing fuzz_in. Close drains fuzz_out (avoiding a SIGPIPE in the worker),
+// and closes it after the worker process closes the other end.
+func (wc *workerClient) Close() error {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+
+	// Close fuzzIn. This signals to the server that there are no more calls,
+	// and it should exit.
+	if err := wc.fuzzIn.Close(); err != nil {
+		wc.fuzzOut.Close()
+		return err
+	}
+
+	// Drain fuzzOut and close it. When the server exits, the kernel will close
+	// its end of fuzzOut, and we'll get EOF.
+	if _, err := io.Copy(ioutil.Discard, wc.fuzzOut); err != nil {
+		wc.fuzzOut.Close()
+		return err
+	}
+	return wc.fuzzOut.Close()
+}
+
+// ping tells the worker to call the ping method. See workerServer.ping.
+func (wc *workerClient) ping() error {
+	var resp pingResponse
+	return wc.callLocked(call{Ping: &pingArgs{}}, &resp)
+}
+
+// fuzz tells the worker to call the fuzz method. See workerServer.fuzz.
+func (wc *workerClient) fuzz(args fuzzArgs) (resp fuzzResponse, err error) {
+	err = wc.callLocked(call{Fuzz: &args}, &resp)
+	return resp, err
+}
+
+// minimize tells the worker to call the minimize method. See
+// workerServer.minimize.
+func (wc *workerClient) minimize(args minimizeArgs) (resp minimizeResponse, err error) {
+	err = wc.callLocked(call{Minimize: &args}, &resp)
+	return resp, err
+}
+
+// callLocked sends an RPC from the coordinator to the worker process and waits
+// for the response.
+func (wc *workerClient) callLocked(c call, resp interface{}) error {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	if err := wc.enc.Encode(c); err != nil {
+		return err
+	}
+	return wc.dec.Decode(resp)
+}
+
+// tailBuffer is an io.Writer that keeps the last tailBufferSize bytes
+// written to it. It records what a worker process writes to stderr, so that
+// the output of a crash can be reported.
+type tailBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+const tailBufferSize = 16 << 10
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > tailBufferSize {
+		b.buf = b.buf[len(b.buf)-tailBufferSize:]
+	}
+	return len(p), nil
+}
+
+// Bytes returns a copy of the data kept in b.
+func (b *tailBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]byte(nil), b.buf...)
+}
@@ -0,0 +1,71 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fs_test
+
+import (
+	. "io/fs"
+	"testing"
+)
+
+var isValidPathTests = []struct {
+	name string
+	ok   bool
+}{
+	{".", true},
+	{"x", true},
+	{"x/y", true},
+
+	{"", false},
+	{"..", false},
+	{"/", false},
+	{"x/", false},
+	{"/x", false},
+	{"x/y/", false},
+	{"/x/y", false},
+	{"./", false},
+	{"./x", false},
+	{"x/.", false},
+	{"x/./y", false},
+	{"../", false},
+	{"../x", false},
+	{"x/..", false},
+	{"x/../y", false},
+	{"x//y", false},
+	{`x\`, true},
+	{`x\y`, true},
+	{`x:y`, true},
+	{`\x`, true},
+}
+
+func TestValidPath(t *testing.T) {
+	for _, tt := range isValidPathTests {
+		ok := ValidPath(tt.name)
+		if ok != tt.ok {
+			t.Errorf("ValidPath(%q) = %v, want %v", tt.name, ok, tt.ok)
+		}
+	}
+}
+
+var fileModeTests = []struct {
+	mode FileMode
+	str  string
+	typ  FileMode
+}{
+	{0644, "-rw-r--r--", 0},
+	{ModeDir | 0755, "drwxr-xr-x", ModeDir},
+	{ModeSymlink | 0777, "Lrwxrwxrwx", ModeSymlink},
+	{ModeNamedPipe | ModeSetuid | 0600, "purw-------", ModeNamedPipe},
+}
+
+func TestFileMode(t *testing.T) {
+	for _, tt := range fileModeTests {
+		if s := tt.mode.String(); s != tt.str {
+			t.Errorf("FileMode(%#o).String() = %q, want %q", uint32(tt.mode), s, tt.str)
+		}
+		if typ := tt.mode.Type(); typ != tt.typ {
+			t.Errorf("FileMode(%#o).Type() = %v, want %v", uint32(tt.mode), typ, tt.typ)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fs_test
+
+import (
+	. "io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+var testFsys = fstest.MapFS{
+	"hello.txt": {
+		Data:    []byte("hello, world"),
+		Mode:    0456,
+		ModTime: time.Now(),
+		Sys:     &sysValue,
+	},
+	"sub/goodbye.txt": {
+		Data:    []byte("goodbye, world"),
+		Mode:    0456,
+		ModTime: time.Now(),
+		Sys:     &sysValue,
+	},
+}
+
+var sysValue int
+
+type readFileOnly struct{ ReadFileFS }
+
+func (readFileOnly) Open(name string) (File, error) { return nil, ErrNotExist }
+
+type openOnly struct{ FS }
+
+func TestReadFile(t *testing.T) {
+	// Test that ReadFile uses the method when present.
+	data, err := ReadFile(readFileOnly{testFsys}, "hello.txt")
+	if string(data) != "hello, world" || err != nil {
+		t.Fatalf(`ReadFile(readFileOnly, "hello.txt") = %q, %v, want %q, nil`, data, err, "hello, world")
+	}
+
+	// Test that ReadFile uses Open when the method is not present.
+	data, err = ReadFile(openOnly{testFsys}, "hello.txt")
+	if string(data) != "hello, world" || err != nil {
+		t.Fatalf(`ReadFile(openOnly, "hello.txt") = %q, %v, want %q, nil`, data, err, "hello, world")
+	}
+
+	// Test that ReadFile on Sub of . works (sub_test checks non-. situations).
+	fsys, err := Sub(testFsys, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err = ReadFile(fsys, "hello.txt")
+	if string(data) != "hello, world" || err != nil {
+		t.Fatalf(`ReadFile(sub(.), "hello.txt") = %q, %v, want %q, nil`, data, err, "hello, world")
+	}
+}
+
+func TestStat(t *testing.T) {
+	info, err := Stat(openOnly{testFsys}, "hello.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Name() != "hello.txt" || info.Size() != 12 || info.Mode() != 0456 || info.Sys() != &sysValue {
+		t.Errorf("Stat(openOnly, hello.txt) = %v %v %v %v", info.Name(), info.Size(), info.Mode(), info.Sys())
+	}
+}
+
+func TestReadDir(t *testing.T) {
+	check := func(desc string, dirs []DirEntry, err error) {
+		t.Helper()
+		if err != nil || len(dirs) != 2 || dirs[0].Name() != "hello.txt" || dirs[1].Name() != "sub" {
+			var names []string
+			for _, d := range dirs {
+				names = append(names, d.Name())
+			}
+			t.Errorf("ReadDir(%s) = %v, %v, want %v, nil", desc, names, err, []string{"hello.txt", "sub"})
+		}
+	}
+
+	// Test that ReadDir uses the method when present.
+	dirs, err := ReadDir(testFsys, ".")
+	check("testFsys", dirs, err)
+
+	// Test that ReadDir uses Open when the method is not present.
+	dirs, err = ReadDir(openOnly{testFsys}, ".")
+	check("openOnly", dirs, err)
+
+	// Test that ReadDir on Sub of . works (sub_test checks non-. situations).
+	sub, err := Sub(testFsys, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dirs, err = ReadDir(sub, ".")
+	check("sub(.)", dirs, err)
+}
+
+func TestGlob(t *testing.T) {
+	for _, fsys := range []FS{testFsys, openOnly{testFsys}} {
+		matches, err := Glob(fsys, "*/*.txt")
+		if err != nil || len(matches) != 1 || matches[0] != "sub/goodbye.txt" {
+			t.Errorf("Glob(%T, */*.txt) = %v, %v, want [sub/goodbye.txt], nil", fsys, matches, err)
+		}
+		if _, err := Glob(fsys, "["); err == nil {
+			t.Errorf("Glob(%T, [) succeeded, want error", fsys)
+		}
+	}
+}
+
+func TestSub(t *testing.T) {
+	sub, err := Sub(openOnly{testFsys}, "sub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ReadFile(sub, "goodbye.txt")
+	if string(data) != "goodbye, world" || err != nil {
+		t.Fatalf(`ReadFile(sub, "goodbye.txt") = %q, %v, want %q, nil`, data, err, "goodbye, world")
+	}
+
+	_, err = sub.Open("nonexist")
+	pe, ok := err.(*PathError)
+	if !ok {
+		t.Fatalf("Open(nonexist): error is %T, want *PathError", err)
+	}
+	if pe.Path != "nonexist" {
+		t.Fatalf("Open(nonexist): err.Path = %q, want %q", pe.Path, "nonexist")
+	}
+}
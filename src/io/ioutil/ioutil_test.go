@@ -2,10 +2,11 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-package ioutil
+package ioutil_test
 
 import (
 	"bytes"
+	. "io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
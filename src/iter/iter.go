@@ -0,0 +1,269 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package iter provides basic definitions and operations related to
+iterators over sequences.
+
+Iterators
+
+An iterator is a function that passes successive elements of a
+sequence to a callback function, conventionally named yield.
+The function stops either when the sequence is finished or
+when yield returns false, indicating to stop the iteration early.
+This package defines Seq and Seq2
+(pronounced like seek—the first syllable of sequence)
+as shorthands for iterators that pass 1 or 2 values per sequence element
+to yield:
+
+	type (
+		Seq[V any]     func(yield func(V) bool)
+		Seq2[K, V any] func(yield func(K, V) bool)
+	)
+
+Seq2 represents a sequence of paired values, conventionally key-value
+or index-value pairs.
+
+Yield returns true if the iterator should continue with the next
+element in the sequence, false if it should stop.
+
+Iterator functions are most often called by a range loop, as in:
+
+	func PrintAll[V any](seq iter.Seq[V]) {
+		for v := range seq {
+			fmt.Println(v)
+		}
+	}
+
+Naming Conventions
+
+Iterator functions and methods are named for the sequence being walked:
+
+	// All returns an iterator over all elements in s.
+	func (s *Set[V]) All() iter.Seq[V]
+
+The iterator method on a collection type is conventionally named All,
+because it iterates a sequence of all the values in the collection.
+
+For a type containing multiple possible sequences, the iterator's name
+can indicate which sequence is being provided:
+
+	// Cities returns an iterator over the major cities in the country.
+	func (c *Country) Cities() iter.Seq[*City]
+
+If an iterator requires additional configuration, the constructor
+function can take additional configuration arguments:
+
+	// Scan returns an iterator over key-value pairs with min ≤ key ≤ max.
+	func (m *Map[K, V]) Scan(min, max K) iter.Seq2[K, V]
+
+When there are multiple possible iteration orders, the method name may
+indicate that order:
+
+	// Backward returns an iterator over the list elements,
+	// traversing it backward from tail to head.
+	func (l *List[V]) Backward() iter.Seq[V]
+
+Pulling Values
+
+Functions and methods that accept or return iterators should use the
+standard Seq or Seq2 types, to ensure compatibility with range loops
+and other iterator adapters. The standard iterators can be thought of
+as “push iterators”, which push values to the yield function.
+
+Sometimes a range loop is not the most natural way to consume values
+of the sequence. In this case, Pull converts a standard push iterator
+to a “pull iterator”, which can be called to pull one value at a time
+from the sequence. Pull starts an iterator and returns a pair of
+functions—next and stop—which return the next value from the iterator
+and stop it, respectively.
+
+Clients must call stop if they do not read the sequence to its end,
+so that the iterator function can finish and return.
+*/
+package iter
+
+// Seq is an iterator over sequences of individual values.
+// When called as seq(yield), seq calls yield(v) for each value v in the
+// sequence, stopping early if yield returns false.
+// See the package documentation for more details.
+type Seq[V any] func(yield func(V) bool)
+
+// Seq2 is an iterator over sequences of pairs of values, most commonly
+// key-value pairs.
+// When called as seq(yield), seq calls yield(k, v) for each pair (k, v)
+// in the sequence, stopping early if yield returns false.
+// See the package documentation for more details.
+type Seq2[K, V any] func(yield func(K, V) bool)
+
+// Pull converts the “push-style” iterator sequence seq
+// into a “pull-style” iterator accessed by the two functions
+// next and stop.
+//
+// Next returns the next value in the sequence
+// and a boolean indicating whether the value is valid.
+// When the sequence is over, next returns the zero V and false.
+// It is valid to call next after reaching the end of the sequence
+// or after calling stop. These calls will continue
+// to return the zero V and false.
+//
+// Stop ends the iteration. It must be called when the caller is
+// no longer interested in next values and next has not yet
+// signaled that the sequence is over (with a false boolean return).
+// It is valid to call stop multiple times and when next has
+// already returned false.
+//
+// It is an error to call next or stop from multiple goroutines
+// simultaneously.
+//
+// If the iterator function panics, calls to next or stop propagate
+// the same panic.
+func Pull[V any](seq Seq[V]) (next func() (V, bool), stop func()) {
+	var v V
+	p := newPuller(func(yield func() bool) {
+		seq(func(v1 V) bool {
+			v = v1
+			return yield()
+		})
+	})
+	next = func() (V, bool) {
+		if !p.next() {
+			var zero V
+			v = zero
+			return zero, false
+		}
+		return v, true
+	}
+	return next, p.stop
+}
+
+// Pull2 converts the “push-style” iterator sequence seq
+// into a “pull-style” iterator accessed by the two functions
+// next and stop.
+//
+// Next returns the next pair in the sequence
+// and a boolean indicating whether the pair is valid.
+// When the sequence is over, next returns a pair of zero values and false.
+// It is valid to call next after reaching the end of the sequence
+// or after calling stop. These calls will continue
+// to return a pair of zero values and false.
+//
+// Stop ends the iteration. It must be called when the caller is
+// no longer interested in next values and next has not yet
+// signaled that the sequence is over (with a false boolean return).
+// It is valid to call stop multiple times and when next has
+// already returned false.
+//
+// It is an error to call next or stop from multiple goroutines
+// simultaneously.
+//
+// If the iterator function panics, calls to next or stop propagate
+// the same panic.
+func Pull2[K, V any](seq Seq2[K, V]) (next func() (K, V, bool), stop func()) {
+	var k K
+	var v V
+	p := newPuller(func(yield func() bool) {
+		seq(func(k1 K, v1 V) bool {
+			k, v = k1, v1
+			return yield()
+		})
+	})
+	next = func() (K, V, bool) {
+		if !p.next() {
+			var zk K
+			var zv V
+			k, v = zk, zv
+			return zk, zv, false
+		}
+		return k, v, true
+	}
+	return next, p.stop
+}
+
+// A puller runs an iterator function in a goroutine of its own, handing
+// control back and forth between that goroutine and the caller of next
+// and stop so that only one of them runs at a time.
+type puller struct {
+	seq func(yield func() bool)
+
+	started bool
+	done    bool
+	resume  chan bool     // true to continue the iteration, false to stop it
+	yielded chan struct{} // the iterator yielded a value, or returned if closed
+
+	panicking  bool
+	panicValue interface{}
+}
+
+func newPuller(seq func(yield func() bool)) *puller {
+	return &puller{seq: seq}
+}
+
+// run runs the iterator function. It is the body of the goroutine
+// started by the first call of next.
+func (p *puller) run() {
+	defer func() {
+		if e := recover(); e != nil {
+			p.panicking = true
+			p.panicValue = e
+		}
+		p.done = true
+		close(p.yielded)
+	}()
+	<-p.resume // the first call of next
+	stopped := false
+	p.seq(func() bool {
+		if stopped {
+			panic("iter.Pull: yield called after iteration was stopped")
+		}
+		p.yielded <- struct{}{}
+		if !<-p.resume {
+			stopped = true
+			return false
+		}
+		return true
+	})
+}
+
+// transfer hands control to the iterator goroutine, telling it whether
+// to continue, and waits for it to yield or return. It reports whether
+// the iterator yielded a value.
+func (p *puller) transfer(cont bool) bool {
+	p.resume <- cont
+	_, ok := <-p.yielded
+	if p.panicking {
+		p.panicking = false
+		e := p.panicValue
+		p.panicValue = nil
+		panic(e)
+	}
+	return ok
+}
+
+func (p *puller) next() bool {
+	if p.done {
+		return false
+	}
+	if !p.started {
+		p.started = true
+		p.resume = make(chan bool)
+		p.yielded = make(chan struct{})
+		go p.run()
+	}
+	return p.transfer(true)
+}
+
+func (p *puller) stop() {
+	if p.done {
+		return
+	}
+	if !p.started {
+		p.done = true
+		return
+	}
+	// Tell the iterator to stop and wait for it to return. An
+	// iterator that keeps calling yield panics instead, and the
+	// panic is reported here.
+	p.transfer(false)
+}
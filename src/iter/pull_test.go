@@ -0,0 +1,197 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import (
+	"fmt"
+	. "iter"
+	"runtime"
+	"testing"
+)
+
+func count(n int) Seq[int] {
+	return func(yield func(int) bool) {
+		for i := 0; i < n; i++ {
+			if !yield(i) {
+				break
+			}
+		}
+	}
+}
+
+func squares(n int) Seq2[int, int64] {
+	return func(yield func(int, int64) bool) {
+		for i := 0; i < n; i++ {
+			if !yield(i, int64(i)*int64(i)) {
+				break
+			}
+		}
+	}
+}
+
+func TestPull(t *testing.T) {
+	for end := 0; end <= 3; end++ {
+		t.Run(fmt.Sprint(end), func(t *testing.T) {
+			ng := stableNumGoroutine()
+			wantNG := func(want int) {
+				if xg := runtime.NumGoroutine() - ng; xg != want {
+					t.Helper()
+					t.Errorf("have %d extra goroutines, want %d", xg, want)
+				}
+			}
+			wantNG(0)
+			next, stop := Pull(count(3))
+			for i := 0; i < end; i++ {
+				v, ok := next()
+				if v != i || ok != true {
+					t.Fatalf("next() = %d, %v, want %d, %v", v, ok, i, true)
+				}
+			}
+			if end < 3 {
+				stop()
+			}
+			v, ok := next()
+			if v != 0 || ok != false {
+				t.Fatalf("next() = %d, %v, want %d, %v", v, ok, 0, false)
+			}
+			stop()
+			stop()
+			wantNG(0)
+		})
+	}
+}
+
+func TestPull2(t *testing.T) {
+	for end := 0; end <= 3; end++ {
+		t.Run(fmt.Sprint(end), func(t *testing.T) {
+			ng := stableNumGoroutine()
+			next, stop := Pull2(squares(3))
+			for i := 0; i < end; i++ {
+				k, v, ok := next()
+				if k != i || v != int64(i*i) || ok != true {
+					t.Fatalf("next() = %d, %d, %v, want %d, %d, %v", k, v, ok, i, i*i, true)
+				}
+			}
+			if end < 3 {
+				stop()
+			}
+			k, v, ok := next()
+			if k != 0 || v != 0 || ok != false {
+				t.Fatalf("next() = %d, %d, %v, want %d, %d, %v", k, v, ok, 0, 0, false)
+			}
+			stop()
+			stop()
+			if xg := runtime.NumGoroutine() - ng; xg != 0 {
+				t.Errorf("have %d extra goroutines, want 0", xg)
+			}
+		})
+	}
+}
+
+// stableNumGoroutine is like NumGoroutine but tries to ensure stability of
+// the value by letting any exiting goroutines finish exiting.
+func stableNumGoroutine() int {
+	// The goroutine running an iterator closes its channel and then
+	// exits, so the count may be briefly stale. Keep trying until
+	// the number is the same a few times in a row.
+	c := 0
+	ng := runtime.NumGoroutine()
+	for i := 0; i < 1000; i++ {
+		nng := runtime.NumGoroutine()
+		if nng == ng {
+			c++
+		} else {
+			c = 0
+			ng = nng
+		}
+		if c >= 100 {
+			return ng
+		}
+		runtime.Gosched()
+	}
+	panic("failed to stabilize NumGoroutine after 1000 iterations")
+}
+
+func TestPullStopBeforeNext(t *testing.T) {
+	called := false
+	_, stop := Pull(Seq[int](func(yield func(int) bool) {
+		called = true
+	}))
+	stop()
+	if called {
+		t.Fatal("iterator started by stop")
+	}
+}
+
+func TestPullPanic(t *testing.T) {
+	t.Run("next", func(t *testing.T) {
+		next, stop := Pull(panicSeq())
+		if !panicsWith("boom", func() { next() }) {
+			t.Fatal("failed to propagate panic on first next")
+		}
+		// Make sure we don't panic again if we try to call next or stop.
+		if _, ok := next(); ok {
+			t.Fatal("next returned true after iterator panicked")
+		}
+		stop()
+	})
+	t.Run("stop", func(t *testing.T) {
+		next, stop := Pull(panicCleanupSeq())
+		x, ok := next()
+		if !ok || x != 55 {
+			t.Fatalf("expected (55, true) from next, got (%d, %t)", x, ok)
+		}
+		if !panicsWith("boom", func() { stop() }) {
+			t.Fatal("failed to propagate panic on stop")
+		}
+		// Make sure we don't panic again if we try to call next or stop.
+		if _, ok := next(); ok {
+			t.Fatal("next returned true after iterator panicked")
+		}
+		stop()
+	})
+}
+
+func panicSeq() Seq[int] {
+	return func(yield func(int) bool) {
+		panic("boom")
+	}
+}
+
+func panicCleanupSeq() Seq[int] {
+	return func(yield func(int) bool) {
+		for {
+			if !yield(55) {
+				panic("boom")
+			}
+		}
+	}
+}
+
+func TestPullYieldAfterStop(t *testing.T) {
+	next, stop := Pull(Seq[int](func(yield func(int) bool) {
+		yield(1)
+		yield(2)
+	}))
+	if v, ok := next(); v != 1 || !ok {
+		t.Fatalf("next() = %d, %v, want 1, true", v, ok)
+	}
+	if !panicsWith("iter.Pull: yield called after iteration was stopped", stop) {
+		t.Fatal("yield after stop did not panic")
+	}
+}
+
+func panicsWith(v interface{}, f func()) (panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			if r != v {
+				panic(r)
+			}
+			panicked = true
+		}
+	}()
+	f()
+	return
+}
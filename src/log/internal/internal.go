@@ -0,0 +1,13 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package internal contains definitions used by both log and log/slog.
+package internal
+
+// DefaultOutput holds a function which calls the default log.Logger's
+// output method, or nil if the log package has not been initialized.
+// It allows log/slog to write through the standard logger, so that the
+// standard logger's flags and output destination apply to its default
+// handler, without exposing the method in the API of package log.
+var DefaultOutput func(pc uintptr, data []byte) error
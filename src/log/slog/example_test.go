@@ -0,0 +1,49 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package slog_test
+
+import (
+	"log/slog"
+	"os"
+)
+
+func ExampleGroup() {
+	// Remove the time so the output is deterministic.
+	removeTime := func(groups []string, a slog.Attr) slog.Attr {
+		if a.Key == slog.TimeKey && len(groups) == 0 {
+			return slog.Attr{}
+		}
+		return a
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{ReplaceAttr: removeTime}))
+	logger.Info("finished",
+		slog.Group("req",
+			slog.String("method", "GET"),
+			slog.String("url", "/api")),
+		slog.Int("status", 200))
+
+	// Output:
+	// level=INFO msg=finished req.method=GET req.url=/api status=200
+}
+
+func ExampleHandlerOptions_customLevels() {
+	var programLevel = new(slog.LevelVar) // Info by default
+	h := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: programLevel,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	logger := slog.New(h)
+	logger.Debug("not shown")
+	programLevel.Set(slog.LevelDebug)
+	logger.Debug("shown", "count", 3)
+
+	// Output:
+	// {"level":"DEBUG","msg":"shown","count":3}
+}
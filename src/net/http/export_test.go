@@ -300,3 +300,11 @@ func ExportCloseTransportConnsAbruptly(tr *Transport) {
 	}
 	tr.idleMu.Unlock()
 }
+
+// SetUse113 sets whether ServeMux uses Go 1.13 patterns, as if by
+// GODEBUG=httpmuxgo113=1, and returns a function that restores the old value.
+func SetUse113(v bool) (restore func()) {
+	old := use113
+	use113 = v
+	return func() { use113 = old }
+}
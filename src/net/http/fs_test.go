@@ -26,6 +26,7 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"testing/fstest"
 	"time"
 )
 
@@ -590,6 +591,42 @@ func TestServeIndexHtml(t *testing.T) {
 	}
 }
 
+func TestFileServerFS(t *testing.T) {
+	defer afterTest(t)
+	fsys := fstest.MapFS{
+		"index.html":    {Data: []byte("index page")},
+		"dir/hello.txt": {Data: []byte("hello, world")},
+	}
+	ts := httptest.NewServer(FileServer(FS(fsys)))
+	defer ts.Close()
+
+	for _, tt := range []struct {
+		path, want string
+		code       int
+	}{
+		{"/", "index page", 200},
+		{"/dir/hello.txt", "hello, world", 200},
+		{"/dir/", `<a href="hello.txt">hello.txt</a>`, 200},
+		{"/missing.txt", "404 page not found", 404},
+	} {
+		res, err := Get(ts.URL + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != tt.code {
+			t.Errorf("GET %s: status = %d, want %d", tt.path, res.StatusCode, tt.code)
+		}
+		if !strings.Contains(string(b), tt.want) {
+			t.Errorf("GET %s: body = %q, want it to contain %q", tt.path, b, tt.want)
+		}
+	}
+}
+
 func TestFileServerZeroByte(t *testing.T) {
 	defer afterTest(t)
 	ts := httptest.NewServer(FileServer(Dir(".")))
@@ -610,6 +610,193 @@ func TestShouldRedirectConcurrency(t *testing.T) {
 	mux.HandleFunc("/", func(w ResponseWriter, r *Request) {})
 }
 
+func TestServeMuxPathValue(t *testing.T) {
+	setParallel(t)
+	for _, test := range []struct {
+		pattern string
+		url     string
+		want    map[string]string
+	}{
+		{
+			"/{a}/is/{b}/{c...}",
+			"/now/is/the/time/for/all",
+			map[string]string{
+				"a": "now",
+				"b": "the",
+				"c": "time/for/all",
+				"d": "",
+			},
+		},
+		{
+			"/names/{name}/{other...}",
+			"/names/john%20doe/address",
+			map[string]string{
+				"name":  "john doe",
+				"other": "address",
+			},
+		},
+		{
+			"/names/{name}/{other...}",
+			"/names/john/there/is/more",
+			map[string]string{
+				"name":  "john",
+				"other": "there/is/more",
+			},
+		},
+		{
+			"GET /{x}/{$}",
+			"/a/",
+			map[string]string{
+				"x": "a",
+			},
+		},
+	} {
+		mux := NewServeMux()
+		mux.HandleFunc(test.pattern, func(w ResponseWriter, r *Request) {
+			for name, want := range test.want {
+				if got := r.PathValue(name); got != want {
+					t.Errorf("%q, %q: PathValue(%q) = %q, want %q", test.pattern, test.url, name, got, want)
+				}
+			}
+		})
+		req, err := NewRequest("GET", "http://example.com"+test.url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rw := httptest.NewRecorder()
+		mux.ServeHTTP(rw, req)
+		if rw.Code != 200 {
+			t.Errorf("%q, %q: got code %d, want 200", test.pattern, test.url, rw.Code)
+		}
+	}
+}
+
+func TestSetPathValue(t *testing.T) {
+	mux := NewServeMux()
+	mux.HandleFunc("/a/{b}/c/{d...}", func(_ ResponseWriter, r *Request) {
+		kvs := map[string]string{
+			"b": "X",
+			"d": "Y",
+			"a": "Z",
+		}
+		for k, v := range kvs {
+			r.SetPathValue(k, v)
+		}
+		for k, w := range kvs {
+			if g := r.PathValue(k); g != w {
+				t.Errorf("got %q, want %q", g, w)
+			}
+		}
+	})
+	req, err := NewRequest("GET", "http://example.com/a/b/c/d/e", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func TestServeMuxMethodNotAllowed(t *testing.T) {
+	setParallel(t)
+	mux := NewServeMux()
+	mux.Handle("GET /items/{id}", serve(200))
+	mux.Handle("PUT /items/{id}", serve(201))
+	mux.Handle("POST /items/", serve(202))
+	mux.Handle("/other/{x}", serve(203))
+
+	for _, test := range []struct {
+		method, path string
+		code         int
+		allow        string
+	}{
+		{"GET", "/items/1", 200, ""},
+		{"HEAD", "/items/1", 200, ""},
+		{"PUT", "/items/1", 201, ""},
+		{"POST", "/items/1", 202, ""},
+		{"DELETE", "/items/1", 405, "GET, HEAD, POST, PUT"},
+		{"DELETE", "/items/", 405, "POST"},
+		{"DELETE", "/other/1", 203, ""},
+		{"GET", "/nothing", 404, ""},
+	} {
+		req := httptest.NewRequest(test.method, "http://example.com"+test.path, nil)
+		rw := httptest.NewRecorder()
+		mux.ServeHTTP(rw, req)
+		if rw.Code != test.code {
+			t.Errorf("%s %s: got code %d, want %d", test.method, test.path, rw.Code, test.code)
+		}
+		if got := rw.Header().Get("Allow"); got != test.allow {
+			t.Errorf("%s %s: got Allow %q, want %q", test.method, test.path, got, test.allow)
+		}
+	}
+}
+
+func TestServeMuxRegistrationPanics(t *testing.T) {
+	for _, test := range []struct {
+		pattern1, pattern2 string
+		want               string
+	}{
+		{"/a/{x}", "/a/{y}", "conflicts with pattern"},
+		{"/a/{x}", "/{y}/b", "conflicts with pattern"},
+		{"GET /", "/index.html", "conflicts with pattern"},
+		{"/", "/{x", "bad wildcard segment"},
+		{"/", "GET /a/../b", "unclean path"},
+	} {
+		func() {
+			defer func() {
+				r := recover()
+				err, ok := r.(error)
+				if !ok || !strings.Contains(err.Error(), test.want) {
+					t.Errorf("%q, %q: got panic %v, want error containing %q", test.pattern1, test.pattern2, r, test.want)
+				}
+			}()
+			mux := NewServeMux()
+			mux.Handle(test.pattern1, serve(200))
+			mux.Handle(test.pattern2, serve(200))
+		}()
+	}
+}
+
+func TestServeMuxEscapedLiteral(t *testing.T) {
+	setParallel(t)
+	mux := NewServeMux()
+	mux.Handle("/a%20b/%61", serve(200))
+	for _, path := range []string{"/a%20b/a", "/a%20b/%61"} {
+		req := httptest.NewRequest("GET", "http://example.com"+path, nil)
+		rw := httptest.NewRecorder()
+		mux.ServeHTTP(rw, req)
+		if rw.Code != 200 {
+			t.Errorf("%s: got code %d, want 200", path, rw.Code)
+		}
+	}
+}
+
+func TestServeMux113(t *testing.T) {
+	defer SetUse113(true)()
+
+	mux := NewServeMux()
+	mux.Handle("/{x}", serve(200))
+	mux.Handle("/a b", serve(201))
+	mux.Handle("/%61", serve(202))
+	mux.Handle("/dir/", serve(203))
+	for _, test := range []struct {
+		path string
+		code int
+	}{
+		{"/%7Bx%7D", 200},
+		{"/y", 404},
+		{"/a%20b", 201},
+		{"/%2561", 202},
+		{"/a", 404},
+		{"/dir/x", 203},
+	} {
+		req := httptest.NewRequest("GET", "http://example.com"+test.path, nil)
+		rw := httptest.NewRecorder()
+		mux.ServeHTTP(rw, req)
+		if rw.Code != test.code {
+			t.Errorf("%s: got code %d, want %d", test.path, rw.Code, test.code)
+		}
+	}
+}
+
 func BenchmarkServeMux(b *testing.B)           { benchmarkServeMux(b, true) }
 func BenchmarkServeMux_SkipServe(b *testing.B) { benchmarkServeMux(b, false) }
 func benchmarkServeMux(b *testing.B, runHandler bool) {
@@ -37,7 +37,7 @@ func BenchmarkServerMatch(b *testing.B) {
 		"/products/", "/products/3/image.jpg"}
 	b.StartTimer()
 	for i := 0; i < b.N; i++ {
-		if h, p := mux.match(paths[i%len(paths)]); h != nil && p == "" {
+		if n, _ := mux.tree.match("", "GET", paths[i%len(paths)]); n == nil {
 			b.Error("impossible")
 		}
 	}
@@ -9,6 +9,7 @@ package net
 import (
 	"context"
 	"internal/poll"
+	"net/netip"
 	"runtime"
 	"syscall"
 )
@@ -180,3 +181,34 @@ func ipToSockaddr(family int, ip IP, port int, zone string) (syscall.Sockaddr, e
 	}
 	return nil, &AddrError{Err: "invalid address family", Addr: ip.String()}
 }
+
+func addrPortToSockaddrInet4(ap netip.AddrPort) (syscall.SockaddrInet4, error) {
+	// Unlike ipToSockaddr, there is no nil IP to treat as the
+	// wildcard address: netip has no generic zero IP address.
+	addr := ap.Addr().Unmap()
+	if !addr.Is4() {
+		return syscall.SockaddrInet4{}, &AddrError{Err: "non-IPv4 address", Addr: addr.String()}
+	}
+	sa := syscall.SockaddrInet4{
+		Addr: addr.As4(),
+		Port: int(ap.Port()),
+	}
+	return sa, nil
+}
+
+func addrPortToSockaddrInet6(ap netip.AddrPort) (syscall.SockaddrInet6, error) {
+	addr := ap.Addr()
+	if !addr.IsValid() {
+		return syscall.SockaddrInet6{}, &AddrError{Err: "non-IPv6 address", Addr: addr.String()}
+	}
+	// See ipToSockaddr for the wildcard address handling.
+	if addr == netip.IPv4Unspecified() {
+		addr = netip.IPv6Unspecified()
+	}
+	sa := syscall.SockaddrInet6{
+		Addr:   addr.As16(),
+		Port:   int(ap.Port()),
+		ZoneId: uint32(zoneCache.index(addr.Zone())),
+	}
+	return sa, nil
+}
@@ -8,6 +8,7 @@ import (
 	"context"
 	"internal/nettrace"
 	"internal/singleflight"
+	"net/netip"
 	"sync"
 )
 
@@ -204,6 +205,43 @@ func (r *Resolver) LookupIPAddr(ctx context.Context, host string) ([]IPAddr, err
 	return r.lookupIPAddr(ctx, "ip", host)
 }
 
+// LookupNetIP looks up host using the local resolver.
+// It returns a slice of that host's IP addresses of the type specified by
+// network.
+// The network must be one of "ip", "ip4" or "ip6".
+func (r *Resolver) LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error) {
+	switch network {
+	case "ip", "ip4", "ip6":
+	default:
+		return nil, UnknownNetworkError(network)
+	}
+	addrs, err := r.lookupIPAddr(ctx, network, host)
+	if err != nil {
+		return nil, err
+	}
+	ips := make([]netip.Addr, 0, len(addrs))
+	for _, ia := range addrs {
+		ip, ok := netip.AddrFromSlice(ia.IP)
+		if !ok {
+			continue
+		}
+		// Addresses of either family may be stored in 16-byte form.
+		ip = ip.Unmap()
+		switch ipVersion(network) {
+		case '4':
+			if !ip.Is4() {
+				continue
+			}
+		case '6':
+			if !ip.Is6() {
+				continue
+			}
+		}
+		ips = append(ips, ip.WithZone(ia.Zone))
+	}
+	return ips, nil
+}
+
 // onlyValuesCtx is a context that uses an underlying context
 // for value lookup if the underlying context hasn't yet expired.
 type onlyValuesCtx struct {
@@ -11,6 +11,7 @@ import (
 	"context"
 	"fmt"
 	"internal/testenv"
+	"net/netip"
 	"reflect"
 	"runtime"
 	"sort"
@@ -1184,3 +1185,29 @@ func TestLookupNullByte(t *testing.T) {
 		t.Errorf("unexpected success")
 	}
 }
+
+func TestLookupNetIP(t *testing.T) {
+	tests := []struct {
+		network, host string
+		want          []netip.Addr
+	}{
+		{"ip", "127.0.0.1", []netip.Addr{netip.MustParseAddr("127.0.0.1")}},
+		{"ip4", "127.0.0.1", []netip.Addr{netip.MustParseAddr("127.0.0.1")}},
+		{"ip6", "::1", []netip.Addr{netip.MustParseAddr("::1")}},
+		{"ip", "fe80::1%lo0", []netip.Addr{netip.MustParseAddr("fe80::1%lo0")}},
+	}
+	for _, tt := range tests {
+		got, err := DefaultResolver.LookupNetIP(context.Background(), tt.network, tt.host)
+		if err != nil {
+			t.Errorf("LookupNetIP(%q, %q): %v", tt.network, tt.host, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("LookupNetIP(%q, %q) = %v; want %v", tt.network, tt.host, got, tt.want)
+		}
+	}
+
+	if _, err := DefaultResolver.LookupNetIP(context.Background(), "tcp", "127.0.0.1"); err == nil {
+		t.Errorf("LookupNetIP with network tcp succeeded")
+	}
+}
@@ -0,0 +1,1610 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netip_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	. "net/netip"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestParseAddr(t *testing.T) {
+	var validIPs = []struct {
+		in      string
+		ip      Addr   // output of ParseAddr()
+		str     string // output of String(). If "", use in.
+		wantErr string
+	}{
+		// Basic zero IPv4 address.
+		{
+			in: "0.0.0.0",
+			ip: AddrFrom4([4]byte{}),
+		},
+		// Basic non-zero IPv4 address.
+		{
+			in: "192.168.140.255",
+			ip: AddrFrom4([4]byte{192, 168, 140, 255}),
+		},
+		// IPv4 address in windows-style "print all the digits" form.
+		{
+			in:      "010.000.015.001",
+			wantErr: `ParseAddr("010.000.015.001"): IPv4 field has octet with leading zero`,
+		},
+		// IPv4 address with a silly amount of leading zeros.
+		{
+			in:      "000001.00000002.00000003.000000004",
+			wantErr: `ParseAddr("000001.00000002.00000003.000000004"): IPv4 field has octet with leading zero`,
+		},
+		// 4-in-6 with octet with leading zero
+		{
+			in:      "::ffff:1.2.03.4",
+			wantErr: `ParseAddr("::ffff:1.2.03.4"): ParseAddr("1.2.03.4"): IPv4 field has octet with leading zero (at "1.2.03.4")`,
+		},
+		// Basic zero IPv6 address.
+		{
+			in: "::",
+			ip: AddrFrom16([16]byte{}),
+		},
+		// Localhost IPv6.
+		{
+			in: "::1",
+			ip: AddrFrom16([16]byte{15: 1}),
+		},
+		// Fully expanded IPv6 address.
+		{
+			in: "fd7a:115c:a1e0:ab12:4843:cd96:626b:430b",
+			ip: AddrFrom16([16]byte{0xfd, 0x7a, 0x11, 0x5c, 0xa1, 0xe0, 0xab, 0x12, 0x48, 0x43, 0xcd, 0x96, 0x62, 0x6b, 0x43, 0x0b}),
+		},
+		// IPv6 with elided fields in the middle.
+		{
+			in: "fd7a:115c::626b:430b",
+			ip: AddrFrom16([16]byte{0xfd, 0x7a, 0x11, 0x5c, 12: 0x62, 13: 0x6b, 14: 0x43, 15: 0x0b}),
+		},
+		// IPv6 with elided fields at the end.
+		{
+			in: "fd7a:115c:a1e0:ab12:4843:cd96::",
+			ip: AddrFrom16([16]byte{0xfd, 0x7a, 0x11, 0x5c, 0xa1, 0xe0, 0xab, 0x12, 0x48, 0x43, 0xcd, 0x96}),
+		},
+		// IPv6 with single elided field at the end.
+		{
+			in:  "fd7a:115c:a1e0:ab12:4843:cd96:626b::",
+			ip:  AddrFrom16([16]byte{0xfd, 0x7a, 0x11, 0x5c, 0xa1, 0xe0, 0xab, 0x12, 0x48, 0x43, 0xcd, 0x96, 0x62, 0x6b}),
+			str: "fd7a:115c:a1e0:ab12:4843:cd96:626b:0",
+		},
+		// IPv6 with single elided field in the middle.
+		{
+			in:  "fd7a:115c:a1e0::4843:cd96:626b:430b",
+			ip:  AddrFrom16([16]byte{0xfd, 0x7a, 0x11, 0x5c, 0xa1, 0xe0, 6: 0, 7: 0, 8: 0x48, 9: 0x43, 10: 0xcd, 11: 0x96, 12: 0x62, 13: 0x6b, 14: 0x43, 15: 0x0b}),
+			str: "fd7a:115c:a1e0:0:4843:cd96:626b:430b",
+		},
+		// IPv6 with the trailing 32 bits written as IPv4 dotted decimal. (4in6)
+		{
+			in:  "::ffff:192.168.140.255",
+			ip:  AddrFrom16([16]byte{10: 0xff, 11: 0xff, 12: 192, 13: 168, 14: 140, 15: 255}),
+			str: "::ffff:192.168.140.255",
+		},
+		// IPv6 with a zone specifier.
+		{
+			in: "fd7a:115c:a1e0:ab12:4843:cd96:626b:430b%eth0",
+			ip: AddrFrom16([16]byte{0xfd, 0x7a, 0x11, 0x5c, 0xa1, 0xe0, 0xab, 0x12, 0x48, 0x43, 0xcd, 0x96, 0x62, 0x6b, 0x43, 0x0b}).WithZone("eth0"),
+		},
+		// IPv6 with dotted decimal and zone specifier.
+		{
+			in:  "1:2::ffff:192.168.140.255%eth1",
+			ip:  AddrFrom16([16]byte{1: 1, 3: 2, 10: 0xff, 11: 0xff, 12: 192, 13: 168, 14: 140, 15: 255}).WithZone("eth1"),
+			str: "1:2::ffff:c0a8:8cff%eth1",
+		},
+		// 4-in-6 with zone
+		{
+			in:  "::ffff:192.168.140.255%eth1",
+			ip:  AddrFrom16([16]byte{10: 0xff, 11: 0xff, 12: 192, 13: 168, 14: 140, 15: 255}).WithZone("eth1"),
+			str: "::ffff:192.168.140.255%eth1",
+		},
+		// IPv6 with capital letters.
+		{
+			in:  "FD9E:1A04:F01D::1",
+			ip:  AddrFrom16([16]byte{0xfd, 0x9e, 0x1a, 0x04, 0xf0, 0x1d, 15: 0x01}),
+			str: "fd9e:1a04:f01d::1",
+		},
+	}
+
+	for _, test := range validIPs {
+		t.Run(test.in, func(t *testing.T) {
+			got, err := ParseAddr(test.in)
+			if err != nil {
+				if err.Error() == test.wantErr {
+					return
+				}
+				t.Fatal(err)
+			}
+			if test.wantErr != "" {
+				t.Fatalf("wanted error %q; got none", test.wantErr)
+			}
+			if got != test.ip {
+				t.Errorf("got %#v, want %#v", got, test.ip)
+			}
+
+			// Check that ParseAddr is a pure function.
+			got2, err := ParseAddr(test.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != got2 {
+				t.Errorf("ParseAddr(%q) got 2 different results: %#v, %#v", test.in, got, got2)
+			}
+
+			// Check that ParseAddr(ip.String()) is the identity function.
+			s := got.String()
+			got3, err := ParseAddr(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != got3 {
+				t.Errorf("ParseAddr(%q) != ParseAddr(ParseIP(%q).String()). Got %#v, want %#v", test.in, test.in, got3, got)
+			}
+
+			// Check that the slow-but-readable parser produces the same result.
+			slow, err := parseIPSlow(test.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != slow {
+				t.Errorf("ParseAddr(%q) = %#v, parseIPSlow(%q) = %#v", test.in, got, test.in, slow)
+			}
+
+			// Check that the parsed IP formats as expected.
+			s = got.String()
+			wants := test.str
+			if wants == "" {
+				wants = test.in
+			}
+			if s != wants {
+				t.Errorf("ParseAddr(%q).String() got %q, want %q", test.in, s, wants)
+			}
+
+			// Check that AppendTo matches MarshalText.
+			testAppendToMarshal(t, got)
+
+			// Check that MarshalText/UnmarshalText work similarly to
+			// ParseAddr/String.
+			js := `"` + test.in + `"`
+			var jsgot Addr
+			if err := json.Unmarshal([]byte(js), &jsgot); err != nil {
+				t.Fatal(err)
+			}
+			if jsgot != got {
+				t.Errorf("json.Unmarshal(%q) = %#v, want %#v", test.in, jsgot, got)
+			}
+			jsb, err := json.Marshal(jsgot)
+			if err != nil {
+				t.Fatal(err)
+			}
+			jswant := `"` + wants + `"`
+			jsback := string(jsb)
+			if jsback != jswant {
+				t.Errorf("Marshal(Unmarshal(%q)) = %s, want %s", test.in, jsback, jswant)
+			}
+		})
+	}
+
+	var invalidIPs = []string{
+		// Empty string
+		"",
+		// Garbage non-IP
+		"bad",
+		// Single number. Some parsers accept this as an IPv4 address in
+		// big-endian uint32 form, but we don't.
+		"1234",
+		// IPv4 with a zone specifier
+		"1.2.3.4%eth0",
+		// IPv4 field must have at least one digit
+		".1.2.3",
+		"1.2.3.",
+		"1..2.3",
+		// IPv4 address too long
+		"1.2.3.4.5",
+		// IPv4 in dotted octal form
+		"0300.0250.0214.0377",
+		// IPv4 in dotted hex form
+		"0xc0.0xa8.0x8c.0xff",
+		// IPv4 in class B form
+		"192.168.12345",
+		// IPv4 in class B form, with a small enough number to be
+		// parseable as a regular dotted decimal field.
+		"127.0.1",
+		// IPv4 in class A form
+		"192.1234567",
+		// IPv4 in class A form, with a small enough number to be
+		// parseable as a regular dotted decimal field.
+		"127.1",
+		// IPv4 field has value >255
+		"192.168.300.1",
+		// IPv4 with too many fields
+		"192.168.0.1.5.6",
+		// IPv6 with not enough fields
+		"1:2:3:4:5:6:7",
+		// IPv6 with too many fields
+		"1:2:3:4:5:6:7:8:9",
+		// IPv6 with 8 fields and a :: expander
+		"1:2:3:4::5:6:7:8",
+		// IPv6 with a field bigger than 2b
+		"fe801::1",
+		// IPv6 with non-hex values in field
+		"fe80:tail:scale::1",
+		// IPv6 with a zone delimiter but no zone.
+		"fe80::1%",
+		// IPv6 (without ellipsis) with too many fields for trailing embedded IPv4.
+		"ffff:ffff:ffff:ffff:ffff:ffff:ffff:192.168.140.255",
+		// IPv6 (with ellipsis) with too many fields for trailing embedded IPv4.
+		"ffff::ffff:ffff:ffff:ffff:ffff:ffff:192.168.140.255",
+		// IPv6 with invalid embedded IPv4.
+		"::ffff:192.168.140.bad",
+		// IPv6 with multiple ellipsis ::.
+		"fe80::1::1",
+		// IPv6 with invalid non hex/colon character.
+		"fe80:1?:1",
+		// IPv6 with truncated bytes after single colon.
+		"fe80:",
+		// IPv6 with a zone but no address.
+		"%eth0",
+	}
+
+	for _, s := range invalidIPs {
+		t.Run(s, func(t *testing.T) {
+			got, err := ParseAddr(s)
+			if err == nil {
+				t.Errorf("ParseAddr(%q) = %#v, want error", s, got)
+			}
+
+			slow, err := parseIPSlow(s)
+			if err == nil {
+				t.Errorf("parseIPSlow(%q) = %#v, want error", s, slow)
+			}
+
+			// Unlike ParseAddr, UnmarshalText accepts the empty
+			// string as the zero Addr.
+			if s == "" {
+				return
+			}
+			var jsgot Addr
+			js := []byte(`"` + s + `"`)
+			err = json.Unmarshal(js, &jsgot)
+			if err == nil {
+				t.Errorf("json.Unmarshal(%q) = %#v, want error", s, jsgot)
+			}
+		})
+	}
+}
+
+// parseIPSlow is like ParseAddr, but aims for readability above
+// speed. It is used to check the fast parser.
+func parseIPSlow(s string) (Addr, error) {
+	// Identify and strip out the zone, if any. There should be 0 or 1
+	// '%' in the string.
+	var zone string
+	fs := strings.Split(s, "%")
+	switch len(fs) {
+	case 1:
+		// No zone, that's fine.
+	case 2:
+		s, zone = fs[0], fs[1]
+		if zone == "" {
+			return Addr{}, errBad
+		}
+	default:
+		return Addr{}, errBad
+	}
+
+	// IPv4 by itself is easy to do in a helper.
+	if strings.Count(s, ":") == 0 {
+		if zone != "" {
+			return Addr{}, errBad
+		}
+		return parseIPv4Slow(s)
+	}
+
+	normal, err := normalizeIPv6Slow(s)
+	if err != nil {
+		return Addr{}, err
+	}
+
+	// At this point, we've converted the address into its fully
+	// expanded "a:b:c:d:e:f:g:h" form. We don't worry about the
+	// embedded IPv4 here: parseIPv6Slow's output is compared to
+	// ParseAddr's, and the ellipsis code below handles it.
+	var ret [16]byte
+	fs = strings.Split(normal, ":")
+	if len(fs) != 8 {
+		return Addr{}, errBad
+	}
+	for i, f := range fs {
+		a, b, err := parseWord(f)
+		if err != nil {
+			return Addr{}, err
+		}
+		ret[i*2] = a
+		ret[i*2+1] = b
+	}
+
+	return AddrFrom16(ret).WithZone(zone), nil
+}
+
+type badErr struct{}
+
+func (badErr) Error() string { return "bad" }
+
+var errBad = badErr{}
+
+// normalizeIPv6Slow expands s, which is assumed to be an IPv6
+// address, to its canonical text form.
+//
+// The canonical form of an IPv6 address is 8 colon-separated fields,
+// where each field should be a hex value from 0 to ffff. This
+// function does not verify the contents of each field.
+//
+// This function performs two transformations:
+//   - The last 32 bits of an IPv6 address may be represented in
+//     IPv4-style dotted quad form, as in 1:2:3:4:5:6:7.8.9.10. That
+//     address is transformed to its hex equivalent,
+//     e.g. 1:2:3:4:5:6:708:90a.
+//   - An address may contain one "::", which expands into as many
+//     16-bit blocks of zeros as needed to make the address its correct
+//     full size. For example, fe80::1:2 expands to fe80:0:0:0:0:0:1:2.
+//
+// Both short forms may be present in a single address,
+// e.g. fe80::1.2.3.4.
+func normalizeIPv6Slow(orig string) (string, error) {
+	s := orig
+
+	// Find and convert an IPv4 address in the final field, if any.
+	i := strings.LastIndex(s, ":")
+	if i == -1 {
+		return "", errBad
+	}
+	if strings.Contains(s[i+1:], ".") {
+		ip, err := parseIPv4Slow(s[i+1:])
+		if err != nil {
+			return "", err
+		}
+		a4 := ip.As4()
+		s = s[:i+1] + hex16(a4[0], a4[1]) + ":" + hex16(a4[2], a4[3])
+	}
+
+	// Find and expand a ::, if any.
+	fs := strings.Split(s, "::")
+	switch len(fs) {
+	case 1:
+		// No ::, nothing to do.
+	case 2:
+		lhs, rhs := fs[0], fs[1]
+		// Found a ::, figure out how many zero blocks need to be
+		// inserted.
+		nblocks := strings.Count(lhs, ":") + strings.Count(rhs, ":")
+		if lhs != "" {
+			nblocks++
+		}
+		if rhs != "" {
+			nblocks++
+		}
+		if nblocks > 7 {
+			return "", errBad
+		}
+		fs = nil
+		// Either side of the :: can be empty. We don't want empty
+		// fields to feature in the final normalized address.
+		if lhs != "" {
+			fs = append(fs, lhs)
+		}
+		fs = append(fs, strings.Repeat("0:", 8-nblocks)[:2*(8-nblocks)-1])
+		if rhs != "" {
+			fs = append(fs, rhs)
+		}
+		s = strings.Join(fs, ":")
+	default:
+		// Too many ::
+		return "", errBad
+	}
+
+	return s, nil
+}
+
+func hex16(a, b byte) string {
+	const digits = "0123456789abcdef"
+	return string([]byte{digits[a>>4], digits[a&0xf], digits[b>>4], digits[b&0xf]})
+}
+
+// parseIPv4Slow parses and returns an IPv4 address in dotted quad
+// form, e.g. "192.168.0.1". It is slow but easy to read, and the
+// returned errors are less specific than ParseAddr's.
+func parseIPv4Slow(s string) (Addr, error) {
+	var ret [4]byte
+	gs := strings.Split(s, ".")
+	if len(gs) != 4 {
+		return Addr{}, errBad
+	}
+	for i, g := range gs {
+		if len(g) == 0 || len(g) > 3 || (len(g) > 1 && g[0] == '0') {
+			return Addr{}, errBad
+		}
+		v := 0
+		for _, c := range []byte(g) {
+			if c < '0' || c > '9' {
+				return Addr{}, errBad
+			}
+			v = v*10 + int(c-'0')
+		}
+		if v > 255 {
+			return Addr{}, errBad
+		}
+		ret[i] = byte(v)
+	}
+	return AddrFrom4(ret), nil
+}
+
+// parseWord converts a 16-bit hex string into its corresponding
+// two-byte value.
+func parseWord(s string) (byte, byte, error) {
+	if len(s) == 0 || len(s) > 4 {
+		return 0, 0, errBad
+	}
+	v := 0
+	for _, c := range []byte(s) {
+		var d int
+		switch {
+		case '0' <= c && c <= '9':
+			d = int(c - '0')
+		case 'a' <= c && c <= 'f':
+			d = int(c-'a') + 10
+		case 'A' <= c && c <= 'F':
+			d = int(c-'A') + 10
+		default:
+			return 0, 0, errBad
+		}
+		v = v<<4 | d
+	}
+	return byte(v >> 8), byte(v), nil
+}
+
+// testAppendToMarshal checks that x.AppendTo(nil) equals the result
+// of x.MarshalText.
+func testAppendToMarshal(t *testing.T, x interface {
+	MarshalText() ([]byte, error)
+	AppendTo([]byte) []byte
+}) {
+	t.Helper()
+	m, err := x.MarshalText()
+	if err != nil {
+		t.Fatalf("(%v).MarshalText: %v", x, err)
+	}
+	a := make([]byte, 0, len(m))
+	a = x.AppendTo(a)
+	if !bytes.Equal(m, a) {
+		t.Errorf("(%v).MarshalText = %q, (%v).AppendTo = %q", x, m, x, a)
+	}
+}
+
+func TestAddrMarshalUnmarshal(t *testing.T) {
+	// This only tests the cases where Marshal/Unmarshal diverges from
+	// the behavior of ParseAddr/String. For the rest of the test cases,
+	// see TestParseAddr above.
+	orig := `""`
+	var ip Addr
+	if err := json.Unmarshal([]byte(orig), &ip); err != nil {
+		t.Fatalf("Unmarshal(%q) got error %v", orig, err)
+	}
+	if ip != (Addr{}) {
+		t.Errorf("Unmarshal(%q) is not the zero Addr", orig)
+	}
+
+	jsb, err := json.Marshal(ip)
+	if err != nil {
+		t.Fatalf("Marshal(%v) got error %v", ip, err)
+	}
+	back := string(jsb)
+	if back != orig {
+		t.Errorf("Marshal(Unmarshal(%q)) got %q, want %q", orig, back, orig)
+	}
+}
+
+func TestAddrMarshalBinary(t *testing.T) {
+	for _, s := range []string{"", "1.2.3.4", "::ffff:1.2.3.4", "fe80::1%eth0", "2001:db8::1"} {
+		var ip Addr
+		if s != "" {
+			ip = MustParseAddr(s)
+		}
+		b, err := ip.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var back Addr
+		if err := back.UnmarshalBinary(b); err != nil {
+			t.Fatalf("UnmarshalBinary(%q): %v", b, err)
+		}
+		if back != ip {
+			t.Errorf("UnmarshalBinary(MarshalBinary(%v)) = %v", ip, back)
+		}
+	}
+
+	var ip Addr
+	if err := ip.UnmarshalBinary([]byte{1, 2, 3}); err == nil {
+		t.Errorf("UnmarshalBinary of 3 bytes succeeded")
+	}
+}
+
+func TestAddrFromSlice(t *testing.T) {
+	tests := []struct {
+		ip       []byte
+		wantAddr Addr
+		wantOK   bool
+	}{
+		{
+			ip:       []byte{10, 0, 0, 1},
+			wantAddr: AddrFrom4([4]byte{10, 0, 0, 1}),
+			wantOK:   true,
+		},
+		{
+			ip:       []byte{0xfe, 0x80, 15: 0x01},
+			wantAddr: AddrFrom16([16]byte{0xfe, 0x80, 15: 0x01}),
+			wantOK:   true,
+		},
+		{
+			ip:       []byte{0, 1, 2},
+			wantAddr: Addr{},
+			wantOK:   false,
+		},
+		{
+			ip:       nil,
+			wantAddr: Addr{},
+			wantOK:   false,
+		},
+	}
+	for _, tt := range tests {
+		addr, ok := AddrFromSlice(tt.ip)
+		if ok != tt.wantOK || addr != tt.wantAddr {
+			t.Errorf("AddrFromSlice(%#v) = %#v, %v, want %#v, %v", tt.ip, addr, ok, tt.wantAddr, tt.wantOK)
+		}
+		if ok && !reflect.DeepEqual(addr.AsSlice(), tt.ip) {
+			t.Errorf("AddrFromSlice(%#v).AsSlice() = %#v", tt.ip, addr.AsSlice())
+		}
+	}
+}
+
+func TestAs4(t *testing.T) {
+	if got, want := MustParseAddr("::ffff:1.2.3.4").As4(), [4]byte{1, 2, 3, 4}; got != want {
+		t.Errorf("As4 = %v, want %v", got, want)
+	}
+	for _, ip := range []Addr{{}, MustParseAddr("::1")} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("(%v).As4 did not panic", ip)
+				}
+			}()
+			ip.As4()
+		}()
+	}
+}
+
+func TestAddrProperties(t *testing.T) {
+	var (
+		nilIP = Addr{}
+
+		unicast4           = MustParseAddr("192.0.2.1")
+		unicast6           = MustParseAddr("2001:db8::1")
+		unicastZone6       = MustParseAddr("2001:db8::1%eth0")
+		unicast6Unassigned = MustParseAddr("4000::1") // not in 2000::/3.
+
+		multicast4     = MustParseAddr("224.0.0.1")
+		multicast6     = MustParseAddr("ff02::1")
+		multicastZone6 = MustParseAddr("ff02::1%eth0")
+
+		llu4     = MustParseAddr("169.254.0.1")
+		llu6     = MustParseAddr("fe80::1")
+		llu6Last = MustParseAddr("febf:ffff:ffff:ffff:ffff:ffff:ffff:ffff")
+		lluZone6 = MustParseAddr("fe80::1%eth0")
+
+		loopback4 = MustParseAddr("127.0.0.1")
+
+		ilm6     = MustParseAddr("ff01::1")
+		ilmZone6 = MustParseAddr("ff01::1%eth0")
+
+		private4a = MustParseAddr("10.0.0.1")
+		private4b = MustParseAddr("172.16.0.1")
+		private4c = MustParseAddr("192.168.1.1")
+		private6  = MustParseAddr("fd00::1")
+
+		unspecified4 = AddrFrom4([4]byte{})
+		unspecified6 = IPv6Unspecified()
+	)
+
+	tests := []struct {
+		name                    string
+		ip                      Addr
+		globalUnicast           bool
+		interfaceLocalMulticast bool
+		linkLocalMulticast      bool
+		linkLocalUnicast        bool
+		loopback                bool
+		multicast               bool
+		private                 bool
+		unspecified             bool
+	}{
+		{
+			name: "nil",
+			ip:   nilIP,
+		},
+		{
+			name:          "unicast v4Addr",
+			ip:            unicast4,
+			globalUnicast: true,
+		},
+		{
+			name:          "unicast v6Addr",
+			ip:            unicast6,
+			globalUnicast: true,
+		},
+		{
+			name:          "unicast v6AddrZone",
+			ip:            unicastZone6,
+			globalUnicast: true,
+		},
+		{
+			name:          "unicast v6Addr unassigned",
+			ip:            unicast6Unassigned,
+			globalUnicast: true,
+		},
+		{
+			name:               "multicast v4Addr",
+			ip:                 multicast4,
+			linkLocalMulticast: true,
+			multicast:          true,
+		},
+		{
+			name:               "multicast v6Addr",
+			ip:                 multicast6,
+			linkLocalMulticast: true,
+			multicast:          true,
+		},
+		{
+			name:               "multicast v6AddrZone",
+			ip:                 multicastZone6,
+			linkLocalMulticast: true,
+			multicast:          true,
+		},
+		{
+			name:             "link-local unicast v4Addr",
+			ip:               llu4,
+			linkLocalUnicast: true,
+		},
+		{
+			name:             "link-local unicast v6Addr",
+			ip:               llu6,
+			linkLocalUnicast: true,
+		},
+		{
+			name:             "link-local unicast v6Addr upper bound",
+			ip:               llu6Last,
+			linkLocalUnicast: true,
+		},
+		{
+			name:             "link-local unicast v6AddrZone",
+			ip:               lluZone6,
+			linkLocalUnicast: true,
+		},
+		{
+			name:     "loopback v4Addr",
+			ip:       loopback4,
+			loopback: true,
+		},
+		{
+			name:     "loopback v6Addr",
+			ip:       IPv6Loopback(),
+			loopback: true,
+		},
+		{
+			name:                    "interface-local multicast v6Addr",
+			ip:                      ilm6,
+			interfaceLocalMulticast: true,
+			multicast:               true,
+		},
+		{
+			name:                    "interface-local multicast v6AddrZone",
+			ip:                      ilmZone6,
+			interfaceLocalMulticast: true,
+			multicast:               true,
+		},
+		{
+			name:          "private v4Addr 10/8",
+			ip:            private4a,
+			globalUnicast: true,
+			private:       true,
+		},
+		{
+			name:          "private v4Addr 172.16/12",
+			ip:            private4b,
+			globalUnicast: true,
+			private:       true,
+		},
+		{
+			name:          "private v4Addr 192.168/16",
+			ip:            private4c,
+			globalUnicast: true,
+			private:       true,
+		},
+		{
+			name:          "private v6Addr",
+			ip:            private6,
+			globalUnicast: true,
+			private:       true,
+		},
+		{
+			name:        "unspecified v4Addr",
+			ip:          unspecified4,
+			unspecified: true,
+		},
+		{
+			name:        "unspecified v6Addr",
+			ip:          unspecified6,
+			unspecified: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gu := tt.ip.IsGlobalUnicast()
+			if gu != tt.globalUnicast {
+				t.Errorf("IsGlobalUnicast(%v) = %v; want %v", tt.ip, gu, tt.globalUnicast)
+			}
+
+			ilm := tt.ip.IsInterfaceLocalMulticast()
+			if ilm != tt.interfaceLocalMulticast {
+				t.Errorf("IsInterfaceLocalMulticast(%v) = %v; want %v", tt.ip, ilm, tt.interfaceLocalMulticast)
+			}
+
+			llu := tt.ip.IsLinkLocalUnicast()
+			if llu != tt.linkLocalUnicast {
+				t.Errorf("IsLinkLocalUnicast(%v) = %v; want %v", tt.ip, llu, tt.linkLocalUnicast)
+			}
+
+			llm := tt.ip.IsLinkLocalMulticast()
+			if llm != tt.linkLocalMulticast {
+				t.Errorf("IsLinkLocalMulticast(%v) = %v; want %v", tt.ip, llm, tt.linkLocalMulticast)
+			}
+
+			lo := tt.ip.IsLoopback()
+			if lo != tt.loopback {
+				t.Errorf("IsLoopback(%v) = %v; want %v", tt.ip, lo, tt.loopback)
+			}
+
+			multicast := tt.ip.IsMulticast()
+			if multicast != tt.multicast {
+				t.Errorf("IsMulticast(%v) = %v; want %v", tt.ip, multicast, tt.multicast)
+			}
+
+			private := tt.ip.IsPrivate()
+			if private != tt.private {
+				t.Errorf("IsPrivate(%v) = %v; want %v", tt.ip, private, tt.private)
+			}
+
+			unspecified := tt.ip.IsUnspecified()
+			if unspecified != tt.unspecified {
+				t.Errorf("IsUnspecified(%v) = %v; want %v", tt.ip, unspecified, tt.unspecified)
+			}
+		})
+	}
+}
+
+func TestAddrWellKnown(t *testing.T) {
+	tests := []struct {
+		name string
+		ip   Addr
+		std  string
+	}{
+		{"IPv6 link-local all nodes", IPv6LinkLocalAllNodes(), "ff02::1"},
+		{"IPv6 link-local all routers", IPv6LinkLocalAllRouters(), "ff02::2"},
+		{"IPv6 loopback", IPv6Loopback(), "::1"},
+		{"IPv6 unspecified", IPv6Unspecified(), "::"},
+		{"IPv4 unspecified", IPv4Unspecified(), "0.0.0.0"},
+	}
+	for _, tt := range tests {
+		if got := tt.ip.String(); got != tt.std {
+			t.Errorf("%s: String() = %q, want %q", tt.name, got, tt.std)
+		}
+	}
+}
+
+func TestAddrStringExpanded(t *testing.T) {
+	tests := []struct {
+		ip Addr
+		s  string
+	}{
+		{
+			ip: Addr{},
+			s:  "invalid IP",
+		},
+		{
+			ip: MustParseAddr("192.0.2.1"),
+			s:  "192.0.2.1",
+		},
+		{
+			ip: MustParseAddr("::ffff:192.0.2.1"),
+			s:  "0000:0000:0000:0000:0000:ffff:c000:0201",
+		},
+		{
+			ip: MustParseAddr("2001:db8::1"),
+			s:  "2001:0db8:0000:0000:0000:0000:0000:0001",
+		},
+		{
+			ip: MustParseAddr("2001:db8::1%eth0"),
+			s:  "2001:0db8:0000:0000:0000:0000:0000:0001%eth0",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := tt.ip.StringExpanded(); got != tt.s {
+			t.Errorf("(%v).StringExpanded() = %q, want %q", tt.ip, got, tt.s)
+		}
+	}
+}
+
+func TestAddrLessCompare(t *testing.T) {
+	tests := []struct {
+		a, b Addr
+		want bool
+	}{
+		{Addr{}, Addr{}, false},
+		{Addr{}, MustParseAddr("1.2.3.4"), true},
+		{MustParseAddr("1.2.3.4"), Addr{}, false},
+
+		{MustParseAddr("1.2.3.4"), MustParseAddr("0102:0304::0"), true},
+		{MustParseAddr("0102:0304::0"), MustParseAddr("1.2.3.4"), false},
+		{MustParseAddr("1.2.3.4"), MustParseAddr("1.2.3.4"), false},
+
+		{MustParseAddr("::1"), MustParseAddr("::2"), true},
+		{MustParseAddr("::1"), MustParseAddr("::1%foo"), true},
+		{MustParseAddr("::1%foo"), MustParseAddr("::2"), true},
+		{MustParseAddr("::2"), MustParseAddr("::3"), true},
+
+		{MustParseAddr("::"), MustParseAddr("0.0.0.0"), false},
+		{MustParseAddr("0.0.0.0"), MustParseAddr("::"), true},
+
+		{MustParseAddr("::1%a"), MustParseAddr("::1%b"), true},
+		{MustParseAddr("::1%a"), MustParseAddr("::1%a"), false},
+		{MustParseAddr("::1%b"), MustParseAddr("::1%a"), false},
+	}
+	for _, tt := range tests {
+		got := tt.a.Less(tt.b)
+		if got != tt.want {
+			t.Errorf("Less(%q, %q) = %v; want %v", tt.a, tt.b, got, tt.want)
+		}
+		cmp := tt.a.Compare(tt.b)
+		if got && cmp != -1 {
+			t.Errorf("Less(%q, %q) = true, but Compare = %v (not -1)", tt.a, tt.b, cmp)
+		}
+		if cmp < -1 || cmp > 1 {
+			t.Errorf("bogus Compare return value %v", cmp)
+		}
+		if cmp == 0 && tt.a != tt.b {
+			t.Errorf("Compare(%q, %q) = 0; but not equal", tt.a, tt.b)
+		}
+		if cmp == 1 && !tt.b.Less(tt.a) {
+			t.Errorf("Compare(%q, %q) = 1; but b.Less(a) isn't true", tt.a, tt.b)
+		}
+	}
+
+	// And just sort.
+	values := []Addr{
+		MustParseAddr("::1"),
+		MustParseAddr("::2"),
+		Addr{},
+		MustParseAddr("1.2.3.4"),
+		MustParseAddr("8.8.8.8"),
+		MustParseAddr("::1%foo"),
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].Less(values[j]) })
+	got := fmt.Sprintf("%s", values)
+	want := `[invalid IP 1.2.3.4 8.8.8.8 ::1 ::1%foo ::2]`
+	if got != want {
+		t.Errorf("unexpected sort\n got: %s\nwant: %s\n", got, want)
+	}
+}
+
+func TestAddrNextPrev(t *testing.T) {
+	doNextPrev := func(t *testing.T, ip Addr, next, prev Addr) {
+		t.Helper()
+		if got := ip.Next(); got != next {
+			t.Errorf("(%v).Next() = %v, want %v", ip, got, next)
+		}
+		if got := ip.Prev(); got != prev {
+			t.Errorf("(%v).Prev() = %v, want %v", ip, got, prev)
+		}
+		if next.IsValid() && next.Prev() != ip {
+			t.Errorf("(%v).Next().Prev() = %v, want %v", ip, next.Prev(), ip)
+		}
+		if prev.IsValid() && prev.Next() != ip {
+			t.Errorf("(%v).Prev().Next() = %v, want %v", ip, prev.Next(), ip)
+		}
+	}
+
+	doNextPrev(t, MustParseAddr("10.0.0.1"), MustParseAddr("10.0.0.2"), MustParseAddr("10.0.0.0"))
+	doNextPrev(t, MustParseAddr("10.0.0.255"), MustParseAddr("10.0.1.0"), MustParseAddr("10.0.0.254"))
+	doNextPrev(t, MustParseAddr("127.0.0.1"), MustParseAddr("127.0.0.2"), MustParseAddr("127.0.0.0"))
+	doNextPrev(t, MustParseAddr("::1"), MustParseAddr("::2"), MustParseAddr("::"))
+	doNextPrev(t, MustParseAddr("::%x"), MustParseAddr("::1%x"), Addr{})
+	doNextPrev(t, MustParseAddr("0.0.0.0"), MustParseAddr("0.0.0.1"), Addr{})
+	doNextPrev(t, MustParseAddr("255.255.255.255"), Addr{}, MustParseAddr("255.255.255.254"))
+	doNextPrev(t, MustParseAddr("ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"), Addr{}, MustParseAddr("ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe"))
+	doNextPrev(t, MustParseAddr("::ffff:ffff:ffff"), MustParseAddr("::1:0:0:0"), MustParseAddr("::ffff:ffff:fffe"))
+}
+
+func TestAddrZoneUnmap(t *testing.T) {
+	ip := MustParseAddr("fe80::1%eth0")
+	if got := ip.Zone(); got != "eth0" {
+		t.Errorf("Zone() = %q, want %q", got, "eth0")
+	}
+	if got := ip.WithZone(""); got != MustParseAddr("fe80::1") {
+		t.Errorf("WithZone(\"\") = %v", got)
+	}
+	if got := ip.WithZone("eth1").Zone(); got != "eth1" {
+		t.Errorf("WithZone(\"eth1\").Zone() = %q", got)
+	}
+	if v4 := MustParseAddr("1.2.3.4"); v4.WithZone("eth0") != v4 {
+		t.Errorf("WithZone on IPv4 address changed it")
+	}
+
+	mapped := MustParseAddr("::ffff:1.2.3.4")
+	if !mapped.Is6() || mapped.Is4() || !mapped.Is4In6() {
+		t.Errorf("%v: Is4 = %v, Is6 = %v, Is4In6 = %v", mapped, mapped.Is4(), mapped.Is6(), mapped.Is4In6())
+	}
+	if got, want := mapped.Unmap(), MustParseAddr("1.2.3.4"); got != want {
+		t.Errorf("Unmap() = %v, want %v", got, want)
+	}
+	if got := mapped.BitLen(); got != 128 {
+		t.Errorf("BitLen() = %d, want 128", got)
+	}
+	if got := mapped.Unmap().BitLen(); got != 32 {
+		t.Errorf("Unmap().BitLen() = %d, want 32", got)
+	}
+}
+
+func TestAddrPrefix(t *testing.T) {
+	tests := []struct {
+		ip      string
+		bits    int
+		want    string
+		wantErr bool
+	}{
+		{ip: "1.2.3.4", bits: 0, want: "0.0.0.0/0"},
+		{ip: "1.2.3.4", bits: 8, want: "1.0.0.0/8"},
+		{ip: "1.2.3.4", bits: 24, want: "1.2.3.0/24"},
+		{ip: "1.2.3.4", bits: 32, want: "1.2.3.4/32"},
+		{ip: "1.2.3.4", bits: 33, wantErr: true},
+		{ip: "1.2.3.4", bits: -1, wantErr: true},
+		{ip: "fe80::dead:beef", bits: 0, want: "::/0"},
+		{ip: "fe80::dead:beef", bits: 64, want: "fe80::/64"},
+		{ip: "fe80::dead:beef", bits: 112, want: "fe80::dead:0/112"},
+		{ip: "fe80::dead:beef", bits: 128, want: "fe80::dead:beef/128"},
+		{ip: "fe80::dead:beef%eth0", bits: 64, want: "fe80::/64"},
+		{ip: "fe80::dead:beef", bits: 129, wantErr: true},
+		{ip: "::ffff:1.2.3.4", bits: 120, want: "::ffff:1.2.3.0/120"},
+	}
+	for _, tt := range tests {
+		p, err := MustParseAddr(tt.ip).Prefix(tt.bits)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("(%v).Prefix(%d) error = %v, want error %v", tt.ip, tt.bits, err, tt.wantErr)
+			continue
+		}
+		if err == nil && p.String() != tt.want {
+			t.Errorf("(%v).Prefix(%d) = %v, want %v", tt.ip, tt.bits, p, tt.want)
+		}
+	}
+
+	if p, err := (Addr{}).Prefix(8); err != nil || p != (Prefix{}) {
+		t.Errorf("zero Addr Prefix(8) = %v, %v, want zero Prefix", p, err)
+	}
+}
+
+func TestParseAddrPort(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    AddrPort
+		wantStr string
+		wantErr bool
+	}{
+		{in: "1.2.3.4:1234", want: AddrPortFrom(MustParseAddr("1.2.3.4"), 1234)},
+		{in: "1.1.1.1:123456", wantErr: true},
+		{in: "1.1.1.1:-123", wantErr: true},
+		{in: "[::1]:1234", want: AddrPortFrom(MustParseAddr("::1"), 1234)},
+		{in: "[1.2.3.4]:1234", wantErr: true},
+		{in: "fe80::1:1234", wantErr: true},
+		{in: ":0", wantErr: true}, // if we need to parse this form, there should be a separate function that explicitly allows it
+		{in: "1.2.3.4:", wantErr: true},
+		{in: "[::1]", wantErr: true},
+		{in: "[::1%eth0]:80", want: AddrPortFrom(MustParseAddr("::1%eth0"), 80)},
+		{in: "[::ffff:1.2.3.4]:0", want: AddrPortFrom(MustParseAddr("::ffff:1.2.3.4"), 0)},
+	}
+	for _, test := range tests {
+		t.Run(test.in, func(t *testing.T) {
+			got, err := ParseAddrPort(test.in)
+			if err != nil {
+				if test.wantErr {
+					return
+				}
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("got %v; want %v", got, test.want)
+			}
+			if got.String() != test.in {
+				t.Errorf("String = %q; want %q", got.String(), test.in)
+			}
+			testAppendToMarshal(t, got)
+		})
+
+		t.Run(test.in+"/AppendTo", func(t *testing.T) {
+			got, err := ParseAddrPort(test.in)
+			if err == nil {
+				testAppendToMarshal(t, got)
+			}
+		})
+
+		// TextMarshal and TextUnmarshal mostly behave like
+		// ParseAddrPort and String. Divergent behavior are handled in
+		// TestAddrPortMarshalUnmarshal.
+		t.Run(test.in+"/Marshal", func(t *testing.T) {
+			var got AddrPort
+			jsin := `"` + test.in + `"`
+			err := json.Unmarshal([]byte(jsin), &got)
+			if err != nil {
+				if test.wantErr {
+					return
+				}
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("got %v; want %v", got, test.want)
+			}
+			gotb, err := json.Marshal(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(gotb) != jsin {
+				t.Errorf("Marshal = %q; want %q", string(gotb), jsin)
+			}
+		})
+	}
+}
+
+func TestAddrPortMarshalUnmarshal(t *testing.T) {
+	tests := []struct {
+		in   string
+		want AddrPort
+	}{
+		{"", AddrPort{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.in, func(t *testing.T) {
+			orig := `"` + test.in + `"`
+
+			var ipp AddrPort
+			if err := json.Unmarshal([]byte(orig), &ipp); err != nil {
+				t.Fatalf("failed to unmarshal: %v", err)
+			}
+
+			ippb, err := json.Marshal(ipp)
+			if err != nil {
+				t.Fatalf("failed to marshal: %v", err)
+			}
+
+			back := string(ippb)
+			if orig != back {
+				t.Errorf("Marshal = %q; want %q", back, orig)
+			}
+		})
+	}
+
+	for _, s := range []string{"1.2.3.4:80", "[::1%eth0]:65535", "[::ffff:1.2.3.4]:0"} {
+		ipp := MustParseAddrPort(s)
+		b, err := ipp.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var back AddrPort
+		if err := back.UnmarshalBinary(b); err != nil {
+			t.Fatalf("UnmarshalBinary(%q): %v", b, err)
+		}
+		if back != ipp {
+			t.Errorf("UnmarshalBinary(MarshalBinary(%v)) = %v", ipp, back)
+		}
+	}
+}
+
+func TestPrefixMasking(t *testing.T) {
+	type subtest struct {
+		ip   Addr
+		bits uint8
+		p    Prefix
+		ok   bool
+	}
+
+	// makeIPv6 produces a set of IPv6 subtests with an optional zone identifier.
+	makeIPv6 := func(zone string) []subtest {
+		if zone != "" {
+			zone = "%" + zone
+		}
+
+		return []subtest{
+			{
+				ip:   MustParseAddr("2001:db8::1" + zone),
+				bits: 255,
+			},
+			{
+				ip:   MustParseAddr("2001:db8::1" + zone),
+				bits: 32,
+				p:    MustParsePrefix("2001:db8::/32"),
+				ok:   true,
+			},
+			{
+				ip:   MustParseAddr("fe80::dead:beef:dead:beef" + zone),
+				bits: 96,
+				p:    MustParsePrefix("fe80::dead:beef:0:0/96"),
+				ok:   true,
+			},
+			{
+				ip:   MustParseAddr("aaaa::" + zone),
+				bits: 4,
+				p:    MustParsePrefix("a000::/4"),
+				ok:   true,
+			},
+			{
+				ip:   MustParseAddr("::" + zone),
+				bits: 63,
+				p:    MustParsePrefix("::/63"),
+				ok:   true,
+			},
+		}
+	}
+
+	tests := []struct {
+		family   string
+		subtests []subtest
+	}{
+		{
+			family: "nil",
+			subtests: []subtest{
+				{
+					bits: 255,
+					ok:   true,
+				},
+				{
+					bits: 16,
+					ok:   true,
+				},
+			},
+		},
+		{
+			family: "IPv4",
+			subtests: []subtest{
+				{
+					ip:   MustParseAddr("192.0.2.0"),
+					bits: 255,
+				},
+				{
+					ip:   MustParseAddr("192.0.2.0"),
+					bits: 16,
+					p:    MustParsePrefix("192.0.0.0/16"),
+					ok:   true,
+				},
+				{
+					ip:   MustParseAddr("255.255.255.255"),
+					bits: 20,
+					p:    MustParsePrefix("255.255.240.0/20"),
+					ok:   true,
+				},
+				{
+					// Partially masking one byte that contains both
+					// 1s and 0s on either side of the mask limit.
+					ip:   MustParseAddr("100.98.156.66"),
+					bits: 10,
+					p:    MustParsePrefix("100.64.0.0/10"),
+					ok:   true,
+				},
+			},
+		},
+		{
+			family:   "IPv6",
+			subtests: makeIPv6(""),
+		},
+		{
+			family:   "IPv6 zone",
+			subtests: makeIPv6("eth0"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.family, func(t *testing.T) {
+			for _, st := range tt.subtests {
+				t.Run(st.p.String(), func(t *testing.T) {
+					// Ensure st.ip is not mutated.
+					orig := st.ip.String()
+
+					p, err := st.ip.Prefix(int(st.bits))
+					if st.ok && err != nil {
+						t.Fatalf("failed to produce prefix: %v", err)
+					}
+					if !st.ok && err == nil {
+						t.Fatal("expected an error, but none occurred")
+					}
+					if err != nil {
+						t.Logf("err: %v", err)
+						return
+					}
+
+					if !reflect.DeepEqual(p, st.p) {
+						t.Errorf("prefix = %q, want %q", p, st.p)
+					}
+
+					if got := st.ip.String(); got != orig {
+						t.Errorf("IP was mutated: %q, want %q", got, orig)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestParsePrefix(t *testing.T) {
+	tests := []struct {
+		prefix string
+		ip     Addr
+		bits   int
+		str    string
+	}{
+		{
+			prefix: "192.168.0.0/24",
+			ip:     MustParseAddr("192.168.0.0"),
+			bits:   24,
+		},
+		{
+			prefix: "192.168.0.0/32",
+			ip:     MustParseAddr("192.168.0.0"),
+			bits:   32,
+		},
+		{
+			prefix: "192.168.0.0/0",
+			ip:     MustParseAddr("192.168.0.0"),
+			bits:   0,
+		},
+		{
+			prefix: "::ffff:c000:0280/96",
+			ip:     MustParseAddr("::ffff:192.0.2.128"),
+			bits:   96,
+			str:    "::ffff:192.0.2.128/96",
+		},
+		{
+			prefix: "::ffff:192.168.140.255/8",
+			ip:     MustParseAddr("::ffff:192.168.140.255"),
+			bits:   8,
+		},
+		{
+			prefix: "2001:db8::/32",
+			ip:     MustParseAddr("2001:db8::"),
+			bits:   32,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.prefix, func(t *testing.T) {
+			prefix, err := ParsePrefix(test.prefix)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if prefix.Addr() != test.ip {
+				t.Errorf("IP=%s, want %s", prefix.Addr(), test.ip)
+			}
+			if prefix.Bits() != test.bits {
+				t.Errorf("bits=%d, want %d", prefix.Bits(), test.bits)
+			}
+			want := test.str
+			if want == "" {
+				want = test.prefix
+			}
+			if got := prefix.String(); got != want {
+				t.Errorf("prefix.String()=%q, want %q", got, want)
+			}
+
+			testAppendToMarshal(t, prefix)
+
+			b, err := prefix.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var back Prefix
+			if err := back.UnmarshalBinary(b); err != nil || back != prefix {
+				t.Errorf("UnmarshalBinary(MarshalBinary(%v)) = %v, %v", prefix, back, err)
+			}
+		})
+	}
+
+	invalidPrefixes := []string{
+		"192.168.0.0/33",
+		"192.168.0.0/-1",
+		"192.168.0.0/-0",
+		"192.168.0.0/+1",
+		"192.168.0.0/08",
+		"192.168.0.0/",
+		"192.168.0.0",
+		"::/129",
+		"::/0x10",
+		"fe80::1%eth0/64",
+		"bad/24",
+	}
+	for _, s := range invalidPrefixes {
+		if p, err := ParsePrefix(s); err == nil {
+			t.Errorf("ParsePrefix(%q) = %v, want error", s, p)
+		}
+	}
+}
+
+func TestPrefixFromInvalidBits(t *testing.T) {
+	v4 := MustParseAddr("1.2.3.4")
+	v6 := MustParseAddr("66::66")
+	tests := []struct {
+		ip       Addr
+		in, want int
+	}{
+		{v4, 0, 0},
+		{v6, 0, 0},
+		{v4, 1, 1},
+		{v4, 33, -1},
+		{v6, 33, 33},
+		{v6, 127, 127},
+		{v6, 128, 128},
+		{v4, 254, -1},
+		{v4, 255, -1},
+		{v4, -1, -1},
+		{v6, -1, -1},
+		{v4, -5, -1},
+		{v6, -5, -1},
+	}
+	for _, tt := range tests {
+		p := PrefixFrom(tt.ip, tt.in)
+		if got := p.Bits(); got != tt.want {
+			t.Errorf("for (%v, %v), Prefix.Bits() = %v; want %v", tt.ip, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPrefixContains(t *testing.T) {
+	tests := []struct {
+		ipp  Prefix
+		ip   Addr
+		want bool
+	}{
+		{MustParsePrefix("9.8.7.6/0"), MustParseAddr("9.8.7.6"), true},
+		{MustParsePrefix("9.8.7.6/16"), MustParseAddr("9.8.7.6"), true},
+		{MustParsePrefix("9.8.7.6/16"), MustParseAddr("9.8.6.4"), true},
+		{MustParsePrefix("9.8.7.6/16"), MustParseAddr("9.9.7.6"), false},
+		{MustParsePrefix("9.8.7.6/32"), MustParseAddr("9.8.7.6"), true},
+		{MustParsePrefix("9.8.7.6/32"), MustParseAddr("9.8.7.7"), false},
+		{MustParsePrefix("::1/0"), MustParseAddr("::1"), true},
+		{MustParsePrefix("::1/0"), MustParseAddr("::2"), true},
+		{MustParsePrefix("::1/127"), MustParseAddr("::1"), true},
+		{MustParsePrefix("::1/127"), MustParseAddr("::2"), false},
+		{MustParsePrefix("::1/128"), MustParseAddr("::1"), true},
+		{MustParsePrefix("::1/127"), MustParseAddr("::2"), false},
+		// Zones ignored: https://go.dev/issue/51899
+		{Prefix{}, MustParseAddr("1.2.3.4"), false},
+		{MustParsePrefix("::1/128"), MustParseAddr("::1%a"), false},
+		// invalid IP
+		{MustParsePrefix("::1/0"), Addr{}, false},
+		{MustParsePrefix("1.2.3.4/0"), Addr{}, false},
+		// invalid Prefix
+		{PrefixFrom(MustParseAddr("::1"), 129), MustParseAddr("::1"), false},
+		{PrefixFrom(MustParseAddr("1.2.3.4"), 33), MustParseAddr("1.2.3.4"), false},
+		{PrefixFrom(Addr{}, 0), MustParseAddr("1.2.3.4"), false},
+		{PrefixFrom(Addr{}, 32), MustParseAddr("1.2.3.4"), false},
+		{PrefixFrom(Addr{}, 128), MustParseAddr("1.2.3.4"), false},
+		// wrong IP family
+		{MustParsePrefix("::1/0"), MustParseAddr("1.2.3.4"), false},
+		{MustParsePrefix("1.2.3.4/0"), MustParseAddr("::1"), false},
+	}
+	for _, tt := range tests {
+		got := tt.ipp.Contains(tt.ip)
+		if got != tt.want {
+			t.Errorf("(%v).Contains(%v) = %v want %v", tt.ipp, tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestPrefixOverlaps(t *testing.T) {
+	pfx := MustParsePrefix
+	tests := []struct {
+		a, b Prefix
+		want bool
+	}{
+		{Prefix{}, pfx("1.2.0.0/16"), false},    // first zero
+		{pfx("1.2.0.0/16"), Prefix{}, false},    // second zero
+		{pfx("::0/3"), pfx("0.0.0.0/3"), false}, // different families
+
+		{pfx("1.2.0.0/16"), pfx("1.2.0.0/16"), true}, // equal
+
+		{pfx("1.2.0.0/16"), pfx("1.2.3.0/24"), true},
+		{pfx("1.2.3.0/24"), pfx("1.2.0.0/16"), true},
+
+		{pfx("1.2.0.0/16"), pfx("1.2.3.0/32"), true},
+		{pfx("1.2.3.0/32"), pfx("1.2.0.0/16"), true},
+
+		// Match /0 either order
+		{pfx("1.2.3.0/32"), pfx("0.0.0.0/0"), true},
+		{pfx("0.0.0.0/0"), pfx("1.2.3.0/32"), true},
+
+		{pfx("1.2.3.0/32"), pfx("5.5.5.5/0"), true}, // normalization not required; /0 means true
+
+		// IPv6 overlapping
+		{pfx("5::1/128"), pfx("5::0/8"), true},
+		{pfx("5::0/8"), pfx("5::1/128"), true},
+
+		// IPv6 not overlapping
+		{pfx("1::1/128"), pfx("2::2/128"), false},
+		{pfx("0100::0/8"), pfx("::1/128"), false},
+
+		// IPv4-mapped IPv6 addresses should not overlap with IPv4.
+		{PrefixFrom(AddrFrom16(MustParseAddr("1.2.0.0").As16()), 16), pfx("1.2.3.0/24"), false},
+
+		// Invalid prefixes
+		{PrefixFrom(MustParseAddr("1.2.3.4"), 33), pfx("1.2.3.0/24"), false},
+		{PrefixFrom(MustParseAddr("2000::"), 129), pfx("2000::/64"), false},
+	}
+	for i, tt := range tests {
+		if got := tt.a.Overlaps(tt.b); got != tt.want {
+			t.Errorf("%d. (%v).Overlaps(%v) = %v; want %v", i, tt.a, tt.b, got, tt.want)
+		}
+		// Overlaps is commutative
+		if got := tt.b.Overlaps(tt.a); got != tt.want {
+			t.Errorf("%d. (%v).Overlaps(%v) = %v; want %v", i, tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestPrefixIsSingleIP(t *testing.T) {
+	tests := []struct {
+		ipp  Prefix
+		want bool
+	}{
+		{ipp: MustParsePrefix("127.0.0.1/32"), want: true},
+		{ipp: MustParsePrefix("127.0.0.1/31"), want: false},
+		{ipp: MustParsePrefix("127.0.0.1/0"), want: false},
+		{ipp: MustParsePrefix("::1/128"), want: true},
+		{ipp: MustParsePrefix("::1/127"), want: false},
+		{ipp: MustParsePrefix("::1/0"), want: false},
+		{ipp: Prefix{}, want: false},
+	}
+	for _, tt := range tests {
+		got := tt.ipp.IsSingleIP()
+		if got != tt.want {
+			t.Errorf("IsSingleIP(%v) = %v want %v", tt.ipp, got, tt.want)
+		}
+	}
+}
+
+func TestNoAllocs(t *testing.T) {
+	// Wrappers that panic on error, to prove that our alloc-free
+	// methods are returning successfully.
+	panicIP := func(ip Addr, err error) Addr {
+		if err != nil {
+			panic(err)
+		}
+		return ip
+	}
+	panicPfx := func(pfx Prefix, err error) Prefix {
+		if err != nil {
+			panic(err)
+		}
+		return pfx
+	}
+	panicIPP := func(ipp AddrPort, err error) AddrPort {
+		if err != nil {
+			panic(err)
+		}
+		return ipp
+	}
+	test := func(name string, f func()) {
+		t.Run(name, func(t *testing.T) {
+			n := testing.AllocsPerRun(1000, f)
+			if n != 0 {
+				t.Fatalf("allocs = %d; want 0", int(n))
+			}
+		})
+	}
+
+	// Addr constructors
+	test("IPv4", func() { AddrFrom4([4]byte{192, 168, 1, 1}) })
+	test("IPv6", func() { AddrFrom16([16]byte{}) })
+	test("ParseAddr/4", func() { panicIP(ParseAddr("1.2.3.4")) })
+	test("ParseAddr/6", func() { panicIP(ParseAddr("::1")) })
+	test("MustParseAddr", func() { MustParseAddr("1.2.3.4") })
+	test("IPv6Unspecified", func() { IPv6Unspecified() })
+
+	// Addr methods
+	test("Addr.IsZero", func() { MustParseAddr("1.2.3.4").IsValid() })
+	test("Addr.BitLen", func() { MustParseAddr("1.2.3.4").BitLen() })
+	test("Addr.Zone/4", func() { MustParseAddr("1.2.3.4").Zone() })
+	test("Addr.Zone/6", func() { MustParseAddr("fe80::1").Zone() })
+	test("Addr.Zone/6zone", func() { MustParseAddr("fe80::1%zone").Zone() })
+	test("Addr.Compare", func() {
+		a := MustParseAddr("1.2.3.4")
+		b := MustParseAddr("2.3.4.5")
+		a.Compare(b)
+	})
+	test("Addr.Less", func() {
+		a := MustParseAddr("1.2.3.4")
+		b := MustParseAddr("2.3.4.5")
+		a.Less(b)
+	})
+	test("Addr.Unmap", func() { MustParseAddr("::ffff:1.2.3.4").Unmap() })
+	test("Addr.WithZone", func() { MustParseAddr("fe80::1").WithZone("") })
+	test("Addr.IsGlobalUnicast", func() { MustParseAddr("2001:db8::1").IsGlobalUnicast() })
+	test("Addr.Prefix/4", func() { panicPfx(MustParseAddr("1.2.3.4").Prefix(20)) })
+	test("Addr.Prefix/6", func() { panicPfx(MustParseAddr("fe80::1").Prefix(64)) })
+	test("Addr.As16", func() { MustParseAddr("1.2.3.4").As16() })
+	test("Addr.As4", func() { MustParseAddr("1.2.3.4").As4() })
+	test("Addr.Next", func() { MustParseAddr("1.2.3.4").Next() })
+	test("Addr.Prev", func() { MustParseAddr("1.2.3.4").Prev() })
+
+	// AddrPort constructors
+	test("AddrPortFrom", func() { AddrPortFrom(IPv4Unspecified(), 1234) })
+	test("ParseAddrPort", func() { panicIPP(ParseAddrPort("[::1]:1234")) })
+	test("MustParseAddrPort", func() { MustParseAddrPort("[::1]:1234") })
+
+	// Prefix constructors
+	test("PrefixFrom", func() { PrefixFrom(IPv4Unspecified(), 0) })
+	test("ParsePrefix/4", func() { panicPfx(ParsePrefix("1.2.3.4/20")) })
+	test("ParsePrefix/6", func() { panicPfx(ParsePrefix("fe80::1/64")) })
+	test("MustParsePrefix", func() { MustParsePrefix("1.2.3.4/20") })
+
+	// Prefix methods
+	test("Prefix.Contains", func() { MustParsePrefix("1.2.3.0/24").Contains(MustParseAddr("1.2.3.4")) })
+	test("Prefix.Overlaps", func() {
+		a, b := MustParsePrefix("1.2.3.0/24"), MustParsePrefix("1.2.0.0/16")
+		a.Overlaps(b)
+	})
+	test("Prefix.IsZero", func() { MustParsePrefix("1.2.0.0/16").IsValid() })
+	test("Prefix.IsSingleIP", func() { MustParsePrefix("1.2.3.4/32").IsSingleIP() })
+	test("Prefix.Masked", func() { MustParsePrefix("1.2.3.4/16").Masked() })
+}
+
+func BenchmarkParseAddr(b *testing.B) {
+	for _, s := range []string{"1.2.3.4", "fd7a:115c:a1e0:ab12:4843:cd96:626b:430b", "fe80::1%eth0"} {
+		b.Run(s, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				sinkAddr, _ = ParseAddr(s)
+			}
+		})
+	}
+}
+
+func BenchmarkAddrString(b *testing.B) {
+	for _, s := range []string{"1.2.3.4", "fd7a:115c:a1e0:ab12:4843:cd96:626b:430b", "::ffff:1.2.3.4"} {
+		ip := MustParseAddr(s)
+		b.Run(s, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				sinkString = ip.String()
+			}
+		})
+	}
+}
+
+var (
+	sinkAddr   Addr
+	sinkString string
+)
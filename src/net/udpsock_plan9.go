@@ -7,6 +7,7 @@ package net
 import (
 	"context"
 	"errors"
+	"net/netip"
 	"os"
 	"syscall"
 )
@@ -27,6 +28,12 @@ func (c *UDPConn) readFrom(b []byte) (n int, addr *UDPAddr, err error) {
 	return n, &UDPAddr{IP: h.raddr, Port: int(h.rport)}, nil
 }
 
+func (c *UDPConn) readFromAddrPort(b []byte) (int, netip.AddrPort, error) {
+	// TODO: optimize. The equivalent code on posix is alloc-free.
+	n, addr, err := c.readFrom(b)
+	return n, addr.AddrPort(), err
+}
+
 func (c *UDPConn) readMsg(b, oob []byte) (n, oobn, flags int, addr *UDPAddr, err error) {
 	return 0, 0, 0, nil, syscall.EPLAN9
 }
@@ -51,6 +58,14 @@ func (c *UDPConn) writeTo(b []byte, addr *UDPAddr) (int, error) {
 	return len(b), nil
 }
 
+func (c *UDPConn) writeToAddrPort(b []byte, addr netip.AddrPort) (int, error) {
+	// TODO: optimize. The equivalent code on posix is alloc-free.
+	if !addr.IsValid() {
+		return 0, errMissingAddress
+	}
+	return c.writeTo(b, UDPAddrFromAddrPort(addr))
+}
+
 func (c *UDPConn) writeMsg(b, oob []byte, addr *UDPAddr) (n, oobn int, err error) {
 	return 0, 0, syscall.EPLAN9
 }
@@ -8,6 +8,7 @@ package net
 
 import (
 	"internal/testenv"
+	"net/netip"
 	"reflect"
 	"runtime"
 	"testing"
@@ -444,3 +445,81 @@ func TestUDPReadSizeError(t *testing.T) {
 		}
 	}
 }
+
+func TestUDPAddrPort(t *testing.T) {
+	switch runtime.GOOS {
+	case "plan9":
+		t.Skipf("not supported on %s", runtime.GOOS)
+	}
+
+	c1, err := newLocalPacketListener("udp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c1.Close()
+	c2, err := newLocalPacketListener("udp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c2.Close()
+
+	uc1, uc2 := c1.(*UDPConn), c2.(*UDPConn)
+	addr1 := uc1.LocalAddr().(*UDPAddr).AddrPort()
+	addr2 := uc2.LocalAddr().(*UDPAddr).AddrPort()
+	if got := UDPAddrFromAddrPort(addr1).AddrPort(); got != addr1 {
+		t.Errorf("UDPAddrFromAddrPort(%v).AddrPort() = %v", addr1, got)
+	}
+
+	b := []byte("UDP ADDRPORT TEST")
+	if _, err := uc2.WriteToUDPAddrPort(b, addr1); err != nil {
+		t.Fatal(err)
+	}
+	uc1.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 64)
+	n, from, err := uc1.ReadFromUDPAddrPort(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf[:n]) != string(b) {
+		t.Errorf("got %q; want %q", buf[:n], b)
+	}
+	if from.Port() != addr2.Port() || from.Addr().Unmap() != addr2.Addr().Unmap() {
+		t.Errorf("got source address %v; want %v", from, addr2)
+	}
+
+	if _, err := uc2.WriteToUDPAddrPort(b, netip.AddrPort{}); err == nil {
+		t.Errorf("WriteToUDPAddrPort with zero AddrPort succeeded")
+	}
+}
+
+func TestAllocs(t *testing.T) {
+	switch runtime.GOOS {
+	case "plan9":
+		// Plan9 wasn't optimized.
+		t.Skipf("skipping on %v", runtime.GOOS)
+	case "windows":
+		// The allocation-free read and write paths are unix only.
+		t.Skipf("skipping on %v", runtime.GOOS)
+	}
+	conn, err := ListenUDP("udp4", &UDPAddr{IP: IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	addr := conn.LocalAddr().(*UDPAddr).AddrPort()
+	buf := make([]byte, 8)
+
+	allocs := testing.AllocsPerRun(1000, func() {
+		_, err := conn.WriteToUDPAddrPort(buf, addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _, err = conn.ReadFromUDPAddrPort(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+	if got := int(allocs); got != 0 {
+		t.Errorf("WriteToUDPAddrPort/ReadFromUDPAddrPort allocated %d objects", got)
+	}
+}
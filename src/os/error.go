@@ -5,8 +5,8 @@
 package os
 
 import (
-	"internal/oserror"
 	"internal/poll"
+	"io/fs"
 )
 
 // Portable analogs of some common system call errors.
@@ -25,11 +25,11 @@ var (
 	ErrNoDeadline = errNoDeadline() // "file type does not support deadline"
 )
 
-func errInvalid() error    { return oserror.ErrInvalid }
-func errPermission() error { return oserror.ErrPermission }
-func errExist() error      { return oserror.ErrExist }
-func errNotExist() error   { return oserror.ErrNotExist }
-func errClosed() error     { return oserror.ErrClosed }
+func errInvalid() error    { return fs.ErrInvalid }
+func errPermission() error { return fs.ErrPermission }
+func errExist() error      { return fs.ErrExist }
+func errNotExist() error   { return fs.ErrNotExist }
+func errClosed() error     { return fs.ErrClosed }
 func errNoDeadline() error { return poll.ErrNoDeadline }
 
 type timeout interface {
@@ -37,21 +37,7 @@ type timeout interface {
 }
 
 // PathError records an error and the operation and file path that caused it.
-type PathError struct {
-	Op   string
-	Path string
-	Err  error
-}
-
-func (e *PathError) Error() string { return e.Op + " " + e.Path + ": " + e.Err.Error() }
-
-func (e *PathError) Unwrap() error { return e.Err }
-
-// Timeout reports whether this error represents a timeout.
-func (e *PathError) Timeout() bool {
-	t, ok := e.Err.(timeout)
-	return ok && t.Timeout()
-}
+type PathError = fs.PathError
 
 // SyscallError records an error from a specific system call.
 type SyscallError struct {
@@ -32,6 +32,7 @@ import (
 	"strings"
 	"sync"
 	"syscall"
+	"time"
 )
 
 // Error is returned by LookPath when it fails to classify a file as an
@@ -49,6 +50,25 @@ func (e *Error) Error() string {
 
 func (e *Error) Unwrap() error { return e.Err }
 
+// ErrWaitDelay is returned by (*Cmd).Wait if the process exits with a
+// successful status code but its output pipes are not closed before the
+// command's WaitDelay expires.
+var ErrWaitDelay = errors.New("exec: WaitDelay expired before I/O complete")
+
+// wrappedError wraps an error without relying on fmt.Errorf.
+type wrappedError struct {
+	prefix string
+	err    error
+}
+
+func (w wrappedError) Error() string {
+	return w.prefix + ": " + w.err.Error()
+}
+
+func (w wrappedError) Unwrap() error {
+	return w.err
+}
+
 // Cmd represents an external command being prepared or run.
 //
 // A Cmd cannot be reused after calling its Run, Output or CombinedOutput
@@ -132,6 +152,62 @@ type Cmd struct {
 	// available after a call to Wait or Run.
 	ProcessState *os.ProcessState
 
+	// If Cancel is non-nil, the command must have been created with
+	// CommandContext and Cancel will be called when the command's
+	// Context is done. By default, CommandContext sets Cancel to
+	// call the Kill method on the command's Process.
+	//
+	// Typically a custom Cancel will send a signal to the command's
+	// Process, but it may instead take other actions to initiate
+	// cancellation, such as closing a stdin or stdout pipe or sending
+	// a shutdown request on a network socket.
+	//
+	// If the command exits with a success status after Cancel is
+	// called, and Cancel does not return an error equivalent to
+	// os.ErrProcessDone, then Wait and similar methods will return a
+	// non-nil error: either an error wrapping the one returned by
+	// Cancel, or the error from the Context. (If the command exits
+	// with a non-success status, or Cancel returns an error that wraps
+	// os.ErrProcessDone, Wait and similar methods continue to return
+	// the command's usual exit status.)
+	//
+	// If Cancel is set to nil, nothing will happen immediately when
+	// the command's Context is done, but a nonzero WaitDelay will
+	// still take effect. That may be useful, for example, to work
+	// around deadlocks in commands that do not support shutdown
+	// signals but are expected to always finish quickly.
+	//
+	// Cancel will not be called if Start returns a non-nil error.
+	Cancel func() error
+
+	// If WaitDelay is non-zero, it bounds the time spent waiting on two
+	// sources of unexpected delay in Wait: a child process that fails to
+	// exit after the associated Context is canceled, and a child process
+	// that exits but leaves its I/O pipes unclosed.
+	//
+	// The WaitDelay timer starts when either the associated Context is
+	// done or a call to Wait observes that the child process has exited,
+	// whichever occurs first. When the delay has elapsed, the command
+	// shuts down the child process and/or its I/O pipes.
+	//
+	// If the child process has failed to exit (perhaps because it
+	// ignored or failed to receive a shutdown signal from a Cancel
+	// function, or because no Cancel function was set), then it will be
+	// terminated using os.Process.Kill.
+	//
+	// Then, if the I/O pipes communicating with the child process are
+	// still open, those pipes are closed in order to unblock any
+	// goroutines currently blocked on Read or Write calls.
+	//
+	// If pipes are closed due to WaitDelay, no Cancel call has occurred,
+	// and the command has otherwise exited with a successful status,
+	// Wait and similar methods will return ErrWaitDelay instead of nil.
+	//
+	// If WaitDelay is zero (the default), I/O pipes will be read until
+	// EOF, which might not occur until orphaned subprocesses of the
+	// command have also closed their descriptors for the pipes.
+	WaitDelay time.Duration
+
 	ctx             context.Context // nil means none
 	lookPathErr     error           // LookPath error, if any.
 	finished        bool            // when Wait was called
@@ -139,8 +215,29 @@ type Cmd struct {
 	closeAfterStart []io.Closer
 	closeAfterWait  []io.Closer
 	goroutine       []func() error
-	errch           chan error // one send per goroutine
-	waitDone        chan struct{}
+
+	// goroutineErr receives the first error, if any, from the
+	// goroutines, once all of them have finished. It is nil if there
+	// are no goroutines or their result has already been received.
+	goroutineErr chan error
+
+	// ctxResult receives the result of the watchCtx goroutine once
+	// Process.Wait has returned or the Context is done. It is nil if
+	// the command has no Context.
+	ctxResult chan ctxResult
+}
+
+// A ctxResult reports the result of watching the Context of a Cmd.
+type ctxResult struct {
+	err error
+
+	// If timer is non-nil, it expires after WaitDelay has elapsed after
+	// the Context is done.
+	//
+	// (If timer is nil, that means that the Context was not done before
+	// the command completed, or no WaitDelay was set, or the WaitDelay
+	// already expired and its effect was already applied.)
+	timer *time.Timer
 }
 
 // Command returns the Cmd struct to execute the named program with
@@ -182,15 +279,22 @@ func Command(name string, arg ...string) *Cmd {
 
 // CommandContext is like Command but includes a context.
 //
-// The provided context is used to kill the process (by calling
-// os.Process.Kill) if the context becomes done before the command
-// completes on its own.
+// The provided context is used to interrupt the process
+// (by calling cmd.Cancel or os.Process.Kill)
+// if the context becomes done before the command completes on its own.
+//
+// CommandContext sets the command's Cancel function to invoke the Kill method
+// on its Process, and leaves its WaitDelay unset. The caller may change the
+// cancellation behavior by modifying those fields before starting the command.
 func CommandContext(ctx context.Context, name string, arg ...string) *Cmd {
 	if ctx == nil {
 		panic("nil Context")
 	}
 	cmd := Command(name, arg...)
 	cmd.ctx = ctx
+	cmd.Cancel = func() error {
+		return cmd.Process.Kill()
+	}
 	return cmd
 }
 
@@ -391,6 +495,11 @@ func (c *Cmd) Start() error {
 	if c.Process != nil {
 		return errors.New("exec: already started")
 	}
+	if c.Cancel != nil && c.ctx == nil {
+		c.closeDescriptors(c.closeAfterStart)
+		c.closeDescriptors(c.closeAfterWait)
+		return errors.New("exec: command with a non-nil Cancel was not created with CommandContext")
+	}
 	if c.ctx != nil {
 		select {
 		case <-c.ctx.Done():
@@ -429,30 +538,132 @@ func (c *Cmd) Start() error {
 
 	c.closeDescriptors(c.closeAfterStart)
 
-	// Don't allocate the channel unless there are goroutines to fire.
+	// Don't allocate the channels unless there are goroutines to fire.
 	if len(c.goroutine) > 0 {
-		c.errch = make(chan error, len(c.goroutine))
+		errc := make(chan error, len(c.goroutine))
 		for _, fn := range c.goroutine {
 			go func(fn func() error) {
-				c.errch <- fn()
+				errc <- fn()
 			}(fn)
 		}
-	}
 
-	if c.ctx != nil {
-		c.waitDone = make(chan struct{})
+		n := len(c.goroutine)
+		goroutineErr := make(chan error, 1)
+		c.goroutineErr = goroutineErr
 		go func() {
-			select {
-			case <-c.ctx.Done():
-				c.Process.Kill()
-			case <-c.waitDone:
+			var copyError error
+			for i := 0; i < n; i++ {
+				if err := <-errc; err != nil && copyError == nil {
+					copyError = err
+				}
 			}
+			goroutineErr <- copyError
 		}()
 	}
 
+	if c.ctx != nil {
+		c.ctxResult = make(chan ctxResult)
+		go c.watchCtx(c.ctxResult)
+	}
+
 	return nil
 }
 
+// watchCtx watches c.ctx until it is able to send a result to resultc.
+//
+// If c.ctx is done before a result can be sent, watchCtx calls c.Cancel,
+// and/or kills cmd.Process after c.WaitDelay has elapsed.
+//
+// watchCtx manipulates c.goroutineErr, so its result must be received
+// before c.awaitGoroutines is called.
+func (c *Cmd) watchCtx(resultc chan<- ctxResult) {
+	select {
+	case resultc <- ctxResult{}:
+		return
+	case <-c.ctx.Done():
+	}
+
+	var err error
+	if c.Cancel != nil {
+		if interruptErr := c.Cancel(); interruptErr == nil {
+			// We appear to have successfully interrupted the command, so any
+			// program behavior from this point may be due to ctx even if the
+			// command exits with code 0.
+			err = c.ctx.Err()
+		} else if errors.Is(interruptErr, os.ErrProcessDone) {
+			// The process already finished: we just didn't notice it yet.
+			// (Perhaps c.Wait hadn't been called, or perhaps it happened to
+			// race with c.ctx being canceled.) Don't inject a needless error.
+		} else {
+			err = wrappedError{
+				prefix: "exec: canceling Cmd",
+				err:    interruptErr,
+			}
+		}
+	}
+	if c.WaitDelay == 0 {
+		resultc <- ctxResult{err: err}
+		return
+	}
+
+	timer := time.NewTimer(c.WaitDelay)
+	select {
+	case resultc <- ctxResult{err: err, timer: timer}:
+		// c.Process.Wait returned and we've handed the timer off to c.Wait.
+		// It will take care of goroutine shutdown from here.
+		return
+	case <-timer.C:
+	}
+
+	killed := false
+	if killErr := c.Process.Kill(); killErr == nil {
+		// We appear to have killed the process. c.Process.Wait should return a
+		// non-nil error to c.Wait unless the Kill signal races with a successful
+		// exit, and if that does happen we shouldn't report a spurious error,
+		// so don't set err to anything here.
+		killed = true
+	} else if !errors.Is(killErr, os.ErrProcessDone) {
+		err = wrappedError{
+			prefix: "exec: killing Cmd",
+			err:    killErr,
+		}
+	}
+
+	if c.goroutineErr != nil {
+		select {
+		case goroutineErr := <-c.goroutineErr:
+			// Forward goroutineErr only if we don't have reason to believe it was
+			// caused by a call to Cancel or Kill above.
+			if err == nil && !killed {
+				err = goroutineErr
+			}
+		default:
+			// Close the child process's I/O pipes, in case it abandoned some
+			// subprocess that inherited them and is still holding them open.
+			//
+			// We close the pipes only after we have sent any signals we're going
+			// to send to the process (via Cancel or Kill above): if we send
+			// SIGKILL to the process, we would prefer for it to die of SIGKILL,
+			// not SIGPIPE. (However, this may still cause any orphaned
+			// subprocesses to terminate with SIGPIPE.)
+			c.closeDescriptors(c.closeAfterWait)
+			// Wait for the copying goroutines to finish, but report ErrWaitDelay
+			// for the error: any other error here could result from closing the
+			// pipes.
+			<-c.goroutineErr
+			if err == nil {
+				err = ErrWaitDelay
+			}
+		}
+
+		// Since we have already received the only result from c.goroutineErr,
+		// set it to nil to prevent awaitGoroutines from blocking on it.
+		c.goroutineErr = nil
+	}
+
+	resultc <- ctxResult{err: err}
+}
+
 // An ExitError reports an unsuccessful exit by a command.
 type ExitError struct {
 	*os.ProcessState
@@ -488,7 +699,12 @@ func (e *ExitError) Error() string {
 // returned for I/O problems.
 //
 // If any of c.Stdin, c.Stdout or c.Stderr are not an *os.File, Wait also waits
-// for the respective I/O loop copying to or from the process to complete.
+// for the respective I/O loop copying to or from the process to complete,
+// bounded by c.WaitDelay if it is set.
+//
+// If the command was started by CommandContext and its Context is done
+// before the command exits, Wait may return the Context's error or an
+// error from c.Cancel; see the documentation for the Cancel field.
 //
 // Wait releases any resources associated with the Cmd.
 func (c *Cmd) Wait() error {
@@ -501,27 +717,83 @@ func (c *Cmd) Wait() error {
 	c.finished = true
 
 	state, err := c.Process.Wait()
-	if c.waitDone != nil {
-		close(c.waitDone)
+	if err == nil && !state.Success() {
+		err = &ExitError{ProcessState: state}
 	}
 	c.ProcessState = state
 
-	var copyError error
-	for range c.goroutine {
-		if err := <-c.errch; err != nil && copyError == nil {
-			copyError = err
+	var timer *time.Timer
+	if c.ctxResult != nil {
+		watch := <-c.ctxResult
+		timer = watch.timer
+		// If c.Process.Wait returned an error, prefer that.
+		// Otherwise, report any error from the watchCtx goroutine,
+		// such as a Context cancellation or a WaitDelay overrun.
+		if err == nil && watch.err != nil {
+			err = watch.err
 		}
 	}
 
+	if copyError := c.awaitGoroutines(timer); err == nil {
+		// Report an error from the copying goroutines only if the program
+		// otherwise exited normally on its own. Otherwise, the copying error
+		// may be due to the abnormal termination.
+		err = copyError
+	}
+
 	c.closeDescriptors(c.closeAfterWait)
 
-	if err != nil {
-		return err
-	} else if !state.Success() {
-		return &ExitError{ProcessState: state}
+	return err
+}
+
+// awaitGoroutines waits for the results of the goroutines copying data to
+// or from the command's I/O pipes.
+//
+// If c.WaitDelay elapses before the goroutines complete, awaitGoroutines
+// forcibly closes their pipes and returns ErrWaitDelay.
+//
+// If timer is non-nil, it must send to timer.C at the end of c.WaitDelay.
+func (c *Cmd) awaitGoroutines(timer *time.Timer) error {
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+		c.goroutineErr = nil
+	}()
+
+	if c.goroutineErr == nil {
+		return nil // No running goroutines to await.
+	}
+
+	if timer == nil {
+		if c.WaitDelay == 0 {
+			return <-c.goroutineErr
+		}
+
+		select {
+		case err := <-c.goroutineErr:
+			// Avoid the overhead of starting a timer.
+			return err
+		default:
+		}
+
+		// No existing timer was started: either there is no Context associated
+		// with the command, or c.Process.Wait completed before the Context was
+		// done.
+		timer = time.NewTimer(c.WaitDelay)
 	}
 
-	return copyError
+	select {
+	case <-timer.C:
+		c.closeDescriptors(c.closeAfterWait)
+		// Wait for the copying goroutines to finish, but ignore any error
+		// (since it was probably caused by closing the pipes).
+		<-c.goroutineErr
+		return ErrWaitDelay
+
+	case err := <-c.goroutineErr:
+		return err
+	}
 }
 
 // Output runs the command and returns its standard output.
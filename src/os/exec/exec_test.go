@@ -11,6 +11,7 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"internal/poll"
 	"internal/testenv"
@@ -954,6 +955,17 @@ func TestHelperProcess(*testing.T) {
 	case "sleep":
 		time.Sleep(3 * time.Second)
 		os.Exit(0)
+	case "leaksleeper":
+		// Start a "sleep" subprocess that inherits our stdout,
+		// and exit without waiting for it.
+		cmd := exec.Command(os.Args[0], "-test.run=TestHelperProcess", "--", "sleep")
+		cmd.Env = os.Environ()
+		cmd.Stdout = os.Stdout
+		if err := cmd.Start(); err != nil {
+			fmt.Fprintf(os.Stderr, "Start failed: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown command %q\n", cmd)
 		os.Exit(2)
@@ -1151,6 +1163,93 @@ func TestContextCancel(t *testing.T) {
 	}
 }
 
+func TestContextCancelFunc(t *testing.T) {
+	errCancel := errors.New("cancel failed")
+	for _, tt := range []struct {
+		name      string
+		cancelErr error
+		want      error
+	}{
+		{"ok", nil, context.Canceled},
+		{"error", errCancel, errCancel},
+		{"done", os.ErrProcessDone, nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			c := helperCommandContext(t, ctx, "cat")
+			stdin, err := c.StdinPipe()
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Closing stdin makes the command exit successfully.
+			c.Cancel = func() error {
+				stdin.Close()
+				return tt.cancelErr
+			}
+			if err := c.Start(); err != nil {
+				t.Fatal(err)
+			}
+			cancel()
+			err = c.Wait()
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("Wait: %v; want nil", err)
+				}
+			} else if !errors.Is(err, tt.want) {
+				t.Errorf("Wait: %v; want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCancelWithoutContext(t *testing.T) {
+	c := helperCommand(t, "echo")
+	c.Cancel = func() error { return nil }
+	if err := c.Start(); err == nil {
+		c.Wait()
+		t.Fatal("Start succeeded with Cancel set on a command without a Context")
+	}
+}
+
+func TestWaitDelayKill(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := helperCommandContext(t, ctx, "sleep")
+	// Ignore the cancellation, so that the process is only
+	// killed once WaitDelay has elapsed.
+	c.Cancel = func() error { return nil }
+	c.WaitDelay = 100 * time.Millisecond
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	cancel()
+	err := c.Wait()
+	if _, ok := err.(*exec.ExitError); !ok {
+		t.Errorf("Wait: %v; want *exec.ExitError", err)
+	}
+	if d := time.Since(start); d >= 3*time.Second {
+		t.Errorf("Wait returned after %v; want the process to be killed after WaitDelay", d)
+	}
+}
+
+func TestWaitDelayPipes(t *testing.T) {
+	// The helper leaves a subprocess holding its stdout open,
+	// so without a WaitDelay Wait would block until that exits.
+	c := helperCommand(t, "leaksleeper")
+	var out bytes.Buffer
+	c.Stdout = &out
+	c.WaitDelay = 100 * time.Millisecond
+	start := time.Now()
+	if err := c.Run(); err != exec.ErrWaitDelay {
+		t.Errorf("Run: %v; want %v", err, exec.ErrWaitDelay)
+	}
+	if d := time.Since(start); d >= 3*time.Second {
+		t.Errorf("Run returned after %v; want the pipes to be closed after WaitDelay", d)
+	}
+}
+
 // test that environment variables are de-duped.
 func TestDedupEnvEcho(t *testing.T) {
 	testenv.MustHaveExec(t)
@@ -23,6 +23,7 @@ import (
 	"sync"
 	"syscall"
 	"testing"
+	"testing/fstest"
 	"time"
 )
 
@@ -366,6 +367,60 @@ func TestReaddir(t *testing.T) {
 	testReaddir(sysdir.name, sysdir.files, t)
 }
 
+func TestReadDir(t *testing.T) {
+	dirs, err := ReadDir(".")
+	if err != nil {
+		t.Fatalf("ReadDir .: %v", err)
+	}
+	if !sort.SliceIsSorted(dirs, func(i, j int) bool { return dirs[i].Name() < dirs[j].Name() }) {
+		t.Errorf("ReadDir . returned unsorted entries")
+	}
+	for _, m := range dot {
+		found := false
+		for _, d := range dirs {
+			if d.Name() == m {
+				if d.IsDir() || !d.Type().IsRegular() {
+					t.Errorf("ReadDir .: %s has type %v, want regular file", m, d.Type())
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("ReadDir .: %s not found", m)
+		}
+	}
+
+	if _, err := ReadDir("file_does_not_exist"); !IsNotExist(err) {
+		t.Errorf("ReadDir(file_does_not_exist) = %v, want not-exist error", err)
+	}
+}
+
+func TestDirFS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestDirFS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer RemoveAll(dir)
+
+	if err := MkdirAll(filepath.Join(dir, "sub", "deep"), 0777); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.txt", "sub/b.txt", "sub/deep/c.txt"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), []byte(name), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := fstest.TestFS(DirFS(dir), "a.txt", "sub", "sub/b.txt", "sub/deep/c.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Invalid paths are rejected rather than passed to the OS.
+	if _, err := DirFS(dir).Open("../" + filepath.Base(dir)); err == nil {
+		t.Errorf("DirFS.Open with .. element succeeded")
+	}
+}
+
 func benchmarkReaddirname(path string, b *testing.B) {
 	var nentries int
 	for i := 0; i < b.N; i++ {
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"internal/testenv"
+	"io/fs"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -522,6 +523,81 @@ func TestWalk(t *testing.T) {
 	}
 }
 
+func TestWalkDir(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "TestWalkDir")
+	if err != nil {
+		t.Fatal("creating temp dir:", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	origDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal("finding working dir:", err)
+	}
+	if err = os.Chdir(tmpDir); err != nil {
+		t.Fatal("entering temp dir:", err)
+	}
+	defer os.Chdir(origDir)
+
+	makeTree(t)
+	var errors []error
+	markFn := func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			errors = append(errors, err)
+			return nil
+		}
+		name := d.Name()
+		walkTree(tree, tree.name, func(path string, n *Node) {
+			if n.name == name {
+				n.mark++
+			}
+		})
+		return nil
+	}
+	if err := filepath.WalkDir(tree.name, markFn); err != nil {
+		t.Fatalf("no error expected, found: %s", err)
+	}
+	if len(errors) != 0 {
+		t.Fatalf("unexpected errors: %s", errors)
+	}
+	checkMarks(t, true)
+
+	// Skipping a directory skips its contents but not its siblings.
+	var visited []string
+	err = filepath.WalkDir(tree.name, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		visited = append(visited, filepath.ToSlash(path))
+		if d.IsDir() && d.Name() == "b" {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range visited {
+		if strings.HasPrefix(p, "testdata/b/") {
+			t.Errorf("WalkDir visited %s inside skipped directory", p)
+		}
+	}
+	if len(visited) == 0 || visited[len(visited)-1] != "testdata/d/z/v" {
+		t.Errorf("WalkDir visited %v, want it to end with testdata/d/z/v", visited)
+	}
+
+	// A missing root is reported to fn with a nil DirEntry.
+	err = filepath.WalkDir("missing", func(path string, d fs.DirEntry, err error) error {
+		if d != nil {
+			t.Errorf("WalkDir(missing) called fn with non-nil DirEntry")
+		}
+		return err
+	})
+	if !os.IsNotExist(err) {
+		t.Errorf("WalkDir(missing) = %v, want not-exist error", err)
+	}
+}
+
 func touch(t *testing.T, name string) {
 	f, err := os.Create(name)
 	if err != nil {
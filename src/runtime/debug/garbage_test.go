@@ -6,6 +6,7 @@ package debug_test
 
 import (
 	"internal/testenv"
+	"math"
 	"runtime"
 	. "runtime/debug"
 	"testing"
@@ -172,6 +173,47 @@ func TestSetGCPercent(t *testing.T) {
 	}
 }
 
+var setMemoryLimitSink []byte
+
+func TestSetMemoryLimit(t *testing.T) {
+	// Test that the limit is being set and returned correctly.
+	old := SetMemoryLimit(123 << 20)
+	defer SetMemoryLimit(old)
+	if got := SetMemoryLimit(-1); got != 123<<20 {
+		t.Errorf("SetMemoryLimit(123<<20); SetMemoryLimit(-1) = %d, want %d", got, 123<<20)
+	}
+	if got := SetMemoryLimit(old); got != 123<<20 {
+		t.Errorf("SetMemoryLimit(123<<20); SetMemoryLimit(x) = %d, want %d", got, 123<<20)
+	}
+	if got := SetMemoryLimit(-1); got != old {
+		t.Errorf("SetMemoryLimit(-1) = %d after restoring, want %d", got, old)
+	}
+}
+
+func TestSetMemoryLimitGCOff(t *testing.T) {
+	// With GOGC=off, only the memory limit can trigger a GC.
+	defer SetGCPercent(SetGCPercent(-1))
+	defer SetMemoryLimit(SetMemoryLimit(math.MaxInt64))
+	runtime.GC()
+
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	const limit = 64 << 20
+	SetMemoryLimit(int64(ms.Sys) + limit)
+	ngc1 := ms.NumGC
+
+	// Allocate several times the limit in garbage.
+	for i := 0; i < 4*limit; i += 1 << 20 {
+		setMemoryLimitSink = make([]byte, 1<<20)
+	}
+	setMemoryLimitSink = nil
+
+	runtime.ReadMemStats(&ms)
+	if ms.NumGC == ngc1 {
+		t.Errorf("expected the memory limit to trigger a GC, but none ran")
+	}
+}
+
 func abs64(a int64) int64 {
 	if a < 0 {
 		return -a
@@ -24,6 +24,9 @@ type BuildInfo struct {
 	Path string    // The main package path
 	Main Module    // The module containing the main package
 	Deps []*Module // Module dependencies
+
+	// Settings describes the build settings used to build the binary.
+	Settings []BuildSetting
 }
 
 // Module represents a module.
@@ -34,6 +37,13 @@ type Module struct {
 	Replace *Module // replaced by this module
 }
 
+// A BuildSetting is a key-value pair describing one setting that
+// influenced a build, such as "-pgo" and the path of the profile
+// used for profile-guided optimization.
+type BuildSetting struct {
+	Key, Value string
+}
+
 func readBuildInfo(data string) (*BuildInfo, bool) {
 	if len(data) < 32 {
 		return nil, false
@@ -41,10 +51,11 @@ func readBuildInfo(data string) (*BuildInfo, bool) {
 	data = data[16 : len(data)-16]
 
 	const (
-		pathLine = "path\t"
-		modLine  = "mod\t"
-		depLine  = "dep\t"
-		repLine  = "=>\t"
+		pathLine  = "path\t"
+		modLine   = "mod\t"
+		depLine   = "dep\t"
+		repLine   = "=>\t"
+		buildLine = "build\t"
 	)
 
 	info := &BuildInfo{}
@@ -99,6 +110,13 @@ func readBuildInfo(data string) (*BuildInfo, bool) {
 				Version: elem[1],
 				Sum:     elem[2],
 			}
+		case strings.HasPrefix(line, buildLine):
+			kv := line[len(buildLine):]
+			i := strings.IndexByte(kv, '=')
+			if i < 0 {
+				return nil, false
+			}
+			info.Settings = append(info.Settings, BuildSetting{Key: kv[:i], Value: kv[i+1:]})
 		}
 	}
 	return info, true
@@ -0,0 +1,139 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package metrics_test
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"runtime/metrics"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestDescriptionNameFormat(t *testing.T) {
+	r := regexp.MustCompile("^(?P<name>/[^:]+):(?P<unit>[^:*/]+(?:[*/][^:*/]+)*)$")
+	descriptions := metrics.All()
+	for _, desc := range descriptions {
+		if !r.MatchString(desc.Name) {
+			t.Errorf("metrics %q does not match regexp %s", desc.Name, r)
+		}
+	}
+}
+
+func TestDescriptionSorted(t *testing.T) {
+	descriptions := metrics.All()
+	if !sort.SliceIsSorted(descriptions, func(i, j int) bool {
+		return descriptions[i].Name < descriptions[j].Name
+	}) {
+		t.Error("metrics.All is not sorted by name")
+	}
+}
+
+func TestReadUnknown(t *testing.T) {
+	s := []metrics.Sample{{Name: "/does/not/exist:bytes"}}
+	metrics.Read(s)
+	if k := s[0].Value.Kind(); k != metrics.KindBad {
+		t.Errorf("unknown metric has kind %d, want KindBad", k)
+	}
+}
+
+func TestReadAll(t *testing.T) {
+	descs := metrics.All()
+	samples := make([]metrics.Sample, len(descs))
+	for i := range samples {
+		samples[i].Name = descs[i].Name
+	}
+	metrics.Read(samples)
+	for i, s := range samples {
+		if got, want := s.Value.Kind(), descs[i].Kind; got != want {
+			t.Errorf("%s: got kind %d, want %d", s.Name, got, want)
+			continue
+		}
+		if h := s.Value; h.Kind() == metrics.KindFloat64Histogram {
+			hist := h.Float64Histogram()
+			if len(hist.Counts) != len(hist.Buckets)-1 {
+				t.Errorf("%s: %d counts for %d buckets", s.Name, len(hist.Counts), len(hist.Buckets))
+			}
+			if !sort.Float64sAreSorted(hist.Buckets) {
+				t.Errorf("%s: buckets are not sorted", s.Name)
+			}
+		}
+	}
+}
+
+func formatDesc() string {
+	var b strings.Builder
+	for i, d := range metrics.All() {
+		if i != 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "\t%s\n", d.Name)
+		for _, line := range wrap(d.Description, 62) {
+			fmt.Fprintf(&b, "\t\t%s\n", line)
+		}
+	}
+	return b.String()
+}
+
+// wrap splits s into lines no longer than width,
+// breaking at spaces.
+func wrap(s string, width int) []string {
+	var lines []string
+	var line string
+	for _, w := range strings.Fields(s) {
+		if line != "" && len(line)+1+len(w) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += w
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+var generate = flag.Bool("generate", false, "update doc.go for go generate")
+
+func TestDocs(t *testing.T) {
+	want := formatDesc()
+
+	src, err := ioutil.ReadFile("doc.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The list of supported metrics runs from the line after the
+	// "Supported metrics" heading's introduction to the end of the
+	// package comment.
+	const start = "Below is the full list of supported metrics, ordered lexicographically.\n\n"
+	const end = "*/\n"
+	i := strings.Index(string(src), start)
+	if i < 0 {
+		t.Fatalf("cannot find metrics list in doc.go")
+	}
+	i += len(start)
+	j := strings.Index(string(src[i:]), end)
+	if j < 0 {
+		t.Fatalf("cannot find end of package comment in doc.go")
+	}
+	j += i
+	if got := string(src[i:j]); got == want {
+		return
+	}
+	if !*generate {
+		t.Fatalf("doc.go comment out of date; use go test -run=Docs -generate")
+	}
+	out := string(src[:i]) + want + string(src[j:])
+	if err := ioutil.WriteFile("doc.go", []byte(out), 0666); err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("updated doc.go")
+}
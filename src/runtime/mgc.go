@@ -185,6 +185,10 @@ func gcinit() {
 	// This will go into computing the initial GC goal.
 	memstats.heap_marked = uint64(float64(heapminimum) / (1 + memstats.triggerRatio))
 
+	// Set the memory limit from the environment before gcpercent
+	// so that the initial pacing takes it into account.
+	memoryLimit = readGOMEMLIMIT()
+
 	// Set gcpercent from the environment. This will also compute
 	// and set the GC trigger and goal.
 	_ = setGCPercent(readgogc())
@@ -756,7 +760,7 @@ func pollFractionalWorkerExit() bool {
 // This can be called any time. If GC is the in the middle of a
 // concurrent phase, it will adjust the pacing of that phase.
 //
-// This depends on gcpercent, memstats.heap_marked, and
+// This depends on gcpercent, memoryLimit, memstats.heap_marked, and
 // memstats.heap_live. These must be up to date.
 //
 // mheap_.lock must be held or the world must be stopped.
@@ -769,6 +773,14 @@ func gcSetTriggerRatio(triggerRatio float64) {
 		goal = memstats.heap_marked + memstats.heap_marked*uint64(gcpercent)/100
 	}
 
+	// If the memory limit leaves less room for the heap, it
+	// determines the goal instead.
+	memoryLimited := false
+	if limitGoal := memoryLimitHeapGoal(); limitGoal < goal {
+		goal = limitGoal
+		memoryLimited = true
+	}
+
 	// If we let triggerRatio go too low, then if the application
 	// is allocating very rapidly we might end up in a situation
 	// where we're allocating black during a nearly always-on GC.
@@ -805,8 +817,31 @@ func gcSetTriggerRatio(triggerRatio float64) {
 	trigger := ^uint64(0)
 	if gcpercent >= 0 {
 		trigger = uint64(float64(memstats.heap_marked) * (1 + triggerRatio))
-		// Don't trigger below the minimum heap size.
-		minTrigger := heapminimum
+	}
+	if memoryLimited {
+		// Trigger early enough to finish the cycle before the
+		// memory-limited goal. Use the same fraction of the runway
+		// to the goal that the trigger ratio uses of the runway
+		// to the GOGC-based goal.
+		runway := 0.95
+		if gcpercent > 0 && triggerRatio*100/float64(gcpercent) < runway {
+			runway = triggerRatio * 100 / float64(gcpercent)
+		}
+		limitTrigger := goal
+		if goal > memstats.heap_marked {
+			limitTrigger = memstats.heap_marked + uint64(float64(goal-memstats.heap_marked)*runway)
+		}
+		if limitTrigger < trigger {
+			trigger = limitTrigger
+		}
+	}
+	if gcpercent >= 0 || memoryLimited {
+		// Don't trigger below the minimum heap size, unless
+		// the memory limit doesn't leave room for it.
+		minTrigger := uint64(0)
+		if gcpercent >= 0 && !memoryLimited {
+			minTrigger = heapminimum
+		}
 		if !isSweepDone() {
 			// Concurrent sweep happens in the heap growth
 			// from heap_live to gc_trigger, so ensure
@@ -892,7 +927,7 @@ func gcSetTriggerRatio(triggerRatio float64) {
 //
 // mheap_.lock must be held or the world must be stopped.
 func gcEffectiveGrowthRatio() float64 {
-	egogc := float64(memstats.next_gc-memstats.heap_marked) / float64(memstats.heap_marked)
+	egogc := (float64(memstats.next_gc) - float64(memstats.heap_marked)) / float64(memstats.heap_marked)
 	if egogc < 0 {
 		// Shouldn't happen, but just in case.
 		egogc = 0
@@ -1366,6 +1401,7 @@ func gcStart(trigger gcTrigger) {
 	systemstack(func() {
 		now = startTheWorldWithSema(trace.enabled)
 		work.pauseNS += now - work.pauseStart
+		memstats.gcPauseDist.record(now - work.pauseStart)
 		work.tMark = now
 	})
 
@@ -1584,6 +1620,7 @@ top:
 			systemstack(func() {
 				now := startTheWorldWithSema(true)
 				work.pauseNS += now - work.pauseStart
+				memstats.gcPauseDist.record(now - work.pauseStart)
 			})
 			semrelease(&worldsema)
 			goto top
@@ -1688,6 +1725,12 @@ func gcMarkTermination(nextTriggerRatio float64) {
 	memstats.last_next_gc = memstats.next_gc
 	memstats.last_heap_inuse = memstats.heap_inuse
 
+	// Account for this cycle's CPU time in the memory limit's
+	// death spiral guard before pacing the next cycle.
+	tLimit := nanotime()
+	updateMemoryLimiter(tLimit, int64(work.stwprocs)*(work.tMark-work.tSweepTerm+tLimit-work.tMarkTerm)+
+		gcController.assistTime+gcController.dedicatedMarkTime+gcController.fractionalMarkTime)
+
 	// Update GC trigger and pacing for the next cycle.
 	gcSetTriggerRatio(nextTriggerRatio)
 
@@ -1700,6 +1743,7 @@ func gcMarkTermination(nextTriggerRatio float64) {
 	unixNow := sec*1e9 + int64(nsec)
 	work.pauseNS += now - work.pauseStart
 	work.tEnd = now
+	memstats.gcPauseDist.record(now - work.pauseStart)
 	atomic.Store64(&memstats.last_gc_unix, uint64(unixNow)) // must be Unix time to make sense to user
 	atomic.Store64(&memstats.last_gc_nanotime, uint64(now)) // monotonic time for us
 	memstats.pause_ns[memstats.numgc%uint32(len(memstats.pause_ns))] = uint64(work.pauseNS)
@@ -0,0 +1,179 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Soft memory limit.
+//
+// In addition to GOGC, the pacer may be bounded by a soft limit on
+// the total amount of memory mapped by the Go runtime: the heap,
+// goroutine stacks, and runtime metadata. The limit is set with the
+// GOMEMLIMIT environment variable or runtime/debug.SetMemoryLimit.
+//
+// When a limit is set, the heap goal is the smaller of the GOGC-based
+// goal and the heap size the limit leaves room for once all non-heap
+// memory is accounted for. The scavenger additionally returns free
+// heap memory to the OS so that retained memory stays under the limit.
+//
+// A limit that is smaller than the live heap would have the GC run
+// continuously (a "death spiral"). To guard against this, the runtime
+// tracks the fraction of CPU time spent in the GC and, when it exceeds
+// memoryLimitMaxGCCPUFraction, lets the heap grow past the limit by at
+// least memoryLimitMinHeapGrowth over the live heap.
+
+package runtime
+
+import _ "unsafe" // for go:linkname
+
+const (
+	// memoryLimitHeadroomPercent is the percentage of the heap goal
+	// implied by the memory limit that is held back as headroom, to
+	// account for fragmentation and the time it takes the scavenger
+	// to return memory.
+	memoryLimitHeadroomPercent = 3
+
+	// memoryLimitMinHeadroom is the minimum headroom in bytes.
+	memoryLimitMinHeadroom = 1 << 20
+
+	// memoryLimitScavengePercent is the percentage of the memory
+	// limit that the scavenger tries to keep total retained memory
+	// under.
+	memoryLimitScavengePercent = 95
+
+	// memoryLimitMaxGCCPUFraction is the fraction of available CPU
+	// time that the GC may consume before the memory limit is
+	// relaxed to avoid a death spiral.
+	memoryLimitMaxGCCPUFraction = 0.5
+
+	// memoryLimitMinHeapGrowth is the minimum growth of the heap
+	// goal over the live heap, as a fraction of the live heap,
+	// while the memory limit is relaxed.
+	memoryLimitMinHeapGrowth = 0.5
+)
+
+// memoryLimit is the soft memory limit in bytes. maxInt64 means
+// there is no limit. It is initialized from GOMEMLIMIT.
+//
+// Protected by mheap_.lock.
+var memoryLimit int64 = maxInt64
+
+// memoryLimiter tracks GC CPU usage across cycles for the memory
+// limit's death spiral guard.
+var memoryLimiter struct {
+	// cpuFraction is the fraction of available CPU time spent in
+	// the GC between the ends of the two most recent cycles.
+	cpuFraction float64
+
+	// lastCycleEnd is the nanotime at which the most recent cycle
+	// was accounted for.
+	lastCycleEnd int64
+}
+
+func readGOMEMLIMIT() int64 {
+	p := gogetenv("GOMEMLIMIT")
+	if p == "" || p == "off" {
+		return maxInt64
+	}
+	n, ok := parseByteCount(p)
+	if !ok {
+		print("GOMEMLIMIT=", p, "\n")
+		throw("malformed GOMEMLIMIT; see `go doc runtime/debug.SetMemoryLimit`")
+	}
+	return n
+}
+
+//go:linkname setMemoryLimit runtime/debug.setMemoryLimit
+func setMemoryLimit(in int64) (out int64) {
+	// Run on the system stack since we grab the heap lock.
+	systemstack(func() {
+		lock(&mheap_.lock)
+		out = memoryLimit
+		if in < 0 {
+			// A negative input only queries the limit.
+			unlock(&mheap_.lock)
+			return
+		}
+		memoryLimit = in
+		// Update pacing in response to the memory limit change.
+		gcSetTriggerRatio(memstats.triggerRatio)
+		unlock(&mheap_.lock)
+	})
+	// Pacing changed, so the scavenger should be awoken.
+	wakeScavenger()
+	return out
+}
+
+// nonHeapSys returns the memory mapped by the runtime for anything
+// other than heap spans: stacks and runtime metadata.
+//
+// mheap_.lock must be held or the world must be stopped.
+func nonHeapSys() uint64 {
+	return memstats.stacks_inuse + memstats.stacks_sys + memstats.mspan_sys +
+		memstats.mcache_sys + memstats.buckhash_sys + memstats.gc_sys +
+		memstats.other_sys
+}
+
+// memoryLimitHeapGoal returns the heap goal implied by the memory
+// limit, or ^uint64(0) if there is no limit.
+//
+// mheap_.lock must be held or the world must be stopped.
+func memoryLimitHeapGoal() uint64 {
+	limit := memoryLimit
+	if limit == maxInt64 {
+		return ^uint64(0)
+	}
+	// Whatever the limit leaves after non-heap memory is available
+	// to the heap, less some headroom.
+	var goal uint64
+	if nonHeap := nonHeapSys(); uint64(limit) > nonHeap {
+		goal = uint64(limit) - nonHeap
+	}
+	headroom := goal / 100 * memoryLimitHeadroomPercent
+	if headroom < memoryLimitMinHeadroom {
+		headroom = memoryLimitMinHeadroom
+	}
+	if goal > headroom {
+		goal -= headroom
+	} else {
+		goal = 0
+	}
+
+	// If the GC has been using too much CPU, the limit is likely
+	// too close to (or below) the live heap. Let the heap grow
+	// anyway so that the application can make progress.
+	if memoryLimiter.cpuFraction > memoryLimitMaxGCCPUFraction {
+		minGoal := memstats.heap_marked + uint64(float64(memstats.heap_marked)*memoryLimitMinHeapGrowth)
+		if goal < minGoal {
+			goal = minGoal
+		}
+	}
+	return goal
+}
+
+// memoryLimitRetainedGoal returns the amount of heap memory the
+// scavenger should retain to keep the total memory mapped by the
+// runtime under the memory limit, or ^uint64(0) if there is no limit.
+//
+// mheap_.lock must be held or the world must be stopped.
+func memoryLimitRetainedGoal() uint64 {
+	limit := memoryLimit
+	if limit == maxInt64 {
+		return ^uint64(0)
+	}
+	goal := uint64(limit) / 100 * memoryLimitScavengePercent
+	if nonHeap := nonHeapSys(); goal > nonHeap {
+		return goal - nonHeap
+	}
+	return 0
+}
+
+// updateMemoryLimiter accounts for gcCPU nanoseconds of CPU time used
+// by the GC cycle ending at now.
+//
+// The world must be stopped.
+func updateMemoryLimiter(now, gcCPU int64) {
+	l := &memoryLimiter
+	if l.lastCycleEnd != 0 && now > l.lastCycleEnd {
+		l.cpuFraction = float64(gcCPU) / float64((now-l.lastCycleEnd)*int64(gomaxprocs))
+	}
+	l.lastCycleEnd = now
+}
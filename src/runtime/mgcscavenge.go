@@ -118,6 +118,11 @@ func gcPaceScavenger() {
 	// a bit more exact.
 	retainedGoal = (retainedGoal + uint64(physPageSize) - 1) &^ (uint64(physPageSize) - 1)
 
+	// If a memory limit is set, retain no more than it allows.
+	if limitGoal := memoryLimitRetainedGoal(); limitGoal < retainedGoal {
+		retainedGoal = limitGoal &^ (uint64(physPageSize) - 1)
+	}
+
 	// Represents where we are now in the heap's contribution to RSS in bytes.
 	//
 	// Guaranteed to always be a multiple of physPageSize on systems where
@@ -212,6 +212,15 @@ func panicmem() {
 	panic(memoryError)
 }
 
+var rangeExitError = error(errorString("range function continued iteration after exit"))
+
+// panicrangeexit is called by the code generated for a range-over-func
+// loop when the iterator calls the yield function again after the loop
+// body has asked it to stop.
+func panicrangeexit() {
+	panic(rangeExitError)
+}
+
 // Create a new deferred function fn with siz bytes of arguments.
 // The compiler turns a defer statement into a call to this.
 //go:nosplit
@@ -257,6 +266,88 @@ func deferproc(siz int32, fn *funcval) { // arguments of fn follow fn
 	// been set and must not be clobbered.
 }
 
+// deferrangefunc is called by a function that executes defer statements
+// in the body of a range-over-func loop, before the loop starts. It adds a
+// defer record for the deferred calls made by the loop body to the frame
+// of its caller, and stores a token identifying the record in *tok. The
+// body, which the compiler turns into a closure, passes the token to
+// deferprocat for each of its defer statements.
+//
+// Like deferproc, deferrangefunc returns 0 normally. If one of the
+// deferred calls added to the record stops a panic, it returns 1 and the
+// compiled code jumps to the end of the function.
+//go:nosplit
+func deferrangefunc(tok *unsafe.Pointer) {
+	gp := getg()
+	if gp.m.curg != gp {
+		// go code on the system stack can't defer
+		throw("defer on system stack")
+	}
+
+	sp := getcallersp()
+	callerpc := getcallerpc()
+
+	d := newdefer(0)
+	if d._panic != nil {
+		throw("deferrangefunc: d.panic != nil after newdefer")
+	}
+	d.rangefunc = true
+	d.pc = callerpc
+	d.sp = sp
+	*tok = unsafe.Pointer(d)
+
+	return0()
+	// No code can go here - the C return register has
+	// been set and must not be clobbered.
+}
+
+// deferprocat adds the deferred call of fn to the defer record tok
+// created by deferrangefunc, so that it runs when the frame owning that
+// record returns, rather than when the caller of deferprocat does.
+func deferprocat(fn func(), tok unsafe.Pointer) {
+	head := (*_defer)(tok)
+	if !head.rangefunc {
+		throw("deferprocat: defer after range-over-func loop exited")
+	}
+	gp := getg()
+	d := newdefer(0)
+	gp._defer = d.link
+	d.fn = *(**funcval)(unsafe.Pointer(&fn))
+	d.link = head.head
+	head.head = d
+}
+
+// deferconvert replaces d, the defer record at the head of the defer
+// chain of the current goroutine, which must have been created by
+// deferrangefunc, by the deferred calls added to it. The calls were
+// added by deferprocat, most recent first, which is the order in which
+// they run.
+//go:nosplit
+func deferconvert(d *_defer) {
+	gp := getg()
+	if gp._defer != d || !d.rangefunc {
+		throw("deferconvert: bad defer record")
+	}
+	// The calls take the place of d in its frame. Set their sp now,
+	// as only the records in the chain are adjusted if the stack moves.
+	link := d.link
+	for e := d.head; e != nil; e = e.link {
+		e.sp = d.sp
+		e.pc = d.pc
+		if e.link == nil {
+			e.link = link
+			break
+		}
+	}
+	if d.head != nil {
+		gp._defer = d.head
+	} else {
+		gp._defer = link
+	}
+	d.head = nil
+	freedefer(d)
+}
+
 // deferprocStack queues a new deferred function with a defer record on the stack.
 // The defer record must have its siz and fn fields initialized.
 // All other fields can contain junk.
@@ -277,6 +368,7 @@ func deferprocStack(d *_defer) {
 	d.started = false
 	d.heap = false
 	d.openDefer = false
+	d.rangefunc = false
 	d.sp = getcallersp()
 	d.pc = getcallerpc()
 	d.framepc = 0
@@ -284,16 +376,18 @@ func deferprocStack(d *_defer) {
 	// The lines below implement:
 	//   d.panic = nil
 	//   d.fd = nil
+	//   d.head = nil
 	//   d.link = gp._defer
 	//   gp._defer = d
-	// But without write barriers. The first three are writes to
+	// But without write barriers. The first four are writes to
 	// the stack so they don't need a write barrier, and furthermore
 	// are to uninitialized memory, so they must not use a write barrier.
-	// The fourth write does not require a write barrier because we
+	// The fifth write does not require a write barrier because we
 	// explicitly mark all the defer structures, so we don't need to
 	// keep track of pointers to them with a write barrier.
 	*(*uintptr)(unsafe.Pointer(&d._panic)) = 0
 	*(*uintptr)(unsafe.Pointer(&d.fd)) = 0
+	*(*uintptr)(unsafe.Pointer(&d.head)) = 0
 	*(*uintptr)(unsafe.Pointer(&d.link)) = uintptr(unsafe.Pointer(gp._defer))
 	*(*uintptr)(unsafe.Pointer(&gp._defer)) = uintptr(unsafe.Pointer(d))
 
@@ -482,6 +576,7 @@ func freedefer(d *_defer) {
 	d.siz = 0
 	d.started = false
 	d.openDefer = false
+	d.rangefunc = false
 	d.sp = 0
 	d.pc = 0
 	d.framepc = 0
@@ -491,6 +586,7 @@ func freedefer(d *_defer) {
 	// If not, we would have called freedeferpanic or freedeferfn above,
 	// both of which throw.
 	d.link = nil
+	d.head = nil
 
 	pp.deferpool[sc] = append(pp.deferpool[sc], d)
 }
@@ -533,6 +629,13 @@ func deferreturn(arg0 uintptr) {
 	if d.sp != sp {
 		return
 	}
+	for d.rangefunc {
+		deferconvert(d)
+		d = gp._defer
+		if d == nil || d.sp != sp {
+			return
+		}
+	}
 	if d.openDefer {
 		done := runOpenDeferFrame(gp, d)
 		if !done {
@@ -591,6 +694,10 @@ func Goexit() {
 		if d == nil {
 			break
 		}
+		if d.rangefunc {
+			deferconvert(d)
+			continue
+		}
 		if d.started {
 			if d._panic != nil {
 				d._panic.aborted = true
@@ -926,6 +1033,13 @@ func gopanic(e interface{}) {
 			break
 		}
 
+		// Replace the record holding the deferred calls made by
+		// range-over-func loop bodies by the calls themselves.
+		if d.rangefunc {
+			deferconvert(d)
+			continue
+		}
+
 		// If defer was started by earlier panic or Goexit (and, since we're back here, that triggered a new panic),
 		// take defer off list. An earlier panic will not continue running, but we will make sure below that an
 		// earlier Goexit does continue running.
@@ -12,7 +12,7 @@ import (
 	"reflect"
 	"regexp"
 	"runtime"
-	"runtime/pprof/internal/profile"
+	"internal/profile"
 	"testing"
 	"unsafe"
 )
@@ -18,7 +18,7 @@ import (
 	"os/exec"
 	"regexp"
 	"runtime"
-	"runtime/pprof/internal/profile"
+	"internal/profile"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -14,7 +14,7 @@ import (
 	"os/exec"
 	"reflect"
 	"runtime"
-	"runtime/pprof/internal/profile"
+	"internal/profile"
 	"strings"
 	"testing"
 )
@@ -7,7 +7,7 @@ package pprof
 import (
 	"bytes"
 	"runtime"
-	"runtime/pprof/internal/profile"
+	"internal/profile"
 	"testing"
 )
 
@@ -458,6 +458,12 @@ type g struct {
 	timer          *timer         // cached timer for time.Sleep
 	selectDone     uint32         // are we participating in a select and did someone win the race?
 
+	// Per-G tracking of scheduling latency for runtime/metrics.
+	tracking      bool  // whether we're tracking this G for sched latency statistics
+	trackingSeq   uint8 // used to decide whether to track this G
+	runnableStamp int64 // timestamp of when the G last became runnable, only used when tracking
+	runnableTime  int64 // the amount of time spent runnable, cleared when running, only used when tracking
+
 	// Per-G GC state
 
 	// gcAssistBytes is this G's GC assist credit in terms of
@@ -666,6 +672,11 @@ type schedt struct {
 	lastpoll  uint64 // time of last network poll, 0 if currently polling
 	pollUntil uint64 // time to which current poll is sleeping
 
+	// timeToRun is a distribution of scheduling latencies, defined
+	// as the sum of time a G spends in the _Grunnable state before
+	// it transitions to _Grunning. Updated atomically.
+	timeToRun timeHistogram
+
 	lock mutex
 
 	// When increasing nmidle, nmidlelocked, nmsys, or nmfreed, be
@@ -849,6 +860,10 @@ type _defer struct {
 	// defers. We have only one defer record for the entire frame (which may
 	// currently have 0, 1, or more defers active).
 	openDefer bool
+	// rangefunc indicates that this _defer holds the deferred calls
+	// made by range-over-func loop bodies for the frame, in head, rather
+	// than a call of its own (see deferrangefunc).
+	rangefunc bool
 	sp        uintptr  // sp at time of defer
 	pc        uintptr  // pc at time of defer
 	fn        *funcval // can be nil for open-coded defers
@@ -866,6 +881,10 @@ type _defer struct {
 	// framepc/sp can be used as pc/sp pair to continue a stack trace via
 	// gentraceback().
 	framepc uintptr
+
+	// If rangefunc is true, head is the list of deferred calls added
+	// by deferprocat, most recent first.
+	head *_defer
 }
 
 // A _panic holds information about an active panic.
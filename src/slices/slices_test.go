@@ -0,0 +1,379 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package slices
+
+import (
+	"cmp"
+	"math"
+	"strings"
+	"testing"
+)
+
+var equalIntTests = []struct {
+	s1, s2 []int
+	want   bool
+}{
+	{[]int{1}, nil, false},
+	{[]int{}, nil, true},
+	{[]int{1, 2, 3}, []int{1, 2, 3}, true},
+	{[]int{1, 2, 3}, []int{1, 2, 3, 4}, false},
+}
+
+var equalFloatTests = []struct {
+	s1, s2       []float64
+	wantEqual    bool
+	wantEqualNaN bool
+}{
+	{[]float64{1, 2}, []float64{1, 2}, true, true},
+	{[]float64{1, 2, math.NaN()}, []float64{1, 2, math.NaN()}, false, true},
+}
+
+func TestEqual(t *testing.T) {
+	for _, test := range equalIntTests {
+		if got := Equal(test.s1, test.s2); got != test.want {
+			t.Errorf("Equal(%v, %v) = %t, want %t", test.s1, test.s2, got, test.want)
+		}
+	}
+	for _, test := range equalFloatTests {
+		if got := Equal(test.s1, test.s2); got != test.wantEqual {
+			t.Errorf("Equal(%v, %v) = %t, want %t", test.s1, test.s2, got, test.wantEqual)
+		}
+	}
+}
+
+// equal is simply ==.
+func equal[T comparable](v1, v2 T) bool {
+	return v1 == v2
+}
+
+// equalNaN is like == except that all NaNs are equal.
+func equalNaN[T comparable](v1, v2 T) bool {
+	isNaN := func(f T) bool { return f != f }
+	return v1 == v2 || (isNaN(v1) && isNaN(v2))
+}
+
+func TestEqualFunc(t *testing.T) {
+	for _, test := range equalIntTests {
+		if got := EqualFunc(test.s1, test.s2, equal[int]); got != test.want {
+			t.Errorf("EqualFunc(%v, %v, equal[int]) = %t, want %t", test.s1, test.s2, got, test.want)
+		}
+	}
+	for _, test := range equalFloatTests {
+		if got := EqualFunc(test.s1, test.s2, equalNaN[float64]); got != test.wantEqualNaN {
+			t.Errorf("EqualFunc(%v, %v, equalNaN[float64]) = %t, want %t", test.s1, test.s2, got, test.wantEqualNaN)
+		}
+	}
+
+	s1 := []int{1, 2, 3}
+	s2 := []string{"a", "b", "c"}
+	eq := func(v1 int, v2 string) bool { return string(rune('a'-1+v1)) == v2 }
+	if !EqualFunc(s1, s2, eq) {
+		t.Errorf("EqualFunc(%v, %v, offByOne) = false, want true", s1, s2)
+	}
+}
+
+var compareIntTests = []struct {
+	s1, s2 []int
+	want   int
+}{
+	{[]int{1}, []int{1}, 0},
+	{[]int{1}, []int{}, 1},
+	{[]int{}, []int{1}, -1},
+	{[]int{}, []int{}, 0},
+	{[]int{1, 2, 3}, []int{1, 2, 3}, 0},
+	{[]int{1, 2, 3}, []int{1, 2, 3, 4}, -1},
+	{[]int{1, 2, 3, 4}, []int{1, 2, 3}, +1},
+	{[]int{1, 2, 3}, []int{1, 4, 3}, -1},
+	{[]int{1, 4, 3}, []int{1, 2, 3}, +1},
+	{[]int{1, 4, 3}, []int{1, 2, 6}, +1},
+}
+
+func TestCompare(t *testing.T) {
+	for _, test := range compareIntTests {
+		if got := Compare(test.s1, test.s2); got != test.want {
+			t.Errorf("Compare(%v, %v) = %d, want %d", test.s1, test.s2, got, test.want)
+		}
+		if got := CompareFunc(test.s1, test.s2, cmp.Compare[int]); got != test.want {
+			t.Errorf("CompareFunc(%v, %v, cmp.Compare[int]) = %d, want %d", test.s1, test.s2, got, test.want)
+		}
+	}
+
+	nan := []float64{1, math.NaN()}
+	if got := Compare(nan, nan); got != 0 {
+		t.Errorf("Compare(%v, %v) = %d, want 0", nan, nan, got)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	s := []int{1, 2, 3, 2}
+	for _, test := range []struct {
+		v    int
+		want int
+	}{
+		{1, 0},
+		{2, 1},
+		{4, -1},
+	} {
+		if got := Index(s, test.v); got != test.want {
+			t.Errorf("Index(%v, %v) = %d, want %d", s, test.v, got, test.want)
+		}
+		if got := Contains(s, test.v); got != (test.want != -1) {
+			t.Errorf("Contains(%v, %v) = %t, want %t", s, test.v, got, test.want != -1)
+		}
+	}
+	if got := Index([]int(nil), 0); got != -1 {
+		t.Errorf("Index(nil, 0) = %d, want -1", got)
+	}
+
+	isEven := func(v int) bool { return v%2 == 0 }
+	if got := IndexFunc(s, isEven); got != 1 {
+		t.Errorf("IndexFunc(%v, isEven) = %d, want 1", s, got)
+	}
+	if ContainsFunc([]int{1, 3}, isEven) {
+		t.Errorf("ContainsFunc([1 3], isEven) = true, want false")
+	}
+}
+
+var insertTests = []struct {
+	s    []int
+	i    int
+	add  []int
+	want []int
+}{
+	{[]int{1, 2, 3}, 0, []int{4}, []int{4, 1, 2, 3}},
+	{[]int{1, 2, 3}, 1, []int{4}, []int{1, 4, 2, 3}},
+	{[]int{1, 2, 3}, 3, []int{4}, []int{1, 2, 3, 4}},
+	{[]int{1, 2, 3}, 2, []int{4, 5}, []int{1, 2, 4, 5, 3}},
+	{[]int{1, 2, 3}, 1, nil, []int{1, 2, 3}},
+}
+
+func TestInsert(t *testing.T) {
+	for _, test := range insertTests {
+		copy := Clone(test.s)
+		if got := Insert(copy, test.i, test.add...); !Equal(got, test.want) {
+			t.Errorf("Insert(%v, %d, %v...) = %v, want %v", test.s, test.i, test.add, got, test.want)
+		}
+	}
+
+	// Inserting a part of the slice into itself, with spare capacity.
+	s := make([]int, 5, 10)
+	for i := range s {
+		s[i] = i
+	}
+	got := Insert(s, 1, s[2:4]...)
+	want := []int{0, 2, 3, 1, 2, 3, 4}
+	if !Equal(got, want) {
+		t.Errorf("Insert of aliased values = %v, want %v", got, want)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Insert out of range did not panic")
+		}
+	}()
+	Insert([]int{1}, 2, 3)
+}
+
+var deleteTests = []struct {
+	s    []int
+	i, j int
+	want []int
+}{
+	{[]int{1, 2, 3}, 0, 0, []int{1, 2, 3}},
+	{[]int{1, 2, 3}, 0, 1, []int{2, 3}},
+	{[]int{1, 2, 3}, 3, 3, []int{1, 2, 3}},
+	{[]int{1, 2, 3}, 0, 2, []int{3}},
+	{[]int{1, 2, 3}, 0, 3, []int{}},
+}
+
+func TestDelete(t *testing.T) {
+	for _, test := range deleteTests {
+		copy := Clone(test.s)
+		if got := Delete(copy, test.i, test.j); !Equal(got, test.want) {
+			t.Errorf("Delete(%v, %d, %d) = %v, want %v", test.s, test.i, test.j, got, test.want)
+		}
+	}
+}
+
+func TestDeleteClearTail(t *testing.T) {
+	mem := []*int{new(int), new(int), new(int), new(int)}
+	s := Delete(mem, 1, 3)
+	if len(s) != 2 || mem[2] != nil || mem[3] != nil {
+		t.Errorf("Delete did not clear the obsolete elements: %v", mem)
+	}
+
+	mem = []*int{new(int), nil, new(int)}
+	s = DeleteFunc(mem, func(p *int) bool { return p == nil })
+	if len(s) != 2 || mem[2] != nil {
+		t.Errorf("DeleteFunc did not clear the obsolete elements: %v", mem)
+	}
+}
+
+func TestDeleteFunc(t *testing.T) {
+	s := []int{1, 2, 3, 4, 5, 6}
+	got := DeleteFunc(Clone(s), func(v int) bool { return v%2 == 0 })
+	if want := []int{1, 3, 5}; !Equal(got, want) {
+		t.Errorf("DeleteFunc(%v, isEven) = %v, want %v", s, got, want)
+	}
+	got = DeleteFunc(Clone(s), func(int) bool { return false })
+	if !Equal(got, s) {
+		t.Errorf("DeleteFunc(%v, false) = %v, want %v", s, got, s)
+	}
+}
+
+func TestReplace(t *testing.T) {
+	for _, test := range []struct {
+		s, v []int
+		i, j int
+		want []int
+	}{
+		{[]int{1, 2, 3, 4}, []int{5}, 1, 2, []int{1, 5, 3, 4}},
+		{[]int{1, 2, 3, 4}, []int{5, 6, 7, 8}, 1, 2, []int{1, 5, 6, 7, 8, 3, 4}},
+		{[]int{1, 2, 3, 4}, nil, 1, 3, []int{1, 4}},
+		{[]int{1, 2, 3, 4}, []int{5}, 2, 4, []int{1, 2, 5}},
+		{[]int{1, 2, 3, 4}, []int{5}, 2, 2, []int{1, 2, 5, 3, 4}},
+	} {
+		s := Clone(test.s)
+		if got := Replace(s, test.i, test.j, test.v...); !Equal(got, test.want) {
+			t.Errorf("Replace(%v, %d, %d, %v) = %v, want %v", test.s, test.i, test.j, test.v, got, test.want)
+		}
+	}
+
+	// Replacing with a part of the slice itself, with spare capacity.
+	s := make([]int, 5, 10)
+	for i := range s {
+		s[i] = i
+	}
+	got := Replace(s, 1, 2, s[2:5]...)
+	want := []int{0, 2, 3, 4, 2, 3, 4}
+	if !Equal(got, want) {
+		t.Errorf("Replace with aliased values = %v, want %v", got, want)
+	}
+}
+
+func TestClone(t *testing.T) {
+	s1 := []int{1, 2, 3}
+	s2 := Clone(s1)
+	if !Equal(s1, s2) {
+		t.Errorf("Clone(%v) = %v, want %v", s1, s2, s1)
+	}
+	s1[0] = 4
+	if s2[0] != 1 {
+		t.Errorf("Clone shares storage with its argument: %v", s2)
+	}
+	if got := Clone([]int(nil)); got != nil {
+		t.Errorf("Clone(nil) = %#v, want nil", got)
+	}
+	if got := Clone(s1[:0]); got == nil || len(got) != 0 {
+		t.Errorf("Clone(%v) = %#v, want %#v", s1[:0], got, s1[:0])
+	}
+}
+
+var compactTests = []struct {
+	name string
+	s    []int
+	want []int
+}{
+	{"nil", nil, nil},
+	{"one", []int{1}, []int{1}},
+	{"sorted", []int{1, 2, 3}, []int{1, 2, 3}},
+	{"2 items", []int{1, 1, 2}, []int{1, 2}},
+	{"unsorted", []int{1, 2, 1}, []int{1, 2, 1}},
+	{"many", []int{1, 2, 2, 3, 3, 4}, []int{1, 2, 3, 4}},
+}
+
+func TestCompact(t *testing.T) {
+	for _, test := range compactTests {
+		copy := Clone(test.s)
+		if got := Compact(copy); !Equal(got, test.want) {
+			t.Errorf("Compact(%v) = %v, want %v", test.s, got, test.want)
+		}
+	}
+}
+
+func TestCompactFunc(t *testing.T) {
+	for _, test := range compactTests {
+		copy := Clone(test.s)
+		if got := CompactFunc(copy, equal[int]); !Equal(got, test.want) {
+			t.Errorf("CompactFunc(%v, equal[int]) = %v, want %v", test.s, got, test.want)
+		}
+	}
+
+	s1 := []string{"a", "a", "A", "B", "b"}
+	copy := Clone(s1)
+	want := []string{"a", "B"}
+	if got := CompactFunc(copy, strings.EqualFold); !Equal(got, want) {
+		t.Errorf("CompactFunc(%v, strings.EqualFold) = %v, want %v", s1, got, want)
+	}
+}
+
+func TestGrow(t *testing.T) {
+	s1 := []int{1, 2, 3}
+
+	copy := Clone(s1)
+	s2 := Grow(copy, 1000)
+	if !Equal(s1, s2) {
+		t.Errorf("Grow(%v) = %v, want %v", s1, s2, s1)
+	}
+	if cap(s2) < 1000+len(s1) {
+		t.Errorf("after Grow(%v) cap = %d, want >= %d", s1, cap(s2), 1000+len(s1))
+	}
+
+	// Test mutation of elements between length and capacity.
+	copy = Clone(s1)
+	s3 := Grow(copy[:1], 2)[:3]
+	if !Equal(s1, s3) {
+		t.Errorf("Grow should not mutate elements between length and capacity")
+	}
+
+	var gotPanic bool
+	func() {
+		defer func() { gotPanic = recover() != nil }()
+		Grow(s1, -1)
+	}()
+	if !gotPanic {
+		t.Errorf("Grow(-1) did not panic; expected a panic")
+	}
+}
+
+func TestClip(t *testing.T) {
+	s1 := []int{1, 2, 3, 4, 5, 6}[:3]
+	orig := Clone(s1)
+	if len(s1) != 3 {
+		t.Errorf("len(%v) = %d, want 3", s1, len(s1))
+	}
+	if cap(s1) < 6 {
+		t.Errorf("cap(%v[:3]) = %d, want >= 6", orig, cap(s1))
+	}
+	s2 := Clip(s1)
+	if !Equal(s1, s2) {
+		t.Errorf("Clip(%v) = %v, want %v", s1, s2, s1)
+	}
+	if cap(s2) != 3 {
+		t.Errorf("cap(Clip(%v)) = %d, want 3", orig, cap(s2))
+	}
+}
+
+func TestReverse(t *testing.T) {
+	even := []int{3, 1, 4, 1, 5, 9} // len = 6
+	Reverse(even)
+	if want := []int{9, 5, 1, 4, 1, 3}; !Equal(even, want) {
+		t.Errorf("Reverse(even) = %v, want %v", even, want)
+	}
+
+	odd := []int{3, 1, 4, 1, 5} // len = 5
+	Reverse(odd)
+	if want := []int{5, 1, 4, 1, 3}; !Equal(odd, want) {
+		t.Errorf("Reverse(odd) = %v, want %v", odd, want)
+	}
+
+	singleton := []string{"one"}
+	Reverse(singleton)
+	if want := []string{"one"}; !Equal(singleton, want) {
+		t.Errorf("Reverse(singeleton) = %v, want %v", singleton, want)
+	}
+
+	Reverse[[]string](nil)
+}
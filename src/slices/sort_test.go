@@ -0,0 +1,383 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package slices
+
+import (
+	"cmp"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var ints = [...]int{74, 59, 238, -784, 9845, 959, 905, 0, 0, 42, 7586, -5467984, 7586}
+var float64s = [...]float64{74.3, 59.0, math.Inf(1), 238.2, -784.0, 2.3, math.Inf(-1), 9845.768, -959.7485, 905, 7.8, 7.8, 74.3, 59.0, math.Inf(1), 238.2, -784.0, 2.3}
+var float64sWithNaNs = [...]float64{74.3, 59.0, math.Inf(1), 238.2, -784.0, 2.3, math.NaN(), math.NaN(), math.Inf(-1), 9845.768, -959.7485, 905, 7.8, 7.8}
+var strs = [...]string{"", "Hello", "foo", "bar", "foo", "f00", "%*&^*&^&", "***"}
+
+func TestSortIntSlice(t *testing.T) {
+	data := Clone(ints[:])
+	Sort(data)
+	if !IsSorted(data) {
+		t.Errorf("sorted %v", ints)
+		t.Errorf("   got %v", data)
+	}
+}
+
+func TestSortFuncIntSlice(t *testing.T) {
+	data := Clone(ints[:])
+	SortFunc(data, func(a, b int) int { return a - b })
+	if !IsSorted(data) {
+		t.Errorf("sorted %v", ints)
+		t.Errorf("   got %v", data)
+	}
+}
+
+func TestSortFloat64Slice(t *testing.T) {
+	data := Clone(float64s[:])
+	Sort(data)
+	if !IsSorted(data) {
+		t.Errorf("sorted %v", float64s)
+		t.Errorf("   got %v", data)
+	}
+}
+
+func TestSortFloat64SliceWithNaNs(t *testing.T) {
+	data := float64sWithNaNs[:]
+	data2 := Clone(data)
+
+	Sort(data)
+	sort.Float64s(data2)
+
+	if !IsSorted(data) {
+		t.Error("IsSorted indicates data isn't sorted")
+	}
+
+	// Compare for equality using cmp.Compare, which considers NaNs equal.
+	if !EqualFunc(data, data2, func(a, b float64) bool { return cmp.Compare(a, b) == 0 }) {
+		t.Errorf("mismatch between Sort and sort.Float64: got %v, want %v", data, data2)
+	}
+}
+
+func TestSortStringSlice(t *testing.T) {
+	data := Clone(strs[:])
+	Sort(data)
+	if !IsSorted(data) {
+		t.Errorf("sorted %v", strs)
+		t.Errorf("   got %v", data)
+	}
+}
+
+func TestSortLarge_Random(t *testing.T) {
+	n := 1000000
+	if testing.Short() {
+		n /= 100
+	}
+	data := make([]int, n)
+	for i := 0; i < len(data); i++ {
+		data[i] = rand.Intn(100)
+	}
+	if IsSorted(data) {
+		t.Fatalf("terrible rand.rand")
+	}
+	Sort(data)
+	if !IsSorted(data) {
+		t.Errorf("sort didn't sort - 1M ints")
+	}
+}
+
+// Exercise every pdqsort code path: short slices, already sorted or reversed
+// input, many duplicates and patterns that defeat the pivot selection.
+func TestSortPatterns(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 12, 13, 50, 100, 1023, 1024, 1025, 10000} {
+		patterns := map[string]func(i int) int{
+			"sorted":   func(i int) int { return i },
+			"reversed": func(i int) int { return n - i },
+			"equal":    func(i int) int { return 0 },
+			"sawtooth": func(i int) int { return i % 7 },
+			"organpipe": func(i int) int {
+				if i < n/2 {
+					return i
+				}
+				return n - i
+			},
+			"random": func(i int) int { return rand.Intn(n + 1) },
+		}
+		for name, f := range patterns {
+			data := make([]int, n)
+			for i := range data {
+				data[i] = f(i)
+			}
+			want := Clone(data)
+			sort.Ints(want)
+
+			got := Clone(data)
+			Sort(got)
+			if !Equal(got, want) {
+				t.Errorf("Sort(%s, n=%d) = %v, want %v", name, n, got, want)
+			}
+
+			got = Clone(data)
+			SortFunc(got, cmp.Compare[int])
+			if !Equal(got, want) {
+				t.Errorf("SortFunc(%s, n=%d) = %v, want %v", name, n, got, want)
+			}
+		}
+	}
+}
+
+type intPair struct {
+	a, b int
+}
+
+func intPairCmp(x, y intPair) int {
+	return x.a - y.a
+}
+
+func TestStability(t *testing.T) {
+	n, m := 100000, 1000
+	if testing.Short() {
+		n, m = 1000, 100
+	}
+	data := make([]intPair, n)
+
+	// random distribution
+	for i := 0; i < len(data); i++ {
+		data[i].a = rand.Intn(m)
+		data[i].b = i
+	}
+	SortStableFunc(data, intPairCmp)
+	checkStable(t, data)
+
+	// already sorted
+	SortStableFunc(data, intPairCmp)
+	checkStable(t, data)
+
+	// sorted reversed
+	for i := 0; i < len(data); i++ {
+		data[i].a = len(data) - i
+		data[i].b = i
+	}
+	SortStableFunc(data, intPairCmp)
+	checkStable(t, data)
+}
+
+func checkStable(t *testing.T, data []intPair) {
+	t.Helper()
+	if !IsSortedFunc(data, intPairCmp) {
+		t.Fatal("SortStableFunc didn't sort")
+	}
+	for i := 1; i < len(data); i++ {
+		if data[i-1].a == data[i].a && data[i-1].b > data[i].b {
+			t.Fatalf("SortStableFunc isn't stable at %d: %v, %v", i, data[i-1], data[i])
+		}
+	}
+}
+
+func TestMinMax(t *testing.T) {
+	intCmp := func(a, b int) int { return a - b }
+
+	tests := []struct {
+		data    []int
+		wantMin int
+		wantMax int
+	}{
+		{[]int{7}, 7, 7},
+		{[]int{1, 2}, 1, 2},
+		{[]int{2, 1}, 1, 2},
+		{[]int{1, 2, 3}, 1, 3},
+		{[]int{3, 2, 1}, 1, 3},
+		{[]int{2, 1, 3}, 1, 3},
+		{[]int{2, 2, 3}, 2, 3},
+		{[]int{3, 2, 3}, 2, 3},
+		{[]int{0, 2, -9}, -9, 2},
+	}
+	for _, tt := range tests {
+		if got := Min(tt.data); got != tt.wantMin {
+			t.Errorf("Min(%v) = %d, want %d", tt.data, got, tt.wantMin)
+		}
+		if got := MinFunc(tt.data, intCmp); got != tt.wantMin {
+			t.Errorf("MinFunc(%v) = %d, want %d", tt.data, got, tt.wantMin)
+		}
+		if got := Max(tt.data); got != tt.wantMax {
+			t.Errorf("Max(%v) = %d, want %d", tt.data, got, tt.wantMax)
+		}
+		if got := MaxFunc(tt.data, intCmp); got != tt.wantMax {
+			t.Errorf("MaxFunc(%v) = %d, want %d", tt.data, got, tt.wantMax)
+		}
+	}
+
+	if got := Min([]float64{1, math.NaN(), -1}); !math.IsNaN(got) {
+		t.Errorf("Min with NaN = %v, want NaN", got)
+	}
+	if got := Max([]float64{1, math.NaN(), -1}); !math.IsNaN(got) {
+		t.Errorf("Max with NaN = %v, want NaN", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Min of an empty slice did not panic")
+		}
+	}()
+	Min([]int{})
+}
+
+func TestBinarySearch(t *testing.T) {
+	str1 := []string{"foo"}
+	str2 := []string{"ab", "ca"}
+	str3 := []string{"mo", "qo", "vo"}
+	str4 := []string{"ab", "ad", "ca", "xy"}
+
+	// slice with repeating elements
+	strRepeats := []string{"ba", "ca", "da", "da", "da", "ka", "ma", "ma", "ta"}
+
+	// slice with all element equal
+	strSame := []string{"xx", "xx", "xx"}
+
+	tests := []struct {
+		data      []string
+		target    string
+		wantPos   int
+		wantFound bool
+	}{
+		{[]string{}, "foo", 0, false},
+		{[]string{}, "", 0, false},
+
+		{str1, "foo", 0, true},
+		{str1, "bar", 0, false},
+		{str1, "zx", 1, false},
+
+		{str2, "aa", 0, false},
+		{str2, "ab", 0, true},
+		{str2, "ad", 1, false},
+		{str2, "ca", 1, true},
+		{str2, "ra", 2, false},
+
+		{str3, "bb", 0, false},
+		{str3, "mo", 0, true},
+		{str3, "nb", 1, false},
+		{str3, "qo", 1, true},
+		{str3, "tr", 2, false},
+		{str3, "vo", 2, true},
+		{str3, "xr", 3, false},
+
+		{str4, "aa", 0, false},
+		{str4, "ab", 0, true},
+		{str4, "ac", 1, false},
+		{str4, "ad", 1, true},
+		{str4, "ax", 2, false},
+		{str4, "ca", 2, true},
+		{str4, "cc", 3, false},
+		{str4, "dd", 3, false},
+		{str4, "xy", 3, true},
+		{str4, "zz", 4, false},
+
+		{strRepeats, "da", 2, true},
+		{strRepeats, "db", 5, false},
+		{strRepeats, "ma", 6, true},
+		{strRepeats, "mb", 8, false},
+
+		{strSame, "xx", 0, true},
+		{strSame, "ab", 0, false},
+		{strSame, "zz", 3, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			{
+				pos, found := BinarySearch(tt.data, tt.target)
+				if pos != tt.wantPos || found != tt.wantFound {
+					t.Errorf("BinarySearch got (%v, %v), want (%v, %v)", pos, found, tt.wantPos, tt.wantFound)
+				}
+			}
+
+			{
+				pos, found := BinarySearchFunc(tt.data, tt.target, strings.Compare)
+				if pos != tt.wantPos || found != tt.wantFound {
+					t.Errorf("BinarySearchFunc got (%v, %v), want (%v, %v)", pos, found, tt.wantPos, tt.wantFound)
+				}
+			}
+		})
+	}
+}
+
+func TestBinarySearchInts(t *testing.T) {
+	data := []int{20, 30, 40, 50, 60, 70, 80, 90}
+	tests := []struct {
+		target    int
+		wantPos   int
+		wantFound bool
+	}{
+		{20, 0, true},
+		{23, 1, false},
+		{43, 3, false},
+		{80, 6, true},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.target), func(t *testing.T) {
+			pos, found := BinarySearch(data, tt.target)
+			if pos != tt.wantPos || found != tt.wantFound {
+				t.Errorf("BinarySearch got (%v, %v), want (%v, %v)", pos, found, tt.wantPos, tt.wantFound)
+			}
+
+			cmp := func(a, b int) int {
+				return a - b
+			}
+			pos, found = BinarySearchFunc(data, tt.target, cmp)
+			if pos != tt.wantPos || found != tt.wantFound {
+				t.Errorf("BinarySearchFunc got (%v, %v), want (%v, %v)", pos, found, tt.wantPos, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestBinarySearchFloats(t *testing.T) {
+	data := []float64{math.NaN(), -0.25, 0.0, 1.4}
+	tests := []struct {
+		target    float64
+		wantPos   int
+		wantFound bool
+	}{
+		{math.NaN(), 0, true},
+		{math.Inf(-1), 1, false},
+		{-0.25, 1, true},
+		{0.0, 2, true},
+		{1.4, 3, true},
+		{1.5, 4, false},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.FormatFloat(tt.target, 'g', -1, 64), func(t *testing.T) {
+			pos, found := BinarySearch(data, tt.target)
+			if pos != tt.wantPos || found != tt.wantFound {
+				t.Errorf("BinarySearch got (%v, %v), want (%v, %v)", pos, found, tt.wantPos, tt.wantFound)
+			}
+		})
+	}
+}
+
+func BenchmarkSortInts(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		ints := make([]int, 1<<16)
+		for i := range ints {
+			ints[i] = rand.Int()
+		}
+		b.StartTimer()
+		Sort(ints)
+	}
+}
+
+func BenchmarkSortFuncInts(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		ints := make([]int, 1<<16)
+		for i := range ints {
+			ints[i] = rand.Int()
+		}
+		b.StartTimer()
+		SortFunc(ints, cmp.Compare[int])
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package strings_test
+
+import (
+	"iter"
+	. "strings"
+	"testing"
+	"unicode"
+)
+
+func collect(seq iter.Seq[string]) []string {
+	var s []string
+	for v := range seq {
+		s = append(s, v)
+	}
+	return s
+}
+
+func TestLines(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"a", []string{"a"}},
+		{"a\n", []string{"a\n"}},
+		{"a\nb", []string{"a\n", "b"}},
+		{"a\nb\n", []string{"a\n", "b\n"}},
+		{"\n\n", []string{"\n", "\n"}},
+	} {
+		if got := collect(Lines(tt.in)); !eq(got, tt.want) {
+			t.Errorf("Lines(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSplitSeq(t *testing.T) {
+	for _, tt := range splittests {
+		if tt.n != -1 {
+			continue
+		}
+		if got := collect(SplitSeq(tt.s, tt.sep)); !eq(got, tt.a) {
+			t.Errorf("SplitSeq(%q, %q) = %q, want %q", tt.s, tt.sep, got, tt.a)
+		}
+	}
+	for _, tt := range splitaftertests {
+		if tt.n != -1 {
+			continue
+		}
+		if got := collect(SplitAfterSeq(tt.s, tt.sep)); !eq(got, tt.a) {
+			t.Errorf("SplitAfterSeq(%q, %q) = %q, want %q", tt.s, tt.sep, got, tt.a)
+		}
+	}
+}
+
+func TestFieldsSeq(t *testing.T) {
+	for _, tt := range fieldstests {
+		got := collect(FieldsSeq(tt.s))
+		if len(got) == 0 && len(tt.a) == 0 {
+			continue
+		}
+		if !eq(got, tt.a) {
+			t.Errorf("FieldsSeq(%q) = %q, want %q", tt.s, got, tt.a)
+		}
+	}
+	for _, tt := range FieldsFuncTests {
+		got := collect(FieldsFuncSeq(tt.s, unicode.IsSpace))
+		if len(got) == 0 && len(tt.a) == 0 {
+			continue
+		}
+		if !eq(got, Fields(tt.s)) {
+			t.Errorf("FieldsFuncSeq(%q, unicode.IsSpace) = %q, want %q", tt.s, got, Fields(tt.s))
+		}
+	}
+}
+
+func TestSeqBreak(t *testing.T) {
+	var got []string
+	for s := range SplitSeq("a,b,c,d", ",") {
+		if s == "c" {
+			break
+		}
+		got = append(got, s)
+	}
+	if want := []string{"a", "b"}; !eq(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
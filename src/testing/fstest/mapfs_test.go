@@ -0,0 +1,34 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fstest
+
+import (
+	"io/fs"
+	"testing"
+)
+
+func TestMapFS(t *testing.T) {
+	m := MapFS{
+		"hello":             {Data: []byte("hello, world\n")},
+		"fortune/k/ken.txt": {Data: []byte("If a program is too slow, it must have a loop.\n")},
+	}
+	if err := TestFS(m, "hello", "fortune", "fortune/k", "fortune/k/ken.txt"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMapFSMissing(t *testing.T) {
+	m := MapFS{"hello": {Data: []byte("hello")}}
+	if err := TestFS(m, "hello", "goodbye"); err == nil {
+		t.Fatal("TestFS succeeded with a missing expected file")
+	}
+}
+
+func TestMapFSEmptyDir(t *testing.T) {
+	m := MapFS{"empty": {Mode: fs.ModeDir | 0755}}
+	if err := TestFS(m, "empty"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,579 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+func initFuzzFlags() {
+	matchFuzz = flag.String("test.fuzz", "", "run the fuzz target matching `regexp`")
+	fuzzDuration = flag.Duration("test.fuzztime", 0, "time to spend fuzzing; default is to run indefinitely")
+	minimizeDuration = flag.Duration("test.fuzzminimizetime", 60*time.Second, "time to spend minimizing a value after finding a failing input")
+	fuzzCacheDir = flag.String("test.fuzzcachedir", "", "directory where interesting fuzzing inputs are stored")
+	isFuzzWorker = flag.Bool("test.fuzzworker", false, "coordinate with the parent process to fuzz random values")
+}
+
+var (
+	matchFuzz        *string
+	fuzzDuration     *time.Duration
+	minimizeDuration *time.Duration
+	fuzzCacheDir     *string
+	isFuzzWorker     *bool
+
+	// corpusDir is the parent directory of the target's seed corpus within
+	// the package.
+	corpusDir = "testdata/fuzz"
+)
+
+// fuzzWorkerExitCode is used as an exit code by fuzz worker processes after an
+// internal error. This distinguishes internal errors from uncontrolled panics
+// and other failures. Keep in sync with internal/fuzz.workerExitCode.
+const fuzzWorkerExitCode = 70
+
+// InternalFuzzTarget is an internal type but exported because it is cross-package;
+// it is part of the implementation of the "go test" command.
+type InternalFuzzTarget struct {
+	Name string
+	Fn   func(f *F)
+}
+
+// F is a type passed to fuzz targets.
+//
+// A fuzz target may add seed corpus entries using F.Add or by storing files in
+// the testdata/fuzz/<FuzzTargetName> directory. The fuzz target must then
+// call F.Fuzz once to provide a fuzz function. See the testing package
+// documentation for an example, and see the F.Fuzz and F.Add method
+// documentation for details.
+//
+// *F methods can only be called before (*F).Fuzz. Once the test is
+// executing the fuzz function, only (*T) methods can be used. The only *F
+// methods that are allowed in the (*F).Fuzz function are (*F).Failed and
+// (*F).Name.
+type F struct {
+	common
+	fuzzContext *fuzzContext
+	testContext *testContext
+
+	// inFuzzFn is true when the fuzz function is running. Most F methods cannot
+	// be called when inFuzzFn is true.
+	inFuzzFn bool
+
+	// corpus is a set of seed corpus entries, added with F.Add and loaded
+	// from testdata.
+	corpus []corpusEntry
+
+	fuzzCalled bool
+}
+
+var _ TB = (*F)(nil)
+
+// corpusEntry is an alias to the same type as internal/fuzz.CorpusEntry.
+// We use a type alias because we don't want to export this type, and we can't
+// import internal/fuzz from testing.
+type corpusEntry = struct {
+	Parent     string
+	Path       string
+	Data       []byte
+	Values     []interface{}
+	Generation int
+	IsSeed     bool
+}
+
+// Helper marks the calling function as a test helper function.
+// When printing file and line information, that function will be skipped.
+// Helper may be called simultaneously from multiple goroutines.
+func (f *F) Helper() {
+	if f.inFuzzFn {
+		panic("testing: f.Helper was called inside the fuzz target, use t.Helper instead")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.helpers == nil {
+		f.helpers = make(map[string]struct{})
+	}
+	f.helpers[callerName(1)] = struct{}{}
+}
+
+// Fail marks the function as having failed but continues execution.
+func (f *F) Fail() {
+	// (*F).Fail may be called by (*T).Fail, which we should allow. However, we
+	// shouldn't allow direct (*F).Fail calls from inside the (*F).Fuzz function.
+	if f.inFuzzFn {
+		panic("testing: f.Fail was called inside the fuzz target, use t.Fail instead")
+	}
+	f.common.Helper()
+	f.common.Fail()
+}
+
+// Skipped reports whether the test was skipped.
+func (f *F) Skipped() bool {
+	if f.inFuzzFn {
+		panic("testing: f.Skipped was called inside the fuzz target, use t.Skipped instead")
+	}
+	f.common.Helper()
+	return f.common.Skipped()
+}
+
+// Add adds the arguments to the seed corpus for the fuzz target. Add must be
+// called before F.Fuzz. The args must match those in the Fuzz function.
+func (f *F) Add(args ...interface{}) {
+	if f.fuzzCalled {
+		panic("testing: f.Add called after f.Fuzz")
+	}
+	var values []interface{}
+	for i := range args {
+		if t := reflect.TypeOf(args[i]); !supportedTypes[t] {
+			panic(fmt.Sprintf("testing: unsupported type to Add %v", t))
+		}
+		values = append(values, args[i])
+	}
+	f.corpus = append(f.corpus, corpusEntry{Values: values, IsSeed: true, Path: fmt.Sprintf("seed#%d", len(f.corpus))})
+}
+
+// supportedTypes represents all of the supported types which can be fuzzed.
+var supportedTypes = map[reflect.Type]bool{
+	reflect.TypeOf(([]byte)("")):  true,
+	reflect.TypeOf((string)("")):  true,
+	reflect.TypeOf((bool)(false)): true,
+	reflect.TypeOf((byte)(0)):     true,
+	reflect.TypeOf((rune)(0)):     true,
+	reflect.TypeOf((float32)(0)):  true,
+	reflect.TypeOf((float64)(0)):  true,
+	reflect.TypeOf((int)(0)):      true,
+	reflect.TypeOf((int8)(0)):     true,
+	reflect.TypeOf((int16)(0)):    true,
+	reflect.TypeOf((int64)(0)):    true,
+	reflect.TypeOf((uint)(0)):     true,
+	reflect.TypeOf((uint16)(0)):   true,
+	reflect.TypeOf((uint32)(0)):   true,
+	reflect.TypeOf((uint64)(0)):   true,
+}
+
+// Fuzz runs the fuzz function, ff, for fuzz testing. If ff fails for a set of
+// arguments, those arguments will be added to the seed corpus.
+//
+// ff must be a function with no return value whose first argument is *T and
+// whose remaining arguments are the types to be fuzzed.
+// For example:
+//
+//	f.Fuzz(func(t *testing.T, b []byte, i int) { ... })
+//
+// The following types are allowed: []byte, string, bool, byte, rune, float32,
+// float64, int, int8, int16, int64, uint, uint16, uint32, uint64.
+// More types may be supported in the future.
+//
+// ff must not call any *F methods, e.g. (*F).Log, (*F).Error, (*F).Skip. Use
+// the corresponding *T method instead. The only *F methods that are allowed in
+// the (*F).Fuzz function are (*F).Failed and (*F).Name.
+//
+// This function should be fast and deterministic, and its behavior should not
+// depend on shared state. No mutatable input arguments, or pointers to them,
+// should be retained between executions of the fuzz function, as the memory
+// backing them may be mutated during a subsequent invocation. ff must not
+// modify the underlying data of the arguments provided by the fuzzing engine.
+//
+// When fuzzing, F.Fuzz does not return until a problem is found, time runs out
+// (set with -fuzztime), or the test process is interrupted by a signal. F.Fuzz
+// should be called exactly once, unless F.Skip or F.Fail is called beforehand.
+func (f *F) Fuzz(ff interface{}) {
+	if f.fuzzCalled {
+		panic("testing: F.Fuzz called more than once")
+	}
+	f.fuzzCalled = true
+	if f.failed {
+		return
+	}
+	f.Helper()
+
+	// ff should be in the form func(*testing.T, ...interface{})
+	fn := reflect.ValueOf(ff)
+	fnType := fn.Type()
+	if fnType.Kind() != reflect.Func {
+		panic("testing: F.Fuzz must receive a function")
+	}
+	if fnType.NumIn() < 2 || fnType.In(0) != reflect.TypeOf((*T)(nil)) {
+		panic("testing: fuzz target must receive at least two arguments, where the first argument is a *T")
+	}
+	if fnType.NumOut() != 0 {
+		panic("testing: fuzz target must not return a value")
+	}
+
+	// Save the types of the function to compare against the corpus.
+	var types []reflect.Type
+	for i := 1; i < fnType.NumIn(); i++ {
+		t := fnType.In(i)
+		if !supportedTypes[t] {
+			panic(fmt.Sprintf("testing: unsupported type for fuzzing %v", t))
+		}
+		types = append(types, t)
+	}
+
+	// Load the testdata seed corpus. Check types of entries in the testdata
+	// corpus and entries declared with F.Add.
+	//
+	// Don't load the seed corpus if this is a worker process; we won't use it.
+	if f.fuzzContext.mode != fuzzWorker {
+		for _, c := range f.corpus {
+			if err := f.fuzzContext.deps.CheckCorpus(c.Values, types); err != nil {
+				// TODO: it would be nice to point the user to the f.Add call
+				// that added the bad entry.
+				f.Fatal(err)
+			}
+		}
+
+		// Load seed corpus
+		c, err := f.fuzzContext.deps.ReadCorpus(filepath.Join(corpusDir, f.name), types)
+		if err != nil {
+			f.Fatal(err)
+		}
+		for i := range c {
+			c[i].IsSeed = true // these are all seed corpus values
+		}
+		f.corpus = append(f.corpus, c...)
+	}
+
+	// run calls fn on a given input, as a subtest with its own T.
+	// run is analogous to T.Run. The test filtering and cleanup works similarly.
+	// fn is called in its own goroutine.
+	run := func(captureOut io.Writer, e corpusEntry) (ok bool) {
+		testName := f.name
+		if captureOut == nil {
+			testName = filepath.Base(e.Path)
+			var matched bool
+			testName, matched, _ = f.testContext.match.fullName(&f.common, testName)
+			if !matched || shouldFailFast() {
+				return true
+			}
+		}
+		// Record the stack trace at the point of this call so that if the subtest
+		// function - which runs in a separate stack - is marked as a helper, we can
+		// continue walking the stack into the parent test.
+		var pc [maxStackLen]uintptr
+		n := runtime.Callers(2, pc[:])
+		t := &T{
+			common: common{
+				barrier: make(chan bool),
+				signal:  make(chan bool),
+				name:    testName,
+				creator: pc[:n],
+				chatty:  f.chatty,
+			},
+			context: f.testContext,
+		}
+		if captureOut == nil {
+			t.parent = &f.common
+			t.level = f.level + 1
+		}
+		t.w = indenter{&t.common}
+		if t.chatty {
+			root := t.parent
+			for ; root.parent != nil; root = root.parent {
+			}
+			root.mu.Lock()
+			fmt.Fprintf(root.w, "=== RUN   %s\n", t.name)
+			root.mu.Unlock()
+		}
+		// Everything in the fuzz function runs in its own goroutine.
+		f.inFuzzFn = true
+		defer func() {
+			f.inFuzzFn = false
+		}()
+		go tRunner(t, func(t *T) {
+			args := []reflect.Value{reflect.ValueOf(t)}
+			for _, v := range e.Values {
+				args = append(args, reflect.ValueOf(v))
+			}
+			fn.Call(args)
+		})
+		if !<-t.signal {
+			// A parent test was told to stop.
+			runtime.Goexit()
+		}
+		if captureOut != nil && t.Failed() {
+			fmt.Fprintf(captureOut, "--- FAIL: %s (%s)\n%s", t.name, fmtDuration(t.duration), t.output)
+		}
+		return !t.Failed()
+	}
+
+	switch f.fuzzContext.mode {
+	case fuzzCoordinator:
+		// Fuzzing is enabled, and this is the test process started by 'go test'.
+		// Act as the coordinator process, and coordinate workers to perform the
+		// actual fuzzing.
+		corpusTargetDir := filepath.Join(corpusDir, f.name)
+		cacheTargetDir := ""
+		if *fuzzCacheDir != "" {
+			cacheTargetDir = filepath.Join(*fuzzCacheDir, f.name)
+		}
+		err := f.fuzzContext.deps.CoordinateFuzzing(*fuzzDuration, *minimizeDuration, *parallel, f.corpus, types, corpusTargetDir, cacheTargetDir)
+		if err != nil {
+			f.common.Fail()
+			f.mu.Lock()
+			fmt.Fprintf(f.w, "%v\n", err)
+			if crashErr, ok := err.(fuzzCrashError); ok {
+				crashPath := crashErr.CrashPath()
+				fmt.Fprintf(f.w, "\nFailing input written to %s\n", crashPath)
+				testName := filepath.Base(crashPath)
+				fmt.Fprintf(f.w, "To re-run:\ngo test -run=%s/%s\n", f.name, testName)
+			}
+			f.mu.Unlock()
+		}
+		// TODO: if there was an error, exit the process with a non-zero code.
+
+	case fuzzWorker:
+		// Fuzzing is enabled, and this is a worker process. Follow instructions
+		// from the coordinator.
+		err := f.fuzzContext.deps.RunFuzzWorker(func(e corpusEntry) error {
+			var out bytesBuffer
+			if !run(&out, e) {
+				return errors.New(string(bytes.TrimSuffix(out, []byte("\n"))))
+			}
+			return nil
+		})
+		if err != nil {
+			// Internal errors are marked with f.Fail; user code may call this too, before F.Fuzz.
+			// The worker will exit with fuzzWorkerExitCode, indicating this is a failure
+			// (and 'go test' should exit non-zero) but a crasher should not be recorded.
+			f.Errorf("communicating with fuzzing coordinator: %v", err)
+		}
+
+	default:
+		// Fuzzing is not enabled, or will be done later. Only run the seed
+		// corpus now.
+		for _, e := range f.corpus {
+			run(nil, e)
+		}
+	}
+}
+
+// bytesBuffer is a minimal io.Writer used to capture the output of a failed
+// fuzz function in a worker process.
+type bytesBuffer []byte
+
+func (b *bytesBuffer) Write(p []byte) (int, error) {
+	*b = append(*b, p...)
+	return len(p), nil
+}
+
+func (f *F) report() {
+	if *isFuzzWorker || f.parent == nil {
+		return
+	}
+	dstr := fmtDuration(f.duration)
+	format := "--- %s: %s (%s)\n"
+	if f.Failed() {
+		f.flushToParent(format, "FAIL", f.name, dstr)
+	} else if f.chatty {
+		if f.Skipped() {
+			f.flushToParent(format, "SKIP", f.name, dstr)
+		} else {
+			f.flushToParent(format, "PASS", f.name, dstr)
+		}
+	}
+}
+
+// fuzzCrashError is satisfied by a failing input detected while fuzzing.
+// These errors are written to the seed corpus and can be re-run with 'go test'.
+// Errors within the fuzzing framework (like I/O errors between coordinator
+// and worker processes) don't satisfy this interface.
+type fuzzCrashError interface {
+	error
+	Unwrap() error
+
+	// CrashPath returns the path of the subtest that corresponds to the saved
+	// crash input file in the seed corpus. The test can be re-run with go test
+	// -run=$test/$name $test is the fuzz test name, and $name is the
+	// filepath.Base of the string returned here.
+	CrashPath() string
+}
+
+// fuzzContext holds fields common to all fuzz targets.
+type fuzzContext struct {
+	deps testDeps
+	mode fuzzMode
+}
+
+type fuzzMode uint8
+
+const (
+	seedCorpusOnly fuzzMode = iota
+	fuzzCoordinator
+	fuzzWorker
+)
+
+// runFuzzTests runs the fuzz targets matching the pattern for -run. This will
+// only run the (*F).Fuzz function for each seed corpus entry, and will not
+// actually fuzz.
+func runFuzzTests(deps testDeps, fuzzTargets []InternalFuzzTarget) (ran, ok bool) {
+	ok = true
+	if len(fuzzTargets) == 0 || *isFuzzWorker {
+		return ran, ok
+	}
+	m := newMatcher(deps.MatchString, *match, "-test.run")
+	tctx := newTestContext(*parallel, m)
+	fctx := &fuzzContext{deps: deps, mode: seedCorpusOnly}
+	root := common{w: os.Stdout, chatty: *chatty} // gather output in one place
+	for _, ft := range fuzzTargets {
+		if shouldFailFast() {
+			break
+		}
+		testName, matched, _ := tctx.match.fullName(nil, ft.Name)
+		if !matched {
+			continue
+		}
+		f := newFuzzTarget(&root, testName, tctx, fctx)
+		if f.chatty {
+			root.mu.Lock()
+			fmt.Fprintf(root.w, "=== RUN   %s\n", f.name)
+			root.mu.Unlock()
+		}
+		go fRunner(f, ft.Fn)
+		<-f.signal
+	}
+	return root.ran, !root.Failed()
+}
+
+// runFuzzing runs the fuzz target matching the pattern for -fuzz. Only one
+// such fuzz target must match. This will run the fuzzing engine to generate
+// and mutate new inputs against the fuzz function.
+//
+// If fuzzing is disabled (-test.fuzz is not set), runFuzzing
+// returns immediately.
+func runFuzzing(deps testDeps, fuzzTargets []InternalFuzzTarget) (ok bool) {
+	if len(fuzzTargets) == 0 || *matchFuzz == "" {
+		return true
+	}
+	m := newMatcher(deps.MatchString, *matchFuzz, "-test.fuzz")
+	tctx := newTestContext(1, m)
+	fctx := &fuzzContext{deps: deps}
+	root := common{w: os.Stdout}
+	if *isFuzzWorker {
+		fctx.mode = fuzzWorker
+	} else {
+		fctx.mode = fuzzCoordinator
+		root.chatty = *chatty
+	}
+
+	var target *InternalFuzzTarget
+	var targetName string
+	var matched []string
+	for i := range fuzzTargets {
+		name, ok, _ := tctx.match.fullName(nil, fuzzTargets[i].Name)
+		if !ok {
+			continue
+		}
+		matched = append(matched, name)
+		target = &fuzzTargets[i]
+		targetName = name
+	}
+	if len(matched) == 0 {
+		fmt.Fprintln(os.Stderr, "testing: warning: no targets to fuzz")
+		return true
+	}
+	if len(matched) > 1 {
+		fmt.Fprintf(os.Stderr, "testing: will not fuzz, -fuzz matches more than one target: %v\n", matched)
+		return false
+	}
+
+	f := newFuzzTarget(&root, targetName, tctx, fctx)
+	if f.chatty {
+		root.mu.Lock()
+		fmt.Fprintf(root.w, "=== FUZZ  %s\n", f.name)
+		root.mu.Unlock()
+	}
+	go fRunner(f, target.Fn)
+	<-f.signal
+	return !f.Failed()
+}
+
+// newFuzzTarget returns an F for the fuzz target called name, reporting to
+// root.
+func newFuzzTarget(root *common, name string, tctx *testContext, fctx *fuzzContext) *F {
+	f := &F{
+		common: common{
+			signal:  make(chan bool),
+			barrier: make(chan bool),
+			name:    name,
+			parent:  root,
+			level:   root.level + 1,
+			chatty:  root.chatty,
+		},
+		testContext: tctx,
+		fuzzContext: fctx,
+	}
+	f.w = indenter{&f.common}
+	return f
+}
+
+// fRunner wraps a call to a fuzz target and ensures that cleanup functions are
+// called and status flags are set. fRunner should be called in its own
+// goroutine. To wait for its completion, receive from f.signal.
+//
+// fRunner is analogous to tRunner, which wraps subtests started with T.Run.
+// Tests and fuzz targets work a little differently, so for now, these functions
+// aren't consolidated. In particular, because there are no F.Run and F.Parallel
+// methods, i.e., no fuzz sub-targets or parallel fuzz targets, a few
+// simplifications are made. We also require that F.Fuzz, F.Skip, or F.Fail is
+// called.
+func fRunner(f *F, fn func(*F)) {
+	f.runner = callerName(0)
+
+	// When this goroutine is done, either because runtime.Goexit was called,
+	// a panic started, or fn returned normally, record the duration and send
+	// t.signal, indicating the fuzz target is done.
+	defer func() {
+		if f.Failed() {
+			atomic.AddUint32(&numFailed, 1)
+		}
+		err := recover()
+		if err == nil && !f.finished {
+			err = errNilPanicOrGoexit
+		}
+		if err != nil {
+			f.common.Fail()
+			// Flush the output log up to the root before dying.
+			f.mu.Lock()
+			root := &f.common
+			for ; root.parent != nil; root = root.parent {
+				root.duration += time.Since(root.start)
+				fmt.Fprintf(root.parent.w, "--- FAIL: %s (%s)\n", root.name, fmtDuration(root.duration))
+				root.parent.mu.Lock()
+				io.Copy(root.parent.w, bytes.NewReader(root.output))
+			}
+			panic(err)
+		}
+
+		// No panic or inappropriate Goexit.
+		f.duration += time.Since(f.start)
+		f.report()
+		f.done = true
+		f.setRan()
+		f.signal <- true
+	}()
+	defer f.runCleanup()
+
+	f.ctx, f.cancelCtx = context.WithCancel(context.Background())
+	f.start = time.Now()
+	fn(f)
+
+	// Code beyond this point is only executed if fn returned normally.
+	// That means fn did not call F.Fuzz or F.Skip. It should have called F.Fail.
+	if !f.failed && !f.fuzzCalled && !f.skipped {
+		f.common.Errorf("fuzz target did not call F.Fuzz, F.Skip, or F.Fail")
+	}
+	f.finished = true
+}
@@ -130,7 +130,56 @@
 //
 // The entire test file is presented as the example when it contains a single
 // example function, at least one other function, type, variable, or constant
-// declaration, and no test or benchmark functions.
+// declaration, and no test, benchmark, or fuzz functions.
+//
+// Fuzzing
+//
+// 'go test' and the testing package support fuzzing, a testing technique where
+// a function is called with randomly generated inputs to find bugs not
+// anticipated by unit tests.
+//
+// A fuzz target is a function that declares a set of "seed" inputs by calling
+// F.Add, then provides a fuzz function by calling F.Fuzz. A fuzz target has
+// the form:
+//
+//     func FuzzXxx(*testing.F)
+//
+// For example:
+//
+//     func FuzzHex(f *testing.F) {
+//       for _, seed := range [][]byte{{}, {0}, {9}, {0xa}, {0xf}, {1, 2, 3, 4}} {
+//         f.Add(seed)
+//       }
+//       f.Fuzz(func(t *testing.T, in []byte) {
+//         enc := hex.EncodeToString(in)
+//         out, err := hex.DecodeString(enc)
+//         if err != nil {
+//           t.Fatalf("%v: decode: %v", in, err)
+//         }
+//         if !bytes.Equal(in, out) {
+//           t.Fatalf("%v: not equal after round trip: %v", in, out)
+//         }
+//       })
+//     }
+//
+// Seed inputs may also be loaded from files in the directory
+// testdata/fuzz/<Name> (where <Name> is the name of the fuzz target) within
+// the package containing the fuzz target.
+//
+// The fuzz function may only take arguments of the types []byte, string,
+// bool, byte, rune, float32, float64, int, int8, int16, int32, int64, uint,
+// uint16, uint32, and uint64, and must not call any *F methods.
+//
+// When 'go test' is run without the -fuzz flag, each fuzz target behaves like
+// a test: the fuzz function is called once for each seed input, as a subtest
+// named after the input. When the -fuzz flag is provided, 'go test' runs the
+// seed inputs, then generates new inputs by mutating them, using coverage
+// instrumentation to prefer inputs that exercise new code. Inputs that expand
+// coverage are kept in the build cache. If the fuzz function fails for some
+// input, that input is minimized and written to testdata/fuzz/<Name>, where it
+// will serve as a seed input, and so as a regression test, in later runs.
+//
+// See https://golang.org/s/draft-fuzzing-design for more details.
 //
 // Skipping
 //
@@ -233,12 +282,16 @@ package testing
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"flag"
 	"fmt"
 	"internal/race"
 	"io"
+	"io/ioutil"
+	"math/rand"
 	"os"
+	"reflect"
 	"runtime"
 	"runtime/debug"
 	"runtime/trace"
@@ -294,8 +347,11 @@ func Init() {
 	cpuListStr = flag.String("test.cpu", "", "comma-separated `list` of cpu counts to run each test with")
 	parallel = flag.Int("test.parallel", runtime.GOMAXPROCS(0), "run at most `n` tests in parallel")
 	testlog = flag.String("test.testlogfile", "", "write test action log to `file` (for use only by cmd/go)")
+	shuffle = flag.String("test.shuffle", "off", "randomize the execution order of tests and benchmarks")
+	shard = flag.String("test.shard", "", "run only the top-level tests, examples and fuzz targets in shard `i/n`")
 
 	initBenchmarkFlags()
+	initFuzzFlags()
 }
 
 var (
@@ -320,6 +376,8 @@ var (
 	cpuListStr           *string
 	parallel             *int
 	testlog              *string
+	shuffle              *string
+	shard                *string
 
 	haveExamples bool // are there examples?
 
@@ -351,6 +409,12 @@ type common struct {
 	hasSub     int32  // written atomically
 	raceErrors int    // number of races detected during test
 	runner     string // function name of tRunner running the test
+	isParallel bool   // Test is running in parallel.
+	isEnvSet   bool   // Test has changed the environment or working directory.
+
+	// ctx is canceled just before the cleanup functions run.
+	ctx       context.Context
+	cancelCtx context.CancelFunc
 
 	parent   *common
 	level    int       // Nesting depth of test or benchmark.
@@ -361,6 +425,11 @@ type common struct {
 	barrier  chan bool // To signal parallel subtests they may start.
 	signal   chan bool // To signal a test is done.
 	sub      []*T      // Queue of subtests to be run in parallel.
+
+	tempDirMu  sync.Mutex
+	tempDir    string
+	tempDirErr error
+	tempDirSeq int32
 }
 
 // Short reports whether the -test.short flag is set.
@@ -544,7 +613,9 @@ func fmtDuration(d time.Duration) string {
 
 // TB is the interface common to T and B.
 type TB interface {
+	Chdir(dir string)
 	Cleanup(func())
+	Context() context.Context
 	Error(args ...interface{})
 	Errorf(format string, args ...interface{})
 	Fail()
@@ -556,10 +627,12 @@ type TB interface {
 	Log(args ...interface{})
 	Logf(format string, args ...interface{})
 	Name() string
+	Setenv(key, value string)
 	Skip(args ...interface{})
 	SkipNow()
 	Skipf(format string, args ...interface{})
 	Skipped() bool
+	TempDir() string
 
 	// A private method to prevent users implementing the
 	// interface and so future additions to it will not
@@ -582,8 +655,7 @@ var _ TB = (*B)(nil)
 // may be called simultaneously from multiple goroutines.
 type T struct {
 	common
-	isParallel bool
-	context    *testContext // For running tests and subtests.
+	context *testContext // For running tests and subtests.
 }
 
 func (c *common) private() {}
@@ -791,8 +863,155 @@ func (c *common) Cleanup(f func()) {
 	}
 }
 
+// TempDir returns a temporary directory for the test to use.
+// The directory is automatically removed by Cleanup when the test and
+// all its subtests complete.
+// Each subsequent call to t.TempDir returns a unique directory;
+// if the directory creation fails, TempDir terminates the test by calling Fatal.
+func (c *common) TempDir() string {
+	// Use a single parent directory for all the temporary directories
+	// created by a test, each numbered sequentially.
+	// The parent directory is created again if a previous cleanup
+	// removed it, as happens between the runs of a benchmark.
+	c.tempDirMu.Lock()
+	var nonExistent bool
+	if c.tempDir == "" {
+		nonExistent = true
+	} else {
+		_, err := os.Stat(c.tempDir)
+		nonExistent = os.IsNotExist(err)
+		if err != nil && !nonExistent {
+			c.tempDirMu.Unlock()
+			c.Fatalf("TempDir: %v", err)
+		}
+	}
+	if nonExistent {
+		c.Helper()
+
+		// ioutil.TempDir doesn't like path separators in its pattern,
+		// so mangle the name to accommodate subtests.
+		pattern := tempDirReplacer.Replace(c.Name())
+		c.tempDir, c.tempDirErr = ioutil.TempDir("", pattern)
+		if c.tempDirErr == nil {
+			dir := c.tempDir
+			c.Cleanup(func() {
+				if err := os.RemoveAll(dir); err != nil {
+					c.Errorf("TempDir RemoveAll cleanup: %v\n"+
+						"(files left in %s may still be open or read-only; "+
+						"close them, or use Cleanup to make them writable, before the test ends)",
+						err, dir)
+				}
+			})
+		}
+	}
+	c.tempDirMu.Unlock()
+
+	if c.tempDirErr != nil {
+		c.Fatalf("TempDir: %v", c.tempDirErr)
+	}
+	seq := atomic.AddInt32(&c.tempDirSeq, 1)
+	dir := fmt.Sprintf("%s%c%03d", c.tempDir, os.PathSeparator, seq)
+	if err := os.Mkdir(dir, 0777); err != nil {
+		c.Fatalf("TempDir: %v", err)
+	}
+	return dir
+}
+
+var tempDirReplacer = strings.NewReplacer("/", "_", "\\", "_", ":", "_")
+
+// Setenv calls os.Setenv(key, value) and uses Cleanup to
+// restore the environment variable to its original value
+// after the test.
+//
+// Because Setenv affects the whole process, it cannot be used
+// in parallel tests or tests with parallel ancestors.
+func (c *common) Setenv(key, value string) {
+	c.checkNotParallel("Setenv")
+	prevValue, ok := os.LookupEnv(key)
+
+	if err := os.Setenv(key, value); err != nil {
+		c.Fatalf("cannot set environment variable: %v", err)
+	}
+
+	if ok {
+		c.Cleanup(func() {
+			os.Setenv(key, prevValue)
+		})
+	} else {
+		c.Cleanup(func() {
+			os.Unsetenv(key)
+		})
+	}
+}
+
+// Chdir calls os.Chdir(dir) and uses Cleanup to restore the current
+// working directory to its original value after the test. On Unix, it
+// also sets the PWD environment variable for the duration of the test.
+//
+// Because Chdir affects the whole process, it cannot be used
+// in parallel tests or tests with parallel ancestors.
+func (c *common) Chdir(dir string) {
+	c.checkNotParallel("Chdir")
+	oldwd, err := os.Open(".")
+	if err != nil {
+		c.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		oldwd.Close()
+		c.Fatal(err)
+	}
+	c.Cleanup(func() {
+		err := oldwd.Chdir()
+		oldwd.Close()
+		if err != nil {
+			// It's not safe to continue with tests if we can't
+			// get back to the original working directory. Since
+			// we are holding a directory descriptor, this is
+			// highly unlikely.
+			panic("testing: Chdir: " + err.Error())
+		}
+	})
+	// On POSIX systems, PWD holds the absolute path of the current
+	// working directory. Windows and Plan 9 do not use it.
+	switch runtime.GOOS {
+	case "windows", "plan9":
+	default:
+		if dir, err = os.Getwd(); err != nil {
+			c.Fatal(err)
+		}
+		c.Setenv("PWD", dir)
+	}
+}
+
+// checkNotParallel panics if the test or one of its ancestors
+// is running in parallel, and otherwise records that the test
+// changed process-wide state, so that a later call to Parallel panics.
+func (c *common) checkNotParallel(method string) {
+	// Non-parallel subtests that have parallel ancestors may still
+	// run in parallel with other tests: they are only non-parallel
+	// with respect to the other subtests of the same parent.
+	for p := c; p != nil; p = p.parent {
+		if p.isParallel {
+			panic("testing: t." + method + " called after t.Parallel; cannot change the process environment in parallel tests")
+		}
+	}
+	c.isEnvSet = true
+}
+
+// Context returns a context that is canceled just before
+// Cleanup-registered functions are called.
+//
+// Cleanup functions can wait for any resources
+// that shut down on Context.Done before the test or benchmark completes.
+func (c *common) Context() context.Context {
+	return c.ctx
+}
+
 // runCleanup is called at the end of the test.
 func (c *common) runCleanup() {
+	if c.cancelCtx != nil {
+		c.cancelCtx()
+	}
 	c.mu.Lock()
 	cleanup := c.cleanup
 	c.cleanup = nil
@@ -824,6 +1043,9 @@ func (t *T) Parallel() {
 	if t.isParallel {
 		panic("testing: t.Parallel called multiple times")
 	}
+	if t.isEnvSet {
+		panic("testing: t.Parallel called after t.Setenv or t.Chdir; cannot change the process environment in parallel tests")
+	}
 	t.isParallel = true
 
 	// We don't want to include the time we spend waiting for serial tests
@@ -949,6 +1171,7 @@ func tRunner(t *T, fn func(t *T)) {
 	}()
 	defer t.runCleanup()
 
+	t.ctx, t.cancelCtx = context.WithCancel(context.Background())
 	t.start = time.Now()
 	t.raceErrors = -race.Errors()
 	fn(t)
@@ -1078,6 +1301,14 @@ func (f matchStringOnly) WriteProfileTo(string, io.Writer, int) error { return e
 func (f matchStringOnly) ImportPath() string                          { return "" }
 func (f matchStringOnly) StartTestLog(io.Writer)                      {}
 func (f matchStringOnly) StopTestLog() error                          { return errMain }
+func (f matchStringOnly) CoordinateFuzzing(time.Duration, time.Duration, int, []corpusEntry, []reflect.Type, string, string) error {
+	return errMain
+}
+func (f matchStringOnly) RunFuzzWorker(func(corpusEntry) error) error { return errMain }
+func (f matchStringOnly) ReadCorpus(string, []reflect.Type) ([]corpusEntry, error) {
+	return nil, errMain
+}
+func (f matchStringOnly) CheckCorpus([]interface{}, []reflect.Type) error { return nil }
 
 // Main is an internal function, part of the implementation of the "go test" command.
 // It was exported because it is cross-package and predates "internal" packages.
@@ -1086,15 +1317,16 @@ func (f matchStringOnly) StopTestLog() error                          { return e
 // new functionality is added to the testing package.
 // Systems simulating "go test" should be updated to use MainStart.
 func Main(matchString func(pat, str string) (bool, error), tests []InternalTest, benchmarks []InternalBenchmark, examples []InternalExample) {
-	os.Exit(MainStart(matchStringOnly(matchString), tests, benchmarks, examples).Run())
+	os.Exit(MainStart(matchStringOnly(matchString), tests, benchmarks, nil, examples).Run())
 }
 
 // M is a type passed to a TestMain function to run the actual tests.
 type M struct {
-	deps       testDeps
-	tests      []InternalTest
-	benchmarks []InternalBenchmark
-	examples   []InternalExample
+	deps        testDeps
+	tests       []InternalTest
+	benchmarks  []InternalBenchmark
+	fuzzTargets []InternalFuzzTarget
+	examples    []InternalExample
 
 	timer     *time.Timer
 	afterOnce sync.Once
@@ -1114,18 +1346,23 @@ type testDeps interface {
 	StartTestLog(io.Writer)
 	StopTestLog() error
 	WriteProfileTo(string, io.Writer, int) error
+	CoordinateFuzzing(time.Duration, time.Duration, int, []corpusEntry, []reflect.Type, string, string) error
+	RunFuzzWorker(func(corpusEntry) error) error
+	ReadCorpus(string, []reflect.Type) ([]corpusEntry, error)
+	CheckCorpus([]interface{}, []reflect.Type) error
 }
 
 // MainStart is meant for use by tests generated by 'go test'.
 // It is not meant to be called directly and is not subject to the Go 1 compatibility document.
 // It may change signature from release to release.
-func MainStart(deps testDeps, tests []InternalTest, benchmarks []InternalBenchmark, examples []InternalExample) *M {
+func MainStart(deps testDeps, tests []InternalTest, benchmarks []InternalBenchmark, fuzzTargets []InternalFuzzTarget, examples []InternalExample) *M {
 	Init()
 	return &M{
-		deps:       deps,
-		tests:      tests,
-		benchmarks: benchmarks,
-		examples:   examples,
+		deps:        deps,
+		tests:       tests,
+		benchmarks:  benchmarks,
+		fuzzTargets: fuzzTargets,
+		examples:    examples,
 	}
 }
 
@@ -1149,10 +1386,47 @@ func (m *M) Run() int {
 	}
 
 	if len(*matchList) != 0 {
-		listTests(m.deps.MatchString, m.tests, m.benchmarks, m.examples)
+		listTests(m.deps.MatchString, m.tests, m.benchmarks, m.fuzzTargets, m.examples)
+		return 0
+	}
+
+	if *isFuzzWorker {
+		// A fuzz worker only runs the fuzz target it was started for;
+		// tests, examples and benchmarks were run by the coordinator.
+		if !runFuzzing(m.deps, m.fuzzTargets) {
+			return fuzzWorkerExitCode
+		}
 		return 0
 	}
 
+	if *shard != "" {
+		i, n, err := parseShard(*shard)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "testing: -shard should be of the form i/n with 0 <= i < n:", err)
+			return 2
+		}
+		fmt.Println("-test.shard", *shard)
+		m.selectShard(i, n)
+	}
+
+	if *shuffle != "off" {
+		var n int64
+		var err error
+		if *shuffle == "on" {
+			n = time.Now().UnixNano()
+		} else {
+			n, err = strconv.ParseInt(*shuffle, 10, 64)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, `testing: -shuffle should be "off", "on", or a valid integer:`, err)
+				return 2
+			}
+		}
+		fmt.Println("-test.shuffle", n)
+		rng := rand.New(rand.NewSource(n))
+		rng.Shuffle(len(m.tests), func(i, j int) { m.tests[i], m.tests[j] = m.tests[j], m.tests[i] })
+		rng.Shuffle(len(m.benchmarks), func(i, j int) { m.benchmarks[i], m.benchmarks[j] = m.benchmarks[j], m.benchmarks[i] })
+	}
+
 	parseCpuList()
 
 	m.before()
@@ -1160,12 +1434,18 @@ func (m *M) Run() int {
 	m.startAlarm()
 	haveExamples = len(m.examples) > 0
 	testRan, testOk := runTests(m.deps.MatchString, m.tests)
+	fuzzTargetsRan, fuzzTargetsOk := runFuzzTests(m.deps, m.fuzzTargets)
 	exampleRan, exampleOk := runExamples(m.deps.MatchString, m.examples)
 	m.stopAlarm()
-	if !testRan && !exampleRan && *matchBenchmarks == "" {
+	if !testRan && !exampleRan && !fuzzTargetsRan && *matchBenchmarks == "" && *matchFuzz == "" {
 		fmt.Fprintln(os.Stderr, "testing: warning: no tests to run")
 	}
-	if !testOk || !exampleOk || !runBenchmarks(m.deps.ImportPath(), m.deps.MatchString, m.benchmarks) || race.Errors() > 0 {
+	if !testOk || !fuzzTargetsOk || !exampleOk || !runBenchmarks(m.deps.ImportPath(), m.deps.MatchString, m.benchmarks) || race.Errors() > 0 {
+		fmt.Println("FAIL")
+		return 1
+	}
+
+	if !runFuzzing(m.deps, m.fuzzTargets) {
 		fmt.Println("FAIL")
 		return 1
 	}
@@ -1191,7 +1471,7 @@ func (t *T) report() {
 	}
 }
 
-func listTests(matchString func(pat, str string) (bool, error), tests []InternalTest, benchmarks []InternalBenchmark, examples []InternalExample) {
+func listTests(matchString func(pat, str string) (bool, error), tests []InternalTest, benchmarks []InternalBenchmark, fuzzTargets []InternalFuzzTarget, examples []InternalExample) {
 	if _, err := matchString(*matchList, "non-empty"); err != nil {
 		fmt.Fprintf(os.Stderr, "testing: invalid regexp in -test.list (%q): %s\n", *matchList, err)
 		os.Exit(1)
@@ -1207,6 +1487,11 @@ func listTests(matchString func(pat, str string) (bool, error), tests []Internal
 			fmt.Println(bench.Name)
 		}
 	}
+	for _, fuzzTarget := range fuzzTargets {
+		if ok, _ := matchString(*matchList, fuzzTarget.Name); ok {
+			fmt.Println(fuzzTarget.Name)
+		}
+	}
 	for _, example := range examples {
 		if ok, _ := matchString(*matchList, example.Name); ok {
 			fmt.Println(example.Name)
@@ -1449,6 +1734,67 @@ func parseCpuList() {
 	}
 }
 
+// parseShard parses the value of the -test.shard flag,
+// which has the form i/n with 0 <= i < n.
+func parseShard(s string) (i, n int, err error) {
+	slash := strings.Index(s, "/")
+	if slash < 0 {
+		return 0, 0, fmt.Errorf("missing slash in %q", s)
+	}
+	if i, err = strconv.Atoi(s[:slash]); err != nil {
+		return 0, 0, err
+	}
+	if n, err = strconv.Atoi(s[slash+1:]); err != nil {
+		return 0, 0, err
+	}
+	if i < 0 || n <= 0 || i >= n {
+		return 0, 0, fmt.Errorf("shard %q out of range", s)
+	}
+	return i, n, nil
+}
+
+// inShard reports whether the top-level test, example or fuzz target
+// called name belongs to shard i of n. The assignment depends only on
+// the name, so that it does not change when other tests are added,
+// removed or reordered.
+func inShard(name string, i, n int) bool {
+	// 32-bit FNV-1a hash of name.
+	h := uint32(2166136261)
+	for j := 0; j < len(name); j++ {
+		h ^= uint32(name[j])
+		h *= 16777619
+	}
+	return int(h%uint32(n)) == i
+}
+
+// selectShard removes the tests, examples and fuzz targets that do not
+// belong to shard i of n. Benchmarks are not sharded.
+func (m *M) selectShard(i, n int) {
+	tests := m.tests[:0]
+	for _, t := range m.tests {
+		if inShard(t.Name, i, n) {
+			tests = append(tests, t)
+		}
+	}
+	m.tests = tests
+
+	examples := m.examples[:0]
+	for _, e := range m.examples {
+		if inShard(e.Name, i, n) {
+			examples = append(examples, e)
+		}
+	}
+	m.examples = examples
+
+	fuzzTargets := m.fuzzTargets[:0]
+	for _, f := range m.fuzzTargets {
+		if inShard(f.Name, i, n) {
+			fuzzTargets = append(fuzzTargets, f)
+		}
+	}
+	m.fuzzTargets = fuzzTargets
+}
+
 func shouldFailFast() bool {
 	return *failFast && atomic.LoadUint32(&numFailed) > 0
 }
@@ -5,7 +5,13 @@
 package testing_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
 )
 
@@ -16,3 +22,179 @@ import (
 func TestMain(m *testing.M) {
 	os.Exit(m.Run())
 }
+
+func TestTempDir(t *testing.T) {
+	testTempDir(t)
+	t.Run("InSubtest", testTempDir)
+	t.Run("test/subtest", testTempDir)
+	t.Run("test\\subtest", testTempDir)
+	t.Run("test:subtest", testTempDir)
+	t.Run("test/..", testTempDir)
+	t.Run("../test", testTempDir)
+}
+
+func testTempDir(t *testing.T) {
+	dirCh := make(chan string, 1)
+	t.Cleanup(func() {
+		// Verify directory has been removed.
+		select {
+		case dir := <-dirCh:
+			fi, err := os.Stat(dir)
+			if os.IsNotExist(err) {
+				// All good
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Errorf("directory %q still exists: %v, isDir=%v", dir, fi, fi.IsDir())
+		default:
+			if !t.Failed() {
+				t.Fatal("never received dir channel")
+			}
+		}
+	})
+
+	dir := t.TempDir()
+	if dir == "" {
+		t.Fatal("expected dir")
+	}
+	dir2 := t.TempDir()
+	if dir == dir2 {
+		t.Fatal("subsequent calls to TempDir returned the same directory")
+	}
+	if filepath.Dir(dir) != filepath.Dir(dir2) {
+		t.Fatalf("calls to TempDir do not share a parent; got %q, %q", dir, dir2)
+	}
+	dirCh <- dir
+	fi, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fi.IsDir() {
+		t.Errorf("dir %q is not a dir", dir)
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) > 0 {
+		t.Errorf("unexpected %d files in TempDir: %v", len(files), files)
+	}
+}
+
+func TestSetenv(t *testing.T) {
+	const key = "GO_TEST_KEY_1"
+	for _, initial := range []string{"", "initial"} {
+		t.Run(fmt.Sprintf("initial=%q", initial), func(t *testing.T) {
+			if initial == "" {
+				os.Unsetenv(key)
+			} else {
+				os.Setenv(key, initial)
+				defer os.Unsetenv(key)
+			}
+
+			t.Run("sub", func(t *testing.T) {
+				t.Setenv(key, "new")
+				if got := os.Getenv(key); got != "new" {
+					t.Errorf("Getenv(%q) = %q; want %q", key, got, "new")
+				}
+			})
+
+			got, ok := os.LookupEnv(key)
+			if got != initial || ok != (initial != "") {
+				t.Errorf("after test, LookupEnv(%q) = %q, %v; want %q restored", key, got, ok, initial)
+			}
+		})
+	}
+}
+
+func TestSetenvWithParallelAfterSetenv(t *testing.T) {
+	defer func() {
+		want := "testing: t.Parallel called after t.Setenv or t.Chdir; cannot change the process environment in parallel tests"
+		if got := recover(); got != want {
+			t.Fatalf("expected panic; got %#v want %q", got, want)
+		}
+	}()
+
+	t.Setenv("GO_TEST_KEY_1", "value")
+
+	t.Parallel()
+}
+
+func TestSetenvWithParallelBeforeSetenv(t *testing.T) {
+	t.Parallel()
+
+	t.Run("child", func(t *testing.T) {
+		defer func() {
+			want := "testing: t.Setenv called after t.Parallel; cannot change the process environment in parallel tests"
+			if got := recover(); got != want {
+				t.Fatalf("expected panic; got %#v want %q", got, want)
+			}
+		}()
+
+		t.Setenv("GO_TEST_KEY_1", "value")
+	})
+}
+
+func TestChdir(t *testing.T) {
+	oldwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmp := t.TempDir()
+
+	t.Run("sub", func(t *testing.T) {
+		t.Chdir(tmp)
+		wd, err := os.Getwd()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := filepath.EvalSymlinks(tmp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := filepath.EvalSymlinks(wd); err != nil || got != want {
+			t.Errorf("after Chdir, working directory = %q; want %q", wd, want)
+		}
+		if runtime.GOOS != "windows" && runtime.GOOS != "plan9" {
+			if pwd := os.Getenv("PWD"); pwd != wd {
+				t.Errorf("after Chdir, PWD = %q; want %q", pwd, wd)
+			}
+		}
+	})
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wd != oldwd {
+		t.Errorf("after test, working directory = %q; want %q", wd, oldwd)
+	}
+}
+
+func TestContext(t *testing.T) {
+	ctx := t.Context()
+	if err := ctx.Err(); err != nil {
+		t.Fatalf("expected non-canceled context, got %v", err)
+	}
+
+	var innerCtx context.Context
+	t.Run("inner", func(t *testing.T) {
+		innerCtx = t.Context()
+		if err := innerCtx.Err(); err != nil {
+			t.Fatalf("expected inner test to not inherit canceled context, got %v", err)
+		}
+	})
+	t.Run("inner2", func(t *testing.T) {
+		if !errors.Is(innerCtx.Err(), context.Canceled) {
+			t.Fatal("expected context of sibling test to be canceled after its test function finished")
+		}
+	})
+
+	t.Cleanup(func() {
+		if !errors.Is(ctx.Err(), context.Canceled) {
+			t.Fatal("expected context canceled before cleanup")
+		}
+	})
+}
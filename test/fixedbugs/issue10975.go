@@ -10,9 +10,9 @@
 package main
 
 type I interface {
-	int // ERROR "interface contains embedded non-interface int"
+	int
 }
 
-func New() I {
+func New() I { // ERROR "interface contains type constraints"
 	return struct{}{}
 }
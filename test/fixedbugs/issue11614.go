@@ -11,15 +11,15 @@
 package main
 
 type I interface {
-	int // ERROR "interface contains embedded non-interface int"
+	int
 }
 
 func n() {
-	(I)
+	(I) // ERROR "type I is not an expression"
 }
 
 func m() {
-	(interface{int}) // ERROR "interface contains embedded non-interface int" "type interface { int } is not an expression"
+	(interface{int}) // ERROR "type interface { int } is not an expression"
 }
 
 func main() {
@@ -0,0 +1,331 @@
+// run
+
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test range over iterator functions.
+
+package main
+
+import "fmt"
+
+type Seq func(yield func(int) bool)
+
+type Seq2 func(yield func(int, string) bool)
+
+func count(n int) Seq {
+	return func(yield func(int) bool) {
+		for i := 0; i < n; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+func pairs(s ...string) Seq2 {
+	return func(yield func(int, string) bool) {
+		for i, v := range s {
+			if !yield(i, v) {
+				return
+			}
+		}
+	}
+}
+
+func check(got, want interface{}) {
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		panic(fmt.Sprintf("got %v, want %v", got, want))
+	}
+}
+
+func testBasic() {
+	var got []int
+	for i := range count(5) {
+		got = append(got, i)
+	}
+	check(got, []int{0, 1, 2, 3, 4})
+
+	var s string
+	for i, v := range pairs("a", "b", "c") {
+		s += fmt.Sprint(i, v)
+	}
+	check(s, "0a1b2c")
+
+	n := 0
+	for range count(3) {
+		n++
+	}
+	check(n, 3)
+
+	var k int
+	for k, s = range pairs("p", "q") {
+	}
+	check(k, 1)
+	check(s, "q")
+}
+
+func testBranches() {
+	var got []int
+	for i := range count(10) {
+		if i%2 == 0 {
+			continue
+		}
+		if i > 7 {
+			break
+		}
+		got = append(got, i)
+	}
+	check(got, []int{1, 3, 5, 7})
+
+	var out []string
+outer:
+	for i := range count(4) {
+		for j, s := range pairs("a", "b", "c") {
+			if j == 2 {
+				continue outer
+			}
+			if i == 3 {
+				break outer
+			}
+			out = append(out, fmt.Sprint(i, s))
+		}
+	}
+	check(out, []string{"0a", "0b", "1a", "1b", "2a", "2b"})
+
+	got = nil
+loop:
+	for i := 0; i < 3; i++ {
+		for j := range count(5) {
+			if j == 2 {
+				continue loop
+			}
+			if i == 2 {
+				break loop
+			}
+			got = append(got, i*10+j)
+		}
+	}
+	check(got, []int{0, 1, 10, 11})
+
+	n := 0
+	for i := range count(3) {
+		switch i {
+		case 1:
+			break
+		default:
+			n += 10
+		}
+		n++
+	}
+	check(n, 23)
+}
+
+func findUnnamed(n, x int) (int, bool) {
+	for i := range count(n) {
+		if i == x {
+			return i * 10, true
+		}
+	}
+	return -1, false
+}
+
+func findNamed() (r int, err error) {
+	defer func() { r++ }()
+	for i := range count(5) {
+		for j := range count(5) {
+			if i*j == 6 {
+				r = i*10 + j
+				return
+			}
+		}
+	}
+	return -1, nil
+}
+
+func findDeep() (int, string) {
+	for i := range count(5) {
+		for j := range count(5) {
+			for k := range count(5) {
+				if i+j+k == 7 {
+					return i*100 + j*10 + k, "found"
+				}
+			}
+		}
+	}
+	return 0, "none"
+}
+
+func findGoto() int {
+	n := 0
+	for i := range count(10) {
+		n = i
+		if i == 5 {
+			goto done
+		}
+	}
+	return -1
+done:
+	return n
+}
+
+func testReturn() {
+	v, ok := findUnnamed(10, 3)
+	check(v, 30)
+	check(ok, true)
+	v, ok = findUnnamed(3, 7)
+	check(v, -1)
+	check(ok, false)
+	r, err := findNamed()
+	check(r, 24)
+	check(err, nil)
+	v, s := findDeep()
+	check(v, 34)
+	check(s, "found")
+	check(findGoto(), 5)
+}
+
+func testClosures() {
+	var fs []func() int
+	for i := range count(3) {
+		fs = append(fs, func() int { return i })
+	}
+	for i, f := range fs {
+		check(f(), i)
+	}
+
+	total := 0
+	inc := func(d int) { total += d }
+	for i := range count(4) {
+		inc(i)
+		func() { total *= 2 }()
+	}
+	check(total, 22)
+
+	f := func(n int) int {
+		s := 0
+		for i := range count(n) {
+			if i == 3 {
+				return s
+			}
+			s += i
+		}
+		return -s
+	}
+	check(f(10), 3)
+	check(f(2), -1)
+}
+
+type T struct{ n int }
+
+func (t *T) items(yield func(int) bool) {
+	for _, v := range []int{1, 2, 3} {
+		if !yield(v) {
+			return
+		}
+	}
+}
+
+func testMethod() {
+	var t T
+	for v := range t.items {
+		t.n += v
+	}
+	check(t.n, 6)
+}
+
+func testPanic() {
+	defer func() {
+		check(recover(), "boom")
+	}()
+	for i := range count(3) {
+		if i == 1 {
+			panic("boom")
+		}
+	}
+	panic("not reached")
+}
+
+func testContinuedIteration() {
+	defer func() {
+		err, ok := recover().(error)
+		if !ok || err.Error() != "runtime error: range function continued iteration after exit" {
+			panic(fmt.Sprintf("unexpected panic: %v", err))
+		}
+	}()
+	var saved func(int) bool
+	for range Seq(func(yield func(int) bool) { saved = yield; yield(1) }) {
+		break
+	}
+	saved(2)
+	panic("not reached")
+}
+
+func deferOrder() (log []string) {
+	add := func(s string) { log = append(log, s) }
+	defer add("before")
+	for i := range count(2) {
+		defer add(fmt.Sprint("outer ", i))
+		for _, v := range pairs("a", "b") {
+			defer func() { add(fmt.Sprint("inner ", i, v)) }()
+		}
+	}
+	defer add("after")
+	return nil
+}
+
+func pair() (int, string) { return 1, "one" }
+
+func deferArgs() (s string) {
+	for i := range count(1) {
+		x := i
+		defer func(a, b int) { s += fmt.Sprint(a, b, x) }(i, x)
+		x = 5
+		defer func(n int, v string) { s += fmt.Sprint(n, v) }(pair())
+	}
+	return ""
+}
+
+func deferRecover() (err error) {
+	defer func() { err = fmt.Errorf("%v, done", err) }()
+	for i := range count(3) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("recovered %v", r)
+			}
+		}()
+		if i == 1 {
+			panic("boom")
+		}
+	}
+	panic("not reached")
+}
+
+func testDefer() {
+	check(deferOrder(), []string{"after", "inner 1b", "inner 1a", "outer 1", "inner 0b", "inner 0a", "outer 0", "before"})
+	check(deferArgs(), "1one0 0 5")
+	check(deferRecover(), "recovered boom, done")
+
+	var got []int
+	for j := 0; j < 2; j++ {
+		func() {
+			defer func() { got = append(got, -1) }()
+			for i := range count(2) {
+				defer func() { got = append(got, j*10+i) }()
+			}
+		}()
+	}
+	check(got, []int{1, 0, -1, 11, 10, -1})
+}
+
+func main() {
+	testBasic()
+	testBranches()
+	testReturn()
+	testClosures()
+	testMethod()
+	testPanic()
+	testContinuedIteration()
+	testDefer()
+}
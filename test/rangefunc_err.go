@@ -0,0 +1,27 @@
+// errorcheck
+
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Verify that erroneous range-over-func loops are rejected.
+// Does not compile.
+
+package main
+
+func f() {
+	for range func() {} { // ERROR "wrong argument count"
+	}
+	for range func(int) {} { // ERROR "argument is not func"
+	}
+	for range func(func() int) {} { // ERROR "yield func does not return bool"
+	}
+	for range func(func(int, int, int) bool) {} { // ERROR "yield func has too many parameters"
+	}
+	for x := range func(func() bool) {} { // ERROR "permits no iteration variables"
+		_ = x
+	}
+	for x, y := range func(func(int) bool) {} { // ERROR "permits only one iteration variable"
+		_, _ = x, y
+	}
+}
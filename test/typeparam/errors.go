@@ -0,0 +1,50 @@
+// errorcheck
+
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Verify that misuses of generic declarations are rejected.
+
+package p
+
+type Number interface {
+	~int | ~float64
+}
+
+type Stringer interface {
+	String() string
+}
+
+func Sum[T Number](s []T) T {
+	var sum T
+	for _, v := range s {
+		sum += v
+	}
+	return sum
+}
+
+func Str[T Stringer](x T) string { return x.String() }
+
+func Eq[T comparable](a, b T) bool { return a == b }
+
+func Zero[T any]() T {
+	var z T
+	return z
+}
+
+type Box[T any] struct{ v T }
+
+var _ = Sum([]string{"a"}) // ERROR "string does not satisfy Number"
+var _ = Str(1)             // ERROR "int does not satisfy Stringer .missing method String."
+var _ = Eq([]int{}, nil)   // ERROR "\[\]int does not satisfy comparable"
+var _ = Sum                // ERROR "cannot use generic function Sum without instantiation"
+var _ = Zero()             // ERROR "cannot infer T"
+var _ Box                  // ERROR "cannot use generic type Box without instantiation"
+var _ = Sum[int, int]      // ERROR "got 2 type arguments but Sum has 1 type parameters"
+var _ Number               // ERROR "interface contains type constraints"
+
+func f(x Number) {} // ERROR "interface contains type constraints"
+
+var _ = Zero[int]()
+var _ Box[string]
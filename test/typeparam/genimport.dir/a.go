@@ -0,0 +1,48 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package a
+
+import "strings"
+
+type Ordered interface {
+	~int | ~float64 | ~string
+}
+
+func Max[T Ordered](a, b T) T {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+var sep = ","
+
+func Join[T interface{ String() string }](s []T) string {
+	var b strings.Builder
+	for i, v := range s {
+		if i > 0 {
+			b.WriteString(sep)
+		}
+		b.WriteString(v.String())
+	}
+	return b.String()
+}
+
+type Map[K comparable, V any] struct {
+	m map[K]V
+}
+
+func NewMap[K comparable, V any]() *Map[K, V] {
+	return &Map[K, V]{make(map[K]V)}
+}
+
+func (m *Map[K, V]) Set(k K, v V) { m.m[k] = v }
+
+func (m *Map[K, V]) Get(k K) (V, bool) {
+	v, ok := m.m[k]
+	return v, ok
+}
+
+var Counts = NewMap[string, int]()
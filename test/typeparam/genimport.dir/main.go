@@ -0,0 +1,41 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+
+	"./a"
+)
+
+type S string
+
+func (s S) String() string { return string(s) }
+
+func main() {
+	if got, want := a.Max(2, 5), 5; got != want {
+		panic(fmt.Sprintf("got %d, want %d", got, want))
+	}
+	if got, want := a.Max("x", "y"), "y"; got != want {
+		panic(fmt.Sprintf("got %q, want %q", got, want))
+	}
+	if got, want := a.Join([]S{"a", "b"}), "a,b"; got != want {
+		panic(fmt.Sprintf("got %q, want %q", got, want))
+	}
+
+	m := a.NewMap[S, float64]()
+	m.Set("pi", 3.14)
+	if v, ok := m.Get("pi"); !ok || v != 3.14 {
+		panic(fmt.Sprintf("got %g, %v, want 3.14, true", v, ok))
+	}
+
+	a.Counts.Set("x", 1)
+	if v, _ := a.Counts.Get("x"); v != 1 {
+		panic(fmt.Sprintf("got %d, want 1", v))
+	}
+	if got, want := fmt.Sprintf("%T", a.Counts), "*a.Map[string,int]"; got != want {
+		panic(fmt.Sprintf("got %s, want %s", got, want))
+	}
+}
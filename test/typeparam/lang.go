@@ -0,0 +1,22 @@
+// errorcheck -lang=go1.13
+
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Verify that generic declarations are rejected before go1.14.
+
+package p
+
+type Pair[K comparable, V any] struct { // ERROR "type parameters require go1.14 or later"
+	Key K
+	Val V
+}
+
+func Map[T, U any](s []T, f func(T) U) []U { // ERROR "type parameters require go1.14 or later"
+	r := make([]U, len(s))
+	for i, v := range s {
+		r[i] = f(v)
+	}
+	return r
+}
@@ -0,0 +1,65 @@
+// run
+
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test generic types with methods.
+
+package main
+
+import "fmt"
+
+type List[T any] struct {
+	head *element[T]
+	len  int
+}
+
+type element[T any] struct {
+	next *element[T]
+	val  T
+}
+
+func (l *List[T]) Push(v T) {
+	l.head = &element[T]{l.head, v}
+	l.len++
+}
+
+func (l *List[T]) Each(f func(T)) {
+	for e := l.head; e != nil; e = e.next {
+		f(e.val)
+	}
+}
+
+func (l *List[T]) Len() int { return l.len }
+
+type Lener interface {
+	Len() int
+}
+
+func Map[T, U any](l *List[T], f func(T) U) *List[U] {
+	r := new(List[U])
+	l.Each(func(v T) { r.Push(f(v)) })
+	return r
+}
+
+func main() {
+	var l List[int]
+	l.Push(1)
+	l.Push(2)
+	l.Push(3)
+
+	var s string
+	Map(&l, func(v int) string { return fmt.Sprint(v * 2) }).Each(func(v string) { s += v })
+	if want := "246"; s != want {
+		panic(fmt.Sprintf("got %q, want %q", s, want))
+	}
+
+	var x Lener = &l
+	if got, want := x.Len(), 3; got != want {
+		panic(fmt.Sprintf("got %d, want %d", got, want))
+	}
+	if got, want := fmt.Sprintf("%T", x), "*main.List[int]"; got != want {
+		panic(fmt.Sprintf("got %s, want %s", got, want))
+	}
+}
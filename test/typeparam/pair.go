@@ -0,0 +1,34 @@
+// run
+
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test methods whose receivers rename or omit the type parameters
+// of their generic type.
+
+package main
+
+import "fmt"
+
+type Pair[A, B any] struct {
+	a A
+	b B
+}
+
+func (p Pair[A, B]) Swap() Pair[B, A] { return Pair[B, A]{p.b, p.a} }
+
+func (p Pair[First, _]) First() First { return p.a }
+
+func (p *Pair[_, Second]) SetSecond(b Second) { p.b = b }
+
+func main() {
+	p := Pair[int, string]{1, "one"}
+	p.SetSecond("uno")
+	if got, want := fmt.Sprint(p.Swap()), "{uno 1}"; got != want {
+		panic(fmt.Sprintf("got %s, want %s", got, want))
+	}
+	if got, want := p.Swap().First(), "uno"; got != want {
+		panic(fmt.Sprintf("got %s, want %s", got, want))
+	}
+}
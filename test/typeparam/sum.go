@@ -0,0 +1,56 @@
+// run
+
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test generic functions with type set constraints.
+
+package main
+
+import "fmt"
+
+type Number interface {
+	~int | ~int64 | ~float64
+}
+
+func Sum[T Number](s []T) T {
+	var sum T
+	for _, v := range s {
+		sum += v
+	}
+	return sum
+}
+
+func Max[T ~int | ~string](a, b T) T {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+type MyInt int
+
+func main() {
+	if got, want := Sum([]int{1, 2, 3}), 6; got != want {
+		panic(fmt.Sprintf("got %d, want %d", got, want))
+	}
+	if got, want := Sum([]float64{1.5, 2.5}), 4.0; got != want {
+		panic(fmt.Sprintf("got %g, want %g", got, want))
+	}
+	if got, want := Sum([]MyInt{4, 5}), MyInt(9); got != want {
+		panic(fmt.Sprintf("got %d, want %d", got, want))
+	}
+	if got, want := Sum[int64](nil), int64(0); got != want {
+		panic(fmt.Sprintf("got %d, want %d", got, want))
+	}
+	if got, want := Max("a", "b"), "b"; got != want {
+		panic(fmt.Sprintf("got %q, want %q", got, want))
+	}
+	if got, want := Max(3, 2), 3; got != want {
+		panic(fmt.Sprintf("got %d, want %d", got, want))
+	}
+	if got, want := fmt.Sprintf("%T", Max[MyInt]), "func(main.MyInt, main.MyInt) main.MyInt"; got != want {
+		panic(fmt.Sprintf("got %s, want %s", got, want))
+	}
+}